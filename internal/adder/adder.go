@@ -0,0 +1,217 @@
+// Package adder saves URLs to the reading list the same way from every
+// front end: `rl add`, the TUI, and the MCP server.
+package adder
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/bunchhieng/rl/internal/attach"
+	"github.com/bunchhieng/rl/internal/fetch"
+	"github.com/bunchhieng/rl/internal/model"
+	"github.com/bunchhieng/rl/internal/storage"
+)
+
+// FetchTimeout bounds the page fetch when a link is added.
+const FetchTimeout = 15 * time.Second
+
+// Options holds the optional fields and steps for Add.
+type Options struct {
+	Title string
+	// Note is added to the link's dated notes, unless it has it already.
+	Note string
+	Tags string
+	// Priority is the level to save the link at; nil leaves the level of
+	// a link saved before as it is.
+	Priority *model.Priority
+	// Fetch downloads the page to fill in the title and reading time.
+	Fetch bool
+	// Canonicalize strips tracking parameters and fragments, normalizes
+	// case, and follows trivial redirects before saving.
+	Canonicalize bool
+	// Attach keeps a copy of URLs that point to a PDF or other document.
+	Attach bool
+}
+
+// Result describes a link saved by Add.
+type Result struct {
+	Link *model.Link
+	// Updated is set when the URL was already saved and merged into.
+	Updated bool
+	// AliasURL is the URL as given when a redirect moved the link.
+	AliasURL string
+	// Attachment is the copy saved of a document, if any.
+	Attachment *model.Attachment
+	// FetchErr is why the page couldn't be fetched; the link is saved
+	// without it.
+	FetchErr error
+	// AttachErr is why a copy of the document couldn't be saved.
+	AttachErr error
+}
+
+// Adder saves links to a store.
+type Adder struct {
+	storage     storage.Storage
+	fetcher     *fetch.Client
+	attachments *attach.Store
+}
+
+// New returns an Adder saving to s. attachments may be nil, in which case
+// links can't be given a saved copy.
+func New(s storage.Storage, fetcher *fetch.Client, attachments *attach.Store) *Adder {
+	return &Adder{storage: s, fetcher: fetcher, attachments: attachments}
+}
+
+// Add canonicalizes, fetches, and saves a URL. A page that can't be
+// fetched or copied doesn't stop the link being saved; the result says
+// why instead.
+func (a *Adder) Add(ctx context.Context, url string, opts Options) (*Result, error) {
+	if opts.Canonicalize {
+		canonical, err := model.CanonicalizeURL(url)
+		if err != nil {
+			return nil, fmt.Errorf("invalid URL: %w", err)
+		}
+		url = canonical
+	}
+
+	link := &model.Link{
+		URL:   url,
+		Title: opts.Title,
+		Tags:  opts.Tags,
+	}
+	if opts.Note != "" {
+		link.Annotations = []*model.Annotation{{Text: opts.Note}}
+	}
+	if opts.Priority != nil {
+		link.Priority = *opts.Priority
+	}
+	if err := link.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid URL: %w", err)
+	}
+
+	result := &Result{}
+	// The URL as given is kept as an alias when a redirect moves the link
+	var fetched *fetch.Metadata
+	if opts.Fetch {
+		fetched, result.FetchErr = a.FetchMetadata(ctx, link)
+		if fetched != nil && opts.Canonicalize {
+			if resolved := ResolveRedirect(link.URL, fetched.FinalURL); resolved != "" {
+				if !model.IsTrivialRedirect(link.URL, fetched.FinalURL) {
+					result.AliasURL = link.URL
+				}
+				link.URL = resolved
+			}
+		}
+	}
+
+	_, err := a.storage.FindByURL(ctx, link.URL)
+	result.Updated = err == nil
+
+	created, err := a.storage.Add(ctx, link)
+	if err != nil {
+		return nil, fmt.Errorf("add link: %w", err)
+	}
+	result.Link = created
+	// Merging keeps a saved link's priority unless given another level,
+	// so asking for normal has to be applied on its own
+	if opts.Priority != nil && created.Priority != *opts.Priority {
+		if err := a.storage.SetPriority(ctx, created.ID, *opts.Priority); err != nil {
+			return nil, fmt.Errorf("set priority: %w", err)
+		}
+		created.Priority = *opts.Priority
+	}
+
+	if result.AliasURL != "" {
+		if err := a.storage.AddURLAlias(ctx, created.ID, result.AliasURL); err != nil {
+			return nil, fmt.Errorf("record original URL: %w", err)
+		}
+	}
+	if fetched != nil {
+		if fetched.Text != "" {
+			if err := a.storage.SetContent(ctx, created.ID, fetched.Text); err != nil {
+				return nil, fmt.Errorf("archive content: %w", err)
+			}
+		}
+		if err := a.RecordFetch(ctx, created.ID, fetched.Validators); err != nil {
+			return nil, fmt.Errorf("record fetch: %w", err)
+		}
+	}
+	// Without a fetch there's no telling whether the URL is a document
+	// until it is downloaded
+	if opts.Attach && (fetched == nil || fetch.IsDocument(fetched.ContentType)) {
+		result.Attachment, result.AttachErr = a.SaveAttachment(ctx, created)
+	}
+	return result, nil
+}
+
+// FetchMetadata downloads a link's page and fills in its title (if
+// empty), description, word count, and estimated reading time. The
+// returned metadata also carries the URL reached after redirects and the
+// article text.
+func (a *Adder) FetchMetadata(ctx context.Context, link *model.Link) (*fetch.Metadata, error) {
+	ctx, cancel := context.WithTimeout(ctx, FetchTimeout)
+	defer cancel()
+
+	meta, err := a.fetcher.Fetch(ctx, link.URL)
+	if err != nil {
+		return nil, err
+	}
+	meta.Apply(link)
+	return meta, nil
+}
+
+// RecordFetch notes that a link's page was fetched just now, keeping its
+// validators for the next conditional fetch.
+func (a *Adder) RecordFetch(ctx context.Context, id string, v fetch.Validators) error {
+	return a.storage.SetFetchState(ctx, storage.FetchState{
+		LinkID:       id,
+		ETag:         v.ETag,
+		LastModified: v.LastModified,
+		FetchedAt:    time.Now(),
+	})
+}
+
+// SaveAttachment downloads the file a link points to into the attachments
+// directory and records it. Web pages and other files that aren't
+// documents are refused.
+func (a *Adder) SaveAttachment(ctx context.Context, link *model.Link) (*model.Attachment, error) {
+	if a.attachments == nil {
+		return nil, fmt.Errorf("no attachments directory for this database")
+	}
+	dl, err := a.fetcher.Download(ctx, link.URL)
+	if err != nil {
+		return nil, err
+	}
+	defer dl.Close()
+	if !fetch.IsDocument(dl.ContentType) {
+		contentType := dl.ContentType
+		if contentType == "" {
+			contentType = "unknown type"
+		}
+		return nil, fmt.Errorf("%s is not a document (%s)", link.URL, contentType)
+	}
+
+	sum, rel, size, err := a.attachments.Save(dl, attach.Extension(link.URL, dl.ContentType))
+	if err != nil {
+		return nil, err
+	}
+	att := &model.Attachment{LinkID: link.ID, SHA256: sum, Path: rel, ContentType: dl.ContentType, Size: size}
+	if err := a.storage.SetAttachment(ctx, att); err != nil {
+		return nil, err
+	}
+	return att, nil
+}
+
+// ResolveRedirect returns the canonical form of the URL a request for
+// current ended up at, or "" if following redirects didn't change it.
+func ResolveRedirect(current, final string) string {
+	if final == "" {
+		return ""
+	}
+	resolved, err := model.CanonicalizeURL(final)
+	if err != nil || resolved == current {
+		return ""
+	}
+	return resolved
+}
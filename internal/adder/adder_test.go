@@ -0,0 +1,164 @@
+package adder
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bunchhieng/rl/internal/attach"
+	"github.com/bunchhieng/rl/internal/fetch"
+	"github.com/bunchhieng/rl/internal/model"
+	"github.com/bunchhieng/rl/internal/storage"
+)
+
+func newTestAdder(t *testing.T) (*Adder, *storage.SQLiteStorage) {
+	t.Helper()
+	s, err := storage.NewSQLiteStorage(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return New(s, fetch.NewClient(), attach.NewStore(t.TempDir())), s
+}
+
+func TestAdd(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/old":
+			http.Redirect(w, r, "/article", http.StatusMovedPermanently)
+		case "/article":
+			w.Header().Set("Content-Type", "text/html")
+			w.Header().Set("ETag", `"v1"`)
+			w.Write([]byte(`<html><head><title>An Article</title></head><body><article><p>Some words to read.</p></article></body></html>`))
+		case "/paper.pdf":
+			w.Header().Set("Content-Type", "application/pdf")
+			w.Write([]byte("%PDF-1.4"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	ctx := context.Background()
+	a, s := newTestAdder(t)
+
+	// A fetch fills in the page, keeps its text, and notes the redirect
+	result, err := a.Add(ctx, srv.URL+"/old", Options{Tags: "go", Fetch: true, Canonicalize: true})
+	if err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	link := result.Link
+	if link.Title != "An Article" || !strings.HasSuffix(link.URL, "/article") || link.Tags != "go" {
+		t.Errorf("Expected the fetched page saved at its final URL, got %+v", link)
+	}
+	if result.Updated || result.FetchErr != nil || !strings.HasSuffix(result.AliasURL, "/old") {
+		t.Errorf("Unexpected result %+v", result)
+	}
+	if found, err := s.FindByURL(ctx, result.AliasURL); err != nil || found.ID != link.ID {
+		t.Errorf("Expected the original URL kept as an alias, got %v", err)
+	}
+	if text, _ := s.Content(ctx, link.ID); text != "Some words to read." {
+		t.Errorf("Expected the article text archived, got %q", text)
+	}
+	if state, err := s.FetchState(ctx, link.ID); err != nil || state.ETag != `"v1"` {
+		t.Errorf("Expected the fetch recorded, got %+v (%v)", state, err)
+	}
+
+	// Saving it again merges into the same link
+	result, err = a.Add(ctx, link.URL, Options{})
+	if err != nil || !result.Updated || result.Link.ID != link.ID {
+		t.Errorf("Expected the link updated, got %+v (%v)", result, err)
+	}
+
+	// A page that can't be fetched is saved anyway
+	result, err = a.Add(ctx, srv.URL+"/missing", Options{Fetch: true})
+	if err != nil || result.FetchErr == nil || result.Link == nil {
+		t.Errorf("Expected the link saved with the fetch error, got %+v (%v)", result, err)
+	}
+
+	// Documents get a copy, web pages don't
+	result, err = a.Add(ctx, srv.URL+"/paper.pdf", Options{Fetch: true, Attach: true})
+	if err != nil || result.Attachment == nil || result.AttachErr != nil {
+		t.Errorf("Expected a copy of the PDF, got %+v (%v)", result, err)
+	}
+	result, err = a.Add(ctx, srv.URL+"/article", Options{Attach: true})
+	if err != nil || result.Attachment != nil || result.AttachErr == nil {
+		t.Errorf("Expected a web page refused as an attachment, got %+v (%v)", result, err)
+	}
+
+	if _, err := a.Add(ctx, "not a url", Options{}); err == nil {
+		t.Error("Expected an invalid URL to be refused")
+	}
+}
+
+func TestAddPriority(t *testing.T) {
+	a, s := newTestAdder(t)
+	ctx := context.Background()
+	level := func(p model.Priority) *model.Priority { return &p }
+
+	result, err := a.Add(ctx, "https://example.com/a", Options{Priority: level(model.PriorityHigh)})
+	if err != nil || result.Link.Priority != model.PriorityHigh {
+		t.Fatalf("Add(high) = %+v, %v", result, err)
+	}
+	// Saving it again without a priority keeps the one it has
+	if result, err = a.Add(ctx, "https://example.com/a", Options{}); err != nil || result.Link.Priority != model.PriorityHigh {
+		t.Errorf("Expected re-adding without a priority to keep high, got %+v, %v", result, err)
+	}
+	// Asking for normal resets it
+	if result, err = a.Add(ctx, "https://example.com/a", Options{Priority: level(model.PriorityNormal)}); err != nil || result.Link.Priority != model.PriorityNormal {
+		t.Errorf("Expected re-adding with normal to reset the priority, got %+v, %v", result, err)
+	}
+	if link, _ := s.Get(ctx, result.Link.ID); link.Priority != model.PriorityNormal {
+		t.Errorf("Expected normal saved, got %v", link.Priority)
+	}
+}
+
+func TestAddNote(t *testing.T) {
+	a, s := newTestAdder(t)
+	ctx := context.Background()
+
+	result, err := a.Add(ctx, "https://example.com/a", Options{Note: "read the intro"})
+	if err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	// The same note given again isn't repeated; a new one is added
+	for _, note := range []string{"read the intro", "", "then part 2"} {
+		if _, err := a.Add(ctx, "https://example.com/a", Options{Note: note}); err != nil {
+			t.Fatalf("Add(%q) failed: %v", note, err)
+		}
+	}
+	notes, err := s.Annotations(ctx, result.Link.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(notes) != 2 || notes[0].Text != "read the intro" || notes[1].Text != "then part 2" {
+		t.Errorf("Expected the two notes, oldest first, got %+v", notes)
+	}
+}
+
+func TestAddDescription(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<html><head><title>Go</title><meta name="description" content="The Go blog."></head><body></body></html>`))
+	}))
+	defer srv.Close()
+	ctx := context.Background()
+	a, s := newTestAdder(t)
+
+	result, err := a.Add(ctx, srv.URL+"/blog", Options{Fetch: true})
+	if err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if link, err := s.Get(ctx, result.Link.ID); err != nil || link.Description != "The Go blog." {
+		t.Errorf("Expected the page's description saved, got %+v (%v)", link, err)
+	}
+
+	// Saving it again without a fetch keeps the description
+	if _, err := a.Add(ctx, srv.URL+"/blog", Options{Tags: "go"}); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if link, _ := s.Get(ctx, result.Link.ID); link.Description != "The Go blog." || link.Tags != "go" {
+		t.Errorf("Expected the description kept, got %+v", link)
+	}
+}
@@ -1,9 +1,14 @@
 package app
 
 import (
+	"context"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
+	"github.com/bunchhieng/rl/internal/backup"
 	"github.com/bunchhieng/rl/internal/storage"
 )
 
@@ -16,14 +21,195 @@ func DefaultDBPath() (string, error) {
 	return filepath.Join(configDir, "rl", "links.db"), nil
 }
 
-// NewStorage creates a new storage instance with the default database path.
-func NewStorage(dbPath string) (storage.Storage, error) {
+// ResolveDBPath returns dbPath, or the default database path if it is empty.
+func ResolveDBPath(dbPath string) (string, error) {
 	if dbPath == "" {
-		var err error
-		dbPath, err = DefaultDBPath()
+		return DefaultDBPath()
+	}
+	return dbPath, nil
+}
+
+// ListingPath returns the file caching the IDs of the last listing, kept
+// next to the database so separate databases don't share indices.
+func ListingPath(dbPath string) (string, error) {
+	if dbPath == ":memory:" {
+		return "", nil
+	}
+	dbPath, err := ResolveDBPath(dbPath)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(dbPath), "last-listing.json"), nil
+}
+
+// SearchHistoryPath returns the file the TUI keeps recent searches in,
+// next to the database like ListingPath.
+func SearchHistoryPath(dbPath string) (string, error) {
+	if dbPath == ":memory:" {
+		return "", nil
+	}
+	dbPath, err := ResolveDBPath(dbPath)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(dbPath), "search-history.json"), nil
+}
+
+// RemindStatePath returns the file rl remind records its last check in,
+// next to the database like ListingPath.
+func RemindStatePath(dbPath string) (string, error) {
+	if dbPath == ":memory:" {
+		return "", nil
+	}
+	dbPath, err := ResolveDBPath(dbPath)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(dbPath), "remind.json"), nil
+}
+
+// DaemonStatePath returns the file rl daemon records its jobs' last runs
+// in, next to the database like ListingPath.
+func DaemonStatePath(dbPath string) (string, error) {
+	if dbPath == ":memory:" {
+		return "", nil
+	}
+	dbPath, err := ResolveDBPath(dbPath)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(dbPath), "daemon.json"), nil
+}
+
+// AttachmentsPath returns the directory saved documents are kept in, named
+// after the database ("links-attachments" for links.db) so separate
+// databases in one directory keep separate copies.
+func AttachmentsPath(dbPath string) (string, error) {
+	if dbPath == ":memory:" {
+		return "", nil
+	}
+	dbPath, err := ResolveDBPath(dbPath)
+	if err != nil {
+		return "", err
+	}
+	name := strings.TrimSuffix(filepath.Base(dbPath), filepath.Ext(dbPath))
+	return filepath.Join(filepath.Dir(dbPath), name+"-attachments"), nil
+}
+
+// BackupsPath returns the directory database snapshots are kept in, named
+// after the database like AttachmentsPath ("links-backups" for links.db).
+func BackupsPath(dbPath string) (string, error) {
+	if dbPath == ":memory:" {
+		return "", nil
+	}
+	dbPath, err := ResolveDBPath(dbPath)
+	if err != nil {
+		return "", err
+	}
+	name := strings.TrimSuffix(filepath.Base(dbPath), filepath.Ext(dbPath))
+	return filepath.Join(filepath.Dir(dbPath), name+"-backups"), nil
+}
+
+// Options controls how the database is opened.
+type Options struct {
+	// Timeout limits each database operation; zero means no limit.
+	Timeout time.Duration
+	// ReadOnly opens the database so that nothing can write to it.
+	ReadOnly bool
+	// OnBusy is called when an operation starts waiting for another
+	// process to release the database (see SQLiteStorage.OnBusy).
+	OnBusy func()
+	// IDScheme and IDLength set how new links' IDs are made (see
+	// SQLiteStorage.SetIDScheme).
+	IDScheme string
+	IDLength int
+	// Backups, if set, snapshots a database before migrations change it.
+	Backups *backup.Store
+}
+
+// NewStorage creates a new storage instance with the default database path,
+// applying pending migrations. A read-only database must exist and be
+// fully migrated already.
+func NewStorage(dbPath string, opts Options) (storage.Storage, error) {
+	dbPath, err := ResolveDBPath(dbPath)
+	if err != nil {
+		return nil, err
+	}
+	if !opts.ReadOnly {
+		s, err := storage.OpenSQLiteStorage(dbPath)
 		if err != nil {
 			return nil, err
 		}
+		if err := migrate(s, opts.Backups); err != nil {
+			s.Close()
+			return nil, err
+		}
+		s.SetTimeout(opts.Timeout)
+		s.OnBusy(opts.OnBusy)
+		s.SetIDScheme(opts.IDScheme, opts.IDLength)
+		return s, nil
+	}
+
+	s, err := OpenDB(dbPath, opts)
+	if err != nil {
+		return nil, err
+	}
+	migrations, err := s.Migrations(context.Background())
+	if err != nil {
+		s.Close()
+		return nil, err
+	}
+	for _, m := range migrations {
+		if m.AppliedAt == nil {
+			s.Close()
+			return nil, fmt.Errorf("the database needs migrating, which --read-only prevents; run rl once without it")
+		}
+	}
+	return s, nil
+}
+
+// migrate applies pending migrations, snapshotting the database into
+// backups first unless it is new.
+func migrate(s *storage.SQLiteStorage, backups *backup.Store) error {
+	ctx := context.Background()
+	if backups != nil {
+		migrations, err := s.Migrations(ctx)
+		if err != nil {
+			return err
+		}
+		applied, pending := 0, 0
+		for _, m := range migrations {
+			if m.AppliedAt != nil {
+				applied++
+			} else {
+				pending++
+			}
+		}
+		if applied > 0 && pending > 0 {
+			if _, err := backups.Create(ctx, s, backup.ReasonMigrate); err != nil {
+				return fmt.Errorf("back up before migrating: %w", err)
+			}
+		}
+	}
+	return s.Migrate(ctx)
+}
+
+// OpenDB opens the database without applying migrations, for commands that
+// manage its schema.
+func OpenDB(dbPath string, opts Options) (*storage.SQLiteStorage, error) {
+	dbPath, err := ResolveDBPath(dbPath)
+	if err != nil {
+		return nil, err
+	}
+	open := storage.OpenSQLiteStorage
+	if opts.ReadOnly {
+		open = storage.OpenSQLiteStorageReadOnly
+	}
+	s, err := open(dbPath)
+	if err != nil {
+		return nil, err
 	}
-	return storage.NewSQLiteStorage(dbPath)
+	s.SetTimeout(opts.Timeout)
+	s.OnBusy(opts.OnBusy)
+	return s, nil
 }
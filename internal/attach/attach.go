@@ -0,0 +1,149 @@
+// Package attach keeps downloaded copies of documents in a
+// content-addressed directory, so a file saved for several links is stored
+// once.
+package attach
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Store is a directory of files named by the SHA-256 of their contents,
+// fanned out into subdirectories by the first two hex digits.
+type Store struct {
+	dir string
+}
+
+// NewStore returns a store rooted at dir, which is created on first save.
+func NewStore(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+// Dir returns the store's root directory.
+func (s *Store) Dir() string {
+	return s.dir
+}
+
+// Path returns the absolute location of a file saved under rel.
+func (s *Store) Path(rel string) string {
+	return filepath.Join(s.dir, filepath.FromSlash(rel))
+}
+
+// Save copies r into the store with the given extension (".pdf"), returning
+// the hex digest of its contents, its path relative to the store, and its
+// size. Saving contents already in the store keeps the existing file.
+func (s *Store) Save(r io.Reader, ext string) (sum, rel string, size int64, err error) {
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return "", "", 0, fmt.Errorf("create attachments directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(s.dir, ".download-*")
+	if err != nil {
+		return "", "", 0, fmt.Errorf("create attachment: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	hash := sha256.New()
+	size, err = io.Copy(io.MultiWriter(tmp, hash), r)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", "", 0, fmt.Errorf("write attachment: %w", err)
+	}
+
+	sum = hex.EncodeToString(hash.Sum(nil))
+	rel = path.Join(sum[:2], sum+ext)
+	dest := s.Path(rel)
+	if _, err := os.Stat(dest); err == nil {
+		return sum, rel, size, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+		return "", "", 0, fmt.Errorf("create attachments directory: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), dest); err != nil {
+		return "", "", 0, fmt.Errorf("save attachment: %w", err)
+	}
+	return sum, rel, size, nil
+}
+
+// Prune deletes files in the store whose relative paths are not in keep,
+// returning how many were removed. Downloads still being written are left
+// alone. A missing store has nothing to prune.
+func (s *Store) Prune(keep map[string]bool) (int, error) {
+	removed := 0
+	err := filepath.WalkDir(s.dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		// Directories, and temp files of downloads still in progress
+		if d.IsDir() || strings.HasPrefix(d.Name(), ".") {
+			return nil
+		}
+		rel, err := filepath.Rel(s.dir, p)
+		if err != nil {
+			return err
+		}
+		if keep[filepath.ToSlash(rel)] {
+			return nil
+		}
+		if err := os.Remove(p); err != nil {
+			return err
+		}
+		removed++
+		return nil
+	})
+	if err != nil {
+		return removed, fmt.Errorf("prune attachments: %w", err)
+	}
+	return removed, nil
+}
+
+// Extensions for document types whose registered extensions vary by
+// platform.
+var extensions = map[string]string{
+	"application/pdf":      ".pdf",
+	"application/epub+zip": ".epub",
+	"application/msword":   ".doc",
+	"application/rtf":      ".rtf",
+	"text/plain":           ".txt",
+	"application/vnd.openxmlformats-officedocument.wordprocessingml.document":   ".docx",
+	"application/vnd.openxmlformats-officedocument.presentationml.presentation": ".pptx",
+	"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet":         ".xlsx",
+}
+
+var plainExt = regexp.MustCompile(`^\.[A-Za-z0-9]{1,5}$`)
+
+// Extension picks the file extension for a download: the URL's own if it
+// has a plain one, otherwise one matching the Content-Type, or "".
+func Extension(rawURL, contentType string) string {
+	if u, err := url.Parse(rawURL); err == nil {
+		if ext := path.Ext(u.Path); plainExt.MatchString(ext) {
+			return strings.ToLower(ext)
+		}
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return ""
+	}
+	if ext, ok := extensions[mediaType]; ok {
+		return ext
+	}
+	if exts, _ := mime.ExtensionsByType(mediaType); len(exts) > 0 {
+		return exts[0]
+	}
+	return ""
+}
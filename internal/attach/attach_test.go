@@ -0,0 +1,76 @@
+package attach
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestSave(t *testing.T) {
+	store := NewStore(t.TempDir())
+
+	sum, rel, size, err := store.Save(strings.NewReader("%PDF-1.7 hello"), ".pdf")
+	if err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if size != 14 || len(sum) != 64 {
+		t.Errorf("Expected 14 bytes and a SHA-256 digest, got %d bytes, %q", size, sum)
+	}
+	if want := sum[:2] + "/" + sum + ".pdf"; rel != want {
+		t.Errorf("Expected path %q, got %q", want, rel)
+	}
+	data, err := os.ReadFile(store.Path(rel))
+	if err != nil || string(data) != "%PDF-1.7 hello" {
+		t.Fatalf("Expected saved contents, got %q (%v)", data, err)
+	}
+
+	// The same contents land on the same file
+	again, rel2, _, err := store.Save(strings.NewReader("%PDF-1.7 hello"), ".pdf")
+	if err != nil || again != sum || rel2 != rel {
+		t.Errorf("Expected identical contents to reuse %q, got %q (%v)", rel, rel2, err)
+	}
+	entries, _ := os.ReadDir(store.Dir())
+	if len(entries) != 1 {
+		t.Errorf("Expected one fan-out directory and no temp files, got %d entries", len(entries))
+	}
+}
+
+func TestPrune(t *testing.T) {
+	store := NewStore(t.TempDir())
+	_, keep, _, _ := store.Save(strings.NewReader("keep"), ".pdf")
+	_, drop, _, _ := store.Save(strings.NewReader("drop"), ".pdf")
+
+	removed, err := store.Prune(map[string]bool{keep: true})
+	if err != nil {
+		t.Fatalf("Prune failed: %v", err)
+	}
+	if removed != 1 {
+		t.Errorf("Expected 1 file removed, got %d", removed)
+	}
+	if _, err := os.Stat(store.Path(keep)); err != nil {
+		t.Errorf("Expected kept file to remain: %v", err)
+	}
+	if _, err := os.Stat(store.Path(drop)); !os.IsNotExist(err) {
+		t.Errorf("Expected unreferenced file to be removed, got %v", err)
+	}
+
+	if removed, err := NewStore(t.TempDir() + "/missing").Prune(nil); err != nil || removed != 0 {
+		t.Errorf("Expected nothing to prune in a missing store, got %d (%v)", removed, err)
+	}
+}
+
+func TestExtension(t *testing.T) {
+	tests := []struct {
+		url, contentType, want string
+	}{
+		{"https://example.com/paper.PDF", "", ".pdf"},
+		{"https://example.com/download?id=3", "application/pdf", ".pdf"},
+		{"https://example.com/book", "application/epub+zip; charset=binary", ".epub"},
+		{"https://example.com/v1.2/file", "application/x-unknown", ""},
+	}
+	for _, tt := range tests {
+		if got := Extension(tt.url, tt.contentType); got != tt.want {
+			t.Errorf("Extension(%q, %q) = %q, want %q", tt.url, tt.contentType, got, tt.want)
+		}
+	}
+}
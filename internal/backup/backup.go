@@ -0,0 +1,296 @@
+// Package backup keeps gzipped snapshots of the database, taken on demand,
+// on a schedule, and before migrations and imports, and restores them.
+package backup
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bunchhieng/rl/internal/storage"
+)
+
+// DefaultKeep is how many snapshots are kept when no number is configured.
+const DefaultKeep = 7
+
+// Reasons a snapshot was taken, recorded in its name.
+const (
+	ReasonManual    = "manual"
+	ReasonScheduled = "scheduled"
+	ReasonMigrate   = "migrate"
+	ReasonImport    = "import"
+	// ReasonDoctor marks the database as it was before rl db doctor
+	// --fix repaired it.
+	ReasonDoctor = "doctor"
+	// ReasonRestore marks the database as it was before a restore
+	// replaced it.
+	ReasonRestore = "restore"
+)
+
+const (
+	// timeLayout starts each snapshot's name, so names sort by age.
+	timeLayout = "20060102T150405.000Z"
+	ext        = ".db.gz"
+)
+
+// ErrNotFound is returned for a snapshot name that isn't in the store.
+var ErrNotFound = errors.New("no such backup")
+
+// Source is a database that can copy itself, such as storage.Storage.
+type Source interface {
+	BackupTo(ctx context.Context, path string) error
+}
+
+// Snapshot is one backup of the database.
+type Snapshot struct {
+	Name   string    `json:"name"`
+	Path   string    `json:"path"`
+	Time   time.Time `json:"time"`
+	Reason string    `json:"reason"`
+	// Size is the compressed size in bytes.
+	Size int64 `json:"size"`
+}
+
+// Store is a directory of snapshots named by when and why they were
+// taken, e.g. "20261017T061208.000Z-manual.db.gz".
+type Store struct {
+	dir  string
+	keep int
+}
+
+// NewStore returns a store in dir, which is created on first backup,
+// keeping the newest keep snapshots (DefaultKeep if keep isn't positive).
+func NewStore(dir string, keep int) *Store {
+	if keep <= 0 {
+		keep = DefaultKeep
+	}
+	return &Store{dir: dir, keep: keep}
+}
+
+// Dir returns the directory the snapshots are kept in.
+func (s *Store) Dir() string {
+	return s.dir
+}
+
+// Create snapshots src, then deletes the oldest snapshots beyond those
+// the store keeps.
+func (s *Store) Create(ctx context.Context, src Source, reason string) (*Snapshot, error) {
+	// Snapshots hold the whole reading list, so only the owner can read them
+	if err := os.MkdirAll(s.dir, 0o700); err != nil {
+		return nil, fmt.Errorf("create backups directory: %w", err)
+	}
+
+	// VACUUM INTO refuses to overwrite a file, so reserve a name and free it
+	tmp, err := os.CreateTemp(s.dir, ".snapshot-*.db")
+	if err != nil {
+		return nil, fmt.Errorf("create backup: %w", err)
+	}
+	tmp.Close()
+	os.Remove(tmp.Name())
+	defer os.Remove(tmp.Name())
+	if err := src.BackupTo(ctx, tmp.Name()); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	snap := &Snapshot{Name: now.Format(timeLayout) + "-" + reason + ext, Time: now, Reason: reason}
+	snap.Path = filepath.Join(s.dir, snap.Name)
+	if snap.Size, err = compress(tmp.Name(), snap.Path); err != nil {
+		return nil, err
+	}
+	if err := s.prune(); err != nil {
+		return nil, err
+	}
+	return snap, nil
+}
+
+// compress gzips src into dst, which appears only once complete.
+func compress(src, dst string) (int64, error) {
+	in, err := os.Open(src)
+	if err != nil {
+		return 0, fmt.Errorf("read backup: %w", err)
+	}
+	defer in.Close()
+
+	out, err := os.CreateTemp(filepath.Dir(dst), ".snapshot-*.gz")
+	if err != nil {
+		return 0, fmt.Errorf("create backup: %w", err)
+	}
+	defer os.Remove(out.Name())
+
+	zw := gzip.NewWriter(out)
+	_, err = io.Copy(zw, in)
+	if closeErr := zw.Close(); err == nil {
+		err = closeErr
+	}
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return 0, fmt.Errorf("write backup: %w", err)
+	}
+	info, err := os.Stat(out.Name())
+	if err != nil {
+		return 0, fmt.Errorf("write backup: %w", err)
+	}
+	if err := os.Rename(out.Name(), dst); err != nil {
+		return 0, fmt.Errorf("write backup: %w", err)
+	}
+	return info.Size(), nil
+}
+
+// List returns the snapshots in the store, newest first.
+func (s *Store) List() ([]*Snapshot, error) {
+	entries, err := os.ReadDir(s.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("list backups: %w", err)
+	}
+
+	var snaps []*Snapshot
+	for _, entry := range entries {
+		name := entry.Name()
+		stamp, reason, ok := strings.Cut(strings.TrimSuffix(name, ext), "-")
+		if !strings.HasSuffix(name, ext) || !ok || entry.IsDir() {
+			continue
+		}
+		t, err := time.Parse(timeLayout, stamp)
+		if err != nil {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		snaps = append(snaps, &Snapshot{Name: name, Path: filepath.Join(s.dir, name), Time: t, Reason: reason, Size: info.Size()})
+	}
+	sort.Slice(snaps, func(i, j int) bool { return snaps[i].Name > snaps[j].Name })
+	return snaps, nil
+}
+
+// Latest returns the newest snapshot, or nil if there are none.
+func (s *Store) Latest() (*Snapshot, error) {
+	snaps, err := s.List()
+	if err != nil || len(snaps) == 0 {
+		return nil, err
+	}
+	return snaps[0], nil
+}
+
+// Find returns the snapshot called name, or the newest for "latest".
+func (s *Store) Find(name string) (*Snapshot, error) {
+	snaps, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+	for _, snap := range snaps {
+		if snap.Name == name || (name == "latest" && snap == snaps[0]) {
+			return snap, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+}
+
+// prune deletes the oldest snapshots beyond those the store keeps.
+func (s *Store) prune() error {
+	snaps, err := s.List()
+	if err != nil {
+		return err
+	}
+	for _, snap := range snaps[min(s.keep, len(snaps)):] {
+		if err := os.Remove(snap.Path); err != nil {
+			return fmt.Errorf("delete old backup: %w", err)
+		}
+	}
+	return nil
+}
+
+// Restore replaces the database at dbPath with snap, after checking that
+// snap is intact. The database being replaced is snapshotted first and
+// that snapshot returned (nil if there was no database). No other process
+// should have the database open.
+func (s *Store) Restore(ctx context.Context, snap *Snapshot, dbPath string) (*Snapshot, error) {
+	// Unpack first: snapshotting the current database may prune snap
+	tmp, err := os.CreateTemp(filepath.Dir(dbPath), ".restore-*.db")
+	if err != nil {
+		return nil, fmt.Errorf("restore backup: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	err = decompress(snap.Path, tmp)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := check(ctx, tmp.Name()); err != nil {
+		return nil, err
+	}
+
+	var replaced *Snapshot
+	if _, err := os.Stat(dbPath); err == nil {
+		db, err := storage.OpenSQLiteStorage(dbPath)
+		if err != nil {
+			return nil, err
+		}
+		replaced, err = s.Create(ctx, db, ReasonRestore)
+		db.Close()
+		if err != nil {
+			return nil, fmt.Errorf("back up the current database: %w", err)
+		}
+	}
+
+	// A write-ahead log left beside the old database would be replayed
+	// into the restored one
+	for _, suffix := range []string{"-wal", "-shm"} {
+		if err := os.Remove(dbPath + suffix); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("restore backup: %w", err)
+		}
+	}
+	if err := os.Rename(tmp.Name(), dbPath); err != nil {
+		return nil, fmt.Errorf("restore backup: %w", err)
+	}
+	return replaced, nil
+}
+
+func decompress(src string, dst io.Writer) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("read backup: %w", err)
+	}
+	defer in.Close()
+	zr, err := gzip.NewReader(in)
+	if err != nil {
+		return fmt.Errorf("read backup: %w", err)
+	}
+	if _, err := io.Copy(dst, zr); err != nil {
+		return fmt.Errorf("read backup: %w", err)
+	}
+	return zr.Close()
+}
+
+// check fails unless the database at path passes SQLite's integrity check.
+func check(ctx context.Context, path string) error {
+	db, err := storage.OpenSQLiteStorage(path)
+	if err != nil {
+		return fmt.Errorf("backup is not a database: %w", err)
+	}
+	defer db.Close()
+	problems, err := db.IntegrityCheck(ctx)
+	if err != nil {
+		return fmt.Errorf("backup is not a database: %w", err)
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("backup is corrupt: %s", problems[0])
+	}
+	return nil
+}
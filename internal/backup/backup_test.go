@@ -0,0 +1,94 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bunchhieng/rl/internal/model"
+	"github.com/bunchhieng/rl/internal/storage"
+)
+
+func TestCreateAndRestore(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "links.db")
+	db, err := storage.NewSQLiteStorage(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	if _, err := db.Add(ctx, &model.Link{URL: "https://example.com/kept"}); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	store := NewStore(filepath.Join(dir, "backups"), 2)
+	snap, err := store.Create(ctx, db, ReasonManual)
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if snap.Reason != ReasonManual || snap.Size == 0 {
+		t.Errorf("Unexpected snapshot %+v", snap)
+	}
+	if info, err := os.Stat(snap.Path); err != nil || info.Mode().Perm() != 0o600 {
+		t.Errorf("Expected a snapshot only its owner can read: %v", err)
+	}
+
+	if _, err := db.Add(ctx, &model.Link{URL: "https://example.com/lost"}); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	db.Close()
+
+	found, err := store.Find("latest")
+	if err != nil || found.Name != snap.Name {
+		t.Fatalf("Find(latest) = %v, %v; want %s", found, err, snap.Name)
+	}
+	replaced, err := store.Restore(ctx, found, dbPath)
+	if err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+	if replaced == nil || replaced.Reason != ReasonRestore {
+		t.Fatalf("Expected the replaced database to be backed up, got %+v", replaced)
+	}
+
+	db, err = storage.NewSQLiteStorage(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to open restored database: %v", err)
+	}
+	defer db.Close()
+	if n, _ := db.Count(ctx, storage.ListOptions{ReadStatus: storage.ReadStatusAll}); n != 1 {
+		t.Errorf("Expected the restored database to have 1 link, got %d", n)
+	}
+
+	// A third snapshot pushes the oldest out
+	if _, err := store.Create(ctx, db, ReasonImport); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	snaps, err := store.List()
+	if err != nil || len(snaps) != 2 {
+		t.Fatalf("Expected 2 snapshots kept, got %d (%v)", len(snaps), err)
+	}
+	if snaps[0].Reason != ReasonImport || snaps[1].Name != replaced.Name {
+		t.Errorf("Expected the newest two snapshots, newest first, got %s and %s", snaps[0].Name, snaps[1].Name)
+	}
+	if _, err := store.Find(snap.Name); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected the oldest snapshot to be deleted, got %v", err)
+	}
+}
+
+func TestRestoreRefusesCorruptBackup(t *testing.T) {
+	dir := t.TempDir()
+	store := NewStore(dir, 0)
+	bad := &Snapshot{Name: "bad", Path: filepath.Join(dir, "bad"+ext)}
+	if err := os.WriteFile(bad.Path, []byte("not gzip"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	dbPath := filepath.Join(dir, "links.db")
+	if _, err := store.Restore(context.Background(), bad, dbPath); err == nil {
+		t.Error("Expected a corrupt backup to be refused")
+	}
+	if _, err := os.Stat(dbPath); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Expected nothing restored, got %v", err)
+	}
+}
@@ -0,0 +1,94 @@
+package browser
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/kballard/go-shellquote"
+)
+
+// Browser opens URLs, either with a user-configured command or the
+// platform's default handler.
+type Browser struct {
+	command string
+}
+
+// New returns a Browser using command, a shell-style template such as
+// "firefox --new-tab %s" or "w3m %s". When command is empty the first entry
+// of $BROWSER is used, falling back to open/xdg-open/start. Templates
+// without %s get the URL appended.
+func New(command string) *Browser {
+	if command == "" {
+		// $BROWSER is conventionally a colon-separated list of candidates
+		command, _, _ = strings.Cut(os.Getenv("BROWSER"), ":")
+	}
+	return &Browser{command: strings.TrimSpace(command)}
+}
+
+// Custom reports whether a user-configured command is used. Such commands
+// may be terminal browsers, so they run attached to the terminal.
+func (b *Browser) Custom() bool {
+	return b.command != ""
+}
+
+// Command returns the command that opens url.
+func (b *Browser) Command(url string) (*exec.Cmd, error) {
+	if b.Custom() {
+		args, err := expand(b.command, url)
+		if err != nil {
+			return nil, err
+		}
+		cmd := exec.Command(args[0], args[1:]...)
+		cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+		return cmd, nil
+	}
+
+	switch runtime.GOOS {
+	case "darwin":
+		return exec.Command("open", url), nil
+	case "linux":
+		return exec.Command("xdg-open", url), nil
+	case "windows":
+		return exec.Command("cmd", "/c", "start", url), nil
+	default:
+		return nil, fmt.Errorf("unsupported operating system: %s", runtime.GOOS)
+	}
+}
+
+// Open opens url and waits for the command to exit.
+func (b *Browser) Open(url string) error {
+	cmd, err := b.Command(url)
+	if err != nil {
+		return err
+	}
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("open browser: %w", err)
+	}
+	return nil
+}
+
+// expand splits a command template into arguments, substituting url for
+// each %s (or appending it if there is none).
+func expand(template, url string) ([]string, error) {
+	args, err := shellquote.Split(template)
+	if err != nil {
+		return nil, fmt.Errorf("invalid browser command %q: %w", template, err)
+	}
+	if len(args) == 0 {
+		return nil, fmt.Errorf("empty browser command")
+	}
+	substituted := false
+	for i, arg := range args {
+		if strings.Contains(arg, "%s") {
+			args[i] = strings.ReplaceAll(arg, "%s", url)
+			substituted = true
+		}
+	}
+	if !substituted {
+		args = append(args, url)
+	}
+	return args, nil
+}
@@ -0,0 +1,32 @@
+package browser
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExpand(t *testing.T) {
+	const url = "https://example.com/a?b=1&c=2"
+	tests := []struct {
+		template string
+		want     []string
+	}{
+		{"firefox --new-tab %s", []string{"firefox", "--new-tab", url}},
+		{"w3m", []string{"w3m", url}},
+		{`"/Applications/My Browser" --url=%s`, []string{"/Applications/My Browser", "--url=" + url}},
+	}
+	for _, tt := range tests {
+		got, err := expand(tt.template, url)
+		if err != nil {
+			t.Errorf("expand(%q) error: %v", tt.template, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("expand(%q) = %q, want %q", tt.template, got, tt.want)
+		}
+	}
+
+	if _, err := expand(`firefox "unterminated`, url); err == nil {
+		t.Error("Expected error for unbalanced quotes")
+	}
+}
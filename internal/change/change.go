@@ -0,0 +1,58 @@
+// Package change measures how much a page's text differs between two
+// fetches, so reference links whose content was rewritten or removed can
+// be flagged.
+package change
+
+import (
+	"strings"
+	"unicode"
+)
+
+// shingleSize is the number of consecutive words compared as a unit.
+// Phrases rather than single words catch reordered and rewritten text
+// while ignoring changes that only touch a word here and there.
+const shingleSize = 3
+
+// Measure returns how different new is from old, from 0 (the same
+// phrases) to 1 (nothing in common): the share of three-word phrases in
+// either text that the other lacks. Case and punctuation are ignored.
+func Measure(old, new string) float64 {
+	a, b := shingles(old), shingles(new)
+	if len(a) == 0 && len(b) == 0 {
+		return 0
+	}
+	shared := 0
+	for s := range a {
+		if b[s] {
+			shared++
+		}
+	}
+	union := len(a) + len(b) - shared
+	return 1 - float64(shared)/float64(union)
+}
+
+// Words counts the words in text as Measure sees them.
+func Words(text string) int {
+	return len(words(text))
+}
+
+func shingles(text string) map[string]bool {
+	w := words(text)
+	set := make(map[string]bool)
+	if len(w) < shingleSize {
+		if len(w) > 0 {
+			set[strings.Join(w, " ")] = true
+		}
+		return set
+	}
+	for i := 0; i+shingleSize <= len(w); i++ {
+		set[strings.Join(w[i:i+shingleSize], " ")] = true
+	}
+	return set
+}
+
+func words(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
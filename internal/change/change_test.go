@@ -0,0 +1,34 @@
+package change
+
+import (
+	"math"
+	"testing"
+)
+
+func TestMeasure(t *testing.T) {
+	const text = "The quick brown fox jumps over the lazy dog near the river bank."
+	tests := []struct {
+		name     string
+		old, new string
+		min, max float64
+	}{
+		{"identical", text, text, 0, 0},
+		{"case and punctuation", text, "the QUICK brown fox -- jumps over the lazy dog, near the river bank", 0, 0},
+		{"one word", text, "The quick brown fox leaps over the lazy dog near the river bank.", 0.2, 0.5},
+		{"rewritten", text, "An entirely different article about cooking pasta at home.", 1, 1},
+		{"emptied", text, "", 1, 1},
+		{"both empty", "", "", 0, 0},
+	}
+	for _, tt := range tests {
+		got := Measure(tt.old, tt.new)
+		if got < tt.min-1e-9 || got > tt.max+1e-9 || math.IsNaN(got) {
+			t.Errorf("%s: Measure = %.2f, want between %.2f and %.2f", tt.name, got, tt.min, tt.max)
+		}
+	}
+}
+
+func TestWords(t *testing.T) {
+	if got := Words("Hello, world -- it's 2024!"); got != 5 {
+		t.Errorf("Expected 5 words, got %d", got)
+	}
+}
@@ -0,0 +1,64 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/bunchhieng/rl/internal/model"
+	"github.com/bunchhieng/rl/internal/storage"
+)
+
+// Alias names a link so the name can be used anywhere an ID is accepted.
+func (c *Commands) Alias(id, name string) error {
+	if !model.ValidateAlias(name) {
+		return model.ErrInvalidAlias
+	}
+	id, err := c.resolveID(id)
+	if err != nil {
+		return err
+	}
+	if err := c.storage.SetAlias(c.ctx, id, name); err != nil {
+		return c.handleNotFound(err, id, "set alias")
+	}
+	fmt.Printf("%sAliased%s link %s%s%s as %s%s%s.\n", colorGreen, colorReset, colorBold, id, colorReset, colorCyan, name, colorReset)
+	return nil
+}
+
+// Unalias removes a link alias.
+func (c *Commands) Unalias(name string) error {
+	if err := c.storage.RemoveAlias(c.ctx, name); err != nil {
+		if err == model.ErrNotFound {
+			return fmt.Errorf("alias %s%s%s not found", colorBold, name, colorReset)
+		}
+		return err
+	}
+	fmt.Printf("%sRemoved%s alias %s%s%s.\n", colorRed, colorReset, colorBold, name, colorReset)
+	return nil
+}
+
+// Aliases lists all link aliases.
+func (c *Commands) Aliases() error {
+	aliases, err := c.storage.Aliases(c.ctx)
+	if err != nil {
+		return err
+	}
+	if c.jsonOutput {
+		if aliases == nil {
+			aliases = []storage.Alias{}
+		}
+		return printJSON(aliases)
+	}
+	if len(aliases) == 0 {
+		fmt.Println("No aliases.")
+		return nil
+	}
+	width := 0
+	for _, a := range aliases {
+		if len(a.Name) > width {
+			width = len(a.Name)
+		}
+	}
+	for _, a := range aliases {
+		fmt.Printf("%s%-*s%s  %s%s%s\n", colorCyan, width, a.Name, colorReset, colorDim, a.LinkID, colorReset)
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/bunchhieng/rl/internal/attach"
+	"github.com/bunchhieng/rl/internal/model"
+)
+
+// SetAttachments sets the directory downloaded documents are kept in.
+// Without one, links can't be given a saved copy.
+func (c *Commands) SetAttachments(store *attach.Store) {
+	c.attachments = store
+}
+
+// Attach downloads the documents links point to and keeps a copy of each,
+// replacing any earlier copy.
+func (c *Commands) Attach(ids ...string) error {
+	err := c.forEachID(ids, "attach", func(id string) error {
+		id, err := c.resolveID(id)
+		if err != nil {
+			return err
+		}
+		link, err := c.storage.Get(c.ctx, id)
+		if err != nil {
+			return c.handleNotFound(err, id, "get link")
+		}
+		a, err := c.saveAttachment(link)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%sSaved%s a copy of %s%s%s: %s (%s)\n", colorGreen, colorReset, colorBold, link.ID, colorReset,
+			c.attachments.Path(a.Path), formatSize(a.Size))
+		return nil
+	})
+	if pruneErr := c.pruneAttachments(); err == nil {
+		err = pruneErr
+	}
+	return err
+}
+
+// saveAttachment downloads the file a link points to into the attachments
+// directory and records it.
+func (c *Commands) saveAttachment(link *model.Link) (*model.Attachment, error) {
+	return c.adder().SaveAttachment(c.ctx, link)
+}
+
+// openLocal opens the saved copy of a link's document.
+func (c *Commands) openLocal(link *model.Link) error {
+	a, err := c.storage.Attachment(c.ctx, link.ID)
+	if err == model.ErrNotFound || (err == nil && c.attachments == nil) {
+		return fmt.Errorf("no saved copy of %s (save one with `rl attach %s`)", link.ID, link.ID)
+	}
+	if err != nil {
+		return err
+	}
+	path := c.attachments.Path(a.Path)
+	if err := c.browser.Open(path); err != nil {
+		return err
+	}
+	fmt.Printf("%sOpened:%s %s%s%s\n", colorGreen, colorReset, colorCyan, path, colorReset)
+	return nil
+}
+
+// pruneAttachments deletes saved files no link refers to any more, such
+// as those of deleted links or replaced copies.
+func (c *Commands) pruneAttachments() error {
+	if c.attachments == nil {
+		return nil
+	}
+	attachments, err := c.storage.Attachments(c.ctx)
+	if err != nil {
+		return err
+	}
+	keep := make(map[string]bool, len(attachments))
+	for _, a := range attachments {
+		keep[a.Path] = true
+	}
+	_, err = c.attachments.Prune(keep)
+	return err
+}
+
+// formatSize formats a byte count compactly ("512 B", "1.4 MB").
+func formatSize(n int64) string {
+	const unit = 1000
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "kMGT"[exp])
+}
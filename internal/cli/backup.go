@@ -0,0 +1,122 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/bunchhieng/rl/internal/backup"
+)
+
+// BackupCommands handles rl backup. Restoring replaces the database file,
+// so it runs without the database open.
+type BackupCommands struct {
+	ctx        context.Context
+	store      *backup.Store
+	jsonOutput bool
+}
+
+// NewBackupCommands creates a BackupCommands for the snapshots in store.
+func NewBackupCommands(store *backup.Store) *BackupCommands {
+	return &BackupCommands{ctx: context.Background(), store: store}
+}
+
+// SetContext sets the context commands run under.
+func (b *BackupCommands) SetContext(ctx context.Context) {
+	b.ctx = ctx
+}
+
+// SetJSON enables JSON output.
+func (b *BackupCommands) SetJSON(enabled bool) {
+	b.jsonOutput = enabled
+}
+
+// Now snapshots the database.
+func (b *BackupCommands) Now(src backup.Source) error {
+	snap, err := b.store.Create(b.ctx, src, backup.ReasonManual)
+	if err != nil {
+		return err
+	}
+	if b.jsonOutput {
+		return printJSON(snap)
+	}
+	fmt.Printf("%sBacked up%s to %s (%s).\n", colorGreen, colorReset, snap.Path, formatSize(snap.Size))
+	return nil
+}
+
+// List prints the snapshots, newest first.
+func (b *BackupCommands) List() error {
+	snaps, err := b.store.List()
+	if err != nil {
+		return err
+	}
+	if b.jsonOutput {
+		if snaps == nil {
+			snaps = []*backup.Snapshot{}
+		}
+		return printJSON(snaps)
+	}
+	if len(snaps) == 0 {
+		fmt.Println("No backups yet; run `rl backup now` to make one.")
+		return nil
+	}
+
+	width := 0
+	for _, snap := range snaps {
+		width = max(width, len(snap.Name))
+	}
+	fmt.Printf("%s%-*s  %-19s  %-9s  %s%s\n", colorBold, width, "NAME", "TAKEN", "REASON", "SIZE", colorReset)
+	for _, snap := range snaps {
+		fmt.Printf("%-*s  %-19s  %-9s  %s\n", width, snap.Name, snap.Time.In(displayLocation).Format("2006-01-02 15:04:05"),
+			snap.Reason, formatSize(snap.Size))
+	}
+	fmt.Printf("\n%sIn %s%s\n", colorDim, b.store.Dir(), colorReset)
+	return nil
+}
+
+// Restore replaces the database at dbPath with the snapshot called name
+// ("latest" for the newest), first snapshotting the database it replaces.
+func (b *BackupCommands) Restore(name, dbPath string) error {
+	snap, err := b.store.Find(name)
+	if err != nil {
+		return err
+	}
+	replaced, err := b.store.Restore(b.ctx, snap, dbPath)
+	if err != nil {
+		return err
+	}
+	if b.jsonOutput {
+		return printJSON(struct {
+			Restored *backup.Snapshot `json:"restored"`
+			Replaced *backup.Snapshot `json:"replaced"`
+		}{snap, replaced})
+	}
+	fmt.Printf("%sRestored%s %s.\n", colorGreen, colorReset, snap.Name)
+	if replaced != nil {
+		fmt.Fprintf(os.Stderr, "The database it replaced was backed up as %s.\n", replaced.Name)
+	}
+	return nil
+}
+
+// SetBackups sets where the database is snapshotted before imports and by
+// AutoBackup.
+func (c *Commands) SetBackups(store *backup.Store) {
+	c.backups = store
+}
+
+// AutoBackup snapshots the database when the newest snapshot is older
+// than every, returning the snapshot, or nil if none was due.
+func (c *Commands) AutoBackup(every time.Duration) (*backup.Snapshot, error) {
+	if c.backups == nil || every <= 0 {
+		return nil, nil
+	}
+	latest, err := c.backups.Latest()
+	if err != nil {
+		return nil, err
+	}
+	if latest != nil && time.Since(latest.Time) < every {
+		return nil, nil
+	}
+	return c.backups.Create(c.ctx, c.storage, backup.ReasonScheduled)
+}
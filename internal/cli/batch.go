@@ -0,0 +1,94 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/bunchhieng/rl/internal/model"
+)
+
+// urlPattern finds http(s) URLs in free text.
+var urlPattern = regexp.MustCompile(`https?://[^\s<>"'` + "`" + `]+`)
+
+// ExtractURLs returns the http(s) URLs in text in order of appearance, so
+// both a plain list and pasted prose or Markdown work. Punctuation that
+// usually ends a sentence or wraps a link is trimmed.
+func ExtractURLs(text string) []string {
+	var urls []string
+	for _, match := range urlPattern.FindAllString(text, -1) {
+		match = trimURLPunctuation(match)
+		if strings.Contains(match, "://") && !strings.HasSuffix(match, "://") {
+			urls = append(urls, match)
+		}
+	}
+	return urls
+}
+
+// trimURLPunctuation drops trailing characters that are more likely part
+// of the surrounding text than the URL, keeping balanced parentheses as in
+// Wikipedia links.
+func trimURLPunctuation(s string) string {
+	for s != "" {
+		last := s[len(s)-1]
+		switch {
+		case strings.IndexByte(".,;:!?]}*_", last) >= 0:
+			s = s[:len(s)-1]
+		case last == ')' && strings.Count(s, "(") < strings.Count(s, ")"):
+			s = s[:len(s)-1]
+		default:
+			return s
+		}
+	}
+	return s
+}
+
+// AddAll adds several URLs with the same options, reporting each one and
+// a summary of how many were added, updated, or skipped. Duplicates within
+// urls are skipped; so are URLs that fail, which makes the command fail
+// after the rest have been saved.
+func (c *Commands) AddAll(urls []string, opts AddOptions) error {
+	seen := make(map[string]bool)
+	var saved []*model.Link
+	added, updated, skipped, failed := 0, 0, 0, 0
+	for _, url := range urls {
+		if seen[url] {
+			skipped++
+			continue
+		}
+		seen[url] = true
+
+		result, err := c.addLink(url, opts)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%sSkipped%s %s: %v\n", colorRed, colorReset, url, err)
+			skipped++
+			failed++
+			continue
+		}
+		saved = append(saved, result.Link)
+		if result.Updated {
+			updated++
+		} else {
+			added++
+		}
+		if !c.jsonOutput {
+			printAdded(result)
+		}
+	}
+
+	if c.jsonOutput {
+		if saved == nil {
+			saved = []*model.Link{}
+		}
+		if err := printJSON(saved); err != nil {
+			return err
+		}
+	} else {
+		fmt.Printf("%s%d added, %d updated, %d skipped%s\n", colorBold, added, updated, skipped, colorReset)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d URL(s) could not be added", failed, len(urls))
+	}
+	return nil
+}
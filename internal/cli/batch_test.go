@@ -0,0 +1,24 @@
+package cli
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExtractURLs(t *testing.T) {
+	text := `https://example.com/a
+see https://go.dev/doc, and (https://blog.golang.org/x).
+[link](https://github.com/golang/go) https://en.wikipedia.org/wiki/Go_(game)
+not a url: example.com
+`
+	want := []string{
+		"https://example.com/a",
+		"https://go.dev/doc",
+		"https://blog.golang.org/x",
+		"https://github.com/golang/go",
+		"https://en.wikipedia.org/wiki/Go_(game)",
+	}
+	if got := ExtractURLs(text); !reflect.DeepEqual(got, want) {
+		t.Errorf("ExtractURLs() = %q, want %q", got, want)
+	}
+}
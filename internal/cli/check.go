@@ -0,0 +1,132 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/bunchhieng/rl/internal/fetch"
+	"github.com/bunchhieng/rl/internal/model"
+	"github.com/bunchhieng/rl/internal/storage"
+)
+
+const (
+	checkWorkers = 8
+	checkTimeout = 10 * time.Second
+)
+
+type checkResult struct {
+	link   *model.Link
+	status int
+	err    error
+}
+
+// Check requests every link matching readStatus concurrently, records the
+// HTTP status and check time, and reports links that are gone or unreachable.
+func (c *Commands) Check(readStatus storage.ReadStatus) error {
+	links, err := c.storage.List(c.ctx, storage.ListOptions{ReadStatus: readStatus})
+	if err != nil {
+		return fmt.Errorf("list links: %w", err)
+	}
+	if len(links) == 0 {
+		fmt.Println("No links found.")
+		return nil
+	}
+
+	var dead, ok int
+	err = c.checkLinks(links, func(res checkResult) {
+		checked := res.link
+		switch {
+		case checked.IsDead():
+			dead++
+			reason := fmt.Sprintf("HTTP %d", res.status)
+			if res.err != nil {
+				reason = "unreachable"
+			}
+			fmt.Printf("%sDead%s %s%s%s (%s): %s%s%s\n", colorRed, colorReset, colorBold, checked.ID, colorReset,
+				reason, colorCyan, checked.URL, colorReset)
+		case res.status >= 400:
+			fmt.Printf("%sWarn%s %s%s%s (HTTP %d): %s%s%s\n", colorYellow, colorReset, colorBold, checked.ID, colorReset,
+				res.status, colorCyan, checked.URL, colorReset)
+			ok++
+		default:
+			ok++
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%sChecked%s %d link(s): %s%d ok%s, %s%d dead%s.\n", colorBold, colorReset, len(links),
+		colorGreen, ok, colorReset, colorRed, dead, colorReset)
+	if dead > 0 {
+		fmt.Printf("List them with %srl ls --all --dead%s.\n", colorBold, colorReset)
+	}
+	return nil
+}
+
+// checkLinks requests links concurrently and records each one's HTTP status
+// and check time, calling done with each link as checked. If a check can't
+// be recorded, the rest are cancelled and the first such error returned
+// once every worker has stopped.
+func (c *Commands) checkLinks(links []*model.Link, done func(checkResult)) error {
+	ctx, cancel := context.WithCancel(c.ctx)
+	defer cancel()
+	jobs := make(chan *model.Link)
+	results := make(chan checkResult)
+
+	var wg sync.WaitGroup
+	for i := 0; i < checkWorkers && i < len(links); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for link := range jobs {
+				var validators fetch.Validators
+				if state, err := c.storage.FetchState(ctx, link.ID); err == nil {
+					validators = fetch.Validators{ETag: state.ETag, LastModified: state.LastModified}
+				}
+				checkCtx, cancelCheck := context.WithTimeout(ctx, checkTimeout)
+				status, err := c.fetcher.Check(checkCtx, link.URL, validators)
+				cancelCheck()
+				results <- checkResult{link: link, status: status, err: err}
+			}
+		}()
+	}
+
+	go func() {
+		defer close(jobs)
+		for _, link := range links {
+			select {
+			case jobs <- link:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	// Keep draining results after an error so no worker is left blocked
+	var firstErr error
+	for res := range results {
+		if firstErr != nil {
+			continue
+		}
+		checked := *res.link
+		checkedAt := time.Now()
+		checked.HTTPStatus = res.status
+		checked.CheckedAt = &checkedAt
+		if err := c.storage.RecordCheck(c.ctx, res.link.ID, res.status, checkedAt); err != nil {
+			firstErr = fmt.Errorf("record check for %s: %w", res.link.ID, err)
+			cancel()
+			continue
+		}
+		res.link = &checked
+		done(res)
+	}
+	return firstErr
+}
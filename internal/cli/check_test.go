@@ -0,0 +1,67 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bunchhieng/rl/internal/model"
+	"github.com/bunchhieng/rl/internal/storage"
+)
+
+// failingChecks fails to record every check after the first.
+type failingChecks struct {
+	storage.Storage
+	recorded atomic.Int32
+}
+
+func (s *failingChecks) RecordCheck(ctx context.Context, id string, status int, checkedAt time.Time) error {
+	if s.recorded.Add(1) > 1 {
+		return errors.New("disk full")
+	}
+	return s.Storage.RecordCheck(ctx, id, status, checkedAt)
+}
+
+func TestCheckLinksRecordError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+	_, s := testCommands(t)
+	var links []*model.Link
+	for i := 0; i < 3*checkWorkers; i++ {
+		links = append(links, addLink(t, s, &model.Link{URL: fmt.Sprintf("%s/%d", srv.URL, i)}))
+	}
+
+	c := NewCommands(&failingChecks{Storage: s})
+	checked := 0
+	err := c.checkLinks(links, func(checkResult) { checked++ })
+	if err == nil {
+		t.Fatal("Expected the failure to record a check to be returned")
+	}
+	if checked != 1 {
+		t.Errorf("Expected only the recorded check reported, got %d", checked)
+	}
+
+	// Every worker has stopped rather than blocking on a result nobody reads
+	deadline := time.Now().Add(2 * time.Second)
+	for checkGoroutines() > 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if n := checkGoroutines(); n > 0 {
+		t.Errorf("Expected the check workers to stop, %d left running", n)
+	}
+}
+
+// checkGoroutines counts the goroutines started by checkLinks still
+// running.
+func checkGoroutines() int {
+	buf := make([]byte, 1<<20)
+	stacks := string(buf[:runtime.Stack(buf, true)])
+	return strings.Count(stacks, "(*Commands).checkLinks.func")
+}
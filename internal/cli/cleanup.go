@@ -0,0 +1,167 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bunchhieng/rl/internal/model"
+	"github.com/bunchhieng/rl/internal/storage"
+)
+
+// CleanupPolicy says which links `rl cleanup` clears out. A zero duration
+// turns that policy off.
+type CleanupPolicy struct {
+	// ArchiveReadAfter archives links read longer ago than this.
+	ArchiveReadAfter time.Duration
+	// DeleteTrashedAfter deletes links for good once they've been in the
+	// trash longer than this.
+	DeleteTrashedAfter time.Duration
+}
+
+// IsZero reports whether no policy is set.
+func (p CleanupPolicy) IsZero() bool {
+	return p.ArchiveReadAfter <= 0 && p.DeleteTrashedAfter <= 0
+}
+
+// CleanupResult counts the links a cleanup changed.
+type CleanupResult struct {
+	Archived int
+	Deleted  int
+}
+
+// String summarizes the result, e.g. "archived 3 read links, deleted 1
+// trashed link".
+func (r CleanupResult) String() string {
+	var parts []string
+	if r.Archived > 0 {
+		parts = append(parts, fmt.Sprintf("archived %s", plural(r.Archived, "read link")))
+	}
+	if r.Deleted > 0 {
+		parts = append(parts, fmt.Sprintf("deleted %s", plural(r.Deleted, "trashed link")))
+	}
+	if len(parts) == 0 {
+		return "nothing to clean up"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// cleanupCandidates returns the links p would archive and delete as of
+// now.
+func (c *Commands) cleanupCandidates(p CleanupPolicy, now time.Time) (archive, remove []*model.Link, err error) {
+	ctx := c.ctx
+	if p.ArchiveReadAfter > 0 {
+		archive, err = c.storage.List(ctx, storage.ListOptions{
+			ReadStatus: storage.ReadStatusRead,
+			Sort:       storage.SortOldest,
+			DateRange:  storage.DateRange{ReadBefore: now.Add(-p.ArchiveReadAfter)},
+		})
+		if err != nil {
+			return nil, nil, fmt.Errorf("list links: %w", err)
+		}
+	}
+	if p.DeleteTrashedAfter > 0 {
+		if remove, err = c.trashedBefore(now.Add(-p.DeleteTrashedAfter)); err != nil {
+			return nil, nil, err
+		}
+	}
+	return archive, remove, nil
+}
+
+// Cleanup applies the retention policies, listing what they archive and
+// delete. With dryRun it only lists them.
+func (c *Commands) Cleanup(p CleanupPolicy, dryRun bool) error {
+	if p.IsZero() {
+		return fmt.Errorf("no cleanup policies set; add archive_read_after_days or delete_trashed_after_days under [cleanup] in the config file")
+	}
+	archive, remove, err := c.cleanupCandidates(p, time.Now())
+	if err != nil {
+		return err
+	}
+
+	if c.jsonOutput {
+		out := struct {
+			Archived []*model.Link `json:"archived"`
+			Deleted  []*model.Link `json:"deleted"`
+			DryRun   bool          `json:"dry_run"`
+		}{[]*model.Link{}, []*model.Link{}, dryRun}
+		out.Archived = append(out.Archived, archive...)
+		out.Deleted = append(out.Deleted, remove...)
+		failed := 0
+		if !dryRun {
+			_, failed = c.applyCleanup(archive, remove)
+		}
+		if err := printJSON(out); err != nil {
+			return err
+		}
+		if failed > 0 {
+			return fmt.Errorf("%d link(s) could not be cleaned up", failed)
+		}
+		return nil
+	}
+
+	verb := func(done, would string) string {
+		if dryRun {
+			return would
+		}
+		return done
+	}
+	if len(archive) > 0 {
+		fmt.Printf("%s%s %s%s read more than %s ago:\n", colorBold, verb("Archiving", "Would archive"),
+			plural(len(archive), "link"), colorReset, plural(int(p.ArchiveReadAfter.Hours()/24), "day"))
+		if err := printLinksTable(archive, DisplayOptions{Plain: c.plain}); err != nil {
+			return err
+		}
+	}
+	if len(remove) > 0 {
+		fmt.Printf("%s%s %s%s in the trash more than %s:\n", colorBold, verb("Deleting", "Would delete"),
+			plural(len(remove), "link"), colorReset, plural(int(p.DeleteTrashedAfter.Hours()/24), "day"))
+		if err := printLinksTable(remove, DisplayOptions{Plain: c.plain}); err != nil {
+			return err
+		}
+	}
+	if dryRun {
+		return nil
+	}
+
+	result, failed := c.applyCleanup(archive, remove)
+	fmt.Printf("%sCleanup:%s %s.\n", colorGreen, colorReset, result)
+	if failed > 0 {
+		return fmt.Errorf("%d link(s) could not be cleaned up", failed)
+	}
+	return nil
+}
+
+// AutoCleanup applies the retention policies without listing anything,
+// for running at startup.
+func (c *Commands) AutoCleanup(p CleanupPolicy) (CleanupResult, error) {
+	if p.IsZero() {
+		return CleanupResult{}, nil
+	}
+	archive, remove, err := c.cleanupCandidates(p, time.Now())
+	if err != nil {
+		return CleanupResult{}, err
+	}
+	result, failed := c.applyCleanup(archive, remove)
+	if failed > 0 {
+		return result, fmt.Errorf("%d link(s) could not be cleaned up", failed)
+	}
+	return result, nil
+}
+
+// applyCleanup archives and deletes links, returning what succeeded and
+// how many failed.
+func (c *Commands) applyCleanup(archive, remove []*model.Link) (CleanupResult, int) {
+	var result CleanupResult
+	failed := 0
+	for _, link := range archive {
+		if err := c.storage.Archive(c.ctx, link.ID); err != nil {
+			failed++
+			continue
+		}
+		result.Archived++
+	}
+	deleted, purgeFailed := c.purge(remove)
+	result.Deleted = deleted
+	return result, failed + purgeFailed
+}
@@ -0,0 +1,170 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bunchhieng/rl/internal/model"
+)
+
+// cleanupFixture saves links either side of a 30-day archive and a 14-day
+// trash policy.
+type cleanupFixture struct {
+	oldRead, newRead, oldUnread, oldTrash, newTrash *model.Link
+}
+
+func newCleanupFixture(t *testing.T, c *Commands) cleanupFixture {
+	t.Helper()
+	at := func(n int) *time.Time {
+		when := daysAgo(n)
+		return &when
+	}
+	s := c.storage
+	return cleanupFixture{
+		oldRead:   addLink(t, s, &model.Link{URL: "https://example.com/old-read", CreatedAt: daysAgo(90), ReadAt: at(60)}),
+		newRead:   addLink(t, s, &model.Link{URL: "https://example.com/new-read", CreatedAt: daysAgo(90), ReadAt: at(5)}),
+		oldUnread: addLink(t, s, &model.Link{URL: "https://example.com/old-unread", CreatedAt: daysAgo(400)}),
+		oldTrash:  addLink(t, s, &model.Link{URL: "https://example.com/old-trash", CreatedAt: daysAgo(90), TrashedAt: at(20)}),
+		newTrash:  addLink(t, s, &model.Link{URL: "https://example.com/new-trash", CreatedAt: daysAgo(90), TrashedAt: at(2)}),
+	}
+}
+
+var testCleanupPolicy = CleanupPolicy{ArchiveReadAfter: 30 * 24 * time.Hour, DeleteTrashedAfter: 14 * 24 * time.Hour}
+
+func linkIDs(links []*model.Link) []string {
+	ids := make([]string, len(links))
+	for i, link := range links {
+		ids[i] = link.ID
+	}
+	return ids
+}
+
+func TestCleanupCandidates(t *testing.T) {
+	c, _ := testCommands(t)
+	f := newCleanupFixture(t, c)
+
+	archive, remove, err := c.cleanupCandidates(testCleanupPolicy, time.Now())
+	if err != nil {
+		t.Fatalf("cleanupCandidates failed: %v", err)
+	}
+	if got := linkIDs(archive); len(got) != 1 || got[0] != f.oldRead.ID {
+		t.Errorf("Expected only the link read 60 days ago archived, got %v", got)
+	}
+	if got := linkIDs(remove); len(got) != 1 || got[0] != f.oldTrash.ID {
+		t.Errorf("Expected only the link trashed 20 days ago deleted, got %v", got)
+	}
+
+	// A year on, every read and trashed link is due, but unread links never are
+	archive, remove, err = c.cleanupCandidates(testCleanupPolicy, time.Now().AddDate(1, 0, 0))
+	if err != nil {
+		t.Fatalf("cleanupCandidates failed: %v", err)
+	}
+	if len(archive) != 2 || len(remove) != 2 {
+		t.Errorf("Expected 2 links to archive and 2 to delete, got %v and %v", linkIDs(archive), linkIDs(remove))
+	}
+
+	// A zero duration turns a policy off
+	archive, remove, err = c.cleanupCandidates(CleanupPolicy{ArchiveReadAfter: testCleanupPolicy.ArchiveReadAfter}, time.Now())
+	if err != nil || len(archive) != 1 || len(remove) != 0 {
+		t.Errorf("Expected only links to archive, got %v and %v (%v)", linkIDs(archive), linkIDs(remove), err)
+	}
+}
+
+func TestCleanup(t *testing.T) {
+	ctx := context.Background()
+	c, s := testCommands(t)
+	f := newCleanupFixture(t, c)
+
+	if err := c.Cleanup(CleanupPolicy{}, false); err == nil {
+		t.Error("Expected an error with no policies set")
+	}
+
+	// A dry run lists the links and changes nothing
+	out, err := captureStdout(t, func() error { return c.Cleanup(testCleanupPolicy, true) })
+	if err != nil {
+		t.Fatalf("Cleanup failed: %v", err)
+	}
+	if !strings.Contains(out, "Would archive 1 link read more than 30 days ago") ||
+		!strings.Contains(out, "Would delete 1 link in the trash more than 14 days") {
+		t.Errorf("Expected the dry run to list what it would do, got %q", out)
+	}
+	if got, _ := s.Get(ctx, f.oldRead.ID); got.ArchivedAt != nil {
+		t.Error("Expected a dry run not to archive")
+	}
+	if _, err := s.Get(ctx, f.oldTrash.ID); err != nil {
+		t.Errorf("Expected a dry run not to delete, got %v", err)
+	}
+
+	c.SetJSON(true)
+	out, err = captureStdout(t, func() error { return c.Cleanup(testCleanupPolicy, true) })
+	c.SetJSON(false)
+	if err != nil {
+		t.Fatalf("Cleanup failed: %v", err)
+	}
+	var result struct {
+		Archived []*model.Link `json:"archived"`
+		Deleted  []*model.Link `json:"deleted"`
+		DryRun   bool          `json:"dry_run"`
+	}
+	if err := json.Unmarshal([]byte(out), &result); err != nil {
+		t.Fatalf("Expected JSON, got %q: %v", out, err)
+	}
+	if len(result.Archived) != 1 || len(result.Deleted) != 1 || !result.DryRun {
+		t.Errorf("Unexpected JSON result %+v", result)
+	}
+
+	out, err = captureStdout(t, func() error { return c.Cleanup(testCleanupPolicy, false) })
+	if err != nil {
+		t.Fatalf("Cleanup failed: %v", err)
+	}
+	if !strings.Contains(out, "archived 1 read link, deleted 1 trashed link") {
+		t.Errorf("Expected a summary, got %q", out)
+	}
+
+	// The read link is archived, keeping when it was read
+	archived, err := s.Get(ctx, f.oldRead.ID)
+	if err != nil || archived.ArchivedAt == nil || !archived.ReadAt.Equal(f.oldRead.ReadAt.Truncate(time.Second)) {
+		t.Errorf("Expected the old read link archived with its read time, got %+v (%v)", archived, err)
+	}
+	if _, err := s.Get(ctx, f.oldTrash.ID); !errors.Is(err, model.ErrNotFound) {
+		t.Errorf("Expected the old trashed link deleted, got %v", err)
+	}
+	for _, link := range []*model.Link{f.newRead, f.oldUnread, f.newTrash} {
+		got, err := s.Get(ctx, link.ID)
+		if err != nil || got.ArchivedAt != nil {
+			t.Errorf("Expected %s left alone, got %+v (%v)", link.URL, got, err)
+		}
+	}
+}
+
+func TestAutoCleanup(t *testing.T) {
+	c, _ := testCommands(t)
+	newCleanupFixture(t, c)
+
+	if result, err := c.AutoCleanup(CleanupPolicy{}); err != nil || result != (CleanupResult{}) {
+		t.Errorf("Expected nothing done with no policies, got %+v (%v)", result, err)
+	}
+
+	out, err := captureStdout(t, func() error {
+		result, err := c.AutoCleanup(testCleanupPolicy)
+		if result != (CleanupResult{Archived: 1, Deleted: 1}) {
+			t.Errorf("Expected 1 link archived and 1 deleted, got %+v", result)
+		}
+		return err
+	})
+	if err != nil {
+		t.Fatalf("AutoCleanup failed: %v", err)
+	}
+	if out != "" {
+		t.Errorf("Expected AutoCleanup to print nothing, got %q", out)
+	}
+
+	// Run again, there's nothing left to do
+	if result, err := c.AutoCleanup(testCleanupPolicy); err != nil || result != (CleanupResult{}) {
+		t.Errorf("Expected nothing left to clean up, got %+v (%v)", result, err)
+	}
+}
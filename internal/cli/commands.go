@@ -1,21 +1,33 @@
 package cli
 
 import (
+	"bytes"
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"math"
+	"math/rand"
 	"os"
-	"os/exec"
-	"runtime"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/bunchhieng/rl/internal/adder"
+	"github.com/bunchhieng/rl/internal/attach"
+	"github.com/bunchhieng/rl/internal/backup"
+	"github.com/bunchhieng/rl/internal/browser"
+	"github.com/bunchhieng/rl/internal/crypt"
+	"github.com/bunchhieng/rl/internal/fetch"
+	"github.com/bunchhieng/rl/internal/formats"
 	"github.com/bunchhieng/rl/internal/model"
+	"github.com/bunchhieng/rl/internal/reltime"
 	"github.com/bunchhieng/rl/internal/storage"
+	"github.com/bunchhieng/rl/internal/tui"
 )
 
-const (
+// ANSI escape sequences; SetColor(false) blanks them for plain output.
+var (
 	colorReset  = "\033[0m"
 	colorRed    = "\033[31m"
 	colorGreen  = "\033[32m"
@@ -25,20 +37,54 @@ const (
 	colorDim    = "\033[2m"
 )
 
+// SetColor enables or disables ANSI colors in all command output.
+func SetColor(enabled bool) {
+	if enabled {
+		colorReset, colorRed, colorGreen, colorYellow = "\033[0m", "\033[31m", "\033[32m", "\033[33m"
+		colorCyan, colorBold, colorDim = "\033[36m", "\033[1m", "\033[2m"
+		return
+	}
+	colorReset, colorRed, colorGreen, colorYellow = "", "", "", ""
+	colorCyan, colorBold, colorDim = "", "", ""
+}
+
+// hyperlinks makes link tables wrap URLs and titles in OSC 8 escape
+// sequences, which terminals that support them show as clickable links.
+var hyperlinks bool
+
+// SetHyperlinks enables or disables clickable links in link tables.
+func SetHyperlinks(enabled bool) {
+	hyperlinks = enabled
+}
+
 // Commands handles all CLI command execution.
 type Commands struct {
-	storage storage.Storage
+	ctx         context.Context
+	storage     storage.Storage
+	fetcher     *fetch.Client
+	listingFile string
+	jsonOutput  bool
+	plain       bool
+	browser     *browser.Browser
+	attachments *attach.Store
+	backups     *backup.Store
 }
 
 // NewCommands creates a new Commands instance.
 func NewCommands(s storage.Storage) *Commands {
-	return &Commands{storage: s}
+	return &Commands{ctx: context.Background(), storage: s, fetcher: fetch.NewClient(), browser: browser.New("")}
+}
+
+// SetContext sets the context commands run under; cancelling it (on
+// Ctrl-C, say) stops database work and fetches in progress.
+func (c *Commands) SetContext(ctx context.Context) {
+	c.ctx = ctx
 }
 
 // suggestID suggests a similar ID if the given ID is not found.
 func (c *Commands) suggestID(id string) string {
 	// Get all links to find similar IDs
-	links, err := c.storage.List(context.Background(), storage.ListOptions{
+	links, err := c.storage.List(c.ctx, storage.ListOptions{
 		ReadStatus: storage.ReadStatusAll,
 	})
 	if err != nil {
@@ -107,122 +153,533 @@ func min(a, b, c int) int {
 	return c
 }
 
+// AddOptions holds the optional fields for Add.
+type AddOptions = adder.Options
+
 // Add adds a new link.
-func (c *Commands) Add(url string, title, note, tags string) error {
-	link := &model.Link{
-		URL:   url,
-		Title: title,
-		Note:  note,
-		Tags:  tags,
+func (c *Commands) Add(url string, opts AddOptions) error {
+	result, err := c.addLink(url, opts)
+	if err != nil {
+		return err
+	}
+	if c.jsonOutput {
+		return printJSON(result.Link)
+	}
+	printAdded(result)
+	return nil
+}
+
+// adder saves links the way every front end does.
+func (c *Commands) adder() *adder.Adder {
+	return adder.New(c.storage, c.fetcher, c.attachments)
+}
+
+// addLink saves a URL without printing it, warning about a page that
+// couldn't be fetched or copied.
+func (c *Commands) addLink(url string, opts AddOptions) (*adder.Result, error) {
+	result, err := c.adder().Add(c.ctx, url, opts)
+	if err != nil {
+		return nil, err
 	}
+	if result.FetchErr != nil {
+		fmt.Fprintf(os.Stderr, "%sWarning:%s could not fetch metadata: %v\n", colorYellow, colorReset, result.FetchErr)
+	}
+	if result.AttachErr != nil {
+		fmt.Fprintf(os.Stderr, "%sWarning:%s could not save a copy: %v\n", colorYellow, colorReset, result.AttachErr)
+	}
+	return result, nil
+}
 
-	if err := link.Validate(); err != nil {
-		return fmt.Errorf("invalid URL: %w", err)
+func printAdded(result *adder.Result) {
+	link := result.Link
+	if result.Updated {
+		fmt.Printf("%sUpdated%s link %s%s%s: %s%s%s\n", colorYellow, colorReset, colorBold, link.ID, colorReset, colorCyan, link.URL, colorReset)
+	} else {
+		fmt.Printf("%sAdded%s link %s%s%s: %s%s%s\n", colorGreen, colorReset, colorBold, link.ID, colorReset, colorCyan, link.URL, colorReset)
+	}
+	if result.AliasURL != "" {
+		fmt.Printf("  %s(redirected from %s)%s\n", colorDim, result.AliasURL, colorReset)
 	}
+	if result.Attachment != nil {
+		fmt.Printf("  %s(saved a copy, %s)%s\n", colorDim, formatSize(result.Attachment.Size), colorReset)
+	}
+}
 
-	// Check if link already exists by trying to find it
-	allLinks, _ := c.storage.List(context.Background(), storage.ListOptions{
-		ReadStatus: storage.ReadStatusAll,
-	})
+// fetchMetadata downloads a link's page and fills in its title (if empty),
+// description, word count, and estimated reading time.
+func (c *Commands) fetchMetadata(link *model.Link) (*fetch.Metadata, error) {
+	return c.adder().FetchMetadata(c.ctx, link)
+}
 
-	wasUpdate := false
-	for _, existing := range allLinks {
-		if existing.URL == url {
-			wasUpdate = true
-			break
+// Fetch refreshes metadata (title, word count, reading time) and archived
+// article text for links. With force, pages are downloaded even if the
+// server says they haven't changed.
+func (c *Commands) Fetch(force bool, ids ...string) error {
+	var links []*model.Link
+	var failed []string
+	for _, id := range ids {
+		resolved, err := c.resolveID(id)
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("%s (%v)", id, err))
+			continue
 		}
+		link, err := c.storage.Get(c.ctx, resolved)
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("%s (%v)", id, c.handleNotFound(err, resolved, "get link")))
+			continue
+		}
+		links = append(links, link)
+	}
+	for _, f := range c.fetchLinks(links, force) {
+		failed = append(failed, fmt.Sprintf("%s (%v)", f.link.ID, f.err))
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to fetch: %s", strings.Join(failed, ", "))
 	}
+	return nil
+}
 
-	created, err := c.storage.Add(context.Background(), link)
+// FetchAll refreshes every link, or with missing only those without a
+// title or reading time, reporting failures at the end. force is as for
+// Fetch.
+func (c *Commands) FetchAll(missing, force bool) error {
+	links, err := c.fetchCandidates(missing)
 	if err != nil {
-		return fmt.Errorf("add link: %w", err)
+		return err
+	}
+	if len(links) == 0 {
+		fmt.Println("No links to fetch.")
+		return nil
 	}
 
-	if wasUpdate {
-		fmt.Printf("%sUpdated%s link %s%s%s: %s%s%s\n", colorYellow, colorReset, colorBold, created.ID, colorReset, colorCyan, created.URL, colorReset)
-	} else {
-		fmt.Printf("%sAdded%s link %s%s%s: %s%s%s\n", colorGreen, colorReset, colorBold, created.ID, colorReset, colorCyan, created.URL, colorReset)
+	failed := c.fetchLinks(links, force)
+	for _, f := range failed {
+		fmt.Fprintf(os.Stderr, "%sFailed%s %s: %v\n", colorRed, colorReset, f.link.ID, f.err)
+	}
+	fmt.Printf("%sFetched%s %d of %d link(s).\n", colorBold, colorReset, len(links)-len(failed), len(links))
+	if len(failed) > 0 {
+		return fmt.Errorf("%d link(s) could not be fetched", len(failed))
 	}
 	return nil
 }
 
-// List lists links with optional filters.
-func (c *Commands) List(readStatus storage.ReadStatus, tag string, limit int) error {
-	opts := storage.ListOptions{
-		ReadStatus: readStatus,
-		Tag:        tag,
-		Limit:      limit,
+// fetchCandidates returns every link, or with missing only those without
+// a title or reading time.
+func (c *Commands) fetchCandidates(missing bool) ([]*model.Link, error) {
+	links, err := c.storage.List(c.ctx, storage.ListOptions{ReadStatus: storage.ReadStatusAll})
+	if err != nil {
+		return nil, fmt.Errorf("list links: %w", err)
+	}
+	if !missing {
+		return links, nil
+	}
+	var incomplete []*model.Link
+	for _, link := range links {
+		if link.Title == "" || link.ReadingSeconds == 0 {
+			incomplete = append(incomplete, link)
+		}
+	}
+	return incomplete, nil
+}
+
+// fetchFailure is a link fetchLinks couldn't refresh.
+type fetchFailure struct {
+	link *model.Link
+	err  error
+}
+
+// fetchLinks fetches links concurrently and saves what it finds, printing
+// a line per link and, for several links on a terminal, a progress bar.
+// Pages fetched before are only downloaded again if the server says they
+// changed, unless force is set.
+func (c *Commands) fetchLinks(links []*model.Link, force bool) []fetchFailure {
+	var failed []fetchFailure
+	progress := newProgressBar(len(links))
+	c.fetchEach(links, force, func(link *model.Link, notModified bool, err error) {
+		progress.clear()
+		switch {
+		case err != nil:
+			failed = append(failed, fetchFailure{link, err})
+		case notModified:
+			fmt.Printf("%sUnchanged%s %s%s%s: %s\n", colorDim, colorReset, colorBold, link.ID, colorReset, displayTitle(link))
+		default:
+			fmt.Printf("%sFetched%s %s%s%s: %s (%s)\n", colorGreen, colorReset, colorBold, link.ID, colorReset,
+				displayTitle(link), linkTime(link))
+		}
+		progress.step()
+	})
+	progress.clear()
+	return failed
+}
+
+// fetchEach fetches links concurrently and saves what it finds, calling
+// done as each finishes, with whether the page was unchanged since it was
+// last fetched. force is as for fetchLinks.
+func (c *Commands) fetchEach(links []*model.Link, force bool, done func(link *model.Link, notModified bool, err error)) {
+	requests := make([]fetch.Request, len(links))
+	for i, link := range links {
+		requests[i].URL = link.URL
+		if force {
+			continue
+		}
+		if state, err := c.storage.FetchState(c.ctx, link.ID); err == nil {
+			requests[i].Validators = fetch.Validators{ETag: state.ETag, LastModified: state.LastModified}
+		}
 	}
 
-	links, err := c.storage.List(context.Background(), opts)
+	c.fetcher.FetchAll(c.ctx, requests, fetch.PoolOptions{}, func(r fetch.Result) {
+		link := links[r.Index]
+		err := r.Err
+		switch {
+		case err != nil:
+		case r.Meta.NotModified:
+			err = c.recordFetch(link.ID, r.Meta.Validators)
+		default:
+			r.Meta.Apply(link)
+			err = c.saveFetched(link, r.Meta)
+		}
+		done(link, err == nil && r.Meta.NotModified, err)
+	})
+}
+
+// saveFetched stores metadata fetched for link, moving it to the page's
+// final URL unless another link already has it, and archives the text.
+func (c *Commands) saveFetched(link *model.Link, meta *fetch.Metadata) error {
+	ctx := c.ctx
+	originalURL := link.URL
+	if resolved := adder.ResolveRedirect(link.URL, meta.FinalURL); resolved != "" {
+		if _, err := c.storage.FindByURL(ctx, resolved); err == model.ErrNotFound {
+			link.URL = resolved
+		}
+	}
+
+	if err := c.storage.Update(ctx, link); err != nil {
+		return err
+	}
+	if link.URL != originalURL {
+		if err := c.storage.AddURLAlias(ctx, link.ID, originalURL); err != nil {
+			return err
+		}
+	}
+	if meta.Text != "" {
+		if err := c.storage.SetContent(ctx, link.ID, meta.Text); err != nil {
+			return err
+		}
+	}
+	return c.recordFetch(link.ID, meta.Validators)
+}
+
+// recordFetch notes that a link's page was fetched just now, keeping its
+// validators for the next conditional fetch.
+func (c *Commands) recordFetch(id string, v fetch.Validators) error {
+	return c.adder().RecordFetch(c.ctx, id, v)
+}
+
+// List lists links with optional filters.
+func (c *Commands) List(opts storage.ListOptions, display DisplayOptions) error {
+	links, err := c.storage.List(c.ctx, opts)
 	if err != nil {
 		return fmt.Errorf("list links: %w", err)
 	}
 
-	if len(links) == 0 {
+	if len(links) == 0 && !c.jsonOutput && !display.TSV {
 		fmt.Println("No links found.")
 		return nil
 	}
 
-	return printLinksTable(links)
+	if display.GroupBy != GroupNone && !display.TSV {
+		return c.printGroups(groupLinks(links, display.GroupBy), display)
+	}
+	return c.printListing(links, display)
+}
+
+// Open opens one or more links in the default browser, optionally marking
+// them as read. With local, the saved copies of their documents are opened
+// instead.
+func (c *Commands) Open(markDone, local bool, ids ...string) error {
+	return c.forEachID(ids, "open", func(id string) error {
+		return c.open(id, markDone, local)
+	})
 }
 
-// Open opens a link in the default browser.
-func (c *Commands) Open(id string) error {
-	if !model.ValidateShortID(id) {
-		return fmt.Errorf("invalid ID format")
+func (c *Commands) open(id string, markDone, local bool) error {
+	id, err := c.resolveID(id)
+	if err != nil {
+		return err
 	}
-	link, err := c.storage.Get(context.Background(), id)
+	link, err := c.storage.Get(c.ctx, id)
 	if err != nil {
 		return c.handleNotFound(err, id, "get link")
 	}
 
-	var cmd *exec.Cmd
-	switch runtime.GOOS {
-	case "darwin":
-		cmd = exec.Command("open", link.URL)
-	case "linux":
-		cmd = exec.Command("xdg-open", link.URL)
-	case "windows":
-		cmd = exec.Command("cmd", "/c", "start", link.URL)
-	default:
-		return fmt.Errorf("unsupported operating system: %s", runtime.GOOS)
+	if local {
+		if err := c.openLocal(link); err != nil {
+			return err
+		}
+	} else {
+		if err := c.browser.Open(link.URL); err != nil {
+			return err
+		}
+		fmt.Printf("%sOpened:%s %s%s%s\n", colorGreen, colorReset, colorCyan, link.URL, colorReset)
+	}
+	if markDone && !link.IsRead() {
+		return c.done(link.ID)
+	}
+	return nil
+}
+
+// forEachID runs fn for every ID. A single failing ID returns its error
+// unchanged; with several IDs, all are attempted and failures are combined.
+func (c *Commands) forEachID(ids []string, action string, fn func(id string) error) error {
+	if len(ids) == 0 {
+		return fmt.Errorf("at least one ID required")
+	}
+	if len(ids) == 1 {
+		return fn(ids[0])
 	}
 
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("open browser: %w", err)
+	var failed []string
+	for _, id := range ids {
+		if err := fn(id); err != nil {
+			failed = append(failed, fmt.Sprintf("%s (%v)", id, err))
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to %s: %s", action, strings.Join(failed, ", "))
 	}
+	return nil
+}
 
+// Show prints every field of a single link.
+func (c *Commands) Show(id string) error {
+	id, err := c.resolveID(id)
+	if err != nil {
+		return err
+	}
+	link, err := c.storage.Get(c.ctx, id)
+	if err != nil {
+		return c.handleNotFound(err, id, "get link")
+	}
+	annotations, err := c.storage.Annotations(c.ctx, id)
+	if err != nil {
+		return err
+	}
+	quotes, err := c.storage.Quotes(c.ctx, id)
+	if err != nil {
+		return err
+	}
+	attachment, err := c.storage.Attachment(c.ctx, id)
+	if err != nil && err != model.ErrNotFound {
+		return err
+	}
+	summary, err := c.storage.Summary(c.ctx, id)
+	if err != nil && err != model.ErrNotFound {
+		return err
+	}
+	if c.jsonOutput {
+		if annotations == nil {
+			annotations = []*model.Annotation{}
+		}
+		if quotes == nil {
+			quotes = []*model.Quote{}
+		}
+		return printJSON(struct {
+			*model.Link
+			Annotations []*model.Annotation `json:"annotations"`
+			Quotes      []*model.Quote      `json:"quotes"`
+			Attachment  *model.Attachment   `json:"attachment,omitempty"`
+			Summary     *model.Summary      `json:"summary,omitempty"`
+		}{link, annotations, quotes, attachment, summary})
+	}
+
+	status := "unread"
+	if link.IsRead() {
+		status = "read " + formatTime(*link.ReadAt)
+	}
+	if link.ArchivedAt != nil {
+		status += ", archived " + formatTime(*link.ArchivedAt)
+	}
+	if link.TrashedAt != nil {
+		status += ", in the trash since " + formatTime(*link.TrashedAt)
+	}
+	summaryText := ""
+	if summary != nil {
+		summaryText = summary.Text
+	}
+	fields := []struct{ name, value string }{
+		{"ID", link.ID},
+		{"URL", link.URL},
+		{"Title", link.Title},
+		{"About", link.Description},
+		{"Summary", summaryText},
+		{"Author", link.Author},
+		{"Type", link.MediaType},
+		{"Tags", link.Tags},
+		{"Priority", link.Priority.String()},
+		{"Status", status},
+		{"Created", formatTime(link.CreatedAt)},
+		{"Time", linkTime(link)},
+	}
+	if state, err := c.storage.FetchState(c.ctx, id); err == nil {
+		fields = append(fields, struct{ name, value string }{"Fetched", formatTime(state.FetchedAt)})
+	}
+	if link.IsSnoozed(time.Now()) {
+		fields = append(fields, struct{ name, value string }{"Snoozed", formatTime(*link.SnoozedUntil)})
+	}
+	if attachment != nil && c.attachments != nil {
+		saved := fmt.Sprintf("%s (%s)", c.attachments.Path(attachment.Path), formatSize(attachment.Size))
+		fields = append(fields, struct{ name, value string }{"Saved", saved})
+	}
+	for _, f := range fields {
+		if f.value == "" {
+			continue
+		}
+		fmt.Printf("%s%-9s%s %s\n", colorBold, f.name+":", colorReset, f.value)
+	}
+	if len(annotations) > 0 {
+		fmt.Printf("\n%sNotes:%s\n", colorBold, colorReset)
+		printExcerpts(annotations, false)
+	}
+	if len(quotes) > 0 {
+		fmt.Printf("\n%sQuotes:%s\n", colorBold, colorReset)
+		for _, q := range quotes {
+			for _, line := range strings.Split(q.Text, "\n") {
+				fmt.Printf("%s>%s %s\n", colorDim, colorReset, line)
+			}
+		}
+	}
+	return nil
+}
+
+// Random prints a random link matching opts, optionally opening it.
+func (c *Commands) Random(opts storage.ListOptions, open bool) error {
+	links, err := c.storage.List(c.ctx, opts)
+	if err != nil {
+		return fmt.Errorf("list links: %w", err)
+	}
+	if len(links) == 0 {
+		fmt.Println("No links found.")
+		return nil
+	}
+
+	link := links[rand.Intn(len(links))]
+	if c.jsonOutput {
+		err = printJSON(link)
+	} else {
+		err = printLinksTable([]*model.Link{link}, DisplayOptions{Plain: c.plain})
+	}
+	if err != nil {
+		return err
+	}
+	if !open {
+		return nil
+	}
+	if err := c.browser.Open(link.URL); err != nil {
+		return err
+	}
 	fmt.Printf("%sOpened:%s %s%s%s\n", colorGreen, colorReset, colorCyan, link.URL, colorReset)
 	return nil
 }
 
-// Done marks a link as read.
-func (c *Commands) Done(id string) error {
-	if !model.ValidateShortID(id) {
-		return fmt.Errorf("invalid ID format")
+// Pick lets the user fuzzy-find a link and prints its ID, or opens or
+// marks it read instead when open or markDone is set.
+func (c *Commands) Pick(opts storage.ListOptions, open, markDone bool) error {
+	links, err := c.storage.List(c.ctx, opts)
+	if err != nil {
+		return fmt.Errorf("list links: %w", err)
+	}
+	if len(links) == 0 {
+		return fmt.Errorf("no links to pick from")
+	}
+
+	link, err := tui.Pick(links)
+	if err != nil {
+		return fmt.Errorf("run picker: %w", err)
+	}
+	if link == nil {
+		return fmt.Errorf("nothing selected")
+	}
+
+	switch {
+	case open:
+		return c.open(link.ID, markDone, false)
+	case markDone:
+		return c.done(link.ID)
+	default:
+		fmt.Println(link.ID)
+		return nil
+	}
+}
+
+// Done marks one or more links as read.
+func (c *Commands) Done(ids ...string) error {
+	return c.forEachID(ids, "mark read", c.done)
+}
+
+func (c *Commands) done(id string) error {
+	id, err := c.resolveID(id)
+	if err != nil {
+		return err
 	}
-	if err := c.storage.MarkRead(context.Background(), id); err != nil {
+	if err := c.storage.MarkRead(c.ctx, id); err != nil {
 		return c.handleNotFound(err, id, "mark read")
 	}
 	fmt.Printf("%sMarked%s link %s%s%s as read.\n", colorGreen, colorReset, colorBold, id, colorReset)
 	return nil
 }
 
-// Undo marks a link as unread.
-func (c *Commands) Undo(id string) error {
-	if !model.ValidateShortID(id) {
-		return fmt.Errorf("invalid ID format")
+// Undo marks one or more links as unread.
+func (c *Commands) Undo(ids ...string) error {
+	return c.forEachID(ids, "mark unread", c.undo)
+}
+
+func (c *Commands) undo(id string) error {
+	id, err := c.resolveID(id)
+	if err != nil {
+		return err
 	}
-	if err := c.storage.MarkUnread(context.Background(), id); err != nil {
+	if err := c.storage.MarkUnread(c.ctx, id); err != nil {
 		return c.handleNotFound(err, id, "mark unread")
 	}
 	fmt.Printf("%sMarked%s link %s%s%s as unread.\n", colorYellow, colorReset, colorBold, id, colorReset)
 	return nil
 }
 
-// Remove deletes one or more links.
-func (c *Commands) Remove(ids ...string) error {
+// Prioritize sets the priority level of a link.
+func (c *Commands) Prioritize(id string, priority model.Priority) error {
+	id, err := c.resolveID(id)
+	if err != nil {
+		return err
+	}
+	if err := c.storage.SetPriority(c.ctx, id, priority); err != nil {
+		return c.handleNotFound(err, id, "set priority")
+	}
+	fmt.Printf("%sSet%s link %s%s%s priority to %s.\n", colorGreen, colorReset, colorBold, id, colorReset, priority)
+	return nil
+}
+
+// Snooze hides a link from the unread list until the given time.
+// A zero time clears the snooze.
+func (c *Commands) Snooze(id string, until time.Time) error {
+	id, err := c.resolveID(id)
+	if err != nil {
+		return err
+	}
+	if err := c.storage.Snooze(c.ctx, id, until); err != nil {
+		return c.handleNotFound(err, id, "snooze")
+	}
+	if until.IsZero() {
+		fmt.Printf("%sUnsnoozed%s link %s%s%s.\n", colorYellow, colorReset, colorBold, id, colorReset)
+		return nil
+	}
+	fmt.Printf("%sSnoozed%s link %s%s%s %s.\n", colorGreen, colorReset, colorBold, id, colorReset, reltime.Until(until.In(displayLocation), time.Now(), relativeTimes, dateLayout))
+	return nil
+}
+
+// Remove moves one or more links to the trash, or with permanent deletes
+// them for good.
+func (c *Commands) Remove(permanent bool, ids ...string) error {
 	if len(ids) == 0 {
 		return fmt.Errorf("at least one ID required")
 	}
@@ -231,11 +688,25 @@ func (c *Commands) Remove(ids ...string) error {
 	var failed []string
 
 	for _, id := range ids {
-		if !model.ValidateShortID(id) {
-			failed = append(failed, fmt.Sprintf("%s (invalid format)", id))
+		if isListingRef(id) {
+			resolved, err := c.lookupListing(id)
+			if err != nil {
+				failed = append(failed, fmt.Sprintf("%s (%v)", id, err))
+				continue
+			}
+			id = resolved
+		}
+		if resolved, err := c.storage.ResolveID(c.ctx, id); err == nil {
+			id = resolved
+		} else if err != model.ErrNotFound {
+			failed = append(failed, fmt.Sprintf("%s (%v)", id, err))
 			continue
 		}
-		if err := c.storage.Delete(context.Background(), id); err != nil {
+		remove := c.storage.Trash
+		if permanent {
+			remove = c.storage.Delete
+		}
+		if err := remove(c.ctx, id); err != nil {
 			if err == model.ErrNotFound {
 				suggestion := c.suggestID(id)
 				msg := fmt.Sprintf("%s (not found)", id)
@@ -251,22 +722,56 @@ func (c *Commands) Remove(ids ...string) error {
 		deleted = append(deleted, id)
 	}
 
+	verb := "Trashed"
+	if permanent {
+		verb = "Deleted"
+	}
 	if len(deleted) > 0 {
 		if len(deleted) == 1 {
-			fmt.Printf("%sDeleted%s link %s%s%s.\n", colorRed, colorReset, colorBold, deleted[0], colorReset)
+			fmt.Printf("%s%s%s link %s%s%s.\n", colorRed, verb, colorReset, colorBold, deleted[0], colorReset)
 		} else {
 			ids := strings.Join(deleted, ", ")
-			fmt.Printf("%sDeleted%s %d link(s): %s%s%s\n", colorRed, colorReset, len(deleted), colorBold, ids, colorReset)
+			fmt.Printf("%s%s%s %d link(s): %s%s%s\n", colorRed, verb, colorReset, len(deleted), colorBold, ids, colorReset)
+		}
+	}
+
+	if permanent && len(deleted) > 0 {
+		if err := c.pruneAttachments(); err != nil {
+			fmt.Fprintf(os.Stderr, "%sWarning:%s could not remove unused attachments: %v\n", colorYellow, colorReset, err)
 		}
 	}
 
 	if len(failed) > 0 {
-		return fmt.Errorf("failed to delete: %s", strings.Join(failed, ", "))
+		if permanent {
+			return fmt.Errorf("failed to delete: %s", strings.Join(failed, ", "))
+		}
+		return fmt.Errorf("failed to move to trash: %s", strings.Join(failed, ", "))
 	}
 
 	return nil
 }
 
+// resolveID expands an alias, a unique ID prefix, or an index into the last
+// listing ("%2") to the full link ID.
+func (c *Commands) resolveID(prefix string) (string, error) {
+	if isListingRef(prefix) {
+		return c.lookupListing(prefix)
+	}
+	id, err := c.storage.ResolveID(c.ctx, prefix)
+	if err != nil {
+		var ambiguous *model.AmbiguousIDError
+		if errors.As(err, &ambiguous) {
+			msg := fmt.Sprintf("ID prefix %s%s%s is ambiguous; candidates:", colorBold, prefix, colorReset)
+			for _, candidate := range ambiguous.Candidates {
+				msg += fmt.Sprintf("\n  %s%s%s", colorCyan, candidate, colorReset)
+			}
+			return "", fmt.Errorf("%s", msg)
+		}
+		return "", c.handleNotFound(err, prefix, "resolve ID")
+	}
+	return id, nil
+}
+
 func (c *Commands) handleNotFound(err error, id string, action string) error {
 	if err == model.ErrNotFound {
 		// Try to suggest similar IDs
@@ -280,188 +785,310 @@ func (c *Commands) handleNotFound(err error, id string, action string) error {
 	return fmt.Errorf("%s: %w", action, err)
 }
 
-// Export exports all links to JSON.
-func (c *Commands) Export(w io.Writer) error {
-	links, err := c.storage.Export(context.Background())
+// Export writes all links to w in the named format ("" for rl's own),
+// encrypted as enc says.
+func (c *Commands) Export(w io.Writer, format string, enc Encryption) error {
+	f, err := formats.Lookup(format)
+	if err != nil {
+		return err
+	}
+	if f.Encode == nil {
+		return fmt.Errorf("format %s can only be imported (export supports %s)", f.Name, strings.Join(formats.Exportable(), ", "))
+	}
+	links, err := c.storage.Export(c.ctx)
 	if err != nil {
 		return fmt.Errorf("export links: %w", err)
 	}
-
-	encoder := json.NewEncoder(w)
-	encoder.SetIndent("", "  ")
-	if err := encoder.Encode(links); err != nil {
-		return fmt.Errorf("encode JSON: %w", err)
+	if !enc.Encrypt && enc.KeyFile == "" {
+		return f.Encode(w, links)
 	}
 
-	return nil
+	secret, err := enc.secret(true)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err := f.Encode(&buf, links); err != nil {
+		return err
+	}
+	return crypt.Encrypt(w, buf.Bytes(), secret)
 }
 
-// Import imports links from a JSON file.
-func (c *Commands) Import(filename string) error {
+// Import imports links from a file in the named format ("" for rl's own),
+// printing how many were added, merged, skipped, or failed. With dryRun
+// it reports what would happen without changing the database. Encrypted
+// exports are decrypted with enc's key file or a passphrase.
+func (c *Commands) Import(filename, format string, dryRun bool, enc Encryption) error {
+	f, err := formats.Lookup(format)
+	if err != nil {
+		return err
+	}
 	file, err := os.Open(filename)
 	if err != nil {
 		return fmt.Errorf("open file: %w", err)
 	}
 	defer file.Close()
 
-	var links []*model.Link
-	decoder := json.NewDecoder(file)
-	if err := decoder.Decode(&links); err != nil {
-		return fmt.Errorf("decode JSON: %w", err)
+	r, err := enc.decrypted(file)
+	if err != nil {
+		return err
+	}
+	links, err := f.Decode(r)
+	if err != nil {
+		return err
+	}
+	if !dryRun && len(links) > 0 && c.backups != nil {
+		if _, err := c.backups.Create(c.ctx, c.storage, backup.ReasonImport); err != nil {
+			return fmt.Errorf("back up before importing: %w", err)
+		}
 	}
 
-	if err := c.storage.Import(context.Background(), links); err != nil {
+	results, err := c.storage.Import(c.ctx, links, storage.ImportOptions{DryRun: dryRun})
+	if err != nil {
 		return fmt.Errorf("import links: %w", err)
 	}
 
-	fmt.Printf("%sImported%s %s%d%s link(s).\n", colorGreen, colorReset, colorBold, len(links), colorReset)
+	counts := make(map[storage.ImportStatus]int)
+	for _, r := range results {
+		counts[r.Status]++
+	}
+	if c.jsonOutput {
+		if err := printJSON(results); err != nil {
+			return err
+		}
+	} else {
+		printImportSummary(results, counts, dryRun)
+	}
+	if n := counts[storage.ImportFailed]; n > 0 {
+		return fmt.Errorf("%d link(s) could not be imported", n)
+	}
+	return nil
+}
+
+// printImportSummary prints the failed links with their reasons, then a
+// count of links per import status.
+func printImportSummary(results []storage.ImportResult, counts map[storage.ImportStatus]int, dryRun bool) {
+	for _, r := range results {
+		if r.Status == storage.ImportFailed {
+			fmt.Fprintf(os.Stderr, "%sFailed%s %s: %s\n", colorRed, colorReset, r.URL, r.Reason)
+		}
+	}
+
+	if dryRun {
+		fmt.Printf("%sDry run:%s nothing was changed.\n", colorYellow, colorReset)
+	}
+	rows := []struct {
+		status storage.ImportStatus
+		color  string
+	}{
+		{storage.ImportAdded, colorGreen},
+		{storage.ImportMerged, colorCyan},
+		{storage.ImportSkipped, colorDim},
+		{storage.ImportFailed, colorRed},
+	}
+	for _, row := range rows {
+		fmt.Printf("  %s%-8s%s %5d\n", row.color, row.status, colorReset, counts[row.status])
+	}
+	fmt.Printf("  %s%-8s%s %5d\n", colorBold, "total", colorReset, len(results))
+}
+
+// Stats prints aggregate counts and the most common domains.
+func (c *Commands) Stats() error {
+	stats, err := c.storage.Stats(c.ctx)
+	if err != nil {
+		return fmt.Errorf("get stats: %w", err)
+	}
+	if c.jsonOutput {
+		if stats.Domains == nil {
+			stats.Domains = []storage.Count{}
+		}
+		if stats.Tags == nil {
+			stats.Tags = []storage.Count{}
+		}
+		return printJSON(stats)
+	}
+
+	fmt.Printf("%sTotal:%s   %d\n", colorBold, colorReset, stats.Total)
+	fmt.Printf("%sUnread:%s  %d\n", colorBold, colorReset, stats.Unread)
+	fmt.Printf("%sRead:%s    %d\n", colorBold, colorReset, stats.Read)
+	fmt.Printf("%sSnoozed:%s %d\n", colorBold, colorReset, stats.Snoozed)
+
+	if len(stats.Domains) > 0 {
+		fmt.Printf("\n%sTop domains:%s\n", colorBold, colorReset)
+		printCounts(stats.Domains, maxStatsRows)
+	}
+	if len(stats.Tags) > 0 {
+		fmt.Printf("\n%sTop tags:%s\n", colorBold, colorReset)
+		printCounts(stats.Tags, maxStatsRows)
+	}
 	return nil
 }
 
+const maxStatsRows = 10
+
+// printCounts prints up to limit name/count pairs, right-aligning the counts.
+func printCounts(counts []storage.Count, limit int) {
+	if len(counts) > limit {
+		counts = counts[:limit]
+	}
+	nameLen := 0
+	for _, c := range counts {
+		if len(c.Name) > nameLen {
+			nameLen = len(c.Name)
+		}
+	}
+	for _, c := range counts {
+		fmt.Printf("  %s%-*s%s %5d\n", colorCyan, nameLen, c.Name, colorReset, c.Count)
+	}
+}
+
 // Search performs a full-text search.
-func (c *Commands) Search(query string) error {
-	links, err := c.storage.Search(context.Background(), query)
+func (c *Commands) Search(query string, opts storage.SearchOptions, display DisplayOptions) error {
+	links, err := c.storage.Search(c.ctx, query, opts)
+	if errors.Is(err, model.ErrInvalidQuery) {
+		return err
+	}
 	if err != nil {
 		return fmt.Errorf("search links: %w", err)
 	}
 
 	if len(links) == 0 {
+		// Fall back to similar titles and URLs so typos still find the link
+		links, err = c.storage.SearchApproximate(c.ctx, query, opts, approximateLimit)
+		if err != nil {
+			return fmt.Errorf("search links: %w", err)
+		}
+		if len(links) > 0 {
+			fmt.Fprintf(os.Stderr, "%sNo exact matches;%s showing %d approximate result(s).\n", colorYellow, colorReset, len(links))
+		}
+	}
+
+	if len(links) == 0 && !c.jsonOutput && !display.TSV {
 		fmt.Println("No links found.")
 		return nil
 	}
 
-	return printLinksTable(links)
+	if err := c.printListing(links, display); err != nil {
+		return err
+	}
+	if !c.jsonOutput && !display.TSV {
+		printMatches(links)
+	}
+	return nil
 }
 
-const (
-	maxURLLen   = 60
-	maxTitleLen = 40
-	maxTagsLen  = 30
-	ellipsisLen = 3
-)
+// printMatches prints where each search result matched, numbered like the
+// listing, with the matched terms highlighted.
+func printMatches(links []*model.Link) {
+	first := true
+	for i, link := range links {
+		if link.Match == nil {
+			continue
+		}
+		if first {
+			fmt.Println()
+			first = false
+		}
+		m := link.Match
+		// tsvEscaper replaces byte for byte, so highlight offsets still hold
+		snippet := tsvEscaper.Replace(m.Snippet)
+		var b strings.Builder
+		last := 0
+		for _, h := range m.Highlights {
+			b.WriteString(snippet[last:h[0]])
+			b.WriteString(colorBold + colorYellow + snippet[h[0]:h[1]] + colorReset)
+			last = h[1]
+		}
+		b.WriteString(snippet[last:])
+		if m.Similarity != 0 {
+			fmt.Fprintf(&b, " %s(%.2f)%s", colorDim, m.Similarity, colorReset)
+		}
+		fmt.Printf("%s%3d  %-11s%s %s\n", colorDim, i+1, m.Field, colorReset, b.String())
+	}
+}
 
-var estLocation *time.Location
+// approximateLimit caps the approximate results shown when a search finds
+// nothing.
+const approximateLimit = 10
 
-func init() {
-	var err error
-	estLocation, err = time.LoadLocation("America/New_York")
+// Reindex rebuilds the search index.
+func (c *Commands) Reindex() error {
+	n, err := c.storage.Reindex(c.ctx)
 	if err != nil {
-		estLocation = time.UTC
+		return err
 	}
+	fmt.Printf("%sReindexed%s %s%d%s link(s).\n", colorGreen, colorReset, colorBold, n, colorReset)
+	return nil
 }
 
-func printLinksTable(links []*model.Link) error {
-	// Calculate column widths based on header and content
-	colIDLen := len("ID")
-	colURLLen := len("URL")
-	colTitleLen := len("TITLE")
-	colCreatedLen := len("CREATED")
-	colTagsLen := len("TAGS")
-
-	// Find maximum content widths (with limits)
-	for _, link := range links {
-		if idLen := len(link.ID); idLen > colIDLen {
-			colIDLen = idLen
-		}
-		urlLen := truncateLen(len(link.URL), maxURLLen)
-		if urlLen > colURLLen {
-			colURLLen = urlLen
-		}
-		titleLen := truncateLen(len(link.Title), maxTitleLen)
-		if titleLen > colTitleLen {
-			colTitleLen = titleLen
-		}
-		createdLen := len(formatTime(link.CreatedAt))
-		if createdLen > colCreatedLen {
-			colCreatedLen = createdLen
-		}
-		tagsLen := truncateLen(len(link.Tags), maxTagsLen)
-		if tagsLen > colTagsLen {
-			colTagsLen = tagsLen
-		}
-	}
-
-	// Add padding (2 spaces: one before, one after)
-	colIDLen += 2
-	colURLLen += 2
-	colTitleLen += 2
-	colCreatedLen += 2
-	colTagsLen += 2
-
-	// Calculate total width: sum of columns + 4 separators (│) + 2 spaces per separator
-	totalWidth := colIDLen + colURLLen + colTitleLen + colCreatedLen + colTagsLen + 4
-
-	header := fmt.Sprintf("%s│%s %s%-*s%s │ %s%-*s%s │ %s%-*s%s │ %s%-*s%s │ %s%-*s%s %s│%s",
-		colorDim, colorReset,
-		colorBold, colIDLen-2, "ID", colorReset,
-		colorBold, colURLLen-2, "URL", colorReset,
-		colorBold, colTitleLen-2, "TITLE", colorReset,
-		colorBold, colCreatedLen-2, "CREATED", colorReset,
-		colorBold, colTagsLen-2, "TAGS", colorReset,
-		colorDim, colorReset)
-
-	separator := fmt.Sprintf("%s├%s┼%s┼%s┼%s┼%s┤%s",
-		colorDim,
-		strings.Repeat("─", colIDLen),
-		strings.Repeat("─", colURLLen),
-		strings.Repeat("─", colTitleLen),
-		strings.Repeat("─", colCreatedLen),
-		strings.Repeat("─", colTagsLen),
-		colorReset)
-
-	topBorder := fmt.Sprintf("%s┌%s┐%s", colorDim, strings.Repeat("─", totalWidth), colorReset)
-	bottomBorder := fmt.Sprintf("%s└%s┘%s", colorDim, strings.Repeat("─", totalWidth), colorReset)
-
-	fmt.Println(topBorder)
-	fmt.Println(header)
-	fmt.Println(separator)
-
-	for _, link := range links {
-		url := truncateString(link.URL, colURLLen-2)
-		title := truncateString(link.Title, colTitleLen-2)
-		tags := truncateString(link.Tags, colTagsLen-2)
-		created := formatTime(link.CreatedAt)
-
-		idColor := colorBold + colorCyan
-		row := fmt.Sprintf("%s│%s %s%-*s%s │ %s%-*s%s │ %-*s │ %s%-*s%s │ %s%-*s%s %s│%s",
-			colorDim, colorReset,
-			idColor, colIDLen-2, link.ID, colorReset,
-			colorCyan, colURLLen-2, url, colorReset,
-			colTitleLen-2, title,
-			colorDim, colCreatedLen-2, created, colorReset,
-			colorYellow, colTagsLen-2, tags, colorReset,
-			colorDim, colorReset)
-		fmt.Println(row)
-	}
-
-	fmt.Println(bottomBorder)
-	return nil
+// displayTitle returns the link title, falling back to its URL.
+func displayTitle(link *model.Link) string {
+	if link.Title != "" {
+		return link.Title
+	}
+	return link.URL
 }
 
-func truncateLen(n, max int) int {
-	if n > max {
-		return max
+// ParseDuration parses a duration that, in addition to the units accepted by
+// time.ParseDuration, supports days ("3d") and weeks ("2w"). Negative
+// durations are errors.
+func ParseDuration(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, fmt.Errorf("empty duration")
 	}
-	return n
+	var unit time.Duration
+	switch s[len(s)-1] {
+	case 'd':
+		unit = 24 * time.Hour
+	case 'w':
+		unit = 7 * 24 * time.Hour
+	default:
+		d, err := time.ParseDuration(s)
+		if err != nil || d < 0 {
+			return 0, fmt.Errorf("invalid duration: %s", s)
+		}
+		return d, nil
+	}
+	n, err := strconv.ParseFloat(s[:len(s)-1], 64)
+	if err != nil || math.IsNaN(n) || n < 0 || n*float64(unit) > math.MaxInt64 {
+		return 0, fmt.Errorf("invalid duration: %s", s)
+	}
+	return time.Duration(n * float64(unit)), nil
 }
 
-func truncateString(s string, maxLen int) string {
-	if len(s) <= maxLen {
-		return s
+// ParseUntil parses a point in time given either as a duration from now
+// ("3d", "12h") or as a date ("2024-01-31"), taken as its start in the
+// time zone set by SetTimeFormat.
+func ParseUntil(s string, now time.Time) (time.Time, error) {
+	if t, err := time.ParseInLocation("2006-01-02", s, displayLocation); err == nil {
+		return t, nil
 	}
-	return s[:maxLen-ellipsisLen] + "..."
+	d, err := ParseDuration(s)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return now.Add(d), nil
 }
 
-func formatTime(t time.Time) string {
-	if t.IsZero() {
-		return "-"
+// ParseSince parses a point in the past given either as a duration before
+// now ("7d", "12h") or as a date ("2024-01-31"), like ParseUntil.
+func ParseSince(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if t, err := time.ParseInLocation("2006-01-02", s, displayLocation); err == nil {
+		return t, nil
+	}
+	d, err := ParseDuration(s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date or duration: %s", s)
 	}
-	return t.In(estLocation).Format("2006-01-02 15:04:05 EST")
+	return now.Add(-d), nil
 }
 
-// ParseID validates an ID string format.
+// ParseID validates an ID, a unique ID prefix, an alias, or an index into
+// the last listing ("%2").
 func ParseID(s string) (string, error) {
-	if !model.ValidateShortID(s) {
+	if !model.ValidateIDPrefix(s) && !model.ValidateAlias(s) && !isListingRef(s) {
 		return "", fmt.Errorf("invalid ID format: %s", s)
 	}
 	return s, nil
@@ -0,0 +1,362 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bunchhieng/rl/internal/model"
+	"github.com/bunchhieng/rl/internal/storage"
+)
+
+// testCommands returns Commands on an empty in-memory database, without
+// colors.
+func testCommands(t *testing.T) (*Commands, *storage.SQLiteStorage) {
+	t.Helper()
+	s, err := storage.NewSQLiteStorage(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+	SetColor(false)
+	return NewCommands(s), s
+}
+
+// addLink saves a link for a test, failing it on error.
+func addLink(t *testing.T, s storage.Storage, link *model.Link) *model.Link {
+	t.Helper()
+	added, err := s.Add(context.Background(), link)
+	if err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	return added
+}
+
+// daysAgo returns the time n days before now.
+func daysAgo(n int) time.Time {
+	return time.Now().Add(-time.Duration(n) * 24 * time.Hour)
+}
+
+// captureStdout runs fn and returns what it printed to stdout along with
+// its error.
+func captureStdout(t *testing.T, fn func() error) (string, error) {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	done := make(chan []byte)
+	go func() {
+		out, _ := io.ReadAll(r)
+		done <- out
+	}()
+	fnErr := fn()
+	os.Stdout = stdout
+	w.Close()
+	return string(<-done), fnErr
+}
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2024, 3, 10, 15, 30, 0, 0, time.Local)
+	tests := []struct {
+		in   string
+		want time.Time
+	}{
+		{"12h", now.Add(-12 * time.Hour)},
+		{"90m", now.Add(-90 * time.Minute)},
+		{"7d", now.AddDate(0, 0, -7)},
+		{"1.5d", now.Add(-36 * time.Hour)},
+		{"2w", now.AddDate(0, 0, -14)},
+		{"0d", now},
+		{" 3d ", now.AddDate(0, 0, -3)},
+		{"2024-01-31", time.Date(2024, 1, 31, 0, 0, 0, 0, time.Local)},
+		{"2024-02-29", time.Date(2024, 2, 29, 0, 0, 0, 0, time.Local)},
+		// Dates after now are taken as given
+		{"2030-01-01", time.Date(2030, 1, 1, 0, 0, 0, 0, time.Local)},
+	}
+	for _, tt := range tests {
+		got, err := ParseSince(tt.in, now)
+		if err != nil || !got.Equal(tt.want) {
+			t.Errorf("ParseSince(%q) = %v, %v; want %v", tt.in, got, err, tt.want)
+		}
+	}
+
+	for _, in := range []string{
+		"", "   ", "7", "d", "7x", "7D", "-3d", "-12h", "NaNd", "Infw", "1e300d",
+		"2023-02-29", "2024-13-01", "2024/01/31", "31-01-2024", "2024-01-31T10:00", "yesterday",
+	} {
+		if got, err := ParseSince(in, now); err == nil {
+			t.Errorf("ParseSince(%q) = %v, want an error", in, got)
+		} else if !strings.Contains(err.Error(), "invalid date or duration") {
+			t.Errorf("ParseSince(%q) error = %q, want it to say what's invalid", in, err)
+		}
+	}
+}
+
+func TestParseDateInTimezone(t *testing.T) {
+	tokyo := time.FixedZone("JST", 9*3600)
+	t.Cleanup(func() { displayLocation = time.Local })
+	SetTimeFormat(tokyo, "")
+
+	// Midnight in Tokyo is still the day before in UTC
+	want := time.Date(2024, 1, 30, 15, 0, 0, 0, time.UTC)
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for name, parse := range map[string]func(string, time.Time) (time.Time, error){
+		"ParseSince": ParseSince, "ParseUntil": ParseUntil,
+	} {
+		got, err := parse("2024-01-31", now)
+		if err != nil || !got.Equal(want) {
+			t.Errorf("%s(2024-01-31) = %v, %v; want %v", name, got, err, want)
+		}
+	}
+}
+
+func TestRemove(t *testing.T) {
+	c, s := testCommands(t)
+	trashed := addLink(t, s, &model.Link{URL: "https://example.com/a"})
+	deleted := addLink(t, s, &model.Link{URL: "https://example.com/b"})
+
+	// rm moves links to the trash, where restore can bring them back
+	out, err := captureStdout(t, func() error { return c.Remove(false, trashed.ID) })
+	if err != nil || !strings.Contains(out, "Trashed link "+trashed.ID) {
+		t.Fatalf("Remove() printed %q, %v", out, err)
+	}
+	if link, err := s.Get(c.ctx, trashed.ID); err != nil || link.TrashedAt == nil {
+		t.Errorf("Expected the link in the trash, got %+v, %v", link, err)
+	}
+
+	if _, err := captureStdout(t, func() error { return c.Remove(true, deleted.ID) }); err != nil {
+		t.Fatalf("Remove(--permanent) failed: %v", err)
+	}
+	if _, err := s.Get(c.ctx, deleted.ID); err != model.ErrNotFound {
+		t.Errorf("Expected the link deleted for good, got %v", err)
+	}
+
+	// Failures say what was being done
+	if _, err := captureStdout(t, func() error { return c.Remove(false, "zzzzzzzz") }); err == nil || !strings.HasPrefix(err.Error(), "failed to move to trash: zzzzzzzz") {
+		t.Errorf("Expected a trash failure, got %v", err)
+	}
+	if _, err := captureStdout(t, func() error { return c.Remove(true, "zzzzzzzz") }); err == nil || !strings.HasPrefix(err.Error(), "failed to delete: zzzzzzzz") {
+		t.Errorf("Expected a delete failure, got %v", err)
+	}
+}
+
+func TestSnoozeOutput(t *testing.T) {
+	c, s := testCommands(t)
+	link := addLink(t, s, &model.Link{URL: "https://example.com"})
+	t.Cleanup(func() {
+		displayLocation, dateLayout, relativeTimes = time.Local, defaultDateLayout, false
+	})
+	displayLocation, dateLayout = time.FixedZone("EST", -5*3600), "2006-01-02 15:04"
+
+	until := time.Date(2099, 1, 2, 14, 0, 0, 0, time.UTC)
+	out, err := captureStdout(t, func() error { return c.Snooze(link.ID, until) })
+	if err != nil {
+		t.Fatalf("Snooze failed: %v", err)
+	}
+	if want := "until 2099-01-02 09:00."; !strings.Contains(out, want) {
+		t.Errorf("output = %q, want it to contain %q", out, want)
+	}
+
+	relativeTimes = true
+	out, err = captureStdout(t, func() error { return c.Snooze(link.ID, time.Now().Add(49*time.Hour)) })
+	if err != nil {
+		t.Fatalf("Snooze failed: %v", err)
+	}
+	if want := "for 2d."; !strings.Contains(out, want) {
+		t.Errorf("output = %q, want it to contain %q", out, want)
+	}
+}
+
+// recordBrowser makes c open links by appending them to a file, returning
+// a function that lists the URLs opened so far.
+func recordBrowser(t *testing.T, c *Commands) func() []string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "opened")
+	c.SetBrowser(`sh -c 'echo "$1" >> "$0"' ` + path + " %s")
+	return func() []string {
+		data, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			return nil
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		return strings.Fields(string(data))
+	}
+}
+
+func TestRandom(t *testing.T) {
+	c, s := testCommands(t)
+	opened := recordBrowser(t, c)
+	read := daysAgo(1)
+	addLink(t, s, &model.Link{URL: "https://example.com/read", Tags: "go", ReadAt: &read})
+	addLink(t, s, &model.Link{URL: "https://example.com/rust", Tags: "rust"})
+	addLink(t, s, &model.Link{URL: "https://example.com/long", Tags: "go", ReadingSeconds: 3600})
+	short := addLink(t, s, &model.Link{URL: "https://example.com/short", Tags: "go", ReadingSeconds: 300})
+
+	// Only one unread go link fits in 10 minutes, so the pick is certain
+	opts := storage.ListOptions{ReadStatus: storage.ReadStatusUnread, Tag: "go", MaxReadingTime: 10 * time.Minute}
+	c.SetJSON(true)
+	out, err := captureStdout(t, func() error { return c.Random(opts, true) })
+	c.SetJSON(false)
+	if err != nil {
+		t.Fatalf("Random failed: %v", err)
+	}
+	var picked model.Link
+	if err := json.NewDecoder(strings.NewReader(out)).Decode(&picked); err != nil {
+		t.Fatalf("Expected JSON output, got %q: %v", out, err)
+	}
+	if picked.ID != short.ID {
+		t.Errorf("Random picked %s, want %s", picked.URL, short.URL)
+	}
+	if got := opened(); len(got) != 1 || got[0] != short.URL {
+		t.Errorf("opened %q, want %s", got, short.URL)
+	}
+
+	opts.Tag = "python"
+	out, err = captureStdout(t, func() error { return c.Random(opts, true) })
+	if err != nil || !strings.Contains(out, "No links found.") {
+		t.Errorf("Random with no matches printed %q, %v", out, err)
+	}
+	if got := opened(); len(got) != 1 {
+		t.Errorf("opened %q with nothing picked", got)
+	}
+}
+
+func TestOpenDone(t *testing.T) {
+	c, s := testCommands(t)
+	opened := recordBrowser(t, c)
+	kept := addLink(t, s, &model.Link{URL: "https://example.com/kept"})
+	done := addLink(t, s, &model.Link{URL: "https://example.com/done"})
+	readAt := daysAgo(3)
+	reread := addLink(t, s, &model.Link{URL: "https://example.com/reread", ReadAt: &readAt})
+
+	for _, open := range []struct {
+		id       string
+		markDone bool
+	}{{kept.ID, false}, {done.ID, true}, {reread.ID, true}} {
+		if _, err := captureStdout(t, func() error { return c.Open(open.markDone, false, open.id) }); err != nil {
+			t.Fatalf("Open(%s) failed: %v", open.id, err)
+		}
+	}
+	if got := opened(); len(got) != 3 {
+		t.Errorf("opened %q, want all three", got)
+	}
+	if link, _ := s.Get(c.ctx, kept.ID); link.IsRead() {
+		t.Error("Open without --done marked the link read")
+	}
+	if link, _ := s.Get(c.ctx, done.ID); !link.IsRead() {
+		t.Error("Open --done left the link unread")
+	}
+	// A link read before keeps when it was read
+	if link, _ := s.Get(c.ctx, reread.ID); link.ReadAt == nil || !link.ReadAt.Equal(readAt.Truncate(time.Second)) {
+		t.Errorf("Open --done of a read link changed its read time to %v, want %v", link.ReadAt, readAt)
+	}
+
+	// Nothing is marked read if the browser fails
+	failed := addLink(t, s, &model.Link{URL: "https://example.com/failed"})
+	c.SetBrowser("false")
+	if _, err := captureStdout(t, func() error { return c.Open(true, false, failed.ID) }); err == nil {
+		t.Error("Open succeeded with a failing browser")
+	}
+	if link, _ := s.Get(c.ctx, failed.ID); link.IsRead() {
+		t.Error("Open --done marked the link read though the browser failed")
+	}
+}
+
+func TestSeveralIDs(t *testing.T) {
+	c, s := testCommands(t)
+	opened := recordBrowser(t, c)
+	a := addLink(t, s, &model.Link{URL: "https://example.com/a"})
+	b := addLink(t, s, &model.Link{URL: "https://example.com/b"})
+	isRead := func(id string) bool {
+		link, err := s.Get(c.ctx, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return link.IsRead()
+	}
+
+	// A missing ID doesn't stop the others
+	_, err := captureStdout(t, func() error { return c.Done(a.ID, "zzzzzzzz", b.ID) })
+	if err == nil || !strings.HasPrefix(err.Error(), "failed to mark read: zzzzzzzz (") {
+		t.Errorf("Done with a missing ID = %v, want it named", err)
+	}
+	if !isRead(a.ID) || !isRead(b.ID) {
+		t.Error("Done left a link unread")
+	}
+
+	if _, err := captureStdout(t, func() error { return c.Undo(a.ID, b.ID) }); err != nil {
+		t.Fatalf("Undo failed: %v", err)
+	}
+	if isRead(a.ID) || isRead(b.ID) {
+		t.Error("Undo left a link read")
+	}
+
+	if _, err := captureStdout(t, func() error { return c.Open(false, false, a.ID, b.ID) }); err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if got, want := opened(), []string{a.URL, b.URL}; !reflect.DeepEqual(got, want) {
+		t.Errorf("opened %q, want %q", got, want)
+	}
+
+	// One ID fails with its own error
+	if _, err := captureStdout(t, func() error { return c.Done("zzzzzzzz") }); err == nil || strings.HasPrefix(err.Error(), "failed to") {
+		t.Errorf("Done of one missing ID = %v, want its own error", err)
+	}
+	if err := c.Done(); err == nil {
+		t.Error("Done without IDs succeeded")
+	}
+}
+
+func TestShow(t *testing.T) {
+	c, s := testCommands(t)
+	link := addLink(t, s, &model.Link{URL: "https://example.com/a", Title: "A title", Tags: "go,db", Priority: model.PriorityHigh})
+	if _, err := s.AddAnnotation(c.ctx, link.ID, "worth a reread"); err != nil {
+		t.Fatal(err)
+	}
+	bare := addLink(t, s, &model.Link{URL: "https://example.com/b"})
+
+	out, err := captureStdout(t, func() error { return c.Show(link.ID) })
+	if err != nil {
+		t.Fatalf("Show failed: %v", err)
+	}
+	for _, want := range []string{link.ID, "https://example.com/a", "A title", "go,db", "high", "unread", "worth a reread"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Show output lacks %q:\n%s", want, out)
+		}
+	}
+
+	// JSON output always has the lists, empty or not
+	c.SetJSON(true)
+	defer c.SetJSON(false)
+	for _, tt := range []struct {
+		id    string
+		notes int
+	}{{link.ID, 1}, {bare.ID, 0}} {
+		out, err := captureStdout(t, func() error { return c.Show(tt.id) })
+		if err != nil {
+			t.Fatalf("Show(--json) failed: %v", err)
+		}
+		var got struct {
+			ID          string             `json:"id"`
+			Annotations []model.Annotation `json:"annotations"`
+			Quotes      []model.Quote      `json:"quotes"`
+		}
+		if err := json.Unmarshal([]byte(out), &got); err != nil {
+			t.Fatalf("Expected JSON output, got %q: %v", out, err)
+		}
+		if got.ID != tt.id || len(got.Annotations) != tt.notes || got.Quotes == nil {
+			t.Errorf("Show(--json) = %+v, want %s with %d notes and a quotes list", got, tt.id, tt.notes)
+		}
+	}
+}
@@ -0,0 +1,430 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bunchhieng/rl/internal/backup"
+	"github.com/bunchhieng/rl/internal/model"
+	"github.com/bunchhieng/rl/internal/notify"
+	"github.com/bunchhieng/rl/internal/storage"
+)
+
+const (
+	// daemonHeartbeat is how often the daemon records that it is alive,
+	// at the latest; a daemon silent for two beats is taken to be gone.
+	daemonHeartbeat = time.Minute
+	// minJobInterval is how often a job may run at most.
+	minJobInterval = time.Minute
+)
+
+// DaemonJob is a job the daemon runs, and how often.
+type DaemonJob struct {
+	Name  string
+	Every time.Duration
+}
+
+// DaemonOptions configures Daemon.
+type DaemonOptions struct {
+	// Jobs run in this order when several are due at once.
+	Jobs []DaemonJob
+	// Cleanup is the policy the cleanup job applies.
+	Cleanup CleanupPolicy
+	// StatePath records each job's last run, for DaemonStatus.
+	StatePath string
+	// RemindPath is where the remind job records its last check, shared
+	// with rl remind.
+	RemindPath string
+	// Log receives a JSON line per event.
+	Log io.Writer
+}
+
+// DaemonState is what the daemon records in its state file.
+type DaemonState struct {
+	// PID is the running daemon's process ID, or 0 once it stopped.
+	PID       int                `json:"pid,omitempty"`
+	StartedAt time.Time          `json:"started_at"`
+	StoppedAt *time.Time         `json:"stopped_at,omitempty"`
+	Heartbeat time.Time          `json:"heartbeat"`
+	Jobs      map[string]*JobRun `json:"jobs"`
+}
+
+// JobRun is the last run of a daemon job.
+type JobRun struct {
+	LastRun  time.Time `json:"last_run"`
+	Duration string    `json:"duration"`
+	// Counts are what the run did, e.g. {"fetched": 3, "failed": 1}.
+	Counts map[string]int `json:"counts,omitempty"`
+	Error  string         `json:"error,omitempty"`
+}
+
+// running reports whether a daemon is still recording heartbeats.
+func (s *DaemonState) running(now time.Time) bool {
+	return s.PID != 0 && now.Sub(s.Heartbeat) < 2*daemonHeartbeat
+}
+
+// daemon is a running rl daemon.
+type daemon struct {
+	c       *Commands
+	opts    DaemonOptions
+	log     *slog.Logger
+	pending sync.WaitGroup // notifications waiting to be clicked
+}
+
+// daemonJobs are the jobs the daemon can run, reporting counts of what
+// they did.
+var daemonJobs = map[string]func(d *daemon) (map[string]int, error){
+	"backfill": (*daemon).backfill,
+	"check":    (*daemon).check,
+	"cleanup":  (*daemon).cleanup,
+	"backup":   (*daemon).backup,
+	"remind":   (*daemon).remind,
+}
+
+// Daemon runs opts.Jobs on their schedules until interrupted, logging each
+// run and recording it for DaemonStatus. A job that was due while no
+// daemon ran runs as soon as the daemon starts.
+func (c *Commands) Daemon(opts DaemonOptions) error {
+	if len(opts.Jobs) == 0 {
+		return fmt.Errorf("no jobs scheduled; add them under [daemon.jobs] in the config file, e.g. backfill = \"6h\"")
+	}
+	for _, job := range opts.Jobs {
+		if daemonJobs[job.Name] == nil {
+			return fmt.Errorf("unknown daemon job %q", job.Name)
+		}
+		if job.Every < minJobInterval {
+			return fmt.Errorf("daemon job %s must run at most once a minute, got every %s", job.Name, job.Every)
+		}
+		switch {
+		case job.Name == "cleanup" && opts.Cleanup.IsZero():
+			return fmt.Errorf("the cleanup job needs archive_read_after_days or delete_trashed_after_days under [cleanup] in the config file")
+		case job.Name == "backup" && c.backups == nil:
+			return fmt.Errorf("the backup job needs a database file to back up")
+		case job.Name == "remind":
+			if err := notify.Available(); err != nil {
+				return err
+			}
+		}
+	}
+
+	state, err := LoadDaemonState(opts.StatePath)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	if state.running(now) {
+		return fmt.Errorf("a daemon is already running (pid %d)", state.PID)
+	}
+	state.PID, state.StartedAt, state.StoppedAt, state.Heartbeat = os.Getpid(), now, nil, now
+	if state.Jobs == nil {
+		state.Jobs = map[string]*JobRun{}
+	}
+	if err := saveDaemonState(opts.StatePath, state); err != nil {
+		return err
+	}
+
+	d := &daemon{c: c, opts: opts, log: slog.New(slog.NewJSONHandler(opts.Log, nil))}
+	defer d.pending.Wait()
+	names := make([]string, len(opts.Jobs))
+	for i, job := range opts.Jobs {
+		names[i] = job.Name
+	}
+	d.log.Info("daemon started", "pid", state.PID, "jobs", names)
+
+	for {
+		for _, job := range opts.Jobs {
+			if c.ctx.Err() != nil {
+				break
+			}
+			if last := state.Jobs[job.Name]; last != nil && time.Now().Before(last.LastRun.Add(job.Every)) {
+				continue
+			}
+			run := d.run(job.Name)
+			if c.ctx.Err() != nil {
+				// Cut short; run it again next time
+				break
+			}
+			state.Jobs[job.Name] = run
+			if err := saveDaemonState(opts.StatePath, state); err != nil {
+				d.log.Error("saving state failed", "error", err.Error())
+			}
+		}
+
+		// Sleep until the next job is due, waking each minute to show
+		// the daemon is alive
+		now := time.Now()
+		wake := now.Add(daemonHeartbeat)
+		for _, job := range opts.Jobs {
+			if run := state.Jobs[job.Name]; run != nil && run.LastRun.Add(job.Every).Before(wake) {
+				wake = run.LastRun.Add(job.Every)
+			}
+		}
+		timer := time.NewTimer(max(wake.Sub(now), 0))
+		select {
+		case <-c.ctx.Done():
+			timer.Stop()
+			stopped := time.Now()
+			state.PID, state.StoppedAt = 0, &stopped
+			d.log.Info("daemon stopped")
+			return saveDaemonState(opts.StatePath, state)
+		case <-timer.C:
+		}
+		state.Heartbeat = time.Now()
+		if err := saveDaemonState(opts.StatePath, state); err != nil {
+			d.log.Error("saving state failed", "error", err.Error())
+		}
+	}
+}
+
+// run runs the job called name and logs how it went.
+func (d *daemon) run(name string) *JobRun {
+	start := time.Now()
+	counts, err := daemonJobs[name](d)
+	run := &JobRun{LastRun: start, Duration: time.Since(start).Round(time.Millisecond).String(), Counts: counts}
+
+	attrs := []any{"job", name, "duration", run.Duration}
+	for _, key := range sortedKeys(counts) {
+		attrs = append(attrs, key, counts[key])
+	}
+	switch {
+	case d.c.ctx.Err() != nil:
+		d.log.Info("job interrupted", attrs...)
+	case err != nil:
+		run.Error = err.Error()
+		d.log.Error("job failed", append(attrs, "error", run.Error)...)
+	default:
+		d.log.Info("job finished", attrs...)
+	}
+	return run
+}
+
+// backfill fetches the pages of links without a title or reading time.
+func (d *daemon) backfill() (map[string]int, error) {
+	links, err := d.c.fetchCandidates(true)
+	if err != nil {
+		return nil, err
+	}
+	counts := map[string]int{"fetched": 0, "failed": 0}
+	d.c.fetchEach(links, false, func(_ *model.Link, _ bool, err error) {
+		if err != nil {
+			counts["failed"]++
+		} else {
+			counts["fetched"]++
+		}
+	})
+	return counts, nil
+}
+
+// check checks the unread links for pages that are gone.
+func (d *daemon) check() (map[string]int, error) {
+	links, err := d.c.storage.List(d.c.ctx, storage.ListOptions{ReadStatus: storage.ReadStatusUnread})
+	if err != nil {
+		return nil, fmt.Errorf("list links: %w", err)
+	}
+	counts := map[string]int{"checked": 0, "dead": 0}
+	err = d.c.checkLinks(links, func(res checkResult) {
+		counts["checked"]++
+		if res.link.IsDead() {
+			counts["dead"]++
+		}
+	})
+	return counts, err
+}
+
+// cleanup applies the [cleanup] policies.
+func (d *daemon) cleanup() (map[string]int, error) {
+	result, err := d.c.AutoCleanup(d.opts.Cleanup)
+	return map[string]int{"archived": result.Archived, "deleted": result.Deleted}, err
+}
+
+// backup snapshots the database.
+func (d *daemon) backup() (map[string]int, error) {
+	snap, err := d.c.backups.Create(d.c.ctx, d.c.storage, backup.ReasonScheduled)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]int{"bytes": int(snap.Size)}, nil
+}
+
+// remind notifies about links whose snooze ended since the last check.
+func (d *daemon) remind() (map[string]int, error) {
+	now := time.Now()
+	since, err := loadRemindState(d.opts.RemindPath)
+	if err != nil {
+		return nil, err
+	}
+	if since.IsZero() {
+		since = now
+	}
+	links, err := d.c.remindWoken(d.opts.RemindPath, since, now, &d.pending, func(err error) {
+		d.log.Warn("notification failed", "error", err.Error())
+	})
+	return map[string]int{"reminded": len(links)}, err
+}
+
+// LoadDaemonState reads the daemon's state file; a missing file is an
+// empty state.
+func LoadDaemonState(path string) (*DaemonState, error) {
+	state := &DaemonState{}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read daemon state: %w", err)
+	}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("read daemon state: %w", err)
+	}
+	return state, nil
+}
+
+// saveDaemonState replaces the state file, so DaemonStatus never reads
+// half of one.
+func saveDaemonState(path string, state *DaemonState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("save daemon state: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("save daemon state: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("save daemon state: %w", err)
+	}
+	return nil
+}
+
+// DaemonStatus prints whether the daemon is running and each scheduled
+// job's last run and next one.
+func DaemonStatus(statePath string, jobs []DaemonJob, jsonOutput bool) error {
+	state, err := LoadDaemonState(statePath)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	running := state.running(now)
+	if jsonOutput {
+		type jobStatus struct {
+			Name    string     `json:"name"`
+			Every   string     `json:"every"`
+			LastRun *JobRun    `json:"last_run,omitempty"`
+			NextRun *time.Time `json:"next_run,omitempty"`
+		}
+		out := struct {
+			Running   bool        `json:"running"`
+			PID       int         `json:"pid,omitempty"`
+			StartedAt *time.Time  `json:"started_at,omitempty"`
+			Jobs      []jobStatus `json:"jobs"`
+		}{Running: running, Jobs: []jobStatus{}}
+		if running {
+			out.PID, out.StartedAt = state.PID, &state.StartedAt
+		}
+		for _, job := range jobs {
+			status := jobStatus{Name: job.Name, Every: formatEvery(job.Every), LastRun: state.Jobs[job.Name]}
+			if running {
+				next := nextRun(state, job, now)
+				status.NextRun = &next
+			}
+			out.Jobs = append(out.Jobs, status)
+		}
+		return printJSON(out)
+	}
+
+	switch {
+	case running:
+		fmt.Printf("%sRunning%s since %s (pid %d).\n", colorGreen, colorReset, formatTime(state.StartedAt), state.PID)
+	case state.StoppedAt != nil:
+		fmt.Printf("%sNot running%s; stopped %s.\n", colorYellow, colorReset, formatTime(*state.StoppedAt))
+	case state.PID != 0:
+		fmt.Printf("%sNot running%s; last seen %s without stopping cleanly.\n", colorRed, colorReset, formatTime(state.Heartbeat))
+	default:
+		fmt.Printf("%sNot running%s; start it with `rl daemon`.\n", colorYellow, colorReset)
+	}
+	if len(jobs) == 0 {
+		fmt.Println("No jobs scheduled; add them under [daemon.jobs] in the config file.")
+		return nil
+	}
+
+	rows := make([][]string, len(jobs))
+	widths := []int{len("JOB"), len("EVERY"), len("LAST RUN"), len("RESULT")}
+	for i, job := range jobs {
+		last, result, next := "never", "-", "-"
+		if run := state.Jobs[job.Name]; run != nil {
+			last = formatTime(run.LastRun)
+			if summary := run.summary(); summary != "" {
+				result = summary
+			}
+		}
+		if running {
+			next = formatTime(nextRun(state, job, now))
+		}
+		rows[i] = []string{job.Name, formatEvery(job.Every), last, result, next}
+		for j := range widths {
+			widths[j] = max(widths[j], len(rows[i][j]))
+		}
+	}
+	fmt.Printf("%s%-*s  %-*s  %-*s  %-*s  %s%s\n", colorBold, widths[0], "JOB", widths[1], "EVERY", widths[2], "LAST RUN",
+		widths[3], "RESULT", "NEXT RUN", colorReset)
+	for _, row := range rows {
+		fmt.Printf("%-*s  %-*s  %-*s  %-*s  %s\n", widths[0], row[0], widths[1], row[1], widths[2], row[2], widths[3], row[3], row[4])
+	}
+	return nil
+}
+
+// nextRun is when a running daemon next runs job: when it is due, or
+// now if it already is.
+func nextRun(state *DaemonState, job DaemonJob, now time.Time) time.Time {
+	run := state.Jobs[job.Name]
+	if run == nil || run.LastRun.Add(job.Every).Before(now) {
+		return now
+	}
+	return run.LastRun.Add(job.Every)
+}
+
+// summary describes a run in a few words, e.g. "fetched 3, failed 1".
+func (r *JobRun) summary() string {
+	if r.Error != "" {
+		return "failed: " + r.Error
+	}
+	parts := make([]string, 0, len(r.Counts))
+	for _, key := range sortedKeys(r.Counts) {
+		parts = append(parts, fmt.Sprintf("%s %d", key, r.Counts[key]))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// formatEvery formats a job interval without trailing zero units, e.g.
+// "6h" rather than "6h0m0s", and whole days as "1d".
+func formatEvery(d time.Duration) string {
+	const day = 24 * time.Hour
+	if d%day == 0 {
+		return fmt.Sprintf("%dd", d/day)
+	}
+	s := d.String()
+	s = strings.Replace(s, "m0s", "m", 1)
+	s = strings.Replace(s, "h0m", "h", 1)
+	return s
+}
+
+func sortedKeys(m map[string]int) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
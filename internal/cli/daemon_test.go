@@ -0,0 +1,192 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bunchhieng/rl/internal/model"
+)
+
+// syncBuffer is a bytes.Buffer safe to write from the daemon while the
+// test reads it.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// runDaemon starts the daemon and stops it once until reports true,
+// returning its state and log.
+func runDaemon(t *testing.T, c *Commands, opts DaemonOptions, until func(log string) bool) (*DaemonState, string) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	c.SetContext(ctx)
+	log := &syncBuffer{}
+	opts.Log = log
+	done := make(chan error, 1)
+	go func() { done <- c.Daemon(opts) }()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !until(log.String()) {
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for the daemon, log:\n%s", log)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Daemon failed: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the daemon to stop when cancelled")
+	}
+	state, err := LoadDaemonState(opts.StatePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return state, log.String()
+}
+
+func TestDaemon(t *testing.T) {
+	c, s := testCommands(t)
+	readAt := daysAgo(60)
+	addLink(t, s, &model.Link{URL: "https://example.com/read", ReadAt: &readAt})
+	opts := DaemonOptions{
+		Jobs:      []DaemonJob{{Name: "cleanup", Every: time.Hour}},
+		Cleanup:   CleanupPolicy{ArchiveReadAfter: 30 * 24 * time.Hour},
+		StatePath: filepath.Join(t.TempDir(), "daemon.json"),
+	}
+
+	// A job never run is due at once
+	state, log := runDaemon(t, c, opts, func(log string) bool { return strings.Contains(log, "job finished") })
+	if state.PID != 0 || state.StoppedAt == nil {
+		t.Errorf("Expected the daemon recorded as stopped, got %+v", state)
+	}
+	if info, err := os.Stat(opts.StatePath); err != nil {
+		t.Error(err)
+	} else if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("Expected the state file readable only by its owner, got %v", perm)
+	}
+	run := state.Jobs["cleanup"]
+	if run == nil || run.Error != "" || run.Counts["archived"] != 1 {
+		t.Errorf("Expected the cleanup run recorded, got %+v", run)
+	}
+	if !strings.Contains(log, `"msg":"daemon started"`) || !strings.Contains(log, `"job":"cleanup"`) ||
+		!strings.Contains(log, `"msg":"daemon stopped"`) {
+		t.Errorf("Expected JSON log lines for the daemon and the job, got:\n%s", log)
+	}
+
+	// Restarted, it keeps the schedule rather than running the job again
+	lastRun := run.LastRun
+	state, log = runDaemon(t, c, opts, func(log string) bool { return strings.Contains(log, "daemon started") })
+	if strings.Contains(log, "job finished") || !state.Jobs["cleanup"].LastRun.Equal(lastRun) {
+		t.Errorf("Expected the cleanup job not due yet, got log:\n%s", log)
+	}
+
+	// Only one daemon runs at a time
+	state.PID, state.Heartbeat = 1, time.Now()
+	if err := saveDaemonState(opts.StatePath, state); err != nil {
+		t.Fatal(err)
+	}
+	c.SetContext(context.Background())
+	opts.Log = &syncBuffer{}
+	if err := c.Daemon(opts); err == nil || !strings.Contains(err.Error(), "already running") {
+		t.Errorf("Expected a second daemon refused, got %v", err)
+	}
+}
+
+func TestDaemonRejectsJobs(t *testing.T) {
+	c, _ := testCommands(t)
+	statePath := filepath.Join(t.TempDir(), "daemon.json")
+	tests := map[string]struct {
+		opts DaemonOptions
+		want string
+	}{
+		"no jobs":      {DaemonOptions{}, "no jobs scheduled"},
+		"unknown job":  {DaemonOptions{Jobs: []DaemonJob{{Name: "feeds", Every: time.Hour}}}, `unknown daemon job "feeds"`},
+		"too often":    {DaemonOptions{Jobs: []DaemonJob{{Name: "check", Every: time.Second}}}, "at most once a minute"},
+		"no policy":    {DaemonOptions{Jobs: []DaemonJob{{Name: "cleanup", Every: time.Hour}}}, "the cleanup job needs"},
+		"no db backup": {DaemonOptions{Jobs: []DaemonJob{{Name: "backup", Every: time.Hour}}}, "the backup job needs"},
+	}
+	for name, tt := range tests {
+		tt.opts.StatePath = statePath
+		err := c.Daemon(tt.opts)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: expected an error containing %q, got %v", name, tt.want, err)
+		}
+	}
+	// Refused before starting, so no daemon is recorded
+	if _, err := os.Stat(statePath); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Expected no state file, got %v", err)
+	}
+}
+
+func TestFormatEvery(t *testing.T) {
+	tests := map[time.Duration]string{
+		time.Minute:                "1m",
+		90 * time.Minute:           "1h30m",
+		6 * time.Hour:              "6h",
+		24 * time.Hour:             "1d",
+		7 * 24 * time.Hour:         "7d",
+		36 * time.Hour:             "36h",
+		time.Hour + 30*time.Second: "1h30s",
+	}
+	for d, want := range tests {
+		if got := formatEvery(d); got != want {
+			t.Errorf("formatEvery(%s) = %q, want %q", d, got, want)
+		}
+	}
+}
+
+func TestDaemonSchedule(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	state := &DaemonState{PID: 1, Heartbeat: now.Add(-30 * time.Second), Jobs: map[string]*JobRun{
+		"backfill": {LastRun: now.Add(-time.Hour), Counts: map[string]int{"fetched": 3, "failed": 1}},
+		"check":    {LastRun: now.Add(-2 * 24 * time.Hour), Error: "list links: database is locked"},
+	}}
+
+	if !state.running(now) {
+		t.Error("Expected a daemon with a recent heartbeat to be running")
+	}
+	if state.running(now.Add(2 * daemonHeartbeat)) {
+		t.Error("Expected a daemon without heartbeats to be gone")
+	}
+
+	if got, want := nextRun(state, DaemonJob{Name: "backfill", Every: 6 * time.Hour}, now), now.Add(5*time.Hour); !got.Equal(want) {
+		t.Errorf("nextRun(backfill) = %s, want %s", got, want)
+	}
+	if got := nextRun(state, DaemonJob{Name: "check", Every: 24 * time.Hour}, now); !got.Equal(now) {
+		t.Errorf("nextRun(overdue check) = %s, want now", got)
+	}
+	if got := nextRun(state, DaemonJob{Name: "backup", Every: time.Hour}, now); !got.Equal(now) {
+		t.Errorf("nextRun(never run backup) = %s, want now", got)
+	}
+
+	if got := state.Jobs["backfill"].summary(); got != "failed 1, fetched 3" {
+		t.Errorf("summary() = %q", got)
+	}
+	if got := state.Jobs["check"].summary(); got != "failed: list links: database is locked" {
+		t.Errorf("summary() = %q", got)
+	}
+}
@@ -0,0 +1,388 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+
+	"github.com/bunchhieng/rl/internal/attach"
+	"github.com/bunchhieng/rl/internal/backup"
+	"github.com/bunchhieng/rl/internal/storage"
+)
+
+// DBCommands handles rl db, which looks after the database itself rather
+// than the links in it. The database is opened without applying
+// migrations so its schema can be inspected and rolled back.
+type DBCommands struct {
+	ctx         context.Context
+	db          *storage.SQLiteStorage
+	jsonOutput  bool
+	attachments *attach.Store
+	backups     *backup.Store
+}
+
+// NewDBCommands creates a DBCommands for an open database.
+func NewDBCommands(db *storage.SQLiteStorage) *DBCommands {
+	return &DBCommands{ctx: context.Background(), db: db}
+}
+
+// SetContext sets the context commands run under.
+func (d *DBCommands) SetContext(ctx context.Context) {
+	d.ctx = ctx
+}
+
+// SetJSON enables JSON output.
+func (d *DBCommands) SetJSON(enabled bool) {
+	d.jsonOutput = enabled
+}
+
+// SetBackups sets where the database is snapshotted before Doctor
+// repairs it.
+func (d *DBCommands) SetBackups(store *backup.Store) {
+	d.backups = store
+}
+
+// SetAttachments sets the directory of saved documents, whose unreferenced
+// files doctor --fix deletes.
+func (d *DBCommands) SetAttachments(store *attach.Store) {
+	d.attachments = store
+}
+
+// Migrations lists the schema migrations and whether each is applied.
+func (d *DBCommands) Migrations() error {
+	migrations, err := d.db.Migrations(d.ctx)
+	if err != nil {
+		return err
+	}
+	if d.jsonOutput {
+		return printJSON(migrations)
+	}
+
+	width := len("NAME")
+	for _, m := range migrations {
+		if len(m.Name) > width {
+			width = len(m.Name)
+		}
+	}
+	fmt.Printf("%s%-7s  %-*s  %-19s  %s%s\n", colorBold, "VERSION", width, "NAME", "APPLIED", "DOWN", colorReset)
+	pending := 0
+	for _, m := range migrations {
+		name, applied, down := m.Name, "pending", "no"
+		color := colorYellow
+		if m.AppliedAt != nil {
+			applied = m.AppliedAt.Local().Format("2006-01-02 15:04:05")
+			color = ""
+		} else {
+			pending++
+		}
+		if m.Unknown {
+			name, down = "(unknown to this rl)", "?"
+		} else if m.Reversible {
+			down = "yes"
+		}
+		fmt.Printf("%03d      %-*s  %s%-19s%s  %s\n", m.Version, width, name, color, applied, colorReset, down)
+	}
+	if pending > 0 {
+		fmt.Printf("\n%s pending; the next rl command applies them.\n", plural(pending, "migration"))
+	}
+	return nil
+}
+
+// Rollback reverses the newest steps applied migrations.
+func (d *DBCommands) Rollback(steps int) error {
+	migrations, err := d.db.Migrations(d.ctx)
+	if err != nil {
+		return err
+	}
+	var applied []int
+	for _, m := range migrations {
+		if m.AppliedAt != nil {
+			applied = append(applied, m.Version)
+		}
+	}
+	if len(applied) == 0 || steps <= 0 {
+		return d.RollbackTo(math.MaxInt)
+	}
+	if steps > len(applied) {
+		steps = len(applied)
+	}
+	return d.RollbackTo(applied[len(applied)-steps] - 1)
+}
+
+// RollbackTo reverses every applied migration newer than version.
+func (d *DBCommands) RollbackTo(version int) error {
+	rolledBack, err := d.db.RollbackTo(d.ctx, version)
+	if err != nil {
+		return err
+	}
+	if d.jsonOutput {
+		if rolledBack == nil {
+			rolledBack = []storage.MigrationStatus{}
+		}
+		return printJSON(rolledBack)
+	}
+	if len(rolledBack) == 0 {
+		fmt.Println("Nothing to roll back.")
+		return nil
+	}
+	for _, m := range rolledBack {
+		fmt.Printf("%sRolled back%s %03d_%s\n", colorGreen, colorReset, m.Version, m.Name)
+	}
+	fmt.Fprintf(os.Stderr, "%sWarning:%s data kept only by these migrations is gone. The next rl command applies them again; use an older rl to keep them rolled back.\n",
+		colorYellow, colorReset)
+	return nil
+}
+
+// doctorReport is what Doctor found and did.
+type doctorReport struct {
+	// Integrity lists the problems SQLite's integrity check found.
+	Integrity         []string             `json:"integrity"`
+	PendingMigrations int                  `json:"pending_migrations"`
+	Indexes           []storage.IndexCheck `json:"indexes"`
+	Orphans           []storage.Count      `json:"orphans"`
+	// Repairs describes what --fix changed.
+	Repairs []string `json:"repairs"`
+	// Backup is the snapshot taken before the first repair.
+	Backup string `json:"backup,omitempty"`
+	// Reclaimed is the space vacuuming freed, in bytes.
+	Reclaimed int64           `json:"reclaimed"`
+	Size      *storage.DBSize `json:"size"`
+}
+
+// Doctor checks the database for corruption, out-of-date search indexes,
+// and rows left behind by deleted links, then checkpoints and vacuums it.
+// With fix, pending migrations are applied, indexes rebuilt, and orphaned
+// rows deleted, after snapshotting the database if a backup store is set.
+// A corrupt database is left untouched.
+func (d *DBCommands) Doctor(fix bool) error {
+	ctx := d.ctx
+	report := doctorReport{Indexes: []storage.IndexCheck{}, Orphans: []storage.Count{}, Repairs: []string{}}
+	problems := 0
+
+	integrity, err := d.db.IntegrityCheck(ctx)
+	if err != nil {
+		return err
+	}
+	if len(integrity) > 0 {
+		report.Integrity = integrity
+		if d.jsonOutput {
+			if err := printJSON(report); err != nil {
+				return err
+			}
+		} else {
+			fmt.Printf("%-11s %sfailed%s\n", "Integrity", colorRed, colorReset)
+			for _, problem := range integrity {
+				fmt.Printf("  %s\n", problem)
+			}
+		}
+		return fmt.Errorf("the database is corrupt; restore it from a backup (nothing was changed)")
+	}
+	report.Integrity = []string{}
+	d.doctorLine("Integrity", "ok", "")
+
+	migrations, err := d.db.Migrations(ctx)
+	if err != nil {
+		return err
+	}
+	for _, m := range migrations {
+		if m.AppliedAt == nil {
+			report.PendingMigrations++
+		}
+	}
+	switch {
+	case report.PendingMigrations == 0:
+		d.doctorLine("Migrations", "up to date", "")
+	case fix:
+		if err := d.backUp(ctx, &report); err != nil {
+			return err
+		}
+		if err := d.db.Migrate(ctx); err != nil {
+			return err
+		}
+		repair := fmt.Sprintf("applied %s", plural(report.PendingMigrations, "pending migration"))
+		report.Repairs = append(report.Repairs, repair)
+		d.doctorLine("Migrations", repair, colorGreen)
+	default:
+		// Later checks assume the current schema
+		problems++
+		d.doctorLine("Migrations", fmt.Sprintf("%d pending; skipping index and orphan checks", report.PendingMigrations), colorYellow)
+	}
+
+	if report.PendingMigrations == 0 || fix {
+		n, err := d.checkIndexes(ctx, &report, fix)
+		if err != nil {
+			return err
+		}
+		problems += n
+		if n, err = d.checkOrphans(ctx, &report, fix); err != nil {
+			return err
+		}
+		problems += n
+	}
+
+	if err := d.db.Checkpoint(ctx); err != nil {
+		return err
+	}
+	before, err := d.db.Size(ctx)
+	if err != nil {
+		return err
+	}
+	if err := d.db.Vacuum(ctx); err != nil {
+		return err
+	}
+	if err := d.db.Checkpoint(ctx); err != nil {
+		return err
+	}
+	if report.Size, err = d.db.Size(ctx); err != nil {
+		return err
+	}
+	report.Reclaimed = max(before.Bytes-report.Size.Bytes, 0)
+	d.doctorLine("Vacuum", fmt.Sprintf("reclaimed %s", formatSize(report.Reclaimed)), "")
+
+	if d.jsonOutput {
+		if err := printJSON(report); err != nil {
+			return err
+		}
+	} else {
+		printDBSize(report.Size)
+	}
+	if problems > 0 {
+		return fmt.Errorf("found %s; run `rl db doctor --fix` to repair", plural(problems, "problem"))
+	}
+	return nil
+}
+
+// backUp snapshots the database before Doctor's first repair, so a
+// repair gone wrong can be undone with rl backup restore.
+func (d *DBCommands) backUp(ctx context.Context, report *doctorReport) error {
+	if d.backups == nil || report.Backup != "" {
+		return nil
+	}
+	snap, err := d.backups.Create(ctx, d.db, backup.ReasonDoctor)
+	if err != nil {
+		return fmt.Errorf("back up before repairing: %w", err)
+	}
+	report.Backup = snap.Path
+	d.doctorLine("Backup", snap.Path, "")
+	return nil
+}
+
+// checkIndexes compares the search indexes with their tables, rebuilding
+// them with fix, and returns the number of problems left.
+func (d *DBCommands) checkIndexes(ctx context.Context, report *doctorReport, fix bool) (int, error) {
+	checks, err := d.db.CheckIndexes(ctx)
+	if err != nil {
+		return 0, err
+	}
+	report.Indexes = checks
+	var issues []string
+	for _, check := range checks {
+		switch {
+		case check.Corrupt != "":
+			issues = append(issues, fmt.Sprintf("%s corrupt", check.Index))
+		case !check.OK():
+			issues = append(issues, fmt.Sprintf("%s %d missing, %d stale", check.Index, check.Missing, check.Stale))
+		}
+	}
+	switch {
+	case len(issues) == 0:
+		d.doctorLine("Indexes", "ok", "")
+		return 0, nil
+	case fix:
+		if err := d.backUp(ctx, report); err != nil {
+			return 0, err
+		}
+		if _, err := d.db.Reindex(ctx); err != nil {
+			return 0, err
+		}
+		repair := fmt.Sprintf("rebuilt search indexes (%s)", strings.Join(issues, "; "))
+		report.Repairs = append(report.Repairs, repair)
+		d.doctorLine("Indexes", repair, colorGreen)
+		return 0, nil
+	default:
+		d.doctorLine("Indexes", strings.Join(issues, "; "), colorYellow)
+		return len(issues), nil
+	}
+}
+
+// checkOrphans counts rows left behind by deleted links, deleting them
+// and any saved files no longer referenced with fix, and returns the
+// number of problems left.
+func (d *DBCommands) checkOrphans(ctx context.Context, report *doctorReport, fix bool) (int, error) {
+	orphans, err := d.db.Orphans(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if orphans != nil {
+		report.Orphans = orphans
+	}
+	total := 0
+	parts := make([]string, len(orphans))
+	for i, o := range orphans {
+		total += o.Count
+		parts[i] = fmt.Sprintf("%d in %s", o.Count, o.Name)
+	}
+	switch {
+	case total == 0:
+		d.doctorLine("Orphans", "none", "")
+	case fix:
+		if err := d.backUp(ctx, report); err != nil {
+			return 0, err
+		}
+		if _, err := d.db.DeleteOrphans(ctx); err != nil {
+			return 0, err
+		}
+		repair := fmt.Sprintf("deleted %s (%s)", plural(total, "orphaned row"), strings.Join(parts, ", "))
+		report.Repairs = append(report.Repairs, repair)
+		d.doctorLine("Orphans", repair, colorGreen)
+	default:
+		d.doctorLine("Orphans", fmt.Sprintf("%s (%s)", plural(total, "row"), strings.Join(parts, ", ")), colorYellow)
+		return 1, nil
+	}
+
+	if fix && d.attachments != nil {
+		attachments, err := d.db.Attachments(ctx)
+		if err != nil {
+			return 0, err
+		}
+		keep := make(map[string]bool, len(attachments))
+		for _, a := range attachments {
+			keep[a.Path] = true
+		}
+		removed, err := d.attachments.Prune(keep)
+		if err != nil {
+			return 0, err
+		}
+		if removed > 0 {
+			repair := fmt.Sprintf("deleted %s no link refers to", plural(removed, "saved file"))
+			report.Repairs = append(report.Repairs, repair)
+			d.doctorLine("Files", repair, colorGreen)
+		}
+	}
+	return 0, nil
+}
+
+// doctorLine prints one line of Doctor's report, unless printing JSON.
+func (d *DBCommands) doctorLine(check, result, color string) {
+	if d.jsonOutput {
+		return
+	}
+	if color == "" {
+		fmt.Printf("%-11s %s\n", check, result)
+		return
+	}
+	fmt.Printf("%-11s %s%s%s\n", check, color, result, colorReset)
+}
+
+func printDBSize(size *storage.DBSize) {
+	fmt.Printf("%-11s %s", "Size", formatSize(size.Bytes))
+	if size.WAL > 0 {
+		fmt.Printf(" (+%s write-ahead log)", formatSize(size.WAL))
+	}
+	fmt.Println()
+	for _, rows := range size.Rows {
+		fmt.Printf("  %s%-14s%s %d\n", colorDim, rows.Name, colorReset, rows.Count)
+	}
+}
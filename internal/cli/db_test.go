@@ -0,0 +1,58 @@
+package cli
+
+import (
+	"database/sql"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bunchhieng/rl/internal/backup"
+	"github.com/bunchhieng/rl/internal/storage"
+)
+
+func TestDoctorBacksUpBeforeFixing(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "links.db")
+	s, err := storage.NewSQLiteStorage(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	store := backup.NewStore(filepath.Join(dir, "backups"), 0)
+	d := NewDBCommands(s)
+	d.SetBackups(store)
+	SetColor(false)
+
+	// Nothing to repair, nothing backed up
+	if _, err := captureStdout(t, func() error { return d.Doctor(true) }); err != nil {
+		t.Fatalf("Doctor failed: %v", err)
+	}
+	if snaps, _ := store.List(); len(snaps) != 0 {
+		t.Fatalf("Expected no backup of a sound database, got %d", len(snaps))
+	}
+
+	// An orphaned row left by a bug or a manual edit
+	raw, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer raw.Close()
+	if _, err := raw.Exec("INSERT INTO link_content (link_id, text) VALUES ('gone', 'text')"); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := captureStdout(t, func() error { return d.Doctor(true) })
+	if err != nil {
+		t.Fatalf("Doctor failed: %v", err)
+	}
+	snaps, err := store.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snaps) != 1 || snaps[0].Reason != backup.ReasonDoctor {
+		t.Fatalf("Expected one doctor backup, got %+v", snaps)
+	}
+	if backupAt, fixAt := strings.Index(out, "Backup"), strings.Index(out, "deleted 1 orphaned row"); backupAt < 0 || fixAt < backupAt {
+		t.Errorf("Expected the backup reported before the repair, got:\n%s", out)
+	}
+}
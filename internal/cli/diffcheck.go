@@ -0,0 +1,204 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/bunchhieng/rl/internal/change"
+	"github.com/bunchhieng/rl/internal/fetch"
+	"github.com/bunchhieng/rl/internal/model"
+	"github.com/bunchhieng/rl/internal/storage"
+)
+
+// DefaultDiffThreshold is the share of a page's text that must differ
+// from the saved copy for rl diffcheck to flag it as changed.
+const DefaultDiffThreshold = 0.3
+
+// vanishedRatio is the share of its words a page may shrink to before its
+// content counts as gone, e.g. an article replaced by a stub or login wall.
+const vanishedRatio = 0.2
+
+// Page states reported by DiffCheck.
+const (
+	pageUnchanged = "unchanged"
+	pageChanged   = "changed"
+	pageGone      = "gone"
+)
+
+// diffResult is what DiffCheck found for one link.
+type diffResult struct {
+	Link   *model.Link `json:"link"`
+	Status string      `json:"status"`
+	// Change is the share of the text that differs, from 0 to 1.
+	Change float64 `json:"change"`
+	// Reason explains a gone page: the HTTP status, or that its text
+	// disappeared.
+	Reason string `json:"reason,omitempty"`
+}
+
+// DiffCheck fetches the links with archived text (all of them, or just
+// ids) and flags pages whose text now differs from the saved copy by at
+// least threshold, or that are gone. With update, the current text of
+// changed pages replaces the saved copy.
+func (c *Commands) DiffCheck(threshold float64, update bool, ids ...string) error {
+	links, err := c.diffCandidates(ids)
+	if err != nil {
+		return err
+	}
+	if len(links) == 0 {
+		if c.jsonOutput {
+			return printJSON([]diffResult{})
+		}
+		fmt.Println("No saved pages to check. Pages are saved when links are added or fetched.")
+		return nil
+	}
+
+	requests := make([]fetch.Request, len(links))
+	for i, link := range links {
+		requests[i].URL = link.URL
+		if state, err := c.storage.FetchState(c.ctx, link.ID); err == nil {
+			requests[i].Validators = fetch.Validators{ETag: state.ETag, LastModified: state.LastModified}
+		}
+	}
+
+	// Indexed by link so the JSON output doesn't depend on fetch timing
+	checked := make([]*diffResult, len(links))
+	var failed []fetchFailure
+	progress := newProgressBar(len(links))
+	c.fetcher.FetchAll(c.ctx, requests, fetch.PoolOptions{}, func(r fetch.Result) {
+		defer progress.step()
+		link := links[r.Index]
+		result, err := c.compareFetched(link, r, threshold)
+		if err == nil && update && result.Status == pageChanged {
+			err = c.storage.SetContent(c.ctx, link.ID, r.Meta.Text)
+			if err == nil {
+				err = c.recordFetch(link.ID, r.Meta.Validators)
+			}
+		}
+		if err != nil {
+			failed = append(failed, fetchFailure{link, err})
+			return
+		}
+		checked[r.Index] = &result
+		if c.jsonOutput || result.Status == pageUnchanged {
+			return
+		}
+		progress.clear()
+		printDiffResult(result)
+	})
+	progress.clear()
+
+	results := make([]diffResult, 0, len(links))
+	for _, r := range checked {
+		if r != nil {
+			results = append(results, *r)
+		}
+	}
+	for _, f := range failed {
+		fmt.Fprintf(os.Stderr, "%sFailed%s %s: %v\n", colorRed, colorReset, f.link.ID, f.err)
+	}
+	if c.jsonOutput {
+		if err := printJSON(results); err != nil {
+			return err
+		}
+	} else {
+		counts := map[string]int{}
+		for _, r := range results {
+			counts[r.Status]++
+		}
+		fmt.Printf("%sChecked%s %s: %d changed, %d gone, %d unchanged.\n", colorBold, colorReset,
+			plural(len(links), "page"), counts[pageChanged], counts[pageGone], counts[pageUnchanged])
+		if update && counts[pageChanged] > 0 {
+			fmt.Printf("Saved the current text of %s.\n", plural(counts[pageChanged], "changed page"))
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("%d page(s) could not be checked", len(failed))
+	}
+	return nil
+}
+
+// diffCandidates returns the links named by ids, or every link with
+// archived text.
+func (c *Commands) diffCandidates(ids []string) ([]*model.Link, error) {
+	ctx := c.ctx
+	if len(ids) > 0 {
+		links := make([]*model.Link, 0, len(ids))
+		for _, id := range ids {
+			resolved, err := c.resolveID(id)
+			if err != nil {
+				return nil, err
+			}
+			link, err := c.storage.Get(ctx, resolved)
+			if err != nil {
+				return nil, c.handleNotFound(err, resolved, "get link")
+			}
+			if _, err := c.storage.ContentHash(ctx, link.ID); err == model.ErrNotFound {
+				return nil, fmt.Errorf("no saved text for %s to compare with (fetch it first with `rl fetch %s`)", link.ID, link.ID)
+			}
+			links = append(links, link)
+		}
+		return links, nil
+	}
+
+	all, err := c.storage.List(ctx, storage.ListOptions{ReadStatus: storage.ReadStatusAll, Sort: storage.SortOldest})
+	if err != nil {
+		return nil, fmt.Errorf("list links: %w", err)
+	}
+	var links []*model.Link
+	for _, link := range all {
+		if _, err := c.storage.ContentHash(ctx, link.ID); err == nil {
+			links = append(links, link)
+		}
+	}
+	return links, nil
+}
+
+// compareFetched compares a fetched page with the saved copy of its text.
+func (c *Commands) compareFetched(link *model.Link, r fetch.Result, threshold float64) (diffResult, error) {
+	result := diffResult{Link: link, Status: pageUnchanged}
+	var statusErr *fetch.StatusError
+	switch {
+	case errors.As(r.Err, &statusErr) && (statusErr.Code == 404 || statusErr.Code == 410):
+		result.Status, result.Change, result.Reason = pageGone, 1, statusErr.Status
+		return result, nil
+	case r.Err != nil:
+		return result, r.Err
+	case r.Meta.NotModified:
+		return result, nil
+	}
+
+	ctx := c.ctx
+	hash, err := c.storage.ContentHash(ctx, link.ID)
+	if err != nil {
+		return result, err
+	}
+	if model.ContentHash(r.Meta.Text) == hash {
+		return result, nil
+	}
+	saved, err := c.storage.Content(ctx, link.ID)
+	if err != nil {
+		return result, err
+	}
+
+	result.Change = change.Measure(saved, r.Meta.Text)
+	if before := change.Words(saved); before > 0 && float64(change.Words(r.Meta.Text)) < vanishedRatio*float64(before) {
+		result.Status, result.Reason = pageGone, "content disappeared"
+	} else if result.Change >= threshold {
+		result.Status = pageChanged
+	}
+	return result, nil
+}
+
+func printDiffResult(r diffResult) {
+	switch r.Status {
+	case pageChanged:
+		fmt.Printf("%sChanged%s %s%s%s: %s (%.0f%% different)\n", colorYellow, colorReset, colorBold, r.Link.ID, colorReset,
+			displayTitle(r.Link), r.Change*100)
+	case pageGone:
+		fmt.Printf("%sGone%s    %s%s%s: %s (%s)\n", colorRed, colorReset, colorBold, r.Link.ID, colorReset,
+			displayTitle(r.Link), strings.ToLower(r.Reason))
+	}
+}
@@ -0,0 +1,96 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/bunchhieng/rl/internal/crypt"
+	"github.com/charmbracelet/x/term"
+)
+
+// passphraseEnv names the variable a passphrase is read from before
+// prompting, for scripts and scheduled backups.
+const passphraseEnv = "RL_PASSPHRASE"
+
+// Encryption says how an export is encrypted, or an import decrypted.
+type Encryption struct {
+	// Encrypt encrypts the export with a passphrase, or with KeyFile if set.
+	Encrypt bool
+	// KeyFile is a file of at least 32 random bytes to use as the key
+	// instead of a passphrase. Setting it implies Encrypt.
+	KeyFile string
+}
+
+// secret returns what to encrypt or decrypt with: the key file if one was
+// given, otherwise a passphrase from $RL_PASSPHRASE or the terminal. When
+// encrypting, a typed passphrase is asked for twice.
+func (e Encryption) secret(encrypting bool) (crypt.Secret, error) {
+	if e.KeyFile != "" {
+		key, err := os.ReadFile(e.KeyFile)
+		if err != nil {
+			return crypt.Secret{}, fmt.Errorf("read key file: %w", err)
+		}
+		return crypt.Secret{Key: key}, nil
+	}
+	if p := os.Getenv(passphraseEnv); p != "" {
+		return crypt.Secret{Passphrase: p}, nil
+	}
+	if !term.IsTerminal(os.Stdin.Fd()) {
+		return crypt.Secret{}, fmt.Errorf("no passphrase: set $%s or use --key-file", passphraseEnv)
+	}
+
+	passphrase, err := readPassphrase("Passphrase: ")
+	if err != nil {
+		return crypt.Secret{}, err
+	}
+	if encrypting {
+		again, err := readPassphrase("Passphrase again: ")
+		if err != nil {
+			return crypt.Secret{}, err
+		}
+		if again != passphrase {
+			return crypt.Secret{}, errors.New("passphrases don't match")
+		}
+	}
+	return crypt.Secret{Passphrase: passphrase}, nil
+}
+
+// readPassphrase prompts on stderr, since stdout may be the export, and
+// reads a line from the terminal without echoing it.
+func readPassphrase(prompt string) (string, error) {
+	fmt.Fprint(os.Stderr, prompt)
+	passphrase, err := term.ReadPassword(os.Stdin.Fd())
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", fmt.Errorf("read passphrase: %w", err)
+	}
+	return string(passphrase), nil
+}
+
+// decrypted returns r, decrypted first if it holds an encrypted export.
+func (e Encryption) decrypted(r io.Reader) (io.Reader, error) {
+	buffered := bufio.NewReader(r)
+	if head, _ := buffered.Peek(len(crypt.Magic)); !crypt.IsEncrypted(head) {
+		return buffered, nil
+	}
+	data, err := io.ReadAll(buffered)
+	if err != nil {
+		return nil, fmt.Errorf("read file: %w", err)
+	}
+	if crypt.NeedsKeyFile(data) && e.KeyFile == "" {
+		return nil, errors.New("file was encrypted with a key file: pass it with --key-file")
+	}
+	secret, err := e.secret(false)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := crypt.Decrypt(data, secret)
+	if err != nil {
+		return nil, fmt.Errorf("decrypt: %w", err)
+	}
+	return bytes.NewReader(plaintext), nil
+}
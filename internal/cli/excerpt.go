@@ -0,0 +1,99 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/bunchhieng/rl/internal/model"
+)
+
+// excerptKind describes annotations or quotes to the commands that add,
+// list, and remove them.
+type excerptKind struct {
+	// noun is what the commands call one, e.g. "note".
+	noun string
+	// added reports one saved, e.g. "Annotated".
+	added string
+}
+
+var (
+	noteKind  = excerptKind{noun: "note", added: "Annotated"}
+	quoteKind = excerptKind{noun: "quote", added: "Quoted"}
+)
+
+// excerpt is an annotation or a quote.
+type excerpt interface {
+	*model.Annotation | *model.Quote
+}
+
+// addExcerpt saves text with the link id using add.
+func addExcerpt[E excerpt](c *Commands, k excerptKind, id, text string, add func(context.Context, string, string) (E, error)) error {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return fmt.Errorf("%s text is empty", k.noun)
+	}
+	id, err := c.resolveID(id)
+	if err != nil {
+		return err
+	}
+	e, err := add(c.ctx, id, text)
+	if err != nil {
+		return c.handleNotFound(err, id, "add "+k.noun)
+	}
+	if c.jsonOutput {
+		return printJSON(e)
+	}
+	fmt.Printf("%s%s%s link %s%s%s (%s %d).\n", colorGreen, k.added, colorReset, colorBold, id, colorReset,
+		k.noun, (*model.Excerpt)(e).ID)
+	return nil
+}
+
+// listExcerpts prints the excerpts list returns for the link id, oldest
+// first, with the numbers removeExcerpt takes.
+func listExcerpts[E excerpt](c *Commands, k excerptKind, id string, list func(context.Context, string) ([]E, error)) error {
+	id, err := c.resolveID(id)
+	if err != nil {
+		return err
+	}
+	excerpts, err := list(c.ctx, id)
+	if err != nil {
+		return err
+	}
+	if c.jsonOutput {
+		if excerpts == nil {
+			excerpts = []E{}
+		}
+		return printJSON(excerpts)
+	}
+	if len(excerpts) == 0 {
+		fmt.Printf("No %ss.\n", k.noun)
+		return nil
+	}
+	printExcerpts(excerpts, true)
+	return nil
+}
+
+// removeExcerpt deletes an excerpt by its number using remove.
+func removeExcerpt(c *Commands, k excerptKind, number int64, remove func(context.Context, int64) error) error {
+	if err := remove(c.ctx, number); err != nil {
+		if err == model.ErrNotFound {
+			return fmt.Errorf("%s %s%d%s not found", k.noun, colorBold, number, colorReset)
+		}
+		return err
+	}
+	fmt.Printf("%sRemoved%s %s %s%d%s.\n", colorRed, colorReset, k.noun, colorBold, number, colorReset)
+	return nil
+}
+
+// printExcerpts prints one dated line per excerpt, with its number when
+// withIDs is set.
+func printExcerpts[E excerpt](excerpts []E, withIDs bool) {
+	for _, e := range excerpts {
+		e := (*model.Excerpt)(e)
+		if withIDs {
+			fmt.Printf("%s%3d%s  ", colorBold, e.ID, colorReset)
+		}
+		fmt.Printf("%s%s%s  %s\n", colorDim, formatTime(e.CreatedAt), colorReset, strings.ReplaceAll(e.Text, "\n", " "))
+	}
+}
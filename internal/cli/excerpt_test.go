@@ -0,0 +1,46 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bunchhieng/rl/internal/model"
+)
+
+func TestExcerptCommands(t *testing.T) {
+	c, s := testCommands(t)
+	link := addLink(t, s, &model.Link{URL: "https://example.com"})
+	kinds := []struct {
+		noun        string
+		add         func(id, text string) error
+		list        func(id string) error
+		remove      func(n int64) error
+		addedOutput string
+	}{
+		{"note", c.NoteAdd, c.Notes, c.NoteRemove, "Annotated link"},
+		{"quote", c.Quote, c.Quotes, c.Unquote, "Quoted link"},
+	}
+	for _, k := range kinds {
+		if err := k.add(link.ID, "  "); err == nil || !strings.Contains(err.Error(), k.noun+" text is empty") {
+			t.Errorf("%s: expected empty text refused, got %v", k.noun, err)
+		}
+		out, err := captureStdout(t, func() error { return k.add(link.ID, "first\nline") })
+		if err != nil || !strings.Contains(out, k.addedOutput) || !strings.Contains(out, "("+k.noun+" 1)") {
+			t.Errorf("%s: unexpected add output %q (%v)", k.noun, out, err)
+		}
+		out, err = captureStdout(t, func() error { return k.list(link.ID) })
+		if err != nil || !strings.Contains(out, "first line") {
+			t.Errorf("%s: expected the excerpt listed on one line, got %q (%v)", k.noun, out, err)
+		}
+		if _, err := captureStdout(t, func() error { return k.remove(1) }); err != nil {
+			t.Errorf("%s: remove failed: %v", k.noun, err)
+		}
+		if err := k.remove(1); err == nil || !strings.Contains(err.Error(), k.noun+" 1 not found") {
+			t.Errorf("%s: expected removing twice to fail, got %v", k.noun, err)
+		}
+		out, _ = captureStdout(t, func() error { return k.list(link.ID) })
+		if out != "No "+k.noun+"s.\n" {
+			t.Errorf("%s: expected none left, got %q", k.noun, out)
+		}
+	}
+}
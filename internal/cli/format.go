@@ -0,0 +1,48 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/template"
+
+	"github.com/bunchhieng/rl/internal/model"
+)
+
+// formatEscapes turns the escapes people type in a shell-quoted format
+// into the characters they mean.
+var formatEscapes = strings.NewReplacer(`\t`, "\t", `\n`, "\n", `\\`, `\`)
+
+// ParseFormat parses a Go template applied to each link by `rl ls
+// --format`, e.g. '{{.ID}}\t{{.URL}}'. Unknown fields are reported here
+// rather than partway through the output.
+func ParseFormat(s string) (*template.Template, error) {
+	tmpl, err := template.New("format").Option("missingkey=error").Parse(formatEscapes.Replace(s))
+	if err != nil {
+		return nil, fmt.Errorf("invalid format: %w", err)
+	}
+	if err := tmpl.Execute(io.Discard, &model.Link{}); err != nil {
+		return nil, fmt.Errorf("invalid format: %w", err)
+	}
+	return tmpl, nil
+}
+
+// printTemplate prints each link through tmpl, one per line.
+func printTemplate(links []*model.Link, tmpl *template.Template) error {
+	var b strings.Builder
+	for _, link := range links {
+		b.Reset()
+		if err := tmpl.Execute(&b, link); err != nil {
+			return fmt.Errorf("format link %s: %w", link.ID, err)
+		}
+		line := b.String()
+		if !strings.HasSuffix(line, "\n") {
+			line += "\n"
+		}
+		if _, err := io.WriteString(os.Stdout, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bunchhieng/rl/internal/model"
+)
+
+func TestParseFormat(t *testing.T) {
+	link := &model.Link{ID: "abc123", URL: "https://example.com", Title: "Example", Tags: "go,web"}
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{`{{.ID}}\t{{.URL}}`, "abc123\thttps://example.com"},
+		{`{{.Title}}\n{{.Tags}}`, "Example\ngo,web"},
+		{`C:\\{{.ID}}`, `C:\abc123`},
+		{`{{.Title | printf "%q"}}`, `"Example"`},
+		{`{{if .IsRead}}read{{else}}unread{{end}}`, "unread"},
+	}
+	for _, tt := range tests {
+		tmpl, err := ParseFormat(tt.format)
+		if err != nil {
+			t.Errorf("ParseFormat(%q) failed: %v", tt.format, err)
+			continue
+		}
+		var b strings.Builder
+		if err := tmpl.Execute(&b, link); err != nil || b.String() != tt.want {
+			t.Errorf("ParseFormat(%q) gave %q, %v; want %q", tt.format, b.String(), err, tt.want)
+		}
+	}
+
+	for _, format := range []string{`{{.Nope}}`, `{{.ID`, `{{range}}`, `{{.Title.Missing}}`} {
+		if _, err := ParseFormat(format); err == nil || !strings.HasPrefix(err.Error(), "invalid format") {
+			t.Errorf("ParseFormat(%q) = %v, want an invalid format error", format, err)
+		}
+	}
+}
+
+func TestPrintTemplate(t *testing.T) {
+	links := []*model.Link{
+		{ID: "aaa111", URL: "https://a.example", Title: "A"},
+		{ID: "bbb222", URL: "https://b.example"},
+	}
+	tmpl, err := ParseFormat(`{{.ID}} {{or .Title .URL}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := captureStdout(t, func() error { return printTemplate(links, tmpl) })
+	if err != nil {
+		t.Fatalf("printTemplate failed: %v", err)
+	}
+	if want := "aaa111 A\nbbb222 https://b.example\n"; out != want {
+		t.Errorf("printTemplate printed %q, want %q", out, want)
+	}
+
+	// A format ending in a newline doesn't get a second one
+	tmpl, _ = ParseFormat(`{{.ID}}\n`)
+	if out, _ := captureStdout(t, func() error { return printTemplate(links[:1], tmpl) }); out != "aaa111\n" {
+		t.Errorf("Expected one newline per link, got %q", out)
+	}
+
+	// A bad field reached only for some links fails while printing, naming
+	// the link
+	tmpl, _ = ParseFormat(`{{if .Title}}{{.Title.Words}}{{end}}`)
+	if _, err := captureStdout(t, func() error { return printTemplate(links, tmpl) }); err == nil || !strings.Contains(err.Error(), "aaa111") {
+		t.Errorf("Expected an error naming the link, got %v", err)
+	}
+}
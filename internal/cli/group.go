@@ -0,0 +1,141 @@
+package cli
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bunchhieng/rl/internal/model"
+)
+
+// GroupBy selects how `rl ls --group-by` splits links into sections.
+type GroupBy string
+
+const (
+	GroupNone   GroupBy = ""
+	GroupTag    GroupBy = "tag"
+	GroupDomain GroupBy = "domain"
+	GroupWeek   GroupBy = "week"
+)
+
+// ParseGroupBy parses a grouping name as accepted by `rl ls --group-by`.
+func ParseGroupBy(s string) (GroupBy, error) {
+	switch g := GroupBy(strings.ToLower(s)); g {
+	case GroupNone, GroupTag, GroupDomain, GroupWeek:
+		return g, nil
+	}
+	return GroupNone, fmt.Errorf("invalid grouping %q (use tag, domain, or week)", s)
+}
+
+// linkGroup is one section of a grouped listing.
+type linkGroup struct {
+	Name  string        `json:"group"`
+	Count int           `json:"count"`
+	Links []*model.Link `json:"links"`
+}
+
+// groupLinks splits links into groups, keeping their order within each.
+// Tag and domain groups are largest first, with untagged links and links
+// without a domain last; week groups (by the Monday of the week a link was
+// saved) follow the order of the links. A link with several tags appears
+// under each.
+func groupLinks(links []*model.Link, by GroupBy) []linkGroup {
+	var names []string
+	members := make(map[string][]*model.Link)
+	add := func(name string, link *model.Link) {
+		if _, ok := members[name]; !ok {
+			names = append(names, name)
+		}
+		members[name] = append(members[name], link)
+	}
+
+	var fallback string
+	for _, link := range links {
+		switch by {
+		case GroupTag:
+			fallback = "(untagged)"
+			tags := link.TagList()
+			if len(tags) == 0 {
+				add(fallback, link)
+			}
+			for _, tag := range tags {
+				add(tag, link)
+			}
+		case GroupDomain:
+			fallback = "(no domain)"
+			if link.Domain == "" {
+				add(fallback, link)
+			} else {
+				add(link.Domain, link)
+			}
+		case GroupWeek:
+			add(weekOf(link.CreatedAt), link)
+		}
+	}
+
+	if by != GroupWeek {
+		sort.SliceStable(names, func(i, j int) bool {
+			a, b := names[i], names[j]
+			if (a == fallback) != (b == fallback) {
+				return b == fallback
+			}
+			if len(members[a]) != len(members[b]) {
+				return len(members[a]) > len(members[b])
+			}
+			return a < b
+		})
+	}
+
+	groups := make([]linkGroup, len(names))
+	for i, name := range names {
+		groups[i] = linkGroup{Name: name, Count: len(members[name]), Links: members[name]}
+	}
+	return groups
+}
+
+// weekOf names the week containing t, e.g. "Week of 2024-01-29", by its
+// Monday in the display time zone.
+func weekOf(t time.Time) string {
+	t = t.In(displayLocation)
+	monday := t.AddDate(0, 0, -((int(t.Weekday()) + 6) % 7))
+	return "Week of " + monday.Format(time.DateOnly)
+}
+
+// printGroups prints each group under a header with its count, numbering
+// rows across all groups so %N refers to the Nth row shown.
+func (c *Commands) printGroups(groups []linkGroup, opts DisplayOptions) error {
+	var ids []string
+	for _, g := range groups {
+		ids = append(ids, listingIDs(g.Links)...)
+	}
+	if err := c.saveListing(ids); err != nil {
+		printListingWarning(err)
+	}
+	if c.jsonOutput {
+		if groups == nil {
+			groups = []linkGroup{}
+		}
+		return printJSON(groups)
+	}
+
+	opts.ShowIndex = true
+	opts.Plain = c.plain
+	for i, g := range groups {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("%s%s%s %s(%d)%s\n", colorBold, g.Name, colorReset, colorDim, g.Count, colorReset)
+		if opts.Template != nil {
+			if err := printTemplate(g.Links, opts.Template); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := printLinksTable(g.Links, opts); err != nil {
+			return err
+		}
+		opts.firstIndex += len(g.Links)
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package cli
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/bunchhieng/rl/internal/model"
+)
+
+func TestGroupLinks(t *testing.T) {
+	displayLocation = time.UTC
+	links := []*model.Link{
+		{ID: "a", Tags: "go,db", Domain: "go.dev", CreatedAt: time.Date(2024, 1, 31, 12, 0, 0, 0, time.UTC)},
+		{ID: "b", Domain: "lwn.net", CreatedAt: time.Date(2024, 1, 29, 0, 0, 0, 0, time.UTC)},
+		{ID: "c", Tags: "go", Domain: "go.dev", CreatedAt: time.Date(2024, 1, 28, 23, 0, 0, 0, time.UTC)},
+	}
+	summarize := func(groups []linkGroup) map[string][]string {
+		out := make(map[string][]string)
+		var order []string
+		for _, g := range groups {
+			order = append(order, g.Name)
+			out[g.Name] = listingIDs(g.Links)
+		}
+		out["order"] = order
+		return out
+	}
+
+	tests := []struct {
+		by   GroupBy
+		want map[string][]string
+	}{
+		{GroupTag, map[string][]string{
+			"order": {"go", "db", "(untagged)"}, "go": {"a", "c"}, "db": {"a"}, "(untagged)": {"b"},
+		}},
+		{GroupDomain, map[string][]string{
+			"order": {"go.dev", "lwn.net"}, "go.dev": {"a", "c"}, "lwn.net": {"b"},
+		}},
+		{GroupWeek, map[string][]string{
+			"order":              {"Week of 2024-01-29", "Week of 2024-01-22"},
+			"Week of 2024-01-29": {"a", "b"},
+			"Week of 2024-01-22": {"c"},
+		}},
+	}
+	for _, tt := range tests {
+		if got := summarize(groupLinks(links, tt.by)); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("groupLinks(%s) = %v, want %v", tt.by, got, tt.want)
+		}
+	}
+
+	if _, err := ParseGroupBy("month"); err == nil {
+		t.Error("Expected an error for an unknown grouping")
+	}
+}
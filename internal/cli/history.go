@@ -0,0 +1,71 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bunchhieng/rl/internal/model"
+)
+
+// maxHistoryValue is how much of a value History prints; --json shows it
+// all.
+const maxHistoryValue = 50
+
+// History prints the edits to a link's title, tags, and URL,
+// oldest first, numbered for Revert.
+func (c *Commands) History(id string) error {
+	id, err := c.resolveID(id)
+	if err != nil {
+		return err
+	}
+	if _, err := c.storage.Get(c.ctx, id); err != nil {
+		return c.handleNotFound(err, id, "get link")
+	}
+	edits, err := c.storage.History(c.ctx, id)
+	if err != nil {
+		return err
+	}
+	if c.jsonOutput {
+		if edits == nil {
+			edits = []*model.Edit{}
+		}
+		return printJSON(edits)
+	}
+	if len(edits) == 0 {
+		fmt.Println("No edits.")
+		return nil
+	}
+	for _, e := range edits {
+		fmt.Printf("%s%3d%s  %s%s%s  %-5s  %s %s→%s %s\n", colorBold, e.ID, colorReset,
+			colorDim, formatTime(e.ChangedAt), colorReset, e.Field,
+			historyValue(e.Old), colorDim, colorReset, historyValue(e.New))
+	}
+	fmt.Printf("\n%sUndo an edit with: rl history --revert <number> %s%s\n", colorDim, id, colorReset)
+	return nil
+}
+
+// historyValue quotes a value on one line, shortened to maxHistoryValue.
+func historyValue(v string) string {
+	if v == "" {
+		return "(empty)"
+	}
+	return fmt.Sprintf("%q", truncateString(strings.Join(strings.Fields(v), " "), maxHistoryValue))
+}
+
+// Revert sets the field the edit editID changed back to its value before
+// that edit.
+func (c *Commands) Revert(id string, editID int64) error {
+	id, err := c.resolveID(id)
+	if err != nil {
+		return err
+	}
+	link, err := c.storage.RevertEdit(c.ctx, id, editID)
+	if err != nil {
+		return c.handleNotFound(err, id, "revert edit")
+	}
+	if c.jsonOutput {
+		return printJSON(link)
+	}
+	fmt.Printf("%sReverted%s edit %d of link %s%s%s.\n", colorGreen, colorReset, editID, colorBold, id, colorReset)
+	return nil
+}
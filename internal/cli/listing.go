@@ -0,0 +1,115 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/bunchhieng/rl/internal/model"
+)
+
+// listingRefPrefix marks an argument as an index into the last listing ("%2").
+const listingRefPrefix = "%"
+
+// SetListingFile sets where the IDs of the last printed listing are cached so
+// later commands can refer to them by index.
+func (c *Commands) SetListingFile(path string) {
+	c.listingFile = path
+}
+
+// saveListing records the IDs of a printed listing in display order.
+func (c *Commands) saveListing(ids []string) error {
+	if c.listingFile == "" {
+		return nil
+	}
+	data, err := json.Marshal(ids)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.listingFile), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(c.listingFile, data, 0644)
+}
+
+// lookupListing returns the ID shown at a 1-based index of the last listing.
+func (c *Commands) lookupListing(ref string) (string, error) {
+	n, err := strconv.Atoi(strings.TrimPrefix(ref, listingRefPrefix))
+	if err != nil || n < 1 {
+		return "", fmt.Errorf("invalid listing index: %s", ref)
+	}
+	if c.listingFile == "" {
+		return "", fmt.Errorf("no previous listing; run rl ls first")
+	}
+	data, err := os.ReadFile(c.listingFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("no previous listing; run rl ls first")
+	}
+	if err != nil {
+		return "", fmt.Errorf("read last listing: %w", err)
+	}
+	var ids []string
+	if err := json.Unmarshal(data, &ids); err != nil {
+		return "", fmt.Errorf("read last listing: %w", err)
+	}
+	if n > len(ids) {
+		return "", fmt.Errorf("index %s is out of range; the last listing had %d link(s)", ref, len(ids))
+	}
+	return ids[n-1], nil
+}
+
+// isListingRef reports whether s refers to an index of the last listing.
+func isListingRef(s string) bool {
+	if !strings.HasPrefix(s, listingRefPrefix) || len(s) == len(listingRefPrefix) {
+		return false
+	}
+	for _, r := range s[len(listingRefPrefix):] {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// printListing prints links as a numbered table (or JSON) and remembers
+// their order.
+func (c *Commands) printListing(links []*model.Link, opts DisplayOptions) error {
+	if err := c.saveListing(listingIDs(links)); err != nil {
+		printListingWarning(err)
+	}
+	if c.jsonOutput {
+		if links == nil {
+			links = []*model.Link{}
+		}
+		return printJSON(links)
+	}
+	if opts.TSV {
+		printTSV(links)
+		return nil
+	}
+	if opts.Template != nil {
+		return printTemplate(links, opts.Template)
+	}
+	opts.ShowIndex = true
+	opts.Plain = c.plain
+	return printLinksTable(links, opts)
+}
+
+// listingIDs returns the IDs of links, in order.
+func listingIDs(links []*model.Link) []string {
+	ids := make([]string, len(links))
+	for i, link := range links {
+		ids[i] = link.ID
+	}
+	return ids
+}
+
+// printListingWarning reports a listing that couldn't be saved; the
+// command itself still succeeds.
+func printListingWarning(err error) {
+	fmt.Fprintf(os.Stderr, "%sWarning:%s failed to save listing: %v\n", colorYellow, colorReset, err)
+}
@@ -0,0 +1,67 @@
+package cli
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bunchhieng/rl/internal/model"
+	"github.com/bunchhieng/rl/internal/storage"
+)
+
+func TestListingRefs(t *testing.T) {
+	c, s := testCommands(t)
+	first := addLink(t, s, &model.Link{URL: "https://example.com/a", CreatedAt: daysAgo(2)})
+	second := addLink(t, s, &model.Link{URL: "https://example.com/b", CreatedAt: daysAgo(1)})
+
+	// Before any listing, a reference has nothing to point at
+	if _, err := captureStdout(t, func() error { return c.Done("%1") }); err == nil || !strings.Contains(err.Error(), "run rl ls first") {
+		t.Errorf("Done(%%1) without a listing file = %v", err)
+	}
+	c.SetListingFile(filepath.Join(t.TempDir(), "cache", "listing.json"))
+	if _, err := captureStdout(t, func() error { return c.Done("%1") }); err == nil || !strings.Contains(err.Error(), "run rl ls first") {
+		t.Errorf("Done(%%1) before a listing = %v", err)
+	}
+
+	out, err := captureStdout(t, func() error {
+		return c.List(storage.ListOptions{Sort: storage.SortOldest}, DisplayOptions{})
+	})
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	// The rows are numbered for the references
+	if i, j := strings.Index(out, first.ID), strings.Index(out, second.ID); i < 0 || j < i {
+		t.Fatalf("listing doesn't show the links oldest first:\n%s", out)
+	}
+
+	if _, err := captureStdout(t, func() error { return c.Done("%2") }); err != nil {
+		t.Fatalf("Done(%%2) failed: %v", err)
+	}
+	if link, _ := s.Get(c.ctx, second.ID); !link.IsRead() {
+		t.Error("Done(%2) didn't mark the second row read")
+	}
+	if link, _ := s.Get(c.ctx, first.ID); link.IsRead() {
+		t.Error("Done(%2) marked the first row read")
+	}
+
+	// References keep pointing at the listing as printed
+	if _, err := captureStdout(t, func() error { return c.Undo("%2") }); err != nil {
+		t.Errorf("Undo(%%2) failed: %v", err)
+	}
+	if _, err := captureStdout(t, func() error { return c.Done("%3") }); err == nil || !strings.Contains(err.Error(), "the last listing had 2 link(s)") {
+		t.Errorf("Done(%%3) = %v, want an out of range error", err)
+	}
+}
+
+func TestIsListingRef(t *testing.T) {
+	for s, want := range map[string]bool{
+		"%1": true, "%12": true, "%": false, "%x": false, "%1a": false, "1": false, "abc": false,
+	} {
+		if got := isListingRef(s); got != want {
+			t.Errorf("isListingRef(%q) = %v, want %v", s, got, want)
+		}
+	}
+	if _, err := ParseID("%2"); err != nil {
+		t.Errorf("ParseID(%%2) = %v, want it accepted", err)
+	}
+}
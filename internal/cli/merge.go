@@ -0,0 +1,39 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Merge folds the duplicate links dupIDs into keepID: tags are combined,
+// notes concatenated, the earliest save time and any read time kept, and
+// the duplicates deleted, all at once.
+func (c *Commands) Merge(keepID string, dupIDs ...string) error {
+	if len(dupIDs) == 0 {
+		return fmt.Errorf("at least one duplicate ID required")
+	}
+	keepID, err := c.resolveID(keepID)
+	if err != nil {
+		return err
+	}
+	resolved := make([]string, len(dupIDs))
+	for i, id := range dupIDs {
+		if resolved[i], err = c.resolveID(id); err != nil {
+			return err
+		}
+	}
+
+	merged, err := c.storage.Merge(c.ctx, keepID, resolved)
+	if err != nil {
+		return c.handleNotFound(err, keepID, "merge links")
+	}
+	if err := c.pruneAttachments(); err != nil {
+		fmt.Fprintf(os.Stderr, "%sWarning:%s could not remove unused attachments: %v\n", colorYellow, colorReset, err)
+	}
+	if c.jsonOutput {
+		return printJSON(merged)
+	}
+	fmt.Printf("%sMerged%s %s into %s%s%s.\n", colorGreen, colorReset, strings.Join(resolved, ", "), colorBold, merged.ID, colorReset)
+	return nil
+}
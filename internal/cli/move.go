@@ -0,0 +1,49 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/bunchhieng/rl/internal/model"
+)
+
+// Move changes the URL of the link id, keeping its title, tags, notes,
+// and history. With canonicalize, the URL is canonicalized as rl add
+// does.
+func (c *Commands) Move(id, url string, canonicalize bool) error {
+	id, err := c.resolveID(id)
+	if err != nil {
+		return err
+	}
+	if canonicalize {
+		canonical, err := model.CanonicalizeURL(url)
+		if err != nil {
+			return err
+		}
+		url = canonical
+	}
+
+	old, err := c.storage.Get(c.ctx, id)
+	if err != nil {
+		return c.handleNotFound(err, id, "get link")
+	}
+	link, err := c.storage.SetURL(c.ctx, id, url)
+	if errors.Is(err, model.ErrDuplicate) {
+		if other, findErr := c.storage.FindByURL(c.ctx, url); findErr == nil {
+			return fmt.Errorf("%v\n\n%sTo combine the two:%s rl merge %s %s", err, colorYellow, colorReset, other.ID, id)
+		}
+		return err
+	}
+	if err != nil {
+		return c.handleNotFound(err, id, "change URL")
+	}
+	if c.jsonOutput {
+		return printJSON(link)
+	}
+	if old.URL == link.URL {
+		fmt.Printf("Link %s%s%s already has that URL.\n", colorBold, id, colorReset)
+		return nil
+	}
+	fmt.Printf("%sMoved%s %s%s%s: %s → %s\n", colorGreen, colorReset, colorBold, id, colorReset, old.URL, link.URL)
+	return nil
+}
@@ -0,0 +1,88 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bunchhieng/rl/internal/model"
+	"github.com/bunchhieng/rl/internal/recommend"
+	"github.com/bunchhieng/rl/internal/storage"
+)
+
+// defaultNextLimit is how many links rl next suggests without a count.
+const defaultNextLimit = 5
+
+// Next suggests the n unread links most worth reading next, best first,
+// each followed by why. available is the reading time on hand; zero means
+// about as long as the links the user has read.
+func (c *Commands) Next(n int, available time.Duration) error {
+	links, err := c.storage.List(c.ctx, storage.ListOptions{ReadStatus: storage.ReadStatusAll})
+	if err != nil {
+		return fmt.Errorf("list links: %w", err)
+	}
+	if n <= 0 {
+		n = defaultNextLimit
+	}
+	typical := available == 0
+	if typical {
+		available = recommend.TypicalTime(links)
+	}
+	now := time.Now()
+	results := recommend.Rank(links, recommend.Options{Time: available, Limit: n}, now)
+
+	shortlist := make([]*model.Link, len(results))
+	for i, r := range results {
+		shortlist[i] = r.Link
+	}
+	if c.jsonOutput {
+		if err := c.saveListing(listingIDs(shortlist)); err != nil {
+			printListingWarning(err)
+		}
+		if results == nil {
+			results = []recommend.Result{}
+		}
+		return printJSON(results)
+	}
+	if len(results) == 0 {
+		fmt.Println("Nothing unread.")
+		return nil
+	}
+	if err := c.printListing(shortlist, DisplayOptions{}); err != nil {
+		return err
+	}
+
+	fmt.Println()
+	for i, r := range results {
+		fmt.Printf("%s%3d  %s%s\n", colorDim, i+1, nextReasons(r, available, typical, now), colorReset)
+	}
+	return nil
+}
+
+// nextReasons describes what recommends a link, e.g. "high priority ·
+// you've read 4 of 6 go links · fits in 15m", as of now.
+func nextReasons(r recommend.Result, available time.Duration, typical bool, now time.Time) string {
+	var parts []string
+	if r.Link.Priority > model.PriorityNormal {
+		parts = append(parts, "high priority")
+	}
+	if r.Tag != "" && r.TagRead > 0 {
+		parts = append(parts, fmt.Sprintf("you've read %d of %d %s links", r.TagRead, r.TagSaved, r.Tag))
+	}
+	switch {
+	case r.Fits && typical:
+		parts = append(parts, "fits the "+formatReadingTime(available)+" you usually read")
+	case r.Fits:
+		parts = append(parts, "fits in "+formatReadingTime(available))
+	}
+	if r.Unsnoozed {
+		parts = append(parts, "back from snooze")
+	}
+	if now.Sub(r.Link.CreatedAt) < 7*24*time.Hour {
+		parts = append(parts, "saved this week")
+	}
+	if len(parts) == 0 {
+		return "nothing stands out"
+	}
+	return strings.Join(parts, " · ")
+}
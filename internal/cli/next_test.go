@@ -0,0 +1,74 @@
+package cli
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bunchhieng/rl/internal/model"
+)
+
+func TestNext(t *testing.T) {
+	c, s := testCommands(t)
+	read := daysAgo(2)
+	for _, url := range []string{"https://example.com/a", "https://example.com/b"} {
+		addLink(t, s, &model.Link{URL: url, Tags: "go", ReadingSeconds: 600, CreatedAt: daysAgo(30), ReadAt: &read})
+	}
+	old := addLink(t, s, &model.Link{URL: "https://example.com/old", Title: "Old essay", ReadingSeconds: 3600, CreatedAt: daysAgo(60)})
+	best := addLink(t, s, &model.Link{URL: "https://example.com/go", Title: "Go tips", Tags: "go", Priority: model.PriorityHigh, ReadingSeconds: 300})
+
+	out, err := captureStdout(t, func() error { return c.Next(0, 0) })
+	if err != nil {
+		t.Fatalf("Next failed: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if !strings.Contains(out, "Go tips") || !strings.Contains(out, "Old essay") {
+		t.Errorf("Expected both unread links listed, got:\n%s", out)
+	}
+	want := "1  high priority · you've read 2 of 3 go links · fits the 10m you usually read · saved this week"
+	if reasons := lines[len(lines)-2]; !strings.Contains(reasons, want) {
+		t.Errorf("Expected the best link's reasons %q, got %q", want, reasons)
+	}
+	if reasons := lines[len(lines)-1]; !strings.HasSuffix(reasons, "2  nothing stands out") {
+		t.Errorf("Expected nothing to recommend the old link, got %q", reasons)
+	}
+
+	// The JSON output is the ranked results, best first
+	c.SetJSON(true)
+	defer c.SetJSON(false)
+	out, err = captureStdout(t, func() error { return c.Next(1, 5*time.Minute) })
+	if err != nil {
+		t.Fatalf("Next(--json) failed: %v", err)
+	}
+	var got []struct {
+		Link struct {
+			ID  string `json:"id"`
+			URL string `json:"url"`
+		} `json:"link"`
+		Score    float64 `json:"score"`
+		Tag      string  `json:"tag"`
+		TagRead  int     `json:"tag_read"`
+		TagSaved int     `json:"tag_saved"`
+		Fits     bool    `json:"fits"`
+	}
+	if err := json.Unmarshal([]byte(out), &got); err != nil {
+		t.Fatalf("Expected JSON output, got %q: %v", out, err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("Expected one result, got %d", len(got))
+	}
+	if r := got[0]; r.Link.ID != best.ID || r.Score <= 0 || r.Score > 1 || r.Tag != "go" || r.TagRead != 2 || r.TagSaved != 3 || !r.Fits {
+		t.Errorf("Expected the Go tips link with its tag and fit, got %+v", r)
+	}
+
+	// Nothing unread is an empty list rather than null
+	for _, link := range []*model.Link{best, old} {
+		if err := s.MarkRead(c.ctx, link.ID); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if out, err := captureStdout(t, func() error { return c.Next(0, 0) }); err != nil || strings.TrimSpace(out) != "[]" {
+		t.Errorf("Expected an empty JSON list, got %q, %v", out, err)
+	}
+}
@@ -0,0 +1,16 @@
+package cli
+
+// NoteAdd appends a dated annotation to a link.
+func (c *Commands) NoteAdd(id, text string) error {
+	return addExcerpt(c, noteKind, id, text, c.storage.AddAnnotation)
+}
+
+// Notes lists a link's annotations, oldest first.
+func (c *Commands) Notes(id string) error {
+	return listExcerpts(c, noteKind, id, c.storage.Annotations)
+}
+
+// NoteRemove deletes an annotation by its number.
+func (c *Commands) NoteRemove(annotationID int64) error {
+	return removeExcerpt(c, noteKind, annotationID, c.storage.DeleteAnnotation)
+}
@@ -0,0 +1,36 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/bunchhieng/rl/internal/browser"
+)
+
+// SetJSON switches commands that print records (ls, grep, show, stats, add)
+// to structured JSON output on stdout.
+func (c *Commands) SetJSON(enabled bool) {
+	c.jsonOutput = enabled
+}
+
+// printJSON writes v to stdout as indented JSON.
+func printJSON(v interface{}) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		return fmt.Errorf("encode JSON: %w", err)
+	}
+	return nil
+}
+
+// SetBrowser sets the command used to open links (see browser.New).
+func (c *Commands) SetBrowser(command string) {
+	c.browser = browser.New(command)
+}
+
+// SetPlain switches link tables to borderless, untruncated columns suited
+// to pipes.
+func (c *Commands) SetPlain(enabled bool) {
+	c.plain = enabled
+}
@@ -0,0 +1,42 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/mattn/go-isatty"
+)
+
+const progressWidth = 30
+
+// progressBar draws "[=====     ] 12/40" on stderr while a batch runs.
+// It stays silent for a single item or when stderr isn't a terminal.
+type progressBar struct {
+	total   int
+	done    int
+	enabled bool
+}
+
+func newProgressBar(total int) *progressBar {
+	fd := os.Stderr.Fd()
+	return &progressBar{total: total, enabled: total > 1 && (isatty.IsTerminal(fd) || isatty.IsCygwinTerminal(fd))}
+}
+
+// step counts one item done and redraws the bar.
+func (p *progressBar) step() {
+	p.done++
+	if !p.enabled {
+		return
+	}
+	filled := progressWidth * p.done / p.total
+	fmt.Fprintf(os.Stderr, "\r%s[%s%s]%s %d/%d", colorDim, strings.Repeat("=", filled),
+		strings.Repeat(" ", progressWidth-filled), colorReset, p.done, p.total)
+}
+
+// clear erases the bar so a line can be printed in its place.
+func (p *progressBar) clear() {
+	if p.enabled {
+		fmt.Fprint(os.Stderr, "\r\033[K")
+	}
+}
@@ -0,0 +1,30 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/skip2/go-qrcode"
+)
+
+// QR prints a link's URL as a QR code drawn with half-block characters, so
+// a phone camera can pick it up straight from the terminal.
+func (c *Commands) QR(id string) error {
+	id, err := c.resolveID(id)
+	if err != nil {
+		return err
+	}
+	link, err := c.storage.Get(c.ctx, id)
+	if err != nil {
+		return c.handleNotFound(err, id, "get link")
+	}
+
+	code, err := qrcode.New(link.URL, qrcode.Medium)
+	if err != nil {
+		return fmt.Errorf("encode QR code: %w", err)
+	}
+	// Dark modules print as blanks, which scans on the usual light-on-dark
+	// terminal; the bitmap already carries the quiet zone around the code.
+	fmt.Print(code.ToSmallString(false))
+	fmt.Println(link.URL)
+	return nil
+}
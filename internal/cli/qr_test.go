@@ -0,0 +1,37 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bunchhieng/rl/internal/model"
+	"github.com/skip2/go-qrcode"
+)
+
+func TestQR(t *testing.T) {
+	c, s := testCommands(t)
+	link := addLink(t, s, &model.Link{URL: "https://example.com/a"})
+
+	out, err := captureStdout(t, func() error { return c.QR(link.ID) })
+	if err != nil {
+		t.Fatalf("QR failed: %v", err)
+	}
+	code, err := qrcode.New(link.URL, qrcode.Medium)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := code.ToSmallString(false) + link.URL + "\n"; out != want {
+		t.Errorf("QR printed\n%s\nwant\n%s", out, want)
+	}
+	lines := strings.Split(strings.TrimSuffix(out, "\n"), "\n")
+	for _, line := range lines[:len(lines)-1] {
+		if strings.Trim(line, " ▀▄█") != "" {
+			t.Fatalf("QR line %q has characters other than half blocks", line)
+		}
+	}
+
+	_, err = captureStdout(t, func() error { return c.QR("zzzzzzzz") })
+	if err == nil || !strings.Contains(err.Error(), "zzzzzzzz not found") {
+		t.Errorf("QR of an unknown ID = %v, want a not found error", err)
+	}
+}
@@ -0,0 +1,26 @@
+package cli
+
+import (
+	"fmt"
+)
+
+// Reorder moves the unread link id offset places along the unread queue,
+// the order rl list shows, toward the top for negative offsets. The links
+// above it keep their places from then on, ahead of links saved later.
+func (c *Commands) Reorder(id string, offset int) error {
+	id, err := c.resolveID(id)
+	if err != nil {
+		return err
+	}
+	place, length, err := c.storage.Reorder(c.ctx, id, offset)
+	if err != nil {
+		return c.handleNotFound(err, id, "move link")
+	}
+	verb := "Bumped"
+	if offset > 0 {
+		verb = "Demoted"
+	}
+	fmt.Printf("%s%s%s link %s%s%s to %d of %d in the unread queue.\n",
+		colorGreen, verb, colorReset, colorBold, id, colorReset, place+1, length)
+	return nil
+}
@@ -0,0 +1,86 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/bunchhieng/rl/internal/model"
+)
+
+// Quote saves a passage highlighted from a link.
+func (c *Commands) Quote(id, text string) error {
+	return addExcerpt(c, quoteKind, id, text, c.storage.AddQuote)
+}
+
+// Quotes lists a link's quotes, oldest first, with the numbers Unquote
+// takes.
+func (c *Commands) Quotes(id string) error {
+	return listExcerpts(c, quoteKind, id, c.storage.Quotes)
+}
+
+// Unquote deletes a quote by its number.
+func (c *Commands) Unquote(quoteID int64) error {
+	return removeExcerpt(c, quoteKind, quoteID, c.storage.DeleteQuote)
+}
+
+// ExportQuotes writes every quote to w as Markdown, grouped under the link
+// it came from, or as JSON with --json.
+func (c *Commands) ExportQuotes(w io.Writer) error {
+	ctx := c.ctx
+	quotes, err := c.storage.Quotes(ctx, "")
+	if err != nil {
+		return err
+	}
+
+	// Group by link, in the order each link was first quoted
+	var links []*model.Link
+	byLink := make(map[string][]*model.Quote)
+	for _, q := range quotes {
+		if _, ok := byLink[q.LinkID]; !ok {
+			link, err := c.storage.Get(ctx, q.LinkID)
+			if err != nil {
+				return fmt.Errorf("get link: %w", err)
+			}
+			links = append(links, link)
+		}
+		byLink[q.LinkID] = append(byLink[q.LinkID], q)
+	}
+
+	if c.jsonOutput {
+		type linkQuotes struct {
+			*model.Link
+			Quotes []*model.Quote `json:"quotes"`
+		}
+		out := []linkQuotes{}
+		for _, link := range links {
+			out = append(out, linkQuotes{link, byLink[link.ID]})
+		}
+		return printJSON(out)
+	}
+	return writeQuotesMarkdown(w, links, byLink)
+}
+
+// writeQuotesMarkdown writes a "# Highlights" document with a section per
+// link and each quote as a blockquote.
+func writeQuotesMarkdown(w io.Writer, links []*model.Link, quotes map[string][]*model.Quote) error {
+	var b strings.Builder
+	b.WriteString("# Highlights\n")
+	for _, link := range links {
+		title := link.Title
+		if title == "" {
+			title = link.URL
+		}
+		fmt.Fprintf(&b, "\n## %s\n\n<%s>\n", title, link.URL)
+		for _, q := range quotes[link.ID] {
+			b.WriteString("\n")
+			for _, line := range strings.Split(q.Text, "\n") {
+				b.WriteString(strings.TrimRight("> "+line, " ") + "\n")
+			}
+			fmt.Fprintf(&b, "\n— %s\n", q.CreatedAt.In(displayLocation).Format(time.DateOnly))
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
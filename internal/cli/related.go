@@ -0,0 +1,78 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bunchhieng/rl/internal/model"
+	"github.com/bunchhieng/rl/internal/related"
+	"github.com/bunchhieng/rl/internal/storage"
+)
+
+// defaultRelatedLimit is how many related links are shown without --limit.
+const defaultRelatedLimit = 10
+
+// Related lists saved links that share tags, domain, or title terms with
+// a link, most similar first, followed by what each has in common.
+func (c *Commands) Related(id string, limit int) error {
+	id, err := c.resolveID(id)
+	if err != nil {
+		return err
+	}
+	ctx := c.ctx
+	target, err := c.storage.Get(ctx, id)
+	if err != nil {
+		return c.handleNotFound(err, id, "get link")
+	}
+	candidates, err := c.storage.List(ctx, storage.ListOptions{ReadStatus: storage.ReadStatusAll})
+	if err != nil {
+		return fmt.Errorf("list links: %w", err)
+	}
+	if limit <= 0 {
+		limit = defaultRelatedLimit
+	}
+	results := related.Find(target, candidates, limit)
+
+	links := make([]*model.Link, len(results))
+	for i, r := range results {
+		links[i] = r.Link
+	}
+	if c.jsonOutput {
+		if err := c.saveListing(listingIDs(links)); err != nil {
+			printListingWarning(err)
+		}
+		if results == nil {
+			results = []related.Result{}
+		}
+		return printJSON(results)
+	}
+	if len(results) == 0 {
+		fmt.Println("No related links.")
+		return nil
+	}
+	if err := c.printListing(links, DisplayOptions{}); err != nil {
+		return err
+	}
+
+	fmt.Println()
+	for i, r := range results {
+		fmt.Printf("%s%3d  %s%s\n", colorDim, i+1, relatedReasons(r), colorReset)
+	}
+	return nil
+}
+
+// relatedReasons describes what a related link shares with the target,
+// e.g. "tags go, runtime · title scheduler · same domain".
+func relatedReasons(r related.Result) string {
+	var parts []string
+	if len(r.Tags) > 0 {
+		parts = append(parts, "tags "+strings.Join(r.Tags, ", "))
+	}
+	if len(r.Terms) > 0 {
+		parts = append(parts, "title "+strings.Join(r.Terms, ", "))
+	}
+	if r.Domain {
+		parts = append(parts, "same domain")
+	}
+	return strings.Join(parts, " · ")
+}
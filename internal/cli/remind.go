@@ -0,0 +1,146 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/bunchhieng/rl/internal/model"
+	"github.com/bunchhieng/rl/internal/notify"
+)
+
+// remindInterval is how often Remind checks for woken links as a daemon.
+const remindInterval = time.Minute
+
+// remindState is what Remind keeps between runs.
+type remindState struct {
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// Remind shows a desktop notification for each unread link whose snooze
+// ended since the last check, recorded in statePath. Without a record
+// (or a statePath), checking starts now. As a daemon it checks every
+// minute until interrupted; otherwise it checks once and waits for the
+// notifications to close, so their Open action still works.
+func (c *Commands) Remind(statePath string, daemon bool) error {
+	if err := notify.Available(); err != nil {
+		return err
+	}
+	since, err := loadRemindState(statePath)
+	if err != nil {
+		return err
+	}
+	if since.IsZero() {
+		since = time.Now()
+	}
+
+	var pending sync.WaitGroup
+	defer pending.Wait()
+	ticker := time.NewTicker(remindInterval)
+	defer ticker.Stop()
+	warn := func(err error) {
+		fmt.Fprintf(os.Stderr, "%sWarning:%s %v\n", colorYellow, colorReset, err)
+	}
+	for {
+		now := time.Now()
+		links, err := c.remindWoken(statePath, since, now, &pending, warn)
+		if err != nil {
+			return err
+		}
+		for _, link := range links {
+			if c.jsonOutput {
+				if err := json.NewEncoder(os.Stdout).Encode(link); err != nil {
+					return fmt.Errorf("encode JSON: %w", err)
+				}
+				continue
+			}
+			fmt.Printf("%sReminded:%s %s %s(%s)%s\n", colorGreen, colorReset, displayTitle(link), colorDim, link.ID, colorReset)
+		}
+		since = now
+
+		if !daemon {
+			return nil
+		}
+		select {
+		case <-c.ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// remindWoken notifies about the unread links whose snooze ended after
+// since and no later than now, then records now in statePath as the last
+// check. Each notification waits in the background, counted by pending,
+// to open its link if asked; warn reports those that fail.
+func (c *Commands) remindWoken(statePath string, since, now time.Time, pending *sync.WaitGroup, warn func(error)) ([]*model.Link, error) {
+	links, err := c.storage.Woken(c.ctx, since, now)
+	if err != nil {
+		return nil, err
+	}
+	for _, link := range links {
+		n := notify.Notification{Title: displayTitle(link), Body: "Back in your queue: " + link.URL, URL: link.URL}
+		pending.Add(1)
+		go func() {
+			defer pending.Done()
+			opened, err := notify.Send(n)
+			if err != nil {
+				warn(err)
+				return
+			}
+			if opened {
+				if err := c.browser.Open(n.URL); err != nil {
+					warn(err)
+				}
+			}
+		}()
+	}
+	if err := saveRemindState(statePath, now); err != nil {
+		return nil, fmt.Errorf("save reminder state: %w", err)
+	}
+	return links, nil
+}
+
+// loadRemindState returns when Remind last checked, or the zero time if
+// it never has.
+func loadRemindState(path string) (time.Time, error) {
+	if path == "" {
+		return time.Time{}, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("read reminder state: %w", err)
+	}
+	var state remindState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return time.Time{}, fmt.Errorf("read reminder state: %w", err)
+	}
+	return state.CheckedAt, nil
+}
+
+// saveRemindState replaces the state file, readable only by its owner as
+// it tells when they read.
+func saveRemindState(path string, checkedAt time.Time) error {
+	if path == "" {
+		return nil
+	}
+	data, err := json.Marshal(remindState{CheckedAt: checkedAt.UTC()})
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
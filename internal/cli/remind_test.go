@@ -0,0 +1,82 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bunchhieng/rl/internal/model"
+)
+
+func TestRemind(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("notify-send stub uses the Linux tool name")
+	}
+	c, s := testCommands(t)
+	ctx := context.Background()
+	dir := t.TempDir()
+	statePath := filepath.Join(dir, "remind.json")
+
+	// A notify-send stub that records each notification's title and
+	// closes it without opening the link
+	bin := t.TempDir()
+	sent := filepath.Join(dir, "sent")
+	stub := "#!/bin/sh\nfor a; do title=\"$b\"; b=\"$a\"; done\necho \"$title\" >> " + sent + "\n"
+	if err := os.WriteFile(filepath.Join(bin, "notify-send"), []byte(stub), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin)
+
+	// The first run only starts the record
+	if _, err := captureStdout(t, func() error { return c.Remind(statePath, false) }); err != nil {
+		t.Fatalf("Remind failed: %v", err)
+	}
+	info, err := os.Stat(statePath)
+	if err != nil {
+		t.Fatalf("Expected a state file: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("Expected the state file readable only by its owner, got %v", perm)
+	}
+
+	// Links whose snooze ended since the last check are reminded of once
+	now := time.Now()
+	checked, _ := json.Marshal(remindState{CheckedAt: now.Add(-time.Hour)})
+	if err := os.WriteFile(statePath, checked, 0600); err != nil {
+		t.Fatal(err)
+	}
+	snooze := func(title string, until time.Time) *model.Link {
+		link := addLink(t, s, &model.Link{URL: "https://example.com/" + strings.ToLower(title), Title: title})
+		if err := s.Snooze(ctx, link.ID, until); err != nil {
+			t.Fatal(err)
+		}
+		return link
+	}
+	snooze("Woken", now.Add(-30*time.Minute))
+	snooze("Earlier", now.Add(-2*time.Hour))
+	snooze("Later", now.Add(time.Hour))
+
+	out, err := captureStdout(t, func() error { return c.Remind(statePath, false) })
+	if err != nil {
+		t.Fatalf("Remind failed: %v", err)
+	}
+	if !strings.Contains(out, "Reminded: Woken") || strings.Contains(out, "Earlier") || strings.Contains(out, "Later") {
+		t.Errorf("Expected only the link woken since the last check, got:\n%s", out)
+	}
+	if data, _ := os.ReadFile(sent); string(data) != "Woken\n" {
+		t.Errorf("Expected one notification for the woken link, got %q", data)
+	}
+	if since, err := loadRemindState(statePath); err != nil || now.Sub(since) > time.Minute {
+		t.Errorf("Expected the check recorded, got %v (%v)", since, err)
+	}
+
+	out, err = captureStdout(t, func() error { return c.Remind(statePath, false) })
+	if err != nil || out != "" {
+		t.Errorf("Expected nothing to remind of again, got %q (%v)", out, err)
+	}
+}
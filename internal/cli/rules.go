@@ -0,0 +1,160 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/bunchhieng/rl/internal/model"
+	"github.com/bunchhieng/rl/internal/rules"
+	"github.com/bunchhieng/rl/internal/storage"
+)
+
+// ListRules prints the tagging rules in the order they're applied.
+func ListRules(rs []rules.Rule, jsonOutput bool) error {
+	if jsonOutput {
+		type ruleJSON struct {
+			Domain string   `json:"domain,omitempty"`
+			URL    string   `json:"url,omitempty"`
+			Title  string   `json:"title,omitempty"`
+			Tags   []string `json:"tags"`
+		}
+		out := make([]ruleJSON, len(rs))
+		for i, r := range rs {
+			out[i] = ruleJSON{Domain: r.Domain, URL: r.URL, Title: r.Title, Tags: r.Tags}
+		}
+		return printJSON(out)
+	}
+	if len(rs) == 0 {
+		fmt.Println("No rules; add them as [[rules]] entries in the config file.")
+		return nil
+	}
+	for i, r := range rs {
+		fmt.Printf("%d. %s %s→ %s%s\n", i+1, r, colorYellow, strings.Join(r.Tags, ", "), colorReset)
+	}
+	return nil
+}
+
+// TestRules shows which rules would tag url and with what. Title rules
+// see title if given, else the title of the link saved for url, else the
+// page's title when fetch is set.
+func (c *Commands) TestRules(rs []rules.Rule, url, title string, fetch bool) error {
+	link := &model.Link{URL: url, Title: title}
+	if canonical, err := model.CanonicalizeURL(url); err == nil {
+		link.URL = canonical
+	}
+	if err := link.Validate(); err != nil {
+		return fmt.Errorf("invalid URL: %w", err)
+	}
+	if link.Title == "" {
+		if saved, err := c.storage.FindByURL(c.ctx, link.URL); err == nil {
+			link.Title = saved.Title
+		} else if fetch {
+			meta, err := c.fetchMetadata(link)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "%sWarning:%s could not fetch the title: %v\n", colorYellow, colorReset, err)
+			} else {
+				link.Title = meta.Title
+			}
+		}
+	}
+
+	matched := rules.Match(rs, link)
+	tags := rules.Apply(rs, link)
+	if c.jsonOutput {
+		type matchJSON struct {
+			Rule int      `json:"rule"`
+			Tags []string `json:"tags"`
+		}
+		out := struct {
+			URL     string      `json:"url"`
+			Title   string      `json:"title"`
+			Matches []matchJSON `json:"matches"`
+			Tags    []string    `json:"tags"`
+		}{URL: link.URL, Title: link.Title, Matches: []matchJSON{}, Tags: tags}
+		if out.Tags == nil {
+			out.Tags = []string{}
+		}
+		for i, r := range rs {
+			if r.Matches(link) {
+				out.Matches = append(out.Matches, matchJSON{Rule: i + 1, Tags: r.Tags})
+			}
+		}
+		return printJSON(out)
+	}
+
+	fmt.Printf("%sURL:%s   %s\n", colorBold, colorReset, link.URL)
+	fmt.Printf("%sTitle:%s %s\n", colorBold, colorReset, link.Title)
+	if len(matched) == 0 {
+		fmt.Println("No rules match.")
+		return nil
+	}
+	for i, r := range rs {
+		if r.Matches(link) {
+			fmt.Printf("%s✓%s %d. %s %s→ %s%s\n", colorGreen, colorReset, i+1, r, colorYellow, strings.Join(r.Tags, ", "), colorReset)
+		}
+	}
+	fmt.Printf("%sTags:%s  %s\n", colorBold, colorReset, strings.Join(tags, ", "))
+	return nil
+}
+
+// ApplyRules adds the tags of the rules each link matches to the links
+// with the given IDs, or to every link if there are none. With dryRun it
+// only reports what it would tag.
+func (c *Commands) ApplyRules(rs []rules.Rule, dryRun bool, ids ...string) error {
+	if len(rs) == 0 {
+		return fmt.Errorf("no rules; add them as [[rules]] entries in the config file")
+	}
+	var links []*model.Link
+	if len(ids) == 0 {
+		var err error
+		links, err = c.storage.List(c.ctx, storage.ListOptions{ReadStatus: storage.ReadStatusAll})
+		if err != nil {
+			return fmt.Errorf("list links: %w", err)
+		}
+	}
+	for _, id := range ids {
+		resolved, err := c.resolveID(id)
+		if err != nil {
+			return err
+		}
+		link, err := c.storage.Get(c.ctx, resolved)
+		if err != nil {
+			return c.handleNotFound(err, resolved, "get link")
+		}
+		links = append(links, link)
+	}
+
+	type taggedJSON struct {
+		ID    string   `json:"id"`
+		URL   string   `json:"url"`
+		Added []string `json:"added"`
+	}
+	tagged := []taggedJSON{}
+	verb := "Tagged"
+	if dryRun {
+		verb = "Would tag"
+	}
+	for _, link := range links {
+		added := rules.Apply(rs, link)
+		if len(added) == 0 {
+			continue
+		}
+		if !dryRun {
+			if err := c.storage.Update(c.ctx, link); err != nil {
+				fmt.Fprintf(os.Stderr, "%sSkipped%s %s: %v\n", colorRed, colorReset, link.ID, err)
+				continue
+			}
+		}
+		tagged = append(tagged, taggedJSON{ID: link.ID, URL: link.URL, Added: added})
+		if !c.jsonOutput {
+			fmt.Printf("%s%s%s %s %s(%s)%s: %s+%s%s\n", colorGreen, verb, colorReset, displayTitle(link),
+				colorDim, link.ID, colorReset, colorYellow, strings.Join(added, " +"), colorReset)
+		}
+	}
+	if c.jsonOutput {
+		return printJSON(tagged)
+	}
+	fmt.Printf("%s %d of %s.\n", verb, len(tagged), plural(len(links), "link"))
+	return nil
+}
@@ -0,0 +1,178 @@
+package cli
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bunchhieng/rl/internal/llm"
+	"github.com/bunchhieng/rl/internal/model"
+	"github.com/bunchhieng/rl/internal/storage"
+)
+
+const (
+	// semanticLimit caps the results of a semantic search, which ranks
+	// every link rather than filtering them.
+	semanticLimit = 20
+	// embedBatch is how many links are embedded per request.
+	embedBatch = 32
+	// snippetWidth is how much of the matched field a result shows.
+	snippetWidth = 120
+)
+
+// SemanticSearch ranks links by how close the meaning of their title,
+// description, and summary is to query's, using client's embedding model.
+// With index set, links not embedded yet, or changed since, are embedded
+// and stored first; otherwise only links already indexed are searched.
+func (c *Commands) SemanticSearch(client *llm.Client, query string, dates storage.DateRange, index bool, display DisplayOptions) error {
+	links, err := c.storage.List(c.ctx, storage.ListOptions{ReadStatus: storage.ReadStatusAll, DateRange: dates})
+	if err != nil {
+		return fmt.Errorf("list links: %w", err)
+	}
+	stored, err := c.storage.Embeddings(c.ctx, client.EmbeddingModel())
+	if err != nil {
+		return err
+	}
+	vectors := make(map[string]*model.Embedding, len(stored))
+	for _, e := range stored {
+		vectors[e.LinkID] = e
+	}
+
+	summaries, err := c.storage.Summaries(c.ctx)
+	if err != nil {
+		return err
+	}
+
+	fields := make(map[string]*model.Match, len(links))
+	var stale []*model.Embedding
+	var staleText []string
+	missing := 0
+	for _, link := range links {
+		match, text := embeddingText(link, summaries[link.ID])
+		if text == "" {
+			continue
+		}
+		fields[link.ID] = match
+		hash := sha256.Sum256([]byte(text))
+		e := vectors[link.ID]
+		if e == nil {
+			missing++
+		}
+		if e == nil || e.TextHash != hex.EncodeToString(hash[:]) {
+			stale = append(stale, &model.Embedding{LinkID: link.ID, Model: client.EmbeddingModel(), TextHash: hex.EncodeToString(hash[:])})
+			staleText = append(staleText, text)
+		}
+	}
+	switch {
+	case len(stale) > 0 && index:
+		if err := c.embed(client, stale, staleText); err != nil {
+			return err
+		}
+		for _, e := range stale {
+			vectors[e.LinkID] = e
+		}
+	case missing > 0:
+		fmt.Fprintf(os.Stderr, "%sWarning:%s %s not indexed for semantic search yet and left out.\n",
+			colorYellow, colorReset, plural(missing, "link"))
+	}
+
+	queryVectors, err := client.Embed(c.ctx, []string{query})
+	if err != nil {
+		return err
+	}
+	var results []*model.Link
+	for _, link := range links {
+		e, match := vectors[link.ID], fields[link.ID]
+		if e == nil || match == nil {
+			continue
+		}
+		if match.Similarity = cosine(queryVectors[0], e.Vector); match.Similarity > 0 {
+			link.Match = match
+			results = append(results, link)
+		}
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Match.Similarity > results[j].Match.Similarity
+	})
+	if len(results) > semanticLimit {
+		results = results[:semanticLimit]
+	}
+
+	if len(results) == 0 && !c.jsonOutput && !display.TSV {
+		fmt.Println("No links found.")
+		return nil
+	}
+	if err := c.printListing(results, display); err != nil {
+		return err
+	}
+	if !c.jsonOutput && !display.TSV {
+		printMatches(results)
+	}
+	return nil
+}
+
+// embeddingText returns the text embedded for link: its title,
+// description, and summary, if any. The match names the most telling of
+// them for display. Links with none of them have no text.
+func embeddingText(link *model.Link, summary *model.Summary) (*model.Match, string) {
+	match := &model.Match{Field: "title", Snippet: link.Title}
+	parts := []string{link.Title, link.Description}
+	if link.Description != "" {
+		match = &model.Match{Field: "description", Snippet: link.Description}
+	}
+	if summary != nil {
+		parts = append(parts, summary.Text)
+		match = &model.Match{Field: "summary", Snippet: summary.Text}
+	}
+	text := strings.TrimSpace(strings.Join(parts, "\n\n"))
+	match.Snippet = truncateString(strings.Join(strings.Fields(match.Snippet), " "), snippetWidth)
+	return match, text
+}
+
+// embed computes and stores the vectors of embeddings, whose texts are
+// given in the same order, in batches.
+func (c *Commands) embed(client *llm.Client, embeddings []*model.Embedding, texts []string) error {
+	if !c.jsonOutput {
+		fmt.Fprintf(os.Stderr, "%sIndexing %s with %s…%s\n", colorDim, plural(len(embeddings), "link"), client.EmbeddingModel(), colorReset)
+	}
+	for start := 0; start < len(embeddings); start += embedBatch {
+		end := start + embedBatch
+		if end > len(embeddings) {
+			end = len(embeddings)
+		}
+		vectors, err := client.Embed(c.ctx, texts[start:end])
+		if err != nil {
+			return err
+		}
+		for i, e := range embeddings[start:end] {
+			e.Vector, e.CreatedAt = vectors[i], time.Now()
+			if err := c.storage.SetEmbedding(c.ctx, e); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// cosine returns the cosine similarity of a and b, from -1 to 1, or 0 if
+// they differ in length, as vectors from different models do.
+func cosine(a, b []float32) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / math.Sqrt(normA*normB)
+}
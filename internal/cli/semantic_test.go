@@ -0,0 +1,132 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/bunchhieng/rl/internal/llm"
+	"github.com/bunchhieng/rl/internal/model"
+	"github.com/bunchhieng/rl/internal/storage"
+)
+
+// topics are the dimensions of the test embedding model: a text's vector
+// counts its mentions of each.
+var topics = []string{"rust", "cooking", "garden"}
+
+func TestSemanticSearch(t *testing.T) {
+	c, s := testCommands(t)
+	ctx := context.Background()
+	rust := addLink(t, s, &model.Link{URL: "https://example.com/rust", Title: "Rust ownership"})
+	bread := addLink(t, s, &model.Link{URL: "https://example.com/bread", Title: "Bread"})
+	addLink(t, s, &model.Link{URL: "https://example.com/untitled"})
+	if err := s.SetSummary(ctx, &model.Summary{LinkID: bread.ID, Text: "A cooking guide to sourdough."}); err != nil {
+		t.Fatal(err)
+	}
+
+	var mu sync.Mutex
+	var embedded []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Input []string `json:"input"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("Decode request: %v", err)
+		}
+		type datum struct {
+			Index     int       `json:"index"`
+			Embedding []float32 `json:"embedding"`
+		}
+		var resp struct {
+			Data []datum `json:"data"`
+		}
+		for i, text := range req.Input {
+			vector := make([]float32, len(topics))
+			for j, topic := range topics {
+				vector[j] = float32(strings.Count(strings.ToLower(text), topic))
+			}
+			resp.Data = append(resp.Data, datum{Index: i, Embedding: vector})
+		}
+		mu.Lock()
+		embedded = append(embedded, req.Input...)
+		mu.Unlock()
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+	client, err := llm.NewClient(llm.Options{Endpoint: server.URL, EmbeddingModel: "test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	search := func(query string, index bool) []*model.Link {
+		t.Helper()
+		embedded = nil
+		c.SetJSON(true)
+		defer c.SetJSON(false)
+		out, err := captureStdout(t, func() error {
+			return c.SemanticSearch(client, query, storage.DateRange{}, index, DisplayOptions{})
+		})
+		if err != nil {
+			t.Fatalf("SemanticSearch(%q) failed: %v", query, err)
+		}
+		var links []*model.Link
+		if err := json.Unmarshal([]byte(out), &links); err != nil {
+			t.Fatalf("Expected JSON links, got %q: %v", out, err)
+		}
+		return links
+	}
+
+	// The first search indexes the links with text, leaving out links
+	// unrelated to the query
+	links := search("cooking", true)
+	if len(links) != 1 || links[0].ID != bread.ID || links[0].Match == nil || links[0].Match.Field != "summary" {
+		t.Fatalf("Expected the bread link matched on its summary, got %+v", links)
+	}
+	if len(embedded) != 3 {
+		t.Errorf("Expected the 2 links with text and the query embedded, got %q", embedded)
+	}
+
+	// Later searches reuse the stored vectors until a link's text changes
+	if links = search("rust", true); len(links) != 1 || links[0].ID != rust.ID {
+		t.Errorf("Expected the rust link, got %+v", links)
+	}
+	if len(embedded) != 1 {
+		t.Errorf("Expected only the query embedded, got %q", embedded)
+	}
+	if err := s.SetSummary(ctx, &model.Summary{LinkID: rust.ID, Text: "Rust borrowing, and cooking with it."}); err != nil {
+		t.Fatal(err)
+	}
+	links = search("cooking", false)
+	if len(links) != 1 || links[0].ID != bread.ID || len(embedded) != 1 {
+		t.Errorf("Expected the stale vector kept without indexing, got %+v (embedded %q)", links, embedded)
+	}
+	links = search("cooking", true)
+	if len(links) != 2 || len(embedded) != 2 {
+		t.Errorf("Expected the changed link re-indexed and found, got %+v (embedded %q)", links, embedded)
+	}
+}
+
+func TestCosine(t *testing.T) {
+	tests := []struct {
+		a, b []float32
+		want float64
+	}{
+		{[]float32{1, 0}, []float32{2, 0}, 1},
+		{[]float32{1, 0}, []float32{0, 3}, 0},
+		{[]float32{1, 2}, []float32{-1, -2}, -1},
+		{[]float32{1, 1}, []float32{1, 0}, 1 / math.Sqrt2},
+		{[]float32{1, 0}, []float32{1, 0, 0}, 0},
+		{[]float32{0, 0}, []float32{1, 0}, 0},
+		{nil, nil, 0},
+	}
+	for _, tt := range tests {
+		if got := cosine(tt.a, tt.b); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("cosine(%v, %v) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
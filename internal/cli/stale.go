@@ -0,0 +1,115 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/bunchhieng/rl/internal/model"
+	"github.com/bunchhieng/rl/internal/storage"
+)
+
+// StaleAction is what `rl stale` does with the links it finds.
+type StaleAction string
+
+const (
+	StaleKeep    StaleAction = ""
+	StaleArchive StaleAction = "archive"
+	StaleDelete  StaleAction = "delete"
+)
+
+// StaleOptions configures Stale.
+type StaleOptions struct {
+	// OlderThan is how long a link must have sat unread.
+	OlderThan time.Duration
+	Limit     int
+	// Action is applied without asking; StaleKeep asks on Prompt.
+	Action StaleAction
+	// Prompt is read for the user's answer; nil lists the links without
+	// asking.
+	Prompt io.Reader
+}
+
+// Stale lists unread links saved more than opts.OlderThan ago, oldest
+// first, then archives them all or moves them to the trash if asked to.
+func (c *Commands) Stale(opts StaleOptions) error {
+	ctx := c.ctx
+	links, err := c.storage.List(ctx, storage.ListOptions{
+		ReadStatus: storage.ReadStatusUnread,
+		Limit:      opts.Limit,
+		Sort:       storage.SortOldest,
+		DateRange:  storage.DateRange{Before: time.Now().Add(-opts.OlderThan)},
+	})
+	if err != nil {
+		return fmt.Errorf("list links: %w", err)
+	}
+
+	days := int(opts.OlderThan.Hours() / 24)
+	if len(links) == 0 {
+		if c.jsonOutput {
+			return printJSON([]*model.Link{})
+		}
+		fmt.Printf("No unread links older than %s.\n", plural(days, "day"))
+		return nil
+	}
+	if !c.jsonOutput {
+		fmt.Printf("%s%d unread link(s)%s saved more than %s ago:\n", colorBold, len(links), colorReset, plural(days, "day"))
+	}
+	if err := c.printListing(links, DisplayOptions{}); err != nil {
+		return err
+	}
+
+	action := opts.Action
+	if action == StaleKeep && opts.Prompt != nil && !c.jsonOutput {
+		if action, err = askStaleAction(opts.Prompt, len(links)); err != nil {
+			return err
+		}
+	}
+	switch action {
+	case StaleArchive:
+		return c.applyStale(links, "Archived", c.storage.Archive)
+	case StaleDelete:
+		return c.applyStale(links, "Trashed", c.storage.Trash)
+	}
+	return nil
+}
+
+// askStaleAction asks whether to archive, trash, or keep n links; keeping
+// them is the default.
+func askStaleAction(r io.Reader, n int) (StaleAction, error) {
+	fmt.Printf("\nArchive (rl ls --archived lists them), delete (rl trash lists them), or keep these %d link(s)? [a/d/K] ", n)
+	answer, err := bufio.NewReader(r).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return StaleKeep, fmt.Errorf("read answer: %w", err)
+	}
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "a", "archive":
+		return StaleArchive, nil
+	case "d", "delete":
+		return StaleDelete, nil
+	}
+	fmt.Println("Kept.")
+	return StaleKeep, nil
+}
+
+// applyStale runs apply on each link, reporting failures and a summary.
+func (c *Commands) applyStale(links []*model.Link, done string, apply func(context.Context, string) error) error {
+	failed := 0
+	for _, link := range links {
+		if err := apply(c.ctx, link.ID); err != nil {
+			fmt.Fprintf(os.Stderr, "%sSkipped%s %s: %v\n", colorRed, colorReset, link.ID, err)
+			failed++
+		}
+	}
+	if !c.jsonOutput {
+		fmt.Printf("%s%s%s %d link(s).\n", colorGreen, done, colorReset, len(links)-failed)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d link(s) could not be %s", failed, len(links), strings.ToLower(done))
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bunchhieng/rl/internal/model"
+)
+
+func TestStale(t *testing.T) {
+	c, s := testCommands(t)
+	ctx := context.Background()
+	readAt := daysAgo(200)
+	old := addLink(t, s, &model.Link{URL: "https://example.com/old", CreatedAt: daysAgo(120)})
+	recent := addLink(t, s, &model.Link{URL: "https://example.com/recent", CreatedAt: daysAgo(10)})
+	read := addLink(t, s, &model.Link{URL: "https://example.com/read", CreatedAt: daysAgo(300), ReadAt: &readAt})
+	opts := StaleOptions{OlderThan: 90 * 24 * time.Hour}
+
+	out, err := captureStdout(t, func() error { return c.Stale(opts) })
+	if err != nil {
+		t.Fatalf("Stale failed: %v", err)
+	}
+	if !strings.Contains(out, "1 unread link(s)") || !strings.Contains(out, old.URL) || strings.Contains(out, recent.URL) {
+		t.Errorf("Expected only the old unread link listed, got:\n%s", out)
+	}
+
+	// Keeping is the default answer
+	opts.Prompt = strings.NewReader("\n")
+	if out, _ := captureStdout(t, func() error { return c.Stale(opts) }); !strings.Contains(out, "Kept.") {
+		t.Errorf("Expected the links kept, got:\n%s", out)
+	}
+	if got, _ := s.Get(ctx, old.ID); got.IsRead() {
+		t.Error("Expected the kept link still unread")
+	}
+
+	opts.Prompt = strings.NewReader("a\n")
+	if _, err := captureStdout(t, func() error { return c.Stale(opts) }); err != nil {
+		t.Fatalf("Stale failed: %v", err)
+	}
+	got, _ := s.Get(ctx, old.ID)
+	if got.ArchivedAt == nil || got.ReadAt == nil {
+		t.Errorf("Expected the stale link archived, got %+v", got)
+	}
+	if events, _ := s.ReadLog(ctx); len(events) != 1 || events[0].LinkID != read.ID {
+		t.Errorf("Expected archiving not to count as reading, got %+v", events)
+	}
+	if got, _ := s.Get(ctx, read.ID); !got.ReadAt.Equal(readAt.Truncate(time.Second)) || got.ArchivedAt != nil {
+		t.Errorf("Expected the read link untouched, got %+v", got)
+	}
+
+	// Nothing is left to clear out
+	c.SetJSON(true)
+	out, _ = captureStdout(t, func() error { return c.Stale(opts) })
+	var links []*model.Link
+	if err := json.Unmarshal([]byte(out), &links); err != nil || len(links) != 0 {
+		t.Errorf("Expected an empty JSON list, got %q (%v)", out, err)
+	}
+	c.SetJSON(false)
+
+	// --delete moves them to the trash
+	stale := addLink(t, s, &model.Link{URL: "https://example.com/stale", CreatedAt: daysAgo(100)})
+	opts.Prompt, opts.Action = nil, StaleDelete
+	if _, err := captureStdout(t, func() error { return c.Stale(opts) }); err != nil {
+		t.Fatalf("Stale failed: %v", err)
+	}
+	if got, err := s.Get(ctx, stale.ID); err != nil || got.TrashedAt == nil {
+		t.Errorf("Expected the stale link trashed, got %+v (%v)", got, err)
+	}
+}
@@ -0,0 +1,43 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/bunchhieng/rl/internal/streak"
+)
+
+// Streak prints the current and best reading streaks and progress toward
+// the weekly goal (0 for none).
+func (c *Commands) Streak(goal int) error {
+	events, err := c.storage.ReadLog(c.ctx)
+	if err != nil {
+		return err
+	}
+	sum := streak.Compute(events, goal, time.Now().In(displayLocation))
+	if c.jsonOutput {
+		return printJSON(sum)
+	}
+
+	fmt.Printf("%sCurrent streak:%s %s\n", colorBold, colorReset, plural(sum.Current, "day"))
+	fmt.Printf("%sBest streak:%s    %s\n", colorBold, colorReset, plural(sum.Best, "day"))
+	fmt.Printf("%sRead today:%s     %d\n", colorBold, colorReset, sum.Today)
+	if sum.Goal > 0 {
+		fmt.Printf("%sThis week:%s      %d of %d\n", colorBold, colorReset, sum.Week, sum.Goal)
+	} else {
+		fmt.Printf("%sThis week:%s      %d\n", colorBold, colorReset, sum.Week)
+	}
+	if nudge := sum.Nudge(); nudge != "" {
+		fmt.Printf("\n%s%s%s\n", colorYellow, nudge, colorReset)
+	} else if sum.Goal > 0 {
+		fmt.Printf("\n%sWeekly goal reached.%s\n", colorGreen, colorReset)
+	}
+	return nil
+}
+
+func plural(n int, unit string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, unit)
+	}
+	return fmt.Sprintf("%d %ss", n, unit)
+}
@@ -0,0 +1,222 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/bunchhieng/rl/internal/keywords"
+	"github.com/bunchhieng/rl/internal/llm"
+	"github.com/bunchhieng/rl/internal/model"
+	"github.com/bunchhieng/rl/internal/storage"
+)
+
+const (
+	// suggestLimit is how many tags are suggested per link.
+	suggestLimit = 5
+	// knownTagLimit caps the tags in use sent to the model to prefer, most
+	// used first, to keep prompts short.
+	knownTagLimit = 50
+)
+
+// SuggestOptions controls SuggestTags.
+type SuggestOptions struct {
+	// LLM suggests the tags; nil picks them from the article's own words.
+	LLM *llm.Client
+	// Untagged suggests tags for every link without any, instead of IDs.
+	Untagged bool
+	// Yes adds the suggestions without asking.
+	Yes bool
+	// Prompt is read for the user's answer to each suggestion; nil lists
+	// the suggestions without adding them.
+	Prompt io.Reader
+}
+
+// SuggestTags proposes tags for the links with the given IDs from their
+// title and archived text, preferring tags already in use, and adds them
+// as opts says.
+func (c *Commands) SuggestTags(opts SuggestOptions, ids ...string) error {
+	all, err := c.storage.List(c.ctx, storage.ListOptions{ReadStatus: storage.ReadStatusAll})
+	if err != nil {
+		return fmt.Errorf("list links: %w", err)
+	}
+	known := knownTags(all)
+
+	var links []*model.Link
+	if opts.Untagged {
+		for _, link := range all {
+			if len(link.TagList()) == 0 {
+				links = append(links, link)
+			}
+		}
+		if len(links) == 0 && !c.jsonOutput {
+			fmt.Println("Every link has tags.")
+			return nil
+		}
+	}
+	for _, id := range ids {
+		resolved, err := c.resolveID(id)
+		if err != nil {
+			return err
+		}
+		link, err := c.storage.Get(c.ctx, resolved)
+		if err != nil {
+			return c.handleNotFound(err, resolved, "get link")
+		}
+		links = append(links, link)
+	}
+
+	type suggestionJSON struct {
+		ID        string   `json:"id"`
+		URL       string   `json:"url"`
+		Suggested []string `json:"suggested"`
+		Added     []string `json:"added"`
+	}
+	out := []suggestionJSON{}
+	var answers *bufio.Reader
+	if opts.Prompt != nil && !c.jsonOutput {
+		answers = bufio.NewReader(opts.Prompt)
+	}
+	tagged, failed := 0, 0
+	for _, link := range links {
+		suggested, err := c.suggestTags(opts.LLM, link, known)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%sSkipped%s %s: %v\n", colorRed, colorReset, link.ID, err)
+			failed++
+			continue
+		}
+		if !c.jsonOutput {
+			fmt.Printf("%s%s%s %s(%s)%s\n", colorBold, displayTitle(link), colorReset, colorDim, link.ID, colorReset)
+			if len(suggested) == 0 {
+				fmt.Printf("  %sNo suggestions.%s\n", colorDim, colorReset)
+				continue
+			}
+			fmt.Printf("  %s%s%s\n", colorYellow, strings.Join(suggested, ", "), colorReset)
+		}
+
+		added := []string{}
+		tags := suggested
+		if !opts.Yes && answers != nil && len(suggested) > 0 {
+			tags, err = askTags(answers, suggested)
+			if err != nil {
+				return err
+			}
+		}
+		if (opts.Yes || answers != nil) && len(tags) > 0 {
+			before := len(link.TagList())
+			link.MergeTags(&model.Link{Tags: strings.Join(tags, ",")})
+			if err := c.storage.Update(c.ctx, link); err != nil {
+				fmt.Fprintf(os.Stderr, "%sSkipped%s %s: %v\n", colorRed, colorReset, link.ID, err)
+				failed++
+				continue
+			}
+			added = link.TagList()[before:]
+			if len(added) > 0 {
+				tagged++
+			}
+			if !c.jsonOutput {
+				fmt.Printf("  %sTagged%s %s+%s%s\n", colorGreen, colorReset, colorYellow, strings.Join(added, " +"), colorReset)
+			}
+		}
+		out = append(out, suggestionJSON{ID: link.ID, URL: link.URL, Suggested: suggested, Added: added})
+	}
+
+	if c.jsonOutput {
+		if err := printJSON(out); err != nil {
+			return err
+		}
+	} else if opts.Yes || answers != nil {
+		fmt.Printf("Tagged %d of %s.\n", tagged, plural(len(links), "link"))
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %s could not be tagged", failed, plural(len(links), "link"))
+	}
+	return nil
+}
+
+// suggestTags returns suggestions for link it doesn't already have, from
+// client or, if nil, from the link's words.
+func (c *Commands) suggestTags(client *llm.Client, link *model.Link, known []string) ([]string, error) {
+	text, err := c.storage.Content(c.ctx, link.ID)
+	if err != nil && err != model.ErrNotFound {
+		return nil, err
+	}
+	if link.Description != "" {
+		text = link.Description + "\n\n" + text
+	}
+
+	var suggested []string
+	if client != nil {
+		if strings.TrimSpace(link.Title+text) == "" {
+			return nil, fmt.Errorf("no title or archived text to go on; fetch it with `rl fetch %s`", link.ID)
+		}
+		limit := known
+		if len(limit) > knownTagLimit {
+			limit = limit[:knownTagLimit]
+		}
+		if suggested, err = client.SuggestTags(c.ctx, link.Title, text, limit, suggestLimit); err != nil {
+			return nil, err
+		}
+	} else {
+		// Ask for extra in case some are tags the link has
+		suggested = keywords.Suggest(link.Title, text, known, suggestLimit+len(link.TagList()))
+	}
+
+	had := map[string]bool{}
+	for _, tag := range link.TagList() {
+		had[strings.ToLower(tag)] = true
+	}
+	var fresh []string
+	for _, tag := range suggested {
+		if !had[strings.ToLower(tag)] && len(fresh) < suggestLimit {
+			fresh = append(fresh, tag)
+		}
+	}
+	return fresh, nil
+}
+
+// knownTags returns the tags on links, lowercased, most used first.
+func knownTags(links []*model.Link) []string {
+	counts := map[string]int{}
+	for _, link := range links {
+		for _, tag := range link.TagList() {
+			counts[strings.ToLower(tag)]++
+		}
+	}
+	tags := make([]string, 0, len(counts))
+	for tag := range counts {
+		tags = append(tags, tag)
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		if counts[tags[i]] != counts[tags[j]] {
+			return counts[tags[i]] > counts[tags[j]]
+		}
+		return tags[i] < tags[j]
+	})
+	return tags
+}
+
+// askTags asks whether to add the suggested tags, and returns those to
+// add: all of them by default, none if declined or at the end of input,
+// or the tags typed instead.
+func askTags(r *bufio.Reader, suggested []string) ([]string, error) {
+	fmt.Print("  Add these tags? [Y/n, or type your own] ")
+	answer, err := r.ReadString('\n')
+	if err != nil && err != io.EOF {
+		return nil, fmt.Errorf("read answer: %w", err)
+	}
+	if err == io.EOF && answer == "" {
+		fmt.Println()
+		return nil, nil
+	}
+	switch answer = strings.TrimSpace(answer); strings.ToLower(answer) {
+	case "", "y", "yes":
+		return suggested, nil
+	case "n", "no":
+		return nil, nil
+	}
+	return (&model.Link{Tags: answer}).TagList(), nil
+}
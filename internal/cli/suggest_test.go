@@ -0,0 +1,81 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bunchhieng/rl/internal/llm"
+	"github.com/bunchhieng/rl/internal/model"
+)
+
+func TestSuggestTags(t *testing.T) {
+	c, s := testCommands(t)
+	ctx := context.Background()
+	tagged := addLink(t, s, &model.Link{URL: "https://example.com/channels", Title: "Go channels", Tags: "go"})
+	untagged := addLink(t, s, &model.Link{URL: "https://example.com/pool", Title: "Connection pools"})
+	if err := s.SetContent(ctx, untagged.ID, "Databases hand out pooled connections. Databases reuse them."); err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"go, concurrency, databases"}}]}`))
+	}))
+	defer server.Close()
+	client, err := llm.NewClient(llm.Options{Endpoint: server.URL, Model: "llama3.2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Without a prompt, the suggestions are only listed, leaving out the
+	// tags the link has
+	out, err := captureStdout(t, func() error { return c.SuggestTags(SuggestOptions{LLM: client}, tagged.ID) })
+	if err != nil {
+		t.Fatalf("SuggestTags failed: %v", err)
+	}
+	if !strings.Contains(out, "concurrency, databases") || strings.Contains(out, "Tagged") {
+		t.Errorf("Expected the suggestions listed, got:\n%s", out)
+	}
+	if link, _ := s.Get(ctx, tagged.ID); link.Tags != "go" {
+		t.Errorf("Expected the tags unchanged, got %q", link.Tags)
+	}
+
+	// Answers decline the first link and type tags for the second
+	prompt := strings.NewReader("n\nsql, pooling\n")
+	if _, err := captureStdout(t, func() error {
+		return c.SuggestTags(SuggestOptions{LLM: client, Prompt: prompt}, tagged.ID, untagged.ID)
+	}); err != nil {
+		t.Fatalf("SuggestTags failed: %v", err)
+	}
+	if link, _ := s.Get(ctx, tagged.ID); link.Tags != "go" {
+		t.Errorf("Expected a declined suggestion not added, got %q", link.Tags)
+	}
+	if link, _ := s.Get(ctx, untagged.ID); link.Tags != "sql,pooling" {
+		t.Errorf("Expected the typed tags added, got %q", link.Tags)
+	}
+
+	// Without a model, the article's own words are suggested
+	if err := s.Update(ctx, &model.Link{ID: untagged.ID, URL: untagged.URL, Title: untagged.Title}); err != nil {
+		t.Fatal(err)
+	}
+	c.SetJSON(true)
+	defer c.SetJSON(false)
+	out, err = captureStdout(t, func() error { return c.SuggestTags(SuggestOptions{Untagged: true, Yes: true}) })
+	if err != nil {
+		t.Fatalf("SuggestTags(local) failed: %v", err)
+	}
+	var got []struct {
+		ID        string   `json:"id"`
+		Suggested []string `json:"suggested"`
+		Added     []string `json:"added"`
+	}
+	if err := json.Unmarshal([]byte(out), &got); err != nil {
+		t.Fatalf("Expected JSON output, got %q: %v", out, err)
+	}
+	if len(got) != 1 || got[0].ID != untagged.ID || !strings.Contains(strings.Join(got[0].Added, ","), "databases") {
+		t.Errorf("Expected the untagged link tagged from its words, got %+v", got)
+	}
+}
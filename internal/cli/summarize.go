@@ -0,0 +1,79 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/bunchhieng/rl/internal/llm"
+	"github.com/bunchhieng/rl/internal/model"
+)
+
+// Summarize asks client for a summary of each link's archived text and
+// stores it for rl show and the TUI. Links already summarized keep their
+// summary unless force is set.
+func (c *Commands) Summarize(client *llm.Client, force bool, ids ...string) error {
+	return c.forEachID(ids, "summarize", func(id string) error {
+		return c.summarize(client, force, id)
+	})
+}
+
+func (c *Commands) summarize(client *llm.Client, force bool, id string) error {
+	id, err := c.resolveID(id)
+	if err != nil {
+		return err
+	}
+	link, err := c.storage.Get(c.ctx, id)
+	if err != nil {
+		return c.handleNotFound(err, id, "get link")
+	}
+
+	summary, err := c.storage.Summary(c.ctx, id)
+	switch {
+	case err == nil && !force:
+		return c.printSummary(link, summary, true)
+	case err != nil && err != model.ErrNotFound:
+		return err
+	}
+
+	text, err := c.storage.Content(c.ctx, id)
+	if err == model.ErrNotFound || (err == nil && strings.TrimSpace(text) == "") {
+		return fmt.Errorf("no archived text to summarize; fetch it with `rl fetch %s`", id)
+	}
+	if err != nil {
+		return err
+	}
+	if !c.jsonOutput {
+		fmt.Printf("%sSummarizing %s with %s…%s\n", colorDim, displayTitle(link), client.Model(), colorReset)
+	}
+	text, err = client.Summarize(c.ctx, link.Title, text)
+	if err != nil {
+		return err
+	}
+	summary = &model.Summary{LinkID: id, Text: text, Model: client.Model(), CreatedAt: time.Now()}
+	if err := c.storage.SetSummary(c.ctx, summary); err != nil {
+		return err
+	}
+	return c.printSummary(link, summary, false)
+}
+
+// printSummary prints a link's summary, noting when it was kept from an
+// earlier run. JSON output is a line per summary.
+func (c *Commands) printSummary(link *model.Link, summary *model.Summary, kept bool) error {
+	if c.jsonOutput {
+		if err := json.NewEncoder(os.Stdout).Encode(summary); err != nil {
+			return fmt.Errorf("encode JSON: %w", err)
+		}
+		return nil
+	}
+	fmt.Printf("%s%s%s %s(%s)%s\n", colorBold, displayTitle(link), colorReset, colorDim, link.ID, colorReset)
+	for _, line := range strings.Split(summary.Text, "\n") {
+		fmt.Printf("  %s\n", line)
+	}
+	if kept {
+		fmt.Printf("%s  (summarized %s; --force writes a new one)%s\n", colorDim, formatTime(summary.CreatedAt), colorReset)
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/bunchhieng/rl/internal/model"
+	"github.com/bunchhieng/rl/internal/pinboard"
+	"github.com/bunchhieng/rl/internal/wallabag"
+)
+
+// SyncWallabag mirrors links with a Wallabag instance and prints what
+// changed on each side. Changes made before a failure are still reported.
+func (c *Commands) SyncWallabag(client *wallabag.Client) error {
+	result, err := wallabag.Sync(c.ctx, c.storage, client)
+	if result == nil {
+		return err
+	}
+	if c.jsonOutput {
+		if jsonErr := printJSON(result); jsonErr != nil {
+			return jsonErr
+		}
+	} else {
+		fmt.Printf("%sWallabag:%s %d pushed, %d marked read, %d marked unread\n", colorBold, colorReset,
+			result.Pushed, result.ReadRemote, result.UnreadRemote)
+		fmt.Printf("%srl:%s       %d pulled, %d marked read, %d marked unread\n", colorBold, colorReset,
+			result.Pulled, result.ReadLocal, result.UnreadLocal)
+	}
+	if err != nil {
+		return fmt.Errorf("sync stopped early: %w", err)
+	}
+	return nil
+}
+
+// PushPinboard sends links added or changed since the last push to
+// Pinboard, printing each one. With dryRun set it only lists them.
+func (c *Commands) PushPinboard(client *pinboard.Client, dryRun bool) error {
+	verb := "Pushed"
+	if dryRun {
+		verb = "Would push"
+	}
+	var pushed []*model.Link
+	result, err := pinboard.Push(c.ctx, c.storage, client, dryRun, func(link *model.Link) {
+		pushed = append(pushed, link)
+		if !c.jsonOutput {
+			fmt.Printf("%s%s%s %s%s%s: %s%s%s\n", colorGreen, verb, colorReset, colorBold, link.ID, colorReset, colorCyan, link.URL, colorReset)
+		}
+	})
+	if result == nil {
+		return err
+	}
+	if c.jsonOutput {
+		if pushed == nil {
+			pushed = []*model.Link{}
+		}
+		if jsonErr := printJSON(pushed); jsonErr != nil {
+			return jsonErr
+		}
+	} else {
+		summary := "%s%d pushed, %d unchanged%s\n"
+		if dryRun {
+			summary = "%s%d to push, %d unchanged%s\n"
+		}
+		fmt.Printf(summary, colorBold, result.Pushed, result.Unchanged, colorReset)
+	}
+	if err != nil {
+		return fmt.Errorf("push stopped early: %w", err)
+	}
+	return nil
+}
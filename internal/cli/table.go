@@ -0,0 +1,334 @@
+package cli
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+	"unicode"
+
+	"github.com/bunchhieng/rl/internal/model"
+	"github.com/bunchhieng/rl/internal/reltime"
+	"github.com/mattn/go-runewidth"
+)
+
+// Maximum column widths in terminal cells; wide characters such as CJK
+// and most emoji take two.
+const (
+	maxURLLen    = 60
+	maxTitleLen  = 40
+	maxTagsLen   = 30
+	maxDomainLen = 30
+)
+
+// displayLocation and dateLayout control how timestamps are printed;
+// relativeTimes prints them as "3h ago" instead.
+var (
+	displayLocation = time.Local
+	dateLayout      = defaultDateLayout
+	relativeTimes   bool
+)
+
+const defaultDateLayout = "2006-01-02 15:04:05 MST"
+
+// SetTimeFormat sets the time zone and Go time layout used to print
+// timestamps. A nil location or empty layout keeps the current setting.
+func SetTimeFormat(loc *time.Location, layout string) {
+	if loc != nil {
+		displayLocation = loc
+	}
+	if layout != "" {
+		dateLayout = layout
+	}
+}
+
+// SetRelativeTimes prints timestamps relative to now ("3h ago") instead
+// of in the date layout.
+func SetRelativeTimes(enabled bool) {
+	relativeTimes = enabled
+}
+
+// DisplayOptions controls how link listings are printed.
+type DisplayOptions struct {
+	// ShowDomain adds a DOMAIN column to the table.
+	ShowDomain bool
+	// ShowIndex adds a "#" column numbering the rows from 1, for use with
+	// "%N" references.
+	ShowIndex bool
+	// Plain prints borderless, untruncated columns without colors.
+	Plain bool
+	// TSV prints one tab-separated record per line in tsvColumns order.
+	TSV bool
+	// GroupBy splits the listing into sections with a header each.
+	GroupBy GroupBy
+	// Template prints each link through a user-supplied Go template
+	// instead of the table.
+	Template *template.Template
+
+	// firstIndex is added to the "#" column, so numbering can continue
+	// across the tables of a grouped listing.
+	firstIndex int
+}
+
+// tableColumn describes one column of the links table.
+type tableColumn struct {
+	header string
+	maxLen int // 0 means no truncation
+	value  func(*model.Link) string
+	color  func(*model.Link) string
+	// linked cells are clickable links to the link's URL when hyperlinks
+	// are enabled.
+	linked bool
+}
+
+func staticColor(color string) func(*model.Link) string {
+	return func(*model.Link) string { return color }
+}
+
+func linkTableColumns(links []*model.Link, opts DisplayOptions) []tableColumn {
+	var columns []tableColumn
+	if opts.ShowIndex {
+		index := make(map[*model.Link]int, len(links))
+		for i, link := range links {
+			index[link] = opts.firstIndex + i + 1
+		}
+		columns = append(columns, tableColumn{header: "#", value: func(l *model.Link) string {
+			return strconv.Itoa(index[l])
+		}, color: staticColor(colorDim)})
+	}
+	columns = append(columns, []tableColumn{
+		{header: "ID", value: func(l *model.Link) string { return l.ID }, color: staticColor(colorBold + colorCyan)},
+		{header: "PRI", value: func(l *model.Link) string {
+			label, _ := priorityLabel(l.Priority)
+			return label
+		}, color: func(l *model.Link) string {
+			_, color := priorityLabel(l.Priority)
+			return color
+		}},
+		{header: "URL", maxLen: maxURLLen, value: func(l *model.Link) string { return l.URL }, color: func(l *model.Link) string {
+			if l.IsDead() {
+				return colorRed
+			}
+			return colorCyan
+		}, linked: true},
+	}...)
+	if opts.ShowDomain {
+		columns = append(columns, tableColumn{header: "DOMAIN", maxLen: maxDomainLen, value: func(l *model.Link) string { return l.Domain }, color: staticColor(colorGreen)})
+	}
+	columns = append(columns,
+		tableColumn{header: "TITLE", maxLen: maxTitleLen, value: func(l *model.Link) string { return l.Title }, color: staticColor(""), linked: true},
+		tableColumn{header: "CREATED", value: func(l *model.Link) string { return formatTime(l.CreatedAt) }, color: staticColor(colorDim)},
+		tableColumn{header: "TIME", value: linkTime, color: staticColor(colorDim)},
+		tableColumn{header: "TAGS", maxLen: maxTagsLen, value: func(l *model.Link) string { return l.Tags }, color: staticColor(colorYellow)},
+	)
+	return columns
+}
+
+func printLinksTable(links []*model.Link, opts DisplayOptions) error {
+	columns := linkTableColumns(links, opts)
+	if opts.Plain {
+		printPlainTable(columns, links)
+		return nil
+	}
+
+	// Calculate column widths based on header and content (with limits)
+	widths := make([]int, len(columns))
+	for i, col := range columns {
+		widths[i] = len(col.header)
+		for _, link := range links {
+			n := runewidth.StringWidth(col.value(link))
+			if col.maxLen > 0 {
+				n = truncateLen(n, col.maxLen)
+			}
+			if n > widths[i] {
+				widths[i] = n
+			}
+		}
+	}
+
+	// Total width: columns plus one space of padding on each side, plus separators (│)
+	totalWidth := len(columns) - 1
+	rules := make([]string, len(columns))
+	headers := make([]string, len(columns))
+	for i, col := range columns {
+		totalWidth += widths[i] + 2
+		rules[i] = strings.Repeat("─", widths[i]+2)
+		headers[i] = fmt.Sprintf("%s%-*s%s", colorBold, widths[i], col.header, colorReset)
+	}
+
+	fmt.Printf("%s┌%s┐%s\n", colorDim, strings.Repeat("─", totalWidth), colorReset)
+	fmt.Println(tableRow(headers))
+	fmt.Printf("%s├%s┤%s\n", colorDim, strings.Join(rules, "┼"), colorReset)
+
+	for _, link := range links {
+		cells := make([]string, len(columns))
+		for i, col := range columns {
+			value := col.value(link)
+			if col.maxLen > 0 {
+				value = truncateString(value, widths[i])
+			}
+			// Pad outside the hyperlink so only the text is clickable
+			padding := pad(value, widths[i])
+			if hyperlinks && col.linked && value != "" {
+				value = hyperlink(link.URL, value)
+			}
+			if color := col.color(link); color != "" {
+				cells[i] = color + value + padding + colorReset
+			} else {
+				cells[i] = value + padding
+			}
+		}
+		fmt.Println(tableRow(cells))
+	}
+
+	fmt.Printf("%s└%s┘%s\n", colorDim, strings.Repeat("─", totalWidth), colorReset)
+	return nil
+}
+
+// printPlainTable prints columns separated by two spaces, with no borders,
+// colors, or truncation.
+func printPlainTable(columns []tableColumn, links []*model.Link) {
+	widths := make([]int, len(columns))
+	for i, col := range columns {
+		widths[i] = len(col.header)
+		for _, link := range links {
+			if n := runewidth.StringWidth(col.value(link)); n > widths[i] {
+				widths[i] = n
+			}
+		}
+	}
+
+	printRow := func(value func(i int) string) {
+		cells := make([]string, len(columns))
+		for i := range columns {
+			cells[i] = value(i) + pad(value(i), widths[i])
+		}
+		fmt.Println(strings.TrimRight(strings.Join(cells, "  "), " "))
+	}
+	printRow(func(i int) string { return columns[i].header })
+	for _, link := range links {
+		printRow(func(i int) string { return columns[i].value(link) })
+	}
+}
+
+// tsvColumns is the stable field order of TSV output. New fields are only
+// ever appended so scripts using cut/awk keep working.
+var tsvColumns = []func(*model.Link) string{
+	func(l *model.Link) string { return l.ID },
+	func(l *model.Link) string { return l.URL },
+	func(l *model.Link) string { return l.Title },
+	func(l *model.Link) string { return l.Tags },
+	func(l *model.Link) string { return l.Priority.String() },
+	func(l *model.Link) string { return formatTSVTime(&l.CreatedAt) },
+	func(l *model.Link) string { return formatTSVTime(l.ReadAt) },
+	func(l *model.Link) string { return l.Domain },
+	func(l *model.Link) string { return strconv.Itoa(l.ReadingSeconds) },
+	func(l *model.Link) string { return l.MediaType },
+}
+
+// tsvEscaper keeps each record on one line with a fixed number of fields.
+var tsvEscaper = strings.NewReplacer("\t", " ", "\n", " ", "\r", " ")
+
+func printTSV(links []*model.Link) {
+	fields := make([]string, len(tsvColumns))
+	for _, link := range links {
+		for i, value := range tsvColumns {
+			fields[i] = tsvEscaper.Replace(value(link))
+		}
+		fmt.Println(strings.Join(fields, "\t"))
+	}
+}
+
+func formatTSVTime(t *time.Time) string {
+	if t == nil || t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// hyperlink wraps text in an OSC 8 escape sequence linking it to url.
+// Terminals without support print just the text. Control characters are
+// dropped from url so it can't end the sequence early.
+func hyperlink(url, text string) string {
+	url = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, url)
+	return "\x1b]8;;" + url + "\x1b\\" + text + "\x1b]8;;\x1b\\"
+}
+
+func tableRow(cells []string) string {
+	border := colorDim + "│" + colorReset
+	return border + " " + strings.Join(cells, " │ ") + " " + border
+}
+
+// priorityLabel returns the short table label and color for a priority.
+func priorityLabel(p model.Priority) (string, string) {
+	switch p {
+	case model.PriorityHigh:
+		return "!", colorRed + colorBold
+	case model.PriorityLow:
+		return "↓", colorDim
+	default:
+		return "", ""
+	}
+}
+
+func truncateLen(n, max int) int {
+	if n > max {
+		return max
+	}
+	return n
+}
+
+// truncateString shortens s to at most width terminal cells, ending it
+// with "..." if anything was cut. It never splits a character.
+func truncateString(s string, width int) string {
+	return runewidth.Truncate(s, width, "...")
+}
+
+// pad returns the spaces that fill s out to width terminal cells. fmt's
+// width verbs count runes, which misaligns wide characters.
+func pad(s string, width int) string {
+	return strings.Repeat(" ", max(0, width-runewidth.StringWidth(s)))
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	if relativeTimes {
+		return reltime.Format(t, time.Now())
+	}
+	return t.In(displayLocation).Format(dateLayout)
+}
+
+// linkTime formats a link's reading time, marking video and audio so their
+// running time isn't mistaken for time to read.
+func linkTime(l *model.Link) string {
+	if l.ReadingSeconds <= 0 {
+		return "-"
+	}
+	return l.MediaMark() + formatReadingTime(l.ReadingTime())
+}
+
+// formatReadingTime formats an estimated reading time compactly ("7m", "1h20m").
+func formatReadingTime(d time.Duration) string {
+	if d <= 0 {
+		return "-"
+	}
+	minutes := int(d.Round(time.Minute) / time.Minute)
+	if minutes < 1 {
+		minutes = 1
+	}
+	if minutes < 60 {
+		return fmt.Sprintf("%dm", minutes)
+	}
+	if minutes%60 == 0 {
+		return fmt.Sprintf("%dh", minutes/60)
+	}
+	return fmt.Sprintf("%dh%dm", minutes/60, minutes%60)
+}
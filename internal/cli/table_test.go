@@ -0,0 +1,84 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/bunchhieng/rl/internal/model"
+	"github.com/mattn/go-runewidth"
+)
+
+func TestTruncateString(t *testing.T) {
+	tests := []struct {
+		in    string
+		width int
+		want  string
+	}{
+		{"short", 10, "short"},
+		{"exactly ten", 11, "exactly ten"},
+		{"a long ascii title", 10, "a long ..."},
+		{"日本語のタイトルです", 10, "日本語..."},
+		{"café crème brûlée", 10, "café cr..."},
+		{"🚀 launch notes", 8, "🚀 la..."},
+	}
+	for _, tt := range tests {
+		got := truncateString(tt.in, tt.width)
+		if got != tt.want {
+			t.Errorf("truncateString(%q, %d) = %q, want %q", tt.in, tt.width, got, tt.want)
+		}
+		if !utf8.ValidString(got) || runewidth.StringWidth(got) > tt.width {
+			t.Errorf("truncateString(%q, %d) = %q is invalid or too wide", tt.in, tt.width, got)
+		}
+		if w := runewidth.StringWidth(got + pad(got, tt.width)); w != tt.width {
+			t.Errorf("padded %q is %d cells wide, want %d", got, w, tt.width)
+		}
+	}
+}
+
+func TestHyperlink(t *testing.T) {
+	if got, want := hyperlink("https://example.com", "Example"), "\x1b]8;;https://example.com\x1b\\Example\x1b]8;;\x1b\\"; got != want {
+		t.Errorf("hyperlink() = %q, want %q", got, want)
+	}
+	// Control characters can't end the escape sequence early
+	if got := hyperlink("https://example.com/\x1b\\\x07\n", "x"); strings.Count(got, "\x1b") != 4 || strings.ContainsAny(got, "\x07\n") {
+		t.Errorf("Expected control characters dropped from the URL, got %q", got)
+	}
+
+	// Tables link titles and URLs only when enabled, padding outside the link
+	links := []*model.Link{{ID: "abc123", URL: "https://example.com", Title: "Example"}}
+	for _, enabled := range []bool{false, true} {
+		SetHyperlinks(enabled)
+		out, err := captureStdout(t, func() error { return printLinksTable(links, DisplayOptions{}) })
+		if err != nil {
+			t.Fatal(err)
+		}
+		linked := strings.Contains(out, "\x1b]8;;https://example.com\x1b\\Example\x1b]8;;\x1b\\ ")
+		if linked != enabled {
+			t.Errorf("With hyperlinks %v, got table:\n%q", enabled, out)
+		}
+	}
+	SetHyperlinks(false)
+}
+
+func TestPrintTSV(t *testing.T) {
+	readAt := time.Date(2024, 1, 2, 10, 30, 0, 0, time.FixedZone("EST", -5*3600))
+	links := []*model.Link{
+		{ID: "a", URL: "https://go.dev/blog", Title: "Tabs\tand\nnewlines", Tags: "go,blog", Priority: model.PriorityHigh,
+			CreatedAt: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC), ReadAt: &readAt, Domain: "go.dev", ReadingSeconds: 480},
+		{ID: "b", URL: "https://youtu.be/x", CreatedAt: time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC), MediaType: model.MediaVideo},
+	}
+	out, err := captureStdout(t, func() error {
+		printTSV(links)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "a\thttps://go.dev/blog\tTabs and newlines\tgo,blog\thigh\t2024-01-01T12:00:00Z\t2024-01-02T15:30:00Z\tgo.dev\t480\t\n" +
+		"b\thttps://youtu.be/x\t\t\tnormal\t2024-01-03T00:00:00Z\t\t\t0\tvideo\n"
+	if out != want {
+		t.Errorf("printTSV printed\n%q\nwant\n%q", out, want)
+	}
+}
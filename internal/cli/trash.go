@@ -0,0 +1,103 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/bunchhieng/rl/internal/model"
+	"github.com/bunchhieng/rl/internal/storage"
+)
+
+// TrashList lists the links in the trash, most recently trashed first.
+func (c *Commands) TrashList() error {
+	links, err := c.trashedBefore(time.Time{})
+	if err != nil {
+		return err
+	}
+	if len(links) == 0 && !c.jsonOutput {
+		fmt.Println("The trash is empty.")
+		return nil
+	}
+	return c.printListing(links, DisplayOptions{})
+}
+
+// Restore takes links out of the trash.
+func (c *Commands) Restore(ids ...string) error {
+	return c.forEachID(ids, "restore", func(id string) error {
+		id, err := c.resolveID(id)
+		if err != nil {
+			return err
+		}
+		link, err := c.storage.Get(c.ctx, id)
+		if err != nil {
+			return c.handleNotFound(err, id, "restore")
+		}
+		if link.TrashedAt == nil {
+			return fmt.Errorf("link %s is not in the trash", id)
+		}
+		if err := c.storage.Restore(c.ctx, id); err != nil {
+			return c.handleNotFound(err, id, "restore")
+		}
+		fmt.Printf("%sRestored%s link %s%s%s.\n", colorGreen, colorReset, colorBold, id, colorReset)
+		return nil
+	})
+}
+
+// EmptyTrash deletes the links in the trash for good, or with olderThan
+// only those trashed longer ago than that.
+func (c *Commands) EmptyTrash(olderThan time.Duration) error {
+	var cutoff time.Time
+	if olderThan > 0 {
+		cutoff = time.Now().Add(-olderThan)
+	}
+	links, err := c.trashedBefore(cutoff)
+	if err != nil {
+		return err
+	}
+	deleted, failed := c.purge(links)
+	fmt.Printf("%sDeleted%s %s from the trash.\n", colorRed, colorReset, plural(deleted, "link"))
+	if failed > 0 {
+		return fmt.Errorf("%d link(s) could not be deleted", failed)
+	}
+	return nil
+}
+
+// trashedBefore returns the links trashed before cutoff, most recently
+// trashed first; a zero cutoff returns the whole trash.
+func (c *Commands) trashedBefore(cutoff time.Time) ([]*model.Link, error) {
+	links, err := c.storage.List(c.ctx, storage.ListOptions{
+		ReadStatus: storage.ReadStatusAll,
+		Trashed:    true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list trash: %w", err)
+	}
+	kept := links[:0]
+	for _, link := range links {
+		if cutoff.IsZero() || link.TrashedAt.Before(cutoff) {
+			kept = append(kept, link)
+		}
+	}
+	sort.SliceStable(kept, func(i, j int) bool { return kept[i].TrashedAt.After(*kept[j].TrashedAt) })
+	return kept, nil
+}
+
+// purge deletes links for good, then the attachments no other link uses,
+// returning how many were deleted and how many failed.
+func (c *Commands) purge(links []*model.Link) (deleted, failed int) {
+	for _, link := range links {
+		if err := c.storage.Delete(c.ctx, link.ID); err != nil {
+			failed++
+			continue
+		}
+		deleted++
+	}
+	if deleted > 0 {
+		if err := c.pruneAttachments(); err != nil {
+			fmt.Fprintf(os.Stderr, "%sWarning:%s could not remove unused attachments: %v\n", colorYellow, colorReset, err)
+		}
+	}
+	return deleted, failed
+}
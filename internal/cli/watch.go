@@ -0,0 +1,203 @@
+package cli
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/bunchhieng/rl/internal/clipboard"
+	"github.com/bunchhieng/rl/internal/model"
+)
+
+const (
+	// watchInterval is how often Watch reads the clipboard.
+	watchInterval = 500 * time.Millisecond
+	// watchQueue is how many copied URLs can wait for a prompt or a fetch
+	// before more are dropped.
+	watchQueue = 100
+)
+
+// WatchOptions configures Watch.
+type WatchOptions struct {
+	// Add applies to every link saved.
+	Add AddOptions
+	// Prompt is read for confirmation before each URL is saved; nil saves
+	// them without asking.
+	Prompt io.Reader
+}
+
+// Watch reads the clipboard until interrupted and saves each URL copied,
+// after asking unless opts.Prompt is nil. What's on the clipboard when it
+// starts is left alone, as are URLs already saved. URLs copied while
+// another is being asked about or fetched wait their turn.
+func (c *Commands) Watch(opts WatchOptions) error {
+	last, err := clipboard.Read()
+	if err != nil {
+		return err
+	}
+
+	// Prompts and saves run one at a time, apart from the polling so a
+	// slow fetch doesn't miss the next copy
+	queue := make(chan string, watchQueue)
+	done := make(chan int)
+	go func() {
+		done <- c.watchSave(queue, opts)
+	}()
+
+	if !c.jsonOutput {
+		mode := "asking before saving each"
+		if opts.Prompt == nil {
+			mode = "saving each"
+		}
+		fmt.Printf("Watching the clipboard, %s URL copied. Press Ctrl-C to stop.\n", mode)
+	}
+	ticker := time.NewTicker(watchInterval)
+	defer ticker.Stop()
+	var lastErr string
+	for c.ctx.Err() == nil {
+		select {
+		case <-c.ctx.Done():
+			continue
+		case <-ticker.C:
+		}
+		text, err := clipboard.Read()
+		if err != nil {
+			// Report a failing clipboard once rather than twice a second
+			if err.Error() != lastErr {
+				fmt.Fprintf(os.Stderr, "%sWarning:%s %v\n", colorYellow, colorReset, err)
+				lastErr = err.Error()
+			}
+			continue
+		}
+		lastErr = ""
+		if text == last {
+			continue
+		}
+		last = text
+		url, err := clipboard.ParseURL(text)
+		if err != nil {
+			continue
+		}
+		enqueue(queue, url)
+	}
+
+	close(queue)
+	saved := <-done
+	if !c.jsonOutput {
+		fmt.Printf("\nSaved %s.\n", plural(saved, "link"))
+	}
+	return nil
+}
+
+// enqueue sends url to be saved, or drops it with a warning if the queue
+// is full rather than hold up reading the clipboard.
+func enqueue(queue chan<- string, url string) {
+	select {
+	case queue <- url:
+	default:
+		fmt.Fprintf(os.Stderr, "%sWarning:%s too many URLs waiting, skipped %s\n", colorYellow, colorReset, url)
+	}
+}
+
+// watchSave saves the URLs sent on queue until it is closed or the
+// command interrupted, asking first if opts.Prompt is set, and returns
+// how many it saved.
+func (c *Commands) watchSave(queue <-chan string, opts WatchOptions) int {
+	var answers <-chan string
+	if opts.Prompt != nil {
+		answers = readLines(opts.Prompt)
+	}
+	// Prompts go to standard error when standard output carries JSON
+	out := io.Writer(os.Stdout)
+	if c.jsonOutput {
+		out = os.Stderr
+	}
+
+	saved := 0
+	seen := map[string]bool{}
+	for url := range queue {
+		if c.ctx.Err() != nil {
+			continue
+		}
+		if seen[url] {
+			continue
+		}
+		seen[url] = true
+		if existing := c.savedLink(url, opts.Add.Canonicalize); existing != nil {
+			if !c.jsonOutput {
+				fmt.Printf("%sAlready saved:%s %s %s(%s)%s\n", colorDim, colorReset, displayTitle(existing), colorDim, existing.ID, colorReset)
+			}
+			continue
+		}
+
+		if answers != nil {
+			fmt.Fprintf(out, "Save %s%s%s? [Y/n] ", colorCyan, url, colorReset)
+			var answer string
+			var ok bool
+			select {
+			case <-c.ctx.Done():
+				continue
+			case answer, ok = <-answers:
+			}
+			// Nothing left to read answers no
+			if a := strings.ToLower(strings.TrimSpace(answer)); !ok || (a != "" && a != "y" && a != "yes") {
+				fmt.Fprintln(out, "Skipped.")
+				continue
+			}
+		}
+
+		result, err := c.addLink(url, opts.Add)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%sSkipped%s %s: %v\n", colorRed, colorReset, url, err)
+			continue
+		}
+		saved++
+		if c.jsonOutput {
+			if err := json.NewEncoder(os.Stdout).Encode(result.Link); err != nil {
+				fmt.Fprintf(os.Stderr, "%sWarning:%s encode JSON: %v\n", colorYellow, colorReset, err)
+			}
+			continue
+		}
+		printAdded(result)
+	}
+	return saved
+}
+
+// savedLink returns the link already saved for url, or nil.
+func (c *Commands) savedLink(url string, canonicalize bool) *model.Link {
+	if canonicalize {
+		if canonical, err := model.CanonicalizeURL(url); err == nil {
+			url = canonical
+		}
+	}
+	link, err := c.storage.FindByURL(c.ctx, url)
+	if err != nil {
+		return nil
+	}
+	return link
+}
+
+// readLines sends each line read from r, without its newline, until r
+// ends. A read blocked on a terminal can't be interrupted, so it runs
+// apart from whoever waits for the answers.
+func readLines(r io.Reader) <-chan string {
+	lines := make(chan string)
+	go func() {
+		defer close(lines)
+		reader := bufio.NewReader(r)
+		for {
+			line, err := reader.ReadString('\n')
+			if line != "" || err == nil {
+				lines <- strings.TrimSuffix(line, "\n")
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+	return lines
+}
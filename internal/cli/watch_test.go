@@ -0,0 +1,194 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bunchhieng/rl/internal/model"
+	"github.com/bunchhieng/rl/internal/storage"
+)
+
+// fakeClipboard installs an xclip stub like the clipboard package's tests
+// do. It returns a function that changes what the stub prints and one
+// that reports whether it has been read.
+func fakeClipboard(t *testing.T, text string) (set func(string), read func() bool) {
+	t.Helper()
+	if runtime.GOOS != "linux" {
+		t.Skip("clipboard stub uses the Linux tool names")
+	}
+	dir := t.TempDir()
+	data, reads := filepath.Join(dir, "data"), filepath.Join(dir, "reads")
+	set = func(text string) {
+		// Renamed into place so a read never sees a half-written file
+		tmp := data + ".tmp"
+		if err := os.WriteFile(tmp, []byte(text), 0644); err != nil {
+			t.Error(err)
+		}
+		if err := os.Rename(tmp, data); err != nil {
+			t.Error(err)
+		}
+	}
+	set(text)
+	script := "#!/bin/sh\ncat " + data + "\necho >> " + reads + "\n"
+	if err := os.WriteFile(filepath.Join(dir, "xclip"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv("WAYLAND_DISPLAY", "")
+	read = func() bool {
+		_, err := os.Stat(reads)
+		return err == nil
+	}
+	return set, read
+}
+
+// savedURLs returns the URLs of every saved link, sorted.
+func savedURLs(t *testing.T, s storage.Storage) []string {
+	t.Helper()
+	links, err := s.List(context.Background(), storage.ListOptions{ReadStatus: storage.ReadStatusAll})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var urls []string
+	for _, l := range links {
+		urls = append(urls, l.URL)
+	}
+	sort.Strings(urls)
+	return urls
+}
+
+func TestWatch(t *testing.T) {
+	c, s := testCommands(t)
+	setClipboard, clipboardRead := fakeClipboard(t, "https://example.com/before")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	c.SetContext(ctx)
+
+	done := make(chan error)
+	go func() {
+		_, err := captureStdout(t, func() error { return c.Watch(WatchOptions{}) })
+		done <- err
+	}()
+
+	// Copy once Watch has read what was there when it started
+	deadline := time.Now().Add(5 * time.Second)
+	for !clipboardRead() && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	setClipboard("https://example.com/copied")
+	for len(savedURLs(t, s)) == 0 && time.Now().Before(deadline) {
+		time.Sleep(50 * time.Millisecond)
+	}
+	// Another interval to be sure the starting URL isn't saved late
+	time.Sleep(watchInterval + 100*time.Millisecond)
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("Watch failed: %v", err)
+	}
+
+	if got, want := savedURLs(t, s), []string{"https://example.com/copied"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("saved %q, want %q without what was on the clipboard at the start", got, want)
+	}
+}
+
+func TestWatchSave(t *testing.T) {
+	c, s := testCommands(t)
+	addLink(t, s, &model.Link{URL: "https://example.com/saved", Title: "Saved before"})
+
+	urls := []string{
+		"https://example.com/a", // y
+		"https://example.com/a", // copied again: not asked twice
+		"https://example.com/b", // empty answer means yes
+		"https://example.com/saved",
+		"https://example.com/c", // n
+		"https://example.com/d", // no answers left
+	}
+	queue := make(chan string, len(urls))
+	for _, url := range urls {
+		queue <- url
+	}
+	close(queue)
+
+	var saved int
+	out, _ := captureStdout(t, func() error {
+		saved = c.watchSave(queue, WatchOptions{Prompt: strings.NewReader("y\n\nn\n")})
+		return nil
+	})
+
+	if saved != 2 {
+		t.Errorf("watchSave saved %d, want 2", saved)
+	}
+	if n := strings.Count(out, "? [Y/n] "); n != 4 {
+		t.Errorf("asked %d times, want 4:\n%s", n, out)
+	}
+	if n := strings.Count(out, "Skipped."); n != 2 {
+		t.Errorf("skipped %d, want c and d:\n%s", n, out)
+	}
+	if !strings.Contains(out, "Already saved: Saved before") {
+		t.Errorf("output doesn't mention the link saved before:\n%s", out)
+	}
+	want := []string{"https://example.com/a", "https://example.com/b", "https://example.com/saved"}
+	if got := savedURLs(t, s); !reflect.DeepEqual(got, want) {
+		t.Errorf("saved %q, want %q", got, want)
+	}
+}
+
+func TestWatchSaveWithoutPrompt(t *testing.T) {
+	c, s := testCommands(t)
+	queue := make(chan string, 2)
+	queue <- "https://example.com/a"
+	queue <- "https://example.com/b"
+	close(queue)
+
+	var saved int
+	out, _ := captureStdout(t, func() error {
+		saved = c.watchSave(queue, WatchOptions{})
+		return nil
+	})
+	if saved != 2 || strings.Contains(out, "[Y/n]") {
+		t.Errorf("saved %d with output %q, want both saved without asking", saved, out)
+	}
+	if got := savedURLs(t, s); len(got) != 2 {
+		t.Errorf("saved %q, want both", got)
+	}
+}
+
+func TestEnqueueFull(t *testing.T) {
+	queue := make(chan string, 1)
+	enqueue(queue, "https://example.com/a")
+	enqueue(queue, "https://example.com/b")
+	close(queue)
+
+	var got []string
+	for url := range queue {
+		got = append(got, url)
+	}
+	if want := []string{"https://example.com/a"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("queued %q, want %q with the second dropped", got, want)
+	}
+}
+
+func TestReadLines(t *testing.T) {
+	tests := map[string][]string{
+		"":             nil,
+		"y\n":          {"y"},
+		"y\n\nno":      {"y", "", "no"},
+		"yes\nn\n\n\n": {"yes", "n", "", ""},
+	}
+	for in, want := range tests {
+		var got []string
+		for line := range readLines(strings.NewReader(in)) {
+			got = append(got, line)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("readLines(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package clipboard
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// ErrUnavailable is returned when no clipboard tool is installed.
+var ErrUnavailable = errors.New("no clipboard tool found (install wl-clipboard, xclip, or xsel)")
+
+// writers returns the commands that can write stdin to the clipboard on
+// this platform, in order of preference.
+func writers() [][]string {
+	switch runtime.GOOS {
+	case "darwin":
+		return [][]string{{"pbcopy"}}
+	case "windows":
+		return [][]string{{"clip"}}
+	default:
+		var cmds [][]string
+		if os.Getenv("WAYLAND_DISPLAY") != "" {
+			cmds = append(cmds, []string{"wl-copy"})
+		}
+		return append(cmds,
+			[]string{"xclip", "-selection", "clipboard"},
+			[]string{"xsel", "--clipboard", "--input"},
+		)
+	}
+}
+
+// readers returns the commands that print the clipboard on this platform,
+// in order of preference.
+func readers() [][]string {
+	switch runtime.GOOS {
+	case "darwin":
+		return [][]string{{"pbpaste"}}
+	case "windows":
+		return [][]string{{"powershell", "-NoProfile", "-Command", "Get-Clipboard"}}
+	default:
+		var cmds [][]string
+		if os.Getenv("WAYLAND_DISPLAY") != "" {
+			cmds = append(cmds, []string{"wl-paste", "--no-newline"})
+		}
+		return append(cmds,
+			[]string{"xclip", "-selection", "clipboard", "-o"},
+			[]string{"xsel", "--clipboard", "--output"},
+		)
+	}
+}
+
+// command returns the first of candidates that is installed.
+func command(candidates [][]string) (*exec.Cmd, error) {
+	for _, args := range candidates {
+		if path, err := exec.LookPath(args[0]); err == nil {
+			return exec.Command(path, args[1:]...), nil
+		}
+	}
+	return nil, ErrUnavailable
+}
+
+// Write copies text to the system clipboard using the first available
+// platform tool.
+func Write(text string) error {
+	cmd, err := command(writers())
+	if err != nil {
+		return err
+	}
+	cmd.Stdin = strings.NewReader(text)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("copy to clipboard: %s: %w", strings.TrimSpace(string(out)), err)
+	}
+	return nil
+}
+
+// Read returns the text on the system clipboard.
+func Read() (string, error) {
+	cmd, err := command(readers())
+	if err != nil {
+		return "", err
+	}
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("read clipboard: %s: %w", strings.TrimSpace(stderr.String()), err)
+	}
+	return string(out), nil
+}
+
+// ReadURL returns the clipboard contents if they are a single http(s) URL.
+func ReadURL() (string, error) {
+	text, err := Read()
+	if err != nil {
+		return "", err
+	}
+	return ParseURL(text)
+}
+
+// ParseURL returns text trimmed if it is a single http(s) URL, as copied
+// from a browser's address bar.
+func ParseURL(text string) (string, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return "", errors.New("clipboard is empty")
+	}
+	u, err := url.Parse(text)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || strings.ContainsAny(text, " \t\n") {
+		return "", fmt.Errorf("clipboard does not contain a URL: %q", truncate(text, 60))
+	}
+	return text, nil
+}
+
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n]) + "..."
+}
@@ -0,0 +1,49 @@
+package clipboard
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+// fakeClipboard installs an xclip stub that prints text, so Read and
+// ReadURL can run without a display.
+func fakeClipboard(t *testing.T, text string) {
+	t.Helper()
+	if runtime.GOOS != "linux" {
+		t.Skip("clipboard stub uses the Linux tool names")
+	}
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "data"), []byte(text), 0644); err != nil {
+		t.Fatal(err)
+	}
+	script := "#!/bin/sh\ncat " + filepath.Join(dir, "data") + "\n"
+	if err := os.WriteFile(filepath.Join(dir, "xclip"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv("WAYLAND_DISPLAY", "")
+}
+
+func TestReadURL(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+		ok   bool
+	}{
+		{"https://example.com/a\n", "https://example.com/a", true},
+		{"  http://go.dev  ", "http://go.dev", true},
+		{"not a url", "", false},
+		{"ftp://example.com", "", false},
+		{"https://a.com https://b.com", "", false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		fakeClipboard(t, tt.text)
+		got, err := ReadURL()
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("ReadURL() with %q = %q, %v; want %q, ok=%v", tt.text, got, err, tt.want, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,426 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/bunchhieng/rl/internal/model"
+)
+
+// Config holds user defaults read from config.toml. Command-line flags and
+// environment variables take precedence over these values.
+type Config struct {
+	// DBPath overrides the default database location. A leading "~/" is
+	// expanded to the home directory.
+	DBPath string `toml:"db_path"`
+
+	// DBTimeout limits how long a single database operation may take, as a
+	// duration ("30s"). Empty means no limit.
+	DBTimeout string `toml:"db_timeout"`
+
+	// IDScheme is how new links' IDs are made: "random" (the default) or
+	// "ulid", which sort by when their link was saved.
+	IDScheme string `toml:"id_scheme"`
+
+	// IDLength is the length of new links' random IDs, from 8 to 26.
+	// Shorter IDs are easier to type. Zero means the default of 26.
+	IDLength int `toml:"id_length"`
+
+	// Color is "auto" (color only on a terminal), "always", or "never".
+	Color string `toml:"color"`
+
+	// Hyperlinks is "auto" (clickable links in terminals known to support
+	// them), "always", or "never".
+	Hyperlinks string `toml:"hyperlinks"`
+
+	// Timezone is an IANA zone name ("Europe/Berlin", "UTC") for displayed
+	// times and for dates given on the command line. Empty means the local
+	// time zone.
+	Timezone string `toml:"timezone"`
+
+	// DateFormat is a Go time layout ("2006-01-02 15:04") for displayed
+	// times. Empty keeps each view's default.
+	DateFormat string `toml:"date_format"`
+
+	// RelativeTimes shows times in listings and the TUI as "3h ago"
+	// instead of in DateFormat.
+	RelativeTimes bool `toml:"relative_times"`
+
+	// Browser is the command used to open links, e.g. "firefox --new-tab %s".
+	// Empty means $BROWSER, then the platform default.
+	Browser string `toml:"browser"`
+
+	List ListConfig `toml:"list"`
+	Add  AddConfig  `toml:"add"`
+	Open OpenConfig `toml:"open"`
+
+	// Theme is the TUI color theme: "dark", "light", or "solarized".
+	Theme string `toml:"theme"`
+
+	// Colors overrides individual theme colors with hex values, e.g.
+	// accent = "#5f5fd7".
+	Colors map[string]string `toml:"colors"`
+
+	// Keys remaps TUI actions to keys, e.g. down = ["ctrl+n", "down"].
+	// Actions and conflicts are checked when the TUI starts.
+	Keys map[string][]string `toml:"keys"`
+
+	// Webhooks receive a JSON POST when links are added, read, or deleted.
+	Webhooks []WebhookConfig `toml:"webhooks"`
+
+	// Rules tag links automatically as they're added.
+	Rules []RuleConfig `toml:"rules"`
+
+	// Wallabag is the instance `rl sync wallabag` mirrors links with.
+	Wallabag WallabagConfig `toml:"wallabag"`
+
+	// Pinboard is the account `rl push pinboard` backs links up to.
+	Pinboard PinboardConfig `toml:"pinboard"`
+
+	// LLM is the language model `rl summarize` and `rl suggest-tags` ask,
+	// and the embedding model behind `rl grep --semantic`.
+	LLM LLMConfig `toml:"llm"`
+
+	// Summarize is the [summarize] section that held the model before
+	// [llm] did; Load still reads it into LLM so older files keep working.
+	Summarize SummarizeConfig `toml:"summarize"`
+
+	// Goals sets reading targets for `rl streak` and the TUI header.
+	Goals GoalsConfig `toml:"goals"`
+
+	// Cleanup holds retention policies applied by `rl cleanup`.
+	Cleanup CleanupConfig `toml:"cleanup"`
+
+	// Backup controls snapshots of the database.
+	Backup BackupConfig `toml:"backup"`
+
+	// Daemon schedules the jobs `rl daemon` runs.
+	Daemon DaemonConfig `toml:"daemon"`
+}
+
+// DaemonJobs are the jobs `rl daemon` can run.
+var DaemonJobs = []string{"backfill", "check", "cleanup", "backup", "remind"}
+
+// unsupportedDaemonJobs are jobs rl has nothing to run for, with why, so
+// scheduling one says so instead of being taken for a typo.
+var unsupportedDaemonJobs = map[string]string{
+	"feeds":  "rl doesn't subscribe to feeds, so there are none to poll",
+	"digest": "rl doesn't build digests; rl next and rl stats summarize the queue",
+}
+
+// DaemonConfig schedules the jobs `rl daemon` runs.
+type DaemonConfig struct {
+	// Jobs maps each job to run to how often it runs, e.g.
+	// backfill = "6h" or check = "1d".
+	Jobs map[string]string `toml:"jobs"`
+	// Log is the file the daemon appends its JSON log lines to. A leading
+	// "~/" is expanded; empty means standard error.
+	Log string `toml:"log"`
+}
+
+// BackupConfig controls snapshots of the database, which are also taken
+// before migrations and imports.
+type BackupConfig struct {
+	// Keep is how many snapshots to keep; zero means 7.
+	Keep int `toml:"keep"`
+	// EveryDays takes a snapshot before the first command or TUI once the
+	// newest is this many days old. Zero turns scheduled backups off.
+	EveryDays int `toml:"every_days"`
+}
+
+// CleanupConfig holds retention policies. A zero number of days turns a
+// policy off.
+type CleanupConfig struct {
+	// ArchiveReadAfterDays archives links read this many days ago.
+	ArchiveReadAfterDays int `toml:"archive_read_after_days"`
+	// DeleteTrashedAfterDays deletes links for good once they've been in
+	// the trash this many days.
+	DeleteTrashedAfterDays int `toml:"delete_trashed_after_days"`
+	// OnStartup applies the policies before every command and the TUI,
+	// not just `rl cleanup`.
+	OnStartup bool `toml:"on_startup"`
+}
+
+// GoalsConfig holds reading targets.
+type GoalsConfig struct {
+	// Weekly is the number of links to read each week (Monday to
+	// Sunday); 0 means no goal.
+	Weekly int `toml:"weekly"`
+}
+
+// PinboardConfig holds the Pinboard API token ("user:HEX", from the
+// password settings page).
+type PinboardConfig struct {
+	Token string `toml:"token"`
+}
+
+// LLMConfig is an OpenAI-compatible chat completions API, such as
+// OpenAI's or a local Ollama's.
+type LLMConfig struct {
+	// Endpoint is the API's base URL; empty means Ollama on this machine.
+	Endpoint string `toml:"endpoint"`
+	Model    string `toml:"model"`
+	// EmbeddingModel indexes links for `rl grep --semantic`.
+	EmbeddingModel string `toml:"embedding_model"`
+	// APIKey is sent as a bearer token; local servers need none.
+	APIKey string `toml:"api_key"`
+	// SummaryPrompt replaces the instructions sent with each article
+	// to summarize.
+	SummaryPrompt string `toml:"summary_prompt"`
+}
+
+// SummarizeConfig is the old [summarize] section, now [llm], whose
+// prompt key became summary_prompt.
+type SummarizeConfig struct {
+	Endpoint string `toml:"endpoint"`
+	Model    string `toml:"model"`
+	APIKey   string `toml:"api_key"`
+	Prompt   string `toml:"prompt"`
+}
+
+// inherit fills the LLM settings left empty from the old [summarize]
+// section; those set under [llm] win.
+func (l *LLMConfig) inherit(old SummarizeConfig) {
+	for _, f := range []struct {
+		to   *string
+		from string
+	}{
+		{&l.Endpoint, old.Endpoint},
+		{&l.Model, old.Model},
+		{&l.APIKey, old.APIKey},
+		{&l.SummaryPrompt, old.Prompt},
+	} {
+		if *f.to == "" {
+			*f.to = f.from
+		}
+	}
+}
+
+// WallabagConfig holds Wallabag API credentials. The client ID and secret
+// come from the instance's "API clients management" page; token may be
+// given instead of the login to use an existing access token.
+type WallabagConfig struct {
+	URL          string `toml:"url"`
+	ClientID     string `toml:"client_id"`
+	ClientSecret string `toml:"client_secret"`
+	Username     string `toml:"username"`
+	Password     string `toml:"password"`
+	Token        string `toml:"token"`
+}
+
+// WebhookConfig is one [[webhooks]] entry.
+type WebhookConfig struct {
+	URL string `toml:"url"`
+	// Events limits the hook to "added", "read", and "deleted"; empty
+	// means all of them.
+	Events []string `toml:"events"`
+	// Headers are sent with every request, e.g. an Authorization token.
+	Headers map[string]string `toml:"headers"`
+}
+
+// RuleConfig is one [[rules]] entry: tags added to links matching all of
+// its conditions.
+type RuleConfig struct {
+	// Domain matches the host and its subdomains, e.g. "github.com".
+	Domain string `toml:"domain"`
+	// URL is a pattern for the whole URL, * matching anything.
+	URL string `toml:"url"`
+	// Title is a word or phrase in the title, ignoring case.
+	Title string   `toml:"title"`
+	Tags  []string `toml:"tags"`
+}
+
+// ListConfig holds defaults for `rl ls`.
+type ListConfig struct {
+	// Filter is the read status shown without --read/--all:
+	// "unread", "read", or "all".
+	Filter string `toml:"filter"`
+	// Limit caps the number of results; 0 means no limit.
+	Limit int `toml:"limit"`
+}
+
+// AddConfig holds defaults for `rl add`.
+type AddConfig struct {
+	// Fetch downloads the page title and reading time.
+	Fetch bool `toml:"fetch"`
+	// Canonicalize strips tracking parameters and normalizes URLs.
+	Canonicalize bool `toml:"canonicalize"`
+	// Attach keeps a copy of URLs that point to PDFs and other documents.
+	Attach bool `toml:"attach"`
+}
+
+// OpenConfig holds defaults for `rl open`.
+type OpenConfig struct {
+	// MarkDone marks links as read when they are opened.
+	MarkDone bool `toml:"mark_done"`
+}
+
+// Default returns the configuration used when no config file exists.
+func Default() *Config {
+	return &Config{
+		Color:      "auto",
+		Hyperlinks: "auto",
+		List:       ListConfig{Filter: "unread"},
+		Add:        AddConfig{Fetch: true, Canonicalize: true},
+	}
+}
+
+// DefaultPath returns the config file location in the platform's config
+// directory, next to the default database.
+func DefaultPath() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "rl", "config.toml"), nil
+}
+
+// Load reads the config file at path over the defaults. A missing file is
+// not an error.
+func Load(path string) (*Config, error) {
+	cfg := Default()
+	meta, err := toml.DecodeFile(path, cfg)
+	if errors.Is(err, os.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read config %s: %w", path, err)
+	}
+	if undecoded := meta.Undecoded(); len(undecoded) > 0 {
+		return nil, fmt.Errorf("read config %s: unknown key %q", path, undecoded[0].String())
+	}
+	if err := cfg.validate(); err != nil {
+		return nil, fmt.Errorf("read config %s: %w", path, err)
+	}
+	cfg.LLM.inherit(cfg.Summarize)
+	cfg.DBPath, err = ExpandHome(cfg.DBPath)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Daemon.Log, err = ExpandHome(cfg.Daemon.Log)
+	if err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func (c *Config) validate() error {
+	switch c.Color {
+	case "auto", "always", "never":
+	default:
+		return fmt.Errorf("color must be auto, always, or never, got %q", c.Color)
+	}
+	switch c.Hyperlinks {
+	case "auto", "always", "never":
+	default:
+		return fmt.Errorf("hyperlinks must be auto, always, or never, got %q", c.Hyperlinks)
+	}
+	switch c.List.Filter {
+	case "unread", "read", "all":
+	default:
+		return fmt.Errorf("list.filter must be unread, read, or all, got %q", c.List.Filter)
+	}
+	if c.List.Limit < 0 {
+		return fmt.Errorf("list.limit must not be negative")
+	}
+	if c.Goals.Weekly < 0 {
+		return fmt.Errorf("goals.weekly must not be negative")
+	}
+	if c.Cleanup.ArchiveReadAfterDays < 0 || c.Cleanup.DeleteTrashedAfterDays < 0 {
+		return fmt.Errorf("cleanup days must not be negative")
+	}
+	if c.Backup.Keep < 0 || c.Backup.EveryDays < 0 {
+		return fmt.Errorf("backup.keep and backup.every_days must not be negative")
+	}
+	for job := range c.Daemon.Jobs {
+		if why, ok := unsupportedDaemonJobs[job]; ok {
+			return fmt.Errorf("daemon.jobs: there is no %s job: %s", job, why)
+		}
+		if !slices.Contains(DaemonJobs, job) {
+			return fmt.Errorf("daemon.jobs: unknown job %q (use %s)", job, strings.Join(DaemonJobs, ", "))
+		}
+	}
+	if _, err := c.Location(); err != nil {
+		return err
+	}
+	if _, err := c.Timeout(); err != nil {
+		return err
+	}
+	switch c.IDScheme {
+	case "", model.IDSchemeRandom:
+	case model.IDSchemeULID:
+		if c.IDLength != 0 && c.IDLength != model.DefaultIDLength {
+			return fmt.Errorf("id_length doesn't apply to ulid IDs, which are always %d characters", model.DefaultIDLength)
+		}
+	default:
+		return fmt.Errorf("id_scheme must be random or ulid, got %q", c.IDScheme)
+	}
+	if c.IDLength != 0 && (c.IDLength < model.MinIDLength || c.IDLength > model.DefaultIDLength) {
+		return fmt.Errorf("id_length must be from %d to %d, got %d", model.MinIDLength, model.DefaultIDLength, c.IDLength)
+	}
+	for i, hook := range c.Webhooks {
+		u, err := url.Parse(hook.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("webhooks[%d].url must be an http or https URL, got %q", i, hook.URL)
+		}
+		for _, event := range hook.Events {
+			switch event {
+			case "added", "read", "deleted":
+			default:
+				return fmt.Errorf("webhooks[%d].events: unknown event %q (use added, read, or deleted)", i, event)
+			}
+		}
+	}
+	for i, rule := range c.Rules {
+		if rule.Domain == "" && rule.URL == "" && rule.Title == "" {
+			return fmt.Errorf("rules[%d] needs a domain, url, or title to match", i)
+		}
+		if len(rule.Tags) == 0 {
+			return fmt.Errorf("rules[%d].tags must list at least one tag", i)
+		}
+	}
+	return nil
+}
+
+// Location returns the time zone for displayed times and given dates.
+func (c *Config) Location() (*time.Location, error) {
+	if c.Timezone == "" {
+		return time.Local, nil
+	}
+	loc, err := time.LoadLocation(c.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("unknown timezone %q", c.Timezone)
+	}
+	return loc, nil
+}
+
+// Timeout returns the limit on each database operation, or zero for none.
+func (c *Config) Timeout() (time.Duration, error) {
+	if c.DBTimeout == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(c.DBTimeout)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("db_timeout must be a duration such as \"30s\", got %q", c.DBTimeout)
+	}
+	return d, nil
+}
+
+// ExpandHome replaces a leading "~" in path with the user's home directory.
+func ExpandHome(path string) (string, error) {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, path[1:]), nil
+}
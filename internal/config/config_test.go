@@ -0,0 +1,162 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+
+	cfg, err := Load(filepath.Join(dir, "missing.toml"))
+	if err != nil {
+		t.Fatalf("Load(missing) error: %v", err)
+	}
+	if cfg.List.Filter != "unread" || !cfg.Add.Fetch || !cfg.Add.Canonicalize {
+		t.Errorf("Expected defaults for a missing file, got %+v", cfg)
+	}
+
+	path := filepath.Join(dir, "config.toml")
+	data := "color = \"never\"\n\n[list]\nlimit = 20\n\n[add]\nfetch = false\n"
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err = Load(path)
+	if err != nil {
+		t.Fatalf("Load error: %v", err)
+	}
+	if cfg.Color != "never" || cfg.List.Limit != 20 || cfg.Add.Fetch {
+		t.Errorf("Config values not applied: %+v", cfg)
+	}
+	if cfg.List.Filter != "unread" || !cfg.Add.Canonicalize {
+		t.Errorf("Unset keys should keep defaults: %+v", cfg)
+	}
+
+	if err := os.WriteFile(path, []byte("[list]\nfilter = \"later\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Error("Expected error for invalid list.filter")
+	}
+
+	if err := os.WriteFile(path, []byte("colour = \"never\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Error("Expected error for unknown key")
+	}
+
+	if err := os.WriteFile(path, []byte("db_timeout = \"30 seconds\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Error("Expected error for invalid db_timeout")
+	}
+
+	if err := os.WriteFile(path, []byte("id_length = 6\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Error("Expected error for id_length below 8")
+	}
+
+	if err := os.WriteFile(path, []byte("id_scheme = \"ulid\"\nid_length = 8\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Error("Expected error for id_length with ulid IDs")
+	}
+
+	if err := os.WriteFile(path, []byte("[backup]\nkeep = -1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Error("Expected error for a negative backup.keep")
+	}
+
+	if err := os.WriteFile(path, []byte("[daemon.jobs]\nbackfill = \"6h\"\nfetch = \"1h\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil || !strings.Contains(err.Error(), `unknown job "fetch"`) {
+		t.Errorf("Expected error for an unknown daemon job, got %v", err)
+	}
+	for _, job := range []string{"feeds", "digest"} {
+		if err := os.WriteFile(path, []byte("[daemon.jobs]\n"+job+" = \"1d\"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Load(path); err == nil || !strings.Contains(err.Error(), "there is no "+job+" job") {
+			t.Errorf("Expected the %s job refused with a reason, got %v", job, err)
+		}
+	}
+
+	data = "[[webhooks]]\nurl = \"https://example.com/hook\"\nevents = [\"added\"]\n\n[webhooks.headers]\nAuthorization = \"Bearer x\"\n"
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err = Load(path)
+	if err != nil {
+		t.Fatalf("Load(webhooks) error: %v", err)
+	}
+	if len(cfg.Webhooks) != 1 || cfg.Webhooks[0].Events[0] != "added" || cfg.Webhooks[0].Headers["Authorization"] != "Bearer x" {
+		t.Errorf("Webhook not parsed: %+v", cfg.Webhooks)
+	}
+
+	if err := os.WriteFile(path, []byte("[[webhooks]]\nurl = \"https://example.com\"\nevents = [\"starred\"]\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Error("Expected error for unknown webhook event")
+	}
+
+	data = "[[rules]]\ndomain = \"github.com\"\ntags = [\"code\"]\n\n[[rules]]\ntitle = \"golang\"\ntags = [\"go\", \"code\"]\n"
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err = Load(path)
+	if err != nil {
+		t.Fatalf("Load(rules) error: %v", err)
+	}
+	if len(cfg.Rules) != 2 || cfg.Rules[0].Domain != "github.com" || len(cfg.Rules[1].Tags) != 2 {
+		t.Errorf("Rules not parsed: %+v", cfg.Rules)
+	}
+
+	if err := os.WriteFile(path, []byte("[[rules]]\ntags = [\"code\"]\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Error("Expected error for a rule without conditions")
+	}
+
+	// The old [summarize] section still configures the model
+	data = "[llm]\nmodel = \"llama3.2\"\n\n[summarize]\nmodel = \"mistral\"\napi_key = \"sk-old\"\nprompt = \"Be brief.\"\n"
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err = Load(path)
+	if err != nil {
+		t.Fatalf("Load(summarize) error: %v", err)
+	}
+	if cfg.LLM.Model != "llama3.2" || cfg.LLM.APIKey != "sk-old" || cfg.LLM.SummaryPrompt != "Be brief." {
+		t.Errorf("Expected [summarize] to fill what [llm] leaves empty, got %+v", cfg.LLM)
+	}
+}
+
+func TestLocation(t *testing.T) {
+	if loc, err := (&Config{}).Location(); err != nil || loc != time.Local {
+		t.Errorf("Location() without a timezone = %v, %v; want local", loc, err)
+	}
+	if loc, err := (&Config{Timezone: "UTC"}).Location(); err != nil || loc != time.UTC {
+		t.Errorf("Location() for UTC = %v, %v", loc, err)
+	}
+
+	path := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(path, []byte("timezone = \"Mars/Olympus_Mons\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil || !strings.Contains(err.Error(), "unknown timezone") {
+		t.Errorf("Load with an unknown timezone = %v, want an error", err)
+	}
+}
@@ -0,0 +1,158 @@
+// Package crypt encrypts exports with AES-256-GCM, keyed by a passphrase or
+// a key file, so backups can be kept on storage that isn't trusted.
+//
+// An encrypted file is a header (a magic line, whether it was keyed by a
+// passphrase or a key file, and a random salt), a random nonce, and the
+// sealed data. The header is authenticated along with the data, so a file
+// that was tampered with fails to decrypt.
+package crypt
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hkdf"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+)
+
+const (
+	// Magic starts every encrypted export.
+	Magic = "rl-encrypted-v1\n"
+	// kindPassphrase and kindKeyFile follow the magic line, recording
+	// what the file was encrypted with.
+	kindPassphrase = 'p'
+	kindKeyFile    = 'k'
+
+	saltSize   = 16
+	headerSize = len(Magic) + 1 + saltSize
+	keySize    = 32
+
+	// pbkdf2Iterations follows OWASP's recommendation for PBKDF2-SHA256.
+	pbkdf2Iterations = 600000
+
+	// MinKeyFileSize is the shortest key file accepted.
+	MinKeyFileSize = 32
+)
+
+var (
+	// ErrDecrypt is returned when a file can't be decrypted: the
+	// passphrase or key file is wrong, or the file was modified.
+	ErrDecrypt = errors.New("wrong passphrase or key file, or the file is corrupt")
+	// ErrNotEncrypted is returned when decrypting data that isn't encrypted.
+	ErrNotEncrypted = errors.New("not an encrypted rl export")
+)
+
+// Secret is what data is encrypted with: a passphrase, or the contents of
+// a key file when Key is set.
+type Secret struct {
+	Passphrase string
+	Key        []byte
+}
+
+func (s Secret) kind() byte {
+	if s.Key != nil {
+		return kindKeyFile
+	}
+	return kindPassphrase
+}
+
+// deriveKey turns the secret and salt into an AES-256 key. Passphrases are
+// stretched with PBKDF2 to slow down guessing; key files are expected to
+// be random already, so HKDF suffices.
+func (s Secret) deriveKey(salt []byte) ([]byte, error) {
+	if s.Key != nil {
+		if len(s.Key) < MinKeyFileSize {
+			return nil, fmt.Errorf("key file is too short: want at least %d random bytes", MinKeyFileSize)
+		}
+		return hkdf.Key(sha256.New, s.Key, salt, "rl export", keySize)
+	}
+	if s.Passphrase == "" {
+		return nil, errors.New("passphrase is empty")
+	}
+	return pbkdf2.Key(sha256.New, s.Passphrase, salt, pbkdf2Iterations, keySize)
+}
+
+// IsEncrypted reports whether data starts like an encrypted export.
+func IsEncrypted(data []byte) bool {
+	return bytes.HasPrefix(data, []byte(Magic))
+}
+
+// NeedsKeyFile reports whether encrypted data was encrypted with a key
+// file rather than a passphrase.
+func NeedsKeyFile(data []byte) bool {
+	return IsEncrypted(data) && len(data) > len(Magic) && data[len(Magic)] == kindKeyFile
+}
+
+// Encrypt writes plaintext to w, encrypted with secret.
+func Encrypt(w io.Writer, plaintext []byte, secret Secret) error {
+	header := make([]byte, headerSize)
+	copy(header, Magic)
+	header[len(Magic)] = secret.kind()
+	salt := header[len(Magic)+1:]
+	if _, err := rand.Read(salt); err != nil {
+		return fmt.Errorf("generate salt: %w", err)
+	}
+
+	aead, err := newAEAD(secret, salt)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return fmt.Errorf("generate nonce: %w", err)
+	}
+
+	out := append(header, nonce...)
+	out = aead.Seal(out, nonce, plaintext, header)
+	if _, err := w.Write(out); err != nil {
+		return fmt.Errorf("write encrypted export: %w", err)
+	}
+	return nil
+}
+
+// Decrypt returns the plaintext of data encrypted by Encrypt.
+func Decrypt(data []byte, secret Secret) ([]byte, error) {
+	if !IsEncrypted(data) || len(data) < headerSize {
+		return nil, ErrNotEncrypted
+	}
+	header := data[:headerSize]
+	switch kind := header[len(Magic)]; {
+	case kind == kindKeyFile && secret.Key == nil:
+		return nil, errors.New("file was encrypted with a key file")
+	case kind == kindPassphrase && secret.Key != nil:
+		return nil, errors.New("file was encrypted with a passphrase, not a key file")
+	case kind != kindKeyFile && kind != kindPassphrase:
+		return nil, ErrDecrypt
+	}
+
+	aead, err := newAEAD(secret, header[len(Magic)+1:])
+	if err != nil {
+		return nil, err
+	}
+	rest := data[headerSize:]
+	if len(rest) < aead.NonceSize() {
+		return nil, ErrDecrypt
+	}
+	plaintext, err := aead.Open(nil, rest[:aead.NonceSize()], rest[aead.NonceSize():], header)
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+	return plaintext, nil
+}
+
+func newAEAD(secret Secret, salt []byte) (cipher.AEAD, error) {
+	key, err := secret.deriveKey(salt)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,71 @@
+package crypt
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestRoundTrip(t *testing.T) {
+	plaintext := []byte(`[{"url":"https://example.com"}]`)
+	key := bytes.Repeat([]byte{7}, MinKeyFileSize)
+
+	tests := []struct {
+		name   string
+		secret Secret
+		wrong  Secret
+	}{
+		{"passphrase", Secret{Passphrase: "correct horse"}, Secret{Passphrase: "wrong horse"}},
+		{"key file", Secret{Key: key}, Secret{Key: bytes.Repeat([]byte{8}, MinKeyFileSize)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Encrypt(&buf, plaintext, tt.secret); err != nil {
+				t.Fatalf("Encrypt failed: %v", err)
+			}
+			data := buf.Bytes()
+			if !IsEncrypted(data) || bytes.Contains(data, []byte("example.com")) {
+				t.Fatal("Expected the output to be encrypted")
+			}
+			if NeedsKeyFile(data) != (tt.secret.Key != nil) {
+				t.Errorf("NeedsKeyFile = %v", NeedsKeyFile(data))
+			}
+
+			got, err := Decrypt(data, tt.secret)
+			if err != nil || !bytes.Equal(got, plaintext) {
+				t.Fatalf("Decrypt = %q, %v", got, err)
+			}
+			if _, err := Decrypt(data, tt.wrong); !errors.Is(err, ErrDecrypt) {
+				t.Errorf("Expected the wrong secret to fail, got %v", err)
+			}
+
+			// Flipping a header byte (here, the salt) breaks authentication
+			tampered := bytes.Clone(data)
+			tampered[headerSize-1] ^= 1
+			if _, err := Decrypt(tampered, tt.secret); !errors.Is(err, ErrDecrypt) {
+				t.Errorf("Expected a tampered file to fail, got %v", err)
+			}
+		})
+	}
+}
+
+func TestDecryptErrors(t *testing.T) {
+	if _, err := Decrypt([]byte(`[]`), Secret{Passphrase: "x"}); !errors.Is(err, ErrNotEncrypted) {
+		t.Errorf("Expected plain JSON to be reported as not encrypted, got %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := Encrypt(&buf, []byte("x"), Secret{Passphrase: "x"}); err != nil {
+		t.Fatalf("Encrypt failed: %v", err)
+	}
+	if _, err := Decrypt(buf.Bytes(), Secret{Key: make([]byte, MinKeyFileSize)}); err == nil {
+		t.Error("Expected a key file not to open a passphrase-encrypted file")
+	}
+	if err := Encrypt(&buf, []byte("x"), Secret{Key: []byte("short")}); err == nil {
+		t.Error("Expected a short key file to be refused")
+	}
+	if err := Encrypt(&buf, []byte("x"), Secret{}); err == nil {
+		t.Error("Expected an empty passphrase to be refused")
+	}
+}
@@ -0,0 +1,87 @@
+package fetch
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	downloadTimeout = 5 * time.Minute
+	// MaxDownloadSize caps the size of a downloaded document.
+	MaxDownloadSize = 200 << 20 // 200 MiB
+)
+
+// Download is a document being downloaded. The caller must close it.
+type Download struct {
+	io.ReadCloser
+	// ContentType is the Content-Type the server sent.
+	ContentType string
+}
+
+// Download starts downloading the file at rawURL. Reading past
+// MaxDownloadSize fails rather than saving a truncated file.
+func (c *Client) Download(ctx context.Context, rawURL string) (*Download, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("User-Agent", userAgent)
+
+	client := *c.http
+	client.Timeout = downloadTimeout
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("download %s: %w", rawURL, err)
+	}
+	if resp.StatusCode >= 400 {
+		resp.Body.Close()
+		return nil, &StatusError{URL: rawURL, Code: resp.StatusCode, Status: resp.Status}
+	}
+	return &Download{
+		ReadCloser:  &limitedBody{body: resp.Body, left: MaxDownloadSize, url: rawURL},
+		ContentType: resp.Header.Get("Content-Type"),
+	}, nil
+}
+
+// limitedBody reads a response body, failing once more than left bytes
+// have been read.
+type limitedBody struct {
+	body io.ReadCloser
+	left int64
+	url  string
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	n, err := b.body.Read(p)
+	b.left -= int64(n)
+	if b.left < 0 {
+		return n, fmt.Errorf("download %s: larger than %d MiB", b.url, MaxDownloadSize>>20)
+	}
+	return n, err
+}
+
+func (b *limitedBody) Close() error {
+	return b.body.Close()
+}
+
+// IsDocument reports whether a Content-Type is a document worth keeping a
+// copy of: PDFs, e-books, office documents, and plain text.
+func IsDocument(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	switch mediaType {
+	case "application/pdf", "application/epub+zip", "application/msword", "application/rtf",
+		"application/postscript", "text/plain":
+		return true
+	}
+	return strings.HasPrefix(mediaType, "application/vnd.openxmlformats-officedocument.") ||
+		strings.HasPrefix(mediaType, "application/vnd.oasis.opendocument.") ||
+		strings.HasPrefix(mediaType, "application/vnd.ms-")
+}
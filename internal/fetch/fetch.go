@@ -0,0 +1,354 @@
+package fetch
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/bunchhieng/rl/internal/model"
+	"golang.org/x/net/html"
+)
+
+const (
+	defaultTimeout = 15 * time.Second
+	maxBodySize    = 5 << 20 // 5 MiB
+	userAgent      = "rl/1.0 (+https://github.com/bunchhieng/rl)"
+)
+
+// Metadata holds information extracted from a fetched page.
+type Metadata struct {
+	// FinalURL is the page URL after following redirects.
+	FinalURL string
+	// ContentType is the Content-Type the server sent; IsDocument tells
+	// whether it is a file worth saving a copy of.
+	ContentType string
+	Title       string
+	Description string
+	WordCount   int
+	// Text is the readable page text, one paragraph per line.
+	Text string
+	// Validators identify this version of the page, for asking for it
+	// again only if it changed.
+	Validators Validators
+	// NotModified reports that the server said the page is unchanged since
+	// the validators passed to FetchIfChanged; nothing else is set.
+	NotModified bool
+	// MediaType is model.MediaVideo or model.MediaAudio for a video or
+	// podcast page, and empty otherwise.
+	MediaType string
+	// Author is the page's author or the channel that published it.
+	Author string
+	// Duration is the running time of a video or audio page.
+	Duration time.Duration
+
+	// oembedURL is the oEmbed endpoint the page advertises, if any.
+	oembedURL string
+}
+
+// Validators identify a version of a page for conditional requests.
+type Validators struct {
+	ETag         string
+	LastModified string
+}
+
+// IsZero reports whether there are no validators to send.
+func (v Validators) IsZero() bool {
+	return v.ETag == "" && v.LastModified == ""
+}
+
+// setHeaders asks for the page only if it changed since v.
+func (v Validators) setHeaders(h http.Header) {
+	if v.ETag != "" {
+		h.Set("If-None-Match", v.ETag)
+	}
+	if v.LastModified != "" {
+		h.Set("If-Modified-Since", v.LastModified)
+	}
+}
+
+// Apply fills in link's title (unless already set), description, author,
+// media type, word count, and reading time from the fetched page. Video
+// and audio take their running time as the reading time instead of an
+// estimate from the words on the page.
+func (m *Metadata) Apply(link *model.Link) {
+	if link.Title == "" {
+		link.Title = m.Title
+	}
+	if m.Description != "" {
+		link.Description = m.Description
+	}
+	if m.Author != "" {
+		link.Author = m.Author
+	}
+	if m.MediaType != "" {
+		link.MediaType = m.MediaType
+	}
+	if m.WordCount > 0 {
+		link.WordCount = m.WordCount
+		if link.MediaType == "" {
+			link.ReadingSeconds = model.EstimateReadingSeconds(m.WordCount)
+		}
+	}
+	if m.Duration > 0 {
+		link.ReadingSeconds = int(m.Duration.Round(time.Second) / time.Second)
+	}
+}
+
+// StatusError reports a page that answered with an HTTP error status.
+type StatusError struct {
+	URL    string
+	Code   int
+	Status string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("fetch %s: %s", e.URL, e.Status)
+}
+
+// Client fetches and extracts page metadata.
+type Client struct {
+	http *http.Client
+}
+
+// NewClient creates a new metadata fetch client.
+func NewClient() *Client {
+	return &Client{http: &http.Client{Timeout: defaultTimeout}}
+}
+
+// Fetch downloads a page and extracts its title, description, and word
+// count. Video and podcast pages also get their author and running time
+// from oEmbed.
+func (c *Client) Fetch(ctx context.Context, rawURL string) (*Metadata, error) {
+	return c.FetchIfChanged(ctx, rawURL, Validators{})
+}
+
+// FetchIfChanged is Fetch, except that a server confirming the page still
+// matches v skips the download and returns Metadata with NotModified set.
+func (c *Client) FetchIfChanged(ctx context.Context, rawURL string, v Validators) (*Metadata, error) {
+	return c.fetch(ctx, rawURL, v, nil)
+}
+
+// fetch is FetchIfChanged, spacing the oEmbed request after the page by
+// limiter, if any, as FetchAll does the pages themselves.
+func (c *Client) fetch(ctx context.Context, rawURL string, v Validators, limiter *hostLimiter) (*Metadata, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml;q=0.9,*/*;q=0.8")
+	v.setHeaders(req.Header)
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetch %s: %w", rawURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified && !v.IsZero() {
+		return &Metadata{FinalURL: resp.Request.URL.String(), Validators: v, NotModified: true}, nil
+	}
+	if resp.StatusCode >= 400 {
+		return nil, &StatusError{URL: rawURL, Code: resp.StatusCode, Status: resp.Status}
+	}
+
+	finalURL := resp.Request.URL.String()
+	validators := Validators{ETag: resp.Header.Get("ETag"), LastModified: resp.Header.Get("Last-Modified")}
+
+	contentType := resp.Header.Get("Content-Type")
+	if contentType != "" && !strings.Contains(contentType, "html") {
+		return &Metadata{FinalURL: finalURL, ContentType: contentType, Validators: validators, MediaType: mediaTypeOf(contentType)}, nil
+	}
+
+	meta, err := parseHTML(io.LimitReader(resp.Body, maxBodySize))
+	if err != nil {
+		return nil, err
+	}
+	meta.FinalURL = finalURL
+	meta.ContentType = contentType
+	meta.Validators = validators
+	c.addMedia(ctx, finalURL, meta, limiter)
+	return meta, nil
+}
+
+// Check requests a URL and returns its HTTP status code, trying HEAD first
+// and falling back to GET for servers that reject HEAD. The GET is
+// conditional on v, so an unchanged page answers 304 without a body.
+func (c *Client) Check(ctx context.Context, rawURL string, v Validators) (int, error) {
+	status, err := c.status(ctx, http.MethodHead, rawURL, Validators{})
+	if err != nil {
+		return 0, err
+	}
+	if status == http.StatusMethodNotAllowed || status == http.StatusNotImplemented || status == http.StatusForbidden {
+		return c.status(ctx, http.MethodGet, rawURL, v)
+	}
+	return status, nil
+}
+
+func (c *Client) status(ctx context.Context, method, rawURL string, v Validators) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, nil)
+	if err != nil {
+		return 0, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("User-Agent", userAgent)
+	v.setHeaders(req.Header)
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("%s %s: %w", method, rawURL, err)
+	}
+	resp.Body.Close()
+	return resp.StatusCode, nil
+}
+
+// parseHTML extracts metadata from an HTML document.
+func parseHTML(r io.Reader) (*Metadata, error) {
+	meta := &Metadata{}
+	var ogTitle string
+	var text strings.Builder
+
+	z := html.NewTokenizer(r)
+	inTitle := false
+	skipDepth := 0
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			if z.Err() != io.EOF {
+				return nil, fmt.Errorf("parse HTML: %w", z.Err())
+			}
+			if meta.Title == "" {
+				meta.Title = ogTitle
+			}
+			meta.Text = paragraphs(text.String())
+			meta.WordCount = countWords(meta.Text)
+			return meta, nil
+
+		case html.StartTagToken, html.SelfClosingTagToken:
+			tok := z.Token()
+			if tok.Data == "br" {
+				text.WriteByte('\n')
+			}
+			switch tok.Data {
+			case "title":
+				inTitle = true
+			case "script", "style", "noscript", "nav", "header", "footer", "svg":
+				if tok.Type == html.StartTagToken {
+					skipDepth++
+				}
+			case "meta":
+				name, content := metaAttrs(tok)
+				switch name {
+				case "description", "og:description":
+					if meta.Description == "" {
+						meta.Description = content
+					}
+				case "og:title":
+					ogTitle = content
+				case "og:type":
+					meta.MediaType = mediaTypeOf(content)
+				case "author":
+					meta.Author = content
+				case "duration", "og:video:duration", "video:duration", "music:duration":
+					if meta.Duration == 0 {
+						meta.Duration = parseDuration(content)
+					}
+				}
+			case "link":
+				if isOEmbedLink(tok) {
+					meta.oembedURL = attr(tok, "href")
+				}
+			}
+
+		case html.EndTagToken:
+			tok := z.Token()
+			if blockTags[tok.Data] {
+				text.WriteByte('\n')
+			}
+			switch tok.Data {
+			case "title":
+				inTitle = false
+			case "script", "style", "noscript", "nav", "header", "footer", "svg":
+				if skipDepth > 0 {
+					skipDepth--
+				}
+			}
+
+		case html.TextToken:
+			if inTitle {
+				if meta.Title == "" {
+					meta.Title = strings.Join(strings.Fields(string(z.Text())), " ")
+				}
+				continue
+			}
+			if skipDepth == 0 {
+				text.WriteByte(' ')
+				text.Write(z.Text())
+			}
+		}
+	}
+}
+
+// blockTags end a paragraph of extracted text.
+var blockTags = map[string]bool{
+	"p": true, "div": true, "li": true, "blockquote": true, "pre": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	"article": true, "section": true, "tr": true, "dt": true, "dd": true,
+}
+
+// paragraphs collapses whitespace within each line of s and drops empty
+// lines, leaving one paragraph per line.
+func paragraphs(s string) string {
+	var out []string
+	for _, line := range strings.Split(s, "\n") {
+		if line = strings.Join(strings.Fields(line), " "); line != "" {
+			out = append(out, line)
+		}
+	}
+	return strings.Join(out, "\n")
+}
+
+func metaAttrs(tok html.Token) (name, content string) {
+	for _, attr := range tok.Attr {
+		switch strings.ToLower(attr.Key) {
+		case "name", "property", "itemprop":
+			name = strings.ToLower(attr.Val)
+		case "content":
+			content = strings.TrimSpace(attr.Val)
+		}
+	}
+	return name, content
+}
+
+// isOEmbedLink reports whether tok is a <link> advertising a JSON oEmbed
+// endpoint.
+func isOEmbedLink(tok html.Token) bool {
+	return strings.EqualFold(attr(tok, "rel"), "alternate") &&
+		strings.EqualFold(attr(tok, "type"), "application/json+oembed")
+}
+
+func attr(tok html.Token, key string) string {
+	for _, a := range tok.Attr {
+		if strings.EqualFold(a.Key, key) {
+			return strings.TrimSpace(a.Val)
+		}
+	}
+	return ""
+}
+
+// countWords counts whitespace-separated tokens containing at least one letter or digit.
+func countWords(s string) int {
+	count := 0
+	for _, field := range strings.Fields(s) {
+		for _, r := range field {
+			if unicode.IsLetter(r) || unicode.IsDigit(r) {
+				count++
+				break
+			}
+		}
+	}
+	return count
+}
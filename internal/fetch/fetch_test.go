@@ -0,0 +1,114 @@
+package fetch
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+const samplePage = `<!DOCTYPE html>
+<html>
+<head>
+  <title>  Example
+    Article </title>
+  <meta name="description" content="A short example.">
+  <style>body { color: red; }</style>
+</head>
+<body>
+  <nav>Home About Contact</nav>
+  <article><p>One two three four five.</p><p>Six seven -- eight!</p></article>
+  <script>var notWords = "should not count";</script>
+</body>
+</html>`
+
+func TestFetch(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(samplePage))
+	}))
+	defer srv.Close()
+
+	meta, err := NewClient().Fetch(context.Background(), srv.URL)
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if meta.Title != "Example Article" {
+		t.Errorf("Expected title 'Example Article', got %q", meta.Title)
+	}
+	if meta.Description != "A short example." {
+		t.Errorf("Expected description, got %q", meta.Description)
+	}
+	if meta.WordCount != 8 {
+		t.Errorf("Expected 8 words, got %d", meta.WordCount)
+	}
+	if want := "One two three four five.\nSix seven -- eight!"; meta.Text != want {
+		t.Errorf("Expected text %q, got %q", want, meta.Text)
+	}
+}
+
+func TestFetchNotFound(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+
+	if _, err := NewClient().Fetch(context.Background(), srv.URL); err == nil {
+		t.Error("Expected error for 404 response")
+	}
+}
+
+func TestFetchIfChanged(t *testing.T) {
+	const etag = `"v1"`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Last-Modified", "Wed, 31 Jan 2024 11:00:00 GMT")
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(samplePage))
+	}))
+	defer srv.Close()
+
+	client := NewClient()
+	meta, err := client.FetchIfChanged(context.Background(), srv.URL, Validators{})
+	if err != nil {
+		t.Fatalf("FetchIfChanged failed: %v", err)
+	}
+	if meta.NotModified || meta.Title != "Example Article" || meta.Validators.ETag != etag || meta.Validators.LastModified == "" {
+		t.Fatalf("Expected the full page with its validators, got %+v", meta)
+	}
+
+	again, err := client.FetchIfChanged(context.Background(), srv.URL, meta.Validators)
+	if err != nil {
+		t.Fatalf("FetchIfChanged failed: %v", err)
+	}
+	if !again.NotModified || again.Title != "" || again.Validators != meta.Validators {
+		t.Errorf("Expected an unchanged page keeping its validators, got %+v", again)
+	}
+}
+
+func TestDownload(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/pdf")
+		w.Write([]byte("%PDF-1.7"))
+	}))
+	defer srv.Close()
+
+	dl, err := NewClient().Download(context.Background(), srv.URL)
+	if err != nil {
+		t.Fatalf("Download failed: %v", err)
+	}
+	defer dl.Close()
+	body, err := io.ReadAll(dl)
+	if err != nil || string(body) != "%PDF-1.7" {
+		t.Errorf("Expected the file body, got %q (%v)", body, err)
+	}
+	if !IsDocument(dl.ContentType) {
+		t.Errorf("Expected %q to be a document", dl.ContentType)
+	}
+	if IsDocument("text/html; charset=utf-8") {
+		t.Error("Expected a web page not to be a document")
+	}
+}
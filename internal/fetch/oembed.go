@@ -0,0 +1,160 @@
+package fetch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bunchhieng/rl/internal/model"
+)
+
+// oembedProvider is a site whose oEmbed endpoint is known without having
+// to discover it from the page.
+type oembedProvider struct {
+	endpoint  string
+	mediaType string
+}
+
+// oembedProviders maps hosts (without "www.") to their oEmbed endpoints.
+var oembedProviders = map[string]oembedProvider{
+	"youtube.com":        {"https://www.youtube.com/oembed", model.MediaVideo},
+	"m.youtube.com":      {"https://www.youtube.com/oembed", model.MediaVideo},
+	"youtu.be":           {"https://www.youtube.com/oembed", model.MediaVideo},
+	"vimeo.com":          {"https://vimeo.com/api/oembed.json", model.MediaVideo},
+	"open.spotify.com":   {"https://open.spotify.com/oembed", model.MediaAudio},
+	"soundcloud.com":     {"https://soundcloud.com/oembed", model.MediaAudio},
+	"podcasts.apple.com": {"", model.MediaAudio},
+}
+
+// oembedResponse holds the oEmbed fields rl uses. Duration is a common
+// extension (Vimeo sends it) in seconds.
+type oembedResponse struct {
+	Type       string  `json:"type"`
+	Title      string  `json:"title"`
+	AuthorName string  `json:"author_name"`
+	Duration   float64 `json:"duration"`
+}
+
+// addMedia fills in the media type, author, and duration of a page from
+// its oEmbed endpoint, either a known provider's or one the page links to.
+// oEmbed is best effort: if the request fails, the page's own metadata
+// stands. The request waits its turn with limiter, if any, so fetching
+// many videos from one site doesn't hammer its oEmbed endpoint.
+func (c *Client) addMedia(ctx context.Context, pageURL string, meta *Metadata, limiter *hostLimiter) {
+	endpoint := resolveRef(pageURL, meta.oembedURL)
+	if p, ok := oembedProviders[model.Domain(pageURL)]; ok {
+		meta.MediaType = p.mediaType
+		if p.endpoint != "" {
+			endpoint = p.endpoint + "?format=json&url=" + url.QueryEscape(pageURL)
+		}
+	}
+	if endpoint == "" {
+		return
+	}
+
+	if err := limiter.wait(ctx, endpoint); err != nil {
+		return
+	}
+	data, err := c.oembed(ctx, endpoint)
+	if err != nil {
+		return
+	}
+	if meta.MediaType == "" && data.Type == "video" {
+		meta.MediaType = model.MediaVideo
+	}
+	if meta.Title == "" {
+		meta.Title = data.Title
+	}
+	if data.AuthorName != "" {
+		meta.Author = data.AuthorName
+	}
+	if data.Duration > 0 {
+		meta.Duration = time.Duration(data.Duration) * time.Second
+	}
+}
+
+func (c *Client) oembed(ctx context.Context, endpoint string) (*oembedResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetch oEmbed %s: %w", endpoint, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		return nil, &StatusError{URL: endpoint, Code: resp.StatusCode, Status: resp.Status}
+	}
+
+	var data oembedResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxBodySize)).Decode(&data); err != nil {
+		return nil, fmt.Errorf("decode oEmbed %s: %w", endpoint, err)
+	}
+	return &data, nil
+}
+
+// resolveRef resolves a possibly relative href against the page it
+// appeared on, returning "" if either does not parse.
+func resolveRef(pageURL, href string) string {
+	if href == "" {
+		return ""
+	}
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		return ""
+	}
+	ref, err := url.Parse(href)
+	if err != nil {
+		return ""
+	}
+	return base.ResolveReference(ref).String()
+}
+
+// mediaTypeOf maps an og:type or Content-Type to a media type.
+func mediaTypeOf(kind string) string {
+	kind = strings.ToLower(kind)
+	switch {
+	case strings.HasPrefix(kind, "video"):
+		return model.MediaVideo
+	case strings.HasPrefix(kind, "audio"), strings.HasPrefix(kind, "music"):
+		return model.MediaAudio
+	}
+	return ""
+}
+
+var isoDuration = regexp.MustCompile(`^P(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+(?:\.\d+)?)S)?)?$`)
+
+// parseDuration parses a running time given either in seconds, as in
+// og:video:duration, or as an ISO 8601 duration such as "PT12M34S", as in
+// schema.org's itemprop="duration". It returns 0 if s is neither.
+func parseDuration(s string) time.Duration {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	if secs, err := strconv.ParseFloat(s, 64); err == nil && secs > 0 {
+		return time.Duration(secs * float64(time.Second))
+	}
+	m := isoDuration.FindStringSubmatch(s)
+	if m == nil {
+		return 0
+	}
+	var d time.Duration
+	for i, unit := range []time.Duration{24 * time.Hour, time.Hour, time.Minute} {
+		if n, err := strconv.Atoi(m[i+1]); err == nil {
+			d += time.Duration(n) * unit
+		}
+	}
+	if secs, err := strconv.ParseFloat(m[4], 64); err == nil {
+		d += time.Duration(secs * float64(time.Second))
+	}
+	return d
+}
@@ -0,0 +1,87 @@
+package fetch
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/bunchhieng/rl/internal/model"
+)
+
+func TestFetchOEmbed(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/talk", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<html><head><title>A Talk</title>
+<link rel="alternate" type="application/json+oembed" href="/oembed?url=talk">
+</head><body><p>Watch the talk.</p></body></html>`))
+	})
+	mux.HandleFunc("/oembed", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"type": "video", "title": "A Talk", "author_name": "Jane Speaker", "duration": 1234}`))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	meta, err := NewClient().Fetch(context.Background(), srv.URL+"/talk")
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if meta.MediaType != model.MediaVideo {
+		t.Errorf("Expected media type video, got %q", meta.MediaType)
+	}
+	if meta.Author != "Jane Speaker" {
+		t.Errorf("Expected author from oEmbed, got %q", meta.Author)
+	}
+	if meta.Duration != 1234*time.Second {
+		t.Errorf("Expected duration 1234s, got %v", meta.Duration)
+	}
+
+	link := &model.Link{URL: srv.URL + "/talk"}
+	meta.Apply(link)
+	if link.ReadingSeconds != 1234 || link.MediaType != model.MediaVideo || link.Author != "Jane Speaker" {
+		t.Errorf("Apply: got %d seconds, type %q, author %q", link.ReadingSeconds, link.MediaType, link.Author)
+	}
+}
+
+func TestFetchPageDuration(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<html><head><title>Episode 12</title>
+<meta property="og:type" content="music.song">
+<meta name="author" content="The Show">
+<meta itemprop="duration" content="PT1H2M3S">
+</head><body><p>Show notes.</p></body></html>`))
+	}))
+	defer srv.Close()
+
+	meta, err := NewClient().Fetch(context.Background(), srv.URL)
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if meta.MediaType != model.MediaAudio || meta.Author != "The Show" || meta.Duration != time.Hour+2*time.Minute+3*time.Second {
+		t.Errorf("Got type %q, author %q, duration %v", meta.MediaType, meta.Author, meta.Duration)
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		in   string
+		want time.Duration
+	}{
+		{"PT12M34S", 12*time.Minute + 34*time.Second},
+		{"PT1H", time.Hour},
+		{"P1DT2H", 26 * time.Hour},
+		{"754", 754 * time.Second},
+		{"pt30s", 30 * time.Second},
+		{"", 0},
+		{"soon", 0},
+	}
+	for _, tt := range tests {
+		if got := parseDuration(tt.in); got != tt.want {
+			t.Errorf("parseDuration(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,223 @@
+package fetch
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// PoolOptions configures FetchAll. Zero values pick the defaults.
+type PoolOptions struct {
+	// Workers is how many pages are fetched at once (default 8).
+	Workers int
+	// HostDelay is the least time between requests to the same host, so
+	// a backlog from one site doesn't hammer it (default 1s).
+	HostDelay time.Duration
+	// Retries is how many more times a temporary failure (a network
+	// error, 429, or 5xx) is tried (default 2; negative for none).
+	Retries int
+	// Backoff is the wait before the first retry, doubled for each one
+	// after (default 2s).
+	Backoff time.Duration
+	// Timeout bounds each attempt (default 15s).
+	Timeout time.Duration
+}
+
+const (
+	defaultWorkers   = 8
+	defaultHostDelay = time.Second
+	defaultRetries   = 2
+	defaultBackoff   = 2 * time.Second
+)
+
+func (o PoolOptions) withDefaults() PoolOptions {
+	if o.Workers <= 0 {
+		o.Workers = defaultWorkers
+	}
+	if o.HostDelay <= 0 {
+		o.HostDelay = defaultHostDelay
+	}
+	if o.Retries < 0 {
+		o.Retries = 0
+	} else if o.Retries == 0 {
+		o.Retries = defaultRetries
+	}
+	if o.Backoff <= 0 {
+		o.Backoff = defaultBackoff
+	}
+	if o.Timeout <= 0 {
+		o.Timeout = defaultTimeout
+	}
+	return o
+}
+
+// Request is a page for FetchAll to fetch, with the validators of the copy
+// already saved, if any.
+type Request struct {
+	URL        string
+	Validators Validators
+}
+
+// Result is the outcome of fetching requests[Index] in FetchAll.
+type Result struct {
+	Index int
+	Meta  *Metadata
+	Err   error
+}
+
+// FetchAll fetches pages concurrently with a bounded number of workers,
+// spacing requests to each host and retrying temporary failures with
+// exponential backoff. Pages unchanged since their validators come back
+// NotModified. done is called once per request, in completion order,
+// from the calling goroutine, so it may write to storage or the terminal
+// without locking.
+func (c *Client) FetchAll(ctx context.Context, requests []Request, opts PoolOptions, done func(Result)) {
+	opts = opts.withDefaults()
+	limiter := newHostLimiter(opts.HostDelay)
+
+	jobs := make(chan int)
+	results := make(chan Result)
+	var wg sync.WaitGroup
+	for i := 0; i < opts.Workers && i < len(requests); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range jobs {
+				meta, err := c.fetchWithRetry(ctx, requests[index], opts, limiter)
+				results <- Result{Index: index, Meta: meta, Err: err}
+			}
+		}()
+	}
+
+	go func() {
+		defer close(jobs)
+		for _, index := range interleaveHosts(requests) {
+			select {
+			case jobs <- index:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	for res := range results {
+		done(res)
+	}
+}
+
+// fetchWithRetry fetches req, waiting its turn for the host and retrying
+// temporary failures.
+func (c *Client) fetchWithRetry(ctx context.Context, req Request, opts PoolOptions, limiter *hostLimiter) (*Metadata, error) {
+	backoff := opts.Backoff
+	for attempt := 0; ; attempt++ {
+		if err := limiter.wait(ctx, req.URL); err != nil {
+			return nil, err
+		}
+		attemptCtx, cancel := context.WithTimeout(ctx, opts.Timeout)
+		meta, err := c.fetch(attemptCtx, req.URL, req.Validators, limiter)
+		cancel()
+		if err == nil || attempt >= opts.Retries || !temporary(err) || ctx.Err() != nil {
+			return meta, err
+		}
+		if err := sleep(ctx, backoff); err != nil {
+			return nil, err
+		}
+		backoff *= 2
+	}
+}
+
+// temporary reports whether err may go away on its own: rate limiting,
+// a server error, or a network failure.
+func temporary(err error) bool {
+	var status *StatusError
+	if errors.As(err, &status) {
+		return status.Code == http.StatusTooManyRequests || status.Code >= 500
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// interleaveHosts orders the indexes of requests round-robin by host, so
+// workers spread across sites instead of queueing on one.
+func interleaveHosts(requests []Request) []int {
+	var hosts []string
+	byHost := make(map[string][]int)
+	for i, r := range requests {
+		host := hostOf(r.URL)
+		if _, ok := byHost[host]; !ok {
+			hosts = append(hosts, host)
+		}
+		byHost[host] = append(byHost[host], i)
+	}
+
+	order := make([]int, 0, len(requests))
+	for len(order) < len(requests) {
+		for _, host := range hosts {
+			if queue := byHost[host]; len(queue) > 0 {
+				order = append(order, queue[0])
+				byHost[host] = queue[1:]
+			}
+		}
+	}
+	return order
+}
+
+// hostLimiter hands out request slots at least delay apart per host.
+type hostLimiter struct {
+	delay time.Duration
+	mu    sync.Mutex
+	next  map[string]time.Time
+}
+
+func newHostLimiter(delay time.Duration) *hostLimiter {
+	return &hostLimiter{delay: delay, next: make(map[string]time.Time)}
+}
+
+// wait blocks until rawURL's host may be requested again. A nil limiter
+// never waits.
+func (l *hostLimiter) wait(ctx context.Context, rawURL string) error {
+	if l == nil {
+		return ctx.Err()
+	}
+	host := hostOf(rawURL)
+	l.mu.Lock()
+	now := time.Now()
+	slot := l.next[host]
+	if slot.Before(now) {
+		slot = now
+	}
+	l.next[host] = slot.Add(l.delay)
+	l.mu.Unlock()
+	return sleep(ctx, time.Until(slot))
+}
+
+func hostOf(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	return u.Host
+}
+
+// sleep waits for d or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
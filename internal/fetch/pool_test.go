@@ -0,0 +1,127 @@
+package fetch
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFetchAll(t *testing.T) {
+	var mu sync.Mutex
+	var requests []time.Time
+	var flaky atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests = append(requests, time.Now())
+		mu.Unlock()
+		switch r.URL.Path {
+		case "/flaky":
+			// Fails once, then recovers
+			if flaky.Add(1) == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+		case "/gone":
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(samplePage))
+	}))
+	defer srv.Close()
+
+	urls := []string{srv.URL + "/a", srv.URL + "/flaky", srv.URL + "/gone"}
+	reqs := make([]Request, len(urls))
+	for i, u := range urls {
+		reqs[i] = Request{URL: u}
+	}
+	opts := PoolOptions{Workers: 4, HostDelay: 20 * time.Millisecond, Backoff: time.Millisecond}
+	errs := make([]error, len(urls))
+	calls := 0
+	NewClient().FetchAll(context.Background(), reqs, opts, func(r Result) {
+		calls++
+		errs[r.Index] = r.Err
+		if r.Err == nil && r.Meta.Title != "Example Article" {
+			t.Errorf("Expected the page title for %s, got %q", urls[r.Index], r.Meta.Title)
+		}
+	})
+
+	if calls != len(urls) {
+		t.Fatalf("Expected %d results, got %d", len(urls), calls)
+	}
+	if errs[0] != nil || errs[1] != nil {
+		t.Errorf("Expected /a and /flaky (after a retry) to succeed, got %v and %v", errs[0], errs[1])
+	}
+	if errs[2] == nil {
+		t.Error("Expected /gone to fail without retrying")
+	}
+	if n := flaky.Load(); n != 2 {
+		t.Errorf("Expected /flaky to be tried twice, got %d", n)
+	}
+
+	// Four requests to one host, each at least HostDelay after the last
+	if len(requests) != 4 {
+		t.Fatalf("Expected 4 requests, got %d", len(requests))
+	}
+	for i := 1; i < len(requests); i++ {
+		if gap := requests[i].Sub(requests[i-1]); gap < 15*time.Millisecond {
+			t.Errorf("Requests %d and %d were only %v apart", i-1, i, gap)
+		}
+	}
+}
+
+func TestInterleaveHosts(t *testing.T) {
+	var requests []Request
+	for _, u := range []string{"https://a.example/1", "https://a.example/2", "https://a.example/3", "https://b.example/1", "https://c.example/1"} {
+		requests = append(requests, Request{URL: u})
+	}
+	if got, want := interleaveHosts(requests), []int{0, 3, 4, 1, 2}; !reflect.DeepEqual(got, want) {
+		t.Errorf("interleaveHosts = %v, want %v", got, want)
+	}
+}
+
+func TestFetchAllSpacesOEmbed(t *testing.T) {
+	var mu sync.Mutex
+	var requests []time.Time
+	mux := http.NewServeMux()
+	mux.HandleFunc("/talk/", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests = append(requests, time.Now())
+		mu.Unlock()
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<html><head><title>A Talk</title>
+<link rel="alternate" type="application/json+oembed" href="/oembed">
+</head><body><p>Watch the talk.</p></body></html>`))
+	})
+	mux.HandleFunc("/oembed", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests = append(requests, time.Now())
+		mu.Unlock()
+		w.Write([]byte(`{"type": "video", "author_name": "Jane Speaker"}`))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	reqs := []Request{{URL: srv.URL + "/talk/1"}, {URL: srv.URL + "/talk/2"}, {URL: srv.URL + "/talk/3"}}
+	opts := PoolOptions{Workers: 3, HostDelay: 20 * time.Millisecond}
+	NewClient().FetchAll(context.Background(), reqs, opts, func(r Result) {
+		if r.Err != nil || r.Meta.Author != "Jane Speaker" {
+			t.Errorf("Expected %s fetched with its oEmbed data, got %+v (%v)", reqs[r.Index].URL, r.Meta, r.Err)
+		}
+	})
+
+	// The oEmbed requests wait their turn with the pages on the same host
+	if len(requests) != 2*len(reqs) {
+		t.Fatalf("Expected %d requests, got %d", 2*len(reqs), len(requests))
+	}
+	for i := 1; i < len(requests); i++ {
+		if gap := requests[i].Sub(requests[i-1]); gap < 15*time.Millisecond {
+			t.Errorf("Requests %d and %d were only %v apart", i-1, i, gap)
+		}
+	}
+}
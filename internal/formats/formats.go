@@ -0,0 +1,141 @@
+// Package formats converts links to and from the export formats of rl and
+// other bookmarking tools.
+package formats
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/bunchhieng/rl/internal/model"
+)
+
+// Format reads and writes links in one tool's format.
+type Format struct {
+	Name string
+	// Decode reads links from r.
+	Decode func(r io.Reader) ([]*model.Link, error)
+	// Encode writes links to w; nil for import-only formats.
+	Encode func(w io.Writer, links []*model.Link) error
+}
+
+// All lists the supported formats; the first is the default.
+var All = []*Format{
+	{Name: "rl", Decode: decodeRL, Encode: encodeJSON[*model.Link](identity)},
+	{Name: "linkding", Decode: decodeLinkding, Encode: encodeJSON(toLinkding)},
+	{Name: "shiori", Decode: decodeShiori, Encode: encodeJSON(toShiori)},
+	{Name: "omnivore", Decode: decodeOmnivore},
+	{Name: "readwise", Decode: decodeReadwise},
+}
+
+// Lookup returns the format called name; "" means rl's own format.
+func Lookup(name string) (*Format, error) {
+	if name == "" {
+		return All[0], nil
+	}
+	var names []string
+	for _, f := range All {
+		if f.Name == strings.ToLower(name) {
+			return f, nil
+		}
+		names = append(names, f.Name)
+	}
+	return nil, fmt.Errorf("unknown format %q (use %s)", name, strings.Join(names, ", "))
+}
+
+// Exportable reports the names of formats that can be written.
+func Exportable() []string {
+	var names []string
+	for _, f := range All {
+		if f.Encode != nil {
+			names = append(names, f.Name)
+		}
+	}
+	return names
+}
+
+func identity(link *model.Link) *model.Link {
+	return link
+}
+
+// encodeJSON returns an encoder writing links as an indented JSON array of
+// convert's results.
+func encodeJSON[T any](convert func(*model.Link) T) func(io.Writer, []*model.Link) error {
+	return func(w io.Writer, links []*model.Link) error {
+		items := make([]T, len(links))
+		for i, link := range links {
+			items[i] = convert(link)
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(items); err != nil {
+			return fmt.Errorf("encode JSON: %w", err)
+		}
+		return nil
+	}
+}
+
+func decodeRL(r io.Reader) ([]*model.Link, error) {
+	var entries []struct {
+		*model.Link
+		// Note is the single note of exports from before annotations
+		Note string `json:"note"`
+	}
+	if err := json.NewDecoder(r).Decode(&entries); err != nil {
+		return nil, fmt.Errorf("decode JSON: %w", err)
+	}
+	links := make([]*model.Link, len(entries))
+	for i, e := range entries {
+		if e.Link == nil {
+			e.Link = &model.Link{}
+		}
+		e.Annotations = append(notes(e.Note, e.CreatedAt), e.Annotations...)
+		links[i] = e.Link
+	}
+	return links, nil
+}
+
+// notes returns a tool's single notes field as an annotation dated when
+// the bookmark was saved, or nil if it is empty.
+func notes(text string, savedAt time.Time) []*model.Annotation {
+	if strings.TrimSpace(text) == "" {
+		return nil
+	}
+	return []*model.Annotation{{Text: text, CreatedAt: savedAt}}
+}
+
+// timeLayouts are the timestamp forms found in other tools' exports.
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+}
+
+// parseTime parses a timestamp in any of timeLayouts, returning the zero
+// time if s is empty or unrecognized.
+func parseTime(s string) time.Time {
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// joinTags joins tag names into rl's comma-separated form.
+func joinTags(names []string) string {
+	return strings.Join((&model.Link{Tags: strings.Join(names, ",")}).TagList(), ",")
+}
+
+// checkURLs reports the first link without a URL, numbering from 1.
+func checkURLs(links []*model.Link) error {
+	for i, link := range links {
+		if link.URL == "" {
+			return fmt.Errorf("entry %d has no URL", i+1)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,173 @@
+package formats
+
+import (
+	"archive/zip"
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bunchhieng/rl/internal/model"
+)
+
+func TestLookup(t *testing.T) {
+	if f, err := Lookup(""); err != nil || f.Name != "rl" {
+		t.Errorf("Lookup(\"\") = %v, %v; want rl", f, err)
+	}
+	if f, err := Lookup("Linkding"); err != nil || f.Name != "linkding" {
+		t.Errorf("Lookup should ignore case, got %v, %v", f, err)
+	}
+	if _, err := Lookup("pocket"); err == nil {
+		t.Error("Expected error for an unknown format")
+	}
+}
+
+func TestLinkding(t *testing.T) {
+	input := `{"count": 2, "results": [
+		{"url": "https://example.com/a", "title": "A", "notes": "n", "tag_names": ["go", "db"],
+		 "unread": true, "date_added": "2024-01-02T03:04:05.123Z"},
+		{"url": "https://example.com/b", "unread": false,
+		 "date_added": "2024-01-01T00:00:00Z", "date_modified": "2024-02-01T00:00:00Z"}
+	]}`
+	links, err := decodeLinkding(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("decodeLinkding failed: %v", err)
+	}
+	if len(links) != 2 {
+		t.Fatalf("Expected 2 links, got %d", len(links))
+	}
+	a, b := links[0], links[1]
+	if a.Title != "A" || a.NoteText() != "n" || a.Tags != "go,db" || a.IsRead() || a.CreatedAt.Year() != 2024 {
+		t.Errorf("Unexpected unread bookmark: %+v", a)
+	}
+	if !b.IsRead() || !b.ReadAt.Equal(time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("A bookmark not marked unread should be read at date_modified: %+v", b)
+	}
+
+	// Round trip through the export
+	var out bytes.Buffer
+	if err := encodeJSON(toLinkding)(&out, links); err != nil {
+		t.Fatal(err)
+	}
+	again, err := decodeLinkding(&out)
+	if err != nil {
+		t.Fatalf("Decoding the export failed: %v", err)
+	}
+	if again[0].Tags != "go,db" || again[0].NoteText() != "n" || again[0].IsRead() || !again[1].IsRead() {
+		t.Errorf("Round trip lost fields: %+v %+v", again[0], again[1])
+	}
+}
+
+func TestRL(t *testing.T) {
+	// Exports from before annotations carry a single note
+	input := `[{"url": "https://example.com/a", "note": "old note", "created_at": "2024-01-02T00:00:00Z",
+		"annotations": [{"text": "later", "created_at": "2024-03-01T00:00:00Z"}]},
+		{"url": "https://example.com/b", "created_at": "2024-01-01T00:00:00Z"}]`
+	links, err := decodeRL(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("decodeRL failed: %v", err)
+	}
+	if len(links) != 2 || links[0].URL != "https://example.com/a" || links[1].Annotations != nil {
+		t.Fatalf("Unexpected links: %+v", links)
+	}
+	notes := links[0].Annotations
+	if len(notes) != 2 || notes[0].Text != "old note" || !notes[0].CreatedAt.Equal(links[0].CreatedAt) || notes[1].Text != "later" {
+		t.Errorf("Expected the note, dated when the link was saved, before the later one, got %+v", notes)
+	}
+
+	var out bytes.Buffer
+	if err := encodeJSON(identity)(&out, links); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(out.String(), `"note"`) || !strings.Contains(out.String(), `"text": "old note"`) {
+		t.Errorf("Expected the notes exported as annotations, got %s", out.String())
+	}
+}
+
+func TestShiori(t *testing.T) {
+	input := `[{"id": 1, "url": "https://example.com/s", "title": "S", "excerpt": "About S",
+		"modified": "2023-05-06 07:08:09", "tags": [{"id": 1, "name": "rust"}]}]`
+	links, err := decodeShiori(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("decodeShiori failed: %v", err)
+	}
+	s := links[0]
+	if s.Title != "S" || s.Description != "About S" || s.Tags != "rust" || s.IsRead() {
+		t.Errorf("Unexpected link: %+v", s)
+	}
+	if !s.CreatedAt.Equal(time.Date(2023, 5, 6, 7, 8, 9, 0, time.UTC)) {
+		t.Errorf("CreatedAt = %v", s.CreatedAt)
+	}
+
+	var out bytes.Buffer
+	if err := encodeJSON(toShiori)(&out, []*model.Link{s}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), `"name": "rust"`) || !strings.Contains(out.String(), `"modified": "2023-05-06 07:08:09"`) {
+		t.Errorf("Unexpected Shiori export: %s", out.String())
+	}
+
+	if _, err := decodeShiori(strings.NewReader(`[{"title": "no url"}]`)); err == nil {
+		t.Error("Expected error for a bookmark without a URL")
+	}
+}
+
+func TestOmnivore(t *testing.T) {
+	var archive bytes.Buffer
+	zw := zip.NewWriter(&archive)
+	files := map[string]string{
+		"metadata_0_to_1.json": `[{"url": "https://example.com/o1", "title": "O1", "labels": ["go"],
+			"state": "Succeeded", "savedAt": "2024-03-01T10:00:00.000Z"}]`,
+		"metadata_1_to_2.json": `[{"url": "https://example.com/o2", "state": "Archived",
+			"savedAt": "2024-03-02T10:00:00.000Z", "updatedAt": "2024-03-05T10:00:00.000Z"}]`,
+		"content/o1.md": "# O1",
+	}
+	for name, body := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(body))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	links, err := decodeOmnivore(&archive)
+	if err != nil {
+		t.Fatalf("decodeOmnivore failed: %v", err)
+	}
+	if len(links) != 2 {
+		t.Fatalf("Expected 2 links, got %d", len(links))
+	}
+	if links[0].Title != "O1" || links[0].Tags != "go" || links[0].IsRead() {
+		t.Errorf("Unexpected unread item: %+v", links[0])
+	}
+	if !links[1].IsRead() || links[1].ReadAt.Day() != 5 {
+		t.Errorf("Archived item should be read at updatedAt: %+v", links[1])
+	}
+
+	if _, err := decodeOmnivore(strings.NewReader("not an archive")); err == nil {
+		t.Error("Expected error for input that is neither zip nor JSON")
+	}
+}
+
+func TestReadwise(t *testing.T) {
+	input := "Title,URL,ID,Document tags,Saved date,Reading progress,Location,Seen\n" +
+		"Later,https://example.com/r1,1,\"['go', 'db']\",2023-11-02 19:51:43+00:00,0,later,True\n" +
+		"Done,https://example.com/r2,2,,2023-11-01 08:00:00.123456+00:00,1,archive,True\n" +
+		"Feed item,https://example.com/r3,3,,2023-11-03 08:00:00+00:00,0,feed,False\n"
+	links, err := decodeReadwise(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("decodeReadwise failed: %v", err)
+	}
+	if len(links) != 2 {
+		t.Fatalf("Expected feed items to be skipped, got %d links", len(links))
+	}
+	if links[0].Tags != "go,db" || links[0].IsRead() || links[0].CreatedAt.Hour() != 19 {
+		t.Errorf("Unexpected later document: %+v", links[0])
+	}
+	if !links[1].IsRead() || links[1].CreatedAt.IsZero() {
+		t.Errorf("Archived document should be read: %+v", links[1])
+	}
+}
@@ -0,0 +1,92 @@
+package formats
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/bunchhieng/rl/internal/model"
+)
+
+// linkdingBookmark is a bookmark as served and accepted by linkding's REST
+// API (/api/bookmarks/). Unread marks a bookmark to read later.
+type linkdingBookmark struct {
+	URL          string    `json:"url"`
+	Title        string    `json:"title"`
+	Description  string    `json:"description"`
+	Notes        string    `json:"notes"`
+	TagNames     []string  `json:"tag_names"`
+	Unread       bool      `json:"unread"`
+	IsArchived   bool      `json:"is_archived"`
+	DateAdded    time.Time `json:"date_added"`
+	DateModified time.Time `json:"date_modified"`
+}
+
+// decodeLinkding reads a JSON array of bookmarks, or a page of API results
+// ({"results": [...]}).
+func decodeLinkding(r io.Reader) ([]*model.Link, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var bookmarks []linkdingBookmark
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		var page struct {
+			Results []linkdingBookmark `json:"results"`
+		}
+		err = json.Unmarshal(data, &page)
+		bookmarks = page.Results
+	} else {
+		err = json.Unmarshal(data, &bookmarks)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("decode linkding JSON: %w", err)
+	}
+
+	links := make([]*model.Link, len(bookmarks))
+	for i, b := range bookmarks {
+		link := &model.Link{
+			URL:         b.URL,
+			Title:       b.Title,
+			Description: b.Description,
+			Annotations: notes(b.Notes, b.DateAdded),
+			Tags:        joinTags(b.TagNames),
+			CreatedAt:   b.DateAdded,
+		}
+		// Bookmarks not marked to read later count as read
+		if !b.Unread {
+			readAt := b.DateModified
+			if readAt.IsZero() {
+				readAt = b.DateAdded
+			}
+			if readAt.IsZero() {
+				readAt = time.Now()
+			}
+			link.ReadAt = &readAt
+		}
+		links[i] = link
+	}
+	return links, checkURLs(links)
+}
+
+func toLinkding(link *model.Link) linkdingBookmark {
+	b := linkdingBookmark{
+		URL:          link.URL,
+		Title:        link.Title,
+		Description:  link.Description,
+		Notes:        link.NoteText(),
+		TagNames:     link.TagList(),
+		Unread:       !link.IsRead(),
+		DateAdded:    link.CreatedAt,
+		DateModified: link.CreatedAt,
+	}
+	if b.TagNames == nil {
+		b.TagNames = []string{}
+	}
+	if link.ReadAt != nil {
+		b.DateModified = *link.ReadAt
+	}
+	return b
+}
@@ -0,0 +1,99 @@
+package formats
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/bunchhieng/rl/internal/model"
+)
+
+// omnivoreItem is an entry in the metadata_*.json files of an Omnivore
+// export archive. Archived items have been read.
+type omnivoreItem struct {
+	URL         string   `json:"url"`
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	Labels      []string `json:"labels"`
+	State       string   `json:"state"`
+	SavedAt     string   `json:"savedAt"`
+	UpdatedAt   string   `json:"updatedAt"`
+}
+
+// decodeOmnivore reads an Omnivore export: the zip archive, or one of its
+// metadata JSON files on its own.
+func decodeOmnivore(r io.Reader) ([]*model.Link, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var items []omnivoreItem
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := json.Unmarshal(data, &items); err != nil {
+			return nil, fmt.Errorf("decode Omnivore JSON: %w", err)
+		}
+	} else if items, err = readOmnivoreArchive(data); err != nil {
+		return nil, err
+	}
+
+	links := make([]*model.Link, len(items))
+	for i, item := range items {
+		link := &model.Link{
+			URL:         item.URL,
+			Title:       item.Title,
+			Description: item.Description,
+			Tags:        joinTags(item.Labels),
+			CreatedAt:   parseTime(item.SavedAt),
+		}
+		if strings.EqualFold(item.State, "archived") {
+			readAt := parseTime(item.UpdatedAt)
+			if readAt.IsZero() {
+				readAt = link.CreatedAt
+			}
+			link.ReadAt = &readAt
+		}
+		links[i] = link
+	}
+	return links, checkURLs(links)
+}
+
+// readOmnivoreArchive collects the items of every metadata_*.json file in
+// the archive, in file name order.
+func readOmnivoreArchive(data []byte) ([]omnivoreItem, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("read Omnivore export: expected a zip archive or metadata JSON: %w", err)
+	}
+	var files []*zip.File
+	for _, f := range archive.File {
+		name := path.Base(f.Name)
+		if strings.HasPrefix(name, "metadata_") && strings.HasSuffix(name, ".json") {
+			files = append(files, f)
+		}
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("read Omnivore export: no metadata_*.json files in the archive")
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Name < files[j].Name })
+
+	var items []omnivoreItem
+	for _, f := range files {
+		rc, err := f.Open()
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", f.Name, err)
+		}
+		var page []omnivoreItem
+		err = json.NewDecoder(rc).Decode(&page)
+		rc.Close()
+		if err != nil {
+			return nil, fmt.Errorf("decode %s: %w", f.Name, err)
+		}
+		items = append(items, page...)
+	}
+	return items, nil
+}
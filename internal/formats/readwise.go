@@ -0,0 +1,83 @@
+package formats
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/bunchhieng/rl/internal/model"
+)
+
+// decodeReadwise reads the CSV export of Readwise Reader. Documents in the
+// archive location have been read; RSS feed items that were never saved
+// are skipped.
+func decodeReadwise(r io.Reader) ([]*model.Link, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("read Readwise CSV: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	if _, ok := columns["url"]; !ok {
+		return nil, fmt.Errorf("read Readwise CSV: no URL column")
+	}
+
+	var links []*model.Link
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read Readwise CSV: %w", err)
+		}
+		field := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		location := strings.ToLower(field("location"))
+		if location == "feed" {
+			continue
+		}
+		link := &model.Link{
+			URL:       field("url"),
+			Title:     field("title"),
+			Tags:      joinTags(parseReadwiseTags(field("document tags"))),
+			CreatedAt: parseTime(field("saved date")),
+		}
+		if link.URL == "" {
+			return nil, fmt.Errorf("read Readwise CSV: line %d has no URL", line)
+		}
+		if location == "archive" {
+			readAt := link.CreatedAt
+			link.ReadAt = &readAt
+		}
+		links = append(links, link)
+	}
+	return links, nil
+}
+
+// parseReadwiseTags parses the tag list Reader writes as a Python list
+// literal, e.g. "['go', 'databases']".
+func parseReadwiseTags(s string) []string {
+	s = strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(s, "["), "]"))
+	if s == "" {
+		return nil
+	}
+	var tags []string
+	for _, tag := range strings.Split(s, ",") {
+		tag = strings.Trim(strings.TrimSpace(tag), `'"`)
+		if tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
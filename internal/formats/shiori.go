@@ -0,0 +1,71 @@
+package formats
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/bunchhieng/rl/internal/model"
+)
+
+// shioriBookmark is a bookmark as printed by `shiori print --json` and
+// served by Shiori's API. Shiori has no read state, so imported links are
+// unread.
+type shioriBookmark struct {
+	URL      string      `json:"url"`
+	Title    string      `json:"title"`
+	Excerpt  string      `json:"excerpt"`
+	Tags     []shioriTag `json:"tags"`
+	Modified string      `json:"modified,omitempty"`
+	// Newer Shiori versions name the timestamps createdAt and modifiedAt.
+	CreatedAt  string `json:"createdAt,omitempty"`
+	ModifiedAt string `json:"modifiedAt,omitempty"`
+}
+
+type shioriTag struct {
+	Name string `json:"name"`
+}
+
+func decodeShiori(r io.Reader) ([]*model.Link, error) {
+	var bookmarks []shioriBookmark
+	if err := json.NewDecoder(r).Decode(&bookmarks); err != nil {
+		return nil, fmt.Errorf("decode Shiori JSON: %w", err)
+	}
+	links := make([]*model.Link, len(bookmarks))
+	for i, b := range bookmarks {
+		names := make([]string, len(b.Tags))
+		for j, tag := range b.Tags {
+			names[j] = tag.Name
+		}
+		created := parseTime(b.CreatedAt)
+		if created.IsZero() {
+			created = parseTime(b.Modified)
+		}
+		if created.IsZero() {
+			created = parseTime(b.ModifiedAt)
+		}
+		links[i] = &model.Link{
+			URL:         b.URL,
+			Title:       b.Title,
+			Description: b.Excerpt,
+			Tags:        joinTags(names),
+			CreatedAt:   created,
+		}
+	}
+	return links, checkURLs(links)
+}
+
+func toShiori(link *model.Link) shioriBookmark {
+	tags := make([]shioriTag, 0)
+	for _, name := range link.TagList() {
+		tags = append(tags, shioriTag{Name: name})
+	}
+	created := link.CreatedAt.UTC().Format("2006-01-02 15:04:05")
+	return shioriBookmark{
+		URL:      link.URL,
+		Title:    link.Title,
+		Excerpt:  link.Description,
+		Tags:     tags,
+		Modified: created,
+	}
+}
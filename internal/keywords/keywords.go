@@ -0,0 +1,130 @@
+// Package keywords suggests tags for an article from its own words,
+// without a language model.
+package keywords
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// titleWeight is how many mentions in the text a mention in the title is
+// worth; titles name what an article is about.
+const titleWeight = 3
+
+// Suggest returns up to n tags for the article titled title with text.
+// Tags from known, the ones already in use, come first if the article
+// mentions them; the rest are the words it uses most, which must appear in
+// the title or at least twice in the text.
+func Suggest(title, text string, known []string, n int) []string {
+	titleWords := words(title)
+	textWords := words(text)
+	score := func(phrase []string) (int, int) {
+		return count(titleWords, phrase), count(textWords, phrase)
+	}
+
+	type candidate struct {
+		tag   string
+		score int
+		known bool
+	}
+	var candidates []candidate
+	seen := map[string]bool{}
+	for _, tag := range known {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		phrase := words(tag)
+		if seen[tag] || len(phrase) == 0 {
+			continue
+		}
+		seen[tag] = true
+		if inTitle, inText := score(phrase); inTitle+inText > 0 {
+			candidates = append(candidates, candidate{tag: tag, score: titleWeight*inTitle + inText, known: true})
+		}
+	}
+	for _, w := range append(titleWords, textWords...) {
+		if seen[w] || len([]rune(w)) < 4 || stopWords[w] || isNumber(w) {
+			continue
+		}
+		seen[w] = true
+		if inTitle, inText := score([]string{w}); inTitle > 0 || inText >= 2 {
+			candidates = append(candidates, candidate{tag: w, score: titleWeight*inTitle + inText})
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if a.known != b.known {
+			return a.known
+		}
+		if a.score != b.score {
+			return a.score > b.score
+		}
+		return a.tag < b.tag
+	})
+	var tags []string
+	for _, c := range candidates {
+		if len(tags) == n {
+			break
+		}
+		tags = append(tags, c.tag)
+	}
+	return tags
+}
+
+// words splits s into lowercase words. Hyphens and underscores separate
+// words, so the tag "machine-learning" matches "machine learning".
+func words(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// count returns how many times phrase occurs in words.
+func count(words, phrase []string) int {
+	n := 0
+	for i := 0; i+len(phrase) <= len(words); i++ {
+		match := true
+		for j, w := range phrase {
+			if words[i+j] != w {
+				match = false
+				break
+			}
+		}
+		if match {
+			n++
+		}
+	}
+	return n
+}
+
+func isNumber(w string) bool {
+	for _, r := range w {
+		if !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// stopWords are common English words of four or more letters, which say
+// nothing about what an article is about.
+var stopWords = map[string]bool{}
+
+func init() {
+	for _, w := range strings.Fields(`
+		about above after again against almost also although always among another anyone
+		anything around available back based because been before being below best better
+		between both came come comes could does doing done down during each either else
+		enough even every everyone everything example fact first from further getting give
+		given going good great have having here however into itself just keep know known
+		last least less like likely look made make makes making many might more most much
+		must need needs never next nothing often once only other others ought ours over
+		part people perhaps really right said same says should show since some something
+		still such sure take than that their them themselves then there these they thing
+		things think this those though through time times today together too under until
+		upon used useful uses using very want ways well were what whatever when where
+		whether which while will with within without work works would year years your
+		yours yourself article post read page click share http https html`) {
+		stopWords[w] = true
+	}
+}
@@ -0,0 +1,23 @@
+package keywords
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSuggest(t *testing.T) {
+	title := "Profiling Go services in production"
+	text := "Profiling a service with pprof shows where time goes. The pprof tool reads profiles " +
+		"that Go writes; machine learning is not involved, and neither is Rust. Profiles from 2024 " +
+		"are compared with profiles from 2025 using pprof."
+
+	got := Suggest(title, text, []string{"Go", "machine-learning", "rust", "databases"}, 5)
+	want := []string{"go", "machine-learning", "rust", "profiling", "pprof"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Suggest() = %v, want %v", got, want)
+	}
+
+	if got := Suggest("Hello", "with with with", nil, 3); !reflect.DeepEqual(got, []string{"hello"}) {
+		t.Errorf("Suggest() = %v, want only the title word", got)
+	}
+}
@@ -0,0 +1,258 @@
+// Package llm asks language models to summarize and tag articles and to
+// embed text for semantic search, through an OpenAI-compatible API such as
+// OpenAI's or a local Ollama's.
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultEndpoint is Ollama's OpenAI-compatible API on this machine.
+	DefaultEndpoint = "http://localhost:11434/v1"
+	// DefaultSummaryPrompt asks for the summary rl shows.
+	DefaultSummaryPrompt = "Summarize the following article in two or three plain sentences. Reply with the summary only."
+	// requestTimeout leaves local models on modest hardware time to answer.
+	requestTimeout = 3 * time.Minute
+	// maxText bounds the article text sent, in characters, to fit the
+	// context of small models; the opening of an article says most.
+	maxText = 24000
+	// maxEmbedText bounds each text embedded, in characters, to fit the
+	// context of embedding models.
+	maxEmbedText = 8000
+	// maxResponse bounds the response read; a batch of embeddings runs to
+	// a few megabytes.
+	maxResponse = 32 << 20
+)
+
+var (
+	// ErrNoModel is returned when no chat model is configured.
+	ErrNoModel = errors.New("no language model configured; set llm.model in the config file (e.g. \"llama3.2\" for Ollama)")
+	// ErrNoEmbeddingModel is returned when no embedding model is
+	// configured.
+	ErrNoEmbeddingModel = errors.New("no embedding model configured; set llm.embedding_model in the config file (e.g. \"nomic-embed-text\" for Ollama)")
+)
+
+// Options configures a Client.
+type Options struct {
+	// Endpoint is the API's base URL, e.g. "https://api.openai.com/v1";
+	// empty means DefaultEndpoint.
+	Endpoint string
+	// Model writes summaries and suggests tags.
+	Model string
+	// EmbeddingModel turns text into vectors for semantic search.
+	EmbeddingModel string
+	// APIKey is sent as a bearer token; local servers need none.
+	APIKey string
+	// SummaryPrompt replaces DefaultSummaryPrompt.
+	SummaryPrompt string
+}
+
+// Client sends requests to a chat completions endpoint.
+type Client struct {
+	opts Options
+	http *http.Client
+}
+
+// NewClient returns a client for opts, or ErrNoModel without either
+// model.
+func NewClient(opts Options) (*Client, error) {
+	if opts.Model == "" && opts.EmbeddingModel == "" {
+		return nil, ErrNoModel
+	}
+	if opts.Endpoint == "" {
+		opts.Endpoint = DefaultEndpoint
+	}
+	opts.Endpoint = strings.TrimSuffix(opts.Endpoint, "/")
+	if opts.SummaryPrompt == "" {
+		opts.SummaryPrompt = DefaultSummaryPrompt
+	}
+	return &Client{opts: opts, http: &http.Client{Timeout: requestTimeout}}, nil
+}
+
+// Model returns the name of the chat model.
+func (c *Client) Model() string {
+	return c.opts.Model
+}
+
+// EmbeddingModel returns the name of the embedding model.
+func (c *Client) EmbeddingModel() string {
+	return c.opts.EmbeddingModel
+}
+
+// Summarize returns a summary of the article titled title with text.
+func (c *Client) Summarize(ctx context.Context, title, text string) (string, error) {
+	summary, err := c.complete(ctx, c.opts.SummaryPrompt, article(title, text))
+	if err != nil {
+		return "", fmt.Errorf("summarize: %w", err)
+	}
+	return summary, nil
+}
+
+// SuggestTags returns up to n tags for the article titled title with
+// text, lowercase and without repeats. The model is asked to prefer known,
+// the tags already in use, so suggestions fit the library.
+func (c *Client) SuggestTags(ctx context.Context, title, text string, known []string, n int) ([]string, error) {
+	prompt := fmt.Sprintf("Suggest up to %d short tags for the following article, to file it in a reading list. "+
+		"Use lowercase, one or two words each.", n)
+	if len(known) > 0 {
+		prompt += " Prefer these existing tags where they fit: " + strings.Join(known, ", ") + "."
+	}
+	prompt += " Reply with the tags only, separated by commas."
+	reply, err := c.complete(ctx, prompt, article(title, text))
+	if err != nil {
+		return nil, fmt.Errorf("suggest tags: %w", err)
+	}
+
+	var tags []string
+	seen := map[string]bool{}
+	for _, tag := range strings.FieldsFunc(reply, func(r rune) bool { return r == ',' || r == '\n' }) {
+		// Models like to number, bullet, or hashtag their lists
+		tag = strings.ToLower(strings.Trim(tag, " \t*-#.0123456789)\"'`"))
+		if tag == "" || seen[tag] || len(tag) > 40 {
+			continue
+		}
+		seen[tag] = true
+		tags = append(tags, tag)
+		if len(tags) == n {
+			break
+		}
+	}
+	return tags, nil
+}
+
+// article joins a title and text for a prompt, cutting the text to maxText.
+func article(title, text string) string {
+	if runes := []rune(text); len(runes) > maxText {
+		text = string(runes[:maxText])
+	}
+	if title == "" {
+		return text
+	}
+	return title + "\n\n" + text
+}
+
+type message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// complete sends the system prompt and user message and returns the
+// model's reply.
+func (c *Client) complete(ctx context.Context, system, user string) (string, error) {
+	if c.opts.Model == "" {
+		return "", ErrNoModel
+	}
+	var result struct {
+		Choices []struct {
+			Message message `json:"message"`
+		} `json:"choices"`
+	}
+	err := c.post(ctx, "/chat/completions", struct {
+		Model       string    `json:"model"`
+		Messages    []message `json:"messages"`
+		Temperature float64   `json:"temperature"`
+	}{
+		Model:       c.opts.Model,
+		Messages:    []message{{Role: "system", Content: system}, {Role: "user", Content: user}},
+		Temperature: 0.2,
+	}, &result)
+	if err != nil {
+		return "", err
+	}
+	if len(result.Choices) == 0 || strings.TrimSpace(result.Choices[0].Message.Content) == "" {
+		return "", errors.New("the model returned nothing")
+	}
+	return strings.TrimSpace(result.Choices[0].Message.Content), nil
+}
+
+// Embed returns a vector for each of texts, in order, from the embedding
+// model. Texts that mean similar things get vectors pointing in similar
+// directions.
+func (c *Client) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	if c.opts.EmbeddingModel == "" {
+		return nil, ErrNoEmbeddingModel
+	}
+	input := make([]string, len(texts))
+	for i, text := range texts {
+		if runes := []rune(text); len(runes) > maxEmbedText {
+			text = string(runes[:maxEmbedText])
+		}
+		input[i] = text
+	}
+	var result struct {
+		Data []struct {
+			Index     int       `json:"index"`
+			Embedding []float32 `json:"embedding"`
+		} `json:"data"`
+	}
+	err := c.post(ctx, "/embeddings", struct {
+		Model string   `json:"model"`
+		Input []string `json:"input"`
+	}{Model: c.opts.EmbeddingModel, Input: input}, &result)
+	if err != nil {
+		return nil, fmt.Errorf("embed: %w", err)
+	}
+	vectors := make([][]float32, len(texts))
+	for _, d := range result.Data {
+		if d.Index >= 0 && d.Index < len(vectors) {
+			vectors[d.Index] = d.Embedding
+		}
+	}
+	for _, v := range vectors {
+		if len(v) == 0 {
+			return nil, fmt.Errorf("embed: the model returned %d of %d vectors", len(result.Data), len(texts))
+		}
+	}
+	return vectors, nil
+}
+
+// post sends request as JSON to the endpoint's path and decodes the
+// response into response, turning API errors into Go errors.
+func (c *Client) post(ctx context.Context, path string, request, response any) error {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.opts.Endpoint+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.opts.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.opts.APIKey)
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxResponse))
+	if err != nil {
+		return fmt.Errorf("read response: %w", err)
+	}
+	var apiErr struct {
+		Error *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if json.Unmarshal(data, &apiErr) == nil && apiErr.Error != nil {
+		return fmt.Errorf("%s: %s", resp.Status, apiErr.Error.Message)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return errors.New(resp.Status)
+	}
+	if err := json.Unmarshal(data, response); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSummarize(t *testing.T) {
+	var got struct {
+		Model    string    `json:"model"`
+		Messages []message `json:"messages"`
+	}
+	var auth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" {
+			http.NotFound(w, r)
+			return
+		}
+		auth = r.Header.Get("Authorization")
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("Decode request: %v", err)
+		}
+		if got.Model == "missing" {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":{"message":"model \"missing\" not found"}}`))
+			return
+		}
+		w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"  Go is fast.\n"}}]}`))
+	}))
+	defer server.Close()
+
+	c, err := NewClient(Options{Endpoint: server.URL + "/v1/", Model: "llama3.2", APIKey: "sk-test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	summary, err := c.Summarize(context.Background(), "Why Go", strings.Repeat("x", maxText+100))
+	if err != nil {
+		t.Fatalf("Summarize() error: %v", err)
+	}
+	if summary != "Go is fast." {
+		t.Errorf("Summarize() = %q", summary)
+	}
+	if auth != "Bearer sk-test" {
+		t.Errorf("Authorization = %q", auth)
+	}
+	if len(got.Messages) != 2 || got.Messages[0].Content != DefaultSummaryPrompt || !strings.HasPrefix(got.Messages[1].Content, "Why Go\n\n") {
+		t.Errorf("Unexpected messages: %+v", got.Messages)
+	}
+	if n := len(got.Messages[1].Content); n != len("Why Go\n\n")+maxText {
+		t.Errorf("Expected the text cut to %d characters, sent %d", maxText, n)
+	}
+
+	c, _ = NewClient(Options{Endpoint: server.URL + "/v1", Model: "missing"})
+	if _, err := c.Summarize(context.Background(), "", "text"); err == nil || !strings.Contains(err.Error(), `model "missing" not found`) {
+		t.Errorf("Expected the API's error message, got %v", err)
+	}
+
+	if _, err := NewClient(Options{}); err == nil {
+		t.Error("Expected an error without a model")
+	}
+}
+
+func TestSuggestTags(t *testing.T) {
+	var system string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Messages []message `json:"messages"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("Decode request: %v", err)
+		}
+		system = req.Messages[0].Content
+		w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"1. Go, #concurrency,\n- go\n\"Databases\", testing"}}]}`))
+	}))
+	defer server.Close()
+
+	c, err := NewClient(Options{Endpoint: server.URL, Model: "llama3.2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tags, err := c.SuggestTags(context.Background(), "Go channels", "text", []string{"go", "rust"}, 3)
+	if err != nil {
+		t.Fatalf("SuggestTags() error: %v", err)
+	}
+	if want := "go,concurrency,databases"; strings.Join(tags, ",") != want {
+		t.Errorf("SuggestTags() = %q, want %q", tags, want)
+	}
+	if !strings.Contains(system, "up to 3") || !strings.Contains(system, "go, rust") {
+		t.Errorf("Expected the limit and known tags in the prompt, got %q", system)
+	}
+}
+
+func TestEmbed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Model string   `json:"model"`
+			Input []string `json:"input"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("Decode request: %v", err)
+		}
+		if r.URL.Path != "/embeddings" || req.Model != "nomic-embed-text" || len(req.Input) != 2 || len(req.Input[1]) != maxEmbedText {
+			t.Errorf("Unexpected request to %s: %s, %d inputs", r.URL.Path, req.Model, len(req.Input))
+		}
+		// Out of order, as the API allows
+		w.Write([]byte(`{"data":[{"index":1,"embedding":[0,1]},{"index":0,"embedding":[1,0]}]}`))
+	}))
+	defer server.Close()
+
+	c, err := NewClient(Options{Endpoint: server.URL, EmbeddingModel: "nomic-embed-text"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	vectors, err := c.Embed(context.Background(), []string{"a", strings.Repeat("b", maxEmbedText+10)})
+	if err != nil {
+		t.Fatalf("Embed() error: %v", err)
+	}
+	if len(vectors) != 2 || vectors[0][0] != 1 || vectors[1][1] != 1 {
+		t.Errorf("Embed() = %v", vectors)
+	}
+	if _, err := c.Summarize(context.Background(), "", "text"); !errors.Is(err, ErrNoModel) {
+		t.Errorf("Expected ErrNoModel without a chat model, got %v", err)
+	}
+}
@@ -0,0 +1,97 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/bunchhieng/rl/internal/model"
+	"github.com/bunchhieng/rl/internal/storage"
+)
+
+func TestServe(t *testing.T) {
+	s, err := storage.NewSQLiteStorage(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	ctx := context.Background()
+	existing, err := s.Add(ctx, &model.Link{URL: "https://example.com/old", Title: "Old"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	input := strings.Join([]string{
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2024-11-05"}}`,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/list"}`,
+		`{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"add_link","arguments":{"url":"https://example.com/new","tags":"go, mcp"}}}`,
+		`{"jsonrpc":"2.0","id":4,"method":"tools/call","params":{"name":"mark_read","arguments":{"id":"` + existing.ID[:6] + `"}}}`,
+		`{"jsonrpc":"2.0","id":5,"method":"tools/call","params":{"name":"list_links","arguments":{"status":"read"}}}`,
+		`{"jsonrpc":"2.0","id":6,"method":"tools/call","params":{"name":"mark_read","arguments":{"id":"missing"}}}`,
+		`{"jsonrpc":"2.0","id":7,"method":"resources/list"}`,
+	}, "\n")
+	var out bytes.Buffer
+	server := NewServer(s, Options{Version: "test"})
+	if err := server.Serve(ctx, strings.NewReader(input), &out); err != nil {
+		t.Fatalf("Serve error: %v", err)
+	}
+
+	type reply struct {
+		ID     int             `json:"id"`
+		Result json.RawMessage `json:"result"`
+		Error  *rpcError       `json:"error"`
+	}
+	var responses []reply
+	dec := json.NewDecoder(&out)
+	for dec.More() {
+		var r reply
+		if err := dec.Decode(&r); err != nil {
+			t.Fatal(err)
+		}
+		responses = append(responses, r)
+	}
+	if len(responses) != 7 {
+		t.Fatalf("Expected 7 responses (none for the notification), got %d", len(responses))
+	}
+
+	if !strings.Contains(string(responses[0].Result), `"protocolVersion":"2024-11-05"`) {
+		t.Errorf("initialize should echo a supported version: %s", responses[0].Result)
+	}
+	if !strings.Contains(string(responses[1].Result), `"add_link"`) {
+		t.Errorf("tools/list missing add_link: %s", responses[1].Result)
+	}
+
+	var result toolResult
+	if err := json.Unmarshal(responses[2].Result, &result); err != nil || result.IsError {
+		t.Fatalf("add_link failed: %s", responses[2].Result)
+	}
+	var added model.Link
+	if err := json.Unmarshal([]byte(result.Content[0].Text), &added); err != nil {
+		t.Fatal(err)
+	}
+	if added.URL != "https://example.com/new" || added.Tags != "go,mcp" {
+		t.Errorf("Unexpected added link: %+v", added)
+	}
+
+	link, err := s.Get(ctx, existing.ID)
+	if err != nil || !link.IsRead() {
+		t.Errorf("mark_read by ID prefix should mark the link read")
+	}
+	if err := json.Unmarshal(responses[4].Result, &result); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(result.Content[0].Text, existing.ID) || strings.Contains(result.Content[0].Text, added.ID) {
+		t.Errorf("list_links status=read should list only the read link: %s", result.Content[0].Text)
+	}
+
+	result = toolResult{}
+	if err := json.Unmarshal(responses[5].Result, &result); err != nil || !result.IsError {
+		t.Errorf("Expected a tool error for an unknown ID: %s", responses[5].Result)
+	}
+	if responses[6].Error == nil || responses[6].Error.Code != codeMethodNotFound {
+		t.Errorf("Expected method not found, got %+v", responses[6])
+	}
+}
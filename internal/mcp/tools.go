@@ -0,0 +1,288 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/bunchhieng/rl/internal/adder"
+	"github.com/bunchhieng/rl/internal/fetch"
+	"github.com/bunchhieng/rl/internal/model"
+	"github.com/bunchhieng/rl/internal/storage"
+)
+
+// defaultLimit caps list and search results unless the client asks for more,
+// to keep responses within an assistant's context.
+const defaultLimit = 50
+
+type tool struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	InputSchema map[string]any `json:"inputSchema"`
+}
+
+func schema(required []string, props map[string]any) map[string]any {
+	s := map[string]any{"type": "object", "properties": props}
+	if len(required) > 0 {
+		s["required"] = required
+	}
+	return s
+}
+
+func prop(typ, description string) map[string]any {
+	return map[string]any{"type": typ, "description": description}
+}
+
+var toolList = []tool{
+	{
+		Name:        "search_links",
+		Description: "Full-text search of saved links by title, URL, tags, description, notes, and highlighted quotes. Returns matching links as JSON, best match first, each with a match object giving the matched field and a snippet. If nothing matches, returns links with similar titles or URLs after a note saying they are approximate.",
+		InputSchema: schema([]string{"query"}, map[string]any{
+			"query":   prop("string", `words, "quoted phrases", AND/OR/NOT, and field:term, e.g. "event loop" OR golang`),
+			"content": prop("boolean", "also search the archived article text"),
+			"limit":   prop("integer", fmt.Sprintf("maximum number of results (default %d)", defaultLimit)),
+		}),
+	},
+	{
+		Name:        "list_links",
+		Description: "List saved links as JSON. By default lists unread links, high priority first, then newest.",
+		InputSchema: schema(nil, map[string]any{
+			"status": map[string]any{"type": "string", "enum": []string{"unread", "read", "all"}, "description": "read status to include (default unread)"},
+			"tag":    prop("string", "only links with this tag"),
+			"domain": prop("string", "only links from this domain or its subdomains"),
+			"sort":   map[string]any{"type": "string", "enum": sortNames(), "description": "sort order (default: priority, then newest)"},
+			"limit":  prop("integer", fmt.Sprintf("maximum number of results (default %d)", defaultLimit)),
+		}),
+	},
+	{
+		Name:        "add_link",
+		Description: "Save a URL to the reading list. Saving a URL that already exists updates it. Returns the saved link as JSON.",
+		InputSchema: schema([]string{"url"}, map[string]any{
+			"url":      prop("string", "http or https URL"),
+			"title":    prop("string", "title (fetched from the page when omitted)"),
+			"note":     prop("string", "a note to add to the link, unless it has it already"),
+			"tags":     prop("string", "comma-separated tags"),
+			"priority": map[string]any{"type": "string", "enum": []string{"high", "normal", "low"}, "description": "priority (default normal; a link saved before keeps its own)"},
+		}),
+	},
+	{
+		Name:        "mark_read",
+		Description: "Mark a saved link as read.",
+		InputSchema: schema([]string{"id"}, map[string]any{
+			"id": prop("string", "link ID, unique ID prefix, or alias"),
+		}),
+	},
+}
+
+func sortNames() []string {
+	names := make([]string, len(storage.SortOrders))
+	for i, order := range storage.SortOrders {
+		names[i] = string(order)
+	}
+	return names
+}
+
+// toolResult is the result of tools/call. Tool failures are reported in
+// the result with IsError set, so the assistant can see and react to them.
+type toolResult struct {
+	Content []textContent `json:"content"`
+	IsError bool          `json:"isError,omitempty"`
+}
+
+type textContent struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+func textResult(text string) *toolResult {
+	return &toolResult{Content: []textContent{{Type: "text", Text: text}}}
+}
+
+func jsonResult(v any) (*toolResult, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return textResult(string(data)), nil
+}
+
+func (s *Server) callTool(ctx context.Context, params json.RawMessage) (any, error) {
+	var p struct {
+		Name      string          `json:"name"`
+		Arguments json.RawMessage `json:"arguments"`
+	}
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, fmt.Errorf("invalid tools/call params: %w", err)
+	}
+	if len(p.Arguments) == 0 {
+		p.Arguments = json.RawMessage("{}")
+	}
+
+	var call func(context.Context, json.RawMessage) (*toolResult, error)
+	switch p.Name {
+	case "search_links":
+		call = s.searchLinks
+	case "list_links":
+		call = s.listLinks
+	case "add_link":
+		call = s.addLink
+	case "mark_read":
+		call = s.markRead
+	default:
+		return nil, fmt.Errorf("unknown tool %q", p.Name)
+	}
+	result, err := call(ctx, p.Arguments)
+	if err != nil {
+		result = textResult(err.Error())
+		result.IsError = true
+	}
+	return result, nil
+}
+
+func decodeArgs(args json.RawMessage, v any) error {
+	if err := json.Unmarshal(args, v); err != nil {
+		return fmt.Errorf("invalid arguments: %w", err)
+	}
+	return nil
+}
+
+// limitLinks truncates links to limit (or defaultLimit), returning an empty
+// slice rather than nil so results always encode as a JSON array.
+func limitLinks(links []*model.Link, limit int) []*model.Link {
+	if links == nil {
+		return []*model.Link{}
+	}
+	if limit <= 0 {
+		limit = defaultLimit
+	}
+	if len(links) > limit {
+		links = links[:limit]
+	}
+	return links
+}
+
+func (s *Server) searchLinks(ctx context.Context, args json.RawMessage) (*toolResult, error) {
+	var a struct {
+		Query   string `json:"query"`
+		Content bool   `json:"content"`
+		Limit   int    `json:"limit"`
+	}
+	if err := decodeArgs(args, &a); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(a.Query) == "" {
+		return nil, fmt.Errorf("query is required")
+	}
+	links, err := s.storage.Search(ctx, a.Query, storage.SearchOptions{Content: a.Content})
+	if err != nil {
+		return nil, err
+	}
+	if len(links) > 0 {
+		return jsonResult(limitLinks(links, a.Limit))
+	}
+
+	// Fall back to similar titles and URLs, saying so
+	links, err = s.storage.SearchApproximate(ctx, a.Query, storage.SearchOptions{}, a.Limit)
+	if err != nil {
+		return nil, err
+	}
+	result, err := jsonResult(limitLinks(links, a.Limit))
+	if err != nil || len(links) == 0 {
+		return result, err
+	}
+	note := textContent{Type: "text", Text: "No exact matches; these results are approximate (similar titles or URLs)."}
+	result.Content = append([]textContent{note}, result.Content...)
+	return result, nil
+}
+
+func (s *Server) listLinks(ctx context.Context, args json.RawMessage) (*toolResult, error) {
+	var a struct {
+		Status string `json:"status"`
+		Tag    string `json:"tag"`
+		Domain string `json:"domain"`
+		Sort   string `json:"sort"`
+		Limit  int    `json:"limit"`
+	}
+	if err := decodeArgs(args, &a); err != nil {
+		return nil, err
+	}
+	opts := storage.ListOptions{Tag: a.Tag, Domain: a.Domain}
+	switch a.Status {
+	case "", "unread":
+		opts.ReadStatus = storage.ReadStatusUnread
+	case "read":
+		opts.ReadStatus = storage.ReadStatusRead
+	case "all":
+		opts.ReadStatus = storage.ReadStatusAll
+	default:
+		return nil, fmt.Errorf("invalid status %q (use unread, read, or all)", a.Status)
+	}
+	sort, err := storage.ParseSortOrder(a.Sort)
+	if err != nil {
+		return nil, err
+	}
+	opts.Sort = sort
+	opts.Limit = a.Limit
+	if opts.Limit <= 0 {
+		opts.Limit = defaultLimit
+	}
+	links, err := s.storage.List(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return jsonResult(limitLinks(links, opts.Limit))
+}
+
+func (s *Server) addLink(ctx context.Context, args json.RawMessage) (*toolResult, error) {
+	var a struct {
+		URL      string `json:"url"`
+		Title    string `json:"title"`
+		Note     string `json:"note"`
+		Tags     string `json:"tags"`
+		Priority string `json:"priority"`
+	}
+	if err := decodeArgs(args, &a); err != nil {
+		return nil, err
+	}
+	// Without a priority, a link saved before keeps its own
+	var priority *model.Priority
+	if a.Priority != "" {
+		p, err := model.ParsePriority(a.Priority)
+		if err != nil {
+			return nil, err
+		}
+		priority = &p
+	}
+	// Like rl add, the link is kept even if the page can't be fetched
+	result, err := adder.New(s.storage, fetch.NewClient(), s.opts.Attachments).Add(ctx, strings.TrimSpace(a.URL), adder.Options{
+		Title:        a.Title,
+		Note:         a.Note,
+		Tags:         strings.Join((&model.Link{Tags: a.Tags}).TagList(), ","),
+		Priority:     priority,
+		Fetch:        s.opts.Fetch,
+		Canonicalize: s.opts.Canonicalize,
+		Attach:       s.opts.Attach,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return jsonResult(result.Link)
+}
+
+func (s *Server) markRead(ctx context.Context, args json.RawMessage) (*toolResult, error) {
+	var a struct {
+		ID string `json:"id"`
+	}
+	if err := decodeArgs(args, &a); err != nil {
+		return nil, err
+	}
+	id, err := s.storage.ResolveID(ctx, strings.TrimSpace(a.ID))
+	if err != nil {
+		return nil, fmt.Errorf("link %q: %w", a.ID, err)
+	}
+	if err := s.storage.MarkRead(ctx, id); err != nil {
+		return nil, err
+	}
+	return textResult(fmt.Sprintf("Marked link %s as read.", id)), nil
+}
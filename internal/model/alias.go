@@ -0,0 +1,22 @@
+package model
+
+// maxAliasLen bounds the length of a user-defined link alias.
+const maxAliasLen = 64
+
+// ValidateAlias validates a user-defined link alias such as "golang-sched".
+// Aliases start with a letter and contain only letters, digits, '-', '_',
+// and '.'.
+func ValidateAlias(name string) bool {
+	if len(name) == 0 || len(name) > maxAliasLen {
+		return false
+	}
+	for i, c := range name {
+		switch {
+		case (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z'):
+		case i > 0 && ((c >= '0' && c <= '9') || c == '-' || c == '_' || c == '.'):
+		default:
+			return false
+		}
+	}
+	return true
+}
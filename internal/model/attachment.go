@@ -0,0 +1,16 @@
+package model
+
+import "time"
+
+// Attachment is a downloaded copy of the document a link points to, such
+// as a PDF.
+type Attachment struct {
+	LinkID string `json:"link_id"`
+	// SHA256 is the hex digest of the file's contents.
+	SHA256 string `json:"sha256"`
+	// Path locates the file relative to the attachments directory.
+	Path        string    `json:"path"`
+	ContentType string    `json:"content_type,omitempty"`
+	Size        int64     `json:"size"`
+	CreatedAt   time.Time `json:"created_at"`
+}
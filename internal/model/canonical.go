@@ -0,0 +1,105 @@
+package model
+
+import (
+	"net/url"
+	"strings"
+)
+
+// trackingParams lists query parameters that only carry tracking information.
+var trackingParams = map[string]bool{
+	"fbclid":  true,
+	"gclid":   true,
+	"dclid":   true,
+	"msclkid": true,
+	"yclid":   true,
+	"igshid":  true,
+	"mc_cid":  true,
+	"mc_eid":  true,
+	"_hsenc":  true,
+	"_hsmi":   true,
+	"mkt_tok": true,
+}
+
+// isTrackingParam reports whether a query parameter name is a known tracker.
+func isTrackingParam(name string) bool {
+	name = strings.ToLower(name)
+	return strings.HasPrefix(name, "utm_") || trackingParams[name]
+}
+
+// CanonicalizeURL normalizes a URL so that the same page saved from
+// different share links maps to one entry: the scheme and host are
+// lowercased, default ports and tracking parameters (utm_*, fbclid, ...)
+// are removed, and fragments are dropped unless they look like client-side
+// routes ("#/..." or "#!...").
+func CanonicalizeURL(rawURL string) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return "", ErrInvalidURL
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+	host := strings.ToLower(u.Hostname())
+	port := u.Port()
+	if (u.Scheme == "http" && port == "80") || (u.Scheme == "https" && port == "443") {
+		port = ""
+	}
+	if port != "" {
+		host += ":" + port
+	}
+	u.Host = host
+
+	if u.RawQuery != "" {
+		params := strings.Split(u.RawQuery, "&")
+		kept := params[:0]
+		for _, param := range params {
+			name := param
+			if i := strings.IndexByte(param, '='); i >= 0 {
+				name = param[:i]
+			}
+			if param == "" || isTrackingParam(name) {
+				continue
+			}
+			kept = append(kept, param)
+		}
+		u.RawQuery = strings.Join(kept, "&")
+	}
+	u.ForceQuery = false
+
+	if !strings.HasPrefix(u.Fragment, "/") && !strings.HasPrefix(u.Fragment, "!") {
+		u.Fragment = ""
+		u.RawFragment = ""
+	}
+
+	if u.Path == "/" && u.RawQuery == "" && u.Fragment == "" {
+		u.Path = ""
+		u.RawPath = ""
+	}
+
+	return u.String(), nil
+}
+
+// IsTrivialRedirect reports whether a redirect from one URL to another only
+// changes the scheme to https, adds or removes "www.", or toggles a trailing
+// slash, so the target can safely replace the original.
+func IsTrivialRedirect(from, to string) bool {
+	a, err := url.Parse(from)
+	if err != nil {
+		return false
+	}
+	b, err := url.Parse(to)
+	if err != nil {
+		return false
+	}
+	if a.Scheme != b.Scheme && !(a.Scheme == "http" && b.Scheme == "https") {
+		return false
+	}
+	hostA := strings.TrimPrefix(strings.ToLower(a.Hostname()), "www.")
+	hostB := strings.TrimPrefix(strings.ToLower(b.Hostname()), "www.")
+	if hostA != hostB {
+		return false
+	}
+	if strings.TrimSuffix(a.EscapedPath(), "/") != strings.TrimSuffix(b.EscapedPath(), "/") {
+		return false
+	}
+	return a.RawQuery == b.RawQuery
+}
@@ -0,0 +1,51 @@
+package model
+
+import "testing"
+
+func TestCanonicalizeURL(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"https://Example.COM/Path", "https://example.com/Path"},
+		{"HTTPS://example.com:443/", "https://example.com"},
+		{"http://example.com:8080/a", "http://example.com:8080/a"},
+		{"https://example.com/a?utm_source=x&id=5&utm_medium=y", "https://example.com/a?id=5"},
+		{"https://example.com/a?fbclid=abc", "https://example.com/a"},
+		{"https://example.com/a#section-2", "https://example.com/a"},
+		{"https://example.com/#/inbox", "https://example.com/#/inbox"},
+		{"https://example.com/a?b=1&b=2", "https://example.com/a?b=1&b=2"},
+	}
+	for _, tt := range tests {
+		got, err := CanonicalizeURL(tt.in)
+		if err != nil {
+			t.Errorf("CanonicalizeURL(%q) error: %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("CanonicalizeURL(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+
+	if _, err := CanonicalizeURL("not a url"); err != ErrInvalidURL {
+		t.Errorf("Expected ErrInvalidURL, got %v", err)
+	}
+}
+
+func TestIsTrivialRedirect(t *testing.T) {
+	tests := []struct {
+		from, to string
+		want     bool
+	}{
+		{"http://example.com/a", "https://example.com/a", true},
+		{"https://example.com/a", "https://www.example.com/a/", true},
+		{"https://example.com/a", "https://example.com/b", false},
+		{"https://bit.ly/x", "https://example.com/x", false},
+		{"https://example.com/a", "http://example.com/a", false},
+	}
+	for _, tt := range tests {
+		if got := IsTrivialRedirect(tt.from, tt.to); got != tt.want {
+			t.Errorf("IsTrivialRedirect(%q, %q) = %v, want %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,13 @@
+package model
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// ContentHash fingerprints archived article text, to tell whether a page
+// changed since it was saved.
+func ContentHash(text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:])
+}
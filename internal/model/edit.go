@@ -0,0 +1,20 @@
+package model
+
+import "time"
+
+// Fields whose edits are kept in a link's history.
+const (
+	FieldTitle = "title"
+	FieldTags  = "tags"
+	FieldURL   = "url"
+)
+
+// Edit is one change to a link's title, tags, or URL.
+type Edit struct {
+	ID        int64     `json:"id"`
+	LinkID    string    `json:"link_id"`
+	Field     string    `json:"field"`
+	Old       string    `json:"old"`
+	New       string    `json:"new"`
+	ChangedAt time.Time `json:"changed_at"`
+}
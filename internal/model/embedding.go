@@ -0,0 +1,17 @@
+package model
+
+import "time"
+
+// Embedding is a vector standing for the meaning of a link's title,
+// description, and summary, for semantic search.
+type Embedding struct {
+	LinkID string
+	// Model names the embedding model that computed it; vectors from
+	// different models can't be compared.
+	Model string
+	// TextHash is the SHA-256 of the text embedded, to tell when the link
+	// has changed since.
+	TextHash  string
+	Vector    []float32
+	CreatedAt time.Time
+}
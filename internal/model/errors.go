@@ -1,6 +1,10 @@
 package model
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
 
 var (
 	// ErrNotFound indicates a link was not found.
@@ -11,4 +15,30 @@ var (
 
 	// ErrDuplicate indicates a duplicate URL already exists.
 	ErrDuplicate = errors.New("duplicate URL")
+
+	// ErrInvalidPriority indicates an unknown priority level was provided.
+	ErrInvalidPriority = errors.New("invalid priority")
+
+	// ErrInvalidAlias indicates a malformed link alias was provided.
+	ErrInvalidAlias = errors.New("invalid alias: must start with a letter and contain only letters, digits, '-', '_', or '.'")
+
+	// ErrInvalidQuery indicates a malformed search query.
+	ErrInvalidQuery = errors.New("invalid search query")
+
+	// ErrAmbiguousID indicates an ID prefix matches more than one link.
+	ErrAmbiguousID = errors.New("ambiguous ID prefix")
 )
+
+// AmbiguousIDError reports the links matched by an ambiguous ID prefix.
+type AmbiguousIDError struct {
+	Prefix     string
+	Candidates []string
+}
+
+func (e *AmbiguousIDError) Error() string {
+	return fmt.Sprintf("%s %q matches: %s", ErrAmbiguousID, e.Prefix, strings.Join(e.Candidates, ", "))
+}
+
+func (e *AmbiguousIDError) Unwrap() error {
+	return ErrAmbiguousID
+}
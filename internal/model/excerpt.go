@@ -0,0 +1,19 @@
+package model
+
+import "time"
+
+// Excerpt is a dated piece of text kept with a link. Annotations and quotes
+// are both excerpts, kept apart so each can be listed and numbered on its
+// own.
+type Excerpt struct {
+	ID        int64     `json:"id"`
+	LinkID    string    `json:"link_id"`
+	Text      string    `json:"text"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Annotation is a dated note added to a link, e.g. while reading it.
+type Annotation Excerpt
+
+// Quote is a passage highlighted from a link.
+type Quote Excerpt
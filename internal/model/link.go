@@ -8,13 +8,107 @@ import (
 
 // Link represents a saved URL with metadata.
 type Link struct {
-	ID        string     `json:"id"`
-	URL       string     `json:"url"`
-	Title     string     `json:"title,omitempty"`
-	Note      string     `json:"note,omitempty"`
-	Tags      string     `json:"tags,omitempty"`
-	CreatedAt time.Time  `json:"created_at"`
-	ReadAt    *time.Time `json:"read_at,omitempty"`
+	ID    string `json:"id"`
+	URL   string `json:"url"`
+	Title string `json:"title,omitempty"`
+	// Description is the page's meta description, captured when fetching.
+	Description string     `json:"description,omitempty"`
+	Tags        string     `json:"tags,omitempty"`
+	Domain      string     `json:"domain,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	ReadAt      *time.Time `json:"read_at,omitempty"`
+	Priority    Priority   `json:"priority,omitempty"`
+	// SnoozedUntil hides the link from the unread queue until this time.
+	SnoozedUntil *time.Time `json:"snoozed_until,omitempty"`
+	// ArchivedAt is when the link was put away by rl stale or a cleanup
+	// policy. Archived links are also read, keeping ReadAt if they were
+	// read first.
+	ArchivedAt *time.Time `json:"archived_at,omitempty"`
+	// TrashedAt is when the link was moved to the trash by rl rm; it is
+	// deleted for good when the trash is emptied.
+	TrashedAt *time.Time `json:"trashed_at,omitempty"`
+	// WordCount is the number of words in the fetched article body.
+	WordCount int `json:"word_count,omitempty"`
+	// ReadingSeconds is the estimated time to read the link, or the
+	// running time of a video or audio link.
+	ReadingSeconds int `json:"reading_seconds,omitempty"`
+	// MediaType is MediaVideo or MediaAudio for links to media, and empty
+	// for articles.
+	MediaType string `json:"media_type,omitempty"`
+	// Author is the creator of the page or media, when known.
+	Author string `json:"author,omitempty"`
+	// HTTPStatus is the status code from the last dead-link check;
+	// 0 with a non-nil CheckedAt means the URL was unreachable.
+	HTTPStatus int        `json:"http_status,omitempty"`
+	CheckedAt  *time.Time `json:"checked_at,omitempty"`
+	// Annotations are the link's dated notes, oldest first. Storage only
+	// fills them in on exports; given to Add or Import, they are added to
+	// the link's notes unless it has one with the same text.
+	Annotations []*Annotation `json:"annotations,omitempty"`
+	// Match describes where a search matched the link; it is only set on
+	// search results.
+	Match *Match `json:"match,omitempty"`
+}
+
+// Match is the part of a link that matched a search.
+type Match struct {
+	// Field is the matched field: "url", "title", "note" for an
+	// annotation, "tags", "description", "quote" for a highlighted
+	// passage, or "content" for the archived article text.
+	Field string `json:"field"`
+	// Snippet is an excerpt of the field around the match.
+	Snippet string `json:"snippet"`
+	// Highlights are the byte offsets [start, end) of the matched terms
+	// within Snippet.
+	Highlights [][2]int `json:"highlights,omitempty"`
+	// Similarity is how close a semantic search result's meaning is to
+	// the query's, from -1 to 1.
+	Similarity float64 `json:"similarity,omitempty"`
+}
+
+// IsDead returns true if the last dead-link check found the URL gone
+// (404, 410) or unreachable.
+func (l *Link) IsDead() bool {
+	if l.CheckedAt == nil {
+		return false
+	}
+	return l.HTTPStatus == 0 || l.HTTPStatus == 404 || l.HTTPStatus == 410
+}
+
+// Media types of links that are not articles.
+const (
+	MediaVideo = "video"
+	MediaAudio = "audio"
+)
+
+// MediaMark returns the mark prefixed to the running time of a video or
+// audio link, "▶ " or "♪ ", so it isn't mistaken for time to read.
+func (l *Link) MediaMark() string {
+	switch l.MediaType {
+	case MediaVideo:
+		return "▶ "
+	case MediaAudio:
+		return "♪ "
+	}
+	return ""
+}
+
+// WordsPerMinute is the average adult silent reading speed used for estimates.
+const WordsPerMinute = 238
+
+// EstimateReadingSeconds estimates reading time in seconds for a word count,
+// rounded up to a whole minute.
+func EstimateReadingSeconds(words int) int {
+	if words <= 0 {
+		return 0
+	}
+	minutes := (words + WordsPerMinute - 1) / WordsPerMinute
+	return minutes * 60
+}
+
+// ReadingTime returns the estimated reading time, or zero if unknown.
+func (l *Link) ReadingTime() time.Duration {
+	return time.Duration(l.ReadingSeconds) * time.Second
 }
 
 // Validate checks if the link has a valid URL.
@@ -32,11 +126,25 @@ func (l *Link) Validate() error {
 	return nil
 }
 
+// Domain returns the lowercased host of a URL without port or a leading "www.".
+func Domain(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+}
+
 // IsRead returns true if the link has been marked as read.
 func (l *Link) IsRead() bool {
 	return l.ReadAt != nil
 }
 
+// IsSnoozed returns true if the link is snoozed at the given time.
+func (l *Link) IsSnoozed(now time.Time) bool {
+	return l.SnoozedUntil != nil && l.SnoozedUntil.After(now)
+}
+
 // TagList returns tags as a slice of strings.
 func (l *Link) TagList() []string {
 	if l.Tags == "" {
@@ -73,3 +181,45 @@ func (l *Link) MergeTags(other *Link) {
 	}
 	l.Tags = strings.Join(newTags, ",")
 }
+
+// NoteText joins the link's annotations into one text, a blank line
+// between each, for services with a single notes field.
+func (l *Link) NoteText() string {
+	texts := make([]string, len(l.Annotations))
+	for i, a := range l.Annotations {
+		texts[i] = a.Text
+	}
+	return strings.Join(texts, "\n\n")
+}
+
+// MergeDuplicate folds other, a duplicate of l, into l: tags are combined,
+// the earlier save time, any read time, and the higher priority kept, and
+// fields l lacks filled in from other. Storage moves the duplicate's notes
+// over separately.
+func (l *Link) MergeDuplicate(other *Link) {
+	l.MergeTags(other)
+	if !other.CreatedAt.IsZero() && (l.CreatedAt.IsZero() || other.CreatedAt.Before(l.CreatedAt)) {
+		l.CreatedAt = other.CreatedAt
+	}
+	if l.ReadAt == nil {
+		l.ReadAt = other.ReadAt
+	}
+	if other.Priority > l.Priority {
+		l.Priority = other.Priority
+	}
+	if l.Title == "" {
+		l.Title = other.Title
+	}
+	if l.Description == "" {
+		l.Description = other.Description
+	}
+	if l.Author == "" {
+		l.Author = other.Author
+	}
+	if l.MediaType == "" {
+		l.MediaType = other.MediaType
+	}
+	if l.WordCount == 0 {
+		l.WordCount, l.ReadingSeconds = other.WordCount, other.ReadingSeconds
+	}
+}
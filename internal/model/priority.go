@@ -0,0 +1,56 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Priority indicates how urgently a link should be read.
+type Priority int
+
+const (
+	PriorityLow    Priority = -1
+	PriorityNormal Priority = 0
+	PriorityHigh   Priority = 1
+)
+
+// String returns the priority level name.
+func (p Priority) String() string {
+	switch {
+	case p > PriorityNormal:
+		return "high"
+	case p < PriorityNormal:
+		return "low"
+	default:
+		return "normal"
+	}
+}
+
+// ParsePriority parses a priority level name (high, normal, low).
+func ParsePriority(s string) (Priority, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "high", "h":
+		return PriorityHigh, nil
+	case "normal", "n", "":
+		return PriorityNormal, nil
+	case "low", "l":
+		return PriorityLow, nil
+	default:
+		return PriorityNormal, fmt.Errorf("%w: %q (expected high, normal, or low)", ErrInvalidPriority, s)
+	}
+}
+
+// MarshalText encodes the priority as its level name.
+func (p Priority) MarshalText() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
+// UnmarshalText decodes a priority from its level name.
+func (p *Priority) UnmarshalText(text []byte) error {
+	parsed, err := ParsePriority(string(text))
+	if err != nil {
+		return err
+	}
+	*p = parsed
+	return nil
+}
@@ -0,0 +1,13 @@
+package model
+
+import "time"
+
+// Summary is a short summary of a link's archived text, written by a
+// language model.
+type Summary struct {
+	LinkID string `json:"link_id"`
+	Text   string `json:"text"`
+	// Model names the model that wrote it.
+	Model     string    `json:"model,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
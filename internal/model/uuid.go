@@ -1,8 +1,10 @@
 package model
 
 import (
+	"crypto/rand"
 	"encoding/base32"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -17,11 +19,79 @@ func GenerateShortID() string {
 	return strings.ToLower(encoded)
 }
 
+// Lengths of generated IDs.
+const (
+	// MinIDLength is the shortest ID GenerateID makes: 40 random bits.
+	MinIDLength = 8
+	// DefaultIDLength is the length of GenerateShortID's IDs.
+	DefaultIDLength = 26
+)
+
+// crockford is Crockford's base32 alphabet, lowercased. It leaves out i,
+// l, o, and u, which are easily misread or mistyped.
+const crockford = "0123456789abcdefghjkmnpqrstvwxyz"
+
+// GenerateID generates a random ID of the given length. Shorter IDs than
+// the default, for typing by hand, use Crockford's base32; the default (or
+// a length of 0) gives GenerateShortID's UUID-based IDs.
+func GenerateID(length int) string {
+	if length <= 0 || length >= DefaultIDLength {
+		return GenerateShortID()
+	}
+	b := make([]byte, max(length, MinIDLength))
+	rand.Read(b)
+	for i := range b {
+		b[i] = crockford[b[i]&31]
+	}
+	return string(b)
+}
+
+// ID schemes for new links.
+const (
+	// IDSchemeRandom IDs are random; see GenerateID.
+	IDSchemeRandom = "random"
+	// IDSchemeULID IDs are ULIDs, which sort by when their link was saved;
+	// see GenerateULID.
+	IDSchemeULID = "ulid"
+)
+
+// GenerateULID generates a ULID (https://github.com/ulid/spec) for a link
+// saved at t, lowercased like other IDs: 48 bits of milliseconds since the
+// Unix epoch followed by 80 random bits, in Crockford's base32, so IDs
+// sort by time. A zero t means now.
+func GenerateULID(t time.Time) string {
+	if t.IsZero() {
+		t = time.Now()
+	}
+	var b [16]byte
+	ms := uint64(max(t.UnixMilli(), 0))
+	for i := 5; i >= 0; i-- {
+		b[i] = byte(ms)
+		ms >>= 8
+	}
+	rand.Read(b[6:])
+
+	// 26 characters hold 130 bits, so the first takes just the top three
+	hi, lo := uint64(0), uint64(0)
+	for i := 0; i < 8; i++ {
+		hi = hi<<8 | uint64(b[i])
+		lo = lo<<8 | uint64(b[i+8])
+	}
+	id := make([]byte, DefaultIDLength)
+	for i := len(id) - 1; i >= 0; i-- {
+		id[i] = crockford[lo&31]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(id)
+}
+
 // ValidateShortID validates that an ID is a valid format.
-// Accepts both base32 encoded UUIDs (26 chars) and hex IDs from migration (12 chars).
+// Accepts base32 encoded UUIDs and ULIDs (26 chars), shorter generated IDs
+// (8 chars or more), and hex IDs from migration (12 chars).
 // Case-insensitive to support IDs created before lowercase conversion.
 func ValidateShortID(id string) bool {
-	if len(id) < 10 || len(id) > 30 {
+	if len(id) < MinIDLength || len(id) > 30 {
 		return false
 	}
 	// Check if it's alphanumeric (case-insensitive)
@@ -32,3 +102,17 @@ func ValidateShortID(id string) bool {
 	}
 	return true
 }
+
+// ValidateIDPrefix validates a full ID or a leading portion of one, as
+// accepted by commands that resolve unique prefixes (e.g. "a3f").
+func ValidateIDPrefix(prefix string) bool {
+	if len(prefix) == 0 || len(prefix) > 30 {
+		return false
+	}
+	for _, c := range prefix {
+		if !((c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')) {
+			return false
+		}
+	}
+	return true
+}
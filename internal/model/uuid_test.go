@@ -0,0 +1,50 @@
+package model
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGenerateID(t *testing.T) {
+	for _, length := range []int{0, MinIDLength, 12, DefaultIDLength} {
+		id := GenerateID(length)
+		want := length
+		if length == 0 {
+			want = DefaultIDLength
+		}
+		if len(id) != want || !ValidateShortID(id) {
+			t.Errorf("GenerateID(%d) = %q, want a valid ID of %d characters", length, id, want)
+		}
+	}
+
+	seen := make(map[string]bool)
+	for i := 0; i < 1000; i++ {
+		id := GenerateID(MinIDLength)
+		if strings.ContainsAny(id, "ilou") {
+			t.Fatalf("GenerateID(%d) = %q, which uses letters outside Crockford's base32", MinIDLength, id)
+		}
+		seen[id] = true
+	}
+	if len(seen) < 999 {
+		t.Errorf("Expected 1000 random IDs to be distinct, got %d", len(seen))
+	}
+}
+
+func TestGenerateULID(t *testing.T) {
+	// The spec's example ULID 01ARYZ6S41TSV4RRFFQ69G5FAV has this time
+	saved := time.UnixMilli(1469918176385)
+	id := GenerateULID(saved)
+	if len(id) != DefaultIDLength || !ValidateShortID(id) {
+		t.Fatalf("GenerateULID = %q, want a valid ID of %d characters", id, DefaultIDLength)
+	}
+	if !strings.HasPrefix(id, "01aryz6s41") {
+		t.Errorf("GenerateULID(%d ms) = %q, want the time encoded as 01aryz6s41", saved.UnixMilli(), id)
+	}
+	if later := GenerateULID(saved.Add(time.Millisecond)); later <= id {
+		t.Errorf("Expected a later ULID to sort after %q, got %q", id, later)
+	}
+	if now := GenerateULID(time.Time{}); now <= id {
+		t.Errorf("Expected a zero time to mean now, got %q", now)
+	}
+}
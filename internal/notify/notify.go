@@ -0,0 +1,104 @@
+// Package notify shows desktop notifications with the platform's tools:
+// notify-send on Linux, terminal-notifier or osascript on macOS, and a
+// PowerShell toast on Windows.
+package notify
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// ErrUnavailable is returned when no notification tool is installed.
+var ErrUnavailable = errors.New("no notification tool found (install libnotify's notify-send)")
+
+// openAction is the action notify-send prints when Open is clicked.
+const openAction = "open"
+
+// Notification is a desktop notification about a link.
+type Notification struct {
+	Title string
+	Body  string
+	// URL is opened when the notification is clicked, where the
+	// platform supports it.
+	URL string
+}
+
+// toastScript shows a Windows toast that opens RL_NOTIFY_URL when clicked.
+const toastScript = `$ErrorActionPreference = 'Stop'
+[Windows.UI.Notifications.ToastNotificationManager, Windows.UI.Notifications, ContentType = WindowsRuntime] > $null
+[Windows.Data.Xml.Dom.XmlDocument, Windows.Data.Xml.Dom.XmlDocument, ContentType = WindowsRuntime] > $null
+$url = [Security.SecurityElement]::Escape($env:RL_NOTIFY_URL)
+$xml = New-Object Windows.Data.Xml.Dom.XmlDocument
+$xml.LoadXml(('<toast activationType="protocol" launch="{0}"><visual><binding template="ToastGeneric"><text>{1}</text><text>{2}</text></binding></visual><actions><action content="Open" activationType="protocol" arguments="{0}"/></actions></toast>' -f $url, [Security.SecurityElement]::Escape($env:RL_NOTIFY_TITLE), [Security.SecurityElement]::Escape($env:RL_NOTIFY_BODY)))
+$app = '{1AC14E77-02E7-4E5D-B744-2EB1AE5198B7}\WindowsPowerShell\v1.0\powershell.exe'
+[Windows.UI.Notifications.ToastNotificationManager]::CreateToastNotifier($app).Show([Windows.UI.Notifications.ToastNotification]::new($xml))
+`
+
+// Available returns ErrUnavailable if notifications can't be shown.
+func Available() error {
+	if runtime.GOOS == "darwin" || runtime.GOOS == "windows" {
+		return nil
+	}
+	if _, err := exec.LookPath("notify-send"); err != nil {
+		return ErrUnavailable
+	}
+	return nil
+}
+
+// command returns the command that shows n on this platform, and whether
+// it waits for the notification to close and prints the action clicked.
+func command(n Notification) (*exec.Cmd, bool, error) {
+	switch runtime.GOOS {
+	case "darwin":
+		if path, err := exec.LookPath("terminal-notifier"); err == nil {
+			return exec.Command(path, "-title", n.Title, "-message", n.Body, "-open", n.URL, "-group", n.URL), false, nil
+		}
+		// osascript's notifications can't open anything, but need nothing
+		// installed; the arguments spare quoting the text as AppleScript
+		return exec.Command("osascript",
+			"-e", "on run argv",
+			"-e", "display notification (item 2 of argv) with title (item 1 of argv)",
+			"-e", "end run",
+			n.Title, n.Body), false, nil
+	case "windows":
+		cmd := exec.Command("powershell", "-NoProfile", "-NonInteractive", "-Command", toastScript)
+		cmd.Env = append(os.Environ(), "RL_NOTIFY_TITLE="+n.Title, "RL_NOTIFY_BODY="+n.Body, "RL_NOTIFY_URL="+n.URL)
+		return cmd, false, nil
+	default:
+		path, err := exec.LookPath("notify-send")
+		if err != nil {
+			return nil, false, ErrUnavailable
+		}
+		return exec.Command(path, "--app-name=rl", "--action="+openAction+"=Open", "--wait", "--", n.Title, n.Body), true, nil
+	}
+}
+
+// Send shows n. With notify-send it waits for the notification to close
+// and reports whether its Open action was clicked, leaving the caller to
+// open the URL; elsewhere clicking opens the URL itself, and Send returns
+// once n is shown.
+func Send(n Notification) (opened bool, err error) {
+	cmd, waits, err := command(n)
+	if err != nil {
+		return false, err
+	}
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil && waits && strings.Contains(stderr.String(), "Unknown option") {
+		// notify-send before libnotify 0.7.9 has no actions
+		cmd = exec.Command(cmd.Path, "--app-name=rl", "--", n.Title, n.Body)
+		stderr.Reset()
+		cmd.Stderr = &stderr
+		out, err = cmd.Output()
+		waits = false
+	}
+	if err != nil {
+		return false, fmt.Errorf("notify: %s: %w", strings.TrimSpace(stderr.String()), err)
+	}
+	return waits && strings.TrimSpace(string(out)) == openAction, nil
+}
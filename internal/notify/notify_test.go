@@ -0,0 +1,61 @@
+package notify
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// fakeNotifySend installs a notify-send stub that records its arguments
+// and runs script, so Send can run without a notification server.
+func fakeNotifySend(t *testing.T, script string) string {
+	t.Helper()
+	if runtime.GOOS != "linux" {
+		t.Skip("notify-send stub uses the Linux tool name")
+	}
+	dir := t.TempDir()
+	args := filepath.Join(dir, "args")
+	stub := "#!/bin/sh\nprintf '%s\\n' \"$@\" > " + args + "\n" + script + "\n"
+	if err := os.WriteFile(filepath.Join(dir, "notify-send"), []byte(stub), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir)
+	return args
+}
+
+func TestSend(t *testing.T) {
+	n := Notification{Title: "A 'quoted' title", Body: "Snooze ended", URL: "https://example.com/a"}
+
+	args := fakeNotifySend(t, "echo open")
+	opened, err := Send(n)
+	if err != nil || !opened {
+		t.Fatalf("Send() = %v, %v; want the Open action reported", opened, err)
+	}
+	data, _ := os.ReadFile(args)
+	if got := string(data); !strings.Contains(got, "--action=open=Open\n") || !strings.HasSuffix(got, "--\nA 'quoted' title\nSnooze ended\n") {
+		t.Errorf("Unexpected notify-send arguments:\n%s", got)
+	}
+
+	// Dismissed without clicking Open
+	fakeNotifySend(t, "true")
+	if opened, err := Send(n); err != nil || opened {
+		t.Errorf("Send() = %v, %v; want a dismissed notification", opened, err)
+	}
+
+	// Old notify-send without actions
+	args = fakeNotifySend(t, `for a; do [ "$a" = --wait ] && { echo "Unknown option --wait" >&2; exit 1; }; done; exit 0`)
+	if opened, err := Send(n); err != nil || opened {
+		t.Errorf("Send() = %v, %v; want a plain notification", opened, err)
+	}
+	data, _ = os.ReadFile(args)
+	if strings.Contains(string(data), "--action") {
+		t.Errorf("Expected the retry without actions, got:\n%s", data)
+	}
+
+	t.Setenv("PATH", t.TempDir())
+	if _, err := Send(n); err != ErrUnavailable {
+		t.Errorf("Expected ErrUnavailable without notify-send, got %v", err)
+	}
+}
@@ -0,0 +1,197 @@
+// Package pinboard pushes links to Pinboard (pinboard.in) as an off-site
+// backup of the reading list.
+package pinboard
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/bunchhieng/rl/internal/model"
+	"github.com/bunchhieng/rl/internal/storage"
+)
+
+// Service names Pinboard in the sync records.
+const Service = "pinboard"
+
+const (
+	defaultBaseURL = "https://api.pinboard.in/v1"
+	requestTimeout = 30 * time.Second
+	// addInterval is the pause between posts/add calls that Pinboard's
+	// rate limit asks for.
+	addInterval = 3 * time.Second
+	// Pinboard's limits on the title and description fields, in characters.
+	maxTitle       = 255
+	maxDescription = 65536
+)
+
+// Client posts bookmarks to the Pinboard API.
+type Client struct {
+	token    string
+	base     string
+	http     *http.Client
+	interval time.Duration
+	last     time.Time
+}
+
+// NewClient returns a client authenticating with token, the "user:HEX"
+// API token from Pinboard's password settings page.
+func NewClient(token string) (*Client, error) {
+	if !strings.Contains(token, ":") {
+		return nil, fmt.Errorf("pinboard token must look like user:0123456789ABCDEF (see pinboard.in/settings/password)")
+	}
+	return &Client{
+		token:    token,
+		base:     defaultBaseURL,
+		http:     &http.Client{Timeout: requestTimeout},
+		interval: addInterval,
+	}, nil
+}
+
+// Add saves link as a bookmark, replacing any bookmark for the same URL.
+// Unread links are marked "to read".
+func (c *Client) Add(ctx context.Context, link *model.Link) error {
+	if wait := c.interval - time.Since(c.last); wait > 0 {
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	defer func() { c.last = time.Now() }()
+
+	title := link.Title
+	if title == "" {
+		title = link.URL
+	}
+	extended := link.NoteText()
+	if extended == "" {
+		extended = link.Description
+	}
+	query := url.Values{
+		"auth_token":  {c.token},
+		"format":      {"json"},
+		"url":         {link.URL},
+		"description": {truncate(title, maxTitle)},
+		"extended":    {truncate(extended, maxDescription)},
+		"tags":        {tags(link)},
+		"dt":          {link.CreatedAt.UTC().Format(time.RFC3339)},
+		"replace":     {"yes"},
+		"shared":      {"no"},
+		"toread":      {yesNo(!link.IsRead())},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.base+"/posts/add?"+query.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		// The request URL carries the token; keep it out of the message
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("pinboard: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("pinboard: %s", resp.Status)
+	}
+	var result struct {
+		ResultCode string `json:"result_code"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("pinboard: decode response: %w", err)
+	}
+	if result.ResultCode != "done" {
+		return fmt.Errorf("pinboard: %s", result.ResultCode)
+	}
+	return nil
+}
+
+// tags converts rl tags to Pinboard's space-separated form; spaces inside
+// a tag become underscores.
+func tags(link *model.Link) string {
+	list := link.TagList()
+	for i, tag := range list {
+		list[i] = strings.Join(strings.Fields(tag), "_")
+	}
+	return strings.Join(list, " ")
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
+
+func truncate(s string, n int) string {
+	if runes := []rune(s); len(runes) > n {
+		return string(runes[:n])
+	}
+	return s
+}
+
+// digest fingerprints the fields sent to Pinboard.
+func digest(link *model.Link) string {
+	sum := sha256.Sum256([]byte(strings.Join([]string{
+		link.URL, link.Title, link.NoteText(), link.Description, link.Tags, yesNo(link.IsRead()),
+	}, "\x00")))
+	return hex.EncodeToString(sum[:])
+}
+
+// Result counts what a push did.
+type Result struct {
+	Pushed    int `json:"pushed"`
+	Unchanged int `json:"unchanged"`
+}
+
+// Push sends every link that is new or changed since its last push. Links
+// deleted from rl are left on Pinboard. With dryRun set nothing is sent or
+// recorded. pushed is called for each link sent (or that would be).
+func Push(ctx context.Context, s storage.Storage, c *Client, dryRun bool, pushed func(*model.Link)) (*Result, error) {
+	records, err := s.SyncRecords(ctx, Service)
+	if err != nil {
+		return nil, err
+	}
+	digests := make(map[string]string, len(records))
+	for _, rec := range records {
+		digests[rec.LinkID] = rec.Digest
+	}
+
+	links, err := s.Export(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list links: %w", err)
+	}
+
+	result := &Result{}
+	for _, link := range links {
+		sum := digest(link)
+		if digests[link.ID] == sum {
+			result.Unchanged++
+			continue
+		}
+		if !dryRun {
+			if err := c.Add(ctx, link); err != nil {
+				return result, fmt.Errorf("push %s: %w", link.URL, err)
+			}
+			rec := storage.SyncRecord{Service: Service, LinkID: link.ID, RemoteID: link.URL, Read: link.IsRead(), Digest: sum}
+			if err := s.SetSyncRecord(ctx, rec); err != nil {
+				return result, err
+			}
+		}
+		result.Pushed++
+		if pushed != nil {
+			pushed(link)
+		}
+	}
+	return result, nil
+}
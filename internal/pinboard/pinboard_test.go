@@ -0,0 +1,94 @@
+package pinboard
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/bunchhieng/rl/internal/model"
+	"github.com/bunchhieng/rl/internal/storage"
+)
+
+func TestPush(t *testing.T) {
+	var posts []url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/posts/add" || r.URL.Query().Get("auth_token") != "me:TOKEN" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		posts = append(posts, r.URL.Query())
+		w.Write([]byte(`{"result_code":"done"}`))
+	}))
+	defer server.Close()
+
+	s, err := storage.NewSQLiteStorage(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	ctx := context.Background()
+	unread, err := s.Add(ctx, &model.Link{URL: "https://example.com/a", Title: "A", Tags: "go,machine learning"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	read, err := s.Add(ctx, &model.Link{URL: "https://example.com/b", Annotations: []*model.Annotation{{Text: "worth it"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.MarkRead(ctx, read.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	client, err := NewClient("me:TOKEN")
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.base, client.interval = server.URL, 0
+
+	if result, err := Push(ctx, s, client, true, nil); err != nil || result.Pushed != 2 || len(posts) != 0 {
+		t.Fatalf("Dry run should send nothing: %+v, %v, %d posts", result, err, len(posts))
+	}
+
+	result, err := Push(ctx, s, client, false, nil)
+	if err != nil {
+		t.Fatalf("Push failed: %v", err)
+	}
+	if *result != (Result{Pushed: 2}) || len(posts) != 2 {
+		t.Fatalf("Expected both links pushed, got %+v with %d posts", result, len(posts))
+	}
+	byURL := map[string]url.Values{posts[0].Get("url"): posts[0], posts[1].Get("url"): posts[1]}
+	a, b := byURL[unread.URL], byURL[read.URL]
+	if a.Get("toread") != "yes" || a.Get("description") != "A" || a.Get("tags") != "go machine_learning" {
+		t.Errorf("Unexpected post for the unread link: %v", a)
+	}
+	if b.Get("toread") != "no" || b.Get("description") != read.URL || b.Get("extended") != "worth it" {
+		t.Errorf("Unexpected post for the read link: %v", b)
+	}
+
+	// Only changed links are pushed again
+	if err := s.MarkRead(ctx, unread.ID); err != nil {
+		t.Fatal(err)
+	}
+	posts = nil
+	result, err = Push(ctx, s, client, false, nil)
+	if err != nil {
+		t.Fatalf("Second push failed: %v", err)
+	}
+	if *result != (Result{Pushed: 1, Unchanged: 1}) || len(posts) != 1 || posts[0].Get("toread") != "no" {
+		t.Errorf("Expected only the newly read link, got %+v, %v", result, posts)
+	}
+
+	// A new note changes the extended field
+	if _, err := s.AddAnnotation(ctx, read.ID, "see part 2"); err != nil {
+		t.Fatal(err)
+	}
+	posts = nil
+	if _, err := Push(ctx, s, client, false, nil); err != nil {
+		t.Fatalf("Third push failed: %v", err)
+	}
+	if len(posts) != 1 || posts[0].Get("extended") != "worth it\n\nsee part 2" {
+		t.Errorf("Expected the read link pushed with both notes, got %v", posts)
+	}
+}
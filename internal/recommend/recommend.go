@@ -0,0 +1,166 @@
+// Package recommend picks the unread links most worth reading next, by
+// priority, the tags the user tends to finish, how well the reading time
+// fits, how recently the link was saved, and whether a snooze just ended.
+package recommend
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bunchhieng/rl/internal/model"
+)
+
+// Weights of each factor in a score. Priority is the user's own say, so it
+// counts most; tag affinity is the best evidence of what they finish.
+const (
+	priorityWeight = 0.3
+	affinityWeight = 0.25
+	fitWeight      = 0.2
+	ageWeight      = 0.15
+	snoozeWeight   = 0.1
+)
+
+const (
+	// ageHalfLife is how long until a link's freshness counts half; interest
+	// in a link fades the longer it sits.
+	ageHalfLife = 30 * 24 * time.Hour
+	// unsnoozedWindow is how long after its snooze ends a link counts as
+	// just back, having been put off until about now.
+	unsnoozedWindow = 7 * 24 * time.Hour
+)
+
+// Options controls Rank.
+type Options struct {
+	// Time is the reading time available; links that fit score higher.
+	// Zero leaves reading time out.
+	Time time.Duration
+	// Limit caps the results; 0 returns all.
+	Limit int
+}
+
+// Result is an unread link with its score and what raised it.
+type Result struct {
+	Link *model.Link `json:"link"`
+	// Score is from 0 to 1; higher is a better next read.
+	Score float64 `json:"score"`
+	// Tag is the link's tag the user finishes most often, with how many
+	// of the links saved with it they've read.
+	Tag      string `json:"tag,omitempty"`
+	TagRead  int    `json:"tag_read,omitempty"`
+	TagSaved int    `json:"tag_saved,omitempty"`
+	// Fits is set when the link's reading time is known and within
+	// Options.Time.
+	Fits bool `json:"fits,omitempty"`
+	// Unsnoozed is set when the link's snooze ended within the last week.
+	Unsnoozed bool `json:"unsnoozed,omitempty"`
+}
+
+// tagCount is how many links have a tag and how many of them are read.
+type tagCount struct {
+	read, saved int
+}
+
+// rate is the share of a tag's links that are read, smoothed toward one
+// half so a tag seen once doesn't count as always or never finished.
+func (t tagCount) rate() float64 {
+	return float64(t.read+1) / float64(t.saved+2)
+}
+
+// Rank scores the unread, unsnoozed links among links, judging the tags
+// the user finishes by all of links, and returns them best first.
+func Rank(links []*model.Link, opts Options, now time.Time) []Result {
+	tags := map[string]*tagCount{}
+	var overall tagCount
+	for _, link := range links {
+		overall.saved++
+		if link.IsRead() {
+			overall.read++
+		}
+		for _, tag := range link.TagList() {
+			tag = strings.ToLower(tag)
+			if tags[tag] == nil {
+				tags[tag] = &tagCount{}
+			}
+			tags[tag].saved++
+			if link.IsRead() {
+				tags[tag].read++
+			}
+		}
+	}
+
+	var results []Result
+	for _, link := range links {
+		if link.IsRead() || link.IsSnoozed(now) {
+			continue
+		}
+		r := Result{Link: link}
+
+		priority := 0.5
+		switch {
+		case link.Priority > model.PriorityNormal:
+			priority = 1
+		case link.Priority < model.PriorityNormal:
+			priority = 0
+		}
+
+		// Untagged links get the rate across the whole library
+		affinity := overall.rate()
+		for i, tag := range link.TagList() {
+			count := tags[strings.ToLower(tag)]
+			if i == 0 || count.rate() > affinity {
+				affinity = count.rate()
+				r.Tag, r.TagRead, r.TagSaved = strings.ToLower(tag), count.read, count.saved
+			}
+		}
+
+		fit := 0.5
+		if rt := link.ReadingTime(); opts.Time > 0 && rt > 0 {
+			if rt <= opts.Time {
+				fit, r.Fits = 1, true
+			} else {
+				// Falls off fast: twice the time available scores a quarter
+				fit = math.Pow(float64(opts.Time)/float64(rt), 2)
+			}
+		}
+
+		age := math.Pow(0.5, float64(now.Sub(link.CreatedAt))/float64(ageHalfLife))
+
+		var snooze float64
+		if link.SnoozedUntil != nil && now.Sub(*link.SnoozedUntil) < unsnoozedWindow {
+			snooze, r.Unsnoozed = 1, true
+		}
+
+		r.Score = priorityWeight*priority + affinityWeight*affinity + fitWeight*fit + ageWeight*min(age, 1) + snoozeWeight*snooze
+		results = append(results, r)
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Link.CreatedAt.After(results[j].Link.CreatedAt)
+	})
+	if opts.Limit > 0 && len(results) > opts.Limit {
+		results = results[:opts.Limit]
+	}
+	return results
+}
+
+// TypicalTime returns the median reading time of the read links among
+// links, or 0 if none has a known reading time: about as long as the user
+// usually gets through.
+func TypicalTime(links []*model.Link) time.Duration {
+	var times []time.Duration
+	for _, link := range links {
+		if link.IsRead() && link.ReadingTime() > 0 {
+			times = append(times, link.ReadingTime())
+		}
+	}
+	if len(times) == 0 {
+		return 0
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i] < times[j] })
+	return times[len(times)/2]
+}
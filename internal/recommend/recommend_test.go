@@ -0,0 +1,60 @@
+package recommend
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/bunchhieng/rl/internal/model"
+)
+
+func TestRank(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+	at := func(d time.Duration) *time.Time {
+		t := now.Add(d)
+		return &t
+	}
+	links := []*model.Link{
+		// Go links get read; cooking ones don't
+		{ID: "r1", Tags: "go", ReadAt: at(-day), ReadingSeconds: 600},
+		{ID: "r2", Tags: "go", ReadAt: at(-day), ReadingSeconds: 300},
+		{ID: "r3", Tags: "go", ReadAt: at(-day), ReadingSeconds: 900},
+		{ID: "c1", Tags: "cooking", CreatedAt: now.Add(-day)},
+		{ID: "c2", Tags: "cooking", CreatedAt: now.Add(-day)},
+
+		{ID: "go", Tags: "go,cooking", CreatedAt: now.Add(-10 * day), ReadingSeconds: 480},
+		{ID: "long", Tags: "go", CreatedAt: now.Add(-10 * day), ReadingSeconds: 3600},
+		{ID: "high", Priority: model.PriorityHigh, Tags: "cooking", CreatedAt: now.Add(-10 * day)},
+		{ID: "back", SnoozedUntil: at(-day), CreatedAt: now.Add(-60 * day)},
+		{ID: "snoozed", Priority: model.PriorityHigh, SnoozedUntil: at(day), CreatedAt: now},
+	}
+
+	typical := TypicalTime(links)
+	if typical != 10*time.Minute {
+		t.Fatalf("TypicalTime() = %v, want 10m", typical)
+	}
+	results := Rank(links, Options{Time: typical, Limit: 4}, now)
+	var ids []string
+	for _, r := range results {
+		ids = append(ids, r.Link.ID)
+	}
+	// The hour-long go link falls behind a fresh cooking one that might fit
+	if want := []string{"go", "high", "back", "c1"}; !reflect.DeepEqual(ids, want) {
+		t.Fatalf("Rank() = %v, want %v", ids, want)
+	}
+
+	if r := results[0]; r.Tag != "go" || r.TagRead != 3 || r.TagSaved != 5 || !r.Fits {
+		t.Errorf("Expected the go link to fit and credit its go tag, got %+v", r)
+	}
+	if !results[2].Unsnoozed {
+		t.Error("Expected the link back from a snooze to be marked")
+	}
+	all := Rank(links, Options{Time: typical}, now)
+	if len(all) != 6 {
+		t.Fatalf("Expected every unread, unsnoozed link without a limit, got %d", len(all))
+	}
+	if last := all[len(all)-1]; last.Link.ID != "long" || last.Fits {
+		t.Errorf("Expected the hour-long read last and not fitting in 10m, got %+v", last)
+	}
+}
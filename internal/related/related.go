@@ -0,0 +1,117 @@
+// Package related ranks saved links by how much they have in common with
+// a given one: shared tags, the same domain, and overlapping title terms.
+package related
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/bunchhieng/rl/internal/model"
+)
+
+// Weights of each kind of overlap in a score. Tags are chosen by the user,
+// so they count most; a shared domain alone is a weak hint.
+const (
+	tagWeight    = 0.5
+	termWeight   = 0.4
+	domainWeight = 0.1
+)
+
+// Result is a link related to the target, with what they share.
+type Result struct {
+	Link *model.Link `json:"link"`
+	// Score is from 0 to 1; higher is more similar.
+	Score float64 `json:"score"`
+	// Tags and Terms are the tags and title terms both links have.
+	Tags   []string `json:"tags,omitempty"`
+	Terms  []string `json:"terms,omitempty"`
+	Domain bool     `json:"same_domain,omitempty"`
+}
+
+// Find scores candidates against target and returns up to limit (0 for
+// all) that share anything with it, best first. The target itself is
+// skipped if among the candidates.
+func Find(target *model.Link, candidates []*model.Link, limit int) []Result {
+	tags := tagSet(target)
+	terms := titleTerms(target.Title)
+
+	var results []Result
+	for _, link := range candidates {
+		if link.ID == target.ID {
+			continue
+		}
+		r := Result{Link: link, Domain: target.Domain != "" && link.Domain == target.Domain}
+		var tagScore, termScore float64
+		r.Tags, tagScore = overlap(tags, tagSet(link))
+		r.Terms, termScore = overlap(terms, titleTerms(link.Title))
+		r.Score = tagWeight*tagScore + termWeight*termScore
+		if r.Domain {
+			r.Score += domainWeight
+		}
+		if r.Score > 0 {
+			results = append(results, r)
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Link.CreatedAt.After(results[j].Link.CreatedAt)
+	})
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
+
+// overlap returns the sorted members a and b share and the fraction of
+// the smaller set they make up, so a short title fully contained in a
+// long one still counts as a strong match.
+func overlap(a, b map[string]bool) ([]string, float64) {
+	var shared []string
+	for k := range a {
+		if b[k] {
+			shared = append(shared, k)
+		}
+	}
+	if len(shared) == 0 {
+		return nil, 0
+	}
+	sort.Strings(shared)
+	return shared, float64(len(shared)) / float64(min(len(a), len(b)))
+}
+
+// tagSet returns a link's tags, lowercased.
+func tagSet(link *model.Link) map[string]bool {
+	set := make(map[string]bool)
+	for _, tag := range link.TagList() {
+		set[strings.ToLower(tag)] = true
+	}
+	return set
+}
+
+// titleTerms returns the distinctive words of a title: lowercased, at
+// least three characters, and not common English words.
+func titleTerms(title string) map[string]bool {
+	set := make(map[string]bool)
+	words := strings.FieldsFunc(strings.ToLower(title), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, w := range words {
+		if len([]rune(w)) >= 3 && !stopWords[w] {
+			set[w] = true
+		}
+	}
+	return set
+}
+
+// stopWords are words too common in titles to relate two links.
+var stopWords = map[string]bool{
+	"the": true, "and": true, "for": true, "with": true, "from": true,
+	"into": true, "your": true, "you": true, "are": true, "how": true,
+	"why": true, "what": true, "when": true, "this": true, "that": true,
+	"not": true, "all": true, "about": true, "its": true, "our": true,
+	"can": true, "new": true, "using": true, "use": true, "part": true,
+}
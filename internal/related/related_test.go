@@ -0,0 +1,40 @@
+package related
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/bunchhieng/rl/internal/model"
+)
+
+func TestFind(t *testing.T) {
+	now := time.Now()
+	target := &model.Link{ID: "t", Title: "Understanding the Go scheduler", Tags: "go,runtime", Domain: "go.dev"}
+	tagged := &model.Link{ID: "a", Title: "Garbage collection", Tags: "Go, runtime", CreatedAt: now}
+	titled := &model.Link{ID: "b", Title: "The Linux scheduler explained", CreatedAt: now}
+	sameSite := &model.Link{ID: "c", Title: "Release notes", Domain: "go.dev", CreatedAt: now}
+	unrelated := &model.Link{ID: "d", Title: "Sourdough for beginners", Tags: "baking", Domain: "example.com"}
+
+	results := Find(target, []*model.Link{unrelated, sameSite, titled, target, tagged}, 0)
+	var ids []string
+	for _, r := range results {
+		ids = append(ids, r.Link.ID)
+	}
+	if want := []string{"a", "b", "c"}; !reflect.DeepEqual(ids, want) {
+		t.Fatalf("Expected %v, got %v", want, ids)
+	}
+	if !reflect.DeepEqual(results[0].Tags, []string{"go", "runtime"}) {
+		t.Errorf("Expected shared tags go and runtime, got %v", results[0].Tags)
+	}
+	if !reflect.DeepEqual(results[1].Terms, []string{"scheduler"}) {
+		t.Errorf("Expected the shared term scheduler, got %v", results[1].Terms)
+	}
+	if !results[2].Domain {
+		t.Error("Expected the same-domain link to be marked")
+	}
+
+	if limited := Find(target, []*model.Link{unrelated, sameSite, titled, tagged}, 2); len(limited) != 2 {
+		t.Errorf("Expected the limit to apply, got %d results", len(limited))
+	}
+}
@@ -0,0 +1,67 @@
+// Package reltime formats timestamps relative to now, like "3h ago", for
+// listings where how long ago matters more than the exact time.
+package reltime
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	day   = 24 * time.Hour
+	week  = 7 * day
+	month = 30 * day
+	year  = 365 * day
+)
+
+// Format returns t relative to now in the largest unit that fits: "just
+// now", "5m ago", "3h ago", "2d ago", "3w ago", "4mo ago", "2y ago", or
+// "in 2d" for a time still to come.
+func Format(t, now time.Time) string {
+	d := now.Sub(t)
+	future := d < 0
+	if future {
+		d = -d
+	}
+	if d < time.Minute {
+		return "just now"
+	}
+	// Something two days away is a few milliseconds closer by now
+	d = d.Round(time.Minute)
+
+	var s string
+	switch {
+	case d < time.Hour:
+		s = fmt.Sprintf("%dm", d/time.Minute)
+	case d < day:
+		s = fmt.Sprintf("%dh", d/time.Hour)
+	case d < 2*week:
+		s = fmt.Sprintf("%dd", d/day)
+	case d < 2*month:
+		s = fmt.Sprintf("%dw", d/week)
+	case d < year:
+		s = fmt.Sprintf("%dmo", d/month)
+	default:
+		s = fmt.Sprintf("%dy", d/year)
+	}
+	if future {
+		return "in " + s
+	}
+	return s + " ago"
+}
+
+// Until describes the end of a period, such as a snooze, ending at t.
+// With relative times, a t still to come is "for 2d" and a past one
+// "until 2d ago"; otherwise it is "until " and t in layout, such as
+// "until 2024-01-02 09:00".
+func Until(t, now time.Time, relative bool, layout string) string {
+	if !relative {
+		return "until " + t.Format(layout)
+	}
+	shown := Format(t, now)
+	if rest, ok := strings.CutPrefix(shown, "in "); ok {
+		return "for " + rest
+	}
+	return "until " + shown
+}
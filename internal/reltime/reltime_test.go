@@ -0,0 +1,56 @@
+package reltime
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFormat(t *testing.T) {
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		ago  time.Duration
+		want string
+	}{
+		{0, "just now"},
+		{59 * time.Second, "just now"},
+		{5 * time.Minute, "5m ago"},
+		{59*time.Minute + 29*time.Second, "59m ago"},
+		{59*time.Minute + 31*time.Second, "1h ago"},
+		{3 * time.Hour, "3h ago"},
+		{2*day + 5*time.Hour, "2d ago"},
+		{13 * day, "13d ago"},
+		{3 * week, "3w ago"},
+		{4 * month, "4mo ago"},
+		{2*year + month, "2y ago"},
+		{-2 * day, "in 2d"},
+		{-2*day + time.Millisecond, "in 2d"},
+		{-30 * time.Second, "just now"},
+	}
+	for _, tt := range tests {
+		if got := Format(now.Add(-tt.ago), now); got != tt.want {
+			t.Errorf("Format(now - %v) = %q, want %q", tt.ago, got, tt.want)
+		}
+	}
+}
+
+func TestUntil(t *testing.T) {
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	const layout = "2006-01-02 15:04"
+	tests := []struct {
+		in       time.Duration
+		relative bool
+		want     string
+	}{
+		{2 * day, true, "for 2d"},
+		{3 * time.Hour, true, "for 3h"},
+		{30 * time.Second, true, "until just now"},
+		{-2 * day, true, "until 2d ago"},
+		{2 * day, false, "until 2026-10-19 12:00"},
+		{-2 * day, false, "until 2026-10-15 12:00"},
+	}
+	for _, tt := range tests {
+		if got := Until(now.Add(tt.in), now, tt.relative, layout); got != tt.want {
+			t.Errorf("Until(now + %v, relative %v) = %q, want %q", tt.in, tt.relative, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+// Package rules tags links automatically by their domain, URL, or title.
+package rules
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/bunchhieng/rl/internal/model"
+	"github.com/bunchhieng/rl/internal/storage"
+)
+
+// Rule tags the links that match all of its conditions.
+type Rule struct {
+	// Domain matches links on the host or any of its subdomains;
+	// "github.com" matches gist.github.com too.
+	Domain string
+	// URL is a pattern the whole URL must match, where * stands for any
+	// run of characters, e.g. "*/blog/*".
+	URL string
+	// Title is a word or phrase the title must contain, ignoring case.
+	Title string
+	// Tags are added to matching links.
+	Tags []string
+
+	url   *regexp.Regexp
+	title *regexp.Regexp
+}
+
+// New prepares rules for matching. Each rule needs a condition and a tag.
+func New(rules []Rule) ([]Rule, error) {
+	prepared := make([]Rule, len(rules))
+	for i, r := range rules {
+		if r.Domain == "" && r.URL == "" && r.Title == "" {
+			return nil, fmt.Errorf("rule %d needs a domain, url, or title to match", i+1)
+		}
+		if len(r.Tags) == 0 {
+			return nil, fmt.Errorf("rule %d has no tags", i+1)
+		}
+		r.Domain = strings.TrimPrefix(strings.ToLower(r.Domain), "www.")
+		if r.URL != "" {
+			parts := strings.Split(r.URL, "*")
+			for j, part := range parts {
+				parts[j] = regexp.QuoteMeta(part)
+			}
+			r.url = regexp.MustCompile("(?i)^" + strings.Join(parts, ".*") + "$")
+		}
+		if r.Title != "" {
+			// Whole words only, so "go" doesn't match "Google"
+			r.title = regexp.MustCompile(`(?i)(^|\W)` + regexp.QuoteMeta(strings.TrimSpace(r.Title)) + `($|\W)`)
+		}
+		prepared[i] = r
+	}
+	return prepared, nil
+}
+
+// Matches reports whether link meets every condition of r.
+func (r Rule) Matches(link *model.Link) bool {
+	if r.Domain != "" {
+		u, err := url.Parse(link.URL)
+		if err != nil {
+			return false
+		}
+		host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+		if host != r.Domain && !strings.HasSuffix(host, "."+r.Domain) {
+			return false
+		}
+	}
+	if r.url != nil && !r.url.MatchString(link.URL) {
+		return false
+	}
+	if r.title != nil && !r.title.MatchString(link.Title) {
+		return false
+	}
+	return true
+}
+
+// String describes r's conditions, e.g. `domain github.com, title "go"`.
+func (r Rule) String() string {
+	var conds []string
+	if r.Domain != "" {
+		conds = append(conds, "domain "+r.Domain)
+	}
+	if r.URL != "" {
+		conds = append(conds, "url "+r.URL)
+	}
+	if r.Title != "" {
+		conds = append(conds, fmt.Sprintf("title %q", r.Title))
+	}
+	return strings.Join(conds, ", ")
+}
+
+// Match returns the rules link matches.
+func Match(rules []Rule, link *model.Link) []Rule {
+	var matched []Rule
+	for _, r := range rules {
+		if r.Matches(link) {
+			matched = append(matched, r)
+		}
+	}
+	return matched
+}
+
+// Apply adds the tags of the rules link matches to it, and returns those
+// it didn't have.
+func Apply(rules []Rule, link *model.Link) []string {
+	var tags []string
+	for _, r := range Match(rules, link) {
+		tags = append(tags, r.Tags...)
+	}
+	if len(tags) == 0 {
+		return nil
+	}
+	had := map[string]bool{}
+	for _, tag := range link.TagList() {
+		had[strings.ToLower(tag)] = true
+	}
+	var added []string
+	for _, tag := range tags {
+		if !had[strings.ToLower(tag)] {
+			had[strings.ToLower(tag)] = true
+			added = append(added, tag)
+		}
+	}
+	link.MergeTags(&model.Link{Tags: strings.Join(added, ",")})
+	return added
+}
+
+// taggingStorage applies rules to links as they're added.
+type taggingStorage struct {
+	storage.Storage
+	rules []Rule
+}
+
+// Wrap returns s tagging each link added by rules. Imports are left as
+// they are. With no rules it returns s unchanged.
+func Wrap(s storage.Storage, rules []Rule) storage.Storage {
+	if len(rules) == 0 {
+		return s
+	}
+	return &taggingStorage{Storage: s, rules: rules}
+}
+
+// Add tags link by the rules it matches, then creates it.
+func (s *taggingStorage) Add(ctx context.Context, link *model.Link) (*model.Link, error) {
+	Apply(s.rules, link)
+	return s.Storage.Add(ctx, link)
+}
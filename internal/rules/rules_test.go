@@ -0,0 +1,84 @@
+package rules
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bunchhieng/rl/internal/model"
+	"github.com/bunchhieng/rl/internal/storage"
+)
+
+func TestMatch(t *testing.T) {
+	rules, err := New([]Rule{
+		{Domain: "github.com", Tags: []string{"code"}},
+		{URL: "*/blog/*", Tags: []string{"blog"}},
+		{Title: "Go", Tags: []string{"go"}},
+		{Domain: "go.dev", Title: "release", Tags: []string{"go", "release"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		url, title string
+		want       string
+	}{
+		{"https://github.com/golang/go", "", "code"},
+		{"https://gist.github.com/x", "", "code"},
+		{"https://www.GitHub.com/x", "", "code"},
+		{"https://notgithub.com/x", "", ""},
+		{"https://example.com/blog/post", "", "blog"},
+		{"https://example.com/blog", "", ""},
+		{"https://example.com", "Learning Go in 2026", "go"},
+		{"https://example.com", "Google Search", ""},
+		{"https://go.dev/blog/go1.24", "Go 1.24 Release Notes", "blog,go,release"},
+		{"https://example.com", "A release", ""},
+	}
+	for _, tt := range tests {
+		link := &model.Link{URL: tt.url, Title: tt.title}
+		Apply(rules, link)
+		if link.Tags != tt.want {
+			t.Errorf("Apply(%s, %q) tags = %q, want %q", tt.url, tt.title, link.Tags, tt.want)
+		}
+	}
+
+	link := &model.Link{URL: "https://github.com/x", Tags: "Code,later"}
+	if added := Apply(rules, link); len(added) != 0 || link.Tags != "Code,later" {
+		t.Errorf("Apply() added %q, tags %q; want existing tags kept regardless of case", added, link.Tags)
+	}
+
+	for _, bad := range []Rule{{Tags: []string{"x"}}, {Domain: "example.com"}} {
+		if _, err := New([]Rule{bad}); err == nil {
+			t.Errorf("Expected New(%+v) to fail", bad)
+		}
+	}
+}
+
+func TestWrap(t *testing.T) {
+	base, err := storage.NewSQLiteStorage(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer base.Close()
+	rules, err := New([]Rule{{Domain: "github.com", Tags: []string{"code"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := Wrap(base, rules)
+
+	ctx := context.Background()
+	link, err := s.Add(ctx, &model.Link{URL: "https://github.com/a", Tags: "later"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if link.Tags != "later,code" {
+		t.Errorf("Added link tags = %q, want %q", link.Tags, "later,code")
+	}
+	link, err = s.Add(ctx, &model.Link{URL: "https://example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if link.Tags != "" {
+		t.Errorf("Unmatched link tagged %q", link.Tags)
+	}
+}
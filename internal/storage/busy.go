@@ -0,0 +1,134 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// ErrBusy is returned when another process kept the database locked for
+// longer than an operation was willing to wait.
+var ErrBusy = errors.New("database is busy")
+
+// defaultBusyWait is how long an operation keeps retrying while another
+// process holds the write lock.
+const defaultBusyWait = 30 * time.Second
+
+// busyDB is the handle SQLiteStorage queries through. Statements and
+// transactions refused because another connection, usually another rl
+// process, holds the write lock are retried until wait runs out.
+// Transactions begin IMMEDIATE, taking the write lock up front, so their
+// statements are never refused and a retry never repeats part of one.
+type busyDB struct {
+	*sqlx.DB
+	wait   time.Duration
+	onBusy func()
+}
+
+// retry runs op until it succeeds, fails for a reason other than the
+// database being locked, or wait runs out.
+func (db *busyDB) retry(ctx context.Context, op func() error) error {
+	deadline := time.Now().Add(db.wait)
+	delay := 50 * time.Millisecond
+	notified := false
+	for {
+		err := op()
+		if !isBusy(err) {
+			return err
+		}
+		if time.Now().Add(delay).After(deadline) {
+			return fmt.Errorf("%w: another rl process is writing to it (gave up after %s)", ErrBusy, db.wait)
+		}
+		if !notified && db.onBusy != nil {
+			db.onBusy()
+			notified = true
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+		delay = min(2*delay, time.Second)
+	}
+}
+
+// isBusy reports whether err is SQLite refusing a statement because the
+// database is locked.
+func isBusy(err error) bool {
+	var sqliteErr *sqlite.Error
+	if !errors.As(err, &sqliteErr) {
+		return false
+	}
+	// Extended codes such as SQLITE_BUSY_SNAPSHOT keep the primary code in
+	// the low byte
+	code := sqliteErr.Code() & 0xff
+	return code == sqlite3.SQLITE_BUSY || code == sqlite3.SQLITE_LOCKED
+}
+
+func (db *busyDB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	var result sql.Result
+	err := db.retry(ctx, func() error {
+		var err error
+		result, err = db.DB.ExecContext(ctx, query, args...)
+		return err
+	})
+	return result, err
+}
+
+func (db *busyDB) GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	return db.retry(ctx, func() error {
+		return db.DB.GetContext(ctx, dest, query, args...)
+	})
+}
+
+func (db *busyDB) SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	return db.retry(ctx, func() error {
+		// Drop rows a refused attempt may have appended
+		v := reflect.ValueOf(dest).Elem()
+		v.Set(reflect.Zero(v.Type()))
+		return db.DB.SelectContext(ctx, dest, query, args...)
+	})
+}
+
+func (db *busyDB) QueryRowxContext(ctx context.Context, query string, args ...interface{}) *sqlx.Row {
+	var row *sqlx.Row
+	db.retry(ctx, func() error {
+		row = db.DB.QueryRowxContext(ctx, query, args...)
+		return row.Err()
+	})
+	return row
+}
+
+func (db *busyDB) BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error) {
+	var tx *sql.Tx
+	err := db.retry(ctx, func() error {
+		var err error
+		tx, err = db.DB.BeginTx(ctx, opts)
+		return err
+	})
+	return tx, err
+}
+
+func (db *busyDB) BeginTxx(ctx context.Context, opts *sql.TxOptions) (*sqlx.Tx, error) {
+	var tx *sqlx.Tx
+	err := db.retry(ctx, func() error {
+		var err error
+		tx, err = db.DB.BeginTxx(ctx, opts)
+		return err
+	})
+	return tx, err
+}
+
+// OnBusy sets a function called when an operation finds the database
+// locked by another process and starts waiting for it, e.g. to tell the
+// user why rl seems stuck.
+func (s *SQLiteStorage) OnBusy(fn func()) {
+	s.db.onBusy = fn
+}
@@ -0,0 +1,260 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// IndexCheck compares a full-text index with the table it indexes.
+type IndexCheck struct {
+	Index string `json:"index"`
+	Table string `json:"table"`
+	// Missing counts rows of Table with no entry in the index.
+	Missing int `json:"missing"`
+	// Stale counts index entries that are out of date, duplicated, or
+	// for rows no longer in Table.
+	Stale int `json:"stale"`
+	// Corrupt holds the error from FTS5's own integrity check, if any.
+	Corrupt string `json:"corrupt,omitempty"`
+}
+
+// OK reports whether the index matches its table.
+func (c IndexCheck) OK() bool {
+	return c.Missing == 0 && c.Stale == 0 && c.Corrupt == ""
+}
+
+// ftsIndexes describes each full-text index: the table it mirrors, the
+// columns joining them, and the conditions an up-to-date entry f meets for
+// its row t.
+var ftsIndexes = []struct {
+	index, table, key, tableKey string
+	current                     []string
+}{
+	{"links_fts", "links", "id", "id", []string{
+		"f.url = t.url", "f.title = COALESCE(t.title, '')", "f.tags = COALESCE(t.tags, '')", "f.description = COALESCE(t.description, '')",
+	}},
+	{"content_fts", "link_content", "link_id", "link_id", []string{"f.text = t.text"}},
+	{"quotes_fts", "quotes", "quote_id", "id", []string{"f.link_id = t.link_id", "f.text = t.text"}},
+	{"annotations_fts", "annotations", "annotation_id", "id", []string{"f.link_id = t.link_id", "f.text = t.text"}},
+}
+
+// orphanTables are the side tables whose rows belong to a link, deleted
+// along with it. read_log and sync_links are left out: their rows outlive
+// deleted links on purpose.
+var orphanTables = []string{
+	"url_aliases", "link_aliases", "link_content", "fetch_state",
+	"annotations", "quotes", "attachments", "summaries", "embeddings",
+	"link_history",
+}
+
+// IntegrityCheck runs SQLite's integrity check and returns the problems it
+// found, or nil if the database is sound.
+func (s *SQLiteStorage) IntegrityCheck(ctx context.Context) ([]string, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	var results []string
+	if err := s.db.SelectContext(ctx, &results, "PRAGMA integrity_check"); err != nil {
+		return nil, fmt.Errorf("integrity check: %w", err)
+	}
+	if len(results) == 1 && results[0] == "ok" {
+		return nil, nil
+	}
+	return results, nil
+}
+
+// CheckIndexes compares each full-text index with its table.
+func (s *SQLiteStorage) CheckIndexes(ctx context.Context) ([]IndexCheck, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	checks := make([]IndexCheck, 0, len(ftsIndexes))
+	for _, fts := range ftsIndexes {
+		check := IndexCheck{Index: fts.index, Table: fts.table}
+		missing := fmt.Sprintf("SELECT COUNT(*) FROM %s t WHERE t.%s NOT IN (SELECT %s FROM %s)",
+			fts.table, fts.tableKey, fts.key, fts.index)
+		if err := s.db.GetContext(ctx, &check.Missing, missing); err != nil {
+			return nil, fmt.Errorf("check %s: %w", fts.index, err)
+		}
+
+		// Entries matching a row exactly are current; everything else is stale
+		stale := fmt.Sprintf(`SELECT (SELECT COUNT(*) FROM %[1]s) -
+			(SELECT COUNT(DISTINCT t.%[3]s) FROM %[1]s f JOIN %[2]s t ON f.%[4]s = t.%[3]s WHERE %[5]s)`,
+			fts.index, fts.table, fts.tableKey, fts.key, strings.Join(fts.current, " AND "))
+		if err := s.db.GetContext(ctx, &check.Stale, stale); err != nil {
+			return nil, fmt.Errorf("check %s: %w", fts.index, err)
+		}
+
+		integrity := fmt.Sprintf("INSERT INTO %[1]s(%[1]s) VALUES ('integrity-check')", fts.index)
+		if _, err := s.db.ExecContext(ctx, integrity); err != nil {
+			check.Corrupt = err.Error()
+		}
+		checks = append(checks, check)
+	}
+	return checks, nil
+}
+
+// Orphans counts the rows of each side table whose link no longer exists,
+// leaving out tables with none.
+func (s *SQLiteStorage) Orphans(ctx context.Context) ([]Count, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	var orphans []Count
+	for _, table := range orphanTables {
+		var n int
+		query := fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE link_id NOT IN (SELECT id FROM links)", table)
+		if err := s.db.GetContext(ctx, &n, query); err != nil {
+			return nil, fmt.Errorf("count orphaned %s: %w", table, err)
+		}
+		if n > 0 {
+			orphans = append(orphans, Count{Name: table, Count: n})
+		}
+	}
+	return orphans, nil
+}
+
+// DeleteOrphans deletes the rows Orphans counts and returns how many were
+// removed.
+func (s *SQLiteStorage) DeleteOrphans(ctx context.Context) (int, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("delete orphans: %w", err)
+	}
+	defer tx.Rollback()
+
+	removed := 0
+	for _, table := range orphanTables {
+		query := fmt.Sprintf("DELETE FROM %s WHERE link_id NOT IN (SELECT id FROM links)", table)
+		result, err := tx.ExecContext(ctx, query)
+		if err != nil {
+			return 0, fmt.Errorf("delete orphaned %s: %w", table, err)
+		}
+		n, err := result.RowsAffected()
+		if err != nil {
+			return 0, fmt.Errorf("delete orphaned %s: %w", table, err)
+		}
+		removed += int(n)
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("delete orphans: %w", err)
+	}
+	return removed, nil
+}
+
+// DBSize describes how much space the database takes.
+type DBSize struct {
+	// Bytes is the size of the main database file, and Free how much of it
+	// is unused pages that a vacuum would reclaim.
+	Bytes int64 `json:"bytes"`
+	Free  int64 `json:"free"`
+	// WAL is the size of the write-ahead log not yet checkpointed into the
+	// main file.
+	WAL int64 `json:"wal"`
+	// Rows counts the rows of each table.
+	Rows []Count `json:"rows"`
+}
+
+// sizedTables are the tables DBSize counts rows of.
+var sizedTables = []string{
+	"links", "link_content", "annotations", "quotes", "attachments", "read_log",
+	"fetch_state", "summaries", "embeddings", "url_aliases",
+	"link_aliases", "sync_links",
+}
+
+// Size reports the database's size on disk and the rows in each table.
+func (s *SQLiteStorage) Size(ctx context.Context) (*DBSize, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	var pageSize, pages, freePages int64
+	for pragma, dest := range map[string]*int64{"page_size": &pageSize, "page_count": &pages, "freelist_count": &freePages} {
+		if err := s.db.GetContext(ctx, dest, "PRAGMA "+pragma); err != nil {
+			return nil, fmt.Errorf("read %s: %w", pragma, err)
+		}
+	}
+	size := &DBSize{Bytes: pages * pageSize, Free: freePages * pageSize}
+
+	var file string
+	if err := s.db.QueryRowxContext(ctx, "SELECT file FROM pragma_database_list WHERE name = 'main'").Scan(&file); err != nil {
+		return nil, fmt.Errorf("read database file: %w", err)
+	}
+	if file != "" {
+		if info, err := os.Stat(file + "-wal"); err == nil {
+			size.WAL = info.Size()
+		}
+	}
+
+	// Tables of migrations not yet applied are skipped
+	var tables []string
+	if err := s.db.SelectContext(ctx, &tables, "SELECT name FROM sqlite_master WHERE type = 'table'"); err != nil {
+		return nil, fmt.Errorf("list tables: %w", err)
+	}
+	exists := make(map[string]bool, len(tables))
+	for _, table := range tables {
+		exists[table] = true
+	}
+	for _, table := range sizedTables {
+		if !exists[table] {
+			continue
+		}
+		var n int
+		if err := s.db.GetContext(ctx, &n, "SELECT COUNT(*) FROM "+table); err != nil {
+			return nil, fmt.Errorf("count %s: %w", table, err)
+		}
+		size.Rows = append(size.Rows, Count{Name: table, Count: n})
+	}
+	return size, nil
+}
+
+// Vacuum rebuilds the database file, reclaiming unused pages.
+func (s *SQLiteStorage) Vacuum(ctx context.Context) error {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	if _, err := s.db.ExecContext(ctx, "VACUUM"); err != nil {
+		return fmt.Errorf("vacuum: %w", err)
+	}
+	return nil
+}
+
+// BackupTo writes a consistent, compacted copy of the database to path,
+// which must not exist. Other processes can keep reading and writing
+// meanwhile.
+func (s *SQLiteStorage) BackupTo(ctx context.Context, path string) error {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	if _, err := s.db.ExecContext(ctx, "VACUUM INTO ?", path); err != nil {
+		return fmt.Errorf("back up database: %w", err)
+	}
+	return nil
+}
+
+// Checkpoint copies the write-ahead log into the main database file and
+// truncates it.
+func (s *SQLiteStorage) Checkpoint(ctx context.Context) error {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	if _, err := s.db.ExecContext(ctx, "PRAGMA wal_checkpoint(TRUNCATE)"); err != nil {
+		return fmt.Errorf("checkpoint: %w", err)
+	}
+	return nil
+}
+
+// Migrate applies any pending migrations.
+func (s *SQLiteStorage) Migrate(ctx context.Context) error {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	if err := runMigrations(ctx, s.db.DB.DB); err != nil {
+		return fmt.Errorf("run migrations: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package storage
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/bunchhieng/rl/internal/model"
+)
+
+// minSimilarity is the average per-term similarity a link needs to be an
+// approximate match. It lets "kubernets" find "kubernetes" without "go"
+// matching every title containing "got".
+const minSimilarity = 0.5
+
+// approximateMatches scores links against the terms of query by trigram
+// similarity over their titles and URLs, returning up to limit matches,
+// best first. Terms after NOT and operators are ignored.
+func approximateMatches(links []*model.Link, query string, limit int) []*model.Link {
+	terms := fuzzyTerms(query)
+	if len(terms) == 0 {
+		return nil
+	}
+
+	type scored struct {
+		link  *model.Link
+		score float64
+	}
+	var matches []scored
+	for _, link := range links {
+		words := fuzzyWords(link.Title + " " + link.URL)
+		total := 0.0
+		for _, term := range terms {
+			best := 0.0
+			for _, word := range words {
+				if sim := termSimilarity(term, word); sim > best {
+					best = sim
+				}
+			}
+			total += best
+		}
+		if score := total / float64(len(terms)); score >= minSimilarity {
+			matches = append(matches, scored{link, score})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].score > matches[j].score
+	})
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+
+	result := make([]*model.Link, len(matches))
+	for i, m := range matches {
+		result[i] = m.link
+	}
+	return result
+}
+
+// fuzzyTerms returns the lowercased words of a search query, dropping
+// operators, field prefixes, and negated terms.
+func fuzzyTerms(query string) []string {
+	var terms []string
+	negated := false
+	for _, tok := range tokenizeQuery(query) {
+		switch tok.kind {
+		case tokenOperator:
+			negated = tok.text == "NOT"
+		case tokenTerm, tokenPhrase:
+			if !negated {
+				terms = append(terms, fuzzyWords(tok.text)...)
+			}
+			negated = false
+		}
+	}
+	return terms
+}
+
+// fuzzyWords splits s into lowercased runs of letters and digits.
+func fuzzyWords(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// termSimilarity rates how closely word matches a query term, from 0 to 1.
+// A word containing a term of three or more characters is a full match;
+// otherwise it is the Jaccard similarity of their trigrams.
+func termSimilarity(term, word string) float64 {
+	if term == word || (len([]rune(term)) >= 3 && strings.Contains(word, term)) {
+		return 1
+	}
+	a, b := trigrams(term), trigrams(word)
+	common := 0
+	for t := range a {
+		if b[t] {
+			common++
+		}
+	}
+	return float64(common) / float64(len(a)+len(b)-common)
+}
+
+// trigrams returns the set of three-rune windows of word, padded so short
+// words and word boundaries contribute.
+func trigrams(word string) map[string]bool {
+	runes := []rune("  " + word + " ")
+	set := make(map[string]bool, len(runes))
+	for i := 0; i+3 <= len(runes); i++ {
+		set[string(runes[i:i+3])] = true
+	}
+	return set
+}
@@ -8,47 +8,98 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 )
 
 //go:embed migrations/*.sql
 var migrationsFS embed.FS
 
-func runMigrations(ctx context.Context, db *sql.DB) error {
+// migration is one numbered schema change: NNN_name.sql applies it and,
+// when present, NNN_name.down.sql reverses it.
+type migration struct {
+	version int
+	name    string
+	up      string
+	down    string
+}
+
+// MigrationStatus describes a schema migration and whether it has been
+// applied to the database.
+type MigrationStatus struct {
+	Version int    `json:"version"`
+	Name    string `json:"name"`
+	// AppliedAt is nil for migrations not yet applied.
+	AppliedAt *time.Time `json:"applied_at"`
+	// Reversible reports whether the migration has a down script, so
+	// RollbackTo can undo it.
+	Reversible bool `json:"reversible"`
+	// Unknown marks migrations recorded in the database that this build
+	// doesn't have, such as those applied by a newer rl.
+	Unknown bool `json:"unknown,omitempty"`
+}
+
+// loadMigrations returns the embedded migrations, oldest first.
+func loadMigrations() ([]migration, error) {
 	entries, err := migrationsFS.ReadDir("migrations")
 	if err != nil {
-		return fmt.Errorf("read migrations directory: %w", err)
+		return nil, fmt.Errorf("read migrations directory: %w", err)
 	}
 
-	migrationFiles := make([]string, 0, len(entries))
+	byVersion := make(map[int]*migration)
 	for _, entry := range entries {
-		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".sql") {
-			migrationFiles = append(migrationFiles, entry.Name())
+		filename := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(filename, ".sql") {
+			continue
+		}
+		base, isDown := strings.CutSuffix(strings.TrimSuffix(filename, ".sql"), ".down")
+		prefix, name, _ := strings.Cut(base, "_")
+		version, err := strconv.Atoi(prefix)
+		if err != nil {
+			continue
+		}
+
+		m := byVersion[version]
+		if m == nil {
+			m = &migration{version: version, name: name}
+			byVersion[version] = m
+		}
+		if isDown {
+			m.down = filename
+		} else {
+			m.up = filename
 		}
 	}
-	sort.Strings(migrationFiles)
+
+	migrations := make([]migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.up == "" {
+			return nil, fmt.Errorf("migration %s has no up script", m.down)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].version < migrations[j].version })
+	return migrations, nil
+}
+
+func runMigrations(ctx context.Context, db *sql.DB) error {
+	migrations, err := loadMigrations()
+	if err != nil {
+		return err
+	}
 
 	applied, err := getAppliedMigrations(ctx, db)
 	if err != nil {
 		return fmt.Errorf("get applied migrations: %w", err)
 	}
 
-	for _, filename := range migrationFiles {
-		parts := strings.Split(filename, "_")
-		if len(parts) == 0 {
-			continue
-		}
-		version, err := strconv.Atoi(parts[0])
-		if err != nil {
-			continue
-		}
-
-		if applied[version] {
+	for _, m := range migrations {
+		if _, ok := applied[m.version]; ok {
 			continue
 		}
 
-		content, err := migrationsFS.ReadFile("migrations/" + filename)
+		content, err := migrationsFS.ReadFile("migrations/" + m.up)
 		if err != nil {
-			return fmt.Errorf("read migration %s: %w", filename, err)
+			return fmt.Errorf("read migration %s: %w", m.up, err)
 		}
 
 		tx, err := db.BeginTx(ctx, nil)
@@ -58,26 +109,28 @@ func runMigrations(ctx context.Context, db *sql.DB) error {
 
 		if _, err := tx.ExecContext(ctx, string(content)); err != nil {
 			tx.Rollback()
-			return fmt.Errorf("execute migration %s: %w", filename, err)
+			return fmt.Errorf("execute migration %s: %w", m.up, err)
 		}
 
 		if _, err := tx.ExecContext(ctx,
 			"INSERT INTO schema_migrations (version, applied_at) VALUES (?, datetime('now'))",
-			version); err != nil {
+			m.version); err != nil {
 			tx.Rollback()
-			return fmt.Errorf("record migration %s: %w", filename, err)
+			return fmt.Errorf("record migration %s: %w", m.up, err)
 		}
 
 		if err := tx.Commit(); err != nil {
-			return fmt.Errorf("commit migration %s: %w", filename, err)
+			return fmt.Errorf("commit migration %s: %w", m.up, err)
 		}
 	}
 
 	return nil
 }
 
-func getAppliedMigrations(ctx context.Context, db *sql.DB) (map[int]bool, error) {
-	applied := make(map[int]bool)
+// getAppliedMigrations returns when each applied migration was applied,
+// by version.
+func getAppliedMigrations(ctx context.Context, db *sql.DB) (map[int]string, error) {
+	applied := make(map[int]string)
 
 	_, err := db.ExecContext(ctx, `
 		CREATE TABLE IF NOT EXISTS schema_migrations (
@@ -89,7 +142,7 @@ func getAppliedMigrations(ctx context.Context, db *sql.DB) (map[int]bool, error)
 		return nil, fmt.Errorf("create schema_migrations table: %w", err)
 	}
 
-	rows, err := db.QueryContext(ctx, "SELECT version FROM schema_migrations")
+	rows, err := db.QueryContext(ctx, "SELECT version, applied_at FROM schema_migrations")
 	if err != nil {
 		return nil, fmt.Errorf("query applied migrations: %w", err)
 	}
@@ -97,11 +150,109 @@ func getAppliedMigrations(ctx context.Context, db *sql.DB) (map[int]bool, error)
 
 	for rows.Next() {
 		var version int
-		if err := rows.Scan(&version); err != nil {
+		var appliedAt string
+		if err := rows.Scan(&version, &appliedAt); err != nil {
 			return nil, fmt.Errorf("scan migration version: %w", err)
 		}
-		applied[version] = true
+		applied[version] = appliedAt
 	}
 
 	return applied, rows.Err()
 }
+
+// Migrations lists every migration this build knows of, plus any the
+// database records that it doesn't, in version order.
+func (s *SQLiteStorage) Migrations(ctx context.Context) ([]MigrationStatus, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	migrations, err := loadMigrations()
+	if err != nil {
+		return nil, err
+	}
+	applied, err := getAppliedMigrations(ctx, s.db.DB.DB)
+	if err != nil {
+		return nil, fmt.Errorf("get applied migrations: %w", err)
+	}
+
+	statuses := make([]MigrationStatus, 0, len(migrations))
+	for _, m := range migrations {
+		status := MigrationStatus{Version: m.version, Name: m.name, Reversible: m.down != ""}
+		if appliedAt, ok := applied[m.version]; ok {
+			t := parseSQLiteTime(appliedAt)
+			status.AppliedAt = &t
+			delete(applied, m.version)
+		}
+		statuses = append(statuses, status)
+	}
+	for version, appliedAt := range applied {
+		t := parseSQLiteTime(appliedAt)
+		statuses = append(statuses, MigrationStatus{Version: version, AppliedAt: &t, Unknown: true})
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Version < statuses[j].Version })
+	return statuses, nil
+}
+
+// RollbackTo reverses every applied migration newer than version, newest
+// first, returning the versions rolled back. Either all of them are
+// reversed or, if any lacks a down script or fails, none are. The next
+// NewSQLiteStorage on the database applies them again.
+func (s *SQLiteStorage) RollbackTo(ctx context.Context, version int) ([]MigrationStatus, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	statuses, err := s.Migrations(ctx)
+	if err != nil {
+		return nil, err
+	}
+	migrations, err := loadMigrations()
+	if err != nil {
+		return nil, err
+	}
+	downs := make(map[int]string, len(migrations))
+	for _, m := range migrations {
+		downs[m.version] = m.down
+	}
+
+	var targets []MigrationStatus
+	for i := len(statuses) - 1; i >= 0; i-- {
+		m := statuses[i]
+		if m.Version <= version || m.AppliedAt == nil {
+			continue
+		}
+		switch {
+		case m.Unknown:
+			return nil, fmt.Errorf("migration %03d was applied by a newer version of rl and can't be rolled back by this one", m.Version)
+		case !m.Reversible:
+			return nil, fmt.Errorf("migration %03d_%s can't be rolled back", m.Version, m.Name)
+		}
+		targets = append(targets, m)
+	}
+	if len(targets) == 0 {
+		return nil, nil
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, m := range targets {
+		content, err := migrationsFS.ReadFile("migrations/" + downs[m.Version])
+		if err != nil {
+			return nil, fmt.Errorf("read migration %s: %w", downs[m.Version], err)
+		}
+		if _, err := tx.ExecContext(ctx, string(content)); err != nil {
+			return nil, fmt.Errorf("execute migration %s: %w", downs[m.Version], err)
+		}
+		if _, err := tx.ExecContext(ctx, "DELETE FROM schema_migrations WHERE version = ?", m.Version); err != nil {
+			return nil, fmt.Errorf("record rollback of %s: %w", downs[m.Version], err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit rollback: %w", err)
+	}
+	return targets, nil
+}
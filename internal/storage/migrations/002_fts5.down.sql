@@ -0,0 +1,4 @@
+DROP TRIGGER IF EXISTS links_ai;
+DROP TRIGGER IF EXISTS links_ad;
+DROP TRIGGER IF EXISTS links_au;
+DROP TABLE IF EXISTS links_fts;
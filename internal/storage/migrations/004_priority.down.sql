@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_links_priority;
+ALTER TABLE links DROP COLUMN priority;
@@ -0,0 +1,6 @@
+-- Add priority levels to links
+-- 1 = high, 0 = normal, -1 = low
+
+ALTER TABLE links ADD COLUMN priority INTEGER NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_links_priority ON links(priority);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_links_snoozed_until;
+ALTER TABLE links DROP COLUMN snoozed_until;
@@ -0,0 +1,6 @@
+-- Add snooze support: links are hidden from the unread list until snoozed_until
+-- Stored as UTC RFC3339 so string comparison matches chronological order
+
+ALTER TABLE links ADD COLUMN snoozed_until TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_links_snoozed_until ON links(snoozed_until);
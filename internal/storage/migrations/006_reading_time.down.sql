@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_links_reading_time;
+ALTER TABLE links DROP COLUMN reading_time;
+ALTER TABLE links DROP COLUMN word_count;
@@ -0,0 +1,6 @@
+-- Add word count and estimated reading time (in seconds) from fetched metadata
+
+ALTER TABLE links ADD COLUMN word_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE links ADD COLUMN reading_time INTEGER NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_links_reading_time ON links(reading_time);
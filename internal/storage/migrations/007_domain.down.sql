@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_links_domain;
+ALTER TABLE links DROP COLUMN domain;
@@ -0,0 +1,15 @@
+-- Store each link's domain in its own indexed column
+-- Backfill existing rows: strip scheme, path/query/fragment, userinfo, port, and a leading www.
+
+ALTER TABLE links ADD COLUMN domain TEXT NOT NULL DEFAULT '';
+
+UPDATE links SET domain = substr(url, instr(url, '://') + 3) WHERE instr(url, '://') > 0;
+UPDATE links SET domain = substr(domain, 1, instr(domain, '/') - 1) WHERE instr(domain, '/') > 0;
+UPDATE links SET domain = substr(domain, 1, instr(domain, '?') - 1) WHERE instr(domain, '?') > 0;
+UPDATE links SET domain = substr(domain, 1, instr(domain, '#') - 1) WHERE instr(domain, '#') > 0;
+UPDATE links SET domain = substr(domain, instr(domain, '@') + 1) WHERE instr(domain, '@') > 0;
+UPDATE links SET domain = substr(domain, 1, instr(domain, ':') - 1) WHERE instr(domain, ':') > 0;
+UPDATE links SET domain = lower(domain);
+UPDATE links SET domain = substr(domain, 5) WHERE domain LIKE 'www.%';
+
+CREATE INDEX IF NOT EXISTS idx_links_domain ON links(domain);
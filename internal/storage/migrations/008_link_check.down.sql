@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_links_http_status;
+ALTER TABLE links DROP COLUMN checked_at;
+ALTER TABLE links DROP COLUMN http_status;
@@ -0,0 +1,7 @@
+-- Record the result of dead-link checks
+-- http_status is 0 when the last check failed without a response (timeout, DNS error)
+
+ALTER TABLE links ADD COLUMN http_status INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE links ADD COLUMN checked_at TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_links_http_status ON links(http_status);
@@ -0,0 +1,10 @@
+-- Alternate URLs (e.g. shortened links) that resolve to a saved link
+-- Looked up when adding so re-saving a short link finds the existing entry
+
+CREATE TABLE IF NOT EXISTS url_aliases (
+    url TEXT PRIMARY KEY,
+    link_id TEXT NOT NULL,
+    created_at TEXT NOT NULL DEFAULT (datetime('now'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_url_aliases_link_id ON url_aliases(link_id);
@@ -0,0 +1,9 @@
+-- User-defined names (e.g. "golang-sched") usable anywhere a link ID is accepted
+
+CREATE TABLE IF NOT EXISTS link_aliases (
+    name TEXT PRIMARY KEY COLLATE NOCASE,
+    link_id TEXT NOT NULL,
+    created_at TEXT NOT NULL DEFAULT (datetime('now'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_link_aliases_link_id ON link_aliases(link_id);
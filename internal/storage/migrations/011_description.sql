@@ -0,0 +1,3 @@
+-- Page description (meta description / og:description) captured when fetching
+
+ALTER TABLE links ADD COLUMN description TEXT NOT NULL DEFAULT '';
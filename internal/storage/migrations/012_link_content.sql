@@ -0,0 +1,7 @@
+-- Archived article text, kept out of the links table so listings stay light
+
+CREATE TABLE IF NOT EXISTS link_content (
+    link_id TEXT PRIMARY KEY,
+    text TEXT NOT NULL,
+    fetched_at TEXT NOT NULL DEFAULT (datetime('now'))
+);
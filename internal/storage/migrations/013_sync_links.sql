@@ -0,0 +1,15 @@
+-- Links mirrored to remote services such as Wallabag. read holds the read
+-- state both sides agreed on at the last sync, so a later change can be
+-- attributed to the side that made it. Rows outlive deleted links so a sync
+-- doesn't bring them back.
+
+CREATE TABLE IF NOT EXISTS sync_links (
+    service TEXT NOT NULL,
+    link_id TEXT NOT NULL,
+    remote_id TEXT NOT NULL,
+    read INTEGER NOT NULL DEFAULT 0,
+    synced_at TEXT NOT NULL DEFAULT (datetime('now')),
+    PRIMARY KEY (service, link_id)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_sync_links_remote ON sync_links(service, remote_id);
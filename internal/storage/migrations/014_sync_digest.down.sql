@@ -0,0 +1 @@
+ALTER TABLE sync_links DROP COLUMN digest;
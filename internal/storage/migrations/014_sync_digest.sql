@@ -0,0 +1,4 @@
+-- Fingerprint of the fields last sent to push-only services (Pinboard), so
+-- only links changed since the last push are sent again
+
+ALTER TABLE sync_links ADD COLUMN digest TEXT NOT NULL DEFAULT '';
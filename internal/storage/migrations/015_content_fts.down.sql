@@ -0,0 +1,4 @@
+DROP TRIGGER IF EXISTS link_content_ai;
+DROP TRIGGER IF EXISTS link_content_ad;
+DROP TRIGGER IF EXISTS link_content_au;
+DROP TABLE IF EXISTS content_fts;
@@ -0,0 +1,23 @@
+-- Full-text index of archived article text. Rows are keyed by link_id
+-- rather than rowid so they survive links being rewritten.
+
+CREATE VIRTUAL TABLE IF NOT EXISTS content_fts USING fts5(
+    link_id UNINDEXED,
+    text
+);
+
+INSERT INTO content_fts(link_id, text)
+SELECT link_id, text FROM link_content;
+
+CREATE TRIGGER IF NOT EXISTS link_content_ai AFTER INSERT ON link_content BEGIN
+    INSERT INTO content_fts(link_id, text) VALUES (new.link_id, new.text);
+END;
+
+CREATE TRIGGER IF NOT EXISTS link_content_ad AFTER DELETE ON link_content BEGIN
+    DELETE FROM content_fts WHERE link_id = old.link_id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS link_content_au AFTER UPDATE ON link_content BEGIN
+    DELETE FROM content_fts WHERE link_id = old.link_id;
+    INSERT INTO content_fts(link_id, text) VALUES (new.link_id, new.text);
+END;
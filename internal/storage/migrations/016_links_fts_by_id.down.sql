@@ -0,0 +1,32 @@
+-- Back to the rowid-keyed index of 002
+
+DROP TRIGGER IF EXISTS links_fts_ai;
+DROP TRIGGER IF EXISTS links_fts_ad;
+DROP TRIGGER IF EXISTS links_fts_au;
+DROP TABLE IF EXISTS links_fts;
+
+CREATE VIRTUAL TABLE links_fts USING fts5(
+    url,
+    title,
+    note,
+    tags
+);
+
+INSERT INTO links_fts(rowid, url, title, note, tags)
+SELECT rowid, url, COALESCE(title, ''), COALESCE(note, ''), COALESCE(tags, '')
+FROM links;
+
+CREATE TRIGGER IF NOT EXISTS links_ai AFTER INSERT ON links BEGIN
+    INSERT INTO links_fts(rowid, url, title, note, tags)
+    VALUES (new.rowid, new.url, COALESCE(new.title, ''), COALESCE(new.note, ''), COALESCE(new.tags, ''));
+END;
+
+CREATE TRIGGER IF NOT EXISTS links_ad AFTER DELETE ON links BEGIN
+    DELETE FROM links_fts WHERE rowid = old.rowid;
+END;
+
+CREATE TRIGGER IF NOT EXISTS links_au AFTER UPDATE ON links BEGIN
+    DELETE FROM links_fts WHERE rowid = old.rowid;
+    INSERT INTO links_fts(rowid, url, title, note, tags)
+    VALUES (new.rowid, new.url, COALESCE(new.title, ''), COALESCE(new.note, ''), COALESCE(new.tags, ''));
+END;
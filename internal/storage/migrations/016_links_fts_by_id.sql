@@ -0,0 +1,37 @@
+-- Rebuild the link index. Migration 003 recreated the links table, which
+-- dropped the triggers from 002, so links_fts stopped following changes.
+-- Rows are now keyed by link id rather than rowid, which the DELETE +
+-- INSERT in Add changes on every update.
+
+DROP TRIGGER IF EXISTS links_ai;
+DROP TRIGGER IF EXISTS links_ad;
+DROP TRIGGER IF EXISTS links_au;
+DROP TABLE IF EXISTS links_fts;
+
+CREATE VIRTUAL TABLE links_fts USING fts5(
+    id UNINDEXED,
+    url,
+    title,
+    note,
+    tags,
+    description
+);
+
+INSERT INTO links_fts(id, url, title, note, tags, description)
+SELECT id, url, COALESCE(title, ''), COALESCE(note, ''), COALESCE(tags, ''), COALESCE(description, '')
+FROM links;
+
+CREATE TRIGGER links_fts_ai AFTER INSERT ON links BEGIN
+    INSERT INTO links_fts(id, url, title, note, tags, description)
+    VALUES (new.id, new.url, COALESCE(new.title, ''), COALESCE(new.note, ''), COALESCE(new.tags, ''), COALESCE(new.description, ''));
+END;
+
+CREATE TRIGGER links_fts_ad AFTER DELETE ON links BEGIN
+    DELETE FROM links_fts WHERE id = old.id;
+END;
+
+CREATE TRIGGER links_fts_au AFTER UPDATE ON links BEGIN
+    DELETE FROM links_fts WHERE id = old.id;
+    INSERT INTO links_fts(id, url, title, note, tags, description)
+    VALUES (new.id, new.url, COALESCE(new.title, ''), COALESCE(new.note, ''), COALESCE(new.tags, ''), COALESCE(new.description, ''));
+END;
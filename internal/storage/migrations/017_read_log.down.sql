@@ -0,0 +1,3 @@
+DROP TRIGGER IF EXISTS read_log_ai;
+DROP TRIGGER IF EXISTS read_log_au;
+DROP TABLE IF EXISTS read_log;
@@ -0,0 +1,21 @@
+-- When links were marked read, for reading streaks. Kept apart from links
+-- so the history survives links being marked unread or deleted.
+
+CREATE TABLE IF NOT EXISTS read_log (
+    link_id TEXT NOT NULL,
+    read_at TEXT NOT NULL,
+    PRIMARY KEY (link_id, read_at)
+);
+
+INSERT OR IGNORE INTO read_log(link_id, read_at)
+SELECT id, read_at FROM links WHERE read_at IS NOT NULL;
+
+CREATE TRIGGER IF NOT EXISTS read_log_ai AFTER INSERT ON links
+WHEN new.read_at IS NOT NULL BEGIN
+    INSERT OR IGNORE INTO read_log(link_id, read_at) VALUES (new.id, new.read_at);
+END;
+
+CREATE TRIGGER IF NOT EXISTS read_log_au AFTER UPDATE OF read_at ON links
+WHEN old.read_at IS NULL AND new.read_at IS NOT NULL BEGIN
+    INSERT OR IGNORE INTO read_log(link_id, read_at) VALUES (new.id, new.read_at);
+END;
@@ -0,0 +1,10 @@
+-- Dated notes added to a link over time, alongside its single note
+
+CREATE TABLE IF NOT EXISTS annotations (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    link_id TEXT NOT NULL,
+    text TEXT NOT NULL,
+    created_at TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_annotations_link_id ON annotations(link_id, created_at);
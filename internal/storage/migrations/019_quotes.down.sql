@@ -0,0 +1,4 @@
+DROP TRIGGER IF EXISTS quotes_ai;
+DROP TRIGGER IF EXISTS quotes_ad;
+DROP TABLE IF EXISTS quotes_fts;
+DROP TABLE IF EXISTS quotes;
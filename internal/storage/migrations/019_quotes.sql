@@ -0,0 +1,24 @@
+-- Highlighted passages saved from links, with a full-text index keyed by
+-- quote id so search can find links by what was highlighted in them
+
+CREATE TABLE IF NOT EXISTS quotes (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    link_id TEXT NOT NULL,
+    text TEXT NOT NULL,
+    created_at TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_quotes_link_id ON quotes(link_id, created_at);
+
+CREATE VIRTUAL TABLE IF NOT EXISTS quotes_fts USING fts5(
+    quote_id UNINDEXED,
+    link_id UNINDEXED,
+    text
+);
+
+CREATE TRIGGER IF NOT EXISTS quotes_ai AFTER INSERT ON quotes BEGIN
+    INSERT INTO quotes_fts(quote_id, link_id, text) VALUES (new.id, new.link_id, new.text);
+END;
+
+CREATE TRIGGER IF NOT EXISTS quotes_ad AFTER DELETE ON quotes BEGIN
+    DELETE FROM quotes_fts WHERE quote_id = old.id;
+END;
@@ -0,0 +1,13 @@
+-- When each link's page was last fetched, with the validators the server
+-- sent, so later fetches can ask for it only if it changed
+
+CREATE TABLE IF NOT EXISTS fetch_state (
+    link_id TEXT PRIMARY KEY,
+    etag TEXT NOT NULL DEFAULT '',
+    last_modified TEXT NOT NULL DEFAULT '',
+    fetched_at TEXT NOT NULL
+);
+
+-- Links with archived text were fetched then; their validators are unknown
+INSERT OR IGNORE INTO fetch_state (link_id, fetched_at)
+SELECT link_id, fetched_at FROM link_content;
@@ -0,0 +1,2 @@
+ALTER TABLE links DROP COLUMN author;
+ALTER TABLE links DROP COLUMN media_type;
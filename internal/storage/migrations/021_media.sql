@@ -0,0 +1,5 @@
+-- Media type ("video", "audio", or '' for articles) and author, from oEmbed
+-- or page metadata captured when fetching
+
+ALTER TABLE links ADD COLUMN media_type TEXT NOT NULL DEFAULT '';
+ALTER TABLE links ADD COLUMN author TEXT NOT NULL DEFAULT '';
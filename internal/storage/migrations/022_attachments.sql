@@ -0,0 +1,14 @@
+-- Downloaded copies of documents (PDFs and the like) that links point to.
+-- Files live in a content-addressed directory next to the database; path is
+-- relative to it.
+
+CREATE TABLE IF NOT EXISTS attachments (
+    link_id TEXT PRIMARY KEY,
+    sha256 TEXT NOT NULL,
+    path TEXT NOT NULL,
+    content_type TEXT NOT NULL DEFAULT '',
+    size INTEGER NOT NULL,
+    created_at TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_attachments_path ON attachments(path);
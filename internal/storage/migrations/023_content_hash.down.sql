@@ -0,0 +1 @@
+ALTER TABLE link_content DROP COLUMN sha256;
@@ -0,0 +1,4 @@
+-- SHA-256 of the archived text, for telling whether a page changed since
+-- it was saved. Text archived before this is hashed when first needed.
+
+ALTER TABLE link_content ADD COLUMN sha256 TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_links_read_time;
+DROP INDEX IF EXISTS idx_links_created_time;
+DROP INDEX IF EXISTS idx_links_domain_created;
+CREATE INDEX IF NOT EXISTS idx_links_domain ON links(domain);
+DROP INDEX IF EXISTS idx_links_read_at_created;
+CREATE INDEX IF NOT EXISTS idx_links_read_at ON links(read_at);
+DROP INDEX IF EXISTS idx_links_queue;
@@ -0,0 +1,18 @@
+-- Indexes matching how links are listed, so large libraries list without
+-- sorting or scanning the whole table.
+-- The unread queue: read_at IS NULL in the default priority order. Its
+-- expression must match orderBy's exactly for SQLite to use it; 032
+-- replaces it with one that also orders by queue place.
+CREATE INDEX IF NOT EXISTS idx_links_queue ON links(read_at, (CASE WHEN read_at IS NULL THEN priority ELSE 0 END) DESC, created_at DESC);
+
+-- Unread or read links, newest or oldest first
+DROP INDEX IF EXISTS idx_links_read_at;
+CREATE INDEX IF NOT EXISTS idx_links_read_at_created ON links(read_at, created_at);
+
+-- One domain's links, and sorting by domain
+DROP INDEX IF EXISTS idx_links_domain;
+CREATE INDEX IF NOT EXISTS idx_links_domain_created ON links(domain, created_at DESC);
+
+-- Date ranges (--since, --read-since, ...) compare through datetime()
+CREATE INDEX IF NOT EXISTS idx_links_created_time ON links(datetime(created_at));
+CREATE INDEX IF NOT EXISTS idx_links_read_time ON links(datetime(read_at));
@@ -0,0 +1,5 @@
+DROP TRIGGER IF EXISTS link_history_title;
+DROP TRIGGER IF EXISTS link_history_note;
+DROP TRIGGER IF EXISTS link_history_tags;
+DROP TRIGGER IF EXISTS link_history_url;
+DROP TABLE IF EXISTS link_history;
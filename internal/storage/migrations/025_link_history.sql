@@ -0,0 +1,36 @@
+-- Earlier values of each link's title, note, tags, and URL, recorded by
+-- triggers so every way of editing a link is covered
+
+CREATE TABLE IF NOT EXISTS link_history (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    link_id TEXT NOT NULL,
+    field TEXT NOT NULL,
+    old_value TEXT NOT NULL,
+    new_value TEXT NOT NULL,
+    changed_at TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_link_history_link_id ON link_history(link_id, id);
+
+CREATE TRIGGER IF NOT EXISTS link_history_title AFTER UPDATE OF title ON links
+WHEN coalesce(old.title, '') IS NOT coalesce(new.title, '') BEGIN
+    INSERT INTO link_history(link_id, field, old_value, new_value, changed_at)
+    VALUES (new.id, 'title', coalesce(old.title, ''), coalesce(new.title, ''), strftime('%Y-%m-%dT%H:%M:%SZ', 'now'));
+END;
+
+CREATE TRIGGER IF NOT EXISTS link_history_note AFTER UPDATE OF note ON links
+WHEN coalesce(old.note, '') IS NOT coalesce(new.note, '') BEGIN
+    INSERT INTO link_history(link_id, field, old_value, new_value, changed_at)
+    VALUES (new.id, 'note', coalesce(old.note, ''), coalesce(new.note, ''), strftime('%Y-%m-%dT%H:%M:%SZ', 'now'));
+END;
+
+CREATE TRIGGER IF NOT EXISTS link_history_tags AFTER UPDATE OF tags ON links
+WHEN coalesce(old.tags, '') IS NOT coalesce(new.tags, '') BEGIN
+    INSERT INTO link_history(link_id, field, old_value, new_value, changed_at)
+    VALUES (new.id, 'tags', coalesce(old.tags, ''), coalesce(new.tags, ''), strftime('%Y-%m-%dT%H:%M:%SZ', 'now'));
+END;
+
+CREATE TRIGGER IF NOT EXISTS link_history_url AFTER UPDATE OF url ON links
+WHEN old.url IS NOT new.url BEGIN
+    INSERT INTO link_history(link_id, field, old_value, new_value, changed_at)
+    VALUES (new.id, 'url', old.url, new.url, strftime('%Y-%m-%dT%H:%M:%SZ', 'now'));
+END;
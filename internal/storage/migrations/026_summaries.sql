@@ -0,0 +1,9 @@
+-- Short summaries of links' archived text, written by a language model
+-- for rl summarize
+
+CREATE TABLE IF NOT EXISTS summaries (
+    link_id TEXT PRIMARY KEY,
+    text TEXT NOT NULL,
+    model TEXT NOT NULL DEFAULT '',
+    created_at TEXT NOT NULL
+);
@@ -0,0 +1,10 @@
+-- Vectors of links' titles, descriptions, and summaries from an embedding
+-- model, for rl grep --semantic
+
+CREATE TABLE IF NOT EXISTS embeddings (
+    link_id TEXT PRIMARY KEY,
+    model TEXT NOT NULL,
+    text_hash TEXT NOT NULL,
+    vector BLOB NOT NULL,
+    created_at TEXT NOT NULL
+);
@@ -0,0 +1,7 @@
+-- Places in the unread queue of links moved by rl bump, rl demote, or J/K
+-- in the TUI; the rest follow by priority and age
+
+CREATE TABLE IF NOT EXISTS queue (
+    link_id TEXT PRIMARY KEY,
+    position INTEGER NOT NULL
+);
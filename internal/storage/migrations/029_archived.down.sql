@@ -0,0 +1 @@
+ALTER TABLE links DROP COLUMN archived_at;
@@ -0,0 +1,6 @@
+-- When a link was archived by rl stale or a cleanup policy. Archived links
+-- are out of the unread queue and the read list; an archived link that was
+-- read keeps its read_at, and one archived unread gets read_at set without
+-- a read_log entry, since it wasn't read.
+
+ALTER TABLE links ADD COLUMN archived_at TEXT;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_links_trashed_at;
+ALTER TABLE links DROP COLUMN trashed_at;
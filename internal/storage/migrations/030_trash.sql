@@ -0,0 +1,7 @@
+-- When a link was moved to the trash by rl rm. Trashed links are left out
+-- of listings, searches, and stats until restored, and deleted for good by
+-- rl trash empty or the cleanup policy delete_trashed_after_days.
+
+ALTER TABLE links ADD COLUMN trashed_at TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_links_trashed_at ON links(trashed_at);
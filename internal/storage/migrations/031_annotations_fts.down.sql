@@ -0,0 +1,3 @@
+DROP TRIGGER IF EXISTS annotations_ai;
+DROP TRIGGER IF EXISTS annotations_ad;
+DROP TABLE IF EXISTS annotations_fts;
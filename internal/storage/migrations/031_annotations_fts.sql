@@ -0,0 +1,19 @@
+-- A full-text index of annotations keyed by annotation id, so search can
+-- find links by what was noted on them while reading
+
+CREATE VIRTUAL TABLE IF NOT EXISTS annotations_fts USING fts5(
+    annotation_id UNINDEXED,
+    link_id UNINDEXED,
+    text
+);
+
+INSERT INTO annotations_fts(annotation_id, link_id, text)
+SELECT id, link_id, text FROM annotations;
+
+CREATE TRIGGER IF NOT EXISTS annotations_ai AFTER INSERT ON annotations BEGIN
+    INSERT INTO annotations_fts(annotation_id, link_id, text) VALUES (new.id, new.link_id, new.text);
+END;
+
+CREATE TRIGGER IF NOT EXISTS annotations_ad AFTER DELETE ON annotations BEGIN
+    DELETE FROM annotations_fts WHERE annotation_id = old.id;
+END;
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS queue (
+    link_id TEXT PRIMARY KEY,
+    position INTEGER NOT NULL
+);
+
+INSERT OR REPLACE INTO queue (link_id, position)
+SELECT id, queue_position FROM links WHERE queue_position IS NOT NULL;
+
+DROP INDEX IF EXISTS idx_links_queue;
+CREATE INDEX IF NOT EXISTS idx_links_queue ON links(read_at, (CASE WHEN read_at IS NULL THEN priority ELSE 0 END) DESC, created_at DESC);
+
+ALTER TABLE links DROP COLUMN queue_position;
@@ -0,0 +1,19 @@
+-- Queue places move onto links so the default order needs no join and
+-- idx_links_queue can serve it again. A read link's place is ignored, as
+-- it was through the join; marking it unread clears it.
+
+ALTER TABLE links ADD COLUMN queue_position INTEGER;
+
+UPDATE links SET queue_position = (SELECT position FROM queue WHERE queue.link_id = links.id);
+
+DROP TABLE IF EXISTS queue;
+
+-- Its expressions must match orderBy's exactly for SQLite to use it
+DROP INDEX IF EXISTS idx_links_queue;
+CREATE INDEX IF NOT EXISTS idx_links_queue ON links(
+    read_at,
+    ((CASE WHEN read_at IS NULL THEN queue_position END) IS NULL),
+    (CASE WHEN read_at IS NULL THEN queue_position END),
+    (CASE WHEN read_at IS NULL THEN priority ELSE 0 END) DESC,
+    created_at DESC
+);
@@ -0,0 +1,54 @@
+-- Notes moved by the up migration, dated when their link was saved, go
+-- back to the note column; notes added since stay annotations
+
+ALTER TABLE links ADD COLUMN note TEXT;
+
+UPDATE links SET note = (
+    SELECT text FROM annotations
+    WHERE annotations.link_id = links.id AND annotations.created_at = links.created_at
+    ORDER BY id LIMIT 1
+);
+DELETE FROM annotations WHERE id IN (
+    SELECT MIN(annotations.id) FROM annotations
+    JOIN links ON links.id = annotations.link_id AND links.created_at = annotations.created_at
+    GROUP BY annotations.link_id
+);
+
+DROP TRIGGER IF EXISTS links_fts_ai;
+DROP TRIGGER IF EXISTS links_fts_ad;
+DROP TRIGGER IF EXISTS links_fts_au;
+DROP TABLE IF EXISTS links_fts;
+
+CREATE VIRTUAL TABLE links_fts USING fts5(
+    id UNINDEXED,
+    url,
+    title,
+    note,
+    tags,
+    description
+);
+
+INSERT INTO links_fts(id, url, title, note, tags, description)
+SELECT id, url, COALESCE(title, ''), COALESCE(note, ''), COALESCE(tags, ''), COALESCE(description, '')
+FROM links;
+
+CREATE TRIGGER links_fts_ai AFTER INSERT ON links BEGIN
+    INSERT INTO links_fts(id, url, title, note, tags, description)
+    VALUES (new.id, new.url, COALESCE(new.title, ''), COALESCE(new.note, ''), COALESCE(new.tags, ''), COALESCE(new.description, ''));
+END;
+
+CREATE TRIGGER links_fts_ad AFTER DELETE ON links BEGIN
+    DELETE FROM links_fts WHERE id = old.id;
+END;
+
+CREATE TRIGGER links_fts_au AFTER UPDATE ON links BEGIN
+    DELETE FROM links_fts WHERE id = old.id;
+    INSERT INTO links_fts(id, url, title, note, tags, description)
+    VALUES (new.id, new.url, COALESCE(new.title, ''), COALESCE(new.note, ''), COALESCE(new.tags, ''), COALESCE(new.description, ''));
+END;
+
+CREATE TRIGGER IF NOT EXISTS link_history_note AFTER UPDATE OF note ON links
+WHEN coalesce(old.note, '') IS NOT coalesce(new.note, '') BEGIN
+    INSERT INTO link_history(link_id, field, old_value, new_value, changed_at)
+    VALUES (new.id, 'note', coalesce(old.note, ''), coalesce(new.note, ''), strftime('%Y-%m-%dT%H:%M:%SZ', 'now'));
+END;
@@ -0,0 +1,44 @@
+-- A link's single note becomes its first annotation, dated when the link
+-- was saved, and the note column goes, so annotations are the only notes
+
+INSERT INTO annotations (link_id, text, created_at)
+SELECT id, note, created_at FROM links WHERE COALESCE(note, '') <> '';
+
+-- Earlier values of the note went with it
+DROP TRIGGER IF EXISTS link_history_note;
+DELETE FROM link_history WHERE field = 'note';
+
+-- The link index loses its note column; annotations_fts indexes notes
+DROP TRIGGER IF EXISTS links_fts_ai;
+DROP TRIGGER IF EXISTS links_fts_ad;
+DROP TRIGGER IF EXISTS links_fts_au;
+DROP TABLE IF EXISTS links_fts;
+
+CREATE VIRTUAL TABLE links_fts USING fts5(
+    id UNINDEXED,
+    url,
+    title,
+    tags,
+    description
+);
+
+INSERT INTO links_fts(id, url, title, tags, description)
+SELECT id, url, COALESCE(title, ''), COALESCE(tags, ''), COALESCE(description, '')
+FROM links;
+
+CREATE TRIGGER links_fts_ai AFTER INSERT ON links BEGIN
+    INSERT INTO links_fts(id, url, title, tags, description)
+    VALUES (new.id, new.url, COALESCE(new.title, ''), COALESCE(new.tags, ''), COALESCE(new.description, ''));
+END;
+
+CREATE TRIGGER links_fts_ad AFTER DELETE ON links BEGIN
+    DELETE FROM links_fts WHERE id = old.id;
+END;
+
+CREATE TRIGGER links_fts_au AFTER UPDATE ON links BEGIN
+    DELETE FROM links_fts WHERE id = old.id;
+    INSERT INTO links_fts(id, url, title, tags, description)
+    VALUES (new.id, new.url, COALESCE(new.title, ''), COALESCE(new.tags, ''), COALESCE(new.description, ''));
+END;
+
+ALTER TABLE links DROP COLUMN note;
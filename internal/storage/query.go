@@ -0,0 +1,246 @@
+package storage
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// searchColumns are the link fields a search term can be limited to with a
+// "field:" prefix.
+var searchColumns = []string{"url", "title", "tags", "description"}
+
+// Snippet markers wrap matched terms in snippets returned by SQLite; they
+// are control characters so they can't clash with link text.
+const (
+	snippetStart = "\x02"
+	snippetEnd   = "\x03"
+)
+
+// snippetTokens is the number of tokens around a match in a snippet.
+const snippetTokens = 12
+
+// linkHits selects each links_fts match with its bm25 rank (lower is
+// better) and a snippet of the best field. Title matches weigh most; the
+// unindexed id column gets no weight. Fields are tried in the order
+// title, description, tags, url, and the first containing a highlighted
+// term is reported.
+var linkHits = func() string {
+	columns := []struct {
+		index int
+		name  string
+	}{{2, "title"}, {4, "description"}, {3, "tags"}, {1, "url"}}
+	var field, index strings.Builder
+	field.WriteString("CASE")
+	index.WriteString("CASE")
+	for _, c := range columns {
+		cond := fmt.Sprintf(" WHEN instr(highlight(links_fts, %d, X'02', X'03'), X'02') > 0", c.index)
+		fmt.Fprintf(&field, "%s THEN '%s'", cond, c.name)
+		fmt.Fprintf(&index, "%s THEN %d", cond, c.index)
+	}
+	field.WriteString(" ELSE 'title' END")
+	index.WriteString(" ELSE 2 END")
+	return fmt.Sprintf(`SELECT id AS link_id, bm25(links_fts, 0, 1, 10, 5, 2) AS score, %s AS field,
+		snippet(links_fts, %s, X'02', X'03', '…', %d) AS snippet
+		FROM links_fts WHERE links_fts MATCH ?`, field.String(), index.String(), snippetTokens)
+}()
+
+// contentHits selects each content_fts match with its rank and snippet.
+var contentHits = fmt.Sprintf(`SELECT link_id, bm25(content_fts, 0, 1) AS score, 'content' AS field,
+	snippet(content_fts, 1, X'02', X'03', '…', %d) AS snippet
+	FROM content_fts WHERE content_fts MATCH ?`, snippetTokens)
+
+// quoteHits selects each quotes_fts match with its rank and snippet.
+// Highlighted passages were picked out by the reader, so they weigh like
+// notes.
+var quoteHits = fmt.Sprintf(`SELECT link_id, bm25(quotes_fts, 0, 0, 5) AS score, 'quote' AS field,
+	snippet(quotes_fts, 2, X'02', X'03', '…', %d) AS snippet
+	FROM quotes_fts WHERE quotes_fts MATCH ?`, snippetTokens)
+
+// annotationHits selects each annotations_fts match with its rank and
+// snippet, reported as a note match: annotations are a link's notes.
+var annotationHits = fmt.Sprintf(`SELECT link_id, bm25(annotations_fts, 0, 0, 5) AS score, 'note' AS field,
+	snippet(annotations_fts, 2, X'02', X'03', '…', %d) AS snippet
+	FROM annotations_fts WHERE annotations_fts MATCH ?`, snippetTokens)
+
+// parseSnippet strips the highlight markers from an SQLite snippet,
+// returning the text and the byte offsets of the highlighted terms.
+func parseSnippet(s string) (string, [][2]int) {
+	var b strings.Builder
+	var highlights [][2]int
+	start := -1
+	for _, r := range s {
+		switch string(r) {
+		case snippetStart:
+			start = b.Len()
+		case snippetEnd:
+			if start >= 0 && b.Len() > start {
+				highlights = append(highlights, [2]int{start, b.Len()})
+			}
+			start = -1
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String(), highlights
+}
+
+// ftsQuery translates a user search query into an FTS5 MATCH expression.
+// Words and "quoted phrases" must all match unless joined by OR; AND and NOT
+// combine terms, parentheses group them, a trailing * matches a prefix, and
+// field:term limits a term to one field. Everything else is quoted, so user
+// input can't produce an FTS syntax error. fields reports whether any term
+// is limited to a field.
+func ftsQuery(input string) (query string, fields bool, err error) {
+	tokens := tokenizeQuery(input)
+	if len(tokens) == 0 {
+		return "", false, fmt.Errorf("empty search query")
+	}
+
+	var parts []string
+	expectTerm := true // at the start, or after an operator or "("
+	depth := 0
+	last := ""
+	for _, tok := range tokens {
+		switch tok.kind {
+		case tokenOperator:
+			if expectTerm {
+				return "", false, fmt.Errorf("%s needs a search term before it", tok.text)
+			}
+			parts = append(parts, tok.text)
+			expectTerm = true
+		case tokenOpen:
+			if !expectTerm {
+				parts = append(parts, "AND")
+			}
+			parts = append(parts, "(")
+			depth++
+			expectTerm = true
+		case tokenClose:
+			if depth == 0 {
+				return "", false, fmt.Errorf("unbalanced parenthesis")
+			}
+			if expectTerm {
+				return "", false, fmt.Errorf("empty parentheses or missing search term after %s", last)
+			}
+			parts = append(parts, ")")
+			depth--
+		default:
+			if !expectTerm {
+				parts = append(parts, "AND")
+			}
+			parts = append(parts, tok.fts())
+			fields = fields || tok.column != ""
+			expectTerm = false
+		}
+		last = tok.text
+	}
+	if expectTerm {
+		return "", false, fmt.Errorf("%s needs a search term after it", last)
+	}
+	if depth > 0 {
+		return "", false, fmt.Errorf("unbalanced parenthesis")
+	}
+	return strings.Join(parts, " "), fields, nil
+}
+
+type tokenKind int
+
+const (
+	tokenTerm tokenKind = iota
+	tokenPhrase
+	tokenOperator
+	tokenOpen
+	tokenClose
+)
+
+type queryToken struct {
+	kind   tokenKind
+	text   string
+	column string
+	prefix bool
+}
+
+// fts renders a term or phrase as a quoted FTS5 string.
+func (t queryToken) fts() string {
+	s := `"` + strings.ReplaceAll(t.text, `"`, `""`) + `"`
+	if t.prefix {
+		s += "*"
+	}
+	if t.column != "" {
+		s = t.column + ":" + s
+	}
+	return s
+}
+
+// tokenizeQuery splits input into terms, phrases, operators, and
+// parentheses. An unterminated quote runs to the end of the input.
+func tokenizeQuery(input string) []queryToken {
+	var tokens []queryToken
+	runes := []rune(input)
+	column := "" // set by field: directly before a quote
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, queryToken{kind: tokenOpen, text: "("})
+			i++
+		case r == ')':
+			tokens = append(tokens, queryToken{kind: tokenClose, text: ")"})
+			i++
+		case r == '"':
+			end := i + 1
+			for end < len(runes) && runes[end] != '"' {
+				end++
+			}
+			if phrase := strings.TrimSpace(string(runes[i+1 : end])); phrase != "" {
+				tokens = append(tokens, queryToken{kind: tokenPhrase, text: phrase, column: column})
+			}
+			column = ""
+			i = end + 1
+		default:
+			end := i
+			for end < len(runes) && !unicode.IsSpace(runes[end]) && !strings.ContainsRune(`()"`, runes[end]) {
+				end++
+			}
+			word := string(runes[i:end])
+			i = end
+			if c, ok := searchColumn(strings.TrimSuffix(word, ":")); ok && strings.HasSuffix(word, ":") &&
+				i < len(runes) && runes[i] == '"' {
+				column = c
+				continue
+			}
+			tokens = append(tokens, wordToken(word))
+		}
+	}
+	return tokens
+}
+
+func wordToken(word string) queryToken {
+	switch word {
+	case "AND", "OR", "NOT":
+		return queryToken{kind: tokenOperator, text: word}
+	}
+	tok := queryToken{kind: tokenTerm, text: word}
+	if name, rest, ok := strings.Cut(word, ":"); ok && rest != "" {
+		if column, ok := searchColumn(name); ok {
+			tok.column, tok.text = column, rest
+		}
+	}
+	if trimmed := strings.TrimRight(tok.text, "*"); trimmed != "" && trimmed != tok.text {
+		tok.text, tok.prefix = trimmed, true
+	}
+	return tok
+}
+
+// searchColumn returns the field called name, ignoring case.
+func searchColumn(name string) (string, bool) {
+	for _, c := range searchColumns {
+		if strings.EqualFold(name, c) {
+			return c, true
+		}
+	}
+	return "", false
+}
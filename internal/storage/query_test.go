@@ -0,0 +1,58 @@
+package storage
+
+import "testing"
+
+func TestFTSQuery(t *testing.T) {
+	tests := []struct {
+		input  string
+		want   string
+		fields bool
+	}{
+		{`go rust`, `"go" AND "rust"`, false},
+		{`"event loop"`, `"event loop"`, false},
+		{`go OR rust`, `"go" OR "rust"`, false},
+		{`go NOT tokio`, `"go" NOT "tokio"`, false},
+		{`go or not`, `"go" AND "or" AND "not"`, false},
+		{`async (rust OR go)`, `"async" AND ( "rust" OR "go" )`, false},
+		{`kube*`, `"kube"*`, false},
+		{`title:go`, `title:"go"`, true},
+		{`Title:"event loop"`, `title:"event loop"`, true},
+		{`author:me`, `"author:me"`, false},
+		{`"unterminated phrase`, `"unterminated phrase"`, false},
+		{`say "a""b"`, `"say" AND "a" AND "b"`, false},
+		{`c++ -x`, `"c++" AND "-x"`, false},
+		{`it's`, `"it's"`, false},
+	}
+	for _, tt := range tests {
+		got, fields, err := ftsQuery(tt.input)
+		if err != nil {
+			t.Errorf("ftsQuery(%q) failed: %v", tt.input, err)
+			continue
+		}
+		if got != tt.want || fields != tt.fields {
+			t.Errorf("ftsQuery(%q) = %q, %v; want %q, %v", tt.input, got, fields, tt.want, tt.fields)
+		}
+	}
+}
+
+func TestFTSQueryInvalid(t *testing.T) {
+	for _, input := range []string{``, `  `, `""`, `AND go`, `go OR`, `NOT`, `(go`, `go)`, `()`, `go (OR rust)`} {
+		if got, _, err := ftsQuery(input); err == nil {
+			t.Errorf("ftsQuery(%q) = %q, want an error", input, got)
+		}
+	}
+}
+
+func TestParseSnippet(t *testing.T) {
+	text, highlights := parseSnippet("…the \x02événement\x03 loop and \x02go\x03")
+	if text != "…the événement loop and go" {
+		t.Errorf("Unexpected snippet text %q", text)
+	}
+	var got []string
+	for _, h := range highlights {
+		got = append(got, text[h[0]:h[1]])
+	}
+	if len(got) != 2 || got[0] != "événement" || got[1] != "go" {
+		t.Errorf("Unexpected highlights %q", got)
+	}
+}
@@ -3,23 +3,48 @@ package storage
 import (
 	"context"
 	"database/sql"
+	"encoding/binary"
+	"errors"
 	"fmt"
+	"math"
 	"os"
 	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/bunchhieng/rl/internal/model"
 	"github.com/jmoiron/sqlx"
-	_ "modernc.org/sqlite"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
 )
 
 // SQLiteStorage implements Storage using SQLite.
 type SQLiteStorage struct {
-	db *sqlx.DB
+	db      *busyDB
+	timeout time.Duration
+	// newID makes the ID of a new link saved at the given time.
+	newID func(time.Time) string
 }
 
-// NewSQLiteStorage creates a new SQLite storage instance.
+// NewSQLiteStorage creates a new SQLite storage instance, applying any
+// pending migrations.
 func NewSQLiteStorage(dbPath string) (*SQLiteStorage, error) {
+	storage, err := OpenSQLiteStorage(dbPath)
+	if err != nil {
+		return nil, err
+	}
+	if err := runMigrations(context.Background(), storage.db.DB.DB); err != nil {
+		storage.Close()
+		return nil, fmt.Errorf("run migrations: %w", err)
+	}
+	return storage, nil
+}
+
+// OpenSQLiteStorage opens the database as it is, without applying
+// migrations, for inspecting or repairing its schema.
+func OpenSQLiteStorage(dbPath string) (*SQLiteStorage, error) {
 	if dbPath != ":memory:" {
 		dir := filepath.Dir(dbPath)
 		if err := os.MkdirAll(dir, 0755); err != nil {
@@ -31,9 +56,29 @@ func NewSQLiteStorage(dbPath string) (*SQLiteStorage, error) {
 	if dbPath == ":memory:" {
 		dsn = dbPath + "?_pragma=journal_mode(DELETE)&_pragma=synchronous(NORMAL)&_pragma=foreign_keys(ON)"
 	} else {
-		dsn = dbPath + "?_pragma=journal_mode(WAL)&_pragma=synchronous(NORMAL)&_pragma=foreign_keys(ON)&_pragma=busy_timeout(5000)"
+		// WAL lets readers, like an open TUI, carry on while another process
+		// writes. SQLite waits out brief locks itself; longer ones come back
+		// to busyDB, which says so and retries. Transactions take the write
+		// lock when they begin rather than on their first write, which could
+		// fail partway through.
+		dsn = dbPath + "?_pragma=journal_mode(WAL)&_pragma=synchronous(NORMAL)&_pragma=foreign_keys(ON)&_pragma=busy_timeout(1000)&_txlock=immediate"
 	}
+	return openDSN(dsn, dbPath == ":memory:")
+}
+
+// OpenSQLiteStorageReadOnly opens an existing database so that nothing,
+// not even migrations, can write to it; SQLite refuses any write.
+func OpenSQLiteStorageReadOnly(dbPath string) (*SQLiteStorage, error) {
+	if _, err := os.Stat(dbPath); errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("open database: no database at %s", dbPath)
+	} else if err != nil {
+		return nil, fmt.Errorf("open database: %w", err)
+	}
+	// The journal mode is left as it is: changing it would be a write
+	return openDSN("file:"+dbPath+"?mode=ro&_pragma=foreign_keys(ON)&_pragma=busy_timeout(1000)", false)
+}
 
+func openDSN(dsn string, memory bool) (*SQLiteStorage, error) {
 	db, err := sqlx.Open("sqlite", dsn)
 	if err != nil {
 		return nil, fmt.Errorf("open database: %w", err)
@@ -44,37 +89,94 @@ func NewSQLiteStorage(dbPath string) (*SQLiteStorage, error) {
 		return nil, fmt.Errorf("ping database: %w", err)
 	}
 
-	storage := &SQLiteStorage{db: db}
-	ctx := context.Background()
-	if err := runMigrations(ctx, db.DB); err != nil {
-		db.Close()
-		return nil, fmt.Errorf("run migrations: %w", err)
+	if memory {
+		// Every connection to :memory: opens a separate, empty database;
+		// keep one so all queries see the migrated schema and data
+		db.SetMaxOpenConns(1)
 	}
 
+	storage := &SQLiteStorage{db: &busyDB{DB: db, wait: defaultBusyWait}}
+	storage.SetIDScheme(model.IDSchemeRandom, 0)
 	return storage, nil
 }
 
+// SetTimeout limits how long each operation may take, after which it is
+// cancelled and returns context.DeadlineExceeded. Zero means no limit.
+func (s *SQLiteStorage) SetTimeout(d time.Duration) {
+	s.timeout = d
+}
+
+// SetIDScheme sets how new links' IDs are made: model.IDSchemeRandom IDs
+// of the given length (see model.GenerateID; 0 means the default), or
+// model.IDSchemeULID IDs, which sort by when their link was saved.
+// Existing links keep their IDs.
+func (s *SQLiteStorage) SetIDScheme(scheme string, length int) {
+	if scheme == model.IDSchemeULID {
+		s.newID = model.GenerateULID
+		return
+	}
+	s.newID = func(time.Time) string { return model.GenerateID(length) }
+}
+
+// withTimeout derives the context for one operation from ctx.
+func (s *SQLiteStorage) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if s.timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, s.timeout)
+}
+
+// linkColumns lists the links table columns scanned into linkRow.
+const linkColumns = "id, url, title, tags, created_at, read_at, priority, snoozed_until, word_count, reading_time, domain, http_status, checked_at, description, media_type, author, archived_at, trashed_at"
+
+// linkPlaceholders holds one bind parameter per entry in linkColumns.
+var linkPlaceholders = strings.TrimSuffix(strings.Repeat("?, ", strings.Count(linkColumns, ",")+1), ", ")
+
 type linkRow struct {
 	ID        string         `db:"id"`
 	URL       string         `db:"url"`
 	Title     sql.NullString `db:"title"`
-	Note      sql.NullString `db:"note"`
 	Tags      sql.NullString `db:"tags"`
 	CreatedAt string         `db:"created_at"`
 	ReadAt    sql.NullString `db:"read_at"`
+	Priority  int            `db:"priority"`
+	Snoozed   sql.NullString `db:"snoozed_until"`
+	WordCount int            `db:"word_count"`
+	Reading   int            `db:"reading_time"`
+	Domain    string         `db:"domain"`
+	Status    int            `db:"http_status"`
+	CheckedAt sql.NullString `db:"checked_at"`
+	Desc      string         `db:"description"`
+	MediaType string         `db:"media_type"`
+	Author    string         `db:"author"`
+	Archived  sql.NullString `db:"archived_at"`
+	Trashed   sql.NullString `db:"trashed_at"`
+}
+
+// searchRow is a link row with the field and snippet a search matched.
+type searchRow struct {
+	linkRow
+	Field   string  `db:"match_field"`
+	Snippet string  `db:"match_snippet"`
+	Score   float64 `db:"score"`
 }
 
 func (r *linkRow) toLink() *model.Link {
 	link := &model.Link{
-		ID:  r.ID,
-		URL: r.URL,
+		ID:             r.ID,
+		URL:            r.URL,
+		Priority:       model.Priority(r.Priority),
+		WordCount:      r.WordCount,
+		ReadingSeconds: r.Reading,
+		Domain:         r.Domain,
+		HTTPStatus:     r.Status,
+		Description:    r.Desc,
+		MediaType:      r.MediaType,
+		Author:         r.Author,
 	}
 	if r.Title.Valid {
 		link.Title = r.Title.String
 	}
-	if r.Note.Valid {
-		link.Note = r.Note.String
-	}
 	if r.Tags.Valid {
 		link.Tags = r.Tags.String
 	}
@@ -83,118 +185,523 @@ func (r *linkRow) toLink() *model.Link {
 		readAt := parseSQLiteTime(r.ReadAt.String)
 		link.ReadAt = &readAt
 	}
+	if r.Snoozed.Valid && r.Snoozed.String != "" {
+		snoozedUntil := parseSQLiteTime(r.Snoozed.String)
+		link.SnoozedUntil = &snoozedUntil
+	}
+	if r.CheckedAt.Valid && r.CheckedAt.String != "" {
+		checkedAt := parseSQLiteTime(r.CheckedAt.String)
+		link.CheckedAt = &checkedAt
+	}
+	if r.Archived.Valid && r.Archived.String != "" {
+		archivedAt := parseSQLiteTime(r.Archived.String)
+		link.ArchivedAt = &archivedAt
+	}
+	if r.Trashed.Valid && r.Trashed.String != "" {
+		trashedAt := parseSQLiteTime(r.Trashed.String)
+		link.TrashedAt = &trashedAt
+	}
 	return link
 }
 
+// selectByURL finds a link by its URL or by one of its URL aliases.
+const selectByURL = "SELECT " + linkColumns + " FROM links WHERE url = ? OR id IN (SELECT link_id FROM url_aliases WHERE url = ?)"
+
+// FindByURL retrieves a link by its URL or by one of its URL aliases.
+func (s *SQLiteStorage) FindByURL(ctx context.Context, url string) (*model.Link, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	var row linkRow
+	err := s.db.GetContext(ctx, &row, selectByURL, url, url)
+	if err == sql.ErrNoRows {
+		return nil, model.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("find link by URL: %w", err)
+	}
+	return row.toLink(), nil
+}
+
+// AddURLAlias records an alternate URL (such as a shortened link) that
+// resolves to the given link.
+func (s *SQLiteStorage) AddURLAlias(ctx context.Context, id, url string) error {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	if !model.ValidateShortID(id) {
+		return fmt.Errorf("invalid ID format")
+	}
+	_, err := s.db.ExecContext(ctx,
+		"INSERT OR REPLACE INTO url_aliases (url, link_id) VALUES (?, ?)", url, id)
+	if err != nil {
+		return fmt.Errorf("add URL alias: %w", err)
+	}
+	return nil
+}
+
+// SetURL changes a link's URL, keeping everything else about it. The old
+// URL becomes a URL alias, so adding it again finds the link. A URL that
+// is saved as, or an alias of, another link is refused with
+// model.ErrDuplicate.
+func (s *SQLiteStorage) SetURL(ctx context.Context, id, url string) (*model.Link, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	if !model.ValidateShortID(id) {
+		return nil, fmt.Errorf("invalid ID format")
+	}
+	if err := (&model.Link{URL: url}).Validate(); err != nil {
+		return nil, err
+	}
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin set URL: %w", err)
+	}
+	defer tx.Rollback()
+
+	var row linkRow
+	err = tx.GetContext(ctx, &row, "SELECT "+linkColumns+" FROM links WHERE id = ?", id)
+	if err == sql.ErrNoRows {
+		return nil, model.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get link: %w", err)
+	}
+	link := row.toLink()
+	if link.URL == url {
+		return link, nil
+	}
+
+	var other linkRow
+	err = tx.GetContext(ctx, &other, selectByURL, url, url)
+	if err == nil && other.ID != id {
+		return nil, fmt.Errorf("%w: %s is saved as link %s", model.ErrDuplicate, url, other.ID)
+	}
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("check existing link: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, "UPDATE links SET url = ?, domain = ? WHERE id = ?",
+		url, model.Domain(url), id); err != nil {
+		return nil, fmt.Errorf("set URL: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM url_aliases WHERE url = ?", url); err != nil {
+		return nil, fmt.Errorf("set URL: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx,
+		"INSERT OR REPLACE INTO url_aliases (url, link_id) VALUES (?, ?)", link.URL, id); err != nil {
+		return nil, fmt.Errorf("set URL: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit set URL: %w", err)
+	}
+	link.URL, link.Domain = url, model.Domain(url)
+	return link, nil
+}
+
 // Add creates a new link or updates an existing one.
 func (s *SQLiteStorage) Add(ctx context.Context, link *model.Link) (*model.Link, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
 	if err := link.Validate(); err != nil {
 		return nil, err
 	}
 
-	// Check if link already exists
+	// Check if link already exists, either by URL or by a recorded alias
 	var existing linkRow
-	err := s.db.GetContext(ctx, &existing,
-		"SELECT id, url, title, note, tags, created_at, read_at FROM links WHERE url = ?", link.URL)
+	err := s.db.GetContext(ctx, &existing, selectByURL, link.URL, link.URL)
 
 	if err == nil {
 		// Link exists - update it
-		existingLink := existing.toLink()
+		merged := existing.toLink()
 
-		// Merge: preserve existing title/note if present, merge tags
-		newTitle := existingLink.Title
+		// Merge: a title given replaces the saved one, tags are combined
 		if link.Title != "" {
-			newTitle = link.Title
-		}
-		newNote := existingLink.Note
-		if link.Note != "" {
-			newNote = link.Note
+			merged.Title = link.Title
 		}
-
-		// Merge tags
 		if link.Tags != "" {
-			mergeLink := &model.Link{Tags: link.Tags}
-			existingLink.MergeTags(mergeLink)
+			merged.MergeTags(&model.Link{Tags: link.Tags})
 		}
-		newTags := existingLink.Tags
-
-		// Use DELETE + INSERT to avoid driver issues with UPDATE
-		_, err = s.db.ExecContext(ctx, "DELETE FROM links WHERE url = ?", link.URL)
-		if err != nil {
-			return nil, fmt.Errorf("delete existing link: %w", err)
+		// Normal is the zero value, so it reads as not given here; the
+		// adder sets an explicit normal with SetPriority
+		if link.Priority != model.PriorityNormal {
+			merged.Priority = link.Priority
 		}
-
-		// Re-insert with merged data, preserving original created_at
-		existingCreatedAt := existingLink.CreatedAt.Format(time.RFC3339)
-		if existingLink.CreatedAt.IsZero() {
-			existingCreatedAt = time.Now().Format(time.RFC3339)
+		if link.WordCount > 0 {
+			merged.WordCount = link.WordCount
 		}
-
-		var readAt sql.NullString
-		if existingLink.ReadAt != nil {
-			readAt.String = existingLink.ReadAt.Format(time.RFC3339)
-			readAt.Valid = true
+		if link.ReadingSeconds > 0 {
+			merged.ReadingSeconds = link.ReadingSeconds
+		}
+		if link.Description != "" {
+			merged.Description = link.Description
+		}
+		if link.MediaType != "" {
+			merged.MediaType = link.MediaType
+		}
+		if link.Author != "" {
+			merged.Author = link.Author
 		}
 
-		_, err = s.db.ExecContext(ctx,
-			"INSERT INTO links (id, url, title, note, tags, created_at, read_at) VALUES (?, ?, ?, ?, ?, ?, ?)",
-			existingLink.ID, link.URL, newTitle, newNote, newTags, existingCreatedAt, readAt)
-		if err != nil {
-			return nil, fmt.Errorf("re-insert updated link: %w", err)
+		// Updating in place, rather than deleting and re-inserting, keeps
+		// the link's history
+		if _, err := updateLink(ctx, s.db, merged); err != nil {
+			return nil, fmt.Errorf("update existing link: %w", err)
+		}
+		// Saving a trashed link again takes it out of the trash
+		if merged.TrashedAt != nil {
+			if _, err := s.db.ExecContext(ctx, "UPDATE links SET trashed_at = NULL WHERE id = ?", merged.ID); err != nil {
+				return nil, fmt.Errorf("restore existing link: %w", err)
+			}
+		}
+		if _, err := addAnnotations(ctx, s.db, merged.ID, link.Annotations); err != nil {
+			return nil, err
 		}
 
 		// Get the updated link by ID (preserved from existing link)
-		return s.Get(ctx, existingLink.ID)
+		return s.Get(ctx, merged.ID)
 	} else if err != sql.ErrNoRows {
 		return nil, fmt.Errorf("check existing link: %w", err)
 	}
 
-	// err == sql.ErrNoRows, so link doesn't exist - insert new one
-
 	// Link doesn't exist - insert new one
-	// Generate short ID for new link
-	link.ID = model.GenerateShortID()
+	if link.CreatedAt.IsZero() {
+		link.CreatedAt = time.Now()
+	}
 
-	var readAt sql.NullString
-	if link.ReadAt != nil {
-		readAt.String = link.ReadAt.Format(time.RFC3339)
-		readAt.Valid = true
+	if err := insertNewLink(ctx, s.db, link, s.newID); err != nil {
+		return nil, fmt.Errorf("insert link: %w", err)
+	}
+	if _, err := addAnnotations(ctx, s.db, link.ID, link.Annotations); err != nil {
+		return nil, err
 	}
 
-	createdAtStr := link.CreatedAt.Format(time.RFC3339)
-	if link.CreatedAt.IsZero() {
-		createdAtStr = time.Now().Format(time.RFC3339)
+	result := *link
+	result.Domain = model.Domain(link.URL)
+	result.CreatedAt = parseSQLiteTime(link.CreatedAt.UTC().Format(time.RFC3339))
+	return &result, nil
+}
+
+// maxIDAttempts is how many IDs insertNewLink tries before giving up.
+// Collisions are rare even for the shortest IDs, so running out means
+// something else is wrong.
+const maxIDAttempts = 10
+
+// insertNewLink gives link an ID made by newID and writes it, trying
+// another ID if one is already taken.
+func insertNewLink(ctx context.Context, db sqlx.ExecerContext, link *model.Link, newID func(time.Time) string) error {
+	for attempt := 1; ; attempt++ {
+		link.ID = newID(link.CreatedAt)
+		err := insertLink(ctx, db, link)
+		if !isDuplicateID(err) {
+			return err
+		}
+		if attempt == maxIDAttempts {
+			return fmt.Errorf("no unused ID after %d attempts: %w", attempt, err)
+		}
+	}
+}
+
+// isDuplicateID reports whether err is an insert refused because the
+// link's ID is already taken.
+func isDuplicateID(err error) bool {
+	var sqliteErr *sqlite.Error
+	return errors.As(err, &sqliteErr) && sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY
+}
+
+// insertLink writes a complete link row. A zero CreatedAt defaults to now.
+func insertLink(ctx context.Context, db sqlx.ExecerContext, link *model.Link) error {
+	createdAt := link.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = time.Now()
+	}
+	row := *link
+	row.CreatedAt = createdAt
+	_, err := db.ExecContext(ctx,
+		"INSERT INTO links ("+linkColumns+") VALUES ("+linkPlaceholders+")", linkValues(&row)...)
+	return err
+}
+
+// addAnnotations adds the notes given with a link to it, leaving out any
+// whose text it already has, so saving a link again with the same note
+// doesn't repeat it. Notes without a date are dated now. It returns how
+// many were added.
+func addAnnotations(ctx context.Context, db sqlx.ExecerContext, linkID string, annotations []*model.Annotation) (int, error) {
+	added := 0
+	for _, a := range annotations {
+		text := strings.TrimSpace(a.Text)
+		if text == "" {
+			continue
+		}
+		createdAt := a.CreatedAt
+		if createdAt.IsZero() {
+			createdAt = time.Now()
+		}
+		result, err := db.ExecContext(ctx, `
+			INSERT INTO annotations (link_id, text, created_at)
+			SELECT ?, ?, ? WHERE NOT EXISTS (SELECT 1 FROM annotations WHERE link_id = ? AND text = ?)`,
+			linkID, text, createdAt.UTC().Format(time.RFC3339), linkID, text)
+		if err != nil {
+			return added, fmt.Errorf("add annotation: %w", err)
+		}
+		if n, err := result.RowsAffected(); err == nil {
+			added += int(n)
+		}
+	}
+	return added, nil
+}
+
+// linkValues returns the values of linkColumns for link, as stored.
+func linkValues(link *model.Link) []interface{} {
+	return []interface{}{
+		link.ID, link.URL, link.Title, link.Tags,
+		link.CreatedAt.UTC().Format(time.RFC3339), formatNullTime(link.ReadAt),
+		int(link.Priority), formatNullTime(link.SnoozedUntil),
+		link.WordCount, link.ReadingSeconds, model.Domain(link.URL),
+		link.HTTPStatus, formatNullTime(link.CheckedAt), link.Description,
+		link.MediaType, link.Author, formatNullTime(link.ArchivedAt),
+		formatNullTime(link.TrashedAt),
+	}
+}
+
+// Update writes the editable fields of an existing link.
+func (s *SQLiteStorage) Update(ctx context.Context, link *model.Link) error {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	if !model.ValidateShortID(link.ID) {
+		return fmt.Errorf("invalid ID format")
+	}
+	if err := link.Validate(); err != nil {
+		return err
+	}
+	result, err := updateLink(ctx, s.db, link)
+	if err != nil {
+		return fmt.Errorf("update link: %w", err)
 	}
+	return checkRowsAffected(result, "update link")
+}
+
+// updateLink rewrites the stored fields of link other than its save time
+// and archive time. Making the link unread takes it out of the archive.
+func updateLink(ctx context.Context, db sqlx.ExecerContext, link *model.Link) (sql.Result, error) {
+	readAt := formatNullTime(link.ReadAt)
+	return db.ExecContext(ctx, `
+		UPDATE links SET url = ?, title = ?, tags = ?, read_at = ?,
+			priority = ?, snoozed_until = ?, word_count = ?, reading_time = ?, domain = ?,
+			description = ?, media_type = ?, author = ?,
+			archived_at = CASE WHEN ? IS NULL THEN NULL ELSE archived_at END
+		WHERE id = ?`,
+		link.URL, link.Title, link.Tags, readAt,
+		int(link.Priority), formatNullTime(link.SnoozedUntil), link.WordCount, link.ReadingSeconds,
+		model.Domain(link.URL), link.Description, link.MediaType, link.Author, readAt, link.ID)
+}
+
+// mergeMoves hand a duplicate's rows in other tables, and its place in
+// the queue, to the link it is merged into. Tables holding one row per
+// link keep the kept link's row; the duplicate's leftovers are deleted
+// with it.
+var mergeMoves = []string{
+	"UPDATE annotations SET link_id = ? WHERE link_id = ?",
+	"UPDATE annotations_fts SET link_id = ? WHERE link_id = ?",
+	"UPDATE quotes SET link_id = ? WHERE link_id = ?",
+	"UPDATE quotes_fts SET link_id = ? WHERE link_id = ?",
+	"UPDATE link_aliases SET link_id = ? WHERE link_id = ?",
+	"UPDATE url_aliases SET link_id = ? WHERE link_id = ?",
+	"UPDATE OR IGNORE read_log SET link_id = ? WHERE link_id = ?",
+	"UPDATE OR IGNORE link_content SET link_id = ? WHERE link_id = ?",
+	"UPDATE OR IGNORE fetch_state SET link_id = ? WHERE link_id = ?",
+	"UPDATE OR IGNORE attachments SET link_id = ? WHERE link_id = ?",
+	"UPDATE OR IGNORE summaries SET link_id = ? WHERE link_id = ?",
+	"UPDATE OR IGNORE embeddings SET link_id = ? WHERE link_id = ?",
+	"UPDATE links SET queue_position = COALESCE(queue_position, (SELECT queue_position FROM links WHERE id = ?2)) WHERE id = ?1",
+	"UPDATE OR IGNORE sync_links SET link_id = ? WHERE link_id = ?",
+}
 
-	_, err = s.db.ExecContext(ctx,
-		"INSERT INTO links (id, url, title, note, tags, created_at, read_at) VALUES (?, ?, ?, ?, ?, ?, ?)",
-		link.ID, link.URL, link.Title, link.Note, link.Tags, createdAtStr, readAt)
+// mergeLeftovers are the duplicate's rows mergeMoves couldn't move. A
+// sync_links row moves to the kept link unless it has its own for that
+// service; then the duplicate's stays, as it would if the link were
+// deleted, so syncing doesn't bring the duplicate back.
+var mergeLeftovers = []string{
+	"DELETE FROM read_log WHERE link_id = ?",
+	"DELETE FROM link_content WHERE link_id = ?",
+	"DELETE FROM fetch_state WHERE link_id = ?",
+	"DELETE FROM attachments WHERE link_id = ?",
+	"DELETE FROM summaries WHERE link_id = ?",
+	"DELETE FROM embeddings WHERE link_id = ?",
+	"DELETE FROM link_history WHERE link_id = ?",
+	"DELETE FROM links WHERE id = ?",
+}
+
+// History returns the edits to a link's title, tags, and URL,
+// oldest first.
+func (s *SQLiteStorage) History(ctx context.Context, id string) ([]*model.Edit, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	var rows []struct {
+		ID        int64  `db:"id"`
+		LinkID    string `db:"link_id"`
+		Field     string `db:"field"`
+		Old       string `db:"old_value"`
+		New       string `db:"new_value"`
+		ChangedAt string `db:"changed_at"`
+	}
+	err := s.db.SelectContext(ctx, &rows,
+		"SELECT id, link_id, field, old_value, new_value, changed_at FROM link_history WHERE link_id = ? ORDER BY id", id)
 	if err != nil {
-		return nil, fmt.Errorf("insert link: %w", err)
+		return nil, fmt.Errorf("list history: %w", err)
 	}
 
-	createdAtTime := parseSQLiteTime(createdAtStr)
-	result := &model.Link{
-		ID:        link.ID,
-		URL:       link.URL,
-		Title:     link.Title,
-		Note:      link.Note,
-		Tags:      link.Tags,
-		CreatedAt: createdAtTime,
-		ReadAt:    link.ReadAt,
+	edits := make([]*model.Edit, len(rows))
+	for i, r := range rows {
+		edits[i] = &model.Edit{ID: r.ID, LinkID: r.LinkID, Field: r.Field, Old: r.Old, New: r.New, ChangedAt: parseSQLiteTime(r.ChangedAt)}
 	}
+	return edits, nil
+}
 
-	return result, nil
+// RevertEdit sets the field the edit editID changed back to the value it
+// had before, which is itself recorded as an edit. Reverting a URL is
+// refused like SetURL refuses one already saved as another link.
+func (s *SQLiteStorage) RevertEdit(ctx context.Context, id string, editID int64) (*model.Link, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	var edit struct {
+		Field string `db:"field"`
+		Old   string `db:"old_value"`
+	}
+	err := s.db.GetContext(ctx, &edit,
+		"SELECT field, old_value FROM link_history WHERE id = ? AND link_id = ?", editID, id)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("link %s has no edit %d", id, editID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get edit: %w", err)
+	}
+
+	switch edit.Field {
+	case model.FieldURL:
+		return s.SetURL(ctx, id, edit.Old)
+	case model.FieldTitle, model.FieldTags:
+		// The column name comes from the switch, not the database
+		if _, err := s.db.ExecContext(ctx, "UPDATE links SET "+edit.Field+" = ? WHERE id = ?", edit.Old, id); err != nil {
+			return nil, fmt.Errorf("revert %s: %w", edit.Field, err)
+		}
+		return s.Get(ctx, id)
+	default:
+		return nil, fmt.Errorf("can't revert an edit to %s", edit.Field)
+	}
+}
+
+// Merge folds the links dupIDs into the link keepID (see
+// model.Link.MergeDuplicate) and deletes them, all in one transaction.
+// Their annotations, quotes, aliases, and read history move to the kept
+// link, as do saved text and attachments it lacks, and their URLs become
+// URL aliases of it. It returns the merged link.
+func (s *SQLiteStorage) Merge(ctx context.Context, keepID string, dupIDs []string) (*model.Link, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin merge: %w", err)
+	}
+	defer tx.Rollback()
+
+	get := func(id string) (*model.Link, error) {
+		if !model.ValidateShortID(id) {
+			return nil, fmt.Errorf("invalid ID format")
+		}
+		var row linkRow
+		err := tx.GetContext(ctx, &row, "SELECT "+linkColumns+" FROM links WHERE id = ?", id)
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("link %s: %w", id, model.ErrNotFound)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("get link: %w", err)
+		}
+		return row.toLink(), nil
+	}
+
+	keep, err := get(keepID)
+	if err != nil {
+		return nil, err
+	}
+	wasUnread := keep.ReadAt == nil
+	seen := map[string]bool{keepID: true}
+	dups := make([]*model.Link, len(dupIDs))
+	for i, id := range dupIDs {
+		if seen[id] {
+			return nil, fmt.Errorf("link %s is given twice", id)
+		}
+		seen[id] = true
+		if dups[i], err = get(id); err != nil {
+			return nil, err
+		}
+		keep.MergeDuplicate(dups[i])
+	}
+
+	for _, dup := range dups {
+		id := dup.ID
+		for _, query := range mergeMoves {
+			if _, err := tx.ExecContext(ctx, query, keepID, id); err != nil {
+				return nil, fmt.Errorf("merge link %s: %w", id, err)
+			}
+		}
+		for _, query := range mergeLeftovers {
+			if _, err := tx.ExecContext(ctx, query, id); err != nil {
+				return nil, fmt.Errorf("merge link %s: %w", id, err)
+			}
+		}
+		// Adding the duplicate's URL again finds the kept link
+		if _, err := tx.ExecContext(ctx,
+			"INSERT OR REPLACE INTO url_aliases (url, link_id) VALUES (?, ?)", dup.URL, keepID); err != nil {
+			return nil, fmt.Errorf("merge link %s: %w", id, err)
+		}
+	}
+
+	// Taking a duplicate's read time isn't a new read, and its read history
+	// moved over already, so undo the read_log entry the update adds
+	readAt := formatNullTime(keep.ReadAt)
+	logged := true
+	if wasUnread && readAt.Valid {
+		if err := tx.GetContext(ctx, &logged,
+			"SELECT EXISTS (SELECT 1 FROM read_log WHERE link_id = ? AND read_at = ?)", keepID, readAt); err != nil {
+			return nil, fmt.Errorf("update merged link: %w", err)
+		}
+	}
+	if _, err := updateLink(ctx, tx, keep); err != nil {
+		return nil, fmt.Errorf("update merged link: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, "UPDATE links SET created_at = ? WHERE id = ?",
+		keep.CreatedAt.UTC().Format(time.RFC3339), keepID); err != nil {
+		return nil, fmt.Errorf("update merged link: %w", err)
+	}
+	if !logged {
+		if _, err := tx.ExecContext(ctx, "DELETE FROM read_log WHERE link_id = ? AND read_at = ?", keepID, readAt); err != nil {
+			return nil, fmt.Errorf("update merged link: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit merge: %w", err)
+	}
+	return keep, nil
 }
 
 // Get retrieves a link by ID.
 func (s *SQLiteStorage) Get(ctx context.Context, id string) (*model.Link, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
 	if !model.ValidateShortID(id) {
 		return nil, fmt.Errorf("invalid ID format")
 	}
 	var row linkRow
 	err := s.db.GetContext(ctx, &row,
-		"SELECT id, url, title, note, tags, created_at, read_at FROM links WHERE id = ?", id)
+		"SELECT "+linkColumns+" FROM links WHERE id = ?", id)
 	if err == sql.ErrNoRows {
 		return nil, model.ErrNotFound
 	}
@@ -204,28 +711,79 @@ func (s *SQLiteStorage) Get(ctx context.Context, id string) (*model.Link, error)
 	return row.toLink(), nil
 }
 
-// List retrieves links with optional filters.
-func (s *SQLiteStorage) List(ctx context.Context, opts ListOptions) ([]*model.Link, error) {
-	query := "SELECT id, url, title, note, tags, created_at, read_at FROM links WHERE 1=1"
-	args := []interface{}{}
+// maxIDCandidates limits how many matches an ambiguous prefix reports.
+const maxIDCandidates = 5
 
-	switch opts.ReadStatus {
-	case ReadStatusUnread:
-		query += " AND read_at IS NULL"
-	case ReadStatusRead:
-		query += " AND read_at IS NOT NULL"
+// ResolveID expands a unique ID prefix (case-insensitive) to a full link ID.
+func (s *SQLiteStorage) ResolveID(ctx context.Context, prefix string) (string, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	isPrefix := model.ValidateIDPrefix(prefix)
+	if !isPrefix && !model.ValidateAlias(prefix) {
+		return "", fmt.Errorf("invalid ID format")
 	}
 
-	if opts.Tag != "" {
-		query += " AND tags LIKE ?"
-		args = append(args, "%"+opts.Tag+"%")
+	var ids []string
+	err := s.db.SelectContext(ctx, &ids,
+		"SELECT id FROM links WHERE id = ? COLLATE NOCASE", prefix)
+	if err != nil {
+		return "", fmt.Errorf("resolve ID: %w", err)
+	}
+	if len(ids) == 1 {
+		return ids[0], nil
+	}
+
+	// An alias wins over a prefix match so names stay stable as links are added
+	err = s.db.SelectContext(ctx, &ids,
+		"SELECT link_id FROM link_aliases WHERE name = ?", prefix)
+	if err != nil {
+		return "", fmt.Errorf("resolve ID: %w", err)
+	}
+	if len(ids) == 1 {
+		return ids[0], nil
+	}
+	if !isPrefix {
+		return "", model.ErrNotFound
+	}
+
+	// Prefix is alphanumeric, so it can't contain LIKE wildcards
+	err = s.db.SelectContext(ctx, &ids,
+		"SELECT id FROM links WHERE id LIKE ? ORDER BY id LIMIT ?", prefix+"%", maxIDCandidates+1)
+	if err != nil {
+		return "", fmt.Errorf("resolve ID: %w", err)
+	}
+	switch len(ids) {
+	case 0:
+		return "", model.ErrNotFound
+	case 1:
+		return ids[0], nil
+	default:
+		if len(ids) > maxIDCandidates {
+			ids = append(ids[:maxIDCandidates], "...")
+		}
+		return "", &model.AmbiguousIDError{Prefix: prefix, Candidates: ids}
 	}
+}
+
+// List retrieves links with optional filters.
+func (s *SQLiteStorage) List(ctx context.Context, opts ListOptions) ([]*model.Link, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
 
-	query += " ORDER BY created_at DESC"
+	where, args := opts.conditions()
+	query := "SELECT " + linkColumns + " FROM links WHERE 1=1" + where
 
-	if opts.Limit > 0 {
-		query += " LIMIT ?"
-		args = append(args, opts.Limit)
+	// High-priority unread links float to the top, low-priority ones sink
+	query += " ORDER BY " + orderBy(opts.Sort)
+
+	switch {
+	case opts.Limit > 0:
+		query += " LIMIT ? OFFSET ?"
+		args = append(args, opts.Limit, opts.Offset)
+	case opts.Offset > 0:
+		query += " LIMIT -1 OFFSET ?"
+		args = append(args, opts.Offset)
 	}
 
 	var rows []linkRow
@@ -242,141 +800,1212 @@ func (s *SQLiteStorage) List(ctx context.Context, opts ListOptions) ([]*model.Li
 	return links, nil
 }
 
-// Delete removes a link by ID.
-func (s *SQLiteStorage) Delete(ctx context.Context, id string) error {
-	if !model.ValidateShortID(id) {
-		return fmt.Errorf("invalid ID format")
-	}
-	result, err := s.db.ExecContext(ctx, "DELETE FROM links WHERE id = ?", id)
-	if err != nil {
-		return fmt.Errorf("delete link: %w", err)
+// Count returns how many links List would return, ignoring Limit and
+// Offset.
+func (s *SQLiteStorage) Count(ctx context.Context, opts ListOptions) (int, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	where, args := opts.conditions()
+	var n int
+	if err := s.db.GetContext(ctx, &n, "SELECT COUNT(*) FROM links WHERE 1=1"+where, args...); err != nil {
+		return 0, fmt.Errorf("count links: %w", err)
 	}
-	return checkRowsAffected(result, "delete link")
+	return n, nil
 }
 
-// MarkRead sets the read_at timestamp for a link.
-func (s *SQLiteStorage) MarkRead(ctx context.Context, id string) error {
-	if !model.ValidateShortID(id) {
-		return fmt.Errorf("invalid ID format")
+// notTrashed leaves links in the trash out of a query.
+const notTrashed = "links.trashed_at IS NULL"
+
+// conditions returns the AND clauses restricting a query to the links
+// opts selects.
+func (opts ListOptions) conditions() (string, []interface{}) {
+	where := ""
+	args := []interface{}{}
+
+	if opts.Trashed {
+		where += " AND trashed_at IS NOT NULL"
+	} else {
+		where += " AND " + notTrashed
 	}
-	result, err := s.db.ExecContext(ctx,
-		"UPDATE links SET read_at = datetime('now') WHERE id = ?", id)
-	if err != nil {
-		return fmt.Errorf("mark read: %w", err)
+
+	switch opts.ReadStatus {
+	case ReadStatusUnread:
+		where += " AND read_at IS NULL"
+	case ReadStatusRead:
+		where += " AND read_at IS NOT NULL AND archived_at IS NULL"
+	case ReadStatusArchived:
+		where += " AND archived_at IS NOT NULL"
 	}
-	return checkRowsAffected(result, "mark read")
-}
 
-// MarkUnread clears the read_at timestamp for a link.
-func (s *SQLiteStorage) MarkUnread(ctx context.Context, id string) error {
-	if !model.ValidateShortID(id) {
-		return fmt.Errorf("invalid ID format")
+	now := time.Now().UTC().Format(time.RFC3339)
+	if opts.Snoozed {
+		where += " AND snoozed_until > ?"
+		args = append(args, now)
+	} else if opts.ReadStatus == ReadStatusUnread {
+		// Snoozed links stay hidden from the unread queue until they wake up
+		where += " AND (snoozed_until IS NULL OR snoozed_until <= ?)"
+		args = append(args, now)
 	}
-	result, err := s.db.ExecContext(ctx,
-		"UPDATE links SET read_at = NULL WHERE id = ?", id)
-	if err != nil {
-		return fmt.Errorf("mark unread: %w", err)
+
+	if opts.Tag != "" {
+		where += " AND tags LIKE ?"
+		args = append(args, "%"+opts.Tag+"%")
 	}
-	return checkRowsAffected(result, "mark unread")
-}
 
-func checkRowsAffected(result sql.Result, action string) error {
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return fmt.Errorf("get rows affected: %w", err)
+	if opts.Domain != "" {
+		// Match the domain itself and any of its subdomains
+		domain := model.Domain("https://" + opts.Domain)
+		where += " AND (domain = ? OR domain LIKE ?)"
+		args = append(args, domain, "%."+domain)
 	}
-	if rowsAffected == 0 {
-		return model.ErrNotFound
+
+	if opts.Dead {
+		where += " AND checked_at IS NOT NULL AND (http_status = 0 OR http_status IN (404, 410))"
+	}
+
+	switch opts.Type {
+	case TypeArticle:
+		where += " AND media_type = ''"
+	case TypeVideo, TypeAudio:
+		where += " AND media_type = ?"
+		args = append(args, string(opts.Type))
+	}
+
+	if opts.MaxReadingTime > 0 {
+		where += " AND reading_time > 0 AND reading_time <= ?"
+		args = append(args, int(opts.MaxReadingTime/time.Second))
+	}
+
+	dates, dateArgs := opts.DateRange.conditions()
+	where += dates
+	args = append(args, dateArgs...)
+	return where, args
+}
+
+// conditions returns the AND clauses restricting a query to r. Timestamps
+// are compared through datetime() because read_at is stored in SQLite's
+// format while created_at is RFC 3339.
+func (r DateRange) conditions() (string, []interface{}) {
+	var where string
+	var args []interface{}
+	if !r.Since.IsZero() {
+		where += " AND datetime(created_at) >= datetime(?)"
+		args = append(args, r.Since.UTC().Format(time.RFC3339))
+	}
+	if !r.Before.IsZero() {
+		where += " AND datetime(created_at) < datetime(?)"
+		args = append(args, r.Before.UTC().Format(time.RFC3339))
+	}
+	if !r.ReadSince.IsZero() {
+		where += " AND read_at IS NOT NULL AND datetime(read_at) >= datetime(?)"
+		args = append(args, r.ReadSince.UTC().Format(time.RFC3339))
+	}
+	if !r.ReadBefore.IsZero() {
+		where += " AND read_at IS NOT NULL AND datetime(read_at) < datetime(?)"
+		args = append(args, r.ReadBefore.UTC().Format(time.RFC3339))
+	}
+	return where, args
+}
+
+// queuePosition is an unread link's place in the queue, for the default
+// order, or NULL if it hasn't been moved.
+const queuePosition = "(CASE WHEN read_at IS NULL THEN queue_position END)"
+
+// orderBy returns the ORDER BY clause for a sort order. Every order ends
+// with created_at DESC so ties are stable.
+func orderBy(sort SortOrder) string {
+	switch sort {
+	case SortNewest:
+		return "created_at DESC"
+	case SortOldest:
+		return "created_at ASC"
+	case SortTitle:
+		return "CASE WHEN COALESCE(title, '') = '' THEN url ELSE title END COLLATE NOCASE ASC, created_at DESC"
+	case SortDomain:
+		return "domain ASC, created_at DESC"
+	case SortPriority:
+		return "priority DESC, created_at DESC"
+	default:
+		// Unread links moved into place first, the rest by priority; read
+		// links don't compete for attention
+		return queuePosition + " IS NULL, " + queuePosition + ", " +
+			"CASE WHEN read_at IS NULL THEN priority ELSE 0 END DESC, created_at DESC"
+	}
+}
+
+// Delete removes a link by ID.
+func (s *SQLiteStorage) Delete(ctx context.Context, id string) error {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	if !model.ValidateShortID(id) {
+		return fmt.Errorf("invalid ID format")
+	}
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("delete link: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, "DELETE FROM links WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("delete link: %w", err)
+	}
+	if err := checkRowsAffected(result, "delete link"); err != nil {
+		return err
+	}
+	for _, table := range orphanTables {
+		query := fmt.Sprintf("DELETE FROM %s WHERE link_id = ?", table)
+		if _, err := tx.ExecContext(ctx, query, id); err != nil {
+			return fmt.Errorf("delete link's %s: %w", table, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("delete link: %w", err)
+	}
+	return nil
+}
+
+// Trash moves a link to the trash, keeping the time it was first trashed
+// if it's there already.
+func (s *SQLiteStorage) Trash(ctx context.Context, id string) error {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	if !model.ValidateShortID(id) {
+		return fmt.Errorf("invalid ID format")
+	}
+	result, err := s.db.ExecContext(ctx,
+		"UPDATE links SET trashed_at = COALESCE(trashed_at, ?) WHERE id = ?", time.Now().UTC().Format(time.RFC3339), id)
+	if err != nil {
+		return fmt.Errorf("trash: %w", err)
+	}
+	return checkRowsAffected(result, "trash")
+}
+
+// Restore takes a link out of the trash.
+func (s *SQLiteStorage) Restore(ctx context.Context, id string) error {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	if !model.ValidateShortID(id) {
+		return fmt.Errorf("invalid ID format")
+	}
+	result, err := s.db.ExecContext(ctx, "UPDATE links SET trashed_at = NULL WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("restore: %w", err)
+	}
+	return checkRowsAffected(result, "restore")
+}
+
+// SetContent stores the archived article text of a link with its hash,
+// replacing any previous copy.
+func (s *SQLiteStorage) SetContent(ctx context.Context, id, text string) error {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	if !model.ValidateShortID(id) {
+		return fmt.Errorf("invalid ID format")
+	}
+	// An upsert rather than INSERT OR REPLACE, whose implicit delete
+	// would skip the trigger that keeps content_fts in sync
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO link_content (link_id, text, sha256, fetched_at) VALUES (?, ?, ?, ?)
+		ON CONFLICT(link_id) DO UPDATE SET text = excluded.text, sha256 = excluded.sha256, fetched_at = excluded.fetched_at
+	`, id, text, model.ContentHash(text), time.Now().UTC().Format(time.RFC3339))
+	if err != nil {
+		return fmt.Errorf("set content: %w", err)
+	}
+	return nil
+}
+
+// Content returns the archived article text of a link, or
+// model.ErrNotFound if none was saved.
+func (s *SQLiteStorage) Content(ctx context.Context, id string) (string, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	var text string
+	err := s.db.GetContext(ctx, &text, "SELECT text FROM link_content WHERE link_id = ?", id)
+	if err == sql.ErrNoRows {
+		return "", model.ErrNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("get content: %w", err)
+	}
+	return text, nil
+}
+
+// ContentHash returns the hash of a link's archived text, or
+// model.ErrNotFound if none was saved. Text archived before hashes were
+// stored is hashed on the fly.
+func (s *SQLiteStorage) ContentHash(ctx context.Context, id string) (string, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	var row struct {
+		Text   string `db:"text"`
+		SHA256 string `db:"sha256"`
+	}
+	err := s.db.GetContext(ctx, &row, "SELECT text, sha256 FROM link_content WHERE link_id = ?", id)
+	if err == sql.ErrNoRows {
+		return "", model.ErrNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("get content hash: %w", err)
+	}
+	if row.SHA256 == "" {
+		return model.ContentHash(row.Text), nil
+	}
+	return row.SHA256, nil
+}
+
+// FetchState returns when a link's page was last fetched, or
+// model.ErrNotFound if it never was.
+func (s *SQLiteStorage) FetchState(ctx context.Context, id string) (*FetchState, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	var row struct {
+		LinkID       string `db:"link_id"`
+		ETag         string `db:"etag"`
+		LastModified string `db:"last_modified"`
+		FetchedAt    string `db:"fetched_at"`
+	}
+	err := s.db.GetContext(ctx, &row, "SELECT link_id, etag, last_modified, fetched_at FROM fetch_state WHERE link_id = ?", id)
+	if err == sql.ErrNoRows {
+		return nil, model.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get fetch state: %w", err)
+	}
+	return &FetchState{LinkID: row.LinkID, ETag: row.ETag, LastModified: row.LastModified, FetchedAt: parseSQLiteTime(row.FetchedAt)}, nil
+}
+
+// SetFetchState records a fetch of a link's page, replacing the previous
+// record.
+func (s *SQLiteStorage) SetFetchState(ctx context.Context, state FetchState) error {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	if !model.ValidateShortID(state.LinkID) {
+		return fmt.Errorf("invalid ID format")
+	}
+	_, err := s.db.ExecContext(ctx, `
+		INSERT OR REPLACE INTO fetch_state (link_id, etag, last_modified, fetched_at) VALUES (?, ?, ?, ?)
+	`, state.LinkID, state.ETag, state.LastModified, state.FetchedAt.UTC().Format(time.RFC3339))
+	if err != nil {
+		return fmt.Errorf("set fetch state: %w", err)
+	}
+	return nil
+}
+
+// SetSummary stores a link's summary, replacing any previous one.
+func (s *SQLiteStorage) SetSummary(ctx context.Context, summary *model.Summary) error {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	if !model.ValidateShortID(summary.LinkID) {
+		return fmt.Errorf("invalid ID format")
+	}
+	_, err := s.db.ExecContext(ctx, `
+		INSERT OR REPLACE INTO summaries (link_id, text, model, created_at) VALUES (?, ?, ?, ?)
+	`, summary.LinkID, summary.Text, summary.Model, summary.CreatedAt.UTC().Format(time.RFC3339))
+	if err != nil {
+		return fmt.Errorf("set summary: %w", err)
+	}
+	return nil
+}
+
+// Summary returns a link's summary, or model.ErrNotFound if it has none.
+func (s *SQLiteStorage) Summary(ctx context.Context, linkID string) (*model.Summary, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	var row summaryRow
+	err := s.db.GetContext(ctx, &row, "SELECT link_id, text, model, created_at FROM summaries WHERE link_id = ?", linkID)
+	if err == sql.ErrNoRows {
+		return nil, model.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get summary: %w", err)
+	}
+	return row.summary(), nil
+}
+
+// Summaries returns every link's summary, keyed by link ID.
+func (s *SQLiteStorage) Summaries(ctx context.Context) (map[string]*model.Summary, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	var rows []summaryRow
+	if err := s.db.SelectContext(ctx, &rows, "SELECT link_id, text, model, created_at FROM summaries"); err != nil {
+		return nil, fmt.Errorf("list summaries: %w", err)
+	}
+	summaries := make(map[string]*model.Summary, len(rows))
+	for _, row := range rows {
+		summaries[row.LinkID] = row.summary()
+	}
+	return summaries, nil
+}
+
+// summaryRow is a row of the summaries table.
+type summaryRow struct {
+	LinkID    string `db:"link_id"`
+	Text      string `db:"text"`
+	Model     string `db:"model"`
+	CreatedAt string `db:"created_at"`
+}
+
+func (r summaryRow) summary() *model.Summary {
+	return &model.Summary{LinkID: r.LinkID, Text: r.Text, Model: r.Model, CreatedAt: parseSQLiteTime(r.CreatedAt)}
+}
+
+// Reorder moves an unread link offset places along the unread queue, toward
+// the top for negative offsets, stopping at either end. The links above
+// its old and new places keep the places they're shown in, so links saved
+// later are listed after them. It returns the link's new place, from 0,
+// and the length of the queue.
+func (s *SQLiteStorage) Reorder(ctx context.Context, id string, offset int) (int, int, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	if !model.ValidateShortID(id) {
+		return 0, 0, fmt.Errorf("invalid ID format")
+	}
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, 0, fmt.Errorf("begin move: %w", err)
+	}
+	defer tx.Rollback()
+
+	where, args := ListOptions{ReadStatus: ReadStatusUnread}.conditions()
+	var rows []struct {
+		ID     string `db:"id"`
+		Queued bool   `db:"queued"`
+	}
+	err = tx.SelectContext(ctx, &rows, "SELECT id, "+queuePosition+" IS NOT NULL AS queued FROM links WHERE 1=1"+
+		where+" ORDER BY "+orderBy(SortDefault), args...)
+	if err != nil {
+		return 0, 0, fmt.Errorf("list queue: %w", err)
+	}
+	from := -1
+	for i, row := range rows {
+		if row.ID == id {
+			from = i
+			break
+		}
+	}
+	if from < 0 {
+		var exists bool
+		if err := tx.GetContext(ctx, &exists, "SELECT EXISTS(SELECT 1 FROM links WHERE id = ?)", id); err != nil {
+			return 0, 0, fmt.Errorf("get link: %w", err)
+		}
+		if !exists {
+			return 0, 0, model.ErrNotFound
+		}
+		return 0, 0, fmt.Errorf("link %s is read or snoozed, so not in the unread queue", id)
+	}
+	to := from + max(-from, min(offset, len(rows)-1-from))
+	if to == from {
+		return to, len(rows), nil
+	}
+
+	ids := make([]string, 0, len(rows))
+	last := max(from, to)
+	for i, row := range rows {
+		if i != from {
+			ids = append(ids, row.ID)
+		}
+		if row.Queued {
+			last = max(last, i)
+		}
+	}
+	ids = append(ids[:to], append([]string{id}, ids[to:]...)...)
+
+	// Snoozed links keep their places for when they wake
+	for _, row := range rows {
+		if _, err := tx.ExecContext(ctx, "UPDATE links SET queue_position = NULL WHERE id = ?", row.ID); err != nil {
+			return 0, 0, fmt.Errorf("clear queue place: %w", err)
+		}
+	}
+	for i, linkID := range ids[:last+1] {
+		if _, err := tx.ExecContext(ctx, "UPDATE links SET queue_position = ? WHERE id = ?", i, linkID); err != nil {
+			return 0, 0, fmt.Errorf("set queue place: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, 0, fmt.Errorf("commit move: %w", err)
+	}
+	return to, len(rows), nil
+}
+
+// SetEmbedding stores a link's embedding, replacing any previous one.
+func (s *SQLiteStorage) SetEmbedding(ctx context.Context, e *model.Embedding) error {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	if !model.ValidateShortID(e.LinkID) {
+		return fmt.Errorf("invalid ID format")
+	}
+	vector := make([]byte, 4*len(e.Vector))
+	for i, f := range e.Vector {
+		binary.LittleEndian.PutUint32(vector[4*i:], math.Float32bits(f))
+	}
+	_, err := s.db.ExecContext(ctx, `
+		INSERT OR REPLACE INTO embeddings (link_id, model, text_hash, vector, created_at) VALUES (?, ?, ?, ?, ?)
+	`, e.LinkID, e.Model, e.TextHash, vector, e.CreatedAt.UTC().Format(time.RFC3339))
+	if err != nil {
+		return fmt.Errorf("set embedding: %w", err)
+	}
+	return nil
+}
+
+// Embeddings returns the embeddings computed by the named model.
+func (s *SQLiteStorage) Embeddings(ctx context.Context, modelName string) ([]*model.Embedding, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	var rows []struct {
+		LinkID    string `db:"link_id"`
+		Model     string `db:"model"`
+		TextHash  string `db:"text_hash"`
+		Vector    []byte `db:"vector"`
+		CreatedAt string `db:"created_at"`
+	}
+	err := s.db.SelectContext(ctx, &rows, `
+		SELECT link_id, model, text_hash, vector, created_at FROM embeddings WHERE model = ?
+	`, modelName)
+	if err != nil {
+		return nil, fmt.Errorf("list embeddings: %w", err)
+	}
+	embeddings := make([]*model.Embedding, len(rows))
+	for i, row := range rows {
+		vector := make([]float32, len(row.Vector)/4)
+		for j := range vector {
+			vector[j] = math.Float32frombits(binary.LittleEndian.Uint32(row.Vector[4*j:]))
+		}
+		embeddings[i] = &model.Embedding{
+			LinkID:    row.LinkID,
+			Model:     row.Model,
+			TextHash:  row.TextHash,
+			Vector:    vector,
+			CreatedAt: parseSQLiteTime(row.CreatedAt),
+		}
+	}
+	return embeddings, nil
+}
+
+type attachmentRow struct {
+	LinkID      string `db:"link_id"`
+	SHA256      string `db:"sha256"`
+	Path        string `db:"path"`
+	ContentType string `db:"content_type"`
+	Size        int64  `db:"size"`
+	CreatedAt   string `db:"created_at"`
+}
+
+func (r *attachmentRow) toAttachment() *model.Attachment {
+	return &model.Attachment{
+		LinkID:      r.LinkID,
+		SHA256:      r.SHA256,
+		Path:        r.Path,
+		ContentType: r.ContentType,
+		Size:        r.Size,
+		CreatedAt:   parseSQLiteTime(r.CreatedAt),
+	}
+}
+
+const attachmentColumns = "link_id, sha256, path, content_type, size, created_at"
+
+// SetAttachment records the saved copy of a link's document, replacing any
+// previous one. A zero CreatedAt defaults to now.
+func (s *SQLiteStorage) SetAttachment(ctx context.Context, a *model.Attachment) error {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	if !model.ValidateShortID(a.LinkID) {
+		return fmt.Errorf("invalid ID format")
+	}
+	if a.CreatedAt.IsZero() {
+		a.CreatedAt = time.Now()
+	}
+	_, err := s.db.ExecContext(ctx,
+		"INSERT OR REPLACE INTO attachments ("+attachmentColumns+") VALUES (?, ?, ?, ?, ?, ?)",
+		a.LinkID, a.SHA256, a.Path, a.ContentType, a.Size, a.CreatedAt.UTC().Format(time.RFC3339))
+	if err != nil {
+		return fmt.Errorf("set attachment: %w", err)
+	}
+	return nil
+}
+
+// Attachment returns the saved copy of a link's document, or
+// model.ErrNotFound if there is none.
+func (s *SQLiteStorage) Attachment(ctx context.Context, linkID string) (*model.Attachment, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	var row attachmentRow
+	err := s.db.GetContext(ctx, &row, "SELECT "+attachmentColumns+" FROM attachments WHERE link_id = ?", linkID)
+	if err == sql.ErrNoRows {
+		return nil, model.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get attachment: %w", err)
+	}
+	return row.toAttachment(), nil
+}
+
+// Attachments returns every saved copy, oldest first.
+func (s *SQLiteStorage) Attachments(ctx context.Context) ([]*model.Attachment, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	var rows []attachmentRow
+	err := s.db.SelectContext(ctx, &rows, "SELECT "+attachmentColumns+" FROM attachments ORDER BY created_at, link_id")
+	if err != nil {
+		return nil, fmt.Errorf("list attachments: %w", err)
+	}
+	attachments := make([]*model.Attachment, len(rows))
+	for i := range rows {
+		attachments[i] = rows[i].toAttachment()
+	}
+	return attachments, nil
+}
+
+// excerptTable is a table of excerpts, annotations or quotes, with the
+// noun its errors use.
+type excerptTable struct {
+	name, noun string
+}
+
+var (
+	annotationsTable = excerptTable{"annotations", "annotation"}
+	quotesTable      = excerptTable{"quotes", "quote"}
+)
+
+// addExcerpt appends text to a link's excerpts in t.
+func (s *SQLiteStorage) addExcerpt(ctx context.Context, t excerptTable, linkID, text string) (*model.Excerpt, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	if !model.ValidateShortID(linkID) {
+		return nil, fmt.Errorf("invalid ID format")
+	}
+	var exists bool
+	if err := s.db.GetContext(ctx, &exists, "SELECT EXISTS(SELECT 1 FROM links WHERE id = ?)", linkID); err != nil {
+		return nil, fmt.Errorf("add %s: %w", t.noun, err)
+	}
+	if !exists {
+		return nil, model.ErrNotFound
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	result, err := s.db.ExecContext(ctx,
+		"INSERT INTO "+t.name+" (link_id, text, created_at) VALUES (?, ?, ?)",
+		linkID, text, now.Format(time.RFC3339))
+	if err != nil {
+		return nil, fmt.Errorf("add %s: %w", t.noun, err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("add %s: %w", t.noun, err)
+	}
+	return &model.Excerpt{ID: id, LinkID: linkID, Text: text, CreatedAt: now}, nil
+}
+
+// excerpts returns a link's excerpts in t, or every link's when linkID is
+// "", oldest first.
+func (s *SQLiteStorage) excerpts(ctx context.Context, t excerptTable, linkID string) ([]*model.Excerpt, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	query := "SELECT id, link_id, text, created_at FROM " + t.name
+	var args []interface{}
+	if linkID != "" {
+		query += " WHERE link_id = ?"
+		args = append(args, linkID)
+	}
+	var rows []struct {
+		ID        int64  `db:"id"`
+		LinkID    string `db:"link_id"`
+		Text      string `db:"text"`
+		CreatedAt string `db:"created_at"`
+	}
+	if err := s.db.SelectContext(ctx, &rows, query+" ORDER BY created_at, id", args...); err != nil {
+		return nil, fmt.Errorf("list %s: %w", t.name, err)
+	}
+
+	excerpts := make([]*model.Excerpt, len(rows))
+	for i, r := range rows {
+		excerpts[i] = &model.Excerpt{ID: r.ID, LinkID: r.LinkID, Text: r.Text, CreatedAt: parseSQLiteTime(r.CreatedAt)}
+	}
+	return excerpts, nil
+}
+
+// deleteExcerpt removes an excerpt from t by ID.
+func (s *SQLiteStorage) deleteExcerpt(ctx context.Context, t excerptTable, id int64) error {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	result, err := s.db.ExecContext(ctx, "DELETE FROM "+t.name+" WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("delete %s: %w", t.noun, err)
+	}
+	return checkRowsAffected(result, "delete "+t.noun)
+}
+
+// excerptsAs converts excerpts to annotations or quotes.
+func excerptsAs[P *model.Annotation | *model.Quote](excerpts []*model.Excerpt) []P {
+	converted := make([]P, len(excerpts))
+	for i, e := range excerpts {
+		converted[i] = P(e)
+	}
+	return converted
+}
+
+// AddAnnotation appends a dated note to a link.
+func (s *SQLiteStorage) AddAnnotation(ctx context.Context, linkID, text string) (*model.Annotation, error) {
+	e, err := s.addExcerpt(ctx, annotationsTable, linkID, text)
+	return (*model.Annotation)(e), err
+}
+
+// Annotations returns a link's annotations, or every link's when linkID
+// is "", oldest first.
+func (s *SQLiteStorage) Annotations(ctx context.Context, linkID string) ([]*model.Annotation, error) {
+	excerpts, err := s.excerpts(ctx, annotationsTable, linkID)
+	return excerptsAs[*model.Annotation](excerpts), err
+}
+
+// DeleteAnnotation removes an annotation by ID.
+func (s *SQLiteStorage) DeleteAnnotation(ctx context.Context, id int64) error {
+	return s.deleteExcerpt(ctx, annotationsTable, id)
+}
+
+// AddQuote saves a passage highlighted from a link.
+func (s *SQLiteStorage) AddQuote(ctx context.Context, linkID, text string) (*model.Quote, error) {
+	e, err := s.addExcerpt(ctx, quotesTable, linkID, text)
+	return (*model.Quote)(e), err
+}
+
+// Quotes returns a link's quotes, or every link's when linkID is "",
+// oldest first.
+func (s *SQLiteStorage) Quotes(ctx context.Context, linkID string) ([]*model.Quote, error) {
+	excerpts, err := s.excerpts(ctx, quotesTable, linkID)
+	return excerptsAs[*model.Quote](excerpts), err
+}
+
+// DeleteQuote removes a quote by ID.
+func (s *SQLiteStorage) DeleteQuote(ctx context.Context, id int64) error {
+	return s.deleteExcerpt(ctx, quotesTable, id)
+}
+
+// SetAlias names a link, moving the alias if it already names another link.
+func (s *SQLiteStorage) SetAlias(ctx context.Context, id, name string) error {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	if !model.ValidateShortID(id) {
+		return fmt.Errorf("invalid ID format")
+	}
+	if !model.ValidateAlias(name) {
+		return model.ErrInvalidAlias
+	}
+	var exists bool
+	if err := s.db.GetContext(ctx, &exists, "SELECT EXISTS(SELECT 1 FROM links WHERE id = ?)", id); err != nil {
+		return fmt.Errorf("set alias: %w", err)
+	}
+	if !exists {
+		return model.ErrNotFound
+	}
+	_, err := s.db.ExecContext(ctx,
+		"INSERT OR REPLACE INTO link_aliases (name, link_id) VALUES (?, ?)", name, id)
+	if err != nil {
+		return fmt.Errorf("set alias: %w", err)
+	}
+	return nil
+}
+
+// RemoveAlias deletes an alias. It returns model.ErrNotFound if no such
+// alias exists.
+func (s *SQLiteStorage) RemoveAlias(ctx context.Context, name string) error {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	result, err := s.db.ExecContext(ctx, "DELETE FROM link_aliases WHERE name = ?", name)
+	if err != nil {
+		return fmt.Errorf("remove alias: %w", err)
+	}
+	return checkRowsAffected(result, "remove alias")
+}
+
+// Aliases returns all link aliases ordered by name.
+func (s *SQLiteStorage) Aliases(ctx context.Context) ([]Alias, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	var aliases []Alias
+	err := s.db.SelectContext(ctx, &aliases,
+		"SELECT name, link_id FROM link_aliases ORDER BY name")
+	if err != nil {
+		return nil, fmt.Errorf("list aliases: %w", err)
+	}
+	return aliases, nil
+}
+
+// SyncRecords returns the links mirrored to a remote service, including
+// links deleted locally since.
+func (s *SQLiteStorage) SyncRecords(ctx context.Context, service string) ([]SyncRecord, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	var records []SyncRecord
+	err := s.db.SelectContext(ctx, &records,
+		"SELECT service, link_id, remote_id, read, digest FROM sync_links WHERE service = ? ORDER BY synced_at, link_id", service)
+	if err != nil {
+		return nil, fmt.Errorf("list sync records: %w", err)
+	}
+	return records, nil
+}
+
+// SetSyncRecord records the remote copy of a link, replacing any previous
+// record for the link or the remote ID.
+func (s *SQLiteStorage) SetSyncRecord(ctx context.Context, rec SyncRecord) error {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	if !model.ValidateShortID(rec.LinkID) {
+		return fmt.Errorf("invalid ID format")
+	}
+	_, err := s.db.ExecContext(ctx, `
+		INSERT OR REPLACE INTO sync_links (service, link_id, remote_id, read, digest, synced_at)
+		VALUES (?, ?, ?, ?, ?, datetime('now'))
+	`, rec.Service, rec.LinkID, rec.RemoteID, rec.Read, rec.Digest)
+	if err != nil {
+		return fmt.Errorf("set sync record: %w", err)
 	}
 	return nil
 }
 
-// Export returns all links for export.
-func (s *SQLiteStorage) Export(ctx context.Context) ([]*model.Link, error) {
-	return s.List(ctx, ListOptions{ReadStatus: ReadStatusAll})
+// MarkRead sets the read_at timestamp for a link.
+func (s *SQLiteStorage) MarkRead(ctx context.Context, id string) error {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	if !model.ValidateShortID(id) {
+		return fmt.Errorf("invalid ID format")
+	}
+	result, err := s.db.ExecContext(ctx,
+		"UPDATE links SET read_at = datetime('now') WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("mark read: %w", err)
+	}
+	return checkRowsAffected(result, "mark read")
 }
 
-// Import imports links from a slice, handling duplicates.
-func (s *SQLiteStorage) Import(ctx context.Context, links []*model.Link) error {
-	for _, link := range links {
-		var existing linkRow
-		err := s.db.GetContext(ctx, &existing,
-			"SELECT id, url, title, note, tags, created_at, read_at FROM links WHERE url = ?", link.URL)
+// Archive puts a link away, out of the unread queue and the read list. A
+// read link keeps its read time; an unread one is marked read without a
+// read log entry, so clearing out the queue doesn't count toward reading
+// streaks. Archiving an archived link does nothing.
+func (s *SQLiteStorage) Archive(ctx context.Context, id string) error {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	if !model.ValidateShortID(id) {
+		return fmt.Errorf("invalid ID format")
+	}
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("archive: %w", err)
+	}
+	defer tx.Rollback()
 
-		var readAt sql.NullString
-		if link.ReadAt != nil {
-			readAt.String = link.ReadAt.Format(time.RFC3339)
-			readAt.Valid = true
+	var row struct {
+		Unread   bool `db:"unread"`
+		Archived bool `db:"archived"`
+	}
+	err = tx.GetContext(ctx, &row, "SELECT read_at IS NULL AS unread, archived_at IS NOT NULL AS archived FROM links WHERE id = ?", id)
+	if err == sql.ErrNoRows {
+		return model.ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("archive: %w", err)
+	}
+	if row.Archived {
+		return nil
+	}
+	now := time.Now().UTC().Format(time.RFC3339)
+	if _, err := tx.ExecContext(ctx, "UPDATE links SET archived_at = ?, read_at = COALESCE(read_at, ?) WHERE id = ?", now, now, id); err != nil {
+		return fmt.Errorf("archive: %w", err)
+	}
+	if row.Unread {
+		if _, err := tx.ExecContext(ctx, "DELETE FROM read_log WHERE link_id = ? AND read_at = ?", id, now); err != nil {
+			return fmt.Errorf("archive: %w", err)
 		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("archive: %w", err)
+	}
+	return nil
+}
+
+// MarkUnread clears the read_at timestamp for a link, taking it out of
+// the archive.
+func (s *SQLiteStorage) MarkUnread(ctx context.Context, id string) error {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	if !model.ValidateShortID(id) {
+		return fmt.Errorf("invalid ID format")
+	}
+	// Its place in the queue from before it was read is long out of date
+	result, err := s.db.ExecContext(ctx,
+		"UPDATE links SET read_at = NULL, archived_at = NULL, queue_position = NULL WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("mark unread: %w", err)
+	}
+	return checkRowsAffected(result, "mark unread")
+}
+
+// SetPriority updates the priority level of a link.
+func (s *SQLiteStorage) SetPriority(ctx context.Context, id string, priority model.Priority) error {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	if !model.ValidateShortID(id) {
+		return fmt.Errorf("invalid ID format")
+	}
+	result, err := s.db.ExecContext(ctx,
+		"UPDATE links SET priority = ? WHERE id = ?", int(priority), id)
+	if err != nil {
+		return fmt.Errorf("set priority: %w", err)
+	}
+	return checkRowsAffected(result, "set priority")
+}
+
+// RecordCheck stores the HTTP status and time of a dead-link check.
+// A status of 0 means the URL could not be reached.
+func (s *SQLiteStorage) RecordCheck(ctx context.Context, id string, status int, checkedAt time.Time) error {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	if !model.ValidateShortID(id) {
+		return fmt.Errorf("invalid ID format")
+	}
+	result, err := s.db.ExecContext(ctx,
+		"UPDATE links SET http_status = ?, checked_at = ? WHERE id = ?",
+		status, formatNullTime(&checkedAt), id)
+	if err != nil {
+		return fmt.Errorf("record check: %w", err)
+	}
+	return checkRowsAffected(result, "record check")
+}
+
+// Snooze hides a link from the unread list until the given time.
+// A zero time clears the snooze.
+func (s *SQLiteStorage) Snooze(ctx context.Context, id string, until time.Time) error {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	if !model.ValidateShortID(id) {
+		return fmt.Errorf("invalid ID format")
+	}
+	var snoozedUntil sql.NullString
+	if !until.IsZero() {
+		snoozedUntil = formatNullTime(&until)
+	}
+	result, err := s.db.ExecContext(ctx,
+		"UPDATE links SET snoozed_until = ? WHERE id = ?", snoozedUntil, id)
+	if err != nil {
+		return fmt.Errorf("snooze: %w", err)
+	}
+	return checkRowsAffected(result, "snooze")
+}
+
+// Woken returns the unread links whose snooze ended after since and no
+// later than until, in the order they woke.
+func (s *SQLiteStorage) Woken(ctx context.Context, since, until time.Time) ([]*model.Link, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	var rows []linkRow
+	err := s.db.SelectContext(ctx, &rows,
+		"SELECT "+linkColumns+" FROM links WHERE read_at IS NULL AND "+notTrashed+" AND snoozed_until > ? AND snoozed_until <= ? ORDER BY snoozed_until, created_at",
+		formatNullTime(&since), formatNullTime(&until))
+	if err != nil {
+		return nil, fmt.Errorf("woken links: %w", err)
+	}
+	links := make([]*model.Link, len(rows))
+	for i := range rows {
+		links[i] = rows[i].toLink()
+	}
+	return links, nil
+}
+
+func checkRowsAffected(result sql.Result, action string) error {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return model.ErrNotFound
+	}
+	return nil
+}
+
+// Export returns all links for export, with their annotations.
+func (s *SQLiteStorage) Export(ctx context.Context) ([]*model.Link, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
 
-		createdAtStr := link.CreatedAt.Format(time.RFC3339)
-		if link.CreatedAt.IsZero() {
-			createdAtStr = time.Now().Format(time.RFC3339)
+	links, err := s.List(ctx, ListOptions{ReadStatus: ReadStatusAll})
+	if err != nil {
+		return nil, err
+	}
+	annotations, err := s.Annotations(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]*model.Link, len(links))
+	for _, link := range links {
+		byID[link.ID] = link
+	}
+	for _, a := range annotations {
+		if link := byID[a.LinkID]; link != nil {
+			link.Annotations = append(link.Annotations, a)
 		}
+	}
+	return links, nil
+}
 
-		if err == sql.ErrNoRows {
-			// Generate ID if not provided
-			if link.ID == "" {
-				link.ID = model.GenerateShortID()
-			}
-			_, err = s.db.ExecContext(ctx,
-				"INSERT INTO links (id, url, title, note, tags, created_at, read_at) VALUES (?, ?, ?, ?, ?, ?, ?)",
-				link.ID, link.URL, link.Title, link.Note, link.Tags, createdAtStr, readAt)
-			if err != nil {
-				return fmt.Errorf("insert link %s: %w", link.URL, err)
-			}
-		} else if err != nil {
-			return fmt.Errorf("check existing link %s: %w", link.URL, err)
-		} else {
-			existingLink := existing.toLink()
-			// Preserve existing title/note if present, otherwise use new
-			newTitle := existingLink.Title
-			if newTitle == "" {
-				newTitle = link.Title
+// Import imports links from a slice, handling duplicates. A link already
+// saved (by URL) keeps its ID, title, and other fields, filling in any it
+// lacks from the import; tags are combined, notes it doesn't have are
+// added, and the read state follows the import. The links are imported in one transaction, so a
+// cancelled import or a database error changes nothing, while a link
+// that is invalid or clashes with another only fails itself.
+func (s *SQLiteStorage) Import(ctx context.Context, links []*model.Link, opts ImportOptions) ([]ImportResult, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin import: %w", err)
+	}
+	defer tx.Rollback()
+
+	results := make([]ImportResult, len(links))
+	for i, link := range links {
+		// Each link gets a savepoint so a failed one leaves no trace
+		if _, err := tx.ExecContext(ctx, "SAVEPOINT import_link"); err != nil {
+			return nil, fmt.Errorf("import link %s: %w", link.URL, err)
+		}
+		result, err := importLink(ctx, tx, link, s.newID)
+		if err != nil {
+			if ctx.Err() != nil || !isConstraint(err) {
+				return nil, fmt.Errorf("import link %s: %w", link.URL, err)
 			}
-			newNote := existingLink.Note
-			if newNote == "" {
-				newNote = link.Note
+			if _, err := tx.ExecContext(ctx, "ROLLBACK TO import_link"); err != nil {
+				return nil, fmt.Errorf("import link %s: %w", link.URL, err)
 			}
+			result = ImportResult{URL: link.URL, Status: ImportFailed, Reason: constraintReason(err)}
+		}
+		if _, err := tx.ExecContext(ctx, "RELEASE import_link"); err != nil {
+			return nil, fmt.Errorf("import link %s: %w", link.URL, err)
+		}
+		if opts.DryRun && result.Status == ImportAdded {
+			result.ID = ""
+		}
+		results[i] = result
+	}
 
-			if link.Tags != "" {
-				mergeLink := &model.Link{Tags: link.Tags}
-				existingLink.MergeTags(mergeLink)
-			}
-			newTags := existingLink.Tags
+	if opts.DryRun {
+		return results, nil
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit import: %w", err)
+	}
+	return results, nil
+}
 
-			_, err = s.db.ExecContext(ctx, "DELETE FROM links WHERE url = ?", link.URL)
-			if err != nil {
-				return fmt.Errorf("delete existing link %s: %w", link.URL, err)
-			}
+// importLink imports one link within tx. Invalid links fail without an
+// error.
+func importLink(ctx context.Context, tx *sqlx.Tx, link *model.Link, newID func(time.Time) string) (ImportResult, error) {
+	result := ImportResult{URL: link.URL}
+	if err := link.Validate(); err != nil {
+		result.Status, result.Reason = ImportFailed, err.Error()
+		return result, nil
+	}
 
-			existingCreatedAt := existingLink.CreatedAt.Format(time.RFC3339)
-			if existingLink.CreatedAt.IsZero() {
-				existingCreatedAt = createdAtStr
-			}
+	var existing linkRow
+	err := tx.GetContext(ctx, &existing, selectByURL, link.URL, link.URL)
+	if err == sql.ErrNoRows {
+		// Generate ID if not provided
+		if link.ID == "" {
+			err = insertNewLink(ctx, tx, link, newID)
+		} else {
+			err = insertLink(ctx, tx, link)
+		}
+		if err != nil {
+			return result, err
+		}
+		if _, err := addAnnotations(ctx, tx, link.ID, link.Annotations); err != nil {
+			return result, err
+		}
+		result.ID, result.Status = link.ID, ImportAdded
+		return result, nil
+	}
+	if err != nil {
+		return result, fmt.Errorf("check existing link: %w", err)
+	}
 
-			_, err = s.db.ExecContext(ctx,
-				"INSERT INTO links (id, url, title, note, tags, created_at, read_at) VALUES (?, ?, ?, ?, ?, ?, ?)",
-				existingLink.ID, link.URL, newTitle, newNote, newTags, existingCreatedAt, readAt)
-			if err != nil {
-				return fmt.Errorf("re-insert merged link %s: %w", link.URL, err)
-			}
+	merged := existing.toLink()
+	result.ID = merged.ID
+	// Preserve the existing title if present, otherwise use new
+	if merged.Title == "" {
+		merged.Title = link.Title
+	}
+	if link.Tags != "" {
+		merged.MergeTags(&model.Link{Tags: link.Tags})
+	}
+	if merged.Priority == model.PriorityNormal {
+		merged.Priority = link.Priority
+	}
+	if merged.SnoozedUntil == nil {
+		merged.SnoozedUntil = link.SnoozedUntil
+	}
+	if merged.WordCount == 0 {
+		merged.WordCount = link.WordCount
+	}
+	if merged.ReadingSeconds == 0 {
+		merged.ReadingSeconds = link.ReadingSeconds
+	}
+	if merged.Description == "" {
+		merged.Description = link.Description
+	}
+	if merged.MediaType == "" {
+		merged.MediaType = link.MediaType
+	}
+	if merged.Author == "" {
+		merged.Author = link.Author
+	}
+	// Read state follows the imported link
+	merged.ReadAt = link.ReadAt
+	if merged.CreatedAt.IsZero() {
+		merged.CreatedAt = link.CreatedAt
+	}
+
+	changed := !reflect.DeepEqual(linkValues(merged), linkValues(existing.toLink()))
+	if changed {
+		if _, err := updateLink(ctx, tx, merged); err != nil {
+			return result, fmt.Errorf("update merged link: %w", err)
 		}
 	}
+	added, err := addAnnotations(ctx, tx, merged.ID, link.Annotations)
+	if err != nil {
+		return result, err
+	}
+	if !changed && added == 0 {
+		result.Status, result.Reason = ImportSkipped, "already saved, nothing new"
+		return result, nil
+	}
+	result.Status = ImportMerged
+	return result, nil
+}
 
-	return nil
+// isConstraint reports whether err is SQLite refusing a row that breaks a
+// constraint, such as a duplicate ID.
+func isConstraint(err error) bool {
+	var sqliteErr *sqlite.Error
+	return errors.As(err, &sqliteErr) && sqliteErr.Code()&0xff == sqlite3.SQLITE_CONSTRAINT
+}
+
+// constraintReason describes the constraint err broke.
+func constraintReason(err error) string {
+	if isDuplicateID(err) {
+		return "ID already used by another link"
+	}
+	var sqliteErr *sqlite.Error
+	if errors.As(err, &sqliteErr) {
+		return strings.TrimSuffix(sqlite.ErrorCodeString[sqliteErr.Code()], ")")
+	}
+	return err.Error()
+}
+
+// Search performs a full-text search across links, and across archived
+// article text if opts.Content is set.
+func (s *SQLiteStorage) Search(ctx context.Context, query string, opts SearchOptions) ([]*model.Link, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	match, fields, err := ftsQuery(query)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", model.ErrInvalidQuery, err)
+	}
+	hits := linkHits
+	args := []interface{}{match}
+	// Field prefixes name link fields, which the quote, annotation, and
+	// content indexes lack
+	if !fields {
+		hits += " UNION ALL " + quoteHits + " UNION ALL " + annotationHits
+		args = append(args, match, match)
+	}
+	if opts.Content && !fields {
+		hits += " UNION ALL " + contentHits
+		args = append(args, match)
+	}
+
+	filter := ListOptions{ReadStatus: ReadStatusAll}
+	if opts.ReadStatus != nil {
+		filter.ReadStatus = *opts.ReadStatus
+	}
+	where, whereArgs := filter.conditions()
+	dates, dateArgs := opts.DateRange.conditions()
+	args = append(append(args, whereArgs...), dateArgs...)
+
+	// A link matching in several places keeps the field and snippet of its
+	// best-ranked hit. MATERIALIZED stops the planner from flattening the
+	// hits into the join, where bm25() and snippet() can't run.
+	var rows []searchRow
+	err = s.db.SelectContext(ctx, &rows, `
+		WITH hits AS MATERIALIZED (`+hits+`)
+		SELECT `+linkColumns+`, hits.field AS match_field, hits.snippet AS match_snippet, MIN(hits.score) AS score
+		FROM links
+		JOIN hits ON hits.link_id = links.id
+		WHERE 1=1`+where+dates+`
+		GROUP BY links.id
+		ORDER BY score, created_at DESC
+	`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("search links: %w", err)
+	}
+
+	links := make([]*model.Link, len(rows))
+	for i := range rows {
+		links[i] = rows[i].toLink()
+		snippet, highlights := parseSnippet(rows[i].Snippet)
+		links[i].Match = &model.Match{Field: rows[i].Field, Snippet: snippet, Highlights: highlights}
+	}
+
+	return links, nil
 }
 
-// Search performs a full-text search across links.
-func (s *SQLiteStorage) Search(ctx context.Context, query string) ([]*model.Link, error) {
+// SearchApproximate matches query against link titles and URLs by trigram
+// similarity, so misspelled terms still find links.
+func (s *SQLiteStorage) SearchApproximate(ctx context.Context, query string, opts SearchOptions, limit int) ([]*model.Link, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	dates, args := opts.DateRange.conditions()
+
 	var rows []linkRow
 	err := s.db.SelectContext(ctx, &rows, `
-		SELECT l.id, l.url, l.title, l.note, l.tags, l.created_at, l.read_at
-		FROM links l
-		INNER JOIN links_fts ON l.rowid = links_fts.rowid
-		WHERE links_fts MATCH ?
-		ORDER BY l.created_at DESC
-	`, query)
+		SELECT `+linkColumns+`
+		FROM links
+		WHERE `+notTrashed+dates+`
+		ORDER BY created_at DESC
+	`, args...)
 	if err != nil {
 		return nil, fmt.Errorf("search links: %w", err)
 	}
@@ -386,7 +2015,176 @@ func (s *SQLiteStorage) Search(ctx context.Context, query string) ([]*model.Link
 		links[i] = rows[i].toLink()
 	}
 
-	return links, nil
+	return approximateMatches(links, query, limit), nil
+}
+
+// Reindex rebuilds the full-text indexes of links and archived content
+// from scratch and returns the number of links indexed.
+func (s *SQLiteStorage) Reindex(ctx context.Context) (int, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("reindex: %w", err)
+	}
+	defer tx.Rollback()
+
+	statements := []string{
+		"DELETE FROM links_fts",
+		`INSERT INTO links_fts(id, url, title, tags, description)
+		 SELECT id, url, COALESCE(title, ''), COALESCE(tags, ''), COALESCE(description, '')
+		 FROM links`,
+		"DELETE FROM content_fts",
+		"INSERT INTO content_fts(link_id, text) SELECT link_id, text FROM link_content",
+		"DELETE FROM quotes_fts",
+		"INSERT INTO quotes_fts(quote_id, link_id, text) SELECT id, link_id, text FROM quotes",
+		"DELETE FROM annotations_fts",
+		"INSERT INTO annotations_fts(annotation_id, link_id, text) SELECT id, link_id, text FROM annotations",
+		"INSERT INTO links_fts(links_fts) VALUES ('optimize')",
+		"INSERT INTO content_fts(content_fts) VALUES ('optimize')",
+		"INSERT INTO quotes_fts(quotes_fts) VALUES ('optimize')",
+		"INSERT INTO annotations_fts(annotations_fts) VALUES ('optimize')",
+	}
+	for _, stmt := range statements {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return 0, fmt.Errorf("reindex: %w", err)
+		}
+	}
+	var count int
+	if err := tx.GetContext(ctx, &count, "SELECT COUNT(*) FROM links_fts"); err != nil {
+		return 0, fmt.Errorf("reindex: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("reindex: %w", err)
+	}
+	return count, nil
+}
+
+// Stats returns aggregate counts across all links.
+func (s *SQLiteStorage) Stats(ctx context.Context) (*Stats, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	stats := &Stats{}
+	err := s.db.QueryRowxContext(ctx, `
+		SELECT
+			COUNT(*),
+			COALESCE(SUM(CASE WHEN read_at IS NULL THEN 1 ELSE 0 END), 0),
+			COALESCE(SUM(CASE WHEN read_at IS NOT NULL THEN 1 ELSE 0 END), 0),
+			COALESCE(SUM(CASE WHEN snoozed_until > ? THEN 1 ELSE 0 END), 0)
+		FROM links
+		WHERE `+notTrashed+`
+	`, now).Scan(&stats.Total, &stats.Unread, &stats.Read, &stats.Snoozed)
+	if err != nil {
+		return nil, fmt.Errorf("count links: %w", err)
+	}
+
+	err = s.db.SelectContext(ctx, &stats.Domains, `
+		SELECT domain AS name, COUNT(*) AS count
+		FROM links
+		WHERE domain != '' AND `+notTrashed+`
+		GROUP BY domain
+		ORDER BY count DESC, domain ASC
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("count domains: %w", err)
+	}
+
+	if stats.Tags, err = s.tagCounts(ctx, ListOptions{ReadStatus: ReadStatusAll}); err != nil {
+		return nil, err
+	}
+	if stats.Weekly, err = s.weeklyAdditions(ctx); err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+// TagCounts counts the links matching opts per tag, most used first.
+func (s *SQLiteStorage) TagCounts(ctx context.Context, opts ListOptions) ([]Count, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	return s.tagCounts(ctx, opts)
+}
+
+// tagCounts counts the links matching opts per tag. Tags are stored as
+// comma-separated text, so they are split here rather than in SQL. A tag
+// is reported with the spelling of its oldest use.
+func (s *SQLiteStorage) tagCounts(ctx context.Context, opts ListOptions) ([]Count, error) {
+	where, args := opts.conditions()
+	var rows []string
+	if err := s.db.SelectContext(ctx, &rows, "SELECT tags FROM links WHERE tags IS NOT NULL AND tags != ''"+where+" ORDER BY created_at, rowid", args...); err != nil {
+		return nil, fmt.Errorf("count tags: %w", err)
+	}
+
+	index := make(map[string]int)
+	counts := []Count{}
+	for _, tags := range rows {
+		for _, tag := range (&model.Link{Tags: tags}).TagList() {
+			key := strings.ToLower(tag)
+			if i, ok := index[key]; ok {
+				counts[i].Count++
+				continue
+			}
+			index[key] = len(counts)
+			counts = append(counts, Count{Name: tag, Count: 1})
+		}
+	}
+	sort.SliceStable(counts, func(i, j int) bool {
+		if counts[i].Count != counts[j].Count {
+			return counts[i].Count > counts[j].Count
+		}
+		return strings.ToLower(counts[i].Name) < strings.ToLower(counts[j].Name)
+	})
+	return counts, nil
+}
+
+// weeklyAdditions buckets links added in the last StatsWeeks weeks into
+// 7-day periods ending now, oldest first.
+func (s *SQLiteStorage) weeklyAdditions(ctx context.Context) ([]int, error) {
+	const week = 7 * 24 * time.Hour
+	now := time.Now().UTC()
+
+	// created_at has been stored in more than one layout, so the times
+	// are compared parsed rather than as text
+	var created []string
+	err := s.db.SelectContext(ctx, &created, "SELECT created_at FROM links WHERE "+notTrashed)
+	if err != nil {
+		return nil, fmt.Errorf("count weekly additions: %w", err)
+	}
+
+	weekly := make([]int, StatsWeeks)
+	for _, c := range created {
+		age := max(now.Sub(parseSQLiteTime(c)), 0)
+		if bucket := int(age / week); bucket < StatsWeeks {
+			weekly[StatsWeeks-1-bucket]++
+		}
+	}
+	return weekly, nil
+}
+
+// ReadLog returns every time a link was marked read, oldest first.
+func (s *SQLiteStorage) ReadLog(ctx context.Context) ([]ReadEvent, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	var rows []struct {
+		LinkID string `db:"link_id"`
+		ReadAt string `db:"read_at"`
+	}
+	err := s.db.SelectContext(ctx, &rows, "SELECT link_id, read_at FROM read_log ORDER BY datetime(read_at)")
+	if err != nil {
+		return nil, fmt.Errorf("read log: %w", err)
+	}
+
+	events := make([]ReadEvent, len(rows))
+	for i, r := range rows {
+		events[i] = ReadEvent{LinkID: r.LinkID, ReadAt: parseSQLiteTime(r.ReadAt)}
+	}
+	return events, nil
 }
 
 // Close closes the database connection.
@@ -394,6 +2192,15 @@ func (s *SQLiteStorage) Close() error {
 	return s.db.Close()
 }
 
+// formatNullTime formats an optional timestamp as UTC RFC3339 so stored
+// values compare correctly as strings.
+func formatNullTime(t *time.Time) sql.NullString {
+	if t == nil {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: t.UTC().Format(time.RFC3339), Valid: true}
+}
+
 func parseSQLiteTime(s string) time.Time {
 	if s == "" {
 		return time.Time{}
@@ -2,8 +2,10 @@ package storage
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
@@ -164,6 +166,82 @@ func TestList(t *testing.T) {
 	}
 }
 
+func TestAddRetriesTakenID(t *testing.T) {
+	s := setupTestDB(t)
+	defer s.Close()
+	ctx := context.Background()
+
+	// Every ID is taken until the generator moves on
+	ids := []string{"aaaaaaaa", "aaaaaaaa", "aaaaaaaa", "bbbbbbbb", "bbbbbbbb", "cccccccc"}
+	s.newID = func(time.Time) string {
+		id := ids[0]
+		ids = ids[1:]
+		return id
+	}
+
+	first, err := s.Add(ctx, &model.Link{URL: "https://example.com/1"})
+	if err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	second, err := s.Add(ctx, &model.Link{URL: "https://example.com/2"})
+	if err != nil {
+		t.Fatalf("Add with a taken ID failed: %v", err)
+	}
+	if _, err := s.Import(ctx, []*model.Link{{URL: "https://example.com/3"}}, ImportOptions{}); err != nil {
+		t.Fatalf("Import with a taken ID failed: %v", err)
+	}
+	third, err := s.FindByURL(ctx, "https://example.com/3")
+	if err != nil {
+		t.Fatalf("FindByURL failed: %v", err)
+	}
+	if first.ID != "aaaaaaaa" || second.ID != "bbbbbbbb" || third.ID != "cccccccc" {
+		t.Errorf("Expected each link to get the next free ID, got %s, %s, %s", first.ID, second.ID, third.ID)
+	}
+
+	s.newID = func(time.Time) string { return "aaaaaaaa" }
+	if _, err := s.Add(ctx, &model.Link{URL: "https://example.com/4"}); err == nil {
+		t.Error("Expected Add to give up when every ID is taken")
+	}
+}
+
+func TestIDSchemes(t *testing.T) {
+	s := setupTestDB(t)
+	defer s.Close()
+	ctx := context.Background()
+
+	s.SetIDScheme(model.IDSchemeRandom, 8)
+	short, err := s.Add(ctx, &model.Link{URL: "https://example.com/short"})
+	if err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if len(short.ID) != 8 {
+		t.Errorf("Expected an 8-character ID, got %q", short.ID)
+	}
+
+	// ULIDs follow the save time, even for links imported out of order
+	s.SetIDScheme(model.IDSchemeULID, 0)
+	base := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	links := []*model.Link{
+		{URL: "https://example.com/2", CreatedAt: base.Add(2 * time.Hour)},
+		{URL: "https://example.com/0", CreatedAt: base},
+		{URL: "https://example.com/1", CreatedAt: base.Add(time.Hour)},
+	}
+	if _, err := s.Import(ctx, links, ImportOptions{}); err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	if !(links[1].ID < links[2].ID && links[2].ID < links[0].ID) {
+		t.Errorf("Expected ULIDs in save order, got %s, %s, %s", links[1].ID, links[2].ID, links[0].ID)
+	}
+
+	// Links saved under the old scheme keep working
+	if _, err := s.Get(ctx, short.ID); err != nil {
+		t.Errorf("Get(%s) after switching schemes failed: %v", short.ID, err)
+	}
+	if id, err := s.ResolveID(ctx, links[0].ID[:20]); err != nil || id != links[0].ID {
+		t.Errorf("ResolveID(prefix of a ULID) = %q, %v", id, err)
+	}
+}
+
 func TestListUnread(t *testing.T) {
 	s := setupTestDB(t)
 	defer s.Close()
@@ -250,6 +328,52 @@ func TestDelete(t *testing.T) {
 	}
 }
 
+func TestDeleteSideRows(t *testing.T) {
+	s := setupTestDB(t)
+	defer s.Close()
+	ctx := context.Background()
+
+	keep, _ := s.Add(ctx, &model.Link{URL: "https://example.com/keep"})
+	gone, _ := s.Add(ctx, &model.Link{URL: "https://example.com/gone"})
+	if _, err := s.AddAnnotation(ctx, gone.ID, "a note"); err != nil {
+		t.Fatalf("AddAnnotation failed: %v", err)
+	}
+	if _, err := s.SetURL(ctx, gone.ID, "https://example.com/moved"); err != nil {
+		t.Fatalf("SetURL failed: %v", err)
+	}
+
+	// A side table that can't be cleared leaves the link as it was
+	if _, err := s.db.Exec("ALTER TABLE embeddings RENAME TO embeddings_away"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Delete(ctx, gone.ID); err == nil {
+		t.Fatal("Delete succeeded without the embeddings table")
+	}
+	if _, err := s.Get(ctx, gone.ID); err != nil {
+		t.Fatalf("Get after failed delete: %v", err)
+	}
+	if texts := noteTexts(t, s, gone.ID); len(texts) != 1 {
+		t.Errorf("notes after failed delete = %q, want the note kept", texts)
+	}
+	if _, err := s.db.Exec("ALTER TABLE embeddings_away RENAME TO embeddings"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.Delete(ctx, gone.ID); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	orphans, err := s.Orphans(ctx)
+	if err != nil {
+		t.Fatalf("Orphans failed: %v", err)
+	}
+	if len(orphans) != 0 {
+		t.Errorf("rows left behind by Delete: %+v", orphans)
+	}
+	if _, err := s.Get(ctx, keep.ID); err != nil {
+		t.Errorf("other link: %v", err)
+	}
+}
+
 func TestExportImport(t *testing.T) {
 	s := setupTestDB(t)
 	defer s.Close()
@@ -282,7 +406,7 @@ func TestExportImport(t *testing.T) {
 	s2 := setupTestDB(t)
 	defer s2.Close()
 
-	if err := s2.Import(ctx, exported); err != nil {
+	if _, err := s2.Import(ctx, exported, ImportOptions{}); err != nil {
 		t.Fatalf("Import failed: %v", err)
 	}
 
@@ -324,14 +448,14 @@ func TestImportDuplicate(t *testing.T) {
 
 	// Import with same URL but different fields
 	link2 := &model.Link{
-		URL:       "https://example.com",
-		Title:     "Updated",
-		Note:      "New note",
-		Tags:      "tag2",
-		CreatedAt: time.Now(),
+		URL:         "https://example.com",
+		Title:       "Updated",
+		Annotations: []*model.Annotation{{Text: "New note"}},
+		Tags:        "tag2",
+		CreatedAt:   time.Now(),
 	}
 
-	if err := s.Import(ctx, []*model.Link{link2}); err != nil {
+	if _, err := s.Import(ctx, []*model.Link{link2}, ImportOptions{}); err != nil {
 		t.Fatalf("Import failed: %v", err)
 	}
 
@@ -352,8 +476,8 @@ func TestImportDuplicate(t *testing.T) {
 	if retrieved.Title == "" {
 		t.Error("Expected title to be set")
 	}
-	if retrieved.Note != "New note" { // Should add new note
-		t.Errorf("Expected note 'New note', got '%s'", retrieved.Note)
+	if got := noteTexts(t, s, retrieved.ID); !reflect.DeepEqual(got, []string{"New note"}) { // Should add new note
+		t.Errorf("Expected note 'New note', got %q", got)
 	}
 	// Tags should be merged
 	if retrieved.Tags == "" {
@@ -361,39 +485,2244 @@ func TestImportDuplicate(t *testing.T) {
 	}
 }
 
+func TestImportAliasedURL(t *testing.T) {
+	s := setupTestDB(t)
+	defer s.Close()
+	ctx := context.Background()
+
+	link, err := s.Add(ctx, &model.Link{URL: "https://example.com/old", Tags: "go"})
+	if err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if _, err := s.SetURL(ctx, link.ID, "https://example.com/new"); err != nil {
+		t.Fatalf("SetURL failed: %v", err)
+	}
+
+	// An export from before the move still has the old URL
+	results, err := s.Import(ctx, []*model.Link{{URL: "https://example.com/old", Tags: "rust"}}, ImportOptions{})
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	if len(results) != 1 || results[0].Status != ImportMerged || results[0].ID != link.ID {
+		t.Fatalf("results = %+v, want %s merged", results, link.ID)
+	}
+	links, _ := s.List(ctx, ListOptions{ReadStatus: ReadStatusAll})
+	if len(links) != 1 {
+		t.Fatalf("got %d links, want the moved link only", len(links))
+	}
+	if links[0].URL != "https://example.com/new" || links[0].Tags != "go,rust" {
+		t.Errorf("link = %s %q, want the new URL with tags merged", links[0].URL, links[0].Tags)
+	}
+}
+
 func TestSearch(t *testing.T) {
-	// Use temp file instead of :memory: for FTS5 to work correctly
-	tmpfile, err := os.CreateTemp("", "rl_test_search_*.db")
+	s := setupTestDB(t)
+	defer s.Close()
+
+	ctx := context.Background()
+	link, err := s.Add(ctx, &model.Link{
+		URL:         "https://example.com",
+		Title:       "Example Title",
+		Annotations: []*model.Annotation{{Text: "This is a test note"}},
+		Tags:        "test,example",
+	})
 	if err != nil {
-		t.Fatalf("Failed to create temp file: %v", err)
+		t.Fatalf("Add failed: %v", err)
+	}
+	other, err := s.Add(ctx, &model.Link{URL: "https://other.org", Title: "Unrelated"})
+	if err != nil {
+		t.Fatalf("Add failed: %v", err)
 	}
-	tmpfile.Close()
-	defer os.Remove(tmpfile.Name())
 
-	s, err := NewSQLiteStorage(tmpfile.Name())
+	search := func(query string) []*model.Link {
+		t.Helper()
+		results, err := s.Search(ctx, query, SearchOptions{})
+		if err != nil {
+			t.Fatalf("Search(%q) failed: %v", query, err)
+		}
+		return results
+	}
+
+	if results := search("example"); len(results) != 1 || results[0].ID != link.ID {
+		t.Fatalf("Expected one match for 'example', got %+v", results)
+	}
+
+	// Re-adding rewrites the row; the index must follow
+	if _, err := s.Add(ctx, &model.Link{URL: "https://example.com", Annotations: []*model.Annotation{{Text: "mentions kubernetes"}}}); err != nil {
+		t.Fatalf("Re-add failed: %v", err)
+	}
+	if results := search("kubernetes"); len(results) != 1 || results[0].ID != link.ID {
+		t.Errorf("Expected the merged note to be indexed, got %+v", results)
+	}
+	if results := search("example"); len(results) != 1 {
+		t.Errorf("Expected exactly one row per link after re-adding, got %d", len(results))
+	}
+
+	updated, _ := s.Get(ctx, other.ID)
+	updated.Title = "Renamed zebra"
+	if err := s.Update(ctx, updated); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if results := search("zebra"); len(results) != 1 || results[0].ID != other.ID {
+		t.Errorf("Expected the updated title to be indexed, got %+v", results)
+	}
+	if results := search("unrelated"); len(results) != 0 {
+		t.Errorf("Expected the old title to be dropped from the index, got %+v", results)
+	}
+
+	// A read status keeps the links a list with that status shows
+	if err := s.MarkRead(ctx, other.ID); err != nil {
+		t.Fatal(err)
+	}
+	for status, want := range map[ReadStatus]int{ReadStatusUnread: 0, ReadStatusRead: 1, ReadStatusAll: 1} {
+		results, err := s.Search(ctx, "zebra", SearchOptions{ReadStatus: &status})
+		if err != nil || len(results) != want {
+			t.Errorf("Search(status %d) = %d links, %v; want %d", status, len(results), err, want)
+		}
+	}
+
+	if err := s.Delete(ctx, link.ID); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if results := search("example"); len(results) != 0 {
+		t.Errorf("Deleted link still matches: %+v", results)
+	}
+}
+
+func TestSearchBoolean(t *testing.T) {
+	s := setupTestDB(t)
+	defer s.Close()
+
+	ctx := context.Background()
+	ids := make(map[string]string)
+	for _, l := range []*model.Link{
+		{URL: "https://a.example/loop", Title: "The event loop in Rust", Tags: "rust"},
+		{URL: "https://b.example/go", Title: "Go scheduler internals", Description: "event driven"},
+		{URL: "https://c.example/tokio", Title: "Tokio event loop", Tags: "rust,async"},
+	} {
+		link, err := s.Add(ctx, l)
+		if err != nil {
+			t.Fatalf("Add failed: %v", err)
+		}
+		ids[l.URL] = link.ID
+	}
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{`"event loop"`, []string{"https://a.example/loop", "https://c.example/tokio"}},
+		{`"event loop" NOT tokio`, []string{"https://a.example/loop"}},
+		{`scheduler OR tokio`, []string{"https://b.example/go", "https://c.example/tokio"}},
+		{`event (go OR async)`, []string{"https://b.example/go", "https://c.example/tokio"}},
+		{`title:event`, []string{"https://a.example/loop", "https://c.example/tokio"}},
+		{`sched*`, []string{"https://b.example/go"}},
+	}
+	for _, tt := range tests {
+		results, err := s.Search(ctx, tt.query, SearchOptions{Content: true})
+		if err != nil {
+			t.Errorf("Search(%q) failed: %v", tt.query, err)
+			continue
+		}
+		got := make(map[string]bool)
+		for _, r := range results {
+			got[r.ID] = true
+		}
+		if len(got) != len(tt.want) {
+			t.Errorf("Search(%q) returned %d links, want %d", tt.query, len(got), len(tt.want))
+			continue
+		}
+		for _, u := range tt.want {
+			if !got[ids[u]] {
+				t.Errorf("Search(%q) is missing %s", tt.query, u)
+			}
+		}
+	}
+
+	// An unterminated quote runs to the end rather than failing
+	if _, err := s.Search(ctx, `"event loop`, SearchOptions{}); err != nil {
+		t.Errorf("Search with an unterminated quote failed: %v", err)
+	}
+	for _, query := range []string{`rust AND`, `(rust`, `OR`} {
+		if _, err := s.Search(ctx, query, SearchOptions{}); !errors.Is(err, model.ErrInvalidQuery) {
+			t.Errorf("Search(%q) error = %v, want ErrInvalidQuery", query, err)
+		}
+	}
+}
+
+func TestSearchApproximate(t *testing.T) {
+	s := setupTestDB(t)
+	defer s.Close()
+
+	ctx := context.Background()
+	kube, err := s.Add(ctx, &model.Link{URL: "https://k8s.example/guide", Title: "Kubernetes networking guide"})
 	if err != nil {
-		t.Fatalf("Failed to create test storage: %v", err)
+		t.Fatalf("Add failed: %v", err)
+	}
+	if _, err := s.Add(ctx, &model.Link{URL: "https://go.example/blog", Title: "Go generics"}); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	tests := []struct {
+		query string
+		want  int
+	}{
+		{"kubernets", 1},
+		{"networkng guide", 1},
+		{"k8s", 1},
+		{"kubernets NOT generics", 1},
+		{"zebra", 0},
+	}
+	for _, tt := range tests {
+		results, err := s.SearchApproximate(ctx, tt.query, SearchOptions{}, 10)
+		if err != nil {
+			t.Fatalf("SearchApproximate(%q) failed: %v", tt.query, err)
+		}
+		if len(results) != tt.want {
+			t.Errorf("SearchApproximate(%q) returned %d links, want %d", tt.query, len(results), tt.want)
+		} else if tt.want == 1 && results[0].ID != kube.ID {
+			t.Errorf("SearchApproximate(%q) = %s, want %s", tt.query, results[0].ID, kube.ID)
+		}
 	}
+}
+
+func TestSearchRanking(t *testing.T) {
+	s := setupTestDB(t)
 	defer s.Close()
 
 	ctx := context.Background()
+	inNote, _ := s.Add(ctx, &model.Link{URL: "https://a.example", Title: "Weekly links", Annotations: []*model.Annotation{{Text: "has a section on sqlite internals"}}})
+	inTitle, _ := s.Add(ctx, &model.Link{URL: "https://b.example", Title: "SQLite internals"})
+	inContent, _ := s.Add(ctx, &model.Link{URL: "https://c.example", Title: "Databases"})
+	if err := s.SetContent(ctx, inContent.ID, "A long article. The query planner in SQLite flattens subqueries."); err != nil {
+		t.Fatalf("SetContent failed: %v", err)
+	}
 
-	link := &model.Link{
-		URL:   "https://example.com",
-		Title: "Example Title",
-		Note:  "This is a test note",
-		Tags:  "test,example",
+	results, err := s.Search(ctx, "sqlite", SearchOptions{Content: true})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("Expected 3 results, got %d", len(results))
+	}
+	// The title match ranks first even though it isn't the newest
+	if results[0].ID != inTitle.ID {
+		t.Errorf("Expected the title match first, got %s", results[0].URL)
+	}
+
+	want := map[string]string{inTitle.ID: "title", inNote.ID: "note", inContent.ID: "content"}
+	for _, r := range results {
+		if r.Match == nil {
+			t.Fatalf("Result %s has no match", r.URL)
+		}
+		if r.Match.Field != want[r.ID] {
+			t.Errorf("%s matched in %q, want %q", r.URL, r.Match.Field, want[r.ID])
+		}
+		if len(r.Match.Highlights) != 1 {
+			t.Errorf("%s: expected one highlight in %q, got %v", r.URL, r.Match.Snippet, r.Match.Highlights)
+			continue
+		}
+		h := r.Match.Highlights[0]
+		if got := r.Match.Snippet[h[0]:h[1]]; !strings.EqualFold(got, "sqlite") {
+			t.Errorf("%s: highlighted %q in %q", r.URL, got, r.Match.Snippet)
+		}
+	}
+}
+
+func TestReadLog(t *testing.T) {
+	s := setupTestDB(t)
+	defer s.Close()
+
+	ctx := context.Background()
+	link, _ := s.Add(ctx, &model.Link{URL: "https://example.com"})
+	if err := s.MarkRead(ctx, link.ID); err != nil {
+		t.Fatalf("MarkRead failed: %v", err)
+	}
+	readAt := time.Now().Add(-24 * time.Hour)
+	if _, err := s.Import(ctx, []*model.Link{{URL: "https://imported.example", ReadAt: &readAt}}, ImportOptions{}); err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	// Marking unread and deleting keep the history
+	if err := s.MarkUnread(ctx, link.ID); err != nil {
+		t.Fatalf("MarkUnread failed: %v", err)
+	}
+	if err := s.Delete(ctx, link.ID); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+
+	events, err := s.ReadLog(ctx)
+	if err != nil {
+		t.Fatalf("ReadLog failed: %v", err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("Expected 2 read events, got %+v", events)
+	}
+	if events[1].LinkID != link.ID || time.Since(events[1].ReadAt) > time.Minute {
+		t.Errorf("Expected the link just read last, got %+v", events[1])
+	}
+	if !events[0].ReadAt.Before(events[1].ReadAt) {
+		t.Errorf("Expected events oldest first, got %+v", events)
+	}
+}
+
+func TestArchive(t *testing.T) {
+	s := setupTestDB(t)
+	defer s.Close()
+
+	ctx := context.Background()
+	link, _ := s.Add(ctx, &model.Link{URL: "https://example.com"})
+	if err := s.Archive(ctx, link.ID); err != nil {
+		t.Fatalf("Archive failed: %v", err)
+	}
+	archived, _ := s.Get(ctx, link.ID)
+	if archived.ReadAt == nil || archived.ArchivedAt == nil {
+		t.Error("Expected an archived link to be read and archived")
+	}
+	if events, _ := s.ReadLog(ctx); len(events) != 0 {
+		t.Errorf("Archiving should not be logged as reading, got %+v", events)
+	}
+	if err := s.Archive(ctx, "zzzzzzzzzzzzzzzzzzzzzzzzzz"); !errors.Is(err, model.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for a missing link, got %v", err)
+	}
+
+	// A read link keeps the time it was read
+	readAt := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	read, _ := s.Add(ctx, &model.Link{URL: "https://example.com/read", ReadAt: &readAt})
+	if err := s.Archive(ctx, read.ID); err != nil {
+		t.Fatalf("Archive failed: %v", err)
+	}
+	if got, _ := s.Get(ctx, read.ID); got.ReadAt == nil || !got.ReadAt.Equal(readAt) || got.ArchivedAt == nil {
+		t.Errorf("Expected the read time kept and the link archived, got %+v", got)
+	}
+
+	count := func(status ReadStatus) int {
+		n, err := s.Count(ctx, ListOptions{ReadStatus: status})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return n
+	}
+	if count(ReadStatusRead) != 0 || count(ReadStatusArchived) != 2 || count(ReadStatusAll) != 2 {
+		t.Errorf("Expected archived links only among archived and all links, got %d read, %d archived",
+			count(ReadStatusRead), count(ReadStatusArchived))
+	}
+
+	// Marked unread, it's back in the queue and out of the archive
+	if err := s.MarkUnread(ctx, read.ID); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := s.Get(ctx, read.ID); got.ArchivedAt != nil || count(ReadStatusUnread) != 1 {
+		t.Errorf("Expected marking unread to unarchive, got %+v", got)
+	}
+}
+
+func TestTrash(t *testing.T) {
+	s := setupTestDB(t)
+	defer s.Close()
+
+	ctx := context.Background()
+	link, _ := s.Add(ctx, &model.Link{URL: "https://example.com/trash", Title: "Trashed page", Tags: "gone"})
+	kept, _ := s.Add(ctx, &model.Link{URL: "https://example.com/kept"})
+	if err := s.Trash(ctx, link.ID); err != nil {
+		t.Fatalf("Trash failed: %v", err)
+	}
+	trashed, err := s.Get(ctx, link.ID)
+	if err != nil || trashed.TrashedAt == nil {
+		t.Fatalf("Expected the link kept in the trash, got %+v (%v)", trashed, err)
+	}
+	if err := s.Trash(ctx, "zzzzzzzzzzzzzzzzzzzzzzzzzz"); !errors.Is(err, model.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for a missing link, got %v", err)
+	}
+
+	// Trashed links are left out of listings, searches, and stats
+	links, _ := s.List(ctx, ListOptions{ReadStatus: ReadStatusAll})
+	if len(links) != 1 || links[0].ID != kept.ID {
+		t.Errorf("Expected only the kept link listed, got %d links", len(links))
+	}
+	if results, _ := s.Search(ctx, "Trashed", SearchOptions{}); len(results) != 0 {
+		t.Errorf("Expected a trashed link not to be found, got %d results", len(results))
+	}
+	if stats, _ := s.Stats(ctx); stats.Total != 1 || len(stats.Tags) != 0 {
+		t.Errorf("Expected stats without the trashed link, got %+v", stats)
+	}
+	if links, _ := s.List(ctx, ListOptions{ReadStatus: ReadStatusAll, Trashed: true}); len(links) != 1 || links[0].ID != link.ID {
+		t.Errorf("Expected the trash to list the trashed link, got %d links", len(links))
+	}
+
+	if err := s.Restore(ctx, link.ID); err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+	if got, _ := s.Get(ctx, link.ID); got.TrashedAt != nil {
+		t.Error("Expected Restore to take the link out of the trash")
+	}
+
+	// Saving a trashed link again restores it
+	if err := s.Trash(ctx, link.ID); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := s.Add(ctx, &model.Link{URL: "https://example.com/trash"}); got.ID != link.ID || got.TrashedAt != nil {
+		t.Errorf("Expected saving the URL again to restore the link, got %+v", got)
+	}
+}
+
+func TestExcerpts(t *testing.T) {
+	kinds := []struct {
+		name   string
+		add    func(s *SQLiteStorage, ctx context.Context, linkID, text string) (*model.Excerpt, error)
+		list   func(s *SQLiteStorage, ctx context.Context, linkID string) ([]*model.Excerpt, error)
+		delete func(s *SQLiteStorage, ctx context.Context, id int64) error
+	}{
+		{
+			name: "annotations",
+			add: func(s *SQLiteStorage, ctx context.Context, linkID, text string) (*model.Excerpt, error) {
+				a, err := s.AddAnnotation(ctx, linkID, text)
+				return (*model.Excerpt)(a), err
+			},
+			list: func(s *SQLiteStorage, ctx context.Context, linkID string) ([]*model.Excerpt, error) {
+				annotations, err := s.Annotations(ctx, linkID)
+				excerpts := make([]*model.Excerpt, len(annotations))
+				for i, a := range annotations {
+					excerpts[i] = (*model.Excerpt)(a)
+				}
+				return excerpts, err
+			},
+			delete: (*SQLiteStorage).DeleteAnnotation,
+		},
+		{
+			name: "quotes",
+			add: func(s *SQLiteStorage, ctx context.Context, linkID, text string) (*model.Excerpt, error) {
+				q, err := s.AddQuote(ctx, linkID, text)
+				return (*model.Excerpt)(q), err
+			},
+			list: func(s *SQLiteStorage, ctx context.Context, linkID string) ([]*model.Excerpt, error) {
+				quotes, err := s.Quotes(ctx, linkID)
+				excerpts := make([]*model.Excerpt, len(quotes))
+				for i, q := range quotes {
+					excerpts[i] = (*model.Excerpt)(q)
+				}
+				return excerpts, err
+			},
+			delete: (*SQLiteStorage).DeleteQuote,
+		},
+	}
+
+	for _, kind := range kinds {
+		t.Run(kind.name, func(t *testing.T) {
+			s := setupTestDB(t)
+			defer s.Close()
+
+			ctx := context.Background()
+			a, _ := s.Add(ctx, &model.Link{URL: "https://a.example", Title: "Essay"})
+			b, _ := s.Add(ctx, &model.Link{URL: "https://b.example", Title: "Paper"})
+			first, err := kind.add(s, ctx, a.ID, "Simplicity is prerequisite for reliability")
+			if err != nil {
+				t.Fatalf("add failed: %v", err)
+			}
+			if _, err := kind.add(s, ctx, b.ID, "Premature optimization is the root of all evil"); err != nil {
+				t.Fatalf("add failed: %v", err)
+			}
+			if _, err := kind.add(s, ctx, "zzzzzzzzzzzzzzzzzzzzzzzzzz", "x"); !errors.Is(err, model.ErrNotFound) {
+				t.Errorf("Expected ErrNotFound for a missing link, got %v", err)
+			}
+
+			// Re-adding the URL rewrites the link row; excerpts must survive
+			if _, err := s.Add(ctx, &model.Link{URL: "https://a.example", Tags: "later"}); err != nil {
+				t.Fatalf("Re-add failed: %v", err)
+			}
+			all, err := kind.list(s, ctx, "")
+			if err != nil {
+				t.Fatalf("list failed: %v", err)
+			}
+			if len(all) != 2 || all[0].ID != first.ID || all[0].LinkID != a.ID {
+				t.Fatalf("Expected both oldest first, got %+v", all)
+			}
+			if excerpts, _ := kind.list(s, ctx, b.ID); len(excerpts) != 1 || excerpts[0].LinkID != b.ID {
+				t.Errorf("Expected one for the second link, got %+v", excerpts)
+			}
+
+			if err := kind.delete(s, ctx, first.ID); err != nil {
+				t.Fatalf("delete failed: %v", err)
+			}
+			if err := kind.delete(s, ctx, first.ID); !errors.Is(err, model.ErrNotFound) {
+				t.Errorf("Expected ErrNotFound deleting twice, got %v", err)
+			}
+
+			if err := s.Delete(ctx, b.ID); err != nil {
+				t.Fatalf("Delete failed: %v", err)
+			}
+			if excerpts, _ := kind.list(s, ctx, ""); len(excerpts) != 0 {
+				t.Errorf("Expected them deleted with their link, got %+v", excerpts)
+			}
+		})
+	}
+}
+
+func TestExcerptSearch(t *testing.T) {
+	s := setupTestDB(t)
+	defer s.Close()
+
+	ctx := context.Background()
+	link, _ := s.Add(ctx, &model.Link{URL: "https://a.example", Title: "Essay"})
+	tests := map[string]struct {
+		text  string
+		field string
+		add   func(text string) (int64, error)
+		del   func(id int64) error
+	}{
+		"quote": {
+			text:  "Simplicity is prerequisite for reliability",
+			field: "quote",
+			add: func(text string) (int64, error) {
+				q, err := s.AddQuote(ctx, link.ID, text)
+				if err != nil {
+					return 0, err
+				}
+				return q.ID, nil
+			},
+			del: func(id int64) error { return s.DeleteQuote(ctx, id) },
+		},
+		"annotation": {
+			text:  "Compare with the chapter on backpressure",
+			field: "note",
+			add: func(text string) (int64, error) {
+				a, err := s.AddAnnotation(ctx, link.ID, text)
+				if err != nil {
+					return 0, err
+				}
+				return a.ID, nil
+			},
+			del: func(id int64) error { return s.DeleteAnnotation(ctx, id) },
+		},
+	}
+	for name, tt := range tests {
+		id, err := tt.add(tt.text)
+		if err != nil {
+			t.Fatalf("%s: add failed: %v", name, err)
+		}
+		word := tt.text[strings.LastIndex(tt.text, " ")+1:]
+		results, err := s.Search(ctx, word, SearchOptions{})
+		if err != nil {
+			t.Fatalf("%s: Search failed: %v", name, err)
+		}
+		if len(results) != 1 || results[0].ID != link.ID || results[0].Match.Field != tt.field {
+			t.Fatalf("%s: expected the link with a %s match, got %+v", name, tt.field, results)
+		}
+		if err := tt.del(id); err != nil {
+			t.Fatalf("%s: delete failed: %v", name, err)
+		}
+		if results, _ := s.Search(ctx, word, SearchOptions{}); len(results) != 0 {
+			t.Errorf("%s: expected a deleted %s to leave the index, got %d results", name, name, len(results))
+		}
 	}
-	s.Add(ctx, link)
+}
+
+func TestFetchState(t *testing.T) {
+	s := setupTestDB(t)
+	defer s.Close()
 
-	// FTS5 search - skip if not working (known issue with FTS5 in test environment)
-	results, err := s.Search(ctx, "example")
+	ctx := context.Background()
+	link, _ := s.Add(ctx, &model.Link{URL: "https://example.com"})
+	if _, err := s.FetchState(ctx, link.ID); !errors.Is(err, model.ErrNotFound) {
+		t.Fatalf("Expected ErrNotFound before any fetch, got %v", err)
+	}
+
+	fetchedAt := time.Date(2024, 1, 31, 12, 0, 0, 0, time.UTC)
+	want := FetchState{LinkID: link.ID, ETag: `"abc"`, LastModified: "Wed, 31 Jan 2024 11:00:00 GMT", FetchedAt: fetchedAt}
+	if err := s.SetFetchState(ctx, want); err != nil {
+		t.Fatalf("SetFetchState failed: %v", err)
+	}
+	got, err := s.FetchState(ctx, link.ID)
 	if err != nil {
-		t.Skipf("FTS5 search not available in test environment: %v", err)
-		return
+		t.Fatalf("FetchState failed: %v", err)
 	}
+	if *got != want {
+		t.Errorf("Expected %+v, got %+v", want, *got)
+	}
+
+	if err := s.Delete(ctx, link.ID); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, err := s.FetchState(ctx, link.ID); !errors.Is(err, model.ErrNotFound) {
+		t.Errorf("Expected the fetch state to be deleted with the link, got %v", err)
+	}
+}
 
-	// If search works but returns no results, that's acceptable for now
-	// FTS5 indexing may have timing issues in test environment
-	_ = results
+func TestSummary(t *testing.T) {
+	s := setupTestDB(t)
+	defer s.Close()
+
+	ctx := context.Background()
+	link, _ := s.Add(ctx, &model.Link{URL: "https://example.com"})
+	if _, err := s.Summary(ctx, link.ID); !errors.Is(err, model.ErrNotFound) {
+		t.Fatalf("Expected ErrNotFound before summarizing, got %v", err)
+	}
+
+	want := model.Summary{LinkID: link.ID, Text: "A short summary.", Model: "llama3.2", CreatedAt: time.Date(2024, 1, 31, 12, 0, 0, 0, time.UTC)}
+	if err := s.SetSummary(ctx, &want); err != nil {
+		t.Fatalf("SetSummary failed: %v", err)
+	}
+	want.Text = "A better summary."
+	if err := s.SetSummary(ctx, &want); err != nil {
+		t.Fatalf("SetSummary failed: %v", err)
+	}
+	got, err := s.Summary(ctx, link.ID)
+	if err != nil {
+		t.Fatalf("Summary failed: %v", err)
+	}
+	if *got != want {
+		t.Errorf("Expected %+v, got %+v", want, *got)
+	}
+	all, err := s.Summaries(ctx)
+	if err != nil {
+		t.Fatalf("Summaries failed: %v", err)
+	}
+	if len(all) != 1 || all[link.ID] == nil || *all[link.ID] != want {
+		t.Errorf("Expected the one summary keyed by its link, got %+v", all)
+	}
+
+	if err := s.Delete(ctx, link.ID); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, err := s.Summary(ctx, link.ID); !errors.Is(err, model.ErrNotFound) {
+		t.Errorf("Expected the summary to be deleted with the link, got %v", err)
+	}
+}
+
+func TestReorder(t *testing.T) {
+	s := setupTestDB(t)
+	defer s.Close()
+
+	ctx := context.Background()
+	base := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	var ids []string
+	for i := range 5 {
+		link, err := s.Add(ctx, &model.Link{URL: fmt.Sprintf("https://example.com/%d", i), CreatedAt: base.Add(time.Duration(i) * time.Hour)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, link.ID)
+	}
+	queue := func() []string {
+		links, err := s.List(ctx, ListOptions{ReadStatus: ReadStatusUnread})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return listingIDs(links)
+	}
+	// Newest first: 4 3 2 1 0
+
+	if place, n, err := s.Reorder(ctx, ids[1], -2); err != nil || place != 1 || n != 5 {
+		t.Fatalf("Reorder() = %d, %d, %v; want 1, 5", place, n, err)
+	}
+	if want := []string{ids[4], ids[1], ids[3], ids[2], ids[0]}; !reflect.DeepEqual(queue(), want) {
+		t.Errorf("After moving up, queue = %v, want %v", queue(), want)
+	}
+
+	// Links down to where the moved one was keep their places; a new
+	// link, even a high-priority one, goes after them, ahead of the rest
+	late, _ := s.Add(ctx, &model.Link{URL: "https://example.com/late", CreatedAt: base.Add(time.Hour), Priority: model.PriorityHigh})
+	if want := []string{ids[4], ids[1], ids[3], ids[2], late.ID, ids[0]}; !reflect.DeepEqual(queue(), want) {
+		t.Errorf("After adding, queue = %v, want %v", queue(), want)
+	}
+
+	if place, _, err := s.Reorder(ctx, ids[4], 10); err != nil || place != 5 {
+		t.Fatalf("Reorder() to the bottom = %d, %v", place, err)
+	}
+	if want := []string{ids[1], ids[3], ids[2], late.ID, ids[0], ids[4]}; !reflect.DeepEqual(queue(), want) {
+		t.Errorf("After moving to the bottom, queue = %v, want %v", queue(), want)
+	}
+
+	if err := s.MarkRead(ctx, ids[2]); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := s.Reorder(ctx, ids[2], -1); err == nil {
+		t.Error("Expected an error moving a read link")
+	}
+	if _, _, err := s.Reorder(ctx, "zzzzzzzz", -1); !errors.Is(err, model.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for a missing link, got %v", err)
+	}
+
+	// Unread again, it goes after the links moved into place rather than
+	// back to its old one
+	if err := s.MarkUnread(ctx, ids[2]); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{ids[1], ids[3], late.ID, ids[0], ids[4], ids[2]}; !reflect.DeepEqual(queue(), want) {
+		t.Errorf("After marking unread, queue = %v, want %v", queue(), want)
+	}
+
+	// A snoozed link keeps its place while others move, back ahead of
+	// the links ordered after it when it wakes
+	if err := s.Snooze(ctx, ids[3], time.Now().Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := s.Reorder(ctx, ids[2], -10); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Snooze(ctx, ids[3], time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{ids[2], ids[3], ids[1], late.ID, ids[0], ids[4]}; !reflect.DeepEqual(queue(), want) {
+		t.Errorf("After waking, queue = %v, want %v", queue(), want)
+	}
+	// A link merged into one that was never moved hands it its place
+	fresh, _ := s.Add(ctx, &model.Link{URL: "https://example.com/fresh", CreatedAt: base})
+	if _, err := s.Merge(ctx, fresh.ID, []string{ids[2]}); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{fresh.ID, ids[3], ids[1], late.ID, ids[0], ids[4]}; !reflect.DeepEqual(queue(), want) {
+		t.Errorf("After merging, queue = %v, want %v", queue(), want)
+	}
+}
+
+func listingIDs(links []*model.Link) []string {
+	ids := make([]string, len(links))
+	for i, link := range links {
+		ids[i] = link.ID
+	}
+	return ids
+}
+
+func TestEmbeddings(t *testing.T) {
+	s := setupTestDB(t)
+	defer s.Close()
+
+	ctx := context.Background()
+	a, _ := s.Add(ctx, &model.Link{URL: "https://example.com/a"})
+	b, _ := s.Add(ctx, &model.Link{URL: "https://example.com/b"})
+	want := &model.Embedding{LinkID: a.ID, Model: "m1", TextHash: "h", Vector: []float32{0.5, -1, 3e-8}, CreatedAt: time.Date(2024, 1, 31, 12, 0, 0, 0, time.UTC)}
+	if err := s.SetEmbedding(ctx, want); err != nil {
+		t.Fatalf("SetEmbedding failed: %v", err)
+	}
+	if err := s.SetEmbedding(ctx, &model.Embedding{LinkID: b.ID, Model: "m2", Vector: []float32{1}}); err != nil {
+		t.Fatalf("SetEmbedding failed: %v", err)
+	}
+
+	got, err := s.Embeddings(ctx, "m1")
+	if err != nil {
+		t.Fatalf("Embeddings failed: %v", err)
+	}
+	if len(got) != 1 || !reflect.DeepEqual(got[0], want) {
+		t.Errorf("Expected only %+v, got %+v", want, got)
+	}
+
+	if err := s.Delete(ctx, a.ID); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if got, _ := s.Embeddings(ctx, "m1"); len(got) != 0 {
+		t.Errorf("Expected the embedding to be deleted with the link, got %+v", got)
+	}
+}
+
+func TestAttachments(t *testing.T) {
+	s := setupTestDB(t)
+	defer s.Close()
+
+	ctx := context.Background()
+	link, _ := s.Add(ctx, &model.Link{URL: "https://example.com/paper.pdf"})
+	if _, err := s.Attachment(ctx, link.ID); !errors.Is(err, model.ErrNotFound) {
+		t.Fatalf("Expected ErrNotFound before saving a copy, got %v", err)
+	}
+
+	want := model.Attachment{
+		LinkID:      link.ID,
+		SHA256:      "ab12",
+		Path:        "ab/ab12.pdf",
+		ContentType: "application/pdf",
+		Size:        2048,
+		CreatedAt:   time.Date(2024, 1, 31, 12, 0, 0, 0, time.UTC),
+	}
+	if err := s.SetAttachment(ctx, &want); err != nil {
+		t.Fatalf("SetAttachment failed: %v", err)
+	}
+	got, err := s.Attachment(ctx, link.ID)
+	if err != nil {
+		t.Fatalf("Attachment failed: %v", err)
+	}
+	if *got != want {
+		t.Errorf("Expected %+v, got %+v", want, *got)
+	}
+
+	// A new copy replaces the old one
+	want.Path, want.SHA256 = "cd/cd34.pdf", "cd34"
+	if err := s.SetAttachment(ctx, &want); err != nil {
+		t.Fatalf("SetAttachment failed: %v", err)
+	}
+	all, err := s.Attachments(ctx)
+	if err != nil {
+		t.Fatalf("Attachments failed: %v", err)
+	}
+	if len(all) != 1 || all[0].Path != "cd/cd34.pdf" {
+		t.Errorf("Expected only the replacement copy, got %d attachments", len(all))
+	}
+
+	if err := s.Delete(ctx, link.ID); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, err := s.Attachment(ctx, link.ID); !errors.Is(err, model.ErrNotFound) {
+		t.Errorf("Expected the attachment to be deleted with the link, got %v", err)
+	}
+}
+
+func TestReindex(t *testing.T) {
+	s := setupTestDB(t)
+	defer s.Close()
+
+	ctx := context.Background()
+	link, err := s.Add(ctx, &model.Link{URL: "https://example.com/r", Title: "Reindexed"})
+	if err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if err := s.SetContent(ctx, link.ID, "body text"); err != nil {
+		t.Fatalf("SetContent failed: %v", err)
+	}
+
+	// Simulate an index that drifted out of sync
+	if _, err := s.db.Exec("DELETE FROM links_fts; DELETE FROM content_fts"); err != nil {
+		t.Fatal(err)
+	}
+	if results, _ := s.Search(ctx, "reindexed", SearchOptions{}); len(results) != 0 {
+		t.Fatalf("Expected the emptied index to find nothing, got %+v", results)
+	}
+
+	n, err := s.Reindex(ctx)
+	if err != nil {
+		t.Fatalf("Reindex failed: %v", err)
+	}
+	if n != 1 {
+		t.Errorf("Expected 1 link reindexed, got %d", n)
+	}
+	if results, _ := s.Search(ctx, "reindexed", SearchOptions{}); len(results) != 1 {
+		t.Errorf("Expected the link to be found after reindexing, got %+v", results)
+	}
+	if results, _ := s.Search(ctx, "body", SearchOptions{Content: true}); len(results) != 1 {
+		t.Errorf("Expected content to be found after reindexing, got %+v", results)
+	}
+}
+
+func TestContentHash(t *testing.T) {
+	s := setupTestDB(t)
+	defer s.Close()
+
+	ctx := context.Background()
+	link, _ := s.Add(ctx, &model.Link{URL: "https://example.com/ref"})
+	if _, err := s.ContentHash(ctx, link.ID); !errors.Is(err, model.ErrNotFound) {
+		t.Fatalf("Expected ErrNotFound without archived text, got %v", err)
+	}
+
+	if err := s.SetContent(ctx, link.ID, "Version one."); err != nil {
+		t.Fatalf("SetContent failed: %v", err)
+	}
+	hash, err := s.ContentHash(ctx, link.ID)
+	if err != nil || hash != model.ContentHash("Version one.") {
+		t.Fatalf("Expected the hash of the archived text, got %q (%v)", hash, err)
+	}
+
+	// Text archived before hashes were stored is hashed on the fly
+	if _, err := s.db.Exec("UPDATE link_content SET sha256 = ''"); err != nil {
+		t.Fatalf("clear hash: %v", err)
+	}
+	if hash, _ := s.ContentHash(ctx, link.ID); hash != model.ContentHash("Version one.") {
+		t.Errorf("Expected a computed hash for old rows, got %q", hash)
+	}
+}
+
+func TestSearchContent(t *testing.T) {
+	s := setupTestDB(t)
+	defer s.Close()
+
+	ctx := context.Background()
+	link, err := s.Add(ctx, &model.Link{URL: "https://example.com/async", Title: "Faster servers"})
+	if err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if err := s.SetContent(ctx, link.ID, "We moved the event loop to io_uring."); err != nil {
+		t.Fatalf("SetContent failed: %v", err)
+	}
+
+	results, err := s.Search(ctx, "io_uring", SearchOptions{})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(results) != 0 {
+		t.Errorf("Content should only match with SearchOptions.Content, got %d result(s)", len(results))
+	}
+	results, err = s.Search(ctx, "io_uring", SearchOptions{Content: true})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(results) != 1 || results[0].ID != link.ID {
+		t.Fatalf("Expected the archived article to match, got %+v", results)
+	}
+
+	// Replacing the text re-indexes it
+	if err := s.SetContent(ctx, link.ID, "Now about epoll."); err != nil {
+		t.Fatalf("SetContent failed: %v", err)
+	}
+	if results, _ := s.Search(ctx, "io_uring", SearchOptions{Content: true}); len(results) != 0 {
+		t.Errorf("Old content should no longer match, got %+v", results)
+	}
+	if results, _ := s.Search(ctx, "epoll", SearchOptions{Content: true}); len(results) != 1 {
+		t.Errorf("New content should match, got %+v", results)
+	}
+
+	if err := s.Delete(ctx, link.ID); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if results, _ := s.Search(ctx, "epoll", SearchOptions{Content: true}); len(results) != 0 {
+		t.Errorf("Deleted link should not match, got %+v", results)
+	}
+}
+
+func TestListPriorityOrder(t *testing.T) {
+	s := setupTestDB(t)
+	defer s.Close()
+
+	ctx := context.Background()
+
+	low, _ := s.Add(ctx, &model.Link{URL: "https://example.com/low", Priority: model.PriorityLow})
+	normal, _ := s.Add(ctx, &model.Link{URL: "https://example.com/normal"})
+	high, _ := s.Add(ctx, &model.Link{URL: "https://example.com/high"})
+
+	if err := s.SetPriority(ctx, high.ID, model.PriorityHigh); err != nil {
+		t.Fatalf("SetPriority failed: %v", err)
+	}
+
+	links, err := s.List(ctx, ListOptions{ReadStatus: ReadStatusUnread})
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+
+	want := []string{high.ID, normal.ID, low.ID}
+	if len(links) != len(want) {
+		t.Fatalf("Expected %d links, got %d", len(want), len(links))
+	}
+	for i, id := range want {
+		if links[i].ID != id {
+			t.Errorf("Position %d: expected %s, got %s", i, id, links[i].ID)
+		}
+	}
+	if links[0].Priority != model.PriorityHigh {
+		t.Errorf("Expected high priority, got %s", links[0].Priority)
+	}
+}
+
+func TestListSort(t *testing.T) {
+	s := setupTestDB(t)
+	defer s.Close()
+
+	ctx := context.Background()
+	base := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	b, _ := s.Add(ctx, &model.Link{URL: "https://b.com/x", Title: "zebra", CreatedAt: base})
+	c, _ := s.Add(ctx, &model.Link{URL: "https://c.com/x", Title: "Apple", CreatedAt: base.Add(time.Hour), Priority: model.PriorityHigh})
+	a, _ := s.Add(ctx, &model.Link{URL: "https://a.com/x", Title: "mango", CreatedAt: base.Add(2 * time.Hour), Priority: model.PriorityLow})
+
+	tests := []struct {
+		sort SortOrder
+		want []string
+	}{
+		{SortNewest, []string{a.ID, c.ID, b.ID}},
+		{SortOldest, []string{b.ID, c.ID, a.ID}},
+		{SortTitle, []string{c.ID, a.ID, b.ID}},
+		{SortDomain, []string{a.ID, b.ID, c.ID}},
+		{SortPriority, []string{c.ID, b.ID, a.ID}},
+	}
+	for _, tt := range tests {
+		links, err := s.List(ctx, ListOptions{ReadStatus: ReadStatusAll, Sort: tt.sort})
+		if err != nil {
+			t.Fatalf("List(%s) failed: %v", tt.sort, err)
+		}
+		if len(links) != len(tt.want) {
+			t.Fatalf("List(%s): expected %d links, got %d", tt.sort, len(tt.want), len(links))
+		}
+		for i, id := range tt.want {
+			if links[i].ID != id {
+				t.Errorf("List(%s) position %d: expected %s, got %s", tt.sort, i, id, links[i].ID)
+			}
+		}
+	}
+
+	if _, err := ParseSortOrder("size"); err == nil {
+		t.Error("Expected error for unknown sort order")
+	}
+}
+
+func TestListPages(t *testing.T) {
+	s := setupTestDB(t)
+	defer s.Close()
+
+	ctx := context.Background()
+	base := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	var ids []string
+	for i := 0; i < 5; i++ {
+		link, err := s.Add(ctx, &model.Link{URL: fmt.Sprintf("https://example.com/%d", i), CreatedAt: base.Add(time.Duration(i) * time.Hour)})
+		if err != nil {
+			t.Fatalf("Add failed: %v", err)
+		}
+		ids = append(ids, link.ID)
+	}
+	if err := s.MarkRead(ctx, ids[0]); err != nil {
+		t.Fatalf("MarkRead failed: %v", err)
+	}
+
+	opts := ListOptions{ReadStatus: ReadStatusAll, Sort: SortOldest, Limit: 2}
+	var paged []string
+	for opts.Offset = 0; ; opts.Offset += opts.Limit {
+		links, err := s.List(ctx, opts)
+		if err != nil {
+			t.Fatalf("List(offset %d) failed: %v", opts.Offset, err)
+		}
+		if len(links) == 0 {
+			break
+		}
+		for _, link := range links {
+			paged = append(paged, link.ID)
+		}
+	}
+	if strings.Join(paged, ",") != strings.Join(ids, ",") {
+		t.Errorf("Pages listed %v, want %v", paged, ids)
+	}
+
+	rest, err := s.List(ctx, ListOptions{ReadStatus: ReadStatusAll, Sort: SortOldest, Offset: 3})
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(rest) != 2 || rest[0].ID != ids[3] {
+		t.Errorf("Offset without a limit listed %d links, want the last 2", len(rest))
+	}
+
+	for status, want := range map[ReadStatus]int{ReadStatusAll: 5, ReadStatusUnread: 4, ReadStatusRead: 1} {
+		n, err := s.Count(ctx, ListOptions{ReadStatus: status, Limit: 1, Offset: 1})
+		if err != nil {
+			t.Fatalf("Count(%d) failed: %v", status, err)
+		}
+		if n != want {
+			t.Errorf("Count(%d) = %d, want %d", status, n, want)
+		}
+	}
+}
+
+func TestSetPriorityNotFound(t *testing.T) {
+	s := setupTestDB(t)
+	defer s.Close()
+
+	err := s.SetPriority(context.Background(), "aaaaaaaaaaaaaaaaaaaaaaaaaa", model.PriorityHigh)
+	if err != model.ErrNotFound {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestSnooze(t *testing.T) {
+	s := setupTestDB(t)
+	defer s.Close()
+
+	ctx := context.Background()
+	snoozed, _ := s.Add(ctx, &model.Link{URL: "https://example.com/later"})
+	s.Add(ctx, &model.Link{URL: "https://example.com/now"})
+
+	if err := s.Snooze(ctx, snoozed.ID, time.Now().Add(72*time.Hour)); err != nil {
+		t.Fatalf("Snooze failed: %v", err)
+	}
+
+	unread, err := s.List(ctx, ListOptions{ReadStatus: ReadStatusUnread})
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(unread) != 1 || unread[0].ID == snoozed.ID {
+		t.Errorf("Expected snoozed link to be hidden from unread list, got %d links", len(unread))
+	}
+
+	onlySnoozed, err := s.List(ctx, ListOptions{ReadStatus: ReadStatusAll, Snoozed: true})
+	if err != nil {
+		t.Fatalf("List snoozed failed: %v", err)
+	}
+	if len(onlySnoozed) != 1 || onlySnoozed[0].ID != snoozed.ID {
+		t.Errorf("Expected only the snoozed link, got %d links", len(onlySnoozed))
+	}
+
+	// An expired snooze returns the link to the queue
+	if err := s.Snooze(ctx, snoozed.ID, time.Now().Add(-time.Hour)); err != nil {
+		t.Fatalf("Snooze failed: %v", err)
+	}
+	unread, _ = s.List(ctx, ListOptions{ReadStatus: ReadStatusUnread})
+	if len(unread) != 2 {
+		t.Errorf("Expected 2 unread links after snooze expired, got %d", len(unread))
+	}
+
+	now := time.Now()
+	if woken, err := s.Woken(ctx, now.Add(-2*time.Hour), now); err != nil || len(woken) != 1 || woken[0].ID != snoozed.ID {
+		t.Errorf("Expected the snoozed link to have woken in the last 2 hours, got %d links (%v)", len(woken), err)
+	}
+	if woken, _ := s.Woken(ctx, now.Add(-30*time.Minute), now); len(woken) != 0 {
+		t.Errorf("Expected nothing to have woken in the last 30 minutes, got %d links", len(woken))
+	}
+
+	if err := s.Snooze(ctx, snoozed.ID, time.Time{}); err != nil {
+		t.Fatalf("Clear snooze failed: %v", err)
+	}
+	retrieved, _ := s.Get(ctx, snoozed.ID)
+	if retrieved.SnoozedUntil != nil {
+		t.Error("Expected SnoozedUntil to be cleared")
+	}
+}
+
+func TestListMaxReadingTime(t *testing.T) {
+	s := setupTestDB(t)
+	defer s.Close()
+
+	ctx := context.Background()
+	short, _ := s.Add(ctx, &model.Link{URL: "https://example.com/short", WordCount: 500, ReadingSeconds: 180})
+	s.Add(ctx, &model.Link{URL: "https://example.com/long", WordCount: 10000, ReadingSeconds: 2580})
+	s.Add(ctx, &model.Link{URL: "https://example.com/unknown"})
+
+	links, err := s.List(ctx, ListOptions{ReadStatus: ReadStatusAll, MaxReadingTime: 10 * time.Minute})
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(links) != 1 || links[0].ID != short.ID {
+		t.Fatalf("Expected only the short link, got %d links", len(links))
+	}
+	if links[0].WordCount != 500 || links[0].ReadingTime() != 3*time.Minute {
+		t.Errorf("Expected 500 words / 3m, got %d words / %v", links[0].WordCount, links[0].ReadingTime())
+	}
+}
+
+func TestListType(t *testing.T) {
+	s := setupTestDB(t)
+	defer s.Close()
+
+	ctx := context.Background()
+	short, _ := s.Add(ctx, &model.Link{URL: "https://youtube.com/watch?v=a", MediaType: model.MediaVideo, Author: "A Channel", ReadingSeconds: 600})
+	s.Add(ctx, &model.Link{URL: "https://youtube.com/watch?v=b", MediaType: model.MediaVideo, ReadingSeconds: 3600})
+	s.Add(ctx, &model.Link{URL: "https://example.com/episode", MediaType: model.MediaAudio, ReadingSeconds: 600})
+	article, _ := s.Add(ctx, &model.Link{URL: "https://example.com/post", ReadingSeconds: 300})
+
+	videos, err := s.List(ctx, ListOptions{ReadStatus: ReadStatusAll, Type: TypeVideo, MaxReadingTime: 20 * time.Minute})
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(videos) != 1 || videos[0].ID != short.ID {
+		t.Fatalf("Expected only the short video, got %d links", len(videos))
+	}
+	if videos[0].Author != "A Channel" || videos[0].MediaType != model.MediaVideo {
+		t.Errorf("Expected author and media type to round-trip, got %q, %q", videos[0].Author, videos[0].MediaType)
+	}
+
+	articles, _ := s.List(ctx, ListOptions{ReadStatus: ReadStatusAll, Type: TypeArticle})
+	if len(articles) != 1 || articles[0].ID != article.ID {
+		t.Errorf("Expected only the article, got %d links", len(articles))
+	}
+
+	if _, err := ParseLinkType("podcast"); err == nil {
+		t.Error("Expected an error for an unknown type")
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	s := setupTestDB(t)
+	defer s.Close()
+
+	ctx := context.Background()
+	created, _ := s.Add(ctx, &model.Link{URL: "https://example.com", Tags: "a"})
+
+	created.Title = "Fetched Title"
+	created.WordCount = 1200
+	created.ReadingSeconds = model.EstimateReadingSeconds(1200)
+	if err := s.Update(ctx, created); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+
+	retrieved, _ := s.Get(ctx, created.ID)
+	if retrieved.Title != "Fetched Title" || retrieved.Tags != "a" {
+		t.Errorf("Unexpected link after update: %+v", retrieved)
+	}
+	if retrieved.ReadingSeconds != 360 {
+		t.Errorf("Expected 360 reading seconds, got %d", retrieved.ReadingSeconds)
+	}
+}
+
+func TestListDateRange(t *testing.T) {
+	s := setupTestDB(t)
+	defer s.Close()
+
+	ctx := context.Background()
+	now := time.Now()
+	old, _ := s.Add(ctx, &model.Link{URL: "https://old.example", CreatedAt: now.AddDate(0, -2, 0)})
+	recent, _ := s.Add(ctx, &model.Link{URL: "https://recent.example", CreatedAt: now.Add(-48 * time.Hour)})
+	if err := s.MarkRead(ctx, old.ID); err != nil {
+		t.Fatalf("MarkRead failed: %v", err)
+	}
+
+	list := func(r DateRange) []*model.Link {
+		t.Helper()
+		links, err := s.List(ctx, ListOptions{ReadStatus: ReadStatusAll, DateRange: r})
+		if err != nil {
+			t.Fatalf("List failed: %v", err)
+		}
+		return links
+	}
+
+	if links := list(DateRange{Since: now.AddDate(0, 0, -7)}); len(links) != 1 || links[0].ID != recent.ID {
+		t.Errorf("Expected only the recent link since a week ago, got %+v", links)
+	}
+	if links := list(DateRange{Before: now.AddDate(0, -1, 0)}); len(links) != 1 || links[0].ID != old.ID {
+		t.Errorf("Expected only the old link before a month ago, got %+v", links)
+	}
+	// read_at is stored in SQLite's own format; it must still compare correctly
+	if links := list(DateRange{ReadSince: now.Add(-time.Hour)}); len(links) != 1 || links[0].ID != old.ID {
+		t.Errorf("Expected the link read just now, got %+v", links)
+	}
+	if links := list(DateRange{ReadSince: now.Add(time.Hour)}); len(links) != 0 {
+		t.Errorf("Expected no links read in the future, got %+v", links)
+	}
+	if links := list(DateRange{ReadBefore: now.Add(time.Hour)}); len(links) != 1 || links[0].ID != old.ID {
+		t.Errorf("Expected only the read link before an hour from now, got %+v", links)
+	}
+	if links := list(DateRange{ReadBefore: now.Add(-time.Hour)}); len(links) != 0 {
+		t.Errorf("Expected no links read over an hour ago, got %+v", links)
+	}
+
+	results, err := s.Search(ctx, "example", SearchOptions{DateRange: DateRange{Since: now.AddDate(0, 0, -7)}})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(results) != 1 || results[0].ID != recent.ID {
+		t.Errorf("Expected search to honor the date range, got %+v", results)
+	}
+}
+
+func TestListDomain(t *testing.T) {
+	s := setupTestDB(t)
+	defer s.Close()
+
+	ctx := context.Background()
+	s.Add(ctx, &model.Link{URL: "https://www.GitHub.com/golang/go"})
+	s.Add(ctx, &model.Link{URL: "https://gist.github.com/abc"})
+	s.Add(ctx, &model.Link{URL: "https://notgithub.com/x"})
+
+	links, err := s.List(ctx, ListOptions{ReadStatus: ReadStatusAll, Domain: "github.com"})
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(links) != 2 {
+		t.Fatalf("Expected 2 github.com links, got %d", len(links))
+	}
+	for _, l := range links {
+		if l.Domain != "github.com" && l.Domain != "gist.github.com" {
+			t.Errorf("Unexpected domain %q", l.Domain)
+		}
+	}
+}
+
+func TestStats(t *testing.T) {
+	s := setupTestDB(t)
+	defer s.Close()
+
+	ctx := context.Background()
+	a, _ := s.Add(ctx, &model.Link{URL: "https://github.com/a", Tags: "go,tools"})
+	s.Add(ctx, &model.Link{URL: "https://github.com/b", Tags: "Go"})
+	s.Add(ctx, &model.Link{URL: "https://go.dev/doc", CreatedAt: time.Now().Add(-15 * 24 * time.Hour)})
+	s.MarkRead(ctx, a.ID)
+	// Saved early on the first day of the first week, in the layout
+	// SQLite's own datetime() writes
+	old, _ := s.Add(ctx, &model.Link{URL: "https://example.com/old"})
+	created := time.Now().UTC().Add(-StatsWeeks*7*24*time.Hour + time.Second)
+	if _, err := s.db.Exec("UPDATE links SET created_at = ? WHERE id = ?", created.Format("2006-01-02 15:04:05"), old.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	stats, err := s.Stats(ctx)
+	if err != nil {
+		t.Fatalf("Stats failed: %v", err)
+	}
+	if stats.Total != 4 || stats.Unread != 3 || stats.Read != 1 {
+		t.Errorf("Unexpected counts: %+v", stats)
+	}
+	if len(stats.Domains) != 3 || stats.Domains[0].Name != "github.com" || stats.Domains[0].Count != 2 {
+		t.Errorf("Unexpected domain counts: %+v", stats.Domains)
+	}
+	if len(stats.Tags) != 2 || stats.Tags[0].Name != "go" || stats.Tags[0].Count != 2 {
+		t.Errorf("Unexpected tag counts: %+v", stats.Tags)
+	}
+	if len(stats.Weekly) != StatsWeeks || stats.Weekly[StatsWeeks-1] != 2 || stats.Weekly[StatsWeeks-3] != 1 || stats.Weekly[0] != 1 {
+		t.Errorf("Unexpected weekly additions: %v", stats.Weekly)
+	}
+
+	// Counted for a list, tags only cover its links
+	unread, err := s.TagCounts(ctx, ListOptions{ReadStatus: ReadStatusUnread})
+	if err != nil {
+		t.Fatalf("TagCounts failed: %v", err)
+	}
+	if len(unread) != 1 || unread[0].Name != "Go" || unread[0].Count != 1 {
+		t.Errorf("Unexpected unread tag counts: %+v", unread)
+	}
+}
+
+func TestRecordCheckAndListDead(t *testing.T) {
+	s := setupTestDB(t)
+	defer s.Close()
+
+	ctx := context.Background()
+	ok, _ := s.Add(ctx, &model.Link{URL: "https://example.com/ok"})
+	gone, _ := s.Add(ctx, &model.Link{URL: "https://example.com/gone"})
+	down, _ := s.Add(ctx, &model.Link{URL: "https://example.com/down"})
+	s.Add(ctx, &model.Link{URL: "https://example.com/unchecked"})
+
+	now := time.Now()
+	for id, status := range map[string]int{ok.ID: 200, gone.ID: 404, down.ID: 0} {
+		if err := s.RecordCheck(ctx, id, status, now); err != nil {
+			t.Fatalf("RecordCheck failed: %v", err)
+		}
+	}
+
+	dead, err := s.List(ctx, ListOptions{ReadStatus: ReadStatusAll, Dead: true})
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(dead) != 2 {
+		t.Fatalf("Expected 2 dead links, got %d", len(dead))
+	}
+	for _, l := range dead {
+		if !l.IsDead() || l.CheckedAt == nil {
+			t.Errorf("Expected %s to be flagged dead", l.URL)
+		}
+	}
+
+	retrieved, _ := s.Get(ctx, ok.ID)
+	if retrieved.HTTPStatus != 200 || retrieved.IsDead() {
+		t.Errorf("Expected healthy link, got status %d", retrieved.HTTPStatus)
+	}
+}
+
+func TestURLAlias(t *testing.T) {
+	s := setupTestDB(t)
+	defer s.Close()
+
+	ctx := context.Background()
+	created, _ := s.Add(ctx, &model.Link{URL: "https://example.com/article", Tags: "a"})
+	if err := s.AddURLAlias(ctx, created.ID, "https://bit.ly/xyz"); err != nil {
+		t.Fatalf("AddURLAlias failed: %v", err)
+	}
+
+	found, err := s.FindByURL(ctx, "https://bit.ly/xyz")
+	if err != nil {
+		t.Fatalf("FindByURL failed: %v", err)
+	}
+	if found.ID != created.ID {
+		t.Errorf("Expected alias to resolve to %s, got %s", created.ID, found.ID)
+	}
+
+	// Re-adding the short link merges into the existing entry
+	updated, err := s.Add(ctx, &model.Link{URL: "https://bit.ly/xyz", Tags: "b"})
+	if err != nil {
+		t.Fatalf("Add via alias failed: %v", err)
+	}
+	if updated.ID != created.ID || updated.URL != "https://example.com/article" {
+		t.Errorf("Expected merge into %s, got %s (%s)", created.ID, updated.ID, updated.URL)
+	}
+	all, _ := s.List(ctx, ListOptions{ReadStatus: ReadStatusAll})
+	if len(all) != 1 {
+		t.Errorf("Expected 1 link, got %d", len(all))
+	}
+
+	if err := s.Delete(ctx, created.ID); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, err := s.FindByURL(ctx, "https://bit.ly/xyz"); err != model.ErrNotFound {
+		t.Errorf("Expected alias removed with link, got %v", err)
+	}
+}
+
+func TestResolveID(t *testing.T) {
+	s := setupTestDB(t)
+	defer s.Close()
+
+	ctx := context.Background()
+	ids := []string{"abc1234567890000000000000a", "abd1234567890000000000000b"}
+	for i, id := range ids {
+		link := &model.Link{ID: id, URL: fmt.Sprintf("https://example.com/%d", i)}
+		if _, err := s.Import(ctx, []*model.Link{link}, ImportOptions{}); err != nil {
+			t.Fatalf("Import failed: %v", err)
+		}
+	}
+
+	got, err := s.ResolveID(ctx, "ABC")
+	if err != nil || got != ids[0] {
+		t.Errorf("ResolveID(ABC) = %q, %v; want %q", got, err, ids[0])
+	}
+
+	got, err = s.ResolveID(ctx, ids[1])
+	if err != nil || got != ids[1] {
+		t.Errorf("ResolveID(full) = %q, %v; want %q", got, err, ids[1])
+	}
+
+	_, err = s.ResolveID(ctx, "ab")
+	var ambiguous *model.AmbiguousIDError
+	if !errors.As(err, &ambiguous) || len(ambiguous.Candidates) != 2 {
+		t.Errorf("Expected ambiguous error with 2 candidates, got %v", err)
+	}
+
+	if _, err := s.ResolveID(ctx, "zz"); err != model.ErrNotFound {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestAliases(t *testing.T) {
+	s := setupTestDB(t)
+	defer s.Close()
+
+	ctx := context.Background()
+	link, err := s.Add(ctx, &model.Link{URL: "https://go.dev/s/sched"})
+	if err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	if err := s.SetAlias(ctx, link.ID, "golang-sched"); err != nil {
+		t.Fatalf("SetAlias failed: %v", err)
+	}
+	if err := s.SetAlias(ctx, link.ID, "bad name"); err != model.ErrInvalidAlias {
+		t.Errorf("Expected ErrInvalidAlias, got %v", err)
+	}
+
+	got, err := s.ResolveID(ctx, "Golang-Sched")
+	if err != nil || got != link.ID {
+		t.Errorf("ResolveID(alias) = %q, %v; want %q", got, err, link.ID)
+	}
+
+	aliases, err := s.Aliases(ctx)
+	if err != nil {
+		t.Fatalf("Aliases failed: %v", err)
+	}
+	if len(aliases) != 1 || aliases[0].Name != "golang-sched" || aliases[0].LinkID != link.ID {
+		t.Errorf("Unexpected aliases: %+v", aliases)
+	}
+
+	if err := s.Delete(ctx, link.ID); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if err := s.RemoveAlias(ctx, "golang-sched"); err != model.ErrNotFound {
+		t.Errorf("Expected alias to be removed with its link, got %v", err)
+	}
+}
+
+func TestContent(t *testing.T) {
+	s := setupTestDB(t)
+	defer s.Close()
+
+	ctx := context.Background()
+	link, err := s.Add(ctx, &model.Link{URL: "https://example.com/article"})
+	if err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	if _, err := s.Content(ctx, link.ID); err != model.ErrNotFound {
+		t.Errorf("Expected ErrNotFound before archiving, got %v", err)
+	}
+	if err := s.SetContent(ctx, link.ID, "First paragraph.\nSecond paragraph."); err != nil {
+		t.Fatalf("SetContent failed: %v", err)
+	}
+	text, err := s.Content(ctx, link.ID)
+	if err != nil || text != "First paragraph.\nSecond paragraph." {
+		t.Errorf("Content = %q, %v", text, err)
+	}
+
+	if err := s.Delete(ctx, link.ID); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, err := s.Content(ctx, link.ID); err != model.ErrNotFound {
+		t.Errorf("Expected content to be deleted with its link, got %v", err)
+	}
+}
+
+func TestSyncRecords(t *testing.T) {
+	s := setupTestDB(t)
+	defer s.Close()
+
+	ctx := context.Background()
+	link, err := s.Add(ctx, &model.Link{URL: "https://example.com/synced"})
+	if err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	rec := SyncRecord{Service: "wallabag", LinkID: link.ID, RemoteID: "42"}
+	if err := s.SetSyncRecord(ctx, rec); err != nil {
+		t.Fatalf("SetSyncRecord failed: %v", err)
+	}
+	rec.Read = true
+	rec.Digest = "abc"
+	if err := s.SetSyncRecord(ctx, rec); err != nil {
+		t.Fatalf("SetSyncRecord update failed: %v", err)
+	}
+
+	records, err := s.SyncRecords(ctx, "wallabag")
+	if err != nil {
+		t.Fatalf("SyncRecords failed: %v", err)
+	}
+	if len(records) != 1 || records[0] != rec {
+		t.Errorf("Expected %+v, got %+v", rec, records)
+	}
+	if other, _ := s.SyncRecords(ctx, "pinboard"); len(other) != 0 {
+		t.Errorf("Records should be per service, got %+v", other)
+	}
+
+	// Records outlive their links so a sync doesn't restore deleted links
+	if err := s.Delete(ctx, link.ID); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if records, _ := s.SyncRecords(ctx, "wallabag"); len(records) != 1 {
+		t.Errorf("Expected the record to survive deletion, got %+v", records)
+	}
+}
+
+// schema returns the database's tables, indexes, and triggers.
+func schema(t *testing.T, s *SQLiteStorage) string {
+	var defs []string
+	if err := s.db.Select(&defs, "SELECT sql FROM sqlite_master WHERE sql IS NOT NULL ORDER BY name"); err != nil {
+		t.Fatalf("read schema: %v", err)
+	}
+	return strings.Join(defs, "\n")
+}
+
+func TestRollback(t *testing.T) {
+	path := t.TempDir() + "/links.db"
+	s, err := NewSQLiteStorage(path)
+	if err != nil {
+		t.Fatalf("NewSQLiteStorage failed: %v", err)
+	}
+	ctx := context.Background()
+	link, err := s.Add(ctx, &model.Link{URL: "https://example.com/go", Title: "Go scheduler"})
+	if err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if err := s.SetContent(ctx, link.ID, "goroutines and threads"); err != nil {
+		t.Fatalf("SetContent failed: %v", err)
+	}
+	want := schema(t, s)
+
+	// Every migration after 003 has a down script
+	rolledBack, err := s.RollbackTo(ctx, 3)
+	if err != nil {
+		t.Fatalf("RollbackTo failed: %v", err)
+	}
+	if len(rolledBack) == 0 || rolledBack[0].Version <= rolledBack[len(rolledBack)-1].Version {
+		t.Errorf("Expected migrations rolled back newest first, got %+v", rolledBack)
+	}
+	migrations, err := s.Migrations(ctx)
+	if err != nil {
+		t.Fatalf("Migrations failed: %v", err)
+	}
+	for _, m := range migrations {
+		if applied := m.AppliedAt != nil; applied != (m.Version <= 3) {
+			t.Errorf("Migration %03d_%s: expected applied = %v", m.Version, m.Name, m.Version <= 3)
+		}
+	}
+	if _, err := s.RollbackTo(ctx, 0); err == nil {
+		t.Error("Expected rolling back a migration without a down script to fail")
+	}
+	s.Close()
+
+	// Reopening applies them again, keeping the links
+	s, err = NewSQLiteStorage(path)
+	if err != nil {
+		t.Fatalf("Re-migrating failed: %v", err)
+	}
+	defer s.Close()
+	if got := schema(t, s); got != want {
+		t.Errorf("Expected the schema to match after re-migrating:\n%s\ngot:\n%s", want, got)
+	}
+	results, err := s.Search(ctx, "scheduler", SearchOptions{})
+	if err != nil || len(results) != 1 {
+		t.Errorf("Expected the link to be searchable again, got %d results (%v)", len(results), err)
+	}
+}
+
+func TestNotesMigration(t *testing.T) {
+	path := t.TempDir() + "/links.db"
+	s, err := NewSQLiteStorage(path)
+	if err != nil {
+		t.Fatalf("NewSQLiteStorage failed: %v", err)
+	}
+	ctx := context.Background()
+	saved := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	link, err := s.Add(ctx, &model.Link{URL: "https://example.com/a", CreatedAt: saved})
+	if err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	plain, err := s.Add(ctx, &model.Link{URL: "https://example.com/b"})
+	if err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	// A link's note from before 033, and a note added to it later
+	if _, err := s.RollbackTo(ctx, 32); err != nil {
+		t.Fatalf("RollbackTo failed: %v", err)
+	}
+	if _, err := s.db.Exec("UPDATE links SET note = ? WHERE id = ?", "from the old field", link.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.db.Exec("UPDATE links SET note = '' WHERE id = ?", plain.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.AddAnnotation(ctx, link.ID, "added later"); err != nil {
+		t.Fatal(err)
+	}
+	s.Close()
+
+	s, err = NewSQLiteStorage(path)
+	if err != nil {
+		t.Fatalf("Re-migrating failed: %v", err)
+	}
+	annotations, err := s.Annotations(ctx, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(annotations) != 2 || annotations[0].Text != "from the old field" || !annotations[0].CreatedAt.Equal(saved) || annotations[0].LinkID != link.ID {
+		t.Fatalf("Expected the note moved to an annotation dated when the link was saved, got %+v", annotations)
+	}
+	if results, err := s.Search(ctx, "field", SearchOptions{}); err != nil || len(results) != 1 || results[0].Match.Field != "note" {
+		t.Errorf("Expected the moved note to be searchable, got %+v, %v", results, err)
+	}
+
+	// Rolling back puts the note back where it came from
+	if _, err := s.RollbackTo(ctx, 32); err != nil {
+		t.Fatalf("RollbackTo failed: %v", err)
+	}
+	var note string
+	if err := s.db.Get(&note, "SELECT note FROM links WHERE id = ?", link.ID); err != nil || note != "from the old field" {
+		t.Errorf("Expected the note restored, got %q, %v", note, err)
+	}
+	if notes := noteTexts(t, s, link.ID); !reflect.DeepEqual(notes, []string{"added later"}) {
+		t.Errorf("Expected only the later note left an annotation, got %q", notes)
+	}
+	s.Close()
+}
+
+func TestRollbackUnknownMigration(t *testing.T) {
+	s := setupTestDB(t)
+	defer s.Close()
+	ctx := context.Background()
+
+	// As if a newer rl had applied a migration this build doesn't have
+	if _, err := s.db.Exec("INSERT INTO schema_migrations (version) VALUES (999)"); err != nil {
+		t.Fatal(err)
+	}
+	migrations, err := s.Migrations(ctx)
+	if err != nil {
+		t.Fatalf("Migrations failed: %v", err)
+	}
+	if last := migrations[len(migrations)-1]; last.Version != 999 || !last.Unknown || last.AppliedAt == nil {
+		t.Errorf("Expected an applied unknown migration 999, got %+v", last)
+	}
+	if _, err := s.RollbackTo(ctx, 998); err == nil {
+		t.Error("Expected rolling back an unknown migration to fail")
+	}
+}
+
+func TestDoctorChecks(t *testing.T) {
+	s := setupTestDB(t)
+	defer s.Close()
+	ctx := context.Background()
+
+	link, err := s.Add(ctx, &model.Link{URL: "https://example.com/a", Title: "A"})
+	if err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if _, err := s.Add(ctx, &model.Link{URL: "https://example.com/b", Title: "B"}); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if problems, err := s.IntegrityCheck(ctx); err != nil || problems != nil {
+		t.Fatalf("Expected a sound database, got %v (%v)", problems, err)
+	}
+
+	// Break things the way bugs and manual edits do
+	for _, stmt := range []string{
+		"UPDATE links_fts SET title = 'stale' WHERE id = '" + link.ID + "'",
+		"DELETE FROM links_fts WHERE id != '" + link.ID + "'",
+		"INSERT INTO link_content (link_id, text) VALUES ('gone', 'text')",
+		"INSERT INTO link_aliases (name, link_id) VALUES ('old', 'gone')",
+	} {
+		if _, err := s.db.Exec(stmt); err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+
+	checks, err := s.CheckIndexes(ctx)
+	if err != nil {
+		t.Fatalf("CheckIndexes failed: %v", err)
+	}
+	if links := checks[0]; links.Missing != 1 || links.Stale != 1 || links.Corrupt != "" {
+		t.Errorf("Expected links_fts to have 1 missing and 1 stale entry, got %+v", links)
+	}
+	if content := checks[1]; !content.OK() {
+		t.Errorf("Expected content_fts to be consistent, got %+v", content)
+	}
+
+	orphans, err := s.Orphans(ctx)
+	if err != nil {
+		t.Fatalf("Orphans failed: %v", err)
+	}
+	if len(orphans) != 2 || orphans[0].Name != "link_aliases" || orphans[1].Name != "link_content" {
+		t.Errorf("Expected orphans in link_aliases and link_content, got %+v", orphans)
+	}
+
+	if removed, err := s.DeleteOrphans(ctx); err != nil || removed != 2 {
+		t.Errorf("Expected 2 orphaned rows deleted, got %d (%v)", removed, err)
+	}
+	if _, err := s.Reindex(ctx); err != nil {
+		t.Fatalf("Reindex failed: %v", err)
+	}
+	checks, err = s.CheckIndexes(ctx)
+	if err != nil {
+		t.Fatalf("CheckIndexes failed: %v", err)
+	}
+	for _, check := range checks {
+		if !check.OK() {
+			t.Errorf("Expected %s to be consistent after repair, got %+v", check.Index, check)
+		}
+	}
+
+	size, err := s.Size(ctx)
+	if err != nil {
+		t.Fatalf("Size failed: %v", err)
+	}
+	if size.Bytes == 0 || size.Rows[0] != (Count{Name: "links", Count: 2}) {
+		t.Errorf("Expected a non-empty database with 2 links, got %+v", size)
+	}
+	if err := s.Vacuum(ctx); err != nil {
+		t.Errorf("Vacuum failed: %v", err)
+	}
+}
+
+func TestImportResults(t *testing.T) {
+	s := setupTestDB(t)
+	defer s.Close()
+	ctx := context.Background()
+
+	saved := &model.Link{URL: "https://example.com/saved", Title: "Saved"}
+	other := &model.Link{URL: "https://example.com/other"}
+	for _, link := range []*model.Link{saved, other} {
+		if _, err := s.Add(ctx, link); err != nil {
+			t.Fatalf("Add failed: %v", err)
+		}
+	}
+
+	links := []*model.Link{
+		{URL: "https://example.com/new"},
+		{URL: saved.URL, Annotations: []*model.Annotation{{Text: "New note"}}},
+		{URL: other.URL},
+		{URL: "not a url"},
+		{ID: saved.ID, URL: "https://example.com/clash"},
+	}
+	want := []ImportStatus{ImportAdded, ImportMerged, ImportSkipped, ImportFailed, ImportFailed}
+
+	check := func(results []ImportResult) {
+		t.Helper()
+		if len(results) != len(want) {
+			t.Fatalf("Expected %d results, got %d", len(want), len(results))
+		}
+		for i, r := range results {
+			if r.Status != want[i] || r.URL != links[i].URL {
+				t.Errorf("Result %d: got %s for %s, want %s for %s", i, r.Status, r.URL, want[i], links[i].URL)
+			}
+			if r.Status == ImportFailed && r.Reason == "" {
+				t.Errorf("Result %d: expected a reason for the failure", i)
+			}
+		}
+		if results[1].ID != saved.ID {
+			t.Errorf("Expected the merged link to keep ID %s, got %s", saved.ID, results[1].ID)
+		}
+	}
+
+	// A dry run reports the same results but changes nothing
+	results, err := s.Import(ctx, links, ImportOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("Dry run failed: %v", err)
+	}
+	check(results)
+	if results[0].ID != "" {
+		t.Errorf("Expected no ID for a link a dry run would add, got %s", results[0].ID)
+	}
+	if n, _ := s.Count(ctx, ListOptions{ReadStatus: ReadStatusAll}); n != 2 {
+		t.Errorf("Expected a dry run to add nothing, got %d links", n)
+	}
+	if got := noteTexts(t, s, saved.ID); got != nil {
+		t.Errorf("Expected a dry run to merge nothing, got notes %q", got)
+	}
+
+	results, err = s.Import(ctx, links, ImportOptions{})
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	check(results)
+	if n, _ := s.Count(ctx, ListOptions{ReadStatus: ReadStatusAll}); n != 3 {
+		t.Errorf("Expected only the new link to be added, got %d links", n)
+	}
+	if got, err := s.Get(ctx, results[0].ID); err != nil || got.URL != links[0].URL {
+		t.Errorf("Expected the added link under ID %s: %v", results[0].ID, err)
+	}
+	if got, _ := s.Get(ctx, saved.ID); !reflect.DeepEqual(noteTexts(t, s, saved.ID), []string{"New note"}) || got.Title != "Saved" {
+		t.Errorf("Expected the note merged into the saved link, got %+v", got)
+	}
+
+	// Importing it again finds nothing new: the note is there already
+	if results, err := s.Import(ctx, links[1:2], ImportOptions{}); err != nil || results[0].Status != ImportSkipped {
+		t.Errorf("Expected the re-import skipped, got %+v, %v", results, err)
+	}
+}
+
+// noteTexts returns the texts of a link's annotations, oldest first.
+func noteTexts(t *testing.T, s *SQLiteStorage, id string) []string {
+	t.Helper()
+	annotations, err := s.Annotations(context.Background(), id)
+	if err != nil {
+		t.Fatalf("Annotations failed: %v", err)
+	}
+	var texts []string
+	for _, a := range annotations {
+		texts = append(texts, a.Text)
+	}
+	return texts
+}
+
+func TestSetURL(t *testing.T) {
+	s := setupTestDB(t)
+	defer s.Close()
+	ctx := context.Background()
+
+	link := &model.Link{URL: "http://old.example.com/post", Title: "Post", Tags: "go"}
+	other := &model.Link{URL: "https://example.com/other"}
+	for _, l := range []*model.Link{link, other} {
+		if _, err := s.Add(ctx, l); err != nil {
+			t.Fatalf("Add failed: %v", err)
+		}
+	}
+	if err := s.MarkRead(ctx, link.ID); err != nil {
+		t.Fatalf("MarkRead failed: %v", err)
+	}
+
+	moved, err := s.SetURL(ctx, link.ID, "https://new.example.com/post")
+	if err != nil {
+		t.Fatalf("SetURL failed: %v", err)
+	}
+	got, err := s.Get(ctx, link.ID)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if got.URL != moved.URL || got.Domain != "new.example.com" || got.Title != "Post" || got.Tags != "go" || got.ReadAt == nil {
+		t.Errorf("Expected only the URL and domain to change, got %+v", got)
+	}
+	if found, err := s.FindByURL(ctx, "http://old.example.com/post"); err != nil || found.ID != link.ID {
+		t.Errorf("Expected the old URL to find the link, got %v (%v)", found, err)
+	}
+
+	if _, err := s.SetURL(ctx, link.ID, other.URL); !errors.Is(err, model.ErrDuplicate) {
+		t.Errorf("Expected another link's URL to be refused, got %v", err)
+	}
+	if _, err := s.SetURL(ctx, other.ID, "http://old.example.com/post"); !errors.Is(err, model.ErrDuplicate) {
+		t.Errorf("Expected another link's URL alias to be refused, got %v", err)
+	}
+	if _, err := s.SetURL(ctx, link.ID, "not a url"); err != model.ErrInvalidURL {
+		t.Errorf("Expected an invalid URL to be refused, got %v", err)
+	}
+
+	// Moving back to the old URL takes it over from the alias
+	if _, err := s.SetURL(ctx, link.ID, "http://old.example.com/post"); err != nil {
+		t.Fatalf("SetURL back failed: %v", err)
+	}
+	if found, err := s.FindByURL(ctx, "https://new.example.com/post"); err != nil || found.ID != link.ID {
+		t.Errorf("Expected the newer URL to become an alias, got %v (%v)", found, err)
+	}
+}
+
+func TestHistory(t *testing.T) {
+	s := setupTestDB(t)
+	defer s.Close()
+	ctx := context.Background()
+
+	link := &model.Link{URL: "https://example.com/post", Title: "Draft"}
+	if _, err := s.Add(ctx, link); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	link.Title = "Final"
+	if err := s.Update(ctx, link); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	// Adding the link again merges in its tags
+	if _, err := s.Add(ctx, &model.Link{URL: link.URL, Tags: "go"}); err != nil {
+		t.Fatalf("Add again failed: %v", err)
+	}
+	if _, err := s.SetURL(ctx, link.ID, "https://example.com/moved"); err != nil {
+		t.Fatalf("SetURL failed: %v", err)
+	}
+
+	edits, err := s.History(ctx, link.ID)
+	if err != nil {
+		t.Fatalf("History failed: %v", err)
+	}
+	want := []model.Edit{
+		{Field: model.FieldTitle, Old: "Draft", New: "Final"},
+		{Field: model.FieldTags, Old: "", New: "go"},
+		{Field: model.FieldURL, Old: "https://example.com/post", New: "https://example.com/moved"},
+	}
+	if len(edits) != len(want) {
+		t.Fatalf("Expected %d edits, got %d", len(want), len(edits))
+	}
+	for i, e := range edits {
+		if e.LinkID != link.ID || e.Field != want[i].Field || e.Old != want[i].Old || e.New != want[i].New {
+			t.Errorf("Edit %d = %+v, want %+v", i, e, want[i])
+		}
+	}
+
+	// Reverting the title restores it and is recorded in turn
+	reverted, err := s.RevertEdit(ctx, link.ID, edits[0].ID)
+	if err != nil {
+		t.Fatalf("RevertEdit failed: %v", err)
+	}
+	if reverted.Title != "Draft" || reverted.Tags != "go" || reverted.URL != "https://example.com/moved" {
+		t.Errorf("Expected only the title reverted, got %+v", reverted)
+	}
+	if edits, _ = s.History(ctx, link.ID); len(edits) != 4 || edits[3].Old != "Final" || edits[3].New != "Draft" {
+		t.Errorf("Expected the revert to be recorded, got %d edits", len(edits))
+	}
+
+	if _, err := s.RevertEdit(ctx, link.ID, edits[2].ID); err != nil {
+		t.Fatalf("RevertEdit of the URL failed: %v", err)
+	}
+	if found, err := s.FindByURL(ctx, "https://example.com/post"); err != nil || found.URL != "https://example.com/post" {
+		t.Errorf("Expected the URL reverted, got %v (%v)", found, err)
+	}
+
+	if _, err := s.RevertEdit(ctx, link.ID, 9999); err == nil {
+		t.Error("Expected an unknown edit to be refused")
+	}
+
+	if err := s.Delete(ctx, link.ID); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if edits, err := s.History(ctx, link.ID); err != nil || len(edits) != 0 {
+		t.Errorf("Expected deleting the link to delete its history, got %d edits (%v)", len(edits), err)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	s := setupTestDB(t)
+	defer s.Close()
+	ctx := context.Background()
+
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	keep := &model.Link{URL: "https://example.com/keep", Tags: "go", CreatedAt: base.Add(time.Hour),
+		Annotations: []*model.Annotation{{Text: "first", CreatedAt: base}}}
+	dup := &model.Link{URL: "https://example.com/dup", Title: "Dup", Tags: "go,db", Priority: model.PriorityHigh, CreatedAt: base,
+		Annotations: []*model.Annotation{{Text: "second", CreatedAt: base.Add(time.Hour)}}}
+	other := &model.Link{URL: "https://example.com/other"}
+	for _, link := range []*model.Link{keep, dup, other} {
+		if _, err := s.Add(ctx, link); err != nil {
+			t.Fatalf("Add failed: %v", err)
+		}
+	}
+	if err := s.MarkRead(ctx, dup.ID); err != nil {
+		t.Fatalf("MarkRead failed: %v", err)
+	}
+	if _, err := s.AddQuote(ctx, dup.ID, "a memorable passage"); err != nil {
+		t.Fatalf("AddQuote failed: %v", err)
+	}
+	if err := s.SetAlias(ctx, dup.ID, "dupe"); err != nil {
+		t.Fatalf("SetAlias failed: %v", err)
+	}
+	if err := s.SetContent(ctx, dup.ID, "archived article text"); err != nil {
+		t.Fatalf("SetContent failed: %v", err)
+	}
+	for _, rec := range []SyncRecord{
+		{Service: "wallabag", LinkID: keep.ID, RemoteID: "1"},
+		{Service: "wallabag", LinkID: dup.ID, RemoteID: "2"},
+		{Service: "pinboard", LinkID: dup.ID, RemoteID: dup.URL},
+	} {
+		if err := s.SetSyncRecord(ctx, rec); err != nil {
+			t.Fatalf("SetSyncRecord failed: %v", err)
+		}
+	}
+
+	// A missing duplicate merges nothing
+	if _, err := s.Merge(ctx, keep.ID, []string{dup.ID, "zzzzzzzzzzzzzzzzzzzzzzzzzz"}); !errors.Is(err, model.ErrNotFound) {
+		t.Fatalf("Expected a missing link to fail the merge, got %v", err)
+	}
+	if _, err := s.Get(ctx, dup.ID); err != nil {
+		t.Fatalf("Expected a failed merge to keep the duplicate: %v", err)
+	}
+
+	merged, err := s.Merge(ctx, keep.ID, []string{dup.ID})
+	if err != nil {
+		t.Fatalf("Merge failed: %v", err)
+	}
+	got, err := s.Get(ctx, keep.ID)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if got.Tags != "go,db" || got.Title != "Dup" || got.Priority != model.PriorityHigh {
+		t.Errorf("Unexpected merged fields: %+v", got)
+	}
+	if !got.CreatedAt.Equal(base) || got.ReadAt == nil {
+		t.Errorf("Expected the earliest save time and the read time, got %v and %v", got.CreatedAt, got.ReadAt)
+	}
+	if merged.Tags != got.Tags || merged.Title != got.Title {
+		t.Errorf("Expected the returned link to match the stored one, got %+v", merged)
+	}
+	if notes := noteTexts(t, s, keep.ID); !reflect.DeepEqual(notes, []string{"first", "second"}) {
+		t.Errorf("Expected the duplicate's note moved to the kept link, got %q", notes)
+	}
+	if _, err := s.Get(ctx, dup.ID); err != model.ErrNotFound {
+		t.Errorf("Expected the duplicate to be deleted, got %v", err)
+	}
+
+	if found, err := s.FindByURL(ctx, dup.URL); err != nil || found.ID != keep.ID {
+		t.Errorf("Expected the duplicate's URL to find the kept link, got %v (%v)", found, err)
+	}
+	if id, err := s.ResolveID(ctx, "dupe"); err != nil || id != keep.ID {
+		t.Errorf("Expected the alias to move to the kept link, got %s (%v)", id, err)
+	}
+	if quotes, _ := s.Quotes(ctx, keep.ID); len(quotes) != 1 {
+		t.Errorf("Expected the quote to move to the kept link, got %d", len(quotes))
+	}
+	for _, query := range []string{"memorable", "archived"} {
+		links, err := s.Search(ctx, query, SearchOptions{Content: true})
+		if err != nil || len(links) != 1 || links[0].ID != keep.ID {
+			t.Errorf("Expected a search for %q to find the kept link, got %d links (%v)", query, len(links), err)
+		}
+	}
+	if events, _ := s.ReadLog(ctx); len(events) != 1 {
+		t.Errorf("Expected the read to move to the kept link, got %d reads", len(events))
+	}
+
+	// The kept link takes the duplicate's record for a service it isn't
+	// synced with; for one it is, the duplicate's record stays as if the
+	// duplicate were deleted
+	pinboard, _ := s.SyncRecords(ctx, "pinboard")
+	if len(pinboard) != 1 || pinboard[0].LinkID != keep.ID {
+		t.Errorf("Expected the pinboard record moved to the kept link, got %+v", pinboard)
+	}
+	wallabag, _ := s.SyncRecords(ctx, "wallabag")
+	remotes := map[string]string{}
+	for _, rec := range wallabag {
+		remotes[rec.RemoteID] = rec.LinkID
+	}
+	if len(wallabag) != 2 || remotes["1"] != keep.ID || remotes["2"] != dup.ID {
+		t.Errorf("Expected both wallabag records kept as they were, got %+v", wallabag)
+	}
+
+	if _, err := s.Merge(ctx, keep.ID, []string{keep.ID}); err == nil {
+		t.Error("Expected merging a link into itself to fail")
+	}
+}
+
+func TestImportCancelled(t *testing.T) {
+	s := setupTestDB(t)
+	defer s.Close()
+
+	links := []*model.Link{{URL: "https://example.com/1"}, {URL: "https://example.com/2"}}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := s.Import(ctx, links, ImportOptions{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected the import to be cancelled, got %v", err)
+	}
+
+	s.SetTimeout(time.Nanosecond)
+	if _, err := s.Stats(context.Background()); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the operation to time out, got %v", err)
+	}
+	s.SetTimeout(0)
+	all, err := s.List(context.Background(), ListOptions{ReadStatus: ReadStatusAll})
+	if err != nil || len(all) != 0 {
+		t.Errorf("Expected a cancelled import to add nothing, got %d links (%v)", len(all), err)
+	}
+}
+
+func TestOpenReadOnly(t *testing.T) {
+	path := t.TempDir() + "/links.db"
+	if _, err := OpenSQLiteStorageReadOnly(path); err == nil {
+		t.Error("Expected opening a missing database read-only to fail")
+	}
+
+	s, err := NewSQLiteStorage(path)
+	if err != nil {
+		t.Fatalf("NewSQLiteStorage failed: %v", err)
+	}
+	ctx := context.Background()
+	if _, err := s.Add(ctx, &model.Link{URL: "https://example.com/a", Title: "A"}); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	s.Close()
+
+	ro, err := OpenSQLiteStorageReadOnly(path)
+	if err != nil {
+		t.Fatalf("OpenSQLiteStorageReadOnly failed: %v", err)
+	}
+	defer ro.Close()
+	links, err := ro.List(ctx, ListOptions{ReadStatus: ReadStatusAll})
+	if err != nil || len(links) != 1 {
+		t.Fatalf("Expected to list 1 link, got %d (%v)", len(links), err)
+	}
+	if _, err := ro.Add(ctx, &model.Link{URL: "https://example.com/b"}); err == nil {
+		t.Error("Expected a write to a read-only database to fail")
+	}
+	if err := ro.MarkRead(ctx, links[0].ID); err == nil {
+		t.Error("Expected a write to a read-only database to fail")
+	}
+}
+
+func TestConcurrentAccess(t *testing.T) {
+	path := t.TempDir() + "/links.db"
+	writer, err := NewSQLiteStorage(path)
+	if err != nil {
+		t.Fatalf("NewSQLiteStorage failed: %v", err)
+	}
+	defer writer.Close()
+	other, err := NewSQLiteStorage(path)
+	if err != nil {
+		t.Fatalf("NewSQLiteStorage failed: %v", err)
+	}
+	defer other.Close()
+	busy := 0
+	other.OnBusy(func() { busy++ })
+	ctx := context.Background()
+
+	var mode string
+	if err := other.db.GetContext(ctx, &mode, "PRAGMA journal_mode"); err != nil || mode != "wal" {
+		t.Fatalf("Expected WAL journal mode, got %q (%v)", mode, err)
+	}
+
+	// Another process holds the write lock, like a long import
+	tx, err := writer.db.BeginTxx(ctx, nil)
+	if err != nil {
+		t.Fatalf("BeginTxx failed: %v", err)
+	}
+	if _, err := tx.ExecContext(ctx, "INSERT INTO links (id, url, created_at) VALUES ('held', 'https://example.com/held', '2024-01-01T00:00:00Z')"); err != nil {
+		t.Fatalf("insert failed: %v", err)
+	}
+
+	// Reads carry on
+	if links, err := other.List(ctx, ListOptions{ReadStatus: ReadStatusAll}); err != nil || len(links) != 0 {
+		t.Errorf("Expected to read while another connection writes, got %d links (%v)", len(links), err)
+	}
+
+	// Writes wait, then fail once the wait runs out
+	other.db.wait = 200 * time.Millisecond
+	if _, err := other.Add(ctx, &model.Link{URL: "https://example.com/a"}); !errors.Is(err, ErrBusy) {
+		t.Errorf("Expected ErrBusy while the lock is held, got %v", err)
+	}
+
+	// ... or succeed once the lock is released
+	other.db.wait = 10 * time.Second
+	go func() {
+		time.Sleep(1500 * time.Millisecond)
+		tx.Commit()
+	}()
+	if _, err := other.Add(ctx, &model.Link{URL: "https://example.com/a"}); err != nil {
+		t.Fatalf("Expected the write to succeed after retrying, got %v", err)
+	}
+	if busy != 1 {
+		t.Errorf("Expected OnBusy once, when the second write started retrying, got %d calls", busy)
+	}
+	if links, _ := other.List(ctx, ListOptions{ReadStatus: ReadStatusAll}); len(links) != 2 {
+		t.Errorf("Expected both writes to land, got %d links", len(links))
+	}
+}
+
+// BenchmarkList lists a 20,000-link library with the indexes of migration
+// 024, as 032 left them, and again without them:
+//
+//	go test -run '^$' -bench List ./internal/storage
+func BenchmarkList(b *testing.B) {
+	s, err := NewSQLiteStorage(b.TempDir() + "/links.db")
+	if err != nil {
+		b.Fatalf("NewSQLiteStorage failed: %v", err)
+	}
+	defer s.Close()
+	ctx := context.Background()
+
+	// Hourly saves over the last two years or so, most of them read a day later
+	const n = 20000
+	base := time.Now().Add(-n * time.Hour)
+	links := make([]*model.Link, n)
+	for i := range links {
+		link := &model.Link{URL: fmt.Sprintf("https://site%d.example/%d", i%50, i), CreatedAt: base.Add(time.Duration(i) * time.Hour)}
+		if i%20 == 0 {
+			link.Priority = model.PriorityHigh
+		}
+		if i%10 < 7 {
+			readAt := link.CreatedAt.Add(24 * time.Hour)
+			link.ReadAt = &readAt
+		}
+		links[i] = link
+	}
+	if _, err := s.Import(ctx, links, ImportOptions{}); err != nil {
+		b.Fatalf("Import failed: %v", err)
+	}
+
+	queries := []struct {
+		name string
+		opts ListOptions
+	}{
+		{"queue", ListOptions{ReadStatus: ReadStatusUnread, Limit: 20}},
+		{"newest", ListOptions{ReadStatus: ReadStatusUnread, Sort: SortNewest, Limit: 20}},
+		{"domain", ListOptions{ReadStatus: ReadStatusAll, Sort: SortDomain, Limit: 20}},
+		{"read-since", ListOptions{ReadStatus: ReadStatusRead, DateRange: DateRange{ReadSince: time.Now().AddDate(0, 0, -7)}}},
+	}
+	run := func(b *testing.B) {
+		for _, q := range queries {
+			b.Run(q.name, func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					if _, err := s.List(ctx, q.opts); err != nil {
+						b.Fatalf("List failed: %v", err)
+					}
+				}
+			})
+		}
+	}
+
+	b.Run("indexed", run)
+	// Rolling back to 023 would drop later columns List needs, so only
+	// the indexes are put back as they were
+	down, err := migrationsFS.ReadFile("migrations/024_query_indexes.down.sql")
+	if err != nil {
+		b.Fatalf("read down script: %v", err)
+	}
+	if _, err := s.db.ExecContext(ctx, string(down)); err != nil {
+		b.Fatalf("drop indexes: %v", err)
+	}
+	b.Run("unindexed", run)
+}
+
+// TestListPlans checks the unread listings BenchmarkList times are read in
+// order from an index rather than sorted.
+func TestListPlans(t *testing.T) {
+	s, err := NewSQLiteStorage(":memory:")
+	if err != nil {
+		t.Fatalf("NewSQLiteStorage failed: %v", err)
+	}
+	defer s.Close()
+
+	for name, opts := range map[string]ListOptions{
+		"queue":  {ReadStatus: ReadStatusUnread, Limit: 20},
+		"newest": {ReadStatus: ReadStatusUnread, Sort: SortNewest, Limit: 20},
+	} {
+		where, args := opts.conditions()
+		var plan []struct {
+			ID      int    `db:"id"`
+			Parent  int    `db:"parent"`
+			NotUsed int    `db:"notused"`
+			Detail  string `db:"detail"`
+		}
+		err := s.db.SelectContext(context.Background(), &plan,
+			"EXPLAIN QUERY PLAN SELECT "+linkColumns+" FROM links WHERE 1=1"+where+" ORDER BY "+orderBy(opts.Sort)+" LIMIT 20", args...)
+		if err != nil {
+			t.Fatalf("%s: explain: %v", name, err)
+		}
+		var details []string
+		for _, step := range plan {
+			details = append(details, step.Detail)
+		}
+		if got := strings.Join(details, "; "); !strings.Contains(got, "USING INDEX") || strings.Contains(got, "TEMP B-TREE") {
+			t.Errorf("%s: expected an index to give the order, got plan %q", name, got)
+		}
+	}
 }
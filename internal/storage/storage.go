@@ -2,6 +2,9 @@ package storage
 
 import (
 	"context"
+	"fmt"
+	"strings"
+	"time"
 
 	"github.com/bunchhieng/rl/internal/model"
 )
@@ -14,26 +17,197 @@ type Storage interface {
 	// Get retrieves a link by ID.
 	Get(ctx context.Context, id string) (*model.Link, error)
 
+	// ResolveID expands an alias or unique ID prefix to a full link ID. It
+	// returns model.ErrNotFound for no match and *model.AmbiguousIDError for
+	// several.
+	ResolveID(ctx context.Context, prefix string) (string, error)
+
+	// SetAlias names a link so the name can be used in place of its ID.
+	SetAlias(ctx context.Context, id, name string) error
+
+	// RemoveAlias deletes a link alias.
+	RemoveAlias(ctx context.Context, name string) error
+
+	// Aliases returns all link aliases ordered by name.
+	Aliases(ctx context.Context) ([]Alias, error)
+
+	// SetContent stores the archived article text of a link.
+	SetContent(ctx context.Context, id, text string) error
+
+	// Content returns the archived article text of a link, or
+	// model.ErrNotFound if none was saved.
+	Content(ctx context.Context, id string) (string, error)
+
+	// ContentHash returns the hash of a link's archived text (see
+	// model.ContentHash), or model.ErrNotFound if none was saved.
+	ContentHash(ctx context.Context, id string) (string, error)
+
+	// AddAnnotation appends a dated note to a link.
+	AddAnnotation(ctx context.Context, linkID, text string) (*model.Annotation, error)
+
+	// Annotations returns a link's annotations, or every link's when
+	// linkID is "", oldest first.
+	Annotations(ctx context.Context, linkID string) ([]*model.Annotation, error)
+
+	// DeleteAnnotation removes an annotation by ID.
+	DeleteAnnotation(ctx context.Context, id int64) error
+
+	// AddQuote saves a passage highlighted from a link.
+	AddQuote(ctx context.Context, linkID, text string) (*model.Quote, error)
+
+	// Quotes returns a link's quotes, or every link's when linkID is "",
+	// oldest first.
+	Quotes(ctx context.Context, linkID string) ([]*model.Quote, error)
+
+	// DeleteQuote removes a quote by ID.
+	DeleteQuote(ctx context.Context, id int64) error
+
+	// SetAttachment records the saved copy of a link's document, replacing
+	// any previous one.
+	SetAttachment(ctx context.Context, a *model.Attachment) error
+
+	// Attachment returns the saved copy of a link's document, or
+	// model.ErrNotFound if there is none.
+	Attachment(ctx context.Context, linkID string) (*model.Attachment, error)
+
+	// SetSummary stores a link's summary, replacing any previous one.
+	SetSummary(ctx context.Context, summary *model.Summary) error
+
+	// Summary returns a link's summary, or model.ErrNotFound if it has
+	// none.
+	Summary(ctx context.Context, linkID string) (*model.Summary, error)
+
+	// Summaries returns every link's summary, keyed by link ID.
+	Summaries(ctx context.Context) (map[string]*model.Summary, error)
+
+	// Reorder moves an unread link offset places along the unread queue, the
+	// default order of unread links, toward the top for negative offsets.
+	// It returns the link's new place, from 0, and the queue's length.
+	Reorder(ctx context.Context, id string, offset int) (int, int, error)
+
+	// SetEmbedding stores a link's embedding, replacing any previous one.
+	SetEmbedding(ctx context.Context, e *model.Embedding) error
+
+	// Embeddings returns the embeddings computed by the named model.
+	Embeddings(ctx context.Context, modelName string) ([]*model.Embedding, error)
+
+	// Attachments returns every saved copy, oldest first.
+	Attachments(ctx context.Context) ([]*model.Attachment, error)
+
+	// SyncRecords returns the links mirrored to a remote service.
+	SyncRecords(ctx context.Context, service string) ([]SyncRecord, error)
+
+	// SetSyncRecord records (or updates) the remote copy of a link.
+	SetSyncRecord(ctx context.Context, rec SyncRecord) error
+
+	// FindByURL retrieves a link by its URL or by one of its URL aliases.
+	FindByURL(ctx context.Context, url string) (*model.Link, error)
+
+	// AddURLAlias records an alternate URL (such as a shortened link) that
+	// resolves to the given link.
+	AddURLAlias(ctx context.Context, id, url string) error
+
 	// List retrieves links with optional filters.
 	List(ctx context.Context, opts ListOptions) ([]*model.Link, error)
 
+	// Count returns how many links List would return, ignoring Limit and
+	// Offset.
+	Count(ctx context.Context, opts ListOptions) (int, error)
+
+	// Update writes the editable fields of an existing link.
+	Update(ctx context.Context, link *model.Link) error
+
 	// Delete removes a link by ID.
 	Delete(ctx context.Context, id string) error
 
+	// Trash moves a link to the trash, keeping the time it was first
+	// trashed if it's there already.
+	Trash(ctx context.Context, id string) error
+
+	// Restore takes a link out of the trash.
+	Restore(ctx context.Context, id string) error
+
 	// MarkRead sets the read_at timestamp for a link.
 	MarkRead(ctx context.Context, id string) error
 
-	// MarkUnread clears the read_at timestamp for a link.
+	// MarkUnread clears the read_at timestamp for a link, taking it out of
+	// the archive.
 	MarkUnread(ctx context.Context, id string) error
 
+	// Archive puts a link away, out of the unread queue and the read
+	// list. A read link keeps its read time; an unread one is marked read
+	// without counting in the read log.
+	Archive(ctx context.Context, id string) error
+
+	// SetPriority updates the priority level of a link.
+	SetPriority(ctx context.Context, id string, priority model.Priority) error
+
+	// Snooze hides a link from the unread list until the given time.
+	// A zero time clears the snooze.
+	Snooze(ctx context.Context, id string, until time.Time) error
+
+	// Woken returns the unread links whose snooze ended after since and no
+	// later than until, in the order they woke.
+	Woken(ctx context.Context, since, until time.Time) ([]*model.Link, error)
+
+	// FetchState returns when a link's page was last fetched, or
+	// model.ErrNotFound if it never was.
+	FetchState(ctx context.Context, id string) (*FetchState, error)
+
+	// SetFetchState records a fetch of a link's page.
+	SetFetchState(ctx context.Context, state FetchState) error
+
+	// RecordCheck stores the HTTP status and time of a dead-link check.
+	// A status of 0 means the URL could not be reached.
+	RecordCheck(ctx context.Context, id string, status int, checkedAt time.Time) error
+
 	// Export returns all links for export.
 	Export(ctx context.Context) ([]*model.Link, error)
 
-	// Import imports links from a slice, handling duplicates.
-	Import(ctx context.Context, links []*model.Link) error
+	// Import imports links from a slice, merging links already saved, and
+	// reports what happened to each. A link that can't be imported fails
+	// on its own; any other error imports none of them.
+	Import(ctx context.Context, links []*model.Link, opts ImportOptions) ([]ImportResult, error)
+
+	// SetURL changes a link's URL, keeping the old one as a URL alias. A
+	// URL already saved as another link returns model.ErrDuplicate.
+	SetURL(ctx context.Context, id, url string) (*model.Link, error)
+
+	// History returns the edits to a link's title, tags, and URL,
+	// oldest first.
+	History(ctx context.Context, id string) ([]*model.Edit, error)
+
+	// RevertEdit sets the field an edit changed back to its earlier value.
+	RevertEdit(ctx context.Context, id string, editID int64) (*model.Link, error)
+
+	// Merge folds the links dupIDs into keepID and deletes them, in one
+	// transaction, returning the merged link.
+	Merge(ctx context.Context, keepID string, dupIDs []string) (*model.Link, error)
+
+	// BackupTo writes a consistent copy of the database to path, which
+	// must not exist.
+	BackupTo(ctx context.Context, path string) error
 
 	// Search performs a full-text search across links.
-	Search(ctx context.Context, query string) ([]*model.Link, error)
+	Search(ctx context.Context, query string, opts SearchOptions) ([]*model.Link, error)
+
+	// SearchApproximate finds up to limit links whose title or URL
+	// resembles the query, for when Search finds nothing.
+	SearchApproximate(ctx context.Context, query string, opts SearchOptions, limit int) ([]*model.Link, error)
+
+	// Reindex rebuilds the full-text search indexes and returns the number
+	// of links indexed.
+	Reindex(ctx context.Context) (int, error)
+
+	// Stats returns aggregate counts across all links.
+	Stats(ctx context.Context) (*Stats, error)
+
+	// TagCounts counts the links matching opts per tag, as in Stats.Tags.
+	TagCounts(ctx context.Context, opts ListOptions) ([]Count, error)
+
+	// ReadLog returns every time a link was marked read, oldest first.
+	// Entries outlive the read state and the link itself.
+	ReadLog(ctx context.Context) ([]ReadEvent, error)
 
 	// Close closes the storage connection.
 	Close() error
@@ -43,7 +217,192 @@ type Storage interface {
 type ListOptions struct {
 	ReadStatus ReadStatus
 	Tag        string
+	Domain     string
 	Limit      int
+	// Offset skips that many links, for paging through results with Limit.
+	Offset int
+	DateRange
+	// Snoozed restricts results to links whose snooze has not yet expired.
+	// When false, unread listings exclude snoozed links.
+	Snoozed bool
+	// Trashed lists the links in the trash instead of the rest.
+	Trashed bool
+	// Type restricts results to articles, videos, or audio.
+	Type LinkType
+	// MaxReadingTime restricts results to links with a known reading
+	// time (or running time, for video and audio) no longer than this
+	// duration.
+	MaxReadingTime time.Duration
+	// Dead restricts results to links whose last check failed
+	// (404, 410, or unreachable).
+	Dead bool
+	// Sort orders the results; the zero value lists unread links in
+	// queue order: those moved into place first, then by priority, then
+	// newest first.
+	Sort SortOrder
+}
+
+// ImportOptions controls Import.
+type ImportOptions struct {
+	// DryRun reports what importing would do without changing anything.
+	DryRun bool
+}
+
+// ImportStatus is what Import did with a link.
+type ImportStatus string
+
+const (
+	// ImportAdded links were new.
+	ImportAdded ImportStatus = "added"
+	// ImportMerged links were already saved and gained something from the
+	// import (see SQLiteStorage.Import for how links merge).
+	ImportMerged ImportStatus = "merged"
+	// ImportSkipped links were already saved with nothing to merge.
+	ImportSkipped ImportStatus = "skipped"
+	// ImportFailed links couldn't be imported; the rest still were.
+	ImportFailed ImportStatus = "failed"
+)
+
+// ImportResult reports what Import did with one link.
+type ImportResult struct {
+	URL string `json:"url"`
+	// ID is the link's ID, empty if it failed or, in a dry run, would be
+	// added.
+	ID     string       `json:"id,omitempty"`
+	Status ImportStatus `json:"status"`
+	// Reason explains a skipped or failed link.
+	Reason string `json:"reason,omitempty"`
+}
+
+// SearchOptions specifies what Search looks through.
+type SearchOptions struct {
+	// Content also matches the archived article text.
+	Content bool
+	// ReadStatus keeps the links a list with this status would show;
+	// nil matches links whether read or not.
+	ReadStatus *ReadStatus
+	DateRange
+}
+
+// DateRange restricts results by when links were saved or read. Zero
+// times are ignored.
+type DateRange struct {
+	// Since keeps links saved at or after this time.
+	Since time.Time
+	// Before keeps links saved before this time.
+	Before time.Time
+	// ReadSince keeps links read at or after this time, which excludes
+	// unread links.
+	ReadSince time.Time
+	// ReadBefore keeps links read before this time, which excludes unread
+	// links.
+	ReadBefore time.Time
+}
+
+// FetchState is when a link's page was last fetched, with the HTTP
+// validators needed to fetch it again only if it changed.
+type FetchState struct {
+	LinkID       string
+	ETag         string
+	LastModified string
+	FetchedAt    time.Time
+}
+
+// SortOrder selects how List orders links.
+type SortOrder string
+
+const (
+	SortDefault  SortOrder = ""
+	SortNewest   SortOrder = "newest"
+	SortOldest   SortOrder = "oldest"
+	SortTitle    SortOrder = "title"
+	SortDomain   SortOrder = "domain"
+	SortPriority SortOrder = "priority"
+)
+
+// SortOrders lists the selectable sort orders, in the order the TUI
+// cycles through them.
+var SortOrders = []SortOrder{SortNewest, SortOldest, SortTitle, SortDomain, SortPriority}
+
+// ParseSortOrder parses a sort order name as accepted by `rl ls --sort`.
+func ParseSortOrder(s string) (SortOrder, error) {
+	if s == "" {
+		return SortDefault, nil
+	}
+	for _, order := range SortOrders {
+		if string(order) == strings.ToLower(s) {
+			return order, nil
+		}
+	}
+	return SortDefault, fmt.Errorf("invalid sort order %q (use newest, oldest, title, domain, or priority)", s)
+}
+
+// LinkType selects links by the kind of media they point to.
+type LinkType string
+
+const (
+	TypeAny     LinkType = ""
+	TypeArticle LinkType = "article"
+	TypeVideo   LinkType = LinkType(model.MediaVideo)
+	TypeAudio   LinkType = LinkType(model.MediaAudio)
+)
+
+// ParseLinkType parses a link type as accepted by `rl ls --type`.
+func ParseLinkType(s string) (LinkType, error) {
+	switch t := LinkType(strings.ToLower(s)); t {
+	case TypeAny, TypeArticle, TypeVideo, TypeAudio:
+		return t, nil
+	}
+	return TypeAny, fmt.Errorf("invalid type %q (use article, video, or audio)", s)
+}
+
+// Stats holds aggregate counts across all links.
+type Stats struct {
+	Total   int `json:"total"`
+	Unread  int `json:"unread"`
+	Read    int `json:"read"`
+	Snoozed int `json:"snoozed"`
+	// Domains lists link counts per domain, most common first.
+	Domains []Count `json:"domains"`
+	// Tags lists link counts per tag (case-insensitive), most common first.
+	Tags []Count `json:"tags"`
+	// Weekly holds the number of links added in each of the last
+	// StatsWeeks 7-day periods, oldest first; the last entry is the past
+	// seven days.
+	Weekly []int `json:"weekly_added"`
+}
+
+// ReadEvent records a link being marked read.
+type ReadEvent struct {
+	LinkID string
+	ReadAt time.Time
+}
+
+// StatsWeeks is the number of weeks covered by Stats.Weekly.
+const StatsWeeks = 12
+
+// Count pairs a name (domain, tag, ...) with the number of links it covers.
+type Count struct {
+	Name  string `db:"name" json:"name"`
+	Count int    `db:"count" json:"count"`
+}
+
+// Alias is a user-defined name for a link.
+type Alias struct {
+	Name   string `db:"name" json:"name"`
+	LinkID string `db:"link_id" json:"link_id"`
+}
+
+// SyncRecord ties a link to its copy on a remote service.
+type SyncRecord struct {
+	Service  string `db:"service"`
+	LinkID   string `db:"link_id"`
+	RemoteID string `db:"remote_id"`
+	// Read is the read state both sides had after the last sync.
+	Read bool `db:"read"`
+	// Digest fingerprints the link as last pushed, for services rl only
+	// pushes to.
+	Digest string `db:"digest"`
 }
 
 // ReadStatus indicates which links to include.
@@ -51,6 +410,8 @@ type ReadStatus int
 
 const (
 	ReadStatusUnread ReadStatus = iota
+	// ReadStatusRead is read links that aren't archived.
 	ReadStatusRead
 	ReadStatusAll
+	ReadStatusArchived
 )
@@ -0,0 +1,90 @@
+// Package streak computes reading streaks and weekly goal progress from
+// the read log.
+package streak
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/bunchhieng/rl/internal/storage"
+)
+
+// Summary is reading progress as of a point in time.
+type Summary struct {
+	// Current counts consecutive days with at least one link read, ending
+	// today, or yesterday while today's reading is still to come.
+	Current int `json:"current"`
+	// Best is the longest run of consecutive reading days.
+	Best int `json:"best"`
+	// Today and Week count links read today and since Monday.
+	Today int `json:"today"`
+	Week  int `json:"week"`
+	// Goal is the weekly target; 0 means none is set.
+	Goal int `json:"weekly_goal,omitempty"`
+}
+
+// Compute summarizes events as of now, in now's time zone. A link read
+// more than once on the same day counts once.
+func Compute(events []storage.ReadEvent, goal int, now time.Time) Summary {
+	loc := now.Location()
+	today := day(now)
+	weekStart := today.AddDate(0, 0, -((int(today.Weekday()) + 6) % 7))
+
+	sum := Summary{Goal: goal}
+	seen := make(map[string]bool)
+	days := make(map[time.Time]bool)
+	for _, e := range events {
+		d := day(e.ReadAt.In(loc))
+		key := e.LinkID + " " + d.Format(time.DateOnly)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		days[d] = true
+		if d.Equal(today) {
+			sum.Today++
+		}
+		if !d.Before(weekStart) && !d.After(today) {
+			sum.Week++
+		}
+	}
+
+	for d := range days {
+		// Count each run once, from its first day
+		if days[d.AddDate(0, 0, -1)] {
+			continue
+		}
+		run := 1
+		for days[d.AddDate(0, 0, run)] {
+			run++
+		}
+		sum.Best = max(sum.Best, run)
+	}
+
+	start := today
+	if !days[start] {
+		start = today.AddDate(0, 0, -1)
+	}
+	for days[start.AddDate(0, 0, -sum.Current)] {
+		sum.Current++
+	}
+	return sum
+}
+
+// Nudge returns a short reminder of what keeps the streak or reaches the
+// weekly goal, or "" when there is nothing to do.
+func (s Summary) Nudge() string {
+	switch {
+	case s.Current > 0 && s.Today == 0:
+		return fmt.Sprintf("read one today to keep your %d-day streak", s.Current)
+	case s.Goal > 0 && s.Week < s.Goal:
+		return fmt.Sprintf("%d more this week to reach your goal of %d", s.Goal-s.Week, s.Goal)
+	}
+	return ""
+}
+
+// day returns midnight at the start of t's day, in t's time zone.
+func day(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
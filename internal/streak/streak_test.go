@@ -0,0 +1,52 @@
+package streak
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bunchhieng/rl/internal/storage"
+)
+
+func TestCompute(t *testing.T) {
+	// Thursday afternoon
+	now := time.Date(2024, 3, 14, 15, 0, 0, 0, time.UTC)
+	daysAgo := func(n int) time.Time { return now.AddDate(0, 0, -n) }
+	events := []storage.ReadEvent{
+		// A four-day run a fortnight ago
+		{LinkID: "a", ReadAt: daysAgo(20)},
+		{LinkID: "b", ReadAt: daysAgo(19)},
+		{LinkID: "c", ReadAt: daysAgo(18)},
+		{LinkID: "d", ReadAt: daysAgo(17)},
+		// Monday to yesterday, twice on Tuesday
+		{LinkID: "e", ReadAt: daysAgo(3)},
+		{LinkID: "f", ReadAt: daysAgo(2)},
+		{LinkID: "g", ReadAt: daysAgo(2)},
+		{LinkID: "h", ReadAt: daysAgo(1)},
+		// Re-marking the same link the same day counts once
+		{LinkID: "h", ReadAt: daysAgo(1).Add(time.Minute)},
+	}
+
+	got := Compute(events, 10, now)
+	want := Summary{Current: 3, Best: 4, Today: 0, Week: 4, Goal: 10}
+	if got != want {
+		t.Errorf("Compute = %+v, want %+v", got, want)
+	}
+	if nudge := got.Nudge(); nudge != "read one today to keep your 3-day streak" {
+		t.Errorf("Unexpected nudge %q", nudge)
+	}
+
+	events = append(events, storage.ReadEvent{LinkID: "i", ReadAt: now})
+	got = Compute(events, 10, now)
+	if got.Current != 4 || got.Today != 1 || got.Week != 5 {
+		t.Errorf("After reading today: %+v", got)
+	}
+	if nudge := got.Nudge(); nudge != "5 more this week to reach your goal of 10" {
+		t.Errorf("Unexpected nudge %q", nudge)
+	}
+
+	// Missing yesterday breaks the streak
+	got = Compute(events[:4], 0, now)
+	if got.Current != 0 || got.Best != 4 || got.Nudge() != "" {
+		t.Errorf("Old run only: %+v, nudge %q", got, got.Nudge())
+	}
+}
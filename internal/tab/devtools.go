@@ -0,0 +1,65 @@
+package tab
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// defaultDevtoolsPort is the usual --remote-debugging-port for Chromium.
+const defaultDevtoolsPort = "9222"
+
+// devtoolsURL returns the DevTools target list endpoint, on the port in
+// $RL_DEVTOOLS_PORT or the default.
+func devtoolsURL() string {
+	port := os.Getenv("RL_DEVTOOLS_PORT")
+	if port == "" {
+		port = defaultDevtoolsPort
+	}
+	return "http://127.0.0.1:" + port + "/json/list"
+}
+
+type devtoolsTarget struct {
+	Type  string `json:"type"`
+	URL   string `json:"url"`
+	Title string `json:"title"`
+}
+
+// devtoolsTab asks a Chromium-based browser's DevTools endpoint for its
+// pages. Targets are listed most recently activated first.
+func devtoolsTab(endpoint string) (*Tab, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("devtools: %s", resp.Status)
+	}
+
+	var targets []devtoolsTarget
+	if err := json.NewDecoder(resp.Body).Decode(&targets); err != nil {
+		return nil, fmt.Errorf("devtools: %w", err)
+	}
+	for _, t := range targets {
+		if t.Type == "page" && isWebURL(t.URL) {
+			return &Tab{URL: t.URL, Title: t.Title}, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// isWebURL skips browser-internal pages such as chrome://newtab.
+func isWebURL(url string) bool {
+	return strings.HasPrefix(url, "http://") || strings.HasPrefix(url, "https://")
+}
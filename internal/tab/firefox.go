@@ -0,0 +1,181 @@
+package tab
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// mozLz4Magic starts Firefox's LZ4-compressed session files.
+var mozLz4Magic = []byte("mozLz40\x00")
+
+// maxMozLz4Size caps the decompressed size a session file may declare,
+// far above any real session, so a corrupt header can't make us
+// allocate gigabytes.
+const maxMozLz4Size = 256 << 20
+
+// firefoxSessionFiles returns the live session files of all Firefox
+// profiles, most recently written first.
+func firefoxSessionFiles() []string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil
+	}
+	var files []string
+	for _, root := range []string{
+		filepath.Join(home, ".mozilla", "firefox"),
+		filepath.Join(home, "snap", "firefox", "common", ".mozilla", "firefox"),
+		filepath.Join(home, ".var", "app", "org.mozilla.firefox", ".mozilla", "firefox"),
+	} {
+		matches, _ := filepath.Glob(filepath.Join(root, "*", "sessionstore-backups", "recovery.jsonlz4"))
+		files = append(files, matches...)
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return modTime(files[i]) > modTime(files[j])
+	})
+	return files
+}
+
+func modTime(path string) int64 {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0
+	}
+	return info.ModTime().UnixNano()
+}
+
+// firefoxSession is the part of sessionstore JSON needed to find the
+// selected tab. Indices are 1-based.
+type firefoxSession struct {
+	SelectedWindow int `json:"selectedWindow"`
+	Windows        []struct {
+		Selected int `json:"selected"`
+		Tabs     []struct {
+			Index   int `json:"index"`
+			Entries []struct {
+				URL   string `json:"url"`
+				Title string `json:"title"`
+			} `json:"entries"`
+		} `json:"tabs"`
+	} `json:"windows"`
+}
+
+// firefoxTab returns the selected tab of the selected window in the first
+// readable session file.
+func firefoxTab(files []string) (*Tab, error) {
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		if tab, err := parseFirefoxSession(data); err == nil {
+			return tab, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func parseFirefoxSession(data []byte) (*Tab, error) {
+	raw, err := decodeMozLz4(data)
+	if err != nil {
+		return nil, err
+	}
+	var session firefoxSession
+	if err := json.Unmarshal(raw, &session); err != nil {
+		return nil, fmt.Errorf("firefox session: %w", err)
+	}
+
+	w := session.SelectedWindow - 1
+	if w < 0 || w >= len(session.Windows) {
+		return nil, ErrNotFound
+	}
+	window := session.Windows[w]
+	t := window.Selected - 1
+	if t < 0 || t >= len(window.Tabs) {
+		return nil, ErrNotFound
+	}
+	tab := window.Tabs[t]
+	e := tab.Index - 1
+	if e < 0 || e >= len(tab.Entries) || !isWebURL(tab.Entries[e].URL) {
+		return nil, ErrNotFound
+	}
+	return &Tab{URL: tab.Entries[e].URL, Title: tab.Entries[e].Title}, nil
+}
+
+// decodeMozLz4 decompresses Firefox's mozLz4 format: a magic header, the
+// decompressed size, and a single LZ4 block.
+func decodeMozLz4(data []byte) ([]byte, error) {
+	if !bytes.HasPrefix(data, mozLz4Magic) || len(data) < len(mozLz4Magic)+4 {
+		return nil, errors.New("not a mozLz4 file")
+	}
+	size := binary.LittleEndian.Uint32(data[len(mozLz4Magic):])
+	block := data[len(mozLz4Magic)+4:]
+	// An LZ4 block decompresses to at most about 255 times its size
+	if size > maxMozLz4Size || int(size) > 255*len(block)+16 {
+		return nil, fmt.Errorf("mozLz4 file declares an implausible size of %d bytes", size)
+	}
+	return decodeLZ4Block(block, int(size))
+}
+
+var errCorruptLZ4 = errors.New("corrupt LZ4 data")
+
+// decodeLZ4Block decompresses a raw LZ4 block of known decompressed size.
+// Output past size is corrupt, so dst never grows beyond it.
+func decodeLZ4Block(src []byte, size int) ([]byte, error) {
+	dst := make([]byte, 0, size)
+	readLen := func(i *int, n int) (int, bool) {
+		if n != 15 {
+			return n, true
+		}
+		for {
+			if *i >= len(src) {
+				return 0, false
+			}
+			b := src[*i]
+			*i++
+			n += int(b)
+			if b != 255 {
+				return n, true
+			}
+		}
+	}
+
+	for i := 0; i < len(src); {
+		token := src[i]
+		i++
+
+		literals, ok := readLen(&i, int(token>>4))
+		if !ok || i+literals > len(src) || len(dst)+literals > size {
+			return nil, errCorruptLZ4
+		}
+		dst = append(dst, src[i:i+literals]...)
+		i += literals
+		if i == len(src) {
+			break // the last sequence has literals only
+		}
+
+		if i+2 > len(src) {
+			return nil, errCorruptLZ4
+		}
+		offset := int(src[i]) | int(src[i+1])<<8
+		i += 2
+		match, ok := readLen(&i, int(token&15))
+		if !ok || offset == 0 || offset > len(dst) || len(dst)+match+4 > size {
+			return nil, errCorruptLZ4
+		}
+		// Byte by byte: the match may overlap the bytes it produces
+		start := len(dst) - offset
+		for k := 0; k < match+4; k++ {
+			dst = append(dst, dst[start+k])
+		}
+	}
+	if len(dst) != size {
+		return nil, errCorruptLZ4
+	}
+	return dst, nil
+}
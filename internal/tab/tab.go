@@ -0,0 +1,94 @@
+// Package tab finds the URL and title of the active browser tab.
+package tab
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// Tab is a browser tab.
+type Tab struct {
+	URL   string
+	Title string
+}
+
+// ErrNotFound is returned when no supported browser has an open tab.
+var ErrNotFound = errors.New("no open browser tab found")
+
+// Current returns the active tab of a running browser. On macOS it asks
+// the frontmost (or else the first running) Safari or Chromium-based
+// browser via AppleScript. On Linux it asks Chromium-based browsers
+// started with --remote-debugging-port, then reads Firefox's session
+// file.
+func Current() (*Tab, error) {
+	switch runtime.GOOS {
+	case "darwin":
+		return macOSTab()
+	case "linux":
+		if tab, err := devtoolsTab(devtoolsURL()); err == nil {
+			return tab, nil
+		}
+		return firefoxTab(firefoxSessionFiles())
+	default:
+		return nil, fmt.Errorf("capturing the browser tab is not supported on %s", runtime.GOOS)
+	}
+}
+
+// macBrowsers maps application names to the AppleScript that prints the
+// URL and title of their front tab on two lines.
+var macBrowsers = []struct {
+	app    string
+	script string
+}{
+	{"Safari", `tell application "Safari" to return (URL of current tab of front window) & linefeed & (name of current tab of front window)`},
+	{"Google Chrome", chromiumScript("Google Chrome")},
+	{"Arc", chromiumScript("Arc")},
+	{"Brave Browser", chromiumScript("Brave Browser")},
+	{"Microsoft Edge", chromiumScript("Microsoft Edge")},
+	{"Chromium", chromiumScript("Chromium")},
+}
+
+func chromiumScript(app string) string {
+	return fmt.Sprintf(`tell application %q to return (URL of active tab of front window) & linefeed & (title of active tab of front window)`, app)
+}
+
+func macOSTab() (*Tab, error) {
+	front, _ := osascript(`tell application "System Events" to return name of first application process whose frontmost is true`)
+
+	// Prefer the browser in front, then any running one in list order
+	order := make([]int, 0, len(macBrowsers))
+	for i, b := range macBrowsers {
+		if b.app == front {
+			order = append([]int{i}, order...)
+		} else {
+			order = append(order, i)
+		}
+	}
+	for _, i := range order {
+		b := macBrowsers[i]
+		running, err := osascript(fmt.Sprintf(`return application %q is running`, b.app))
+		if err != nil || running != "true" {
+			continue
+		}
+		out, err := osascript(b.script)
+		if err != nil {
+			continue
+		}
+		url, title, _ := strings.Cut(out, "\n")
+		if url != "" {
+			return &Tab{URL: url, Title: title}, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func osascript(script string) (string, error) {
+	out, err := exec.Command("osascript", "-e", script).Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
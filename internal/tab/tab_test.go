@@ -0,0 +1,96 @@
+package tab
+
+import (
+	"encoding/binary"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// mozLz4 wraps data as a mozLz4 file holding one literal-only LZ4 block.
+func mozLz4(data string) []byte {
+	out := append([]byte{}, mozLz4Magic...)
+	out = binary.LittleEndian.AppendUint32(out, uint32(len(data)))
+	n := len(data)
+	if n < 15 {
+		out = append(out, byte(n<<4))
+	} else {
+		out = append(out, 15<<4)
+		for n -= 15; n >= 255; n -= 255 {
+			out = append(out, 255)
+		}
+		out = append(out, byte(n))
+	}
+	return append(out, data...)
+}
+
+func TestDecodeLZ4Block(t *testing.T) {
+	// "abc" as literals, then a 6-byte overlapping match at offset 3
+	block := []byte{0x32, 'a', 'b', 'c', 3, 0}
+	got, err := decodeLZ4Block(block, 9)
+	if err != nil || string(got) != "abcabcabc" {
+		t.Errorf("decodeLZ4Block = %q, %v; want %q", got, err, "abcabcabc")
+	}
+	if _, err := decodeLZ4Block([]byte{0x30, 'a'}, 3); err == nil {
+		t.Error("Expected error for truncated literals")
+	}
+	if _, err := decodeLZ4Block(block, 5); err == nil {
+		t.Error("Expected error for output past the declared size")
+	}
+}
+
+func TestDecodeMozLz4(t *testing.T) {
+	if got, err := decodeMozLz4(mozLz4("session")); err != nil || string(got) != "session" {
+		t.Errorf("decodeMozLz4 = %q, %v; want %q", got, err, "session")
+	}
+	// A header declaring more than the block could hold is refused before
+	// anything is allocated
+	for _, size := range []uint32{1 << 31, maxMozLz4Size + 1, 5000} {
+		data := append([]byte{}, mozLz4Magic...)
+		data = binary.LittleEndian.AppendUint32(data, size)
+		data = append(data, 0x32, 'a', 'b', 'c', 3, 0)
+		if _, err := decodeMozLz4(data); err == nil {
+			t.Errorf("Expected error for a declared size of %d", size)
+		}
+	}
+	if _, err := decodeMozLz4([]byte("mozLz40")); err == nil {
+		t.Error("Expected error for a truncated header")
+	}
+}
+
+func TestParseFirefoxSession(t *testing.T) {
+	session := `{"selectedWindow":1,"windows":[{"selected":2,"tabs":[
+		{"index":1,"entries":[{"url":"https://first.example","title":"First"}]},
+		{"index":2,"entries":[{"url":"https://old.example","title":"Old"},{"url":"https://go.dev/","title":"The Go Programming Language"}]}
+	]}]}`
+	tab, err := parseFirefoxSession(mozLz4(session))
+	if err != nil {
+		t.Fatalf("parseFirefoxSession error: %v", err)
+	}
+	if tab.URL != "https://go.dev/" || tab.Title != "The Go Programming Language" {
+		t.Errorf("Unexpected tab: %+v", tab)
+	}
+
+	if _, err := parseFirefoxSession([]byte("{}")); err == nil {
+		t.Error("Expected error for uncompressed data")
+	}
+}
+
+func TestDevtoolsTab(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[
+			{"type":"service_worker","url":"https://sw.example/","title":"sw"},
+			{"type":"page","url":"chrome://newtab/","title":"New Tab"},
+			{"type":"page","url":"https://example.com/a","title":"Example"}
+		]`))
+	}))
+	defer srv.Close()
+
+	tab, err := devtoolsTab(srv.URL)
+	if err != nil {
+		t.Fatalf("devtoolsTab error: %v", err)
+	}
+	if tab.URL != "https://example.com/a" || tab.Title != "Example" {
+		t.Errorf("Unexpected tab: %+v", tab)
+	}
+}
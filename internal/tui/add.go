@@ -0,0 +1,29 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bunchhieng/rl/internal/adder"
+	"github.com/bunchhieng/rl/internal/clipboard"
+	"github.com/bunchhieng/rl/internal/fetch"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// addFromClipboard saves the URL on the clipboard, fetching its metadata
+// and article text like `rl add` unless disabled in the config.
+func (m *appModel) addFromClipboard() tea.Cmd {
+	a, opts := adder.New(m.storage, fetch.NewClient(), m.attachments), m.addOptions
+	return m.startTask("Adding from clipboard", true, func() tea.Msg {
+		url, err := clipboard.ReadURL()
+		if err != nil {
+			return errorf("add: %v", err)
+		}
+		// Keep the link even if the page can't be fetched or copied
+		result, err := a.Add(context.Background(), url, opts)
+		if err != nil {
+			return errorf("add: %v", err)
+		}
+		return statusMsg{fmt.Sprintf("Added: %s", result.Link.URL)}
+	})
+}
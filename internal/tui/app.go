@@ -4,48 +4,134 @@ import (
 	"context"
 	"fmt"
 	"os/exec"
-	"runtime"
 	"strings"
 	"time"
 
+	"github.com/bunchhieng/rl/internal/adder"
+	"github.com/bunchhieng/rl/internal/attach"
+	"github.com/bunchhieng/rl/internal/browser"
+	"github.com/bunchhieng/rl/internal/clipboard"
 	"github.com/bunchhieng/rl/internal/model"
 	"github.com/bunchhieng/rl/internal/storage"
+	"github.com/bunchhieng/rl/internal/streak"
 	tea "github.com/charmbracelet/bubbletea"
 )
 
+// displayLocation, dateLayout, and relativeTimes control how timestamps
+// are rendered; Run sets them from Options.
 var (
-	estLocation *time.Location
+	displayLocation = time.Local
+	dateLayout      = defaultDateLayout
+	relativeTimes   bool
 )
 
-func init() {
-	var err error
-	estLocation, err = time.LoadLocation("America/New_York")
-	if err != nil {
-		estLocation = time.UTC
-	}
+const defaultDateLayout = "2006-01-02 15:04"
+
+// Options configures the TUI.
+type Options struct {
+	// Location is the time zone timestamps are shown in (default: local).
+	Location *time.Location
+	// DateLayout is a Go time layout for timestamps (default: "2006-01-02 15:04").
+	DateLayout string
+	// RelativeTimes shows timestamps as "3h ago" instead of in DateLayout.
+	RelativeTimes bool
+	// Browser is the command used to open links (see browser.New).
+	Browser string
+	// Keys maps action names ("down", "mark_read", ...) to the keys that
+	// trigger them, replacing the defaults for those actions.
+	Keys map[string][]string
+	// Theme names a built-in palette: dark (default), light, or solarized.
+	Theme string
+	// Colors overrides palette roles ("accent", "url", ...) with hex colors.
+	Colors map[string]string
+	// AddFetch, AddCanonicalize, and AddAttach mirror `rl add` for links
+	// added from the clipboard: fetch page metadata, normalize the URL,
+	// and keep a copy of documents in Attachments.
+	AddFetch        bool
+	AddCanonicalize bool
+	AddAttach       bool
+	Attachments     *attach.Store
+	// WeeklyGoal is the number of links to read each week, for the
+	// header nudge; 0 means none.
+	WeeklyGoal int
+	// Status is shown in the status bar on startup.
+	Status string
+	// DBPath is the database file, polled to pick up changes made by other
+	// processes. Empty disables live reload.
+	DBPath string
+	// SearchHistory is the file recent searches are kept in, so up and
+	// down can recall them in later sessions. Empty keeps them in memory.
+	SearchHistory string
 }
 
 type appModel struct {
-	storage       storage.Storage
-	links         []*model.Link
-	filtered      []*model.Link
-	selected      int
-	selectedIDs   map[string]bool // Track multi-selected link IDs
-	readStatus    storage.ReadStatus
-	searchQuery   string
-	searchMode    bool
-	confirmDelete bool
-	deleteLinkIDs []string // For multi-delete confirmation
-	width         int
-	height        int
-	err           error
-	statusMsg     string
-	statusTimer   *time.Timer
+	storage        storage.Storage
+	links          []*model.Link
+	filtered       []*model.Link
+	selected       int
+	selectedIDs    map[string]bool // Track multi-selected link IDs
+	visual         bool            // visual mode: rows from visualAnchor to selected are marked
+	visualAnchor   int
+	readStatus     storage.ReadStatus
+	sort           storage.SortOrder
+	tagFilter      string // only links with this tag are shown; "" shows all
+	tagPicker      *tagPicker
+	keys           keyMap
+	searchQuery    string
+	searchMode     bool
+	searchHistory  []string      // recent searches, oldest first
+	historyPath    string        // file the search history is saved in; "" keeps it in memory
+	historyBack    int           // how far back in searchHistory the recalled search is; 0 when not recalling
+	searchDraft    string        // search text typed before recalling older searches
+	commandMode    bool          // the command palette is open
+	commandInput   string        // typed into the command palette, without the ":"
+	fullText       bool          // search queries storage instead of filtering loaded links
+	searchSeq      int           // bumped on each full-text query change, to drop stale results
+	searchResults  []*model.Link // full-text matches, best first
+	searchErr      error         // why the current full-text query can't run
+	confirmDelete  bool
+	deleteLinkIDs  []string // For multi-delete confirmation
+	width          int
+	height         int
+	statusMsg      string
+	tasks          []task   // operations in progress, oldest first
+	taskSeq        int      // ID of the last task started
+	spinnerFrame   int      // spinner frame shown while tasks run
+	errors         []string // failures shown in the error panel, oldest first
+	statusTimer    *time.Timer
+	browser        *browser.Browser
+	showHelp       bool
+	helpOffset     int // first visible line of the help screen
+	editing        *editForm
+	showDetail     bool
+	annotations    map[string][]*model.Annotation // dated notes by link ID, for the detail view
+	summaries      map[string]*model.Summary      // summaries by link ID, for the detail view
+	stats          *storage.Stats                 // shown on the stats screen once loaded
+	dbPath         string
+	dbStamp        dbStamp
+	lastClick      time.Time // time of the last left click, for double clicks
+	addOptions     adder.Options
+	attachments    *attach.Store
+	lastClickIndex int
+	reader         *readerView
+	weeklyGoal     int
+	startStatus    string          // shown once the TUI starts
+	streak         *streak.Summary // reading streak for the header, once loaded
+	offset         int             // index of the first row shown in the list
+	sections       []listSection   // date sections of filtered, when sorted by date
+	collapsed      map[string]bool // names of collapsed date sections
+	inlineDetails  bool            // a line under each link shows its description and URL
+	total          int             // links matching the filter, loaded or not
+	loading        bool            // a page of links is being loaded
+	pendingBottom  bool            // jump to the last link once all are loaded
+	pendingSelect  bool            // select every link once all are loaded
 }
 
 type loadLinksMsg struct {
-	links []*model.Link
-	err   error
+	links  []*model.Link
+	total  int // links matching, including those not loaded
+	err    error
+	follow string // ID of a link to keep selected, if still listed
 }
 
 type statusMsg struct {
@@ -55,6 +141,8 @@ type statusMsg struct {
 func initialModel(s storage.Storage) appModel {
 	return appModel{
 		storage:    s,
+		browser:    browser.New(""),
+		keys:       defaultKeyMap(),
 		links:      []*model.Link{},
 		filtered:   []*model.Link{},
 		selected:   0,
@@ -66,21 +154,85 @@ func initialModel(s storage.Storage) appModel {
 }
 
 func (m appModel) Init() tea.Cmd {
-	return tea.Batch(
-		loadLinks(m.storage, m.readStatus),
+	cmds := []tea.Cmd{
+		loadLinks(m.storage, m.listOptions(), 0),
+		loadStreak(m.storage, m.weeklyGoal),
 		tea.EnterAltScreen,
-	)
+	}
+	if m.dbPath != "" {
+		cmds = append(cmds, watchDB(m.dbPath))
+	}
+	if m.startStatus != "" {
+		status := m.startStatus
+		cmds = append(cmds, func() tea.Msg { return statusMsg{status} })
+	}
+	return tea.Batch(cmds...)
 }
 
 func (m appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	next, cmd := m.update(msg)
+	// Keep the highlighted link in view whatever moved it, loading more
+	// links as it nears the end of those loaded
+	switch next := next.(type) {
+	case appModel:
+		next.scrollToSelection()
+		more := next.loadMore()
+		return next, tea.Batch(cmd, more)
+	case *appModel:
+		next.scrollToSelection()
+		more := next.loadMore()
+		return next, tea.Batch(cmd, more)
+	}
+	return next, cmd
+}
+
+func (m appModel) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmds []tea.Cmd
 
-	// Handle delete confirmation first
+	// Handle delete confirmation first; tasks and loads carry on behind it
 	if m.confirmDelete {
+		switch msg := msg.(type) {
+		case tea.KeyMsg:
+			return m.handleDeleteConfirmation(msg)
+		case tea.MouseMsg:
+			return m, nil
+		}
+	}
+
+	if m.showHelp {
 		if keyMsg, ok := msg.(tea.KeyMsg); ok {
-			return m.handleDeleteConfirmation(keyMsg)
+			return m.handleHelpInput(keyMsg)
+		}
+	}
+
+	if m.editing != nil {
+		if keyMsg, ok := msg.(tea.KeyMsg); ok {
+			return m.handleEditInput(keyMsg)
+		}
+	}
+
+	if m.tagPicker != nil {
+		if keyMsg, ok := msg.(tea.KeyMsg); ok {
+			return m.handleTagPickerInput(keyMsg)
+		}
+	}
+
+	if m.reader != nil {
+		if keyMsg, ok := msg.(tea.KeyMsg); ok {
+			return m.handleReaderInput(keyMsg)
+		}
+	}
+
+	if m.showDetail {
+		if keyMsg, ok := msg.(tea.KeyMsg); ok {
+			return m.handleDetailInput(keyMsg)
+		}
+	}
+
+	if m.stats != nil {
+		if keyMsg, ok := msg.(tea.KeyMsg); ok {
+			return m.handleStatsInput(keyMsg)
 		}
-		return m, nil
 	}
 
 	switch msg := msg.(type) {
@@ -93,90 +245,226 @@ func (m appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if m.searchMode {
 			return m.handleSearchInput(msg)
 		}
+		if m.commandMode {
+			return m.handleCommandInput(msg)
+		}
+
+		// ctrl+c quits whatever the bindings say
+		if msg.Type == tea.KeyCtrlC {
+			return m, tea.Quit
+		}
 
-		switch msg.String() {
-		case "q", "ctrl+c":
+		switch m.keys.action(msg.String()) {
+		case actionQuit:
 			return m, tea.Quit
 
-		case "j", "down":
+		case actionDown:
 			m.moveDown()
 			return m, nil
 
-		case "k", "up":
+		case actionUp:
 			m.moveUp()
 			return m, nil
 
-		case "g":
-			if len(msg.Runes) > 0 && msg.Runes[0] == 'g' {
-				m.selected = 0
-				return m, nil
-			}
+		case actionTop:
+			m.selected = 0
+			m.pendingBottom = false
+			return m, nil
 
-		case "G":
+		case actionBottom:
 			m.selected = len(m.filtered) - 1
 			if m.selected < 0 {
 				m.selected = 0
 			}
+			m.pendingBottom = m.moreToLoad()
 			return m, nil
 
-		case " ":
+		case actionPageDown:
+			m.selected = min(m.selected+m.listHeight(), max(0, len(m.filtered)-1))
+			return m, nil
+
+		case actionPageUp:
+			m.selected = max(m.selected-m.listHeight(), 0)
+			return m, nil
+
+		case actionToggle:
 			// Toggle selection of current item
 			m.toggleSelection()
 			return m, nil
 
-		case "ctrl+a":
+		case actionSelectAll:
 			// Select all visible items
 			m.selectAll()
+			m.pendingSelect = m.moreToLoad()
 			return m, nil
 
-		case "ctrl+d":
+		case actionDeselectAll:
 			// Deselect all
 			m.selectedIDs = make(map[string]bool)
+			m.pendingSelect = false
+			return m, nil
+
+		case actionVisual:
+			if m.visual {
+				m.commitVisual()
+			} else {
+				m.startVisual()
+			}
 			return m, nil
 
-		case "o", "enter":
-			return m, m.openLink()
+		case actionOpen:
+			cmd := m.openLink()
+			m.visual = false
+			return m, cmd
+
+		case actionCopy:
+			cmd := m.copyURLs()
+			m.visual = false
+			return m, cmd
+
+		case actionMarkRead:
+			cmd := m.markRead()
+			m.visual = false
+			return m, cmd
+
+		case actionMarkUnread:
+			cmd := m.markUnread()
+			m.visual = false
+			return m, cmd
 
-		case "d":
-			return m, m.markRead()
+		case actionMoveUp:
+			cmd := m.moveInQueue(-1)
+			return m, cmd
 
-		case "u":
-			return m, m.markUnread()
+		case actionMoveDown:
+			cmd := m.moveInQueue(1)
+			return m, cmd
 
-		case "r":
-			return m, m.promptDelete()
+		case actionRemove:
+			cmd := m.promptDelete()
+			m.visual = false
+			return m, cmd
 
-		case "/":
+		case actionSearch:
 			m.searchMode = true
 			m.searchQuery = ""
+			m.historyBack = 0
 			return m, nil
 
-		case "esc":
-			m.searchMode = false
-			m.searchQuery = ""
-			m.applyFilters()
+		case actionClearSearch:
+			if len(m.errors) > 0 {
+				m.errors = nil
+				return m, nil
+			}
+			if m.visual {
+				m.visual = false
+				return m, nil
+			}
+			m.clearSearch()
 			return m, nil
 
-		case "tab":
+		case actionFilter:
 			m.cycleFilter()
-			return m, loadLinks(m.storage, m.readStatus)
+			return m, loadLinks(m.storage, m.listOptions(), 0)
+
+		case actionTags:
+			return m, m.loadTagCounts()
+
+		case actionSort:
+			m.cycleSort()
+			return m, loadLinks(m.storage, m.listOptions(), 0)
+
+		case actionAdd:
+			cmd := m.addFromClipboard()
+			return m, cmd
+
+		case actionEdit:
+			m.startEdit()
+			return m, nil
+
+		case actionDetails:
+			m.showDetail = len(m.filtered) > 0
+			return m, m.loadDetail()
+
+		case actionReader:
+			cmd := m.openReader()
+			return m, cmd
+
+		case actionHelp:
+			m.showHelp = true
+			m.helpOffset = 0
+			return m, nil
+
+		case actionInlineDetails:
+			m.inlineDetails = !m.inlineDetails
+			return m, nil
+
+		case actionFoldSection:
+			m.foldSection()
+			return m, nil
+
+		case actionUnfoldSections:
+			m.unfoldSections()
+			return m, nil
 
-		case "a":
-			return m, m.showAddLink()
+		case actionReload:
+			load := m.reload()
+			cmd := m.startTask("Reloading", false, load)
+			return m, cmd
 
-		case "?":
-			return m, m.showHelp()
+		case actionStats:
+			return m, loadStats(m.storage)
 
-		case "ctrl+l":
-			return m, loadLinks(m.storage, m.readStatus)
+		case actionCommand:
+			m.commandMode = true
+			m.commandInput = ""
+			return m, nil
 		}
 
+	case contentMsg:
+		return m.handleContentMsg(msg)
+
+	case statsMsg:
+		return m.handleStatsMsg(msg)
+
+	case tagCountsMsg:
+		return m.handleTagCounts(msg)
+
+	case streakMsg:
+		return m.handleStreakMsg(msg)
+
+	case detailMsg:
+		return m.handleDetailMsg(msg)
+
+	case searchTickMsg:
+		return m.handleSearchTick(msg)
+
+	case searchResultsMsg:
+		return m.handleSearchResults(msg)
+
+	case dbChangedMsg:
+		return m.handleDBChanged(msg)
+
+	case pageMsg:
+		return m.handlePage(msg)
+
+	case taskDoneMsg:
+		return m.handleTaskDone(msg)
+
+	case spinnerTickMsg:
+		return m.handleSpinnerTick()
+
+	case errorMsg:
+		return m.handleError(msg)
+
+	case tea.MouseMsg:
+		return m.handleMouse(msg)
+
 	case loadLinksMsg:
 		if msg.err != nil {
-			m.err = msg.err
-			return m, nil
+			return m.handleError(errorMsg{msg.err})
 		}
-		m.links = msg.links
+		m.links, m.total = msg.links, msg.total
 		// Clean up selected IDs that no longer exist
 		newSelectedIDs := make(map[string]bool)
 		linkMap := make(map[string]bool)
@@ -190,6 +478,14 @@ func (m appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		m.selectedIDs = newSelectedIDs
 		m.applyFilters()
+		if msg.follow != "" {
+			for i, link := range m.filtered {
+				if link.ID == msg.follow {
+					m.selected = i
+					break
+				}
+			}
+		}
 		// Ensure selected index is valid after filtering
 		if m.selected >= len(m.filtered) {
 			if len(m.filtered) > 0 {
@@ -201,7 +497,13 @@ func (m appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if m.selected < 0 {
 			m.selected = 0
 		}
-		return m, nil
+		m.finishPending()
+		// Links may have been read or removed; refresh what depends on them
+		refresh := []tea.Cmd{loadStreak(m.storage, m.weeklyGoal)}
+		if m.fullText && m.searchQuery != "" {
+			refresh = append(refresh, m.runSearch())
+		}
+		return m, tea.Batch(refresh...)
 
 	case statusMsg:
 		m.statusMsg = msg.message
@@ -218,8 +520,17 @@ func (m appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 }
 
 func (m appModel) View() string {
-	if m.err != nil {
-		return fmt.Sprintf("Error: %v\n\nPress q to quit.", m.err)
+	if m.showHelp {
+		return m.renderHelp()
+	}
+	if m.reader != nil {
+		return m.renderReader()
+	}
+	if m.showDetail {
+		return m.renderDetail()
+	}
+	if m.stats != nil {
+		return m.renderStats()
 	}
 
 	var b strings.Builder
@@ -241,13 +552,50 @@ func (m appModel) View() string {
 	b.WriteString(list)
 	b.WriteString("\n")
 
-	// Status bar
-	statusBar := m.renderStatusBar()
-	b.WriteString(statusBar)
+	if len(m.errors) > 0 {
+		b.WriteString(m.renderErrorPanel())
+		b.WriteString("\n")
+	}
+
+	// Status bar, or the command palette in its place
+	if m.commandMode {
+		b.WriteString(m.renderCommandLine())
+	} else {
+		b.WriteString(m.renderStatusBar())
+	}
 
 	return b.String()
 }
 
+// listHeight is the number of links that fit between the header and the
+// status bar.
+func (m appModel) listHeight() int {
+	reserved := 4 // header, blank line, status bar, and a spare line if it wraps
+	if m.searchMode {
+		reserved++
+	}
+	reserved += m.errorPanelHeight()
+	return max(1, m.height-reserved)
+}
+
+// scrollToSelection adjusts the list offset so the highlighted link is
+// visible, with its details line if shown. Scrolling up to the first link
+// of a date section shows the section's header too.
+func (m *appModel) scrollToSelection() {
+	height := m.listHeight()
+	row := m.rowOf(m.selected)
+	if row < m.offset {
+		m.offset = row
+		if m.sections != nil && m.selected == m.sections[m.sectionOf(m.selected)].first {
+			m.offset--
+		}
+	}
+	if last := row + m.rowsPerLink() - 1; last >= m.offset+height {
+		m.offset = last - height + 1
+	}
+	m.offset = max(0, min(m.offset, len(m.rows())-height))
+}
+
 func (m *appModel) moveDown() {
 	if m.selected < len(m.filtered)-1 {
 		m.selected++
@@ -281,8 +629,8 @@ func (m *appModel) selectAll() {
 
 func (m *appModel) getSelectedLinks() []*model.Link {
 	var selected []*model.Link
-	for _, link := range m.filtered {
-		if m.selectedIDs[link.ID] {
+	for i, link := range m.filtered {
+		if m.isMarked(i) {
 			selected = append(selected, link)
 		}
 	}
@@ -290,6 +638,7 @@ func (m *appModel) getSelectedLinks() []*model.Link {
 }
 
 func (m *appModel) cycleFilter() {
+	m.visual = false
 	switch m.readStatus {
 	case storage.ReadStatusUnread:
 		m.readStatus = storage.ReadStatusRead
@@ -299,19 +648,55 @@ func (m *appModel) cycleFilter() {
 		m.readStatus = storage.ReadStatusUnread
 	}
 	m.selected = 0
+	m.pendingBottom, m.pendingSelect = false, false
+}
+
+// cycleSort advances to the next sort order, returning to the default
+// priority order after the last one.
+func (m *appModel) cycleSort() {
+	m.visual = false
+	orders := append([]storage.SortOrder{storage.SortDefault}, storage.SortOrders...)
+	for i, order := range orders {
+		if order == m.sort {
+			m.sort = orders[(i+1)%len(orders)]
+			break
+		}
+	}
+	m.selected = 0
+	m.pendingBottom, m.pendingSelect = false, false
+}
+
+// listOptions returns the storage query for the current filter and sort.
+func (m appModel) listOptions() storage.ListOptions {
+	return storage.ListOptions{
+		ReadStatus: m.readStatus,
+		Sort:       m.sort,
+	}
 }
 
 func (m *appModel) applyFilters() {
 	m.filtered = m.links
+	if m.fullText && m.searchQuery != "" && m.searchResults != nil {
+		m.filtered = m.searchResults
+	}
+
+	if m.tagFilter != "" {
+		filtered := []*model.Link{}
+		for _, link := range m.filtered {
+			if hasTag(link, m.tagFilter) {
+				filtered = append(filtered, link)
+			}
+		}
+		m.filtered = filtered
+	}
 
 	// Apply search filter
-	if m.searchQuery != "" {
+	if m.searchQuery != "" && !m.fullText {
 		query := strings.ToLower(m.searchQuery)
 		filtered := []*model.Link{}
 		for _, link := range m.filtered {
 			if strings.Contains(strings.ToLower(link.URL), query) ||
 				strings.Contains(strings.ToLower(link.Title), query) ||
-				strings.Contains(strings.ToLower(link.Note), query) ||
 				strings.Contains(strings.ToLower(link.Tags), query) {
 				filtered = append(filtered, link)
 			}
@@ -319,6 +704,8 @@ func (m *appModel) applyFilters() {
 		m.filtered = filtered
 	}
 
+	m.applySections()
+
 	// Ensure selected index is valid
 	if m.selected >= len(m.filtered) {
 		m.selected = len(m.filtered) - 1
@@ -331,90 +718,190 @@ func (m *appModel) applyFilters() {
 func (m *appModel) handleSearchInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
 	case "esc":
-		m.searchMode = false
-		m.searchQuery = ""
-		m.applyFilters()
+		m.clearSearch()
 		return m, nil
 
 	case "enter":
 		m.searchMode = false
 		m.applyFilters()
-		return m, nil
+		return m, m.rememberSearch()
+
+	case "tab":
+		return m, m.toggleFullText()
+
+	case "up", "ctrl+p":
+		m.recallSearch(1)
+		return m, m.searchChanged()
+
+	case "down", "ctrl+n":
+		m.recallSearch(-1)
+		return m, m.searchChanged()
 
 	case "backspace":
 		if len(m.searchQuery) > 0 {
 			m.searchQuery = m.searchQuery[:len(m.searchQuery)-1]
-			m.applyFilters()
+			return m, m.searchChanged()
 		}
 		return m, nil
 
 	default:
 		if len(msg.Runes) > 0 {
 			m.searchQuery += string(msg.Runes)
-			m.applyFilters()
+			return m, m.searchChanged()
 		}
 		return m, nil
 	}
 }
 
-func loadLinks(s storage.Storage, readStatus storage.ReadStatus) tea.Cmd {
+// searchChanged updates the list after the search text changes: at once
+// when filtering, after a pause when querying the full-text index.
+func (m *appModel) searchChanged() tea.Cmd {
+	if m.fullText {
+		return m.queueSearch()
+	}
+	m.applyFilters()
+	return nil
+}
+
+// clearSearch leaves search mode and shows the loaded links again.
+func (m *appModel) clearSearch() {
+	m.searchMode = false
+	m.searchQuery = ""
+	m.historyBack = 0
+	m.searchSeq++
+	m.searchResults, m.searchErr = nil, nil
+	m.applyFilters()
+}
+
+// loadLinks loads the first n links matching opts, or the first page if
+// n is smaller, and counts how many match in all.
+func loadLinks(s storage.Storage, opts storage.ListOptions, n int) tea.Cmd {
+	opts.Limit = max(n, pageSize)
 	return func() tea.Msg {
-		links, err := s.List(context.Background(), storage.ListOptions{
-			ReadStatus: readStatus,
-		})
-		return loadLinksMsg{links: links, err: err}
+		total, err := s.Count(context.Background(), opts)
+		if err != nil {
+			return loadLinksMsg{err: err}
+		}
+		links, err := s.List(context.Background(), opts)
+		return loadLinksMsg{links: links, total: total, err: err}
 	}
 }
 
-func (m *appModel) openLink() tea.Cmd {
+// targetLinks returns the links an action applies to: the selected links,
+// or the highlighted one when nothing is selected.
+func (m *appModel) targetLinks() []*model.Link {
+	if selected := m.getSelectedLinks(); len(selected) > 0 {
+		return selected
+	}
 	if len(m.filtered) == 0 || m.selected >= len(m.filtered) {
 		return nil
 	}
+	return []*model.Link{m.filtered[m.selected]}
+}
 
-	link := m.filtered[m.selected]
-	var cmd *exec.Cmd
-	switch runtime.GOOS {
-	case "darwin":
-		cmd = exec.Command("open", link.URL)
-	case "linux":
-		cmd = exec.Command("xdg-open", link.URL)
-	case "windows":
-		cmd = exec.Command("cmd", "/c", "start", link.URL)
-	default:
-		return func() tea.Msg {
-			return statusMsg{"Unsupported OS"}
+func (m *appModel) openLink() tea.Cmd {
+	links := m.targetLinks()
+	if len(links) == 0 {
+		return nil
+	}
+
+	cmds := make([]*exec.Cmd, len(links))
+	for i, link := range links {
+		cmd, err := m.browser.Command(link.URL)
+		if err != nil {
+			return func() tea.Msg {
+				return statusMsg{err.Error()}
+			}
+		}
+		cmds[i] = cmd
+	}
+
+	status := fmt.Sprintf("Opened: %s", links[0].URL)
+	if len(links) > 1 {
+		status = fmt.Sprintf("Opened %d links", len(links))
+	}
+
+	// A configured command may be a terminal browser, so hand it the screen,
+	// one link at a time
+	if m.browser.Custom() {
+		steps := make([]tea.Cmd, 0, len(cmds)+1)
+		for _, cmd := range cmds {
+			steps = append(steps, tea.ExecProcess(cmd, func(err error) tea.Msg {
+				if err != nil {
+					return errorf("open: %v", err)
+				}
+				return nil
+			}))
 		}
+		steps = append(steps, func() tea.Msg {
+			return statusMsg{status}
+		})
+		return tea.Sequence(steps...)
 	}
 
-	go cmd.Run()
+	for _, cmd := range cmds {
+		go cmd.Run()
+	}
 
 	return func() tea.Msg {
-		return statusMsg{fmt.Sprintf("Opened: %s", link.URL)}
+		return statusMsg{status}
 	}
 }
 
-func (m *appModel) markRead() tea.Cmd {
-	if len(m.filtered) == 0 || m.selected >= len(m.filtered) {
+// copyURLs copies the selected links' URLs, one per line, or the
+// highlighted link's URL when nothing is selected.
+func (m *appModel) copyURLs() tea.Cmd {
+	links := m.targetLinks()
+	if len(links) == 0 {
 		return nil
 	}
 
-	link := m.filtered[m.selected]
-	if link.IsRead() {
-		return func() tea.Msg {
-			return statusMsg{"Already marked as read"}
+	urls := make([]string, len(links))
+	for i, link := range links {
+		urls[i] = link.URL
+	}
+	return func() tea.Msg {
+		if err := clipboard.Write(strings.Join(urls, "\n")); err != nil {
+			return errorf("copy: %v", err)
+		}
+		if len(urls) == 1 {
+			return statusMsg{fmt.Sprintf("Copied: %s", urls[0])}
 		}
+		return statusMsg{fmt.Sprintf("Copied %d URLs", len(urls))}
 	}
+}
 
-	return tea.Batch(
-		func() tea.Msg {
-			err := m.storage.MarkRead(context.Background(), link.ID)
-			if err != nil {
-				return statusMsg{fmt.Sprintf("Error: %v", err)}
+func (m *appModel) markRead() tea.Cmd {
+	links := m.targetLinks()
+	if len(links) == 0 {
+		return nil
+	}
+
+	s := m.storage
+	return m.startTask("Marking as read", true, func() tea.Msg {
+		var errs []string
+		count := 0
+		for _, link := range links {
+			if link.IsRead() {
+				continue
+			}
+			if err := s.MarkRead(context.Background(), link.ID); err != nil {
+				errs = append(errs, fmt.Sprintf("%s: %v", link.ID, err))
+			} else {
+				count++
 			}
+		}
+		if len(errs) > 0 {
+			return errorf("mark as read: %s", strings.Join(errs, ", "))
+		}
+		switch count {
+		case 0:
+			return statusMsg{"Already marked as read"}
+		case 1:
 			return statusMsg{"Marked as read"}
-		},
-		loadLinks(m.storage, m.readStatus),
-	)
+		}
+		return statusMsg{fmt.Sprintf("Marked %d links as read", count)}
+	})
 }
 
 func (m *appModel) markUnread() tea.Cmd {
@@ -448,42 +935,36 @@ func (m *appModel) markUnread() tea.Cmd {
 		}
 	}
 
-	return tea.Batch(
-		func() tea.Msg {
-			var errs []string
-			count := 0
-			for _, link := range selected {
-				if link.IsRead() {
-					if err := m.storage.MarkUnread(context.Background(), link.ID); err != nil {
-						errs = append(errs, fmt.Sprintf("%s: %v", link.ID, err))
-					} else {
-						count++
-					}
+	s := m.storage
+	return m.startTask("Marking as unread", true, func() tea.Msg {
+		var errs []string
+		count := 0
+		for _, link := range selected {
+			if link.IsRead() {
+				if err := s.MarkUnread(context.Background(), link.ID); err != nil {
+					errs = append(errs, fmt.Sprintf("%s: %v", link.ID, err))
+				} else {
+					count++
 				}
 			}
-			if len(errs) > 0 {
-				return statusMsg{fmt.Sprintf("Error: %s", strings.Join(errs, ", "))}
-			}
-			if count == 0 {
-				return statusMsg{"Already unread"}
-			}
-			if count == 1 {
-				return statusMsg{"Marked as unread"}
-			}
-			return statusMsg{fmt.Sprintf("Marked %d links as unread", count)}
-		},
-		loadLinks(m.storage, m.readStatus),
-	)
+		}
+		if len(errs) > 0 {
+			return errorf("mark as unread: %s", strings.Join(errs, ", "))
+		}
+		if count == 0 {
+			return statusMsg{"Already unread"}
+		}
+		if count == 1 {
+			return statusMsg{"Marked as unread"}
+		}
+		return statusMsg{fmt.Sprintf("Marked %d links as unread", count)}
+	})
 }
 
 func (m *appModel) promptDelete() tea.Cmd {
-	selected := m.getSelectedLinks()
+	selected := m.targetLinks()
 	if len(selected) == 0 {
-		// If nothing is selected, delete the currently highlighted item
-		if len(m.filtered) == 0 || m.selected >= len(m.filtered) {
-			return nil
-		}
-		selected = []*model.Link{m.filtered[m.selected]}
+		return nil
 	}
 
 	m.confirmDelete = true
@@ -502,30 +983,26 @@ func (m *appModel) handleDeleteConfirmation(msg tea.KeyMsg) (tea.Model, tea.Cmd)
 		m.deleteLinkIDs = nil
 		m.selectedIDs = make(map[string]bool) // Clear selections after delete
 
-		// Delete links synchronously first
-		var errs []string
-		for _, id := range linkIDs {
-			if err := m.storage.Delete(context.Background(), id); err != nil {
-				errs = append(errs, fmt.Sprintf("%s: %v", id, err))
-			}
+		label := "Moving link to the trash"
+		if len(linkIDs) > 1 {
+			label = fmt.Sprintf("Moving %d links to the trash", len(linkIDs))
 		}
-
-		// Then reload links
-		var statusMsgText string
-		if len(errs) > 0 {
-			statusMsgText = fmt.Sprintf("Error deleting: %s", strings.Join(errs, ", "))
-		} else if len(linkIDs) == 1 {
-			statusMsgText = "Deleted link"
-		} else {
-			statusMsgText = fmt.Sprintf("Deleted %d links", len(linkIDs))
-		}
-
-		return m, tea.Batch(
-			func() tea.Msg {
-				return statusMsg{statusMsgText}
-			},
-			loadLinks(m.storage, m.readStatus),
-		)
+		s := m.storage
+		return m, m.startTask(label, true, func() tea.Msg {
+			var errs []string
+			for _, id := range linkIDs {
+				if err := s.Trash(context.Background(), id); err != nil {
+					errs = append(errs, fmt.Sprintf("%s: %v", id, err))
+				}
+			}
+			if len(errs) > 0 {
+				return errorf("delete: %s", strings.Join(errs, ", "))
+			}
+			if len(linkIDs) == 1 {
+				return statusMsg{"Moved link to the trash"}
+			}
+			return statusMsg{fmt.Sprintf("Moved %d links to the trash", len(linkIDs))}
+		})
 
 	case "n", "N", "esc":
 		m.confirmDelete = false
@@ -537,23 +1014,40 @@ func (m *appModel) handleDeleteConfirmation(msg tea.KeyMsg) (tea.Model, tea.Cmd)
 	}
 }
 
-func (m *appModel) showAddLink() tea.Cmd {
-	// TODO: Implement add link modal
-	return func() tea.Msg {
-		return statusMsg{"Add link: Not implemented yet. Use 'rl add <url>' from CLI"}
+// Run starts the TUI application
+func Run(s storage.Storage, opts Options) error {
+	if opts.Location != nil {
+		displayLocation = opts.Location
 	}
-}
-
-func (m *appModel) showHelp() tea.Cmd {
-	// TODO: Implement help screen
-	return func() tea.Msg {
-		return statusMsg{"Help: q=quit, j/k=nav, o=open, d=done, u=undo, r=remove, /=search, tab=filter"}
+	if opts.DateLayout != "" {
+		dateLayout = opts.DateLayout
 	}
-}
-
-// Run starts the TUI application
-func Run(s storage.Storage) error {
-	p := tea.NewProgram(initialModel(s), tea.WithAltScreen())
-	_, err := p.Run()
+	relativeTimes = opts.RelativeTimes
+	keys, err := newKeyMap(opts.Keys)
+	if err != nil {
+		return fmt.Errorf("keybindings: %w", err)
+	}
+	colors, err := newPalette(opts.Theme, opts.Colors)
+	if err != nil {
+		return fmt.Errorf("theme: %w", err)
+	}
+	applyPalette(colors)
+	m := initialModel(s)
+	m.browser = browser.New(opts.Browser)
+	m.keys = keys
+	m.addOptions = adder.Options{Fetch: opts.AddFetch, Canonicalize: opts.AddCanonicalize, Attach: opts.AddAttach}
+	m.attachments = opts.Attachments
+	m.weeklyGoal = opts.WeeklyGoal
+	m.startStatus = opts.Status
+	if opts.SearchHistory != "" {
+		m.historyPath = opts.SearchHistory
+		m.searchHistory = loadSearchHistory(opts.SearchHistory)
+	}
+	if opts.DBPath != "" && opts.DBPath != ":memory:" {
+		m.dbPath = opts.DBPath
+		m.dbStamp = statDB(opts.DBPath)
+	}
+	p := tea.NewProgram(m, tea.WithAltScreen(), tea.WithMouseCellMotion())
+	_, err = p.Run()
 	return err
 }
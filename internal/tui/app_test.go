@@ -0,0 +1,152 @@
+package tui
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bunchhieng/rl/internal/model"
+	"github.com/bunchhieng/rl/internal/storage"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// fakeClipboard installs an xclip stub that keeps what is copied, like the
+// clipboard package's tests do, and returns a function reading it back.
+func fakeClipboard(t *testing.T) func() string {
+	t.Helper()
+	if runtime.GOOS != "linux" {
+		t.Skip("clipboard stub uses the Linux tool names")
+	}
+	dir := t.TempDir()
+	data := filepath.Join(dir, "data")
+	script := "#!/bin/sh\ncat > " + data + "\n"
+	if err := os.WriteFile(filepath.Join(dir, "xclip"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv("WAYLAND_DISPLAY", "")
+	return func() string {
+		copied, _ := os.ReadFile(data)
+		return string(copied)
+	}
+}
+
+func TestCopyURLs(t *testing.T) {
+	copied := fakeClipboard(t)
+	m := initialModel(nil)
+	m.selectedIDs = map[string]bool{}
+	for _, id := range []string{"a", "b", "c"} {
+		m.filtered = append(m.filtered, &model.Link{ID: id, URL: "https://example.com/" + id})
+	}
+	m.links = m.filtered
+	press := func(key string) tea.Msg {
+		t.Helper()
+		next, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+		m = next.(appModel)
+		if cmd == nil {
+			t.Fatalf("Expected %s to copy", key)
+		}
+		return cmd()
+	}
+
+	// The highlighted link's URL
+	m.selected = 1
+	if msg, ok := press("y").(statusMsg); !ok || msg.message != "Copied: https://example.com/b" {
+		t.Errorf("Expected a copied status, got %#v", msg)
+	}
+	if got := copied(); got != "https://example.com/b" {
+		t.Errorf("Copied %q, want the highlighted link's URL", got)
+	}
+
+	// The selected links' URLs, one per line
+	m.selectedIDs["a"], m.selectedIDs["c"] = true, true
+	if msg, ok := press("y").(statusMsg); !ok || msg.message != "Copied 2 URLs" {
+		t.Errorf("Expected a count of URLs copied, got %#v", msg)
+	}
+	if got, want := copied(), "https://example.com/a\nhttps://example.com/c"; got != want {
+		t.Errorf("Copied %q, want %q", got, want)
+	}
+}
+
+func TestSelectedLinksDoneAndOpen(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("opener stub uses the Linux tool name")
+	}
+	dir := t.TempDir()
+	opened := filepath.Join(dir, "opened")
+	script := "#!/bin/sh\necho \"$1\" >> " + opened + "\n"
+	if err := os.WriteFile(filepath.Join(dir, "xdg-open"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv("BROWSER", "")
+
+	s, err := storage.NewSQLiteStorage(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer s.Close()
+	ctx := context.Background()
+	for _, url := range []string{"https://example.com/a", "https://example.com/b", "https://example.com/c"} {
+		if _, err := s.Add(ctx, &model.Link{URL: url}); err != nil {
+			t.Fatalf("Add failed: %v", err)
+		}
+	}
+
+	m := initialModel(s)
+	next, _ := m.update(loadLinks(s, m.listOptions(), 0)())
+	m = next.(appModel)
+	if len(m.filtered) != 3 {
+		t.Fatalf("Expected 3 links loaded, got %d", len(m.filtered))
+	}
+	first, highlighted, last := m.filtered[0], m.filtered[1], m.filtered[2]
+	m.selectedIDs[first.ID], m.selectedIDs[last.ID] = true, true
+	m.selected = 1
+	press := func(key string) tea.Cmd {
+		t.Helper()
+		next, cmd := m.update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+		m = next.(appModel)
+		if cmd == nil {
+			t.Fatalf("Expected %s to act on the selection", key)
+		}
+		return cmd
+	}
+
+	// Open launches every selected link, not the highlighted one
+	if msg, ok := press("o")().(statusMsg); !ok || msg.message != "Opened 2 links" {
+		t.Errorf("Expected both selected links opened, got %#v", msg)
+	}
+	var urls []string
+	for deadline := time.Now().Add(5 * time.Second); len(urls) < 2 && time.Now().Before(deadline); {
+		time.Sleep(10 * time.Millisecond)
+		data, _ := os.ReadFile(opened)
+		urls = strings.Fields(string(data))
+	}
+	want := []string{first.URL, last.URL}
+	sort.Strings(urls)
+	sort.Strings(want)
+	if !slices.Equal(urls, want) {
+		t.Errorf("Opened %q, want %q", urls, want)
+	}
+
+	// Done marks every selected link read
+	done := taskResult(t, press("d")())
+	if msg, ok := done.msg.(statusMsg); !ok || msg.message != "Marked 2 links as read" {
+		t.Errorf("Expected both selected links marked read, got %#v", done.msg)
+	}
+	for _, link := range []*model.Link{first, highlighted, last} {
+		got, err := s.Get(ctx, link.ID)
+		if err != nil {
+			t.Fatalf("Get failed: %v", err)
+		}
+		if want := link != highlighted; got.IsRead() != want {
+			t.Errorf("%s read = %v, want %v", link.URL, got.IsRead(), want)
+		}
+	}
+}
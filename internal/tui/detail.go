@@ -0,0 +1,165 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bunchhieng/rl/internal/model"
+	"github.com/bunchhieng/rl/internal/reltime"
+	"github.com/bunchhieng/rl/internal/storage"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+func (m appModel) handleDetailInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if msg.Type == tea.KeyCtrlC {
+		return m, tea.Quit
+	}
+	switch m.keys.viewAction(msg.String(), actionDetails) {
+	case actionBack:
+		m.showDetail = false
+	case actionDown:
+		m.moveDown()
+		return m, m.loadDetail()
+	case actionUp:
+		m.moveUp()
+		return m, m.loadDetail()
+	case actionOpen:
+		cmd := m.openLink()
+		return m, cmd
+	case actionEdit:
+		m.showDetail = false
+		m.startEdit()
+	case actionReader:
+		cmd := m.openReader()
+		return m, cmd
+	}
+	return m, nil
+}
+
+type detailMsg struct {
+	linkID      string
+	annotations []*model.Annotation
+	summary     *model.Summary
+	err         error
+}
+
+// loadDetailData fetches a link's dated notes and summary for the detail
+// view.
+func loadDetailData(s storage.Storage, linkID string) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		annotations, err := s.Annotations(ctx, linkID)
+		if err != nil {
+			return detailMsg{linkID: linkID, err: err}
+		}
+		summary, err := s.Summary(ctx, linkID)
+		if err == model.ErrNotFound {
+			err = nil
+		}
+		return detailMsg{linkID: linkID, annotations: annotations, summary: summary, err: err}
+	}
+}
+
+// loadDetail refreshes the annotations and summary of the link the detail
+// view shows.
+func (m appModel) loadDetail() tea.Cmd {
+	if m.storage == nil || len(m.filtered) == 0 || m.selected >= len(m.filtered) {
+		return nil
+	}
+	return loadDetailData(m.storage, m.filtered[m.selected].ID)
+}
+
+// handleDetailMsg caches a link's annotations and summary; on error the
+// detail view just shows what it had.
+func (m appModel) handleDetailMsg(msg detailMsg) (tea.Model, tea.Cmd) {
+	if msg.err != nil {
+		return m, nil
+	}
+	if m.annotations == nil {
+		m.annotations = make(map[string][]*model.Annotation)
+		m.summaries = make(map[string]*model.Summary)
+	}
+	m.annotations[msg.linkID] = msg.annotations
+	m.summaries[msg.linkID] = msg.summary
+	return m, nil
+}
+
+func (m appModel) renderDetail() string {
+	var b strings.Builder
+	b.WriteString(headerStyle.Render("rl - Link details"))
+	b.WriteString("\n\n")
+
+	if len(m.filtered) == 0 || m.selected >= len(m.filtered) {
+		b.WriteString("No link selected.\n")
+		return b.String()
+	}
+	link := m.filtered[m.selected]
+
+	// Wrap long values to the space right of the labels
+	wrap := lipgloss.NewStyle().Width(max(20, m.width-12))
+	row := func(label, value string, style lipgloss.Style) {
+		if value == "" {
+			return
+		}
+		b.WriteString(lipgloss.JoinHorizontal(lipgloss.Top,
+			detailLabelStyle.Render(label), style.Inherit(wrap).Render(value)))
+		b.WriteString("\n")
+	}
+	plain := lipgloss.NewStyle()
+
+	row("Title", link.Title, unreadStyle)
+	row("URL", link.URL, urlStyle)
+	row("About", link.Description, plain)
+	if summary := m.summaries[link.ID]; summary != nil {
+		row("Summary", summary.Text, plain)
+	}
+	row("Author", link.Author, plain)
+	row("Tags", link.Tags, tagStyle)
+	row("Priority", link.Priority.String(), plain)
+	row("Added", formatTime(link.CreatedAt), readStyle)
+	if link.ReadAt != nil {
+		row("Read", formatTime(*link.ReadAt), readStyle)
+	}
+	if link.IsSnoozed(time.Now()) {
+		row("Snoozed", reltime.Until(link.SnoozedUntil.In(displayLocation), time.Now(), relativeTimes, dateLayout), readStyle)
+	}
+	switch {
+	case link.ReadingSeconds > 0 && link.MediaType != "":
+		row("Length", fmt.Sprintf("%s%dm", link.MediaMark(), (link.ReadingSeconds+59)/60), readStyle)
+	case link.ReadingSeconds > 0:
+		row("Length", fmt.Sprintf("%d words · %dm", link.WordCount, (link.ReadingSeconds+59)/60), readStyle)
+	}
+	if link.CheckedAt != nil {
+		row("Checked", checkSummary(link), readStyle)
+	}
+	row("ID", link.ID, readStyle)
+
+	// Dated notes, oldest first, each under its date
+	for i, a := range m.annotations[link.ID] {
+		label := ""
+		if i == 0 {
+			b.WriteString("\n")
+			label = "Notes"
+		}
+		row(label, formatTime(a.CreatedAt), readStyle)
+		row("", a.Text, plain)
+	}
+
+	b.WriteString("\n")
+	b.WriteString(statusBarStyle.Width(m.width).Render(
+		fmt.Sprintf("%d/%d  |  %s", m.selected+1, len(m.filtered), hints(
+			m.keys.hint("prev/next", actionDown, actionUp), m.keys.hint("open", actionOpen),
+			m.keys.hint("read", actionReader), m.keys.hint("edit", actionEdit), "[esc] back"))))
+	return b.String()
+}
+
+func checkSummary(link *model.Link) string {
+	status := "unreachable"
+	if link.HTTPStatus != 0 {
+		status = fmt.Sprintf("HTTP %d", link.HTTPStatus)
+	}
+	return fmt.Sprintf("%s on %s", status, formatTime(*link.CheckedAt))
+}
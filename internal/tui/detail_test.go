@@ -0,0 +1,121 @@
+package tui
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/bunchhieng/rl/internal/model"
+	"github.com/bunchhieng/rl/internal/storage"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestDetailNotesAndSummary(t *testing.T) {
+	s, err := storage.NewSQLiteStorage(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer s.Close()
+
+	ctx := context.Background()
+	link, _ := s.Add(ctx, &model.Link{URL: "https://a.example", Title: "Async Rust"})
+	for _, text := range []string{"first impressions", "finished it"} {
+		if _, err := s.AddAnnotation(ctx, link.ID, text); err != nil {
+			t.Fatalf("AddAnnotation failed: %v", err)
+		}
+	}
+
+	if err := s.SetSummary(ctx, &model.Summary{LinkID: link.ID, Text: "Rust's async model, explained."}); err != nil {
+		t.Fatalf("SetSummary failed: %v", err)
+	}
+
+	m := initialModel(s)
+	m.links = []*model.Link{link}
+	m.filtered = m.links
+	m.showDetail = true
+	cmd := m.loadDetail()
+	if cmd == nil {
+		t.Fatal("Expected the detail view to load annotations")
+	}
+	next, _ := m.Update(cmd())
+	m = next.(appModel)
+
+	view := m.renderDetail()
+	first, second := strings.Index(view, "first impressions"), strings.Index(view, "finished it")
+	if first < 0 || second < first {
+		t.Errorf("Expected both notes in order in the detail view, got:\n%s", view)
+	}
+	if !strings.Contains(view, "Rust's async model, explained.") {
+		t.Errorf("Expected the summary in the detail view, got:\n%s", view)
+	}
+
+	if _, cmd := m.handleDetailInput(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'j'}}); cmd == nil {
+		t.Error("Moving in the detail view should load the next link's notes")
+	}
+}
+
+func TestInlineDetails(t *testing.T) {
+	m := initialModel(nil)
+	m.height = 10 // 6 visible rows
+	for i := range 5 {
+		m.filtered = append(m.filtered, &model.Link{URL: "https://example.com/" + string(rune('a'+i)), Description: "worth\na look"})
+	}
+
+	next, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'i'}})
+	m = next.(appModel)
+	if !m.inlineDetails || len(m.rows()) != 10 {
+		t.Fatalf("Expected a details row under each of 5 links, got %d rows", len(m.rows()))
+	}
+	if list := m.renderList(); !strings.Contains(list, "worth a look · https://example.com/a") {
+		t.Errorf("Expected the description and URL under the link, got:\n%s", list)
+	}
+
+	// The highlighted link's details line is kept on screen too
+	m.selected = 3
+	m.scrollToSelection()
+	if m.offset != 2 {
+		t.Errorf("Expected the list scrolled to row 2, got %d", m.offset)
+	}
+
+	// Clicking a details line selects its link
+	next, _ = m.handleMouse(tea.MouseMsg{Button: tea.MouseButtonLeft, Action: tea.MouseActionPress, Y: m.listTop() + 1})
+	if m = next.(appModel); m.selected != 1 {
+		t.Errorf("Click on a details line selected %d, want 1", m.selected)
+	}
+}
+
+func TestDetailView(t *testing.T) {
+	m := initialModel(nil)
+	m.width, m.height = 80, 20
+	m.links = []*model.Link{
+		{ID: "a", URL: "https://a.example", Title: "First", Description: "What it's about", Tags: "go", Priority: model.PriorityHigh},
+		{ID: "b", URL: "https://b.example", Title: "Second"},
+	}
+	m.filtered = m.links
+	press := func(key tea.KeyMsg) {
+		t.Helper()
+		next, _ := m.Update(key)
+		m = next.(appModel)
+	}
+
+	press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'p'}})
+	if !m.showDetail {
+		t.Fatal("Expected p to open the detail view")
+	}
+	view := m.renderDetail()
+	for _, want := range []string{"First", "https://a.example", "What it's about", "go", "high", "1/2"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("Expected %q in the detail view, got:\n%s", want, view)
+		}
+	}
+
+	// Moving shows the next link, and esc goes back to the list there
+	press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'j'}})
+	if view := m.renderDetail(); !strings.Contains(view, "Second") || strings.Contains(view, "About") {
+		t.Errorf("Expected the second link, without an About row, got:\n%s", view)
+	}
+	press(tea.KeyMsg{Type: tea.KeyEsc})
+	if m.showDetail || m.selected != 1 {
+		t.Errorf("Expected esc to close the detail view on the second link, got shown=%v selected=%d", m.showDetail, m.selected)
+	}
+}
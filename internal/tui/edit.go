@@ -0,0 +1,111 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/bunchhieng/rl/internal/model"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Edit form fields, in tab order. The note field starts empty: what is
+// typed there is added to the link's dated notes.
+const (
+	editTitle = iota
+	editTags
+	editNote
+	editFieldCount
+)
+
+var editLabels = [editFieldCount]string{"Title", "Tags", "Add note"}
+
+// editForm holds the in-progress edit of a link's metadata.
+type editForm struct {
+	link   *model.Link
+	values [editFieldCount]string
+	focus  int
+}
+
+func (m *appModel) startEdit() {
+	if len(m.filtered) == 0 || m.selected >= len(m.filtered) {
+		return
+	}
+	link := m.filtered[m.selected]
+	m.editing = &editForm{
+		link:   link,
+		values: [editFieldCount]string{editTitle: link.Title, editTags: link.Tags},
+	}
+}
+
+func (m appModel) handleEditInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	form := *m.editing
+	m.editing = &form
+	value := &form.values[form.focus]
+
+	switch msg.String() {
+	case "esc":
+		m.editing = nil
+		return m, nil
+	case "ctrl+c":
+		return m, tea.Quit
+	case "enter", "ctrl+s":
+		m.editing = nil
+		cmd := m.saveEdit(form)
+		return m, cmd
+	case "tab", "down":
+		form.focus = (form.focus + 1) % editFieldCount
+	case "shift+tab", "up":
+		form.focus = (form.focus + editFieldCount - 1) % editFieldCount
+	case "backspace":
+		if runes := []rune(*value); len(runes) > 0 {
+			*value = string(runes[:len(runes)-1])
+		}
+	case "ctrl+u":
+		*value = ""
+	default:
+		if msg.Type == tea.KeyRunes || msg.Type == tea.KeySpace {
+			*value += string(msg.Runes)
+		}
+	}
+	return m, nil
+}
+
+func (m *appModel) saveEdit(form editForm) tea.Cmd {
+	updated := *form.link
+	updated.Title = strings.TrimSpace(form.values[editTitle])
+	updated.Tags = strings.Join((&model.Link{Tags: form.values[editTags]}).TagList(), ",")
+	note := strings.TrimSpace(form.values[editNote])
+
+	s := m.storage
+	return m.startTask("Saving", true, func() tea.Msg {
+		ctx := context.Background()
+		if err := s.Update(ctx, &updated); err != nil {
+			return errorf("save: %v", err)
+		}
+		if note == "" {
+			return statusMsg{"Saved"}
+		}
+		if _, err := s.AddAnnotation(ctx, updated.ID, note); err != nil {
+			return errorf("add note: %v", err)
+		}
+		// The detail view lists the new note
+		return loadDetailData(s, updated.ID)()
+	})
+}
+
+func (m appModel) renderEditForm() string {
+	form := m.editing
+	var b strings.Builder
+	fmt.Fprintf(&b, "Edit %s\n\n", urlStyle.Render(form.link.URL))
+	for i, label := range editLabels {
+		value := form.values[i]
+		if i == form.focus {
+			value = editFocusStyle.Render(value + "█")
+		}
+		fmt.Fprintf(&b, "%s %s\n", editLabelStyle.Render(label+":"), value)
+	}
+	b.WriteString("\n")
+	b.WriteString(readStyle.Render("[tab] next field  [enter] save  [esc] cancel"))
+	return b.String()
+}
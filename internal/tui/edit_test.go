@@ -0,0 +1,119 @@
+package tui
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bunchhieng/rl/internal/model"
+	"github.com/bunchhieng/rl/internal/storage"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestEdit(t *testing.T) {
+	s, err := storage.NewSQLiteStorage(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer s.Close()
+	ctx := context.Background()
+	link, err := s.Add(ctx, &model.Link{URL: "https://example.com/a", Title: "A", Tags: "go"})
+	if err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	m := initialModel(s)
+	next, _ := m.update(loadLinks(s, m.listOptions(), 0)())
+	m = next.(appModel)
+	keys := func(keys ...tea.KeyMsg) tea.Cmd {
+		t.Helper()
+		var cmd tea.Cmd
+		for _, key := range keys {
+			var next tea.Model
+			next, cmd = m.update(key)
+			m = next.(appModel)
+		}
+		return cmd
+	}
+	typed := func(text string) tea.KeyMsg { return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(text)} }
+
+	// The form starts with the title and tags, and an empty note
+	keys(typed("e"))
+	if m.editing == nil || m.editing.values != [editFieldCount]string{editTitle: "A", editTags: "go"} {
+		t.Fatalf("Expected the edit form with the link's fields, got %+v", m.editing)
+	}
+
+	// A typed note is added to the link's notes, and the detail view
+	// gets them
+	cmd := keys(tea.KeyMsg{Type: tea.KeyTab}, typed(",db"), tea.KeyMsg{Type: tea.KeyTab}, typed("see section 2"), tea.KeyMsg{Type: tea.KeyEnter})
+	next, _ = m.update(taskResult(t, cmd()))
+	m = next.(appModel)
+	if got, _ := s.Get(ctx, link.ID); got.Tags != "go,db" {
+		t.Errorf("Expected the tags saved, got %q", got.Tags)
+	}
+	if notes := m.annotations[link.ID]; len(notes) != 1 || notes[0].Text != "see section 2" {
+		t.Errorf("Expected the note added and loaded, got %+v", notes)
+	}
+
+	// Without a note, nothing more is added
+	cmd = keys(typed("e"), tea.KeyMsg{Type: tea.KeyEnter})
+	next, _ = m.update(taskResult(t, cmd()))
+	m = next.(appModel)
+	if notes, _ := s.Annotations(ctx, link.ID); len(notes) != 1 || m.statusMsg != "Saved" {
+		t.Errorf("Expected no new note and a saved status, got %d notes and %q", len(notes), m.statusMsg)
+	}
+}
+
+func TestEditFormKeys(t *testing.T) {
+	s, err := storage.NewSQLiteStorage(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer s.Close()
+	ctx := context.Background()
+	link, err := s.Add(ctx, &model.Link{URL: "https://example.com/a", Title: "Old title", Tags: "go"})
+	if err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	m := initialModel(s)
+	next, _ := m.update(loadLinks(s, m.listOptions(), 0)())
+	m = next.(appModel)
+	keys := func(keys ...tea.KeyMsg) tea.Cmd {
+		t.Helper()
+		var cmd tea.Cmd
+		for _, key := range keys {
+			var next tea.Model
+			next, cmd = m.update(key)
+			m = next.(appModel)
+		}
+		return cmd
+	}
+	typed := func(text string) tea.KeyMsg { return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(text)} }
+
+	// Focus wraps around both ways
+	keys(typed("e"), tea.KeyMsg{Type: tea.KeyShiftTab})
+	if m.editing.focus != editNote {
+		t.Errorf("Expected shift+tab from the title to wrap to the note, got field %d", m.editing.focus)
+	}
+	keys(tea.KeyMsg{Type: tea.KeyTab})
+	if m.editing.focus != editTitle {
+		t.Errorf("Expected tab from the note to wrap to the title, got field %d", m.editing.focus)
+	}
+
+	// Esc throws the changes away
+	keys(tea.KeyMsg{Type: tea.KeyCtrlU}, typed("Thrown away"), tea.KeyMsg{Type: tea.KeyEsc})
+	if m.editing != nil {
+		t.Fatal("Expected esc to close the form")
+	}
+	if got, _ := s.Get(ctx, link.ID); got.Title != "Old title" {
+		t.Errorf("Expected esc to leave the title alone, got %q", got.Title)
+	}
+
+	// Backspace, spaces, and messy tags are handled on save
+	cmd := keys(typed("e"), tea.KeyMsg{Type: tea.KeyBackspace}, tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}}, typed("2 "),
+		tea.KeyMsg{Type: tea.KeyTab}, tea.KeyMsg{Type: tea.KeyCtrlU}, typed(" db, ,go "), tea.KeyMsg{Type: tea.KeyEnter})
+	next, _ = m.update(taskResult(t, cmd()))
+	m = next.(appModel)
+	got, _ := s.Get(ctx, link.ID)
+	if got.Title != "Old titl 2" || got.Tags != "db,go" {
+		t.Errorf("Expected title %q and tags %q, got %q and %q", "Old titl 2", "db,go", got.Title, got.Tags)
+	}
+}
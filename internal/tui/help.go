@@ -0,0 +1,136 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+type keyHelp struct {
+	action action
+	desc   string
+}
+
+type helpSection struct {
+	title    string
+	bindings []keyHelp
+}
+
+// helpSections lists every action shown on the help screen; the keys come
+// from the active key map.
+var helpSections = []helpSection{
+	{"Navigation", []keyHelp{
+		{actionDown, "move down"},
+		{actionUp, "move up"},
+		{actionTop, "go to top"},
+		{actionBottom, "go to bottom"},
+		{actionPageDown, "page down"},
+		{actionPageUp, "page up"},
+	}},
+	{"Selection", []keyHelp{
+		{actionToggle, "toggle selection"},
+		{actionVisual, "visual mode: mark a range by moving; again adds it to the selection, esc cancels"},
+		{actionSelectAll, "select all visible links"},
+		{actionDeselectAll, "deselect all"},
+	}},
+	{"Actions", []keyHelp{
+		{actionOpen, "open in browser (selected links)"},
+		{actionCopy, "copy URL (selected links: one per line)"},
+		{actionDetails, "show details (full URL, notes, description, timestamps)"},
+		{actionInlineDetails, "show or hide each link's description and full URL on a line under it"},
+		{actionReader, "read archived article text"},
+		{actionMarkRead, "mark as read (selected links)"},
+		{actionMarkUnread, "mark as unread (selected links)"},
+		{actionMoveUp, "move up the unread queue (unread list, default order)"},
+		{actionMoveDown, "move down the unread queue"},
+		{actionEdit, "edit title and tags, and add a note"},
+		{actionRemove, "remove (selected links, asks to confirm)"},
+		{actionAdd, "add the URL on the clipboard"},
+	}},
+	{"Search & filter", []keyHelp{
+		{actionSearch, "search title, URL, and tags (tab: full-text, incl. notes and article text; ↑/↓: recent searches)"},
+		{actionClearSearch, "dismiss errors, or clear search"},
+		{actionFilter, "cycle filter: unread, read, all"},
+		{actionTags, "filter by tag (pick from a list with counts)"},
+		{actionSort, "cycle sort: default, newest, oldest, title, domain, priority"},
+		{actionFoldSection, "collapse the date section (sorted by newest or oldest)"},
+		{actionUnfoldSections, "expand all date sections"},
+		{actionReload, "reload"},
+	}},
+	{"General", []keyHelp{
+		{actionCommand, "command palette (see Commands below)"},
+		{actionStats, "statistics: counts, weekly additions, top tags and domains"},
+		{actionHelp, "show this help"},
+		{actionQuit, "quit"},
+	}},
+}
+
+// helpLines renders the help screen content, one entry per line.
+func helpLines(km keyMap) []string {
+	keyWidth := 0
+	for _, section := range helpSections {
+		for _, b := range section.bindings {
+			keyWidth = max(keyWidth, lipgloss.Width(km.help(b.action)))
+		}
+	}
+
+	var lines []string
+	for i, section := range helpSections {
+		if i > 0 {
+			lines = append(lines, "")
+		}
+		lines = append(lines, helpTitleStyle.Render(section.title))
+		for _, b := range section.bindings {
+			keys := km.help(b.action)
+			pad := strings.Repeat(" ", keyWidth-lipgloss.Width(keys))
+			lines = append(lines, fmt.Sprintf("  %s%s  %s", helpKeyStyle.Render(keys), pad, b.desc))
+		}
+	}
+
+	lines = append(lines, "", helpTitleStyle.Render("Commands"))
+	for _, cmd := range paletteCommands {
+		lines = append(lines, "  "+helpKeyStyle.Render(":")+cmd.usage)
+	}
+	return lines
+}
+
+// helpHeight is the number of help lines visible at once.
+func (m appModel) helpHeight() int {
+	return max(1, m.height-2) // header and status bar
+}
+
+func (m appModel) handleHelpInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	maxOffset := max(0, len(helpLines(m.keys))-m.helpHeight())
+	if msg.Type == tea.KeyCtrlC {
+		return m, tea.Quit
+	}
+	a := m.keys.viewAction(msg.String(), actionHelp)
+	if a == actionBack {
+		m.showHelp = false
+		m.helpOffset = 0
+	} else if offset, ok := scroll(a, m.helpOffset, maxOffset, m.helpHeight()); ok {
+		m.helpOffset = offset
+	}
+	return m, nil
+}
+
+func (m appModel) renderHelp() string {
+	lines := helpLines(m.keys)
+	end := min(len(lines), m.helpOffset+m.helpHeight())
+
+	var b strings.Builder
+	b.WriteString(headerStyle.Render("rl - Keybindings"))
+	b.WriteString("\n")
+	b.WriteString(strings.Join(lines[m.helpOffset:end], "\n"))
+	b.WriteString("\n")
+
+	status := "[esc] close"
+	if len(lines) > m.helpHeight() {
+		status = fmt.Sprintf("%d-%d/%d  |  %s", m.helpOffset+1, end, len(lines),
+			hints(m.keys.hint("scroll", actionDown, actionUp), "[esc] close"))
+	}
+	b.WriteString(statusBarStyle.Width(m.width).Render(status))
+	return b.String()
+}
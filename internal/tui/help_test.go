@@ -0,0 +1,61 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestHelpScrolling(t *testing.T) {
+	m := initialModel(nil)
+	m.width, m.height = 80, 12 // 10 help lines at a time
+	press := func(key tea.KeyMsg) {
+		t.Helper()
+		next, _ := m.Update(key)
+		m = next.(appModel)
+	}
+	typed := func(text string) tea.KeyMsg { return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(text)} }
+	total := len(helpLines(m.keys))
+	if total <= m.helpHeight() {
+		t.Fatalf("help has %d lines, too few to test scrolling", total)
+	}
+
+	press(typed("?"))
+	if !m.showHelp || m.helpOffset != 0 {
+		t.Fatalf("? should open the help at the top, got shown=%v offset=%d", m.showHelp, m.helpOffset)
+	}
+	if view := m.renderHelp(); !strings.Contains(view, "1-10/") {
+		t.Errorf("Expected the status bar to show lines 1-10, got:\n%s", view)
+	}
+
+	press(typed("j"))
+	press(typed("j"))
+	press(typed("k"))
+	if m.helpOffset != 1 {
+		t.Errorf("Expected offset 1 after j j k, got %d", m.helpOffset)
+	}
+	press(tea.KeyMsg{Type: tea.KeyPgDown})
+	if m.helpOffset != 11 {
+		t.Errorf("Expected a page down to move 10 lines, got offset %d", m.helpOffset)
+	}
+	// Scrolling stops with the last line at the bottom
+	press(typed("G"))
+	press(typed("j"))
+	if want := total - m.helpHeight(); m.helpOffset != want {
+		t.Errorf("Expected the last page at offset %d, got %d", want, m.helpOffset)
+	}
+	if last := helpLines(m.keys)[total-1]; !strings.Contains(m.renderHelp(), last) {
+		t.Errorf("Expected the last help line %q shown, got:\n%s", last, m.renderHelp())
+	}
+	press(typed("g"))
+	if m.helpOffset != 0 {
+		t.Errorf("Expected g to go back to the top, got %d", m.helpOffset)
+	}
+
+	press(typed("j"))
+	press(tea.KeyMsg{Type: tea.KeyEsc})
+	if m.showHelp || m.helpOffset != 0 {
+		t.Errorf("Expected esc to close the help and reset it, got shown=%v offset=%d", m.showHelp, m.helpOffset)
+	}
+}
@@ -0,0 +1,83 @@
+package tui
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// maxSearchHistory is how many recent searches are remembered.
+const maxSearchHistory = 50
+
+// loadSearchHistory reads the searches saved at path, oldest first. A
+// missing or unreadable file is an empty history.
+func loadSearchHistory(path string) []string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var history []string
+	if err := json.Unmarshal(data, &history); err != nil {
+		return nil
+	}
+	return history
+}
+
+// saveSearchHistory writes history to path.
+func saveSearchHistory(path string, history []string) error {
+	data, err := json.Marshal(history)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
+
+// rememberSearch adds the current search to the end of the history,
+// moving it there if it was already in it, and saves the history.
+func (m *appModel) rememberSearch() tea.Cmd {
+	m.historyBack = 0
+	if m.searchQuery == "" {
+		return nil
+	}
+	history := slices.DeleteFunc(slices.Clone(m.searchHistory), func(q string) bool { return q == m.searchQuery })
+	history = append(history, m.searchQuery)
+	if len(history) > maxSearchHistory {
+		history = history[len(history)-maxSearchHistory:]
+	}
+	m.searchHistory = history
+	if m.historyPath == "" {
+		return nil
+	}
+	path := m.historyPath
+	return func() tea.Msg {
+		if err := saveSearchHistory(path, history); err != nil {
+			return errorf("save search history: %v", err)
+		}
+		return nil
+	}
+}
+
+// recallSearch replaces the search text with an older (step 1) or newer
+// (step -1) search from the history. Stepping past the newest brings back
+// what was typed before recalling.
+func (m *appModel) recallSearch(step int) {
+	back := max(0, min(m.historyBack+step, len(m.searchHistory)))
+	if back == m.historyBack {
+		return
+	}
+	if m.historyBack == 0 {
+		m.searchDraft = m.searchQuery
+	}
+	m.historyBack = back
+	if back == 0 {
+		m.searchQuery = m.searchDraft
+	} else {
+		m.searchQuery = m.searchHistory[len(m.searchHistory)-back]
+	}
+}
@@ -0,0 +1,63 @@
+package tui
+
+import (
+	"path/filepath"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// searchKey sends a key to the model in search mode, whose handler returns
+// a pointer.
+func searchKey(m appModel, msg tea.KeyMsg) (appModel, tea.Cmd) {
+	next, cmd := m.update(msg)
+	if p, ok := next.(*appModel); ok {
+		return *p, cmd
+	}
+	return next.(appModel), cmd
+}
+
+func TestSearchHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "search-history.json")
+	m := initialModel(nil)
+	m.historyPath = path
+
+	search := func(query string) {
+		m, _ = searchKey(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("/")})
+		m, _ = searchKey(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(query)})
+		var cmd tea.Cmd
+		m, cmd = searchKey(m, tea.KeyMsg{Type: tea.KeyEnter})
+		if msg := cmd(); msg != nil {
+			t.Fatalf("Saving the history failed: %v", msg)
+		}
+	}
+	search("golang")
+	search("rust")
+	search("golang")
+
+	// A later session recalls them, newest first
+	m = initialModel(nil)
+	m.searchHistory = loadSearchHistory(path)
+	if len(m.searchHistory) != 2 {
+		t.Fatalf("Expected 2 remembered searches, got %q", m.searchHistory)
+	}
+	m.searchMode = true
+	m.searchQuery = "dra"
+	keys := []struct {
+		key  tea.KeyType
+		want string
+	}{
+		{tea.KeyUp, "golang"},
+		{tea.KeyUp, "rust"},
+		{tea.KeyUp, "rust"},
+		{tea.KeyDown, "golang"},
+		{tea.KeyDown, "dra"},
+		{tea.KeyDown, "dra"},
+	}
+	for i, k := range keys {
+		m, _ = searchKey(m, tea.KeyMsg{Type: k.key})
+		if m.searchQuery != k.want {
+			t.Errorf("Key %d: search is %q, want %q", i, m.searchQuery, k.want)
+		}
+	}
+}
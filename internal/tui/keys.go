@@ -0,0 +1,255 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// action is a command that can be bound to keys in the config file's
+// [keys] table. The list view runs them all; full-screen views, such as
+// the reader, run the ones that make sense there, e.g. down scrolls.
+type action string
+
+const (
+	actionQuit           action = "quit"
+	actionDown           action = "down"
+	actionUp             action = "up"
+	actionTop            action = "top"
+	actionBottom         action = "bottom"
+	actionPageDown       action = "page_down"
+	actionPageUp         action = "page_up"
+	actionToggle         action = "toggle_select"
+	actionVisual         action = "visual"
+	actionSelectAll      action = "select_all"
+	actionDeselectAll    action = "deselect_all"
+	actionOpen           action = "open"
+	actionCopy           action = "copy"
+	actionDetails        action = "details"
+	actionInlineDetails  action = "inline_details"
+	actionReader         action = "reader"
+	actionMarkRead       action = "mark_read"
+	actionMarkUnread     action = "mark_unread"
+	actionMoveUp         action = "move_up"
+	actionMoveDown       action = "move_down"
+	actionEdit           action = "edit"
+	actionRemove         action = "remove"
+	actionAdd            action = "add"
+	actionSearch         action = "search"
+	actionClearSearch    action = "clear_search"
+	actionFilter         action = "filter"
+	actionTags           action = "tags"
+	actionSort           action = "sort"
+	actionFoldSection    action = "fold_section"
+	actionUnfoldSections action = "unfold_sections"
+	actionReload         action = "reload"
+	actionStats          action = "stats"
+	actionCommand        action = "command"
+	actionHelp           action = "help"
+
+	// actionBack closes a full-screen view. It has no keys of its own:
+	// viewAction maps esc, quit, and the key that opened the view to it.
+	actionBack action = "back"
+)
+
+// defaultBindings lists every action with its default keys, in the order
+// conflicts are reported.
+var defaultBindings = []struct {
+	action action
+	keys   []string
+}{
+	{actionQuit, []string{"q"}},
+	{actionDown, []string{"j", "down"}},
+	{actionUp, []string{"k", "up"}},
+	{actionTop, []string{"g", "home"}},
+	{actionBottom, []string{"G", "end"}},
+	{actionPageDown, []string{"pgdown", "ctrl+f"}},
+	{actionPageUp, []string{"pgup", "ctrl+b"}},
+	{actionToggle, []string{" "}},
+	{actionVisual, []string{"V"}},
+	{actionSelectAll, []string{"ctrl+a"}},
+	{actionDeselectAll, []string{"ctrl+d"}},
+	{actionOpen, []string{"o", "enter"}},
+	{actionCopy, []string{"y"}},
+	{actionDetails, []string{"p"}},
+	{actionInlineDetails, []string{"i"}},
+	{actionReader, []string{"v"}},
+	{actionMarkRead, []string{"d"}},
+	{actionMarkUnread, []string{"u"}},
+	{actionMoveUp, []string{"K"}},
+	{actionMoveDown, []string{"J"}},
+	{actionEdit, []string{"e"}},
+	{actionRemove, []string{"r"}},
+	{actionAdd, []string{"a"}},
+	{actionSearch, []string{"/"}},
+	{actionClearSearch, []string{"esc"}},
+	{actionFilter, []string{"tab"}},
+	{actionTags, []string{"t"}},
+	{actionSort, []string{"s"}},
+	{actionFoldSection, []string{"z"}},
+	{actionUnfoldSections, []string{"Z"}},
+	{actionReload, []string{"ctrl+l"}},
+	{actionStats, []string{"S"}},
+	{actionCommand, []string{":"}},
+	{actionHelp, []string{"?"}},
+}
+
+// keyMap resolves key presses in the list view to actions.
+type keyMap struct {
+	actions map[string]action
+	keys    map[action][]string
+}
+
+// newKeyMap applies user bindings over the defaults. Each entry replaces
+// all default keys of its action; an empty list unbinds it. Unknown
+// actions and keys bound to more than one action are errors.
+func newKeyMap(bindings map[string][]string) (keyMap, error) {
+	km := keyMap{
+		actions: make(map[string]action),
+		keys:    make(map[action][]string),
+	}
+	for _, b := range defaultBindings {
+		km.keys[b.action] = b.keys
+	}
+
+	names := make([]string, 0, len(bindings))
+	for name := range bindings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		a := action(name)
+		if _, ok := km.keys[a]; !ok {
+			return keyMap{}, fmt.Errorf("unknown action %q", name)
+		}
+		keys := make([]string, 0, len(bindings[name]))
+		for _, key := range bindings[name] {
+			switch key {
+			case "":
+				return keyMap{}, fmt.Errorf("empty key for action %q", name)
+			case "ctrl+c":
+				return keyMap{}, fmt.Errorf("ctrl+c always quits and can't be bound to %q", name)
+			}
+			keys = append(keys, normalizeKey(key))
+		}
+		km.keys[a] = keys
+	}
+
+	for _, b := range defaultBindings {
+		for _, key := range km.keys[b.action] {
+			if other, ok := km.actions[key]; ok && other != b.action {
+				return keyMap{}, fmt.Errorf("key %q is bound to both %q and %q", displayKey(key), other, b.action)
+			}
+			km.actions[key] = b.action
+		}
+	}
+	return km, nil
+}
+
+// defaultKeyMap returns the built-in bindings.
+func defaultKeyMap() keyMap {
+	km, _ := newKeyMap(nil)
+	return km
+}
+
+// action returns the action bound to a key, or "" if there is none.
+func (km keyMap) action(key string) action {
+	return km.actions[key]
+}
+
+// viewAction returns the action bound to a key pressed in the
+// full-screen view opened by the action view. esc always backs out, as
+// ctrl+c always quits; quit and view's own keys close it too.
+func (km keyMap) viewAction(key string, view action) action {
+	a := km.action(key)
+	if key == "esc" || a == actionQuit || a == view {
+		return actionBack
+	}
+	return a
+}
+
+// scroll moves offset, clamped to [0, maxOffset], for a scrolling action
+// in a view showing page lines at a time, and reports whether a was one.
+// Space, the list's selection toggle, pages down as in a pager.
+func scroll(a action, offset, maxOffset, page int) (int, bool) {
+	switch a {
+	case actionDown:
+		offset++
+	case actionUp:
+		offset--
+	case actionPageDown, actionToggle:
+		offset += page
+	case actionPageUp:
+		offset -= page
+	case actionTop:
+		offset = 0
+	case actionBottom:
+		offset = maxOffset
+	default:
+		return offset, false
+	}
+	return max(0, min(offset, maxOffset)), true
+}
+
+// hint describes the first key of each action bound for a status bar,
+// e.g. "[j/k] scroll", or "" if none of them is bound.
+func (km keyMap) hint(desc string, actions ...action) string {
+	var names []string
+	for _, a := range actions {
+		if keys := km.keys[a]; len(keys) > 0 {
+			names = append(names, displayKey(keys[0]))
+		}
+	}
+	if len(names) == 0 {
+		return ""
+	}
+	return "[" + strings.Join(names, "/") + "] " + desc
+}
+
+// hints joins the non-empty hints.
+func hints(parts ...string) string {
+	var shown []string
+	for _, part := range parts {
+		if part != "" {
+			shown = append(shown, part)
+		}
+	}
+	return strings.Join(shown, " ")
+}
+
+// help describes the keys bound to a for the help screen, e.g. "j / ↓".
+func (km keyMap) help(a action) string {
+	keys := km.keys[a]
+	if a == actionQuit {
+		keys = append(keys[:len(keys):len(keys)], "ctrl+c")
+	}
+	if len(keys) == 0 {
+		return "(unbound)"
+	}
+	names := make([]string, len(keys))
+	for i, key := range keys {
+		names[i] = displayKey(key)
+	}
+	return strings.Join(names, " / ")
+}
+
+// normalizeKey converts a configured key name to the form Bubble Tea
+// reports ("space" is delivered as " ").
+func normalizeKey(key string) string {
+	if strings.EqualFold(key, "space") {
+		return " "
+	}
+	return key
+}
+
+func displayKey(key string) string {
+	switch key {
+	case " ":
+		return "space"
+	case "down":
+		return "↓"
+	case "up":
+		return "↑"
+	}
+	return key
+}
@@ -0,0 +1,91 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bunchhieng/rl/internal/model"
+	"github.com/bunchhieng/rl/internal/storage"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestNewKeyMap(t *testing.T) {
+	km, err := newKeyMap(map[string][]string{
+		"down": {"ctrl+n", "down"},
+		"up":   {"ctrl+p", "up"},
+	})
+	if err != nil {
+		t.Fatalf("newKeyMap error: %v", err)
+	}
+	if km.action("ctrl+n") != actionDown || km.action("down") != actionDown {
+		t.Error("Expected ctrl+n and down to move down")
+	}
+	if km.action("j") != "" {
+		t.Error("Remapping an action should drop its default keys")
+	}
+	if km.action("d") != actionMarkRead {
+		t.Error("Untouched actions should keep their defaults")
+	}
+
+	if _, err := newKeyMap(map[string][]string{"down": {"d"}}); err == nil {
+		t.Error("Expected conflict error for a key bound to two actions")
+	}
+	if _, err := newKeyMap(map[string][]string{"jump": {"x"}}); err == nil {
+		t.Error("Expected error for an unknown action")
+	}
+	if _, err := newKeyMap(map[string][]string{"quit": {"ctrl+c", "x"}}); err == nil {
+		t.Error("Expected error for binding ctrl+c")
+	}
+}
+
+func TestViewBindings(t *testing.T) {
+	km, err := newKeyMap(map[string][]string{"down": {"ctrl+n"}, "quit": {"x"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := initialModel(nil)
+	m.keys = km
+	m.height = 10
+	press := func(m appModel, key string) appModel {
+		msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+		if key == "ctrl+n" {
+			msg = tea.KeyMsg{Type: tea.KeyCtrlN}
+		}
+		next, _ := m.update(msg)
+		return next.(appModel)
+	}
+
+	// The reader scrolls with the bound key and not the default one
+	m.reader = &readerView{link: &model.Link{URL: "https://example.com"}, text: strings.Repeat("line\n\n", 50)}
+	if m = press(m, "j"); m.reader.offset != 0 {
+		t.Errorf("Expected j unbound in the reader, got offset %d", m.reader.offset)
+	}
+	if m = press(m, "ctrl+n"); m.reader.offset != 1 {
+		t.Errorf("Expected ctrl+n to scroll the reader, got offset %d", m.reader.offset)
+	}
+	if view := m.renderReader(); !strings.Contains(view, "[ctrl+n/k] scroll") {
+		t.Errorf("Expected the bound keys in the reader's status bar, got:\n%s", view)
+	}
+	if m = press(m, "x"); m.reader != nil {
+		t.Error("Expected the quit key to close the reader")
+	}
+
+	// So do the help screen and the tag picker
+	m.showHelp = true
+	if m = press(m, "ctrl+n"); m.helpOffset != 1 {
+		t.Errorf("Expected ctrl+n to scroll the help, got offset %d", m.helpOffset)
+	}
+	if m = press(m, "?"); m.showHelp {
+		t.Error("Expected the help key to close the help")
+	}
+	m.tagPicker = &tagPicker{tags: []storage.Count{{Name: "go", Count: 2}, {Name: "web", Count: 1}}}
+	if m = press(m, "j"); m.tagPicker.selected != 0 {
+		t.Errorf("Expected j unbound in the tag picker, got %d", m.tagPicker.selected)
+	}
+	if m = press(m, "ctrl+n"); m.tagPicker.selected != 1 {
+		t.Errorf("Expected ctrl+n to move in the tag picker, got %d", m.tagPicker.selected)
+	}
+	if m = press(m, "x"); m.tagPicker != nil {
+		t.Error("Expected the quit key to close the tag picker")
+	}
+}
@@ -0,0 +1,102 @@
+package tui
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	// doubleClickInterval is the longest gap between two clicks on the
+	// same row that still counts as a double click.
+	doubleClickInterval = 400 * time.Millisecond
+	// wheelStep is how many rows one wheel notch scrolls.
+	wheelStep = 3
+)
+
+// listTop is the screen row of the first link: below the header and, in
+// search mode, the search bar.
+func (m appModel) listTop() int {
+	if m.searchMode {
+		return 2
+	}
+	return 1
+}
+
+func (m appModel) handleMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	// The scrollable screens scroll by line, as with the arrow keys
+	if m.showHelp || m.reader != nil {
+		var key tea.KeyMsg
+		switch msg.Button {
+		case tea.MouseButtonWheelDown:
+			key = tea.KeyMsg{Type: tea.KeyDown}
+		case tea.MouseButtonWheelUp:
+			key = tea.KeyMsg{Type: tea.KeyUp}
+		default:
+			return m, nil
+		}
+		var next tea.Model = m
+		for range wheelStep {
+			if m.showHelp {
+				next, _ = next.(appModel).handleHelpInput(key)
+			} else {
+				next, _ = next.(appModel).handleReaderInput(key)
+			}
+		}
+		return next, nil
+	}
+
+	// Other screens and overlays replace the list, so rows don't map to links
+	if m.showDetail || m.stats != nil || m.confirmDelete || m.editing != nil || m.tagPicker != nil {
+		return m, nil
+	}
+
+	switch msg.Button {
+	case tea.MouseButtonWheelDown:
+		m.scrollBy(wheelStep)
+	case tea.MouseButtonWheelUp:
+		m.scrollBy(-wheelStep)
+	case tea.MouseButtonLeft:
+		if msg.Action != tea.MouseActionPress {
+			return m, nil
+		}
+		row := msg.Y - m.listTop()
+		rows := m.rows()
+		if row < 0 || row >= m.listHeight() || m.offset+row >= len(rows) {
+			return m, nil
+		}
+		// A click on a date section's header collapses or expands it
+		if section := rows[m.offset+row].section; section >= 0 {
+			m.setCollapsed(section, !m.sections[section].collapsed)
+			return m, nil
+		}
+		index := rows[m.offset+row].link
+
+		now := time.Now()
+		double := index == m.lastClickIndex && now.Sub(m.lastClick) < doubleClickInterval
+		m.selected = index
+		if double {
+			m.lastClick = time.Time{}
+			cmd := m.openLink()
+			return m, cmd
+		}
+		m.lastClick, m.lastClickIndex = now, index
+	}
+	return m, nil
+}
+
+// scrollBy moves the visible window by n rows, dragging the selection
+// along only when it would leave the screen.
+func (m *appModel) scrollBy(n int) {
+	height := m.listHeight()
+	m.offset = max(0, min(m.offset+n, len(m.rows())-height))
+	if len(m.filtered) == 0 {
+		return
+	}
+	start, end := m.shownLinks()
+	if start == end {
+		// Only collapsed headers are on screen; keep the highlight nearby
+		return
+	}
+	m.selected = max(start, min(m.selected, end-1))
+}
@@ -0,0 +1,34 @@
+package tui
+
+import (
+	"testing"
+
+	"github.com/bunchhieng/rl/internal/model"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestHandleMouse(t *testing.T) {
+	m := initialModel(nil)
+	m.height = 10 // 6 visible rows
+	for range 20 {
+		m.filtered = append(m.filtered, &model.Link{URL: "https://example.com"})
+	}
+
+	next, _ := m.handleMouse(tea.MouseMsg{Button: tea.MouseButtonLeft, Action: tea.MouseActionPress, Y: 3})
+	m = next.(appModel)
+	if m.selected != 2 {
+		t.Errorf("Click on third row selected %d, want 2", m.selected)
+	}
+
+	next, _ = m.handleMouse(tea.MouseMsg{Button: tea.MouseButtonWheelDown, Action: tea.MouseActionPress})
+	m = next.(appModel)
+	if m.offset != wheelStep || m.selected != wheelStep {
+		t.Errorf("Wheel down: offset %d, selected %d; want %d, %d", m.offset, m.selected, wheelStep, wheelStep)
+	}
+
+	next, _ = m.handleMouse(tea.MouseMsg{Button: tea.MouseButtonLeft, Action: tea.MouseActionPress, Y: 1})
+	m = next.(appModel)
+	if m.selected != m.offset {
+		t.Errorf("Click on first visible row selected %d, want %d", m.selected, m.offset)
+	}
+}
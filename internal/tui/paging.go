@@ -0,0 +1,135 @@
+package tui
+
+import (
+	"context"
+
+	"github.com/bunchhieng/rl/internal/model"
+	"github.com/bunchhieng/rl/internal/storage"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// pageSize is how many links the list loads at a time. The rest are
+// loaded a page at a time as the selection nears the end of those loaded,
+// so large libraries open and refresh quickly.
+const pageSize = 500
+
+// pageMsg carries the page of links starting at offset, loaded for the
+// list options opts.
+type pageMsg struct {
+	opts   storage.ListOptions
+	offset int
+	links  []*model.Link
+	err    error
+}
+
+func loadPage(s storage.Storage, opts storage.ListOptions, offset int) tea.Cmd {
+	return func() tea.Msg {
+		page := opts
+		page.Limit, page.Offset = pageSize, offset
+		links, err := s.List(context.Background(), page)
+		return pageMsg{opts: opts, offset: offset, links: links, err: err}
+	}
+}
+
+// reload loads the list again, as many links as are loaded now so the
+// selection and scroll position survive.
+func (m appModel) reload() tea.Cmd {
+	return loadLinks(m.storage, m.listOptions(), len(m.links))
+}
+
+// needsAll reports whether every link has to be loaded: filters that
+// check the loaded links, and jumps or selections that reach the end of
+// the list, can't work from a page.
+func (m appModel) needsAll() bool {
+	return m.tagFilter != "" || (m.searchQuery != "" && !m.fullText) ||
+		m.pendingBottom || m.pendingSelect
+}
+
+// loadMore requests the next page of links when the selection is within
+// two screens of the end of those loaded, or when every link is needed.
+// One page is loaded at a time.
+func (m *appModel) loadMore() tea.Cmd {
+	if m.loading || !m.moreToLoad() {
+		return nil
+	}
+	if !m.needsAll() && m.selected < len(m.filtered)-2*m.listHeight() {
+		return nil
+	}
+	m.loading = true
+	return loadPage(m.storage, m.listOptions(), len(m.links))
+}
+
+// handlePage appends a page loaded by loadMore, unless the list was
+// reloaded or its filter or sort changed since the page was requested.
+func (m appModel) handlePage(msg pageMsg) (tea.Model, tea.Cmd) {
+	m.loading = false
+	if msg.opts != m.listOptions() || msg.offset != len(m.links) {
+		return m, nil
+	}
+	if msg.err != nil {
+		return m.handleError(errorMsg{msg.err})
+	}
+
+	// Links added since the list was loaded shift later pages along, so a
+	// page can repeat the last link of the one before
+	seen := make(map[string]bool, len(m.links))
+	for _, link := range m.links {
+		seen[link.ID] = true
+	}
+	for _, link := range msg.links {
+		if !seen[link.ID] {
+			m.links = append(m.links, link)
+		}
+	}
+	if len(msg.links) < pageSize {
+		// Links were removed since they were counted
+		m.total = len(m.links)
+	}
+	m.total = max(m.total, len(m.links))
+
+	// In the default order, the page's links join the date sections
+	// above the highlighted link as well as below it
+	var follow string
+	if len(m.filtered) > 0 {
+		follow = m.filtered[m.selected].ID
+	}
+	m.applyFilters()
+	for i, link := range m.filtered {
+		if link.ID == follow {
+			m.selected = i
+			break
+		}
+	}
+	m.finishPending()
+	return m, nil
+}
+
+// finishPending completes a jump to the bottom or a select-all that was
+// waiting for the rest of the links to load.
+func (m *appModel) finishPending() {
+	if m.moreToLoad() {
+		return
+	}
+	if m.pendingBottom {
+		m.selected = max(len(m.filtered)-1, 0)
+		m.pendingBottom = false
+	}
+	if m.pendingSelect {
+		m.selectAll()
+		m.pendingSelect = false
+	}
+}
+
+// listCount is the number of links in the list, counting those not yet
+// loaded when no filter needs to see them first.
+func (m appModel) listCount() int {
+	if m.tagFilter == "" && m.searchQuery == "" {
+		return max(m.total, len(m.filtered))
+	}
+	return len(m.filtered)
+}
+
+// moreToLoad reports whether links matching the list are still to be loaded.
+func (m appModel) moreToLoad() bool {
+	return len(m.links) < m.total
+}
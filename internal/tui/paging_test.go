@@ -0,0 +1,180 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bunchhieng/rl/internal/model"
+	"github.com/bunchhieng/rl/internal/storage"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestLazyLoading(t *testing.T) {
+	s, err := storage.NewSQLiteStorage(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer s.Close()
+
+	const total = 2*pageSize + 100
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	links := make([]*model.Link, total)
+	for i := range links {
+		links[i] = &model.Link{URL: fmt.Sprintf("https://example.com/%d", i), CreatedAt: base.Add(time.Duration(i) * time.Minute)}
+	}
+	links[total-1].Tags = "last"
+	if _, err := s.Import(context.Background(), links, storage.ImportOptions{}); err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+
+	m := initialModel(s)
+	next, _ := m.update(loadLinks(s, m.listOptions(), 0)())
+	m = next.(appModel)
+	if len(m.links) != pageSize || m.total != total {
+		t.Fatalf("Expected the first %d of %d links, got %d of %d", pageSize, total, len(m.links), m.total)
+	}
+	if !strings.Contains(m.renderHeader(), fmt.Sprintf("[%d links]", total)) {
+		t.Errorf("Header should count links not yet loaded: %q", m.renderHeader())
+	}
+	if cmd := m.loadMore(); cmd != nil {
+		t.Error("Nothing more should load while the selection is far from the end")
+	}
+
+	// loaded runs page loads until loadMore stops asking for more
+	loaded := func() {
+		t.Helper()
+		for cmd := m.loadMore(); cmd != nil; cmd = m.loadMore() {
+			if !strings.Contains(m.renderStatusBar(), "loading…") {
+				t.Error("Status bar should show that a page is loading")
+			}
+			next, _ := m.handlePage(cmd().(pageMsg))
+			m = next.(appModel)
+		}
+	}
+
+	m.selected = pageSize - 1
+	loaded()
+	if len(m.links) != 2*pageSize {
+		t.Fatalf("Expected one more page as the selection neared the end, got %d links", len(m.links))
+	}
+	m.selected = 2*pageSize - 1
+	loaded()
+	if len(m.links) != total || m.moreToLoad() {
+		t.Fatalf("Expected the last page to load, got %d links", len(m.links))
+	}
+
+	// A reload keeps as many links as were loaded, so the selection stays put
+	next, _ = m.update(m.reload()())
+	m = next.(appModel)
+	if len(m.links) != total || m.selected != 2*pageSize-1 {
+		t.Errorf("Reload kept %d links and row %d, want %d and %d", len(m.links), m.selected, total, 2*pageSize-1)
+	}
+
+	// A page requested before the sort changed is dropped
+	m.links, m.total = m.links[:pageSize], total
+	m.applyFilters()
+	cmd := m.loadMore()
+	m.cycleSort()
+	next, _ = m.handlePage(cmd().(pageMsg))
+	m = next.(appModel)
+	if len(m.links) != pageSize || m.loading {
+		t.Errorf("A stale page should be dropped, got %d links", len(m.links))
+	}
+
+	// Filtering by tag loads every page to find the matches
+	next, _ = m.update(loadLinks(s, m.listOptions(), 0)())
+	m = next.(appModel)
+	m.selected = 0
+	m.tagFilter = "last"
+	m.applyFilters()
+	loaded()
+	if len(m.filtered) != 1 || m.filtered[0].ID != links[total-1].ID {
+		t.Errorf("Expected the tag filter to find the last link, got %d links", len(m.filtered))
+	}
+}
+
+func TestJumpToBottomWaitsForLinks(t *testing.T) {
+	m := initialModel(nil)
+	m.selectedIDs = map[string]bool{}
+	for _, id := range []string{"a", "b"} {
+		m.links = append(m.links, &model.Link{ID: id})
+	}
+	m.total = 3
+	m.applyFilters()
+
+	m.selected = len(m.filtered) - 1
+	m.pendingBottom, m.pendingSelect = true, true
+	m.finishPending()
+	if !m.pendingBottom || !m.pendingSelect {
+		t.Fatal("Jump and select-all should wait for the last link")
+	}
+
+	next, _ := m.handlePage(pageMsg{opts: m.listOptions(), offset: 2, links: []*model.Link{{ID: "c"}}})
+	m = next.(appModel)
+	if m.selected != 2 || m.pendingBottom {
+		t.Errorf("Expected the jump to finish on the last link, got row %d", m.selected)
+	}
+	if len(m.selectedIDs) != 3 || m.pendingSelect {
+		t.Errorf("Expected every link selected, got %d", len(m.selectedIDs))
+	}
+}
+
+func TestScrollWithSelection(t *testing.T) {
+	m := initialModel(nil)
+	m.width, m.height = 80, 10 // 6 visible rows
+	for i := range 20 {
+		m.filtered = append(m.filtered, &model.Link{ID: fmt.Sprint(i), URL: fmt.Sprintf("https://example.com/%d", i)})
+	}
+	m.links = m.filtered
+	press := func(keys ...string) {
+		t.Helper()
+		for _, key := range keys {
+			msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+			switch key {
+			case "pgdown":
+				msg = tea.KeyMsg{Type: tea.KeyPgDown}
+			case "pgup":
+				msg = tea.KeyMsg{Type: tea.KeyPgUp}
+			case "home":
+				msg = tea.KeyMsg{Type: tea.KeyHome}
+			}
+			next, _ := m.Update(msg)
+			m = next.(appModel)
+		}
+	}
+
+	if status := m.renderStatusBar(); !strings.Contains(status, "1/20 [1-6 ↓]") {
+		t.Errorf("Expected the status bar to show rows 1-6 of more, got %q", status)
+	}
+	tests := []struct {
+		keys             []string
+		selected, offset int
+	}{
+		{[]string{"j", "j", "j", "j", "j"}, 5, 0},
+		{[]string{"j"}, 6, 1},
+		{[]string{"pgdown"}, 12, 7},
+		{[]string{"G"}, 19, 14},
+		{[]string{"pgup"}, 13, 13},
+		{[]string{"k"}, 12, 12},
+		{[]string{"home"}, 0, 0},
+	}
+	for _, tt := range tests {
+		press(tt.keys...)
+		if m.selected != tt.selected || m.offset != tt.offset {
+			t.Errorf("After %v: selected %d at offset %d, want %d at %d", tt.keys, m.selected, m.offset, tt.selected, tt.offset)
+		}
+	}
+
+	// The list shows the rows from the offset
+	press("G")
+	list := m.renderList()
+	if !strings.Contains(list, "example.com/14") || !strings.Contains(list, "example.com/19") || strings.Contains(list, "example.com/13") {
+		t.Errorf("Expected the last six links listed, got:\n%s", list)
+	}
+	if status := m.renderStatusBar(); !strings.Contains(status, "20/20 [15-20 ↑]") {
+		t.Errorf("Expected the status bar to show rows 15-20 of more above, got %q", status)
+	}
+}
@@ -0,0 +1,233 @@
+package tui
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/bunchhieng/rl/internal/config"
+	"github.com/bunchhieng/rl/internal/formats"
+	"github.com/bunchhieng/rl/internal/model"
+	"github.com/bunchhieng/rl/internal/storage"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// The command palette, opened with ":", runs commands typed vim-style,
+// such as ":tag work" or ":sort title", for things that don't warrant a
+// key of their own. A command can be shortened to any unique prefix.
+
+// paletteCommand is a command the palette runs with the words typed after
+// its name.
+type paletteCommand struct {
+	name  string
+	usage string
+	run   func(m *appModel, args []string) (tea.Cmd, error)
+}
+
+// paletteCommands lists the palette's commands, in the order they are
+// suggested.
+var paletteCommands = []paletteCommand{
+	{"tag", "tag [name]: show links with the tag; no name shows all", (*appModel).runTag},
+	{"sort", "sort default|newest|oldest|title|domain|priority", (*appModel).runSort},
+	{"filter", "filter unread|read|all", (*appModel).runFilter},
+	{"priority", "priority high|normal|low: set the priority of the selected links", (*appModel).runPriority},
+	{"export", "export <file> [format]: write every link to a file (formats: " + strings.Join(formats.Exportable(), ", ") + ")", (*appModel).runExport},
+	{"quit", "quit", func(*appModel, []string) (tea.Cmd, error) { return tea.Quit, nil }},
+}
+
+// lookupPaletteCommand returns the command called name, or the only one
+// name is a prefix of.
+func lookupPaletteCommand(name string) (*paletteCommand, error) {
+	var matches []*paletteCommand
+	for i, cmd := range paletteCommands {
+		if cmd.name == name {
+			return &paletteCommands[i], nil
+		}
+		if strings.HasPrefix(cmd.name, name) {
+			matches = append(matches, &paletteCommands[i])
+		}
+	}
+	switch len(matches) {
+	case 0:
+		names := make([]string, len(paletteCommands))
+		for i, cmd := range paletteCommands {
+			names[i] = cmd.name
+		}
+		return nil, fmt.Errorf("unknown command %q (use %s)", name, strings.Join(names, ", "))
+	case 1:
+		return matches[0], nil
+	}
+	names := make([]string, len(matches))
+	for i, cmd := range matches {
+		names[i] = cmd.name
+	}
+	return nil, fmt.Errorf("ambiguous command %q: %s", name, strings.Join(names, ", "))
+}
+
+// runCommandLine runs the command typed into the palette, reporting
+// mistakes in the status bar.
+func (m *appModel) runCommandLine(line string) tea.Cmd {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return nil
+	}
+	cmd, err := lookupPaletteCommand(strings.ToLower(fields[0]))
+	if err == nil {
+		var run tea.Cmd
+		if run, err = cmd.run(m, fields[1:]); err == nil {
+			return run
+		}
+	}
+	return func() tea.Msg {
+		return statusMsg{err.Error()}
+	}
+}
+
+func (m appModel) handleCommandInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "ctrl+c":
+		m.commandMode = false
+	case "enter":
+		m.commandMode = false
+		cmd := m.runCommandLine(m.commandInput)
+		return m, cmd
+	case "backspace":
+		// Deleting past the ":" closes the palette, as in vim
+		if m.commandInput == "" {
+			m.commandMode = false
+		} else {
+			runes := []rune(m.commandInput)
+			m.commandInput = string(runes[:len(runes)-1])
+		}
+	default:
+		if len(msg.Runes) > 0 {
+			m.commandInput += string(msg.Runes)
+		}
+	}
+	return m, nil
+}
+
+func (m appModel) renderCommandLine() string {
+	return searchStyle.Width(m.width).Render(":" + m.commandInput)
+}
+
+func (m *appModel) runTag(args []string) (tea.Cmd, error) {
+	if len(args) > 1 {
+		return nil, errors.New("usage: tag [name]")
+	}
+	m.tagFilter = ""
+	if len(args) == 1 {
+		m.tagFilter = args[0]
+	}
+	m.visual = false
+	m.selected = 0
+	m.applyFilters()
+	return nil, nil
+}
+
+func (m *appModel) runSort(args []string) (tea.Cmd, error) {
+	if len(args) != 1 {
+		return nil, errors.New("usage: sort default|newest|oldest|title|domain|priority")
+	}
+	order := storage.SortDefault
+	if !strings.EqualFold(args[0], "default") {
+		var err error
+		if order, err = storage.ParseSortOrder(args[0]); err != nil {
+			return nil, err
+		}
+	}
+	m.sort = order
+	m.visual = false
+	m.selected = 0
+	m.pendingBottom, m.pendingSelect = false, false
+	return loadLinks(m.storage, m.listOptions(), 0), nil
+}
+
+func (m *appModel) runFilter(args []string) (tea.Cmd, error) {
+	statuses := map[string]storage.ReadStatus{
+		"unread": storage.ReadStatusUnread,
+		"read":   storage.ReadStatusRead,
+		"all":    storage.ReadStatusAll,
+	}
+	if len(args) != 1 {
+		return nil, errors.New("usage: filter unread|read|all")
+	}
+	status, ok := statuses[strings.ToLower(args[0])]
+	if !ok {
+		return nil, fmt.Errorf("invalid filter %q (use unread, read, or all)", args[0])
+	}
+	m.readStatus = status
+	m.visual = false
+	m.selected = 0
+	m.pendingBottom, m.pendingSelect = false, false
+	return loadLinks(m.storage, m.listOptions(), 0), nil
+}
+
+func (m *appModel) runPriority(args []string) (tea.Cmd, error) {
+	if len(args) != 1 {
+		return nil, errors.New("usage: priority high|normal|low")
+	}
+	priority, err := model.ParsePriority(args[0])
+	if err != nil {
+		return nil, err
+	}
+	links := m.targetLinks()
+	if len(links) == 0 {
+		return nil, nil
+	}
+	m.visual = false
+
+	s := m.storage
+	return m.startTask("Setting priority", true, func() tea.Msg {
+		for _, link := range links {
+			if err := s.SetPriority(context.Background(), link.ID, priority); err != nil {
+				return errorf("set priority of %s: %v", link.ID, err)
+			}
+		}
+		if len(links) == 1 {
+			return statusMsg{fmt.Sprintf("Priority set to %s", priority)}
+		}
+		return statusMsg{fmt.Sprintf("Priority of %d links set to %s", len(links), priority)}
+	}), nil
+}
+
+func (m *appModel) runExport(args []string) (tea.Cmd, error) {
+	if len(args) < 1 || len(args) > 2 {
+		return nil, errors.New("usage: export <file> [format]")
+	}
+	path, err := config.ExpandHome(args[0])
+	if err != nil {
+		return nil, err
+	}
+	format := ""
+	if len(args) == 2 {
+		format = args[1]
+	}
+	f, err := formats.Lookup(format)
+	if err != nil {
+		return nil, err
+	}
+	if f.Encode == nil {
+		return nil, fmt.Errorf("format %s can only be imported (export supports %s)", f.Name, strings.Join(formats.Exportable(), ", "))
+	}
+
+	s := m.storage
+	return m.startTask("Exporting", false, func() tea.Msg {
+		links, err := s.Export(context.Background())
+		if err != nil {
+			return errorf("export: %v", err)
+		}
+		var buf bytes.Buffer
+		if err := f.Encode(&buf, links); err != nil {
+			return errorf("export: %v", err)
+		}
+		// The export is the whole reading list, so only the owner can read it
+		if err := os.WriteFile(path, buf.Bytes(), 0o600); err != nil {
+			return errorf("export: %v", err)
+		}
+		return statusMsg{fmt.Sprintf("Exported %d links to %s", len(links), path)}
+	}), nil
+}
@@ -0,0 +1,91 @@
+package tui
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bunchhieng/rl/internal/model"
+	"github.com/bunchhieng/rl/internal/storage"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// typeCommand opens the palette, types line, and presses enter, returning
+// the model and the message of the command it ran, if any.
+func typeCommand(t *testing.T, m appModel, line string) (appModel, tea.Msg) {
+	t.Helper()
+	next, _ := m.update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(":")})
+	m = next.(appModel)
+	if !m.commandMode {
+		t.Fatal("Expected : to open the command palette")
+	}
+	next, _ = m.update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(line)})
+	next, cmd := next.(appModel).update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = next.(appModel)
+	if m.commandMode {
+		t.Fatal("Expected enter to close the command palette")
+	}
+	if cmd == nil {
+		return m, nil
+	}
+	return m, cmd()
+}
+
+func TestCommandPalette(t *testing.T) {
+	s, err := storage.NewSQLiteStorage(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer s.Close()
+	ctx := context.Background()
+	for _, link := range []*model.Link{
+		{URL: "https://example.com/a", Title: "Banana", Tags: "work"},
+		{URL: "https://example.com/b", Title: "Apple"},
+	} {
+		if _, err := s.Add(ctx, link); err != nil {
+			t.Fatalf("Add failed: %v", err)
+		}
+	}
+
+	m := initialModel(s)
+	next, _ := m.update(loadLinks(s, m.listOptions(), 0)())
+	m = next.(appModel)
+
+	m, _ = typeCommand(t, m, "tag work")
+	if m.tagFilter != "work" || len(m.filtered) != 1 {
+		t.Errorf("Expected :tag work to show 1 link, got %d with filter %q", len(m.filtered), m.tagFilter)
+	}
+	m, _ = typeCommand(t, m, "tag")
+	if m.tagFilter != "" || len(m.filtered) != 2 {
+		t.Errorf("Expected :tag to clear the filter, got %q", m.tagFilter)
+	}
+
+	// Commands can be shortened
+	m, msg := typeCommand(t, m, "so title")
+	next, _ = m.update(msg)
+	m = next.(appModel)
+	if m.sort != storage.SortTitle || m.filtered[0].Title != "Apple" {
+		t.Errorf("Expected :so title to sort by title, got %q", m.sort)
+	}
+
+	m, msg = typeCommand(t, m, "sort sideways")
+	if status, ok := msg.(statusMsg); !ok || !strings.Contains(status.message, "invalid sort order") {
+		t.Errorf("Expected a bad sort order to be reported, got %#v", msg)
+	}
+	m, msg = typeCommand(t, m, "frobnicate")
+	if status, ok := msg.(statusMsg); !ok || !strings.Contains(status.message, "unknown command") {
+		t.Errorf("Expected an unknown command to be reported, got %#v", msg)
+	}
+
+	path := filepath.Join(t.TempDir(), "links.json")
+	_, msg = typeCommand(t, m, "export "+path)
+	if status, ok := taskResult(t, msg).msg.(statusMsg); !ok || !strings.Contains(status.message, "Exported 2 links") {
+		t.Errorf("Expected the export to be reported, got %#v", status)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || !strings.Contains(string(data), "https://example.com/a") {
+		t.Errorf("Expected the links written to %s: %v", path, err)
+	}
+}
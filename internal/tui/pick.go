@@ -0,0 +1,175 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/bunchhieng/rl/internal/model"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// maxPickRows caps how many matches the picker shows at once.
+const maxPickRows = 15
+
+// pickModel is a minimal fzf-style fuzzy finder over links.
+type pickModel struct {
+	links    []*model.Link
+	query    string
+	matches  []*model.Link
+	selected int
+	chosen   *model.Link
+	height   int
+}
+
+func newPickModel(links []*model.Link) pickModel {
+	m := pickModel{links: links, height: maxPickRows}
+	m.filter()
+	return m
+}
+
+func (m pickModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m pickModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		// Leave room for the prompt and count lines
+		if msg.Height > 2 {
+			m.height = min(maxPickRows, msg.Height-2)
+		}
+	case tea.KeyMsg:
+		switch msg.Type {
+		case tea.KeyCtrlC, tea.KeyEsc:
+			return m, tea.Quit
+		case tea.KeyEnter:
+			if len(m.matches) > 0 {
+				m.chosen = m.matches[m.selected]
+			}
+			return m, tea.Quit
+		case tea.KeyUp, tea.KeyCtrlP, tea.KeyCtrlK:
+			if m.selected > 0 {
+				m.selected--
+			}
+		case tea.KeyDown, tea.KeyCtrlN, tea.KeyCtrlJ:
+			if m.selected < len(m.matches)-1 {
+				m.selected++
+			}
+		case tea.KeyBackspace:
+			if m.query != "" {
+				runes := []rune(m.query)
+				m.query = string(runes[:len(runes)-1])
+				m.filter()
+			}
+		case tea.KeyCtrlU:
+			m.query = ""
+			m.filter()
+		case tea.KeyRunes, tea.KeySpace:
+			m.query += string(msg.Runes)
+			m.filter()
+		}
+	}
+	return m, nil
+}
+
+func (m pickModel) View() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s\n", searchStyle.Render(">"), m.query)
+
+	start := 0
+	if m.selected >= m.height {
+		start = m.selected - m.height + 1
+	}
+	end := min(len(m.matches), start+m.height)
+	for i := start; i < end; i++ {
+		line := pickLine(m.matches[i])
+		if i == m.selected {
+			b.WriteString(selectedStyle.Render(line))
+		} else {
+			b.WriteString("  " + line)
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString(filterStyle.Render(fmt.Sprintf("%d/%d", len(m.matches), len(m.links))))
+	return b.String()
+}
+
+// filter recomputes the matches for the current query, best first.
+func (m *pickModel) filter() {
+	type scored struct {
+		link  *model.Link
+		score int
+	}
+	var results []scored
+	for _, link := range m.links {
+		if score, ok := fuzzyScore(m.query, pickText(link)); ok {
+			results = append(results, scored{link, score})
+		}
+	}
+	// Stable so ties keep the list order (priority, then newest)
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].score > results[j].score
+	})
+	m.matches = make([]*model.Link, len(results))
+	for i, r := range results {
+		m.matches[i] = r.link
+	}
+	m.selected = 0
+}
+
+func pickText(link *model.Link) string {
+	return strings.Join([]string{link.Title, link.URL, link.Tags}, " ")
+}
+
+func pickLine(link *model.Link) string {
+	line := link.URL
+	if link.Title != "" {
+		line = link.Title + "  " + urlStyle.Render(link.URL)
+	}
+	if link.Tags != "" {
+		line += "  " + tagStyle.Render(link.Tags)
+	}
+	return line
+}
+
+// fuzzyScore reports whether every rune of query appears in text in order
+// (case-insensitively), scoring consecutive runs and word starts higher.
+func fuzzyScore(query, text string) (int, bool) {
+	if query == "" {
+		return 0, true
+	}
+	q := []rune(strings.ToLower(query))
+	t := []rune(strings.ToLower(text))
+
+	score, qi, run := 0, 0, 0
+	for ti, r := range t {
+		if qi == len(q) {
+			break
+		}
+		if r != q[qi] {
+			run = 0
+			continue
+		}
+		run++
+		score += run
+		if ti == 0 || !unicode.IsLetter(t[ti-1]) && !unicode.IsDigit(t[ti-1]) {
+			score += 2
+		}
+		qi++
+	}
+	return score, qi == len(q)
+}
+
+// Pick shows a fuzzy finder over links on stderr, leaving stdout free for
+// the result, and returns the chosen link or nil if the user cancelled.
+func Pick(links []*model.Link) (*model.Link, error) {
+	p := tea.NewProgram(newPickModel(links), tea.WithOutput(os.Stderr))
+	final, err := p.Run()
+	if err != nil {
+		return nil, err
+	}
+	return final.(pickModel).chosen, nil
+}
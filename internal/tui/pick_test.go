@@ -0,0 +1,28 @@
+package tui
+
+import "testing"
+
+func TestFuzzyScore(t *testing.T) {
+	tests := []struct {
+		query, text string
+		match       bool
+	}{
+		{"", "anything", true},
+		{"gsch", "Go scheduler internals", true},
+		{"GSCH", "go scheduler", true},
+		{"hcs", "go scheduler", false},
+		{"rust", "go scheduler", false},
+	}
+	for _, tt := range tests {
+		if _, ok := fuzzyScore(tt.query, tt.text); ok != tt.match {
+			t.Errorf("fuzzyScore(%q, %q) match = %v, want %v", tt.query, tt.text, ok, tt.match)
+		}
+	}
+
+	// Consecutive and word-start matches rank above scattered ones
+	tight, _ := fuzzyScore("sched", "go scheduler")
+	loose, _ := fuzzyScore("sched", "some cached data")
+	if tight <= loose {
+		t.Errorf("Expected contiguous match to score higher: %d <= %d", tight, loose)
+	}
+}
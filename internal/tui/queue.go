@@ -0,0 +1,65 @@
+package tui
+
+import (
+	"context"
+
+	"github.com/bunchhieng/rl/internal/storage"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// moveInQueue moves the highlighted link offset places along the unread
+// queue, keeping it highlighted. The list has to show the queue as it is:
+// unread links in the default order, unfiltered. The queue is shown under
+// date sections, so a link moves past its neighbors in its own section,
+// skipping the links of other sections between them in the queue.
+func (m *appModel) moveInQueue(offset int) tea.Cmd {
+	if len(m.filtered) == 0 || m.selected >= len(m.filtered) {
+		return nil
+	}
+	first, last := 0, len(m.filtered)-1
+	where := ""
+	if m.sections != nil {
+		section := m.sections[m.sectionOf(m.selected)]
+		first, last = section.first, section.first+section.count-1
+		where = " of " + section.name
+	}
+	var status string
+	switch {
+	case m.readStatus != storage.ReadStatusUnread || m.sort != storage.SortDefault:
+		status = "Links can only be moved in the unread list in the default order"
+	case m.tagFilter != "" || m.searchQuery != "":
+		status = "Clear the search and tag filter to move links"
+	case offset < 0 && m.selected == first:
+		status = "Already at the top" + where
+	case offset > 0 && m.selected == last && (last < len(m.filtered)-1 || !m.moreToLoad()):
+		status = "Already at the bottom" + where
+	}
+	if status != "" {
+		return func() tea.Msg { return statusMsg{status} }
+	}
+
+	id := m.filtered[m.selected].ID
+	if to := max(first, min(m.selected+offset, last)); to != m.selected {
+		// The places between the two links in the queue
+		offset = m.queueIndex(m.filtered[to].ID) - m.queueIndex(id)
+	}
+	s, reload := m.storage, m.reload()
+	return m.startTask("Moving", false, func() tea.Msg {
+		if _, _, err := s.Reorder(context.Background(), id, offset); err != nil {
+			return errorf("move: %v", err)
+		}
+		msg := reload().(loadLinksMsg)
+		msg.follow = id
+		return msg
+	})
+}
+
+// queueIndex returns the place in the loaded queue of the link with id.
+func (m appModel) queueIndex(id string) int {
+	for i, link := range m.links {
+		if link.ID == id {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,84 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/bunchhieng/rl/internal/model"
+	"github.com/bunchhieng/rl/internal/storage"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestMoveInQueue(t *testing.T) {
+	s, err := storage.NewSQLiteStorage(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer s.Close()
+	ctx := context.Background()
+	base := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	add := func(link *model.Link) *model.Link {
+		if _, err := s.Add(ctx, link); err != nil {
+			t.Fatalf("Add failed: %v", err)
+		}
+		return link
+	}
+	for i := range 3 {
+		add(&model.Link{URL: fmt.Sprintf("https://example.com/%d", i), CreatedAt: base.Add(time.Duration(i) * time.Hour)})
+	}
+	// The queue runs high, today, 2, 1, 0; the list shows today's link
+	// under Today and the rest under Older
+	high := add(&model.Link{URL: "https://example.com/high", Priority: model.PriorityHigh, CreatedAt: base})
+	today := add(&model.Link{URL: "https://example.com/today", CreatedAt: time.Now()})
+	m := initialModel(s)
+	next, _ := m.update(loadLinks(s, m.listOptions(), 0)())
+	m = next.(appModel)
+	if m.filtered[0].ID != today.ID || m.filtered[1].ID != high.ID {
+		t.Fatalf("Expected today's link above the Older section, got %s, %s", m.filtered[0].ID, m.filtered[1].ID)
+	}
+	second := m.filtered[2].ID
+
+	// K moves the highlighted link above its neighbor in the section,
+	// past today's link in the queue, and it stays highlighted
+	next, _ = m.update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("j")})
+	next, _ = next.(appModel).update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("j")})
+	next, cmd := next.(appModel).update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("K")})
+	m = next.(appModel)
+	next, _ = m.update(taskResult(t, cmd()))
+	m = next.(appModel)
+	if m.filtered[1].ID != second || m.selected != 1 {
+		t.Fatalf("Expected %s moved to the top of Older and highlighted, got %s at %d", second, m.filtered[1].ID, m.selected)
+	}
+
+	next, cmd = m.update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("K")})
+	if status, ok := cmd().(statusMsg); !ok || status.message != "Already at the top of Older" {
+		t.Errorf("Expected a status at the top of the section, got %#v", cmd())
+	}
+
+	// J moves it back down
+	next, cmd = next.(appModel).update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("J")})
+	m = next.(appModel)
+	next, _ = m.update(taskResult(t, cmd()))
+	m = next.(appModel)
+	if m.filtered[2].ID != second || m.selected != 2 {
+		t.Errorf("Expected %s moved back down and highlighted, got %s at %d", second, m.filtered[2].ID, m.selected)
+	}
+
+	// Links don't move out of their section
+	m.selected = 0
+	if _, cmd := m.update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("J")}); cmd == nil {
+		t.Fatal("Expected a status at the bottom of Today")
+	} else if status, ok := cmd().(statusMsg); !ok || status.message != "Already at the bottom of Today" {
+		t.Errorf("Expected a status at the bottom of the section, got %#v", cmd())
+	}
+
+	// Sorted some other way, the list isn't the queue
+	m.sort = storage.SortOrders[0]
+	if _, cmd := m.update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("K")}); cmd == nil {
+		t.Fatal("Expected a status when sorted")
+	} else if _, ok := cmd().(statusMsg); !ok {
+		t.Errorf("Expected a status rather than a move when sorted, got %#v", cmd())
+	}
+}
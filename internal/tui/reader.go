@@ -0,0 +1,125 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/bunchhieng/rl/internal/model"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// maxReaderWidth keeps lines at a comfortable reading length on wide terminals.
+const maxReaderWidth = 80
+
+// readerView shows a link's archived article text.
+type readerView struct {
+	link   *model.Link
+	text   string
+	offset int // first visible line
+}
+
+type contentMsg struct {
+	link *model.Link
+	text string
+	err  error
+}
+
+func (m *appModel) openReader() tea.Cmd {
+	if len(m.filtered) == 0 || m.selected >= len(m.filtered) {
+		return nil
+	}
+	link := m.filtered[m.selected]
+	s := m.storage
+	return func() tea.Msg {
+		text, err := s.Content(context.Background(), link.ID)
+		return contentMsg{link: link, text: text, err: err}
+	}
+}
+
+func (m appModel) handleContentMsg(msg contentMsg) (tea.Model, tea.Cmd) {
+	if msg.err == model.ErrNotFound {
+		return m, func() tea.Msg {
+			return statusMsg{"No archived text; run 'rl fetch' on this link first"}
+		}
+	}
+	if msg.err != nil {
+		return m.handleError(errorMsg{msg.err})
+	}
+	m.reader = &readerView{link: msg.link, text: msg.text}
+	return m, nil
+}
+
+// readerLines word-wraps the article to the terminal width, with a blank
+// line between paragraphs.
+func (m appModel) readerLines() []string {
+	width := min(maxReaderWidth, max(20, m.width-4))
+	wrap := lipgloss.NewStyle().Width(width)
+
+	var lines []string
+	for i, paragraph := range strings.Split(m.reader.text, "\n") {
+		if i > 0 {
+			lines = append(lines, "")
+		}
+		lines = append(lines, strings.Split(wrap.Render(paragraph), "\n")...)
+	}
+	return lines
+}
+
+// readerHeight is the number of article lines visible at once.
+func (m appModel) readerHeight() int {
+	return max(1, m.height-3) // header, blank line, status bar
+}
+
+func (m appModel) handleReaderInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	reader := *m.reader
+	m.reader = &reader
+	maxOffset := max(0, len(m.readerLines())-m.readerHeight())
+
+	if msg.Type == tea.KeyCtrlC {
+		return m, tea.Quit
+	}
+	a := m.keys.viewAction(msg.String(), actionReader)
+	switch a {
+	case actionBack:
+		m.reader = nil
+	case actionOpen:
+		cmd := m.openLink()
+		return m, cmd
+	default:
+		reader.offset, _ = scroll(a, reader.offset, maxOffset, m.readerHeight())
+	}
+	return m, nil
+}
+
+func (m appModel) renderReader() string {
+	lines := m.readerLines()
+	start := min(m.reader.offset, max(0, len(lines)-m.readerHeight()))
+	end := min(len(lines), start+m.readerHeight())
+
+	var b strings.Builder
+	b.WriteString(headerStyle.Render(displayTitle(m.reader.link)))
+	b.WriteString("\n\n")
+	for _, line := range lines[start:end] {
+		b.WriteString("  " + line + "\n")
+	}
+
+	percent := 100
+	if len(lines) > m.readerHeight() {
+		percent = end * 100 / len(lines)
+	}
+	b.WriteString(statusBarStyle.Width(m.width).Render(
+		fmt.Sprintf("%d%%  |  %s", percent, hints(
+			m.keys.hint("scroll", actionDown, actionUp), m.keys.hint("page", actionToggle, actionPageDown),
+			m.keys.hint("open in browser", actionOpen), "[esc] back"))))
+	return b.String()
+}
+
+// displayTitle returns the link title, falling back to its URL.
+func displayTitle(link *model.Link) string {
+	if link.Title != "" {
+		return link.Title
+	}
+	return link.URL
+}
@@ -6,48 +6,9 @@ import (
 	"time"
 
 	"github.com/bunchhieng/rl/internal/model"
+	"github.com/bunchhieng/rl/internal/reltime"
 	"github.com/bunchhieng/rl/internal/storage"
-	"github.com/charmbracelet/lipgloss"
-)
-
-var (
-	// Styles
-	headerStyle = lipgloss.NewStyle().
-			Bold(true).
-			Foreground(lipgloss.Color("62")).
-			Padding(0, 1)
-
-	statusBarStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("241")).
-			Background(lipgloss.Color("236")).
-			Padding(0, 1)
-
-	selectedStyle = lipgloss.NewStyle().
-			Background(lipgloss.Color("62")).
-			Foreground(lipgloss.Color("230")).
-			Padding(0, 1)
-
-	unreadStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("39")).
-			Bold(true)
-
-	readStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("241"))
-
-	urlStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("33"))
-
-	tagStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("220"))
-
-	searchStyle = lipgloss.NewStyle().
-			Background(lipgloss.Color("236")).
-			Foreground(lipgloss.Color("230")).
-			Padding(0, 1)
-
-	filterStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("241")).
-			Padding(0, 1)
+	"github.com/mattn/go-runewidth"
 )
 
 func (m appModel) renderHeader() string {
@@ -59,43 +20,109 @@ func (m appModel) renderHeader() string {
 		filterText = "All"
 	}
 
-	header := fmt.Sprintf("rl - Read Later  [Filter: %s]  [%d links]", filterText, len(m.filtered))
+	sortText := "default"
+	if m.sort != storage.SortDefault {
+		sortText = string(m.sort)
+	}
+
+	if m.tagFilter != "" {
+		filterText += ", #" + m.tagFilter
+	}
+
+	header := fmt.Sprintf("rl - Read Later  [Filter: %s]  [Sort: %s]  [%d links]", filterText, sortText, m.listCount())
+	if m.streak == nil {
+		return headerStyle.Render(header)
+	}
+	if m.streak.Current > 0 {
+		header += fmt.Sprintf("  [Streak: %dd]", m.streak.Current)
+	}
+	if m.streak.Goal > 0 {
+		header += fmt.Sprintf("  [Week: %d/%d]", m.streak.Week, m.streak.Goal)
+	}
+	if nudge := m.streak.Nudge(); nudge != "" {
+		return headerStyle.Render(header) + filterStyle.Render(nudge)
+	}
 	return headerStyle.Render(header)
 }
 
 func (m appModel) renderSearchBar() string {
 	prompt := fmt.Sprintf("/%s", m.searchQuery)
-	return searchStyle.Width(m.width - 2).Render(prompt)
+	mode := "[filter, tab: full-text]"
+	if m.fullText {
+		mode = "[full-text, tab: filter]"
+	}
+	if len(m.searchHistory) > 0 {
+		mode += "  [↑/↓: history]"
+	}
+	if m.fullText && m.searchErr != nil {
+		mode += "  " + m.searchErr.Error()
+	}
+	return searchStyle.Width(m.width - 2).Render(prompt + "  " + mode)
 }
 
 func (m appModel) renderList() string {
 	if m.confirmDelete {
 		return m.renderDeleteConfirmation()
 	}
+	if m.editing != nil {
+		return m.renderEditForm()
+	}
+	if m.tagPicker != nil {
+		return m.renderTagPicker()
+	}
 
-	if len(m.filtered) == 0 {
+	if len(m.filtered) == 0 && len(m.sections) == 0 {
+		if m.moreToLoad() {
+			return readStyle.Render("loading…")
+		}
 		return "No links found. Press 'a' to add a link or 'q' to quit."
 	}
 
 	var b strings.Builder
-	listHeight := m.height - 6 // Reserve space for header, search, status
-
-	for i, link := range m.filtered {
-		if i >= listHeight {
-			break
+	rows := m.rows()
+	end := min(len(rows), m.offset+m.listHeight())
+	for _, row := range rows[m.offset:end] {
+		switch {
+		case row.section >= 0:
+			b.WriteString(renderSectionHeader(m.sections[row.section]))
+		case row.details:
+			b.WriteString(m.renderLinkDetails(m.filtered[row.link]))
+		default:
+			b.WriteString(m.renderLink(m.filtered[row.link], row.link == m.selected, m.isMarked(row.link)))
 		}
-
-		line := m.renderLink(link, i == m.selected)
-		b.WriteString(line)
+		b.WriteString("\n")
+	}
+	// Scrolled to the end of the loaded links while more are on the way
+	if end == len(rows) && end-m.offset < m.listHeight() && m.moreToLoad() {
+		b.WriteString(readStyle.Render("  loading…"))
 		b.WriteString("\n")
 	}
 
 	return b.String()
 }
 
-func (m appModel) renderLink(link *model.Link, selected bool) string {
-	// Check if this link is in the multi-selection
-	isMultiSelected := m.selectedIDs[link.ID]
+// renderSectionHeader renders a date section's header with its link
+// count, e.g. "▾ Today (3)".
+func renderSectionHeader(section listSection) string {
+	mark := "▾"
+	if section.collapsed {
+		mark = "▸"
+	}
+	return sectionStyle.Render(fmt.Sprintf("%s %s (%d)", mark, section.name, section.count))
+}
+
+// renderLinkDetails renders the line under a link with its description,
+// on one line, and full URL, cut to the width of the screen.
+func (m appModel) renderLinkDetails(link *model.Link) string {
+	const indent = "      "
+	details := link.URL
+	if about := strings.Join(strings.Fields(link.Description), " "); about != "" {
+		details = about + " · " + details
+	}
+	return indent + readStyle.Render(runewidth.Truncate(details, max(m.width-len(indent)-1, 10), "..."))
+}
+
+func (m appModel) renderLink(link *model.Link, selected, isMultiSelected bool) string {
 
 	// Selection indicator
 	selectIcon := " "
@@ -113,18 +140,30 @@ func (m appModel) renderLink(link *model.Link, selected bool) string {
 		statusColor = readStyle
 	}
 
+	// Priority indicator
+	priorityIcon := " "
+	switch link.Priority {
+	case model.PriorityHigh:
+		priorityIcon = highPriorityStyle.Render("!")
+	case model.PriorityLow:
+		priorityIcon = readStyle.Render("↓")
+	}
+
 	// Title or URL
 	title := link.Title
 	if title == "" {
 		title = link.URL
 	}
-	if len(title) > 55 {
-		title = title[:52] + "..."
-	}
+	title = runewidth.Truncate(title, 55, "...")
 
 	// Format time
 	timeStr := formatTime(link.CreatedAt)
 
+	// Reading time, or running time marked for video and audio
+	if link.ReadingSeconds > 0 {
+		timeStr += fmt.Sprintf(" · %s%dm", link.MediaMark(), (link.ReadingSeconds+59)/60)
+	}
+
 	// Tags
 	tagsStr := ""
 	if link.Tags != "" {
@@ -132,9 +171,10 @@ func (m appModel) renderLink(link *model.Link, selected bool) string {
 	}
 
 	// Build line
-	line := fmt.Sprintf("%s %s %s %s%s",
+	line := fmt.Sprintf("%s %s%s %s %s%s",
 		selectIcon,
 		statusColor.Render(statusIcon),
+		priorityIcon,
 		urlStyle.Render(title),
 		readStyle.Render(timeStr),
 		tagStyle.Render(tagsStr),
@@ -154,24 +194,58 @@ func (m appModel) renderStatusBar() string {
 	var parts []string
 
 	selectedCount := len(m.selectedIDs)
+	if m.visual {
+		selectedCount = len(m.getSelectedLinks())
+	}
+	if status := m.taskStatus(); status != "" {
+		parts = append(parts, status)
+	}
 	if m.statusMsg != "" {
 		parts = append(parts, m.statusMsg)
 	} else {
+		count := m.listCount() - m.collapsedCount()
+		position := fmt.Sprintf("%d/%d", m.selected+1, count)
+		if start, end := m.shownLinks(); count > end-start {
+			position += fmt.Sprintf(" %s", scrollIndicator(start, end, count))
+		}
 		if selectedCount > 0 {
-			parts = append(parts, fmt.Sprintf("%d/%d (%d selected)", m.selected+1, len(m.filtered), selectedCount))
-		} else {
-			parts = append(parts, fmt.Sprintf("%d/%d", m.selected+1, len(m.filtered)))
+			position += fmt.Sprintf(" (%d selected)", selectedCount)
 		}
+		parts = append(parts, position)
+	}
+
+	if m.moreToLoad() && (m.loading || m.needsAll()) {
+		parts = append(parts, "loading…")
 	}
 
-	if selectedCount > 0 {
-		parts = append(parts, "[space]toggle [ctrl+a]select all [ctrl+d]deselect")
+	km := m.keys
+	if m.visual {
+		parts = append(parts, "-- VISUAL -- "+hints(km.hint("extend", actionDown, actionUp),
+			km.hint("add to selection", actionVisual), km.hint("cancel", actionClearSearch)))
+	} else if selectedCount > 0 {
+		parts = append(parts, hints(km.hint("toggle", actionToggle),
+			km.hint("select all", actionSelectAll), km.hint("deselect", actionDeselectAll)))
 	}
-	parts = append(parts, "[o]pen [d]one [u]ndo [r]emove [tab]filter [q]uit")
+	parts = append(parts, hints(km.hint("open", actionOpen), km.hint("done", actionMarkRead),
+		km.hint("undo", actionMarkUnread), km.hint("edit", actionEdit), km.hint("remove", actionRemove),
+		km.hint("filter", actionFilter), km.hint("help", actionHelp), km.hint("quit", actionQuit)))
 
 	return statusBarStyle.Width(m.width).Render(strings.Join(parts, "  |  "))
 }
 
+// scrollIndicator describes which part of a list is visible, e.g.
+// "[11-30 ↑↓]", with arrows showing where more rows are hidden.
+func scrollIndicator(start, end, total int) string {
+	arrows := ""
+	if start > 0 {
+		arrows += "↑"
+	}
+	if end < total {
+		arrows += "↓"
+	}
+	return fmt.Sprintf("[%d-%d %s]", start+1, end, arrows)
+}
+
 func (m appModel) renderDeleteConfirmation() string {
 	if len(m.deleteLinkIDs) == 0 {
 		return ""
@@ -194,13 +268,11 @@ func (m appModel) renderDeleteConfirmation() string {
 		if title == "" {
 			title = linkToDelete.URL
 		}
-		if len(title) > 50 {
-			title = title[:47] + "..."
-		}
-		confirmText = fmt.Sprintf("Delete link: %s?\n\n[y]es / [n]o", title)
+		title = runewidth.Truncate(title, 50, "...")
+		confirmText = fmt.Sprintf("Move to the trash: %s?\n\n[y]es / [n]o", title)
 	} else {
 		// Multi delete
-		confirmText = fmt.Sprintf("Delete %d selected links?\n\n[y]es / [n]o", len(m.deleteLinkIDs))
+		confirmText = fmt.Sprintf("Move %d selected links to the trash?\n\n[y]es / [n]o", len(m.deleteLinkIDs))
 	}
 
 	return selectedStyle.Width(m.width-4).Padding(1, 2).Render(confirmText)
@@ -210,5 +282,8 @@ func formatTime(t time.Time) string {
 	if t.IsZero() {
 		return "-"
 	}
-	return t.In(estLocation).Format("2006-01-02 15:04")
+	if relativeTimes {
+		return reltime.Format(t, time.Now())
+	}
+	return t.In(displayLocation).Format(dateLayout)
 }
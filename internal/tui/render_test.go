@@ -0,0 +1,29 @@
+package tui
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFormatTime(t *testing.T) {
+	t.Cleanup(func() {
+		displayLocation, dateLayout, relativeTimes = time.Local, defaultDateLayout, false
+	})
+	saved := time.Date(2024, 1, 1, 20, 0, 0, 0, time.UTC)
+
+	displayLocation = time.FixedZone("JST", 9*3600)
+	if got, want := formatTime(saved), "2024-01-02 05:00"; got != want {
+		t.Errorf("formatTime() = %q, want %q", got, want)
+	}
+	dateLayout = "Jan 2 15:04 MST"
+	if got, want := formatTime(saved), "Jan 2 05:00 JST"; got != want {
+		t.Errorf("formatTime() with a layout = %q, want %q", got, want)
+	}
+	relativeTimes = true
+	if got, want := formatTime(time.Now().Add(-3*time.Hour)), "3h ago"; got != want {
+		t.Errorf("formatTime() with relative times = %q, want %q", got, want)
+	}
+	if got := formatTime(time.Time{}); got != "-" {
+		t.Errorf("formatTime(zero) = %q, want -", got)
+	}
+}
@@ -0,0 +1,87 @@
+package tui
+
+import (
+	"context"
+	"time"
+
+	"github.com/bunchhieng/rl/internal/model"
+	"github.com/bunchhieng/rl/internal/storage"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// searchDebounce is how long typing must pause before a full-text query
+// is sent to storage.
+const searchDebounce = 250 * time.Millisecond
+
+// searchTickMsg fires after the debounce delay; it is stale unless seq is
+// still the latest.
+type searchTickMsg struct {
+	seq int
+}
+
+type searchResultsMsg struct {
+	seq   int
+	links []*model.Link
+	err   error
+}
+
+// toggleFullText switches the search bar between filtering the loaded
+// links and querying the full-text index.
+func (m *appModel) toggleFullText() tea.Cmd {
+	m.fullText = !m.fullText
+	m.searchResults, m.searchErr = nil, nil
+	if m.fullText {
+		return m.queueSearch()
+	}
+	m.applyFilters()
+	return nil
+}
+
+// queueSearch schedules a full-text query for the current search text
+// once typing pauses. An empty query shows the loaded links again.
+func (m *appModel) queueSearch() tea.Cmd {
+	m.searchSeq++
+	if m.searchQuery == "" {
+		m.searchResults, m.searchErr = nil, nil
+		m.applyFilters()
+		return nil
+	}
+	seq := m.searchSeq
+	return tea.Tick(searchDebounce, func(time.Time) tea.Msg {
+		return searchTickMsg{seq: seq}
+	})
+}
+
+// runSearch queries storage for the current search text, including the
+// archived article text, among the links the read filter shows.
+func (m appModel) runSearch() tea.Cmd {
+	s, query, seq := m.storage, m.searchQuery, m.searchSeq
+	opts := storage.SearchOptions{Content: true, ReadStatus: &m.readStatus}
+	return func() tea.Msg {
+		links, err := s.Search(context.Background(), query, opts)
+		return searchResultsMsg{seq: seq, links: links, err: err}
+	}
+}
+
+func (m appModel) handleSearchTick(msg searchTickMsg) (tea.Model, tea.Cmd) {
+	if msg.seq != m.searchSeq || !m.fullText || m.searchQuery == "" {
+		return m, nil
+	}
+	return m, m.runSearch()
+}
+
+// handleSearchResults shows the results of the latest query. A query that
+// doesn't parse yet, as while typing `rust AND`, keeps the previous
+// results and reports the problem in the search bar.
+func (m appModel) handleSearchResults(msg searchResultsMsg) (tea.Model, tea.Cmd) {
+	if msg.seq != m.searchSeq || !m.fullText {
+		return m, nil
+	}
+	m.searchErr = msg.err
+	if msg.err == nil {
+		m.searchResults = msg.links
+		m.selected = 0
+		m.applyFilters()
+	}
+	return m, nil
+}
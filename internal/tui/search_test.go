@@ -0,0 +1,80 @@
+package tui
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bunchhieng/rl/internal/model"
+	"github.com/bunchhieng/rl/internal/storage"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestFullTextSearch(t *testing.T) {
+	s, err := storage.NewSQLiteStorage(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer s.Close()
+
+	ctx := context.Background()
+	rust, _ := s.Add(ctx, &model.Link{URL: "https://a.example", Title: "Async Rust"})
+	read, _ := s.Add(ctx, &model.Link{URL: "https://b.example", Title: "Rust ownership"})
+	if err := s.MarkRead(ctx, read.ID); err != nil {
+		t.Fatalf("MarkRead failed: %v", err)
+	}
+
+	m := initialModel(s)
+	m.searchMode = true
+	m.toggleFullText()
+	typed := func(text string) tea.Cmd {
+		t.Helper()
+		var cmd tea.Cmd
+		for _, r := range text {
+			_, cmd = m.handleSearchInput(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+		}
+		return cmd
+	}
+	// search runs the debounced query for the latest keystroke, as the
+	// tick would after the pause
+	search := func() {
+		t.Helper()
+		next, cmd := m.handleSearchTick(searchTickMsg{seq: m.searchSeq})
+		m = next.(appModel)
+		if cmd == nil {
+			t.Fatal("Expected the latest tick to run a query")
+		}
+		next, _ = m.handleSearchResults(cmd().(searchResultsMsg))
+		m = next.(appModel)
+	}
+
+	typed("rust")
+	if _, cmd := m.handleSearchTick(searchTickMsg{seq: m.searchSeq - 1}); cmd != nil {
+		t.Error("A tick from an earlier keystroke should not query")
+	}
+	search()
+	if len(m.filtered) != 1 || m.filtered[0].ID != rust.ID {
+		t.Errorf("Expected only the unread match, got %d links", len(m.filtered))
+	}
+
+	// Showing every link searches them all again
+	m.cycleFilter()
+	m.cycleFilter()
+	search()
+	if len(m.filtered) != 2 {
+		t.Errorf("Expected both matches with every link shown, got %d links", len(m.filtered))
+	}
+	m.cycleFilter()
+	search()
+
+	// A query that doesn't parse yet keeps the previous results
+	typed(" AND")
+	search()
+	if m.searchErr == nil || len(m.filtered) != 1 {
+		t.Errorf("Expected an error and the previous results, got %v and %d links", m.searchErr, len(m.filtered))
+	}
+
+	m.clearSearch()
+	if !m.fullText || m.searchResults != nil || m.searchQuery != "" {
+		t.Error("clearSearch should drop the query and results but stay in full-text mode")
+	}
+}
@@ -0,0 +1,238 @@
+package tui
+
+import (
+	"time"
+
+	"github.com/bunchhieng/rl/internal/model"
+	"github.com/bunchhieng/rl/internal/storage"
+)
+
+// Date sections split the list under headers, like an inbox. Sorted by
+// date, each section is a run of the list; in the default order, the
+// queue isn't by date, so each section holds its links in queue order.
+// A collapsed section shows only its header; its links are left out of
+// the filtered list, so navigation and selection skip them.
+
+// sectionNames are the date sections, newest first.
+var sectionNames = []string{"Today", "Yesterday", "Last week", "Older"}
+
+// listSection is a run of filtered links saved in the same date section.
+type listSection struct {
+	name      string
+	count     int // links in the section, shown or not
+	collapsed bool
+	first     int // index in filtered of the section's first link, if expanded
+}
+
+// listRow is one row of the list: a section header, a link, or the line
+// under a link showing its description and URL.
+type listRow struct {
+	section int  // index in sections of a header row, or -1
+	link    int  // index in filtered of a link row, or -1
+	details bool // the line under the link
+}
+
+// sectionName returns the date section of a link saved at t: calendar
+// days in the display time zone, so "Yesterday" starts at midnight.
+func sectionName(t, now time.Time) string {
+	y, mo, d := now.In(displayLocation).Date()
+	today := time.Date(y, mo, d, 0, 0, 0, 0, displayLocation)
+	switch t := t.In(displayLocation); {
+	case !t.Before(today):
+		return sectionNames[0]
+	case !t.Before(today.AddDate(0, 0, -1)):
+		return sectionNames[1]
+	case !t.Before(today.AddDate(0, 0, -7)):
+		return sectionNames[2]
+	default:
+		return sectionNames[3]
+	}
+}
+
+// grouped reports whether the list is split into date sections: in the
+// default order or sorted by date, rather than by title or relevance.
+func (m appModel) grouped() bool {
+	if m.fullText && m.searchQuery != "" {
+		return false
+	}
+	return m.sort == storage.SortDefault || m.sort == storage.SortNewest || m.sort == storage.SortOldest
+}
+
+// bySection returns links ordered by date section, newest first, keeping
+// their order within each section.
+func bySection(links []*model.Link, now time.Time) []*model.Link {
+	sections := make(map[string][]*model.Link, len(sectionNames))
+	for _, link := range links {
+		name := sectionName(link.CreatedAt, now)
+		sections[name] = append(sections[name], link)
+	}
+	ordered := make([]*model.Link, 0, len(links))
+	for _, name := range sectionNames {
+		ordered = append(ordered, sections[name]...)
+	}
+	return ordered
+}
+
+// applySections splits the filtered links into date sections, leaving
+// out those in collapsed sections.
+func (m *appModel) applySections() {
+	m.sections = nil
+	if !m.grouped() {
+		return
+	}
+	now := time.Now()
+	links := m.filtered
+	if m.sort == storage.SortDefault {
+		links = bySection(links, now)
+	}
+	shown := make([]*model.Link, 0, len(links))
+	for _, link := range links {
+		name := sectionName(link.CreatedAt, now)
+		if n := len(m.sections); n == 0 || m.sections[n-1].name != name {
+			m.sections = append(m.sections, listSection{name: name, collapsed: m.collapsed[name], first: len(shown)})
+		}
+		section := &m.sections[len(m.sections)-1]
+		section.count++
+		if !section.collapsed {
+			shown = append(shown, link)
+		}
+	}
+	m.filtered = shown
+}
+
+// rows returns the rows of the list: each link, followed by its details
+// line when those are shown, under its section's header when the list is
+// grouped.
+func (m appModel) rows() []listRow {
+	rows := make([]listRow, 0, len(m.filtered)*m.rowsPerLink()+len(m.sections))
+	addLinks := func(from, to int) {
+		for i := from; i < to; i++ {
+			rows = append(rows, listRow{section: -1, link: i})
+			if m.inlineDetails {
+				rows = append(rows, listRow{section: -1, link: i, details: true})
+			}
+		}
+	}
+	if m.sections == nil {
+		addLinks(0, len(m.filtered))
+		return rows
+	}
+	for i, section := range m.sections {
+		rows = append(rows, listRow{section: i, link: -1})
+		if !section.collapsed {
+			addLinks(section.first, section.first+section.count)
+		}
+	}
+	return rows
+}
+
+// rowsPerLink is how many rows each link takes: two with its details line.
+func (m appModel) rowsPerLink() int {
+	if m.inlineDetails {
+		return 2
+	}
+	return 1
+}
+
+// rowOf returns the row of the link at index i in filtered.
+func (m appModel) rowOf(i int) int {
+	per := m.rowsPerLink()
+	if m.sections == nil {
+		return i * per
+	}
+	row := 0
+	for _, section := range m.sections {
+		row++ // the header
+		if section.collapsed {
+			continue
+		}
+		if i < section.first+section.count {
+			return row + (i-section.first)*per
+		}
+		row += section.count * per
+	}
+	return row
+}
+
+// foldSection collapses the date section of the highlighted link,
+// moving the highlight to the next link shown.
+func (m *appModel) foldSection() {
+	if m.sections == nil || len(m.filtered) == 0 {
+		return
+	}
+	m.setCollapsed(m.sectionOf(m.selected), true)
+}
+
+// unfoldSections expands every date section.
+func (m *appModel) unfoldSections() {
+	for i := range m.sections {
+		m.setCollapsed(i, false)
+	}
+}
+
+// setCollapsed collapses or expands section i, keeping the highlighted
+// link highlighted if it is still shown.
+func (m *appModel) setCollapsed(i int, collapsed bool) {
+	section := m.sections[i]
+	if section.collapsed == collapsed {
+		return
+	}
+	if m.collapsed == nil {
+		m.collapsed = make(map[string]bool)
+	}
+	m.collapsed[section.name] = collapsed
+	m.visual = false
+
+	var follow string
+	if len(m.filtered) > 0 {
+		follow = m.filtered[m.selected].ID
+	}
+	next := m.selected
+	if collapsed {
+		// The first link after the section takes its place
+		next = section.first
+	}
+	m.applyFilters()
+	m.selected = min(next, max(len(m.filtered)-1, 0))
+	for j, link := range m.filtered {
+		if link.ID == follow {
+			m.selected = j
+			break
+		}
+	}
+}
+
+// sectionOf returns the index in sections of the section holding the link
+// at index i in filtered.
+func (m appModel) sectionOf(i int) int {
+	for j, section := range m.sections {
+		if !section.collapsed && i < section.first+section.count {
+			return j
+		}
+	}
+	return len(m.sections) - 1
+}
+
+// shownLinks returns the range of indexes in filtered of the links on
+// screen.
+func (m appModel) shownLinks() (start, end int) {
+	rows := m.rows()
+	start, end = len(m.filtered), 0
+	for _, row := range rows[min(m.offset, len(rows)):min(m.offset+m.listHeight(), len(rows))] {
+		if row.link >= 0 {
+			start, end = min(start, row.link), max(end, row.link+1)
+		}
+	}
+	return min(start, end), end
+}
+
+// collapsedCount returns how many loaded links collapsed sections hide.
+func (m appModel) collapsedCount() int {
+	n := 0
+	for _, section := range m.sections {
+		if section.collapsed {
+			n += section.count
+		}
+	}
+	return n
+}
@@ -0,0 +1,93 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bunchhieng/rl/internal/model"
+	"github.com/bunchhieng/rl/internal/storage"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestSectionName(t *testing.T) {
+	displayLocation = time.UTC
+	now := time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		saved time.Time
+		want  string
+	}{
+		{now.Add(-time.Hour), "Today"},
+		{time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC), "Today"},
+		{time.Date(2026, 10, 16, 23, 59, 0, 0, time.UTC), "Yesterday"},
+		{time.Date(2026, 10, 12, 12, 0, 0, 0, time.UTC), "Last week"},
+		{time.Date(2026, 10, 9, 23, 0, 0, 0, time.UTC), "Older"},
+	}
+	for _, tt := range tests {
+		if got := sectionName(tt.saved, now); got != tt.want {
+			t.Errorf("sectionName(%v) = %q, want %q", tt.saved, got, tt.want)
+		}
+	}
+}
+
+func TestDateSections(t *testing.T) {
+	displayLocation = time.Local
+	now := time.Now()
+	m := initialModel(nil)
+	m.sort = storage.SortNewest
+	m.links = []*model.Link{
+		{ID: "a", CreatedAt: now},
+		{ID: "b", CreatedAt: now},
+		{ID: "c", CreatedAt: now.AddDate(0, 0, -30)},
+		{ID: "d", CreatedAt: now.AddDate(0, 0, -40)},
+	}
+	m.applyFilters()
+	if len(m.sections) != 2 || len(m.rows()) != 6 {
+		t.Fatalf("Expected 2 sections in 6 rows, got %d in %d", len(m.sections), len(m.rows()))
+	}
+	if got := m.rowOf(2); got != 4 {
+		t.Errorf("Expected link c on row 4, below both headers, got %d", got)
+	}
+
+	// Collapsing Today moves the highlight to the first older link
+	m.selected = 1
+	next, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("z")})
+	m = next.(appModel)
+	if len(m.filtered) != 2 || m.filtered[m.selected].ID != "c" {
+		t.Fatalf("Expected c highlighted among 2 links, got %d links", len(m.filtered))
+	}
+	if rows := m.rows(); len(rows) != 4 || rows[0].section != 0 || rows[1].section != 1 {
+		t.Errorf("Expected a collapsed Today header above the Older section, got %+v", rows)
+	}
+
+	// Clicking the collapsed header expands it again, keeping the highlight
+	next, _ = m.handleMouse(tea.MouseMsg{Button: tea.MouseButtonLeft, Action: tea.MouseActionPress, Y: m.listTop()})
+	m = next.(appModel)
+	if len(m.filtered) != 4 || m.filtered[m.selected].ID != "c" {
+		t.Errorf("Expected every link shown with c still highlighted, got %d links", len(m.filtered))
+	}
+
+	// In the default order, each section keeps the queue's order
+	m.sort = storage.SortDefault
+	m.links = []*model.Link{
+		{ID: "c", CreatedAt: now.AddDate(0, 0, -30)},
+		{ID: "a", CreatedAt: now},
+		{ID: "d", CreatedAt: now.AddDate(0, 0, -40)},
+		{ID: "b", CreatedAt: now},
+	}
+	m.applyFilters()
+	var order []string
+	for _, link := range m.filtered {
+		order = append(order, link.ID)
+	}
+	if len(m.sections) != 2 || strings.Join(order, "") != "abcd" {
+		t.Errorf("Expected a, b under Today and c, d under Older, got %d sections of %v", len(m.sections), order)
+	}
+
+	// Other sort orders aren't grouped
+	m.sort = storage.SortTitle
+	m.applyFilters()
+	if m.sections != nil || len(m.rows()) != 4 {
+		t.Errorf("Expected no sections when sorted by title, got %d", len(m.sections))
+	}
+}
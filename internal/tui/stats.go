@@ -0,0 +1,124 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/bunchhieng/rl/internal/storage"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// maxStatsRows caps the top tags and domains shown on the stats screen.
+const maxStatsRows = 8
+
+type statsMsg struct {
+	stats *storage.Stats
+	err   error
+}
+
+func loadStats(s storage.Storage) tea.Cmd {
+	return func() tea.Msg {
+		stats, err := s.Stats(context.Background())
+		return statsMsg{stats: stats, err: err}
+	}
+}
+
+func (m appModel) handleStatsMsg(msg statsMsg) (tea.Model, tea.Cmd) {
+	if msg.err != nil {
+		return m.handleError(errorMsg{msg.err})
+	}
+	m.stats = msg.stats
+	return m, nil
+}
+
+func (m appModel) handleStatsInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if msg.Type == tea.KeyCtrlC {
+		return m, tea.Quit
+	}
+	if m.keys.viewAction(msg.String(), actionStats) == actionBack {
+		m.stats = nil
+	}
+	return m, nil
+}
+
+func (m appModel) renderStats() string {
+	stats := m.stats
+	var b strings.Builder
+	b.WriteString(headerStyle.Render("rl - Statistics"))
+	b.WriteString("\n\n")
+
+	row := func(label string, value int) {
+		fmt.Fprintf(&b, "%s %d\n", detailLabelStyle.Render(label), value)
+	}
+	row("Total", stats.Total)
+	row("Unread", stats.Unread)
+	row("Read", stats.Read)
+	row("Snoozed", stats.Snoozed)
+
+	added := 0
+	for _, n := range stats.Weekly {
+		added += n
+	}
+	b.WriteString("\n")
+	fmt.Fprintf(&b, "%s %s  %s\n", detailLabelStyle.Render("Added"),
+		unreadStyle.Render(sparkline(stats.Weekly)),
+		readStyle.Render(fmt.Sprintf("%d in %d weeks, %d this week", added, len(stats.Weekly), last(stats.Weekly))))
+
+	tags := renderCounts("Top tags", stats.Tags, tagStyle)
+	domains := renderCounts("Top domains", stats.Domains, urlStyle)
+	b.WriteString("\n")
+	b.WriteString(lipgloss.JoinHorizontal(lipgloss.Top, tags, "    ", domains))
+	b.WriteString("\n\n")
+
+	b.WriteString(statusBarStyle.Width(m.width).Render("[esc] back"))
+	return b.String()
+}
+
+// renderCounts renders a titled column of names with right-aligned counts.
+func renderCounts(title string, counts []storage.Count, style lipgloss.Style) string {
+	var b strings.Builder
+	b.WriteString(helpTitleStyle.Render(title))
+	if len(counts) == 0 {
+		b.WriteString("\n" + readStyle.Render("none"))
+		return b.String()
+	}
+	counts = counts[:min(len(counts), maxStatsRows)]
+	width := 0
+	for _, c := range counts {
+		width = max(width, lipgloss.Width(c.Name))
+	}
+	for _, c := range counts {
+		pad := strings.Repeat(" ", width-lipgloss.Width(c.Name))
+		fmt.Fprintf(&b, "\n%s%s %4d", style.Render(c.Name), pad, c.Count)
+	}
+	return b.String()
+}
+
+// sparkBars are the block characters used by sparkline, lowest first.
+var sparkBars = []rune("▁▂▃▄▅▆▇█")
+
+// sparkline draws one bar per value, scaled to the largest value.
+func sparkline(values []int) string {
+	peak := 0
+	for _, v := range values {
+		peak = max(peak, v)
+	}
+	bars := make([]rune, len(values))
+	for i, v := range values {
+		level := 0
+		if peak > 0 {
+			level = v * (len(sparkBars) - 1) / peak
+		}
+		bars[i] = sparkBars[level]
+	}
+	return string(bars)
+}
+
+func last(values []int) int {
+	if len(values) == 0 {
+		return 0
+	}
+	return values[len(values)-1]
+}
@@ -0,0 +1,35 @@
+package tui
+
+import (
+	"context"
+	"time"
+
+	"github.com/bunchhieng/rl/internal/storage"
+	"github.com/bunchhieng/rl/internal/streak"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type streakMsg struct {
+	summary streak.Summary
+	err     error
+}
+
+// loadStreak summarizes the read log for the header.
+func loadStreak(s storage.Storage, goal int) tea.Cmd {
+	return func() tea.Msg {
+		events, err := s.ReadLog(context.Background())
+		if err != nil {
+			return streakMsg{err: err}
+		}
+		return streakMsg{summary: streak.Compute(events, goal, time.Now().In(displayLocation))}
+	}
+}
+
+// handleStreakMsg keeps the last summary if the read log can't be loaded;
+// the header nudge isn't worth an error screen.
+func (m appModel) handleStreakMsg(msg streakMsg) (tea.Model, tea.Cmd) {
+	if msg.err == nil {
+		m.streak = &msg.summary
+	}
+	return m, nil
+}
@@ -0,0 +1,133 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/bunchhieng/rl/internal/model"
+	"github.com/bunchhieng/rl/internal/storage"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// tagPicker lists the tags of the links the read filter shows, counted by
+// storage. The first row clears the tag filter.
+type tagPicker struct {
+	tags     []storage.Count
+	selected int
+}
+
+type tagCountsMsg struct {
+	tags []storage.Count
+	err  error
+}
+
+// hasTag reports whether link carries tag, ignoring case.
+func hasTag(link *model.Link, tag string) bool {
+	for _, t := range link.TagList() {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// newTagPicker lists tags with the named tag highlighted, or the row
+// clearing the filter if name is empty.
+func newTagPicker(tags []storage.Count, name string) *tagPicker {
+	picker := &tagPicker{tags: tags}
+	for i, tag := range picker.tags {
+		if strings.EqualFold(tag.Name, name) {
+			picker.selected = i + 1
+		}
+	}
+	return picker
+}
+
+// selectedTag returns the highlighted tag, or "" for the row clearing the
+// filter.
+func (p *tagPicker) selectedTag() string {
+	if p.selected == 0 {
+		return ""
+	}
+	return p.tags[p.selected-1].Name
+}
+
+// loadTagCounts counts the tags of the links the read filter shows,
+// loaded or not.
+func (m appModel) loadTagCounts() tea.Cmd {
+	s, opts := m.storage, m.listOptions()
+	return func() tea.Msg {
+		tags, err := s.TagCounts(context.Background(), opts)
+		return tagCountsMsg{tags: tags, err: err}
+	}
+}
+
+func (m appModel) handleTagCounts(msg tagCountsMsg) (tea.Model, tea.Cmd) {
+	if msg.err != nil {
+		return m.handleError(errorMsg{msg.err})
+	}
+	// Start on the active tag so enter keeps it
+	m.tagPicker = newTagPicker(msg.tags, m.tagFilter)
+	return m, nil
+}
+
+func (m appModel) handleTagPickerInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	picker := *m.tagPicker
+	m.tagPicker = &picker
+
+	if msg.Type == tea.KeyCtrlC {
+		return m, tea.Quit
+	}
+	switch m.keys.viewAction(msg.String(), actionTags) {
+	case actionBack:
+		m.tagPicker = nil
+	case actionDown:
+		if picker.selected < len(picker.tags) {
+			picker.selected++
+		}
+	case actionUp:
+		if picker.selected > 0 {
+			picker.selected--
+		}
+	case actionTop:
+		picker.selected = 0
+	case actionBottom:
+		picker.selected = len(picker.tags)
+	case actionOpen:
+		m.tagFilter = picker.selectedTag()
+		m.tagPicker = nil
+		m.visual = false
+		m.selected = 0
+		m.applyFilters()
+	}
+	return m, nil
+}
+
+func (m appModel) renderTagPicker() string {
+	picker := m.tagPicker
+	var b strings.Builder
+	b.WriteString("Filter by tag\n\n")
+
+	rows := make([]string, 0, len(picker.tags)+1)
+	rows = append(rows, fmt.Sprintf("All tags (%d)", max(m.total, len(m.links))))
+	for _, tag := range picker.tags {
+		rows = append(rows, fmt.Sprintf("%s (%d)", tagStyle.Render(tag.Name), tag.Count))
+	}
+
+	// Keep the selected row visible below the title lines
+	height := max(m.listHeight()-3, 1)
+	start := max(picker.selected-height+1, 0)
+	end := min(len(rows), start+height)
+	for i := start; i < end; i++ {
+		if i == picker.selected {
+			b.WriteString(selectedStyle.Render("> " + rows[i]))
+		} else {
+			b.WriteString("  " + rows[i])
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+	b.WriteString(readStyle.Render(hints(m.keys.hint("move", actionDown, actionUp), m.keys.hint("filter", actionOpen), "[esc] cancel")))
+	return b.String()
+}
@@ -0,0 +1,72 @@
+package tui
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bunchhieng/rl/internal/model"
+	"github.com/bunchhieng/rl/internal/storage"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestTagPicker(t *testing.T) {
+	s, err := storage.NewSQLiteStorage(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer s.Close()
+	ctx := context.Background()
+	links := []*model.Link{
+		{URL: "https://example.com/1", Tags: "go,rust"},
+		{URL: "https://example.com/2", Tags: "Go, web"},
+		{URL: "https://example.com/3"},
+		{URL: "https://example.com/4", Tags: "web,go"},
+		{URL: "https://example.com/5", Tags: "go,archive"},
+	}
+	for _, link := range links {
+		if _, err := s.Add(ctx, link); err != nil {
+			t.Fatalf("Add failed: %v", err)
+		}
+	}
+	if err := s.MarkRead(ctx, links[4].ID); err != nil {
+		t.Fatal(err)
+	}
+
+	m := initialModel(s)
+	next, _ := m.update(loadLinks(s, m.listOptions(), 0)())
+	m = next.(appModel)
+
+	// The picker counts the tags of the unread links, most used first
+	next, cmd := m.update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("t")})
+	next, _ = next.(appModel).update(cmd())
+	m = next.(appModel)
+	want := []storage.Count{{Name: "go", Count: 3}, {Name: "web", Count: 2}, {Name: "rust", Count: 1}}
+	if m.tagPicker == nil || len(m.tagPicker.tags) != len(want) {
+		t.Fatalf("Expected the picker with %v, got %+v", want, m.tagPicker)
+	}
+	for i := range want {
+		if m.tagPicker.tags[i] != want[i] {
+			t.Errorf("tag %d = %v, want %v", i, m.tagPicker.tags[i], want[i])
+		}
+	}
+
+	// Picking web filters the list
+	next, _ = m.update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("j")})
+	next, _ = next.(appModel).update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("j")})
+	next, _ = next.(appModel).update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = next.(appModel)
+	if m.tagPicker != nil || m.tagFilter != "web" || len(m.filtered) != 2 {
+		t.Errorf("Expected the two web links, got %d links tagged %q", len(m.filtered), m.tagFilter)
+	}
+
+	// Reopened, it starts on the active tag
+	next, cmd = m.update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("t")})
+	next, _ = next.(appModel).update(cmd())
+	if picker := next.(appModel).tagPicker; picker == nil || picker.selectedTag() != "web" {
+		t.Errorf("Expected web highlighted, got %+v", picker)
+	}
+
+	if !hasTag(links[1], "GO") || hasTag(links[0], "g") {
+		t.Error("hasTag should match whole tags case-insensitively")
+	}
+}
@@ -0,0 +1,138 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mattn/go-runewidth"
+)
+
+// Slow operations (changing links, deleting them, adding with a page
+// fetch, reloading) run as tasks: off the update loop, with a spinner and
+// a label in the status bar until they finish. Failures are collected in
+// an error panel above the status bar, which esc dismisses, so the list
+// stays usable.
+
+const (
+	// spinnerInterval is how often the spinner advances.
+	spinnerInterval = 100 * time.Millisecond
+	// maxErrors is how many errors the panel keeps, newest last.
+	maxErrors = 5
+)
+
+var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+// task is an operation in progress.
+type task struct {
+	id    int
+	label string
+}
+
+// taskDoneMsg carries the result of a task, handled as if msg had been
+// sent directly, and whether the list should be reloaded.
+type taskDoneMsg struct {
+	id     int
+	msg    tea.Msg
+	reload bool
+}
+
+type spinnerTickMsg struct{}
+
+// errorMsg reports a failed operation in the error panel.
+type errorMsg struct {
+	err error
+}
+
+// errorf returns an errorMsg with a formatted message.
+func errorf(format string, args ...any) errorMsg {
+	return errorMsg{fmt.Errorf(format, args...)}
+}
+
+// startTask runs run in the background, showing label with a spinner
+// until it finishes. With reload, the list is reloaded afterwards.
+func (m *appModel) startTask(label string, reload bool, run func() tea.Msg) tea.Cmd {
+	m.taskSeq++
+	id := m.taskSeq
+	m.tasks = append(m.tasks, task{id: id, label: label})
+	cmd := func() tea.Msg {
+		return taskDoneMsg{id: id, msg: run(), reload: reload}
+	}
+	if len(m.tasks) == 1 {
+		return tea.Batch(cmd, spinnerTick())
+	}
+	return cmd
+}
+
+func spinnerTick() tea.Cmd {
+	return tea.Tick(spinnerInterval, func(time.Time) tea.Msg {
+		return spinnerTickMsg{}
+	})
+}
+
+func (m appModel) handleSpinnerTick() (tea.Model, tea.Cmd) {
+	if len(m.tasks) == 0 {
+		return m, nil
+	}
+	m.spinnerFrame = (m.spinnerFrame + 1) % len(spinnerFrames)
+	return m, spinnerTick()
+}
+
+func (m appModel) handleTaskDone(msg taskDoneMsg) (tea.Model, tea.Cmd) {
+	for i, t := range m.tasks {
+		if t.id == msg.id {
+			m.tasks = append(m.tasks[:i:i], m.tasks[i+1:]...)
+			break
+		}
+	}
+	var cmds []tea.Cmd
+	if msg.reload {
+		cmds = append(cmds, m.reload())
+	}
+	if msg.msg == nil {
+		return m, tea.Batch(cmds...)
+	}
+	next, cmd := m.update(msg.msg)
+	return next, tea.Batch(append(cmds, cmd)...)
+}
+
+// taskStatus describes the newest task in progress for the status bar,
+// e.g. "⠙ Deleting 3 links…", or "" when nothing is running.
+func (m appModel) taskStatus() string {
+	if len(m.tasks) == 0 {
+		return ""
+	}
+	status := fmt.Sprintf("%s %s…", spinnerFrames[m.spinnerFrame], m.tasks[len(m.tasks)-1].label)
+	if len(m.tasks) > 1 {
+		status += fmt.Sprintf(" (+%d)", len(m.tasks)-1)
+	}
+	return status
+}
+
+func (m appModel) handleError(msg errorMsg) (tea.Model, tea.Cmd) {
+	m.errors = append(m.errors, msg.err.Error())
+	if len(m.errors) > maxErrors {
+		m.errors = m.errors[len(m.errors)-maxErrors:]
+	}
+	return m, nil
+}
+
+// errorPanelHeight is how many lines the error panel takes: its errors,
+// the hint, and the border.
+func (m appModel) errorPanelHeight() int {
+	if len(m.errors) == 0 {
+		return 0
+	}
+	return len(m.errors) + 3
+}
+
+func (m appModel) renderErrorPanel() string {
+	width := max(m.width-4, 10) // inside the border and padding
+	lines := make([]string, 0, len(m.errors)+1)
+	for _, err := range m.errors {
+		lines = append(lines, highPriorityStyle.Render(runewidth.Truncate("Error: "+strings.Join(strings.Fields(err), " "), width, "...")))
+	}
+	lines = append(lines, readStyle.Render("[esc] dismiss"))
+	return errorPanelStyle.Width(m.width - 2).Render(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,96 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/bunchhieng/rl/internal/model"
+	"github.com/bunchhieng/rl/internal/storage"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// taskResult runs the task started with the message msg came from,
+// returning its taskDoneMsg. Starting the first task batches it with the
+// spinner, which isn't run.
+func taskResult(t *testing.T, msg tea.Msg) taskDoneMsg {
+	t.Helper()
+	if batch, ok := msg.(tea.BatchMsg); ok {
+		msg = batch[0]()
+	}
+	done, ok := msg.(taskDoneMsg)
+	if !ok {
+		t.Fatalf("Expected a task, got %#v", msg)
+	}
+	return done
+}
+
+func TestTasks(t *testing.T) {
+	s, err := storage.NewSQLiteStorage(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer s.Close()
+	ctx := context.Background()
+	for _, url := range []string{"https://example.com/a", "https://example.com/b"} {
+		if _, err := s.Add(ctx, &model.Link{URL: url}); err != nil {
+			t.Fatalf("Add failed: %v", err)
+		}
+	}
+	m := initialModel(s)
+	next, _ := m.update(loadLinks(s, m.listOptions(), 0)())
+	m = next.(appModel)
+
+	// Deleting runs in the background, with a spinner until it's done
+	next, _ = m.update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("r")})
+	next, cmd := next.(appModel).update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+	m = *next.(*appModel)
+	if len(m.tasks) != 1 || !strings.HasPrefix(m.taskStatus(), spinnerFrames[0]+" Moving link to the trash") {
+		t.Fatalf("Expected the delete shown in progress, got %q", m.taskStatus())
+	}
+	next, _ = m.update(spinnerTickMsg{})
+	m = next.(appModel)
+	if !strings.HasPrefix(m.taskStatus(), spinnerFrames[1]) {
+		t.Errorf("Expected the spinner to advance, got %q", m.taskStatus())
+	}
+	if !strings.Contains(m.renderStatusBar(), "Moving link to the trash…") {
+		t.Errorf("Expected the status bar to show the task, got %q", m.renderStatusBar())
+	}
+
+	done := taskResult(t, cmd())
+	if !done.reload {
+		t.Error("Expected the list to be reloaded after deleting")
+	}
+	next, _ = m.update(done)
+	m = next.(appModel)
+	if len(m.tasks) != 0 || m.taskStatus() != "" || m.statusMsg != "Moved link to the trash" {
+		t.Errorf("Expected the delete finished, got %d tasks and status %q", len(m.tasks), m.statusMsg)
+	}
+	if _, cmd := m.update(spinnerTickMsg{}); cmd != nil {
+		t.Error("Expected the spinner to stop with no tasks running")
+	}
+
+	// Errors go to a panel above the status bar, and esc dismisses it
+	height := m.listHeight()
+	next, _ = m.update(errorMsg{errors.New("database is locked")})
+	m = next.(appModel)
+	if !strings.Contains(m.View(), "Error: database is locked") || !strings.Contains(m.View(), "example.com") {
+		t.Errorf("Expected the error shown below the list, got:\n%s", m.View())
+	}
+	if m.listHeight() != height-m.errorPanelHeight() {
+		t.Errorf("Expected the list to make room for the panel, got %d lines", m.listHeight())
+	}
+	for range maxErrors + 1 {
+		next, _ = m.update(errorMsg{errors.New("again")})
+		m = next.(appModel)
+	}
+	if len(m.errors) != maxErrors {
+		t.Errorf("Expected only the last %d errors kept, got %d", maxErrors, len(m.errors))
+	}
+	next, _ = m.update(tea.KeyMsg{Type: tea.KeyEsc})
+	m = next.(appModel)
+	if len(m.errors) != 0 || strings.Contains(m.View(), "Error:") {
+		t.Error("Expected esc to dismiss the error panel")
+	}
+}
@@ -0,0 +1,189 @@
+package tui
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// palette assigns a color to each role the TUI styles are built from.
+// Colors are ANSI 256 numbers or "#rrggbb" hex values.
+type palette struct {
+	accent      string // header, help titles, selection background
+	accentText  string // text on the accent color
+	surface     string // status bar, search bar, and focused field background
+	surfaceText string // text on the surface color
+	muted       string // read links, labels, hints
+	unread      string
+	url         string
+	tag         string
+	urgent      string // high priority marker
+}
+
+// themes are the built-in palettes selectable with `theme` in the config.
+var themes = map[string]palette{
+	"dark": {
+		accent: "62", accentText: "230", surface: "236", surfaceText: "230",
+		muted: "241", unread: "39", url: "33", tag: "220", urgent: "196",
+	},
+	"light": {
+		accent: "25", accentText: "231", surface: "254", surfaceText: "235",
+		muted: "244", unread: "26", url: "31", tag: "130", urgent: "160",
+	},
+	"solarized": {
+		accent: "#6c71c4", accentText: "#fdf6e3", surface: "#073642", surfaceText: "#93a1a1",
+		muted: "#586e75", unread: "#268bd2", url: "#2aa198", tag: "#b58900", urgent: "#dc322f",
+	},
+}
+
+// defaultTheme keeps the colors rl has always used.
+const defaultTheme = "dark"
+
+var hexColorPattern = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// newPalette returns the named theme with colors overridden by role name
+// ("accent", "url", ...). Override values must be hex colors.
+func newPalette(name string, colors map[string]string) (palette, error) {
+	if name == "" {
+		name = defaultTheme
+	}
+	p, ok := themes[name]
+	if !ok {
+		return palette{}, fmt.Errorf("unknown theme %q (use dark, light, or solarized)", name)
+	}
+
+	roles := map[string]*string{
+		"accent":       &p.accent,
+		"accent_text":  &p.accentText,
+		"surface":      &p.surface,
+		"surface_text": &p.surfaceText,
+		"muted":        &p.muted,
+		"unread":       &p.unread,
+		"url":          &p.url,
+		"tag":          &p.tag,
+		"urgent":       &p.urgent,
+	}
+	names := make([]string, 0, len(colors))
+	for role := range colors {
+		names = append(names, role)
+	}
+	sort.Strings(names)
+	for _, role := range names {
+		target, ok := roles[role]
+		if !ok {
+			return palette{}, fmt.Errorf("unknown color %q", role)
+		}
+		value := strings.TrimSpace(colors[role])
+		if !hexColorPattern.MatchString(value) {
+			return palette{}, fmt.Errorf("color %s must be a hex value like #5f5fd7, got %q", role, colors[role])
+		}
+		*target = value
+	}
+	return p, nil
+}
+
+var (
+	headerStyle       lipgloss.Style
+	statusBarStyle    lipgloss.Style
+	selectedStyle     lipgloss.Style
+	unreadStyle       lipgloss.Style
+	readStyle         lipgloss.Style
+	urlStyle          lipgloss.Style
+	tagStyle          lipgloss.Style
+	searchStyle       lipgloss.Style
+	highPriorityStyle lipgloss.Style
+	filterStyle       lipgloss.Style
+	helpTitleStyle    lipgloss.Style
+	helpKeyStyle      lipgloss.Style
+	editLabelStyle    lipgloss.Style
+	editFocusStyle    lipgloss.Style
+	detailLabelStyle  lipgloss.Style
+	sectionStyle      lipgloss.Style
+	errorPanelStyle   lipgloss.Style
+)
+
+func init() {
+	applyPalette(themes[defaultTheme])
+}
+
+// applyPalette rebuilds every style from p.
+func applyPalette(p palette) {
+	accent := lipgloss.Color(p.accent)
+	accentText := lipgloss.Color(p.accentText)
+	surface := lipgloss.Color(p.surface)
+	surfaceText := lipgloss.Color(p.surfaceText)
+	muted := lipgloss.Color(p.muted)
+
+	headerStyle = lipgloss.NewStyle().
+		Bold(true).
+		Foreground(accent).
+		Padding(0, 1)
+
+	statusBarStyle = lipgloss.NewStyle().
+		Foreground(muted).
+		Background(surface).
+		Padding(0, 1)
+
+	selectedStyle = lipgloss.NewStyle().
+		Background(accent).
+		Foreground(accentText).
+		Padding(0, 1)
+
+	unreadStyle = lipgloss.NewStyle().
+		Foreground(lipgloss.Color(p.unread)).
+		Bold(true)
+
+	readStyle = lipgloss.NewStyle().
+		Foreground(muted)
+
+	urlStyle = lipgloss.NewStyle().
+		Foreground(lipgloss.Color(p.url))
+
+	tagStyle = lipgloss.NewStyle().
+		Foreground(lipgloss.Color(p.tag))
+
+	searchStyle = lipgloss.NewStyle().
+		Background(surface).
+		Foreground(surfaceText).
+		Padding(0, 1)
+
+	highPriorityStyle = lipgloss.NewStyle().
+		Foreground(lipgloss.Color(p.urgent)).
+		Bold(true)
+
+	filterStyle = lipgloss.NewStyle().
+		Foreground(muted).
+		Padding(0, 1)
+
+	helpTitleStyle = lipgloss.NewStyle().
+		Bold(true).
+		Foreground(accent)
+
+	helpKeyStyle = lipgloss.NewStyle().
+		Foreground(lipgloss.Color(p.tag))
+
+	editLabelStyle = lipgloss.NewStyle().
+		Foreground(muted).
+		Width(7)
+
+	editFocusStyle = lipgloss.NewStyle().
+		Foreground(surfaceText).
+		Background(surface)
+
+	detailLabelStyle = lipgloss.NewStyle().
+		Foreground(muted).
+		Width(10)
+
+	sectionStyle = lipgloss.NewStyle().
+		Bold(true).
+		Foreground(accent).
+		PaddingLeft(1)
+
+	errorPanelStyle = lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color(p.urgent)).
+		Padding(0, 1)
+}
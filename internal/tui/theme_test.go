@@ -0,0 +1,31 @@
+package tui
+
+import "testing"
+
+func TestNewPalette(t *testing.T) {
+	p, err := newPalette("", nil)
+	if err != nil || p != themes[defaultTheme] {
+		t.Errorf("Expected the default theme, got %+v, %v", p, err)
+	}
+
+	p, err = newPalette("light", map[string]string{"accent": "#5f5fd7"})
+	if err != nil {
+		t.Fatalf("newPalette error: %v", err)
+	}
+	if p.accent != "#5f5fd7" || p.url != themes["light"].url {
+		t.Errorf("Expected accent override on the light theme, got %+v", p)
+	}
+
+	for _, tt := range []struct {
+		theme  string
+		colors map[string]string
+	}{
+		{"neon", nil},
+		{"dark", map[string]string{"background": "#000000"}},
+		{"dark", map[string]string{"accent": "blue"}},
+	} {
+		if _, err := newPalette(tt.theme, tt.colors); err == nil {
+			t.Errorf("newPalette(%q, %v): expected error", tt.theme, tt.colors)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package tui
+
+// Visual mode marks the contiguous rows between an anchor and the cursor,
+// like vim's linewise visual mode. Actions apply to the marked rows along
+// with any space-selected links.
+
+func (m *appModel) startVisual() {
+	if len(m.filtered) == 0 {
+		return
+	}
+	m.visual = true
+	m.visualAnchor = m.selected
+}
+
+// commitVisual adds the marked rows to the selection and leaves visual mode.
+func (m *appModel) commitVisual() {
+	for i, link := range m.filtered {
+		if m.inVisualRange(i) {
+			m.selectedIDs[link.ID] = true
+		}
+	}
+	m.visual = false
+}
+
+// inVisualRange reports whether row i lies between the anchor and the cursor.
+func (m appModel) inVisualRange(i int) bool {
+	if !m.visual {
+		return false
+	}
+	anchor := min(m.visualAnchor, len(m.filtered)-1)
+	return i >= min(anchor, m.selected) && i <= max(anchor, m.selected)
+}
+
+// isMarked reports whether row i is selected or in the visual range.
+func (m appModel) isMarked(i int) bool {
+	return m.selectedIDs[m.filtered[i].ID] || m.inVisualRange(i)
+}
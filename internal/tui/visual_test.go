@@ -0,0 +1,35 @@
+package tui
+
+import (
+	"testing"
+
+	"github.com/bunchhieng/rl/internal/model"
+)
+
+func TestVisualMode(t *testing.T) {
+	m := initialModel(nil)
+	m.selectedIDs = map[string]bool{}
+	for _, id := range []string{"a", "b", "c", "d", "e"} {
+		m.filtered = append(m.filtered, &model.Link{ID: id})
+	}
+
+	m.selected = 3
+	m.startVisual()
+	m.selected = 1
+	if got := len(m.getSelectedLinks()); got != 3 {
+		t.Fatalf("Visual range from row 3 to 1 marked %d links, want 3", got)
+	}
+
+	m.commitVisual()
+	if m.visual {
+		t.Error("commitVisual should leave visual mode")
+	}
+	for _, id := range []string{"b", "c", "d"} {
+		if !m.selectedIDs[id] {
+			t.Errorf("Expected %s to be selected after commit", id)
+		}
+	}
+	if m.selectedIDs["a"] || m.selectedIDs["e"] {
+		t.Error("Rows outside the range should not be selected")
+	}
+}
@@ -0,0 +1,65 @@
+package tui
+
+import (
+	"os"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// watchInterval is how often the database file is checked for changes
+// made by other processes.
+const watchInterval = 2 * time.Second
+
+// dbStamp identifies a state of the database files on disk. In WAL mode
+// other processes' writes land in the -wal file, so it is included.
+type dbStamp struct {
+	modTime    time.Time
+	size       int64
+	walModTime time.Time
+	walSize    int64
+}
+
+type dbChangedMsg struct {
+	stamp dbStamp
+}
+
+func statDB(path string) dbStamp {
+	var stamp dbStamp
+	if info, err := os.Stat(path); err == nil {
+		stamp.modTime, stamp.size = info.ModTime(), info.Size()
+	}
+	if info, err := os.Stat(path + "-wal"); err == nil {
+		stamp.walModTime, stamp.walSize = info.ModTime(), info.Size()
+	}
+	return stamp
+}
+
+// watchDB polls the database at path and reports its stamp after each
+// interval.
+func watchDB(path string) tea.Cmd {
+	return tea.Tick(watchInterval, func(time.Time) tea.Msg {
+		return dbChangedMsg{stamp: statDB(path)}
+	})
+}
+
+// handleDBChanged reloads the list when the database changed since the
+// last check, keeping the highlighted link selected.
+func (m appModel) handleDBChanged(msg dbChangedMsg) (tea.Model, tea.Cmd) {
+	next := watchDB(m.dbPath)
+	if msg.stamp == m.dbStamp {
+		return m, next
+	}
+	m.dbStamp = msg.stamp
+
+	follow := ""
+	if m.selected < len(m.filtered) {
+		follow = m.filtered[m.selected].ID
+	}
+	reload := m.reload()
+	return m, tea.Batch(next, func() tea.Msg {
+		msg := reload().(loadLinksMsg)
+		msg.follow = follow
+		return msg
+	})
+}
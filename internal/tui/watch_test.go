@@ -0,0 +1,70 @@
+package tui
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bunchhieng/rl/internal/model"
+	"github.com/bunchhieng/rl/internal/storage"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestReloadOnDBChange(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rl.db")
+	s, err := storage.NewSQLiteStorage(path)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer s.Close()
+	ctx := context.Background()
+	for i, url := range []string{"https://example.com/a", "https://example.com/b"} {
+		if _, err := s.Add(ctx, &model.Link{URL: url, CreatedAt: time.Now().Add(time.Duration(i-10) * time.Hour)}); err != nil {
+			t.Fatalf("Add failed: %v", err)
+		}
+	}
+
+	m := initialModel(s)
+	m.dbPath, m.dbStamp = path, statDB(path)
+	next, _ := m.update(loadLinks(s, m.listOptions(), 0)())
+	m = next.(appModel)
+	m.selected = 1 // a, below the newer b
+	highlighted := m.filtered[1].ID
+
+	// Nothing changed on disk, nothing to reload
+	next, _ = m.handleDBChanged(dbChangedMsg{stamp: statDB(path)})
+	if next.(appModel).dbStamp != m.dbStamp {
+		t.Error("Expected an unchanged database to keep its stamp")
+	}
+
+	// Another process saves a link
+	other, err := storage.NewSQLiteStorage(path)
+	if err != nil {
+		t.Fatalf("Failed to open the database again: %v", err)
+	}
+	if _, err := other.Add(ctx, &model.Link{URL: "https://example.com/new"}); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	other.Close()
+
+	stamp := statDB(path)
+	if stamp == m.dbStamp {
+		t.Fatal("Expected the write to change the database's stamp")
+	}
+	next, cmd := m.handleDBChanged(dbChangedMsg{stamp: stamp})
+	m = next.(appModel)
+	// The batch is the next check, which waits for its interval, and the reload
+	batch, ok := cmd().(tea.BatchMsg)
+	if !ok || len(batch) != 2 {
+		t.Fatalf("Expected the next check and a reload, got %#v", cmd())
+	}
+	next, _ = m.update(batch[1]())
+	m = next.(appModel)
+	if len(m.filtered) != 3 {
+		t.Fatalf("Expected the new link listed, got %d links", len(m.filtered))
+	}
+	if m.filtered[m.selected].ID != highlighted {
+		t.Errorf("Expected %s to stay highlighted, got %s", highlighted, m.filtered[m.selected].ID)
+	}
+}
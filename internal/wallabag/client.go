@@ -0,0 +1,221 @@
+// Package wallabag syncs links with a Wallabag instance through its REST API.
+package wallabag
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	requestTimeout = 30 * time.Second
+	// pageSize is the number of entries requested per page.
+	pageSize = 100
+)
+
+// Config holds the API credentials. Wallabag issues OAuth tokens for an API
+// client (created under "API clients management") and a user login. A
+// pre-issued access Token can be given instead of the password.
+type Config struct {
+	URL          string
+	ClientID     string
+	ClientSecret string
+	Username     string
+	Password     string
+	Token        string
+}
+
+// Entry is a Wallabag entry, reduced to the fields rl maps.
+type Entry struct {
+	ID         int    `json:"id"`
+	URL        string `json:"url"`
+	Title      string `json:"title"`
+	IsArchived int    `json:"is_archived"`
+	Tags       []Tag  `json:"tags"`
+}
+
+// Tag is a Wallabag entry tag.
+type Tag struct {
+	Label string `json:"label"`
+}
+
+// Archived reports whether the entry has been read.
+func (e *Entry) Archived() bool {
+	return e.IsArchived != 0
+}
+
+// TagList returns the entry's tag labels.
+func (e *Entry) TagList() []string {
+	labels := make([]string, len(e.Tags))
+	for i, tag := range e.Tags {
+		labels[i] = tag.Label
+	}
+	return labels
+}
+
+// Client talks to one Wallabag instance.
+type Client struct {
+	cfg   Config
+	base  string
+	http  *http.Client
+	token string
+}
+
+// NewClient returns a client for cfg. Credentials are checked on the first
+// request.
+func NewClient(cfg Config) (*Client, error) {
+	u, err := url.Parse(cfg.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("wallabag url must be an http or https URL, got %q", cfg.URL)
+	}
+	if cfg.Token == "" && (cfg.ClientID == "" || cfg.ClientSecret == "" || cfg.Username == "" || cfg.Password == "") {
+		return nil, fmt.Errorf("wallabag needs client_id, client_secret, username, and password (or token) in the config")
+	}
+	return &Client{
+		cfg:   cfg,
+		base:  strings.TrimRight(cfg.URL, "/"),
+		http:  &http.Client{Timeout: requestTimeout},
+		token: cfg.Token,
+	}, nil
+}
+
+// authenticate exchanges the login for an access token.
+func (c *Client) authenticate(ctx context.Context) error {
+	if c.token != "" {
+		return nil
+	}
+	form := url.Values{
+		"grant_type":    {"password"},
+		"client_id":     {c.cfg.ClientID},
+		"client_secret": {c.cfg.ClientSecret},
+		"username":      {c.cfg.Username},
+		"password":      {c.cfg.Password},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.base+"/oauth/v2/token", strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	var token struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := c.send(req, &token); err != nil {
+		return fmt.Errorf("wallabag login: %w", err)
+	}
+	if token.AccessToken == "" {
+		return fmt.Errorf("wallabag login: no access token in response")
+	}
+	c.token = token.AccessToken
+	return nil
+}
+
+// do sends an authenticated API request with an optional JSON body and
+// decodes the JSON response into out.
+func (c *Client) do(ctx context.Context, method, path string, body, out any) error {
+	if err := c.authenticate(ctx); err != nil {
+		return err
+	}
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.base+path, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	if err := c.send(req, out); err != nil {
+		return fmt.Errorf("wallabag %s %s: %w", method, path, err)
+	}
+	return nil
+}
+
+func (c *Client) send(req *http.Request, out any) error {
+	req.Header.Set("Accept", "application/json")
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("%s", resp.Status)
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+	return nil
+}
+
+// Entries returns every entry, read or not.
+func (c *Client) Entries(ctx context.Context) ([]Entry, error) {
+	var entries []Entry
+	for page := 1; ; page++ {
+		query := url.Values{
+			"page":    {strconv.Itoa(page)},
+			"perPage": {strconv.Itoa(pageSize)},
+			"detail":  {"metadata"},
+		}
+		var resp struct {
+			Page     int `json:"page"`
+			Pages    int `json:"pages"`
+			Embedded struct {
+				Items []Entry `json:"items"`
+			} `json:"_embedded"`
+		}
+		if err := c.do(ctx, http.MethodGet, "/api/entries.json?"+query.Encode(), nil, &resp); err != nil {
+			return nil, err
+		}
+		entries = append(entries, resp.Embedded.Items...)
+		if page >= resp.Pages || len(resp.Embedded.Items) == 0 {
+			return entries, nil
+		}
+	}
+}
+
+// Create saves a URL. Wallabag returns the existing entry if the URL is
+// already saved.
+func (c *Client) Create(ctx context.Context, rawURL, title string, tags []string, archived bool) (*Entry, error) {
+	body := map[string]any{
+		"url":     rawURL,
+		"tags":    strings.Join(tags, ","),
+		"archive": boolInt(archived),
+	}
+	if title != "" {
+		body["title"] = title
+	}
+	var entry Entry
+	if err := c.do(ctx, http.MethodPost, "/api/entries.json", body, &entry); err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// SetArchived marks an entry read or unread.
+func (c *Client) SetArchived(ctx context.Context, id int, archived bool) error {
+	path := fmt.Sprintf("/api/entries/%d.json", id)
+	return c.do(ctx, http.MethodPatch, path, map[string]int{"archive": boolInt(archived)}, nil)
+}
+
+func boolInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
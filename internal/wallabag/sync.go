@@ -0,0 +1,169 @@
+package wallabag
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/bunchhieng/rl/internal/model"
+	"github.com/bunchhieng/rl/internal/storage"
+)
+
+// Service names Wallabag in the sync records.
+const Service = "wallabag"
+
+// Result counts what a sync changed.
+type Result struct {
+	// Pushed links were created on Wallabag.
+	Pushed int `json:"pushed"`
+	// Pulled entries were added to rl.
+	Pulled int `json:"pulled"`
+	// ReadLocal and UnreadLocal count links whose read state was taken
+	// from Wallabag.
+	ReadLocal   int `json:"read_local"`
+	UnreadLocal int `json:"unread_local"`
+	// ReadRemote and UnreadRemote count entries updated from rl.
+	ReadRemote   int `json:"read_remote"`
+	UnreadRemote int `json:"unread_remote"`
+}
+
+// Sync mirrors links in both directions: rl links missing from Wallabag are
+// pushed, Wallabag entries missing from rl are pulled, and a read state
+// changed on one side since the last sync is applied to the other.
+// Deletions are not mirrored; a link deleted from rl stays deleted.
+func Sync(ctx context.Context, s storage.Storage, c *Client) (*Result, error) {
+	records, err := s.SyncRecords(ctx, Service)
+	if err != nil {
+		return nil, err
+	}
+	byRemote := make(map[string]storage.SyncRecord, len(records))
+	synced := make(map[string]bool, len(records))
+	for _, rec := range records {
+		byRemote[rec.RemoteID] = rec
+		synced[rec.LinkID] = true
+	}
+
+	links, err := s.Export(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list links: %w", err)
+	}
+	byID := make(map[string]*model.Link, len(links))
+	for _, link := range links {
+		byID[link.ID] = link
+	}
+
+	entries, err := c.Entries(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{}
+	for i := range entries {
+		entry := &entries[i]
+		remoteID := strconv.Itoa(entry.ID)
+		rec, ok := byRemote[remoteID]
+		if !ok {
+			link, err := pull(ctx, s, c, entry, result)
+			if err != nil {
+				return result, err
+			}
+			synced[link.ID] = true
+			continue
+		}
+		link := byID[rec.LinkID]
+		if link == nil {
+			// Deleted locally since the last sync
+			continue
+		}
+		read, err := reconcile(ctx, s, c, link, entry, rec.Read, result)
+		if err != nil {
+			return result, err
+		}
+		if read != rec.Read {
+			rec.Read = read
+			if err := s.SetSyncRecord(ctx, rec); err != nil {
+				return result, err
+			}
+		}
+	}
+
+	for _, link := range links {
+		if synced[link.ID] {
+			continue
+		}
+		entry, err := c.Create(ctx, link.URL, link.Title, link.TagList(), link.IsRead())
+		if err != nil {
+			return result, err
+		}
+		result.Pushed++
+		rec := storage.SyncRecord{Service: Service, LinkID: link.ID, RemoteID: strconv.Itoa(entry.ID), Read: link.IsRead()}
+		if err := s.SetSyncRecord(ctx, rec); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// pull ties an entry not seen before to the rl link with the same URL,
+// adding the link if there is none. A link read on either side ends up read
+// on both.
+func pull(ctx context.Context, s storage.Storage, c *Client, entry *Entry, result *Result) (*model.Link, error) {
+	link, err := s.FindByURL(ctx, entry.URL)
+	if err == model.ErrNotFound {
+		link, err = s.Add(ctx, &model.Link{
+			URL:   entry.URL,
+			Title: entry.Title,
+			Tags:  strings.Join(entry.TagList(), ","),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("add %s: %w", entry.URL, err)
+		}
+		result.Pulled++
+	} else if err != nil {
+		return nil, err
+	}
+
+	read := link.IsRead() || entry.Archived()
+	if _, err := reconcile(ctx, s, c, link, entry, !read, result); err != nil {
+		return nil, err
+	}
+	rec := storage.SyncRecord{Service: Service, LinkID: link.ID, RemoteID: strconv.Itoa(entry.ID), Read: read}
+	if err := s.SetSyncRecord(ctx, rec); err != nil {
+		return nil, err
+	}
+	return link, nil
+}
+
+// reconcile brings the read states of link and entry together and returns
+// the agreed state. last is the state both had at the previous sync: the
+// side that differs from it changed and wins.
+func reconcile(ctx context.Context, s storage.Storage, c *Client, link *model.Link, entry *Entry, last bool, result *Result) (bool, error) {
+	local, remote := link.IsRead(), entry.Archived()
+	if local == remote {
+		return local, nil
+	}
+	if local != last {
+		if err := c.SetArchived(ctx, entry.ID, local); err != nil {
+			return last, err
+		}
+		if local {
+			result.ReadRemote++
+		} else {
+			result.UnreadRemote++
+		}
+		return local, nil
+	}
+	if remote {
+		if err := s.MarkRead(ctx, link.ID); err != nil {
+			return last, err
+		}
+		result.ReadLocal++
+	} else {
+		if err := s.MarkUnread(ctx, link.ID); err != nil {
+			return last, err
+		}
+		result.UnreadLocal++
+	}
+	return remote, nil
+}
@@ -0,0 +1,201 @@
+package wallabag
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/bunchhieng/rl/internal/model"
+	"github.com/bunchhieng/rl/internal/storage"
+	"github.com/bunchhieng/rl/internal/webhook"
+)
+
+// fakeWallabag serves the subset of the Wallabag API the client uses.
+type fakeWallabag struct {
+	entries []*Entry
+}
+
+func (f *fakeWallabag) entry(id int) *Entry {
+	for _, e := range f.entries {
+		if e.ID == id {
+			return e
+		}
+	}
+	return nil
+}
+
+func (f *fakeWallabag) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/oauth/v2/token" {
+		if r.FormValue("password") != "secret" {
+			http.Error(w, "bad login", http.StatusBadRequest)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"access_token": "tok"})
+		return
+	}
+	if r.Header.Get("Authorization") != "Bearer tok" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/api/entries.json":
+		items := make([]Entry, len(f.entries))
+		for i, e := range f.entries {
+			items[i] = *e
+		}
+		json.NewEncoder(w).Encode(map[string]any{
+			"page": 1, "pages": 1,
+			"_embedded": map[string]any{"items": items},
+		})
+	case r.Method == http.MethodPost && r.URL.Path == "/api/entries.json":
+		var body struct {
+			URL     string `json:"url"`
+			Title   string `json:"title"`
+			Archive int    `json:"archive"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		e := &Entry{ID: len(f.entries) + 1, URL: body.URL, Title: body.Title, IsArchived: body.Archive}
+		f.entries = append(f.entries, e)
+		json.NewEncoder(w).Encode(e)
+	case r.Method == http.MethodPatch && strings.HasPrefix(r.URL.Path, "/api/entries/"):
+		id, _ := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/api/entries/"), ".json"))
+		e := f.entry(id)
+		if e == nil {
+			http.NotFound(w, r)
+			return
+		}
+		var body struct {
+			Archive int `json:"archive"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		e.IsArchived = body.Archive
+		json.NewEncoder(w).Encode(e)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func TestSync(t *testing.T) {
+	fake := &fakeWallabag{entries: []*Entry{
+		{ID: 1, URL: "https://example.com/both"},
+		{ID: 2, URL: "https://example.com/remote", Title: "Remote", IsArchived: 1, Tags: []Tag{{Label: "go"}}},
+	}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	s, err := storage.NewSQLiteStorage(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	ctx := context.Background()
+	local, err := s.Add(ctx, &model.Link{URL: "https://example.com/local", Title: "Local"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	both, err := s.Add(ctx, &model.Link{URL: "https://example.com/both"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.MarkRead(ctx, both.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	client, err := NewClient(Config{URL: server.URL, ClientID: "id", ClientSecret: "sec", Username: "me", Password: "secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := Sync(ctx, s, client)
+	if err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	if *result != (Result{Pushed: 1, Pulled: 1, ReadLocal: 1, ReadRemote: 1}) {
+		t.Errorf("Unexpected first sync result: %+v", result)
+	}
+	if len(fake.entries) != 3 || fake.entries[2].URL != local.URL {
+		t.Errorf("Expected the local link to be pushed, got %+v", fake.entries)
+	}
+	if !fake.entry(1).Archived() {
+		t.Error("A link read in rl should be archived on Wallabag")
+	}
+	pulled, err := s.FindByURL(ctx, "https://example.com/remote")
+	if err != nil {
+		t.Fatalf("Expected the remote entry to be pulled: %v", err)
+	}
+	if !pulled.IsRead() || pulled.Title != "Remote" || pulled.Tags != "go" {
+		t.Errorf("Pulled link lost fields: %+v", pulled)
+	}
+
+	// Each side changes one link; the change wins over the old state
+	if err := s.MarkRead(ctx, local.ID); err != nil {
+		t.Fatal(err)
+	}
+	fake.entry(2).IsArchived = 0
+	result, err = Sync(ctx, s, client)
+	if err != nil {
+		t.Fatalf("Second sync failed: %v", err)
+	}
+	if *result != (Result{ReadRemote: 1, UnreadLocal: 1}) {
+		t.Errorf("Unexpected second sync result: %+v", result)
+	}
+	if pulled, _ := s.Get(ctx, pulled.ID); pulled.IsRead() {
+		t.Error("Unarchiving on Wallabag should mark the link unread")
+	}
+
+	// Local deletions stick
+	if err := s.Delete(ctx, both.ID); err != nil {
+		t.Fatal(err)
+	}
+	result, err = Sync(ctx, s, client)
+	if err != nil {
+		t.Fatalf("Third sync failed: %v", err)
+	}
+	if *result != (Result{}) {
+		t.Errorf("Expected no changes, got %+v", result)
+	}
+	if _, err := s.FindByURL(ctx, both.URL); err != model.ErrNotFound {
+		t.Errorf("A deleted link should not be pulled back, got %v", err)
+	}
+}
+
+func TestSyncWebhooks(t *testing.T) {
+	fake := &fakeWallabag{entries: []*Entry{
+		{ID: 1, URL: "https://example.com/remote", IsArchived: 1},
+	}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+	var events []string
+	hooks := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var p webhook.Payload
+		json.NewDecoder(r.Body).Decode(&p)
+		events = append(events, string(p.Event)+" "+p.Link.URL)
+	}))
+	defer hooks.Close()
+
+	base, err := storage.NewSQLiteStorage(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer base.Close()
+	s := webhook.Wrap(base, []webhook.Hook{{URL: hooks.URL}}, func(err error) { t.Errorf("Unexpected webhook error: %v", err) })
+	client, err := NewClient(Config{URL: server.URL, ClientID: "id", ClientSecret: "sec", Username: "me", Password: "secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A pulled entry is added, then read as on Wallabag; syncing again
+	// changes nothing, so sends nothing
+	for range 2 {
+		if _, err := Sync(context.Background(), s, client); err != nil {
+			t.Fatalf("Sync failed: %v", err)
+		}
+	}
+	want := "added https://example.com/remote, read https://example.com/remote"
+	if got := strings.Join(events, ", "); got != want {
+		t.Errorf("Expected events %q, got %q", want, got)
+	}
+}
@@ -0,0 +1,311 @@
+// Package webhook posts a JSON event to configured URLs when links are
+// added, read, or deleted.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/bunchhieng/rl/internal/model"
+	"github.com/bunchhieng/rl/internal/storage"
+)
+
+// Event names a change that can trigger a webhook.
+type Event string
+
+const (
+	EventAdded   Event = "added"
+	EventRead    Event = "read"
+	EventDeleted Event = "deleted"
+)
+
+// Events lists every event a webhook can subscribe to.
+var Events = []Event{EventAdded, EventRead, EventDeleted}
+
+// timeout bounds each delivery so a slow endpoint can't hang a command.
+const timeout = 5 * time.Second
+
+// Hook is a URL that receives events.
+type Hook struct {
+	URL string
+	// Events limits the hook to these events; empty means all of them.
+	Events []Event
+	// Headers are added to each request, e.g. an Authorization token.
+	Headers map[string]string
+}
+
+func (h Hook) wants(event Event) bool {
+	if len(h.Events) == 0 {
+		return true
+	}
+	for _, e := range h.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// Payload is the JSON body posted to a hook. Text is a one-line summary so
+// Slack incoming webhooks (and Discord's Slack-compatible endpoint) can
+// display events without any glue.
+type Payload struct {
+	Event Event       `json:"event"`
+	Time  time.Time   `json:"time"`
+	Link  *model.Link `json:"link"`
+	Text  string      `json:"text"`
+}
+
+func newPayload(event Event, link *model.Link) Payload {
+	text := map[Event]string{
+		EventAdded:   "Added",
+		EventRead:    "Read",
+		EventDeleted: "Deleted",
+	}[event]
+	switch {
+	case link.Title != "":
+		text += fmt.Sprintf(" %s (%s)", link.Title, link.URL)
+	case link.URL != "":
+		text += " " + link.URL
+	default:
+		text += " link " + link.ID
+	}
+	return Payload{Event: event, Time: time.Now().UTC(), Link: link, Text: text}
+}
+
+// Notifier delivers events to hooks.
+type Notifier struct {
+	hooks  []Hook
+	client *http.Client
+}
+
+// NewNotifier returns a Notifier for hooks.
+func NewNotifier(hooks []Hook) *Notifier {
+	return &Notifier{hooks: hooks, client: &http.Client{Timeout: timeout}}
+}
+
+func (n *Notifier) wants(event Event) bool {
+	for _, h := range n.hooks {
+		if h.wants(event) {
+			return true
+		}
+	}
+	return false
+}
+
+// Notify posts event to every hook subscribed to it. Each hook is tried even
+// if an earlier one fails; the failures are joined in the returned error.
+func (n *Notifier) Notify(ctx context.Context, event Event, link *model.Link) error {
+	body, err := json.Marshal(newPayload(event, link))
+	if err != nil {
+		return fmt.Errorf("encode webhook payload: %w", err)
+	}
+	var failed []string
+	for _, h := range n.hooks {
+		if !h.wants(event) {
+			continue
+		}
+		if err := n.post(ctx, h, body); err != nil {
+			failed = append(failed, err.Error())
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("webhook: %s", strings.Join(failed, "; "))
+	}
+	return nil
+}
+
+func (n *Notifier) post(ctx context.Context, h Hook, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("%s: %w", h.URL, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "rl-webhook")
+	for k, v := range h.Headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s: %w", h.URL, err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("%s: %s", h.URL, resp.Status)
+	}
+	return nil
+}
+
+// notifyingStorage notifies hooks after each successful add, import,
+// mark-read, archive, delete, or merge that changes a link's state: saving
+// a link again or marking a read link read sends nothing. Webhook failures
+// never fail the storage call; they go to onError.
+type notifyingStorage struct {
+	storage.Storage
+	notifier *Notifier
+	onError  func(error)
+}
+
+// Wrap returns s with webhook notifications for hooks. Delivery failures
+// are passed to onError, which may be nil to drop them. With no hooks it
+// returns s unchanged.
+func Wrap(s storage.Storage, hooks []Hook, onError func(error)) storage.Storage {
+	if len(hooks) == 0 {
+		return s
+	}
+	return &notifyingStorage{Storage: s, notifier: NewNotifier(hooks), onError: onError}
+}
+
+func (s *notifyingStorage) notify(ctx context.Context, event Event, link *model.Link) {
+	if err := s.notifier.Notify(ctx, event, link); err != nil && s.onError != nil {
+		s.onError(err)
+	}
+}
+
+// Add creates a link and sends an added event, unless the link was
+// already saved and only updated. Saving a trashed link again brings it
+// back to the library, so that sends one too.
+func (s *notifyingStorage) Add(ctx context.Context, link *model.Link) (*model.Link, error) {
+	if !s.notifier.wants(EventAdded) {
+		return s.Storage.Add(ctx, link)
+	}
+	existing, err := s.Storage.FindByURL(ctx, link.URL)
+	saved := err == nil && existing.TrashedAt == nil
+	added, err := s.Storage.Add(ctx, link)
+	if err != nil {
+		return nil, err
+	}
+	if !saved {
+		s.notify(ctx, EventAdded, added)
+	}
+	return added, nil
+}
+
+// Import imports links and sends an added event for each new one. A dry
+// run, or an import that failed as a whole, sends nothing.
+func (s *notifyingStorage) Import(ctx context.Context, links []*model.Link, opts storage.ImportOptions) ([]storage.ImportResult, error) {
+	results, err := s.Storage.Import(ctx, links, opts)
+	if err != nil || opts.DryRun || !s.notifier.wants(EventAdded) {
+		return results, err
+	}
+	for _, result := range results {
+		if result.Status == storage.ImportAdded {
+			s.notify(ctx, EventAdded, s.get(ctx, result.ID))
+		}
+	}
+	return results, nil
+}
+
+// MarkRead marks a link read and sends a read event, unless it was read
+// already.
+func (s *notifyingStorage) MarkRead(ctx context.Context, id string) error {
+	if !s.notifier.wants(EventRead) {
+		return s.Storage.MarkRead(ctx, id)
+	}
+	wasRead := s.get(ctx, id).IsRead()
+	if err := s.Storage.MarkRead(ctx, id); err != nil {
+		return err
+	}
+	if !wasRead {
+		s.notify(ctx, EventRead, s.get(ctx, id))
+	}
+	return nil
+}
+
+// Archive marks a link read without logging it and sends a read event,
+// unless it was read already.
+func (s *notifyingStorage) Archive(ctx context.Context, id string) error {
+	if !s.notifier.wants(EventRead) {
+		return s.Storage.Archive(ctx, id)
+	}
+	wasRead := s.get(ctx, id).IsRead()
+	if err := s.Storage.Archive(ctx, id); err != nil {
+		return err
+	}
+	if !wasRead {
+		s.notify(ctx, EventRead, s.get(ctx, id))
+	}
+	return nil
+}
+
+// Delete removes a link and sends a deleted event carrying the link as it
+// was before deletion, unless it was in the trash and sent one then.
+func (s *notifyingStorage) Delete(ctx context.Context, id string) error {
+	if !s.notifier.wants(EventDeleted) {
+		return s.Storage.Delete(ctx, id)
+	}
+	link := s.get(ctx, id)
+	if err := s.Storage.Delete(ctx, id); err != nil {
+		return err
+	}
+	if link.TrashedAt == nil {
+		s.notify(ctx, EventDeleted, link)
+	}
+	return nil
+}
+
+// Trash moves a link to the trash and sends a deleted event, since it is
+// gone from the library, unless it was in the trash already.
+func (s *notifyingStorage) Trash(ctx context.Context, id string) error {
+	if !s.notifier.wants(EventDeleted) {
+		return s.Storage.Trash(ctx, id)
+	}
+	link := s.get(ctx, id)
+	if err := s.Storage.Trash(ctx, id); err != nil {
+		return err
+	}
+	if link.TrashedAt == nil {
+		s.notify(ctx, EventDeleted, link)
+	}
+	return nil
+}
+
+// Restore takes a link out of the trash and sends an added event, since
+// it is back in the library.
+func (s *notifyingStorage) Restore(ctx context.Context, id string) error {
+	if !s.notifier.wants(EventAdded) {
+		return s.Storage.Restore(ctx, id)
+	}
+	wasTrashed := s.get(ctx, id).TrashedAt != nil
+	if err := s.Storage.Restore(ctx, id); err != nil {
+		return err
+	}
+	if wasTrashed {
+		s.notify(ctx, EventAdded, s.get(ctx, id))
+	}
+	return nil
+}
+
+// Merge merges links and sends a deleted event for each duplicate merged
+// away, carrying it as it was before the merge.
+func (s *notifyingStorage) Merge(ctx context.Context, keepID string, dupIDs []string) (*model.Link, error) {
+	if !s.notifier.wants(EventDeleted) {
+		return s.Storage.Merge(ctx, keepID, dupIDs)
+	}
+	dups := make([]*model.Link, len(dupIDs))
+	for i, id := range dupIDs {
+		dups[i] = s.get(ctx, id)
+	}
+	merged, err := s.Storage.Merge(ctx, keepID, dupIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, dup := range dups {
+		s.notify(ctx, EventDeleted, dup)
+	}
+	return merged, nil
+}
+
+// get loads a link for a payload, falling back to just its ID.
+func (s *notifyingStorage) get(ctx context.Context, id string) *model.Link {
+	link, err := s.Storage.Get(ctx, id)
+	if err != nil {
+		return &model.Link{ID: id}
+	}
+	return link
+}
@@ -0,0 +1,152 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bunchhieng/rl/internal/model"
+	"github.com/bunchhieng/rl/internal/storage"
+)
+
+func TestWrap(t *testing.T) {
+	var got []Payload
+	var auth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var p Payload
+		if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+			t.Errorf("Decode payload: %v", err)
+		}
+		got = append(got, p)
+		auth = r.Header.Get("Authorization")
+	}))
+	defer server.Close()
+
+	base, err := storage.NewSQLiteStorage(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer base.Close()
+	hooks := []Hook{{
+		URL:     server.URL,
+		Events:  []Event{EventAdded, EventDeleted},
+		Headers: map[string]string{"Authorization": "Bearer secret"},
+	}}
+	s := Wrap(base, hooks, func(err error) { t.Errorf("Unexpected webhook error: %v", err) })
+
+	ctx := context.Background()
+	link, err := s.Add(ctx, &model.Link{URL: "https://example.com/a", Title: "A"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.MarkRead(ctx, link.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Delete(ctx, link.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(got) != 2 {
+		t.Fatalf("Expected added and deleted events (read not subscribed), got %+v", got)
+	}
+	if got[0].Event != EventAdded || got[0].Link.ID != link.ID || got[0].Text != "Added A (https://example.com/a)" {
+		t.Errorf("Unexpected added payload: %+v", got[0])
+	}
+	if got[1].Event != EventDeleted || got[1].Link.URL != "https://example.com/a" || got[1].Link.ReadAt == nil {
+		t.Errorf("Deleted payload should carry the link as it was: %+v", got[1])
+	}
+	if auth != "Bearer secret" {
+		t.Errorf("Expected configured header, got %q", auth)
+	}
+}
+
+func TestWrapReportsFailures(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	base, err := storage.NewSQLiteStorage(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer base.Close()
+	var failures []error
+	s := Wrap(base, []Hook{{URL: server.URL}}, func(err error) { failures = append(failures, err) })
+
+	if _, err := s.Add(context.Background(), &model.Link{URL: "https://example.com"}); err != nil {
+		t.Fatalf("A webhook failure must not fail the add: %v", err)
+	}
+	if len(failures) != 1 || !strings.Contains(failures[0].Error(), "500") {
+		t.Errorf("Expected one 500 failure, got %v", failures)
+	}
+}
+
+func TestWrapOnlyChanges(t *testing.T) {
+	var got []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var p Payload
+		if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+			t.Errorf("Decode payload: %v", err)
+		}
+		got = append(got, string(p.Event)+" "+p.Link.URL)
+	}))
+	defer server.Close()
+
+	base, err := storage.NewSQLiteStorage(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer base.Close()
+	s := Wrap(base, []Hook{{URL: server.URL}}, func(err error) { t.Errorf("Unexpected webhook error: %v", err) })
+	ctx := context.Background()
+	expect := func(step string, want ...string) {
+		t.Helper()
+		if strings.Join(got, ", ") != strings.Join(want, ", ") {
+			t.Errorf("%s: got events %q, want %q", step, got, want)
+		}
+		got = nil
+	}
+
+	link, err := s.Add(ctx, &model.Link{URL: "https://example.com/a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Add(ctx, &model.Link{URL: "https://example.com/a", Tags: "go"}); err != nil {
+		t.Fatal(err)
+	}
+	expect("adding twice", "added https://example.com/a")
+
+	for range 2 {
+		if err := s.MarkRead(ctx, link.ID); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.Archive(ctx, link.ID); err != nil {
+		t.Fatal(err)
+	}
+	expect("reading twice and archiving", "read https://example.com/a")
+
+	// Saving a trashed link again brings it back
+	if err := s.Trash(ctx, link.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Add(ctx, &model.Link{URL: "https://example.com/a"}); err != nil {
+		t.Fatal(err)
+	}
+	expect("re-adding a trashed link", "deleted https://example.com/a", "added https://example.com/a")
+
+	// Imports send an event per new link, and none in a dry run
+	imported := []*model.Link{{URL: "https://example.com/a"}, {URL: "https://example.com/b"}}
+	if _, err := s.Import(ctx, append(imported, &model.Link{URL: "https://example.com/c"}), storage.ImportOptions{DryRun: true}); err != nil {
+		t.Fatal(err)
+	}
+	expect("a dry run")
+	if _, err := s.Import(ctx, imported, storage.ImportOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	expect("importing", "added https://example.com/b")
+}
@@ -1,24 +1,66 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"math"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"text/template"
+	"time"
 
 	"github.com/bunchhieng/rl/internal/app"
+	"github.com/bunchhieng/rl/internal/attach"
+	"github.com/bunchhieng/rl/internal/backup"
 	"github.com/bunchhieng/rl/internal/cli"
+	"github.com/bunchhieng/rl/internal/clipboard"
+	"github.com/bunchhieng/rl/internal/config"
+	"github.com/bunchhieng/rl/internal/llm"
+	"github.com/bunchhieng/rl/internal/mcp"
+	"github.com/bunchhieng/rl/internal/model"
+	"github.com/bunchhieng/rl/internal/pinboard"
+	"github.com/bunchhieng/rl/internal/rules"
 	"github.com/bunchhieng/rl/internal/storage"
+	"github.com/bunchhieng/rl/internal/tab"
 	"github.com/bunchhieng/rl/internal/tui"
+	"github.com/bunchhieng/rl/internal/wallabag"
+	"github.com/bunchhieng/rl/internal/webhook"
+	"github.com/mattn/go-isatty"
 	urfavecli "github.com/urfave/cli/v2"
 )
 
-const (
-	colorReset = "\033[0m"
-	colorRed   = "\033[31m"
+var (
+	colorReset  = "\033[0m"
+	colorRed    = "\033[31m"
+	colorYellow = "\033[33m"
 )
 
 var version = "dev"
 
 func main() {
+	// Ctrl-C cancels the command's context so work in progress stops
+	// cleanly; a second Ctrl-C kills rl outright.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+	if err := newApp().RunContext(ctx, os.Args); err != nil {
+		exit(jsonRequested(os.Args), err)
+	}
+}
+
+// newApp returns the rl command line.
+func newApp() *urfavecli.App {
 	cliApp := &urfavecli.App{
 		Name:                 "rl",
 		Usage:                "Read Later CLI - A minimal, local-first read later tool",
@@ -26,35 +68,178 @@ func main() {
 		EnableBashCompletion: true,
 		Flags: []urfavecli.Flag{
 			&urfavecli.StringFlag{
-				Name:  "db-path",
-				Usage: "path to database file (default: platform config directory)",
+				Name:    "db-path",
+				Usage:   "path to database file (default: platform config directory)",
+				EnvVars: []string{"RL_DB_PATH"},
+			},
+			&urfavecli.StringFlag{
+				Name:    "config",
+				Usage:   "path to config file (default: config.toml in the platform config directory)",
+				EnvVars: []string{"RL_CONFIG"},
+			},
+			&urfavecli.BoolFlag{
+				Name:  "json",
+				Usage: "print records and errors as JSON",
+			},
+			&urfavecli.BoolFlag{
+				Name:  "no-color",
+				Usage: "disable colors (also set by NO_COLOR or when stdout is not a terminal)",
+			},
+			&urfavecli.BoolFlag{
+				Name:    "read-only",
+				Usage:   "open the database read-only and refuse commands that change it",
+				EnvVars: []string{"RL_READ_ONLY"},
 			},
+			&urfavecli.BoolFlag{
+				Name:  "relative",
+				Usage: "show times as \"3h ago\" instead of dates (also relative_times in the config file)",
+			},
+			&urfavecli.BoolFlag{
+				Name:  "plain",
+				Usage: "print tables without borders or truncation (default when stdout is not a terminal)",
+			},
+		},
+		Before: func(c *urfavecli.Context) error {
+			err := loadConfig(c)
+			if !colorEnabled(c) {
+				colorReset, colorRed, colorYellow = "", "", ""
+				cli.SetColor(false)
+			}
+			cli.SetHyperlinks(hyperlinksEnabled(c))
+			cli.SetRelativeTimes(boolOr(c, "relative", cfg.RelativeTimes))
+			return err
 		},
 		Action: func(c *urfavecli.Context) error {
-			// Launch TUI if no command provided
-			s, err := app.NewStorage(c.String("db-path"))
+			// Launch TUI if no command provided, unless run from a script or pipe
+			if !isatty.IsTerminal(os.Stdin.Fd()) || !stdoutIsTerminal() {
+				return urfavecli.ShowAppHelp(c)
+			}
+			s, err := openStorage(c, nil)
 			if err != nil {
-				return fmt.Errorf("failed to initialize storage: %w", err)
+				return err
 			}
 			defer s.Close()
-			return tui.Run(s)
+			opts := tuiOptions(c)
+			commands := cli.NewCommands(s)
+			if !c.Bool("read-only") {
+				commands.SetBackups(backupStore(c))
+				// The TUI owns the screen, so failures go to its status bar
+				if _, err := commands.AutoBackup(backupInterval()); err != nil {
+					opts.Status = "Backup: " + err.Error()
+				}
+			}
+			if cfg.Cleanup.OnStartup && !c.Bool("read-only") {
+				result, err := commands.AutoCleanup(cleanupPolicy())
+				if err != nil {
+					opts.Status = "Cleanup: " + err.Error()
+				} else if result != (cli.CleanupResult{}) {
+					opts.Status = "Cleanup: " + result.String()
+				}
+			}
+			return tui.Run(s, opts)
 		},
 		Commands: []*urfavecli.Command{
 			{
 				Name:    "add",
 				Aliases: []string{"a"},
-				Usage:   "Add or update a link",
+				Usage:   "Add or update links",
 				Flags: []urfavecli.Flag{
 					&urfavecli.StringFlag{Name: "title", Usage: "title for the link"},
 					&urfavecli.StringFlag{Name: "note", Usage: "note for the link"},
 					&urfavecli.StringFlag{Name: "tags", Usage: "comma-separated tags"},
+					&urfavecli.StringFlag{Name: "priority", Aliases: []string{"p"}, Usage: "priority level (high, normal, low)"},
+					&urfavecli.BoolFlag{Name: "no-fetch", Usage: "don't fetch the page title and reading time"},
+					&urfavecli.BoolFlag{Name: "raw", Usage: "save the URL exactly as given (no canonicalization)"},
+					&urfavecli.BoolFlag{Name: "attach", Usage: "keep a copy of PDFs and other documents (see rl open --local)"},
+					&urfavecli.BoolFlag{Name: "clipboard", Aliases: []string{"c"}, Usage: "add the URL on the clipboard"},
+					&urfavecli.BoolFlag{Name: "from-tab", Usage: "add the active browser tab (URL and title)"},
 				},
 				Action: func(c *urfavecli.Context) error {
-					if c.NArg() == 0 {
-						return fmt.Errorf("usage: rl add [--title \"...\"] [--note \"...\"] [--tags \"...\"] <url>")
+					url, title := c.Args().Get(0), c.String("title")
+					switch {
+					case c.Bool("clipboard") && c.Bool("from-tab"):
+						return fmt.Errorf("--clipboard and --from-tab can't be combined")
+					case (c.Bool("clipboard") || c.Bool("from-tab")) && c.NArg() > 0:
+						return fmt.Errorf("--clipboard and --from-tab can't be combined with a URL argument")
+					case c.Bool("clipboard"):
+						var err error
+						if url, err = clipboard.ReadURL(); err != nil {
+							return err
+						}
+					case c.Bool("from-tab"):
+						t, err := tab.Current()
+						if err != nil {
+							return err
+						}
+						url = t.URL
+						if title == "" {
+							title = t.Title
+						}
+					case c.NArg() == 0:
+						return fmt.Errorf("usage: rl add [--title \"...\"] [--note \"...\"] [--tags \"...\"] [--priority high|normal|low] <url>... | - | --clipboard")
+					}
+					priority, err := priorityFlag(c)
+					if err != nil {
+						return err
+					}
+					opts := cli.AddOptions{
+						Title:        title,
+						Note:         c.String("note"),
+						Tags:         c.String("tags"),
+						Priority:     priority,
+						Fetch:        !boolOr(c, "no-fetch", !cfg.Add.Fetch),
+						Canonicalize: !boolOr(c, "raw", !cfg.Add.Canonicalize),
+						Attach:       boolOr(c, "attach", cfg.Add.Attach),
+					}
+
+					if c.NArg() <= 1 && url != "-" {
+						return withStorage(c, func(commands *cli.Commands) error {
+							return commands.Add(url, opts)
+						})
+					}
+
+					if c.IsSet("title") {
+						return fmt.Errorf("--title can't be used when adding several links")
+					}
+					urls, err := addArgs(c)
+					if err != nil {
+						return err
 					}
 					return withStorage(c, func(commands *cli.Commands) error {
-						return commands.Add(c.Args().Get(0), c.String("title"), c.String("note"), c.String("tags"))
+						return commands.AddAll(urls, opts)
+					})
+				},
+			},
+			{
+				Name:  "watch",
+				Usage: "Save URLs as they're copied to the clipboard, until interrupted",
+				Flags: []urfavecli.Flag{
+					&urfavecli.BoolFlag{Name: "quiet", Aliases: []string{"q"}, Usage: "save each URL without asking"},
+					&urfavecli.StringFlag{Name: "tags", Usage: "comma-separated tags for every link saved"},
+					&urfavecli.StringFlag{Name: "priority", Aliases: []string{"p"}, Usage: "priority level for every link saved (high, normal, low)"},
+					&urfavecli.BoolFlag{Name: "no-fetch", Usage: "don't fetch page titles and reading times"},
+					&urfavecli.BoolFlag{Name: "raw", Usage: "save URLs exactly as copied (no canonicalization)"},
+				},
+				Action: func(c *urfavecli.Context) error {
+					priority, err := priorityFlag(c)
+					if err != nil {
+						return err
+					}
+					opts := cli.WatchOptions{Add: cli.AddOptions{
+						Tags:         c.String("tags"),
+						Priority:     priority,
+						Fetch:        !boolOr(c, "no-fetch", !cfg.Add.Fetch),
+						Canonicalize: !boolOr(c, "raw", !cfg.Add.Canonicalize),
+						Attach:       cfg.Add.Attach,
+					}}
+					if !c.Bool("quiet") {
+						if !isatty.IsTerminal(os.Stdin.Fd()) {
+							return fmt.Errorf("rl watch asks before saving each URL; use --quiet to save them without a terminal")
+						}
+						opts.Prompt = os.Stdin
+					}
+					return withStorage(c, func(commands *cli.Commands) error {
+						return commands.Watch(opts)
 					})
 				},
 			},
@@ -65,169 +250,1808 @@ func main() {
 				Flags: []urfavecli.Flag{
 					&urfavecli.BoolFlag{Name: "read", Usage: "show only read links"},
 					&urfavecli.BoolFlag{Name: "all", Usage: "show all links"},
+					&urfavecli.BoolFlag{Name: "archived", Usage: "show only links archived by rl stale or a cleanup policy"},
 					&urfavecli.StringFlag{Name: "tag", Usage: "filter by tag"},
+					&urfavecli.StringFlag{Name: "domain", Usage: "filter by domain (includes subdomains)"},
+					&urfavecli.BoolFlag{Name: "show-domain", Usage: "add a domain column to the table"},
+					&urfavecli.BoolFlag{Name: "tsv", Usage: "print tab-separated records for scripts"},
 					&urfavecli.IntFlag{Name: "limit", Usage: "limit number of results"},
+					&urfavecli.BoolFlag{Name: "snoozed", Usage: "show only snoozed links"},
+					&urfavecli.BoolFlag{Name: "dead", Usage: "show only links whose last check failed"},
+					&urfavecli.StringFlag{Name: "type", Usage: "only articles, videos, or audio (article, video, audio)"},
+					&urfavecli.StringFlag{Name: "max-time", Usage: "only links readable (or watchable) within a duration (e.g. 10m)"},
+					&urfavecli.StringFlag{Name: "sort", Usage: "sort by newest, oldest, title, domain, or priority"},
+					&urfavecli.StringFlag{Name: "since", Usage: "only links saved since a duration ago or date (e.g. 7d, 2024-01-01)"},
+					&urfavecli.StringFlag{Name: "before", Usage: "only links saved before a duration ago or date"},
+					&urfavecli.StringFlag{Name: "read-since", Usage: "only links read since a duration ago or date"},
+					&urfavecli.StringFlag{Name: "group-by", Usage: "print a section per tag, domain, or week saved"},
+					&urfavecli.StringFlag{Name: "format", Usage: "print each link through a Go template (e.g. '{{.ID}}\t{{.URL}}')"},
 				},
 				Action: func(c *urfavecli.Context) error {
+					var maxTime time.Duration
+					if c.String("max-time") != "" {
+						var err error
+						maxTime, err = cli.ParseDuration(c.String("max-time"))
+						if err != nil {
+							return err
+						}
+					}
+					linkType, err := storage.ParseLinkType(c.String("type"))
+					if err != nil {
+						return err
+					}
+					sort, err := storage.ParseSortOrder(c.String("sort"))
+					if err != nil {
+						return err
+					}
+					dates, err := parseDateRange(c)
+					if err != nil {
+						return err
+					}
+					groupBy, err := cli.ParseGroupBy(c.String("group-by"))
+					if err != nil {
+						return err
+					}
+					if groupBy != cli.GroupNone && c.Bool("tsv") {
+						return fmt.Errorf("--group-by can't be combined with --tsv")
+					}
+					var format *template.Template
+					if c.IsSet("format") {
+						if c.Bool("tsv") {
+							return fmt.Errorf("--format can't be combined with --tsv")
+						}
+						if format, err = cli.ParseFormat(c.String("format")); err != nil {
+							return err
+						}
+					}
 					return withStorage(c, func(commands *cli.Commands) error {
-						readStatus := storage.ReadStatusUnread
+						readStatus := parseReadStatus(cfg.List.Filter)
 						if c.Bool("all") {
 							readStatus = storage.ReadStatusAll
-						} else if c.Bool("read") {
+						} else if c.Bool("archived") {
+							readStatus = storage.ReadStatusArchived
+						} else if c.Bool("read") || !dates.ReadSince.IsZero() {
 							readStatus = storage.ReadStatusRead
 						}
 
-						return commands.List(readStatus, c.String("tag"), c.Int("limit"))
+						return commands.List(storage.ListOptions{
+							ReadStatus:     readStatus,
+							Tag:            c.String("tag"),
+							Domain:         c.String("domain"),
+							Limit:          intOr(c, "limit", cfg.List.Limit),
+							Snoozed:        c.Bool("snoozed"),
+							Type:           linkType,
+							MaxReadingTime: maxTime,
+							Dead:           c.Bool("dead"),
+							Sort:           sort,
+							DateRange:      dates,
+						}, cli.DisplayOptions{
+							ShowDomain: c.Bool("show-domain"),
+							TSV:        c.Bool("tsv"),
+							GroupBy:    groupBy,
+							Template:   format,
+						})
 					})
 				},
 			},
 			{
 				Name:    "open",
 				Aliases: []string{"o"},
-				Usage:   "Open link in browser",
+				Usage:   "Open one or more links in browser",
+				Flags: []urfavecli.Flag{
+					&urfavecli.BoolFlag{Name: "done", Aliases: []string{"d"}, Usage: "also mark the link as read"},
+					&urfavecli.BoolFlag{Name: "local", Aliases: []string{"l"}, Usage: "open the saved copy of the document (see rl attach)"},
+				},
 				Action: func(c *urfavecli.Context) error {
 					if c.NArg() == 0 {
-						return fmt.Errorf("usage: rl open <id>")
+						return fmt.Errorf("usage: rl open [--done] [--local] <id> [id...]")
 					}
 					return withStorage(c, func(commands *cli.Commands) error {
-						id, err := cli.ParseID(c.Args().Get(0))
+						ids, err := parseIDs(c)
+						if err != nil {
+							return err
+						}
+						// open.mark_done is ignored rather than refused in read-only mode
+						markDone := boolOr(c, "done", cfg.Open.MarkDone && !c.Bool("read-only"))
+						return commands.Open(markDone, c.Bool("local"), ids...)
+					})
+				},
+			},
+			{
+				Name:  "attach",
+				Usage: "Download and keep a copy of the PDF or document a link points to",
+				Action: func(c *urfavecli.Context) error {
+					if c.NArg() == 0 {
+						return fmt.Errorf("usage: rl attach <id> [id...]")
+					}
+					return withStorage(c, func(commands *cli.Commands) error {
+						ids, err := parseIDs(c)
 						if err != nil {
 							return err
 						}
-						return commands.Open(id)
+						return commands.Attach(ids...)
 					})
 				},
 			},
 			{
 				Name:    "done",
 				Aliases: []string{"d"},
-				Usage:   "Mark link as read",
+				Usage:   "Mark one or more links as read",
 				Action: func(c *urfavecli.Context) error {
 					if c.NArg() == 0 {
-						return fmt.Errorf("usage: rl done <id>")
+						return fmt.Errorf("usage: rl done <id> [id...]")
 					}
 					return withStorage(c, func(commands *cli.Commands) error {
-						id, err := cli.ParseID(c.Args().Get(0))
+						ids, err := parseIDs(c)
 						if err != nil {
 							return err
 						}
-						return commands.Done(id)
+						return commands.Done(ids...)
 					})
 				},
 			},
 			{
 				Name:    "undo",
 				Aliases: []string{"u"},
-				Usage:   "Mark link as unread",
+				Usage:   "Mark one or more links as unread",
 				Action: func(c *urfavecli.Context) error {
 					if c.NArg() == 0 {
-						return fmt.Errorf("usage: rl undo <id>")
+						return fmt.Errorf("usage: rl undo <id> [id...]")
+					}
+					return withStorage(c, func(commands *cli.Commands) error {
+						ids, err := parseIDs(c)
+						if err != nil {
+							return err
+						}
+						return commands.Undo(ids...)
+					})
+				},
+			},
+			{
+				Name:    "prioritize",
+				Aliases: []string{"pri"},
+				Usage:   "Set link priority (high, normal, low)",
+				Action: func(c *urfavecli.Context) error {
+					if c.NArg() < 2 {
+						return fmt.Errorf("usage: rl prioritize <id> <high|normal|low>")
+					}
+					priority, err := model.ParsePriority(c.Args().Get(1))
+					if err != nil {
+						return err
 					}
 					return withStorage(c, func(commands *cli.Commands) error {
 						id, err := cli.ParseID(c.Args().Get(0))
 						if err != nil {
 							return err
 						}
-						return commands.Undo(id)
+						return commands.Prioritize(id, priority)
 					})
 				},
 			},
 			{
-				Name:    "rm",
-				Aliases: []string{"remove", "delete"},
-				Usage:   "Delete one or more links",
+				Name:      "bump",
+				Usage:     "Move an unread link up the unread queue",
+				ArgsUsage: "<id>",
+				Flags: []urfavecli.Flag{
+					&urfavecli.IntFlag{Name: "places", Aliases: []string{"n"}, Value: 1, Usage: "number of places to move the link"},
+					&urfavecli.BoolFlag{Name: "top", Aliases: []string{"t"}, Usage: "move the link to the top of the queue"},
+				},
+				Action: func(c *urfavecli.Context) error {
+					return reorder(c, -1, "top")
+				},
+			},
+			{
+				Name:      "demote",
+				Usage:     "Move an unread link down the unread queue",
+				ArgsUsage: "<id>",
+				Flags: []urfavecli.Flag{
+					&urfavecli.IntFlag{Name: "places", Aliases: []string{"n"}, Value: 1, Usage: "number of places to move the link"},
+					&urfavecli.BoolFlag{Name: "bottom", Aliases: []string{"b"}, Usage: "move the link to the bottom of the queue"},
+				},
+				Action: func(c *urfavecli.Context) error {
+					return reorder(c, 1, "bottom")
+				},
+			},
+			{
+				Name:  "show",
+				Usage: "Show all fields of a link",
+				Action: func(c *urfavecli.Context) error {
+					if c.NArg() != 1 {
+						return fmt.Errorf("usage: rl show <id>")
+					}
+					id, err := cli.ParseID(c.Args().Get(0))
+					if err != nil {
+						return err
+					}
+					return withStorage(c, func(commands *cli.Commands) error {
+						return commands.Show(id)
+					})
+				},
+			},
+			{
+				Name:      "summarize",
+				Usage:     "Summarize links' archived text with the language model in the config file",
+				ArgsUsage: "<id> [id...]",
+				Flags: []urfavecli.Flag{
+					&urfavecli.BoolFlag{Name: "force", Aliases: []string{"f"}, Usage: "write a new summary even if the link has one"},
+				},
 				Action: func(c *urfavecli.Context) error {
 					if c.NArg() == 0 {
-						return fmt.Errorf("usage: rl rm <id> [id...]")
+						return fmt.Errorf("usage: rl summarize [--force] <id> [id...]")
+					}
+					if cfg.LLM.Model == "" {
+						return llm.ErrNoModel
+					}
+					client, err := llm.NewClient(llmOptions())
+					if err != nil {
+						return err
 					}
 					return withStorage(c, func(commands *cli.Commands) error {
-						ids := make([]string, 0, c.NArg())
-						for i := 0; i < c.NArg(); i++ {
-							id, err := cli.ParseID(c.Args().Get(i))
-							if err != nil {
+						ids, err := parseIDs(c)
+						if err != nil {
+							return err
+						}
+						return commands.Summarize(client, c.Bool("force"), ids...)
+					})
+				},
+			},
+			{
+				Name:      "suggest-tags",
+				Usage:     "Suggest tags for links from their title and archived text",
+				ArgsUsage: "--untagged | <id> [id...]",
+				Flags: []urfavecli.Flag{
+					&urfavecli.BoolFlag{Name: "untagged", Usage: "suggest tags for every link without any"},
+					&urfavecli.BoolFlag{Name: "yes", Aliases: []string{"y"}, Usage: "add the suggestions without asking"},
+					&urfavecli.BoolFlag{Name: "local", Usage: "pick tags from the article's words instead of asking the language model"},
+				},
+				Action: func(c *urfavecli.Context) error {
+					switch {
+					case c.Bool("untagged") && c.NArg() > 0:
+						return fmt.Errorf("--untagged doesn't take IDs")
+					case !c.Bool("untagged") && c.NArg() == 0:
+						return fmt.Errorf("usage: rl suggest-tags [--yes] [--local] --untagged | <id> [id...]")
+					}
+					opts := cli.SuggestOptions{Untagged: c.Bool("untagged"), Yes: c.Bool("yes")}
+					if !c.Bool("local") && cfg.LLM.Model != "" {
+						client, err := llm.NewClient(llmOptions())
+						if err != nil {
+							return err
+						}
+						opts.LLM = client
+					}
+					if !opts.Yes && isatty.IsTerminal(os.Stdin.Fd()) && !c.Bool("read-only") {
+						opts.Prompt = os.Stdin
+					}
+					return withStorage(c, func(commands *cli.Commands) error {
+						var ids []string
+						if !opts.Untagged {
+							var err error
+							if ids, err = parseIDs(c); err != nil {
 								return err
 							}
-							ids = append(ids, id)
 						}
-						return commands.Remove(ids...)
+						return commands.SuggestTags(opts, ids...)
 					})
 				},
 			},
 			{
-				Name:  "export",
-				Usage: "Export all links to JSON",
+				Name:  "qr",
+				Usage: "Show a link's URL as a QR code to scan with a phone",
 				Action: func(c *urfavecli.Context) error {
+					if c.NArg() != 1 {
+						return fmt.Errorf("usage: rl qr <id>")
+					}
+					id, err := cli.ParseID(c.Args().Get(0))
+					if err != nil {
+						return err
+					}
 					return withStorage(c, func(commands *cli.Commands) error {
-						return commands.Export(os.Stdout)
+						return commands.QR(id)
 					})
 				},
 			},
 			{
-				Name:  "import",
-				Usage: "Import links from JSON file",
+				Name:  "alias",
+				Usage: "Name a link so the name works anywhere an ID does (no arguments lists aliases)",
+				Action: func(c *urfavecli.Context) error {
+					switch c.NArg() {
+					case 0:
+						return withStorage(c, func(commands *cli.Commands) error {
+							return commands.Aliases()
+						})
+					case 2:
+						id, err := cli.ParseID(c.Args().Get(0))
+						if err != nil {
+							return err
+						}
+						return withStorage(c, func(commands *cli.Commands) error {
+							return commands.Alias(id, c.Args().Get(1))
+						})
+					default:
+						return fmt.Errorf("usage: rl alias [<id> <name>]")
+					}
+				},
+			},
+			{
+				Name:  "unalias",
+				Usage: "Remove a link alias",
+				Action: func(c *urfavecli.Context) error {
+					if c.NArg() != 1 {
+						return fmt.Errorf("usage: rl unalias <name>")
+					}
+					return withStorage(c, func(commands *cli.Commands) error {
+						return commands.Unalias(c.Args().Get(0))
+					})
+				},
+			},
+			{
+				Name:  "note",
+				Usage: "Add dated notes to a link while reading it",
+				Subcommands: []*urfavecli.Command{
+					{
+						Name:  "add",
+						Usage: "Append a dated note to a link",
+						Action: func(c *urfavecli.Context) error {
+							if c.NArg() < 2 {
+								return fmt.Errorf("usage: rl note add <id> <text>")
+							}
+							id, err := cli.ParseID(c.Args().Get(0))
+							if err != nil {
+								return err
+							}
+							return withStorage(c, func(commands *cli.Commands) error {
+								return commands.NoteAdd(id, strings.Join(c.Args().Slice()[1:], " "))
+							})
+						},
+					},
+					{
+						Name:    "ls",
+						Aliases: []string{"list"},
+						Usage:   "List a link's notes, oldest first",
+						Action: func(c *urfavecli.Context) error {
+							if c.NArg() != 1 {
+								return fmt.Errorf("usage: rl note ls <id>")
+							}
+							id, err := cli.ParseID(c.Args().Get(0))
+							if err != nil {
+								return err
+							}
+							return withStorage(c, func(commands *cli.Commands) error {
+								return commands.Notes(id)
+							})
+						},
+					},
+					{
+						Name:  "rm",
+						Usage: "Remove a note by the number rl note ls shows",
+						Action: func(c *urfavecli.Context) error {
+							if c.NArg() != 1 {
+								return fmt.Errorf("usage: rl note rm <number>")
+							}
+							n, err := strconv.ParseInt(c.Args().Get(0), 10, 64)
+							if err != nil || n <= 0 {
+								return fmt.Errorf("invalid note number: %s", c.Args().Get(0))
+							}
+							return withStorage(c, func(commands *cli.Commands) error {
+								return commands.NoteRemove(n)
+							})
+						},
+					},
+				},
+			},
+			{
+				Name:  "quote",
+				Usage: "Save a highlighted passage from a link (no text lists the link's quotes)",
 				Action: func(c *urfavecli.Context) error {
 					if c.NArg() == 0 {
-						return fmt.Errorf("usage: rl import <file.json>")
+						return fmt.Errorf("usage: rl quote <id> [text]")
+					}
+					id, err := cli.ParseID(c.Args().Get(0))
+					if err != nil {
+						return err
 					}
 					return withStorage(c, func(commands *cli.Commands) error {
-						return commands.Import(c.Args().Get(0))
+						if c.NArg() == 1 {
+							return commands.Quotes(id)
+						}
+						return commands.Quote(id, strings.Join(c.Args().Slice()[1:], " "))
 					})
 				},
 			},
 			{
-				Name:    "grep",
-				Aliases: []string{"search"},
-				Usage:   "Search links using full-text search",
+				Name:  "unquote",
+				Usage: "Remove a quote by the number rl quote <id> shows",
+				Action: func(c *urfavecli.Context) error {
+					if c.NArg() != 1 {
+						return fmt.Errorf("usage: rl unquote <number>")
+					}
+					n, err := strconv.ParseInt(c.Args().Get(0), 10, 64)
+					if err != nil || n <= 0 {
+						return fmt.Errorf("invalid quote number: %s", c.Args().Get(0))
+					}
+					return withStorage(c, func(commands *cli.Commands) error {
+						return commands.Unquote(n)
+					})
+				},
+			},
+			{
+				Name:  "quotes",
+				Usage: "Export every quote as Markdown, grouped by link",
+				Action: func(c *urfavecli.Context) error {
+					return withStorage(c, func(commands *cli.Commands) error {
+						return commands.ExportQuotes(os.Stdout)
+					})
+				},
+			},
+			{
+				Name:  "related",
+				Usage: "Suggest saved links sharing tags, domain, or title terms with a link",
+				Flags: []urfavecli.Flag{
+					&urfavecli.IntFlag{Name: "limit", Usage: "maximum number of links to suggest (default 10)"},
+				},
+				Action: func(c *urfavecli.Context) error {
+					if c.NArg() != 1 {
+						return fmt.Errorf("usage: rl related [--limit n] <id>")
+					}
+					id, err := cli.ParseID(c.Args().Get(0))
+					if err != nil {
+						return err
+					}
+					return withStorage(c, func(commands *cli.Commands) error {
+						return commands.Related(id, c.Int("limit"))
+					})
+				},
+			},
+			{
+				Name:      "next",
+				Usage:     "Suggest what to read next, by priority, the tags you finish, reading time, and age",
+				ArgsUsage: "[n]",
+				Flags: []urfavecli.Flag{
+					&urfavecli.StringFlag{Name: "time", Aliases: []string{"t"}, Usage: "reading time available, e.g. 15m (default: about as long as what you've read)"},
+				},
+				Action: func(c *urfavecli.Context) error {
+					if c.NArg() > 1 {
+						return fmt.Errorf("usage: rl next [--time 15m] [n]")
+					}
+					var n int
+					if c.NArg() == 1 {
+						var err error
+						if n, err = strconv.Atoi(c.Args().First()); err != nil || n <= 0 {
+							return fmt.Errorf("invalid count %q", c.Args().First())
+						}
+					}
+					var available time.Duration
+					if c.String("time") != "" {
+						var err error
+						if available, err = cli.ParseDuration(c.String("time")); err != nil {
+							return err
+						}
+					}
+					return withStorage(c, func(commands *cli.Commands) error {
+						return commands.Next(n, available)
+					})
+				},
+			},
+			{
+				Name:  "fetch",
+				Usage: "Fetch page title and reading time for one or more links",
+				Flags: []urfavecli.Flag{
+					&urfavecli.BoolFlag{Name: "all", Usage: "fetch every link, several at a time"},
+					&urfavecli.BoolFlag{Name: "missing", Usage: "with --all, only links without a title or reading time"},
+					&urfavecli.BoolFlag{Name: "force", Aliases: []string{"f"}, Usage: "download pages even if the server says they haven't changed"},
+				},
 				Action: func(c *urfavecli.Context) error {
+					if c.Bool("all") || c.Bool("missing") {
+						if c.NArg() > 0 {
+							return fmt.Errorf("--all doesn't take IDs")
+						}
+						return withStorage(c, func(commands *cli.Commands) error {
+							return commands.FetchAll(c.Bool("missing"), c.Bool("force"))
+						})
+					}
 					if c.NArg() == 0 {
-						return fmt.Errorf("usage: rl grep \"<query>\"")
+						return fmt.Errorf("usage: rl fetch [--force] [--all [--missing]] <id> [id...]")
 					}
 					return withStorage(c, func(commands *cli.Commands) error {
-						return commands.Search(c.Args().Get(0))
+						ids, err := parseIDs(c)
+						if err != nil {
+							return err
+						}
+						return commands.Fetch(c.Bool("force"), ids...)
 					})
 				},
 			},
 			{
-				Name:    "tui",
-				Aliases: []string{"interactive", "i"},
-				Usage:   "Launch interactive TUI mode",
+				Name:  "rules",
+				Usage: "Preview and apply the [[rules]] that tag links as they're added",
+				Subcommands: []*urfavecli.Command{
+					{
+						Name:    "list",
+						Aliases: []string{"ls"},
+						Usage:   "List the rules in the order they're applied",
+						Action: func(c *urfavecli.Context) error {
+							tagRules, err := tagRules()
+							if err != nil {
+								return err
+							}
+							return cli.ListRules(tagRules, jsonOutput(c))
+						},
+					},
+					{
+						Name:      "test",
+						Usage:     "Show which rules would tag a URL",
+						ArgsUsage: "<url>",
+						Flags: []urfavecli.Flag{
+							&urfavecli.StringFlag{Name: "title", Usage: "title to match title rules against (default: the saved or fetched title)"},
+							&urfavecli.BoolFlag{Name: "no-fetch", Usage: "don't fetch the page title"},
+						},
+						Action: func(c *urfavecli.Context) error {
+							if c.NArg() != 1 {
+								return fmt.Errorf("usage: rl rules test [--title \"...\"] <url>")
+							}
+							tagRules, err := tagRules()
+							if err != nil {
+								return err
+							}
+							return withStorage(c, func(commands *cli.Commands) error {
+								return commands.TestRules(tagRules, c.Args().First(), c.String("title"), !boolOr(c, "no-fetch", !cfg.Add.Fetch))
+							})
+						},
+					},
+					{
+						Name:      "apply",
+						Usage:     "Tag saved links by the rules",
+						ArgsUsage: "--all | <id> [id...]",
+						Flags: []urfavecli.Flag{
+							&urfavecli.BoolFlag{Name: "all", Usage: "apply them to every link"},
+							&urfavecli.BoolFlag{Name: "dry-run", Usage: "show what would be tagged without changing anything"},
+						},
+						Action: func(c *urfavecli.Context) error {
+							switch {
+							case c.Bool("all") && c.NArg() > 0:
+								return fmt.Errorf("--all doesn't take IDs")
+							case !c.Bool("all") && c.NArg() == 0:
+								return fmt.Errorf("usage: rl rules apply [--dry-run] --all | <id> [id...]")
+							}
+							tagRules, err := tagRules()
+							if err != nil {
+								return err
+							}
+							return withStorage(c, func(commands *cli.Commands) error {
+								var ids []string
+								if !c.Bool("all") {
+									if ids, err = parseIDs(c); err != nil {
+										return err
+									}
+								}
+								return commands.ApplyRules(tagRules, c.Bool("dry-run"), ids...)
+							})
+						},
+					},
+				},
+			},
+			{
+				Name:    "snooze",
+				Aliases: []string{"z"},
+				Usage:   "Hide a link from the unread list for a while (e.g. 3d, 12h, 2w, 2024-01-31)",
 				Action: func(c *urfavecli.Context) error {
-					s, err := app.NewStorage(c.String("db-path"))
+					if c.NArg() < 2 {
+						return fmt.Errorf("usage: rl snooze <id> <duration|date>")
+					}
+					until, err := cli.ParseUntil(c.Args().Get(1), time.Now())
 					if err != nil {
-						return fmt.Errorf("failed to initialize storage: %w", err)
+						return err
 					}
-					defer s.Close()
-					return tui.Run(s)
+					return withStorage(c, func(commands *cli.Commands) error {
+						id, err := cli.ParseID(c.Args().Get(0))
+						if err != nil {
+							return err
+						}
+						return commands.Snooze(id, until)
+					})
 				},
 			},
-		},
-		OnUsageError: func(c *urfavecli.Context, err error, isSubcommand bool) error {
-			if isSubcommand {
-				return err
-			}
-			fmt.Fprintf(os.Stderr, "%sError:%s %v\n", colorRed, colorReset, err)
-			return nil
-		},
-		ExitErrHandler: func(c *urfavecli.Context, err error) {
+			{
+				Name:  "unsnooze",
+				Usage: "Return a snoozed link to the unread list",
+				Action: func(c *urfavecli.Context) error {
+					if c.NArg() == 0 {
+						return fmt.Errorf("usage: rl unsnooze <id>")
+					}
+					return withStorage(c, func(commands *cli.Commands) error {
+						id, err := cli.ParseID(c.Args().Get(0))
+						if err != nil {
+							return err
+						}
+						return commands.Snooze(id, time.Time{})
+					})
+				},
+			},
+			{
+				Name:  "remind",
+				Usage: "Show a desktop notification for each link whose snooze ended since the last check",
+				Flags: []urfavecli.Flag{
+					&urfavecli.BoolFlag{Name: "daemon", Usage: "keep running, checking every minute"},
+				},
+				Action: func(c *urfavecli.Context) error {
+					state, err := app.RemindStatePath(dbPath(c))
+					if err != nil {
+						return err
+					}
+					return withStorage(c, func(commands *cli.Commands) error {
+						return commands.Remind(state, c.Bool("daemon"))
+					})
+				},
+			},
+			{
+				Name:  "daemon",
+				Usage: "Run the jobs scheduled under [daemon.jobs] in the config file until interrupted",
+				Action: func(c *urfavecli.Context) error {
+					jobs, err := daemonJobs()
+					if err != nil {
+						return err
+					}
+					state, err := app.DaemonStatePath(dbPath(c))
+					if err != nil {
+						return err
+					}
+					remind, err := app.RemindStatePath(dbPath(c))
+					if err != nil {
+						return err
+					}
+					log := io.Writer(os.Stderr)
+					if cfg.Daemon.Log != "" {
+						if err := os.MkdirAll(filepath.Dir(cfg.Daemon.Log), 0755); err != nil {
+							return fmt.Errorf("open daemon log: %w", err)
+						}
+						f, err := os.OpenFile(cfg.Daemon.Log, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+						if err != nil {
+							return fmt.Errorf("open daemon log: %w", err)
+						}
+						defer f.Close()
+						log = f
+					}
+					return withStorage(c, func(commands *cli.Commands) error {
+						return commands.Daemon(cli.DaemonOptions{
+							Jobs:       jobs,
+							Cleanup:    cleanupPolicy(),
+							StatePath:  state,
+							RemindPath: remind,
+							Log:        log,
+						})
+					})
+				},
+				Subcommands: []*urfavecli.Command{
+					{
+						Name:  "status",
+						Usage: "Show whether the daemon is running and when each job last and next runs",
+						Action: func(c *urfavecli.Context) error {
+							jobs, err := daemonJobs()
+							if err != nil {
+								return err
+							}
+							state, err := app.DaemonStatePath(dbPath(c))
+							if err != nil {
+								return err
+							}
+							return cli.DaemonStatus(state, jobs, jsonOutput(c))
+						},
+					},
+				},
+			},
+			{
+				Name:    "rm",
+				Aliases: []string{"remove", "delete"},
+				Usage:   "Move one or more links to the trash",
+				Flags: []urfavecli.Flag{
+					&urfavecli.BoolFlag{Name: "permanent", Usage: "delete them for good instead"},
+				},
+				Action: func(c *urfavecli.Context) error {
+					if c.NArg() == 0 {
+						return fmt.Errorf("usage: rl rm <id> [id...]")
+					}
+					return withStorage(c, func(commands *cli.Commands) error {
+						ids, err := parseIDs(c)
+						if err != nil {
+							return err
+						}
+						return commands.Remove(c.Bool("permanent"), ids...)
+					})
+				},
+			},
+			{
+				Name:  "trash",
+				Usage: "List the links rl rm moved to the trash",
+				Action: func(c *urfavecli.Context) error {
+					return withStorage(c, func(commands *cli.Commands) error {
+						return commands.TrashList()
+					})
+				},
+				Subcommands: []*urfavecli.Command{
+					{
+						Name:  "empty",
+						Usage: "Delete the links in the trash for good",
+						Flags: []urfavecli.Flag{
+							&urfavecli.StringFlag{Name: "older-than", Usage: "only links trashed longer ago than this"},
+						},
+						Action: func(c *urfavecli.Context) error {
+							var olderThan time.Duration
+							if c.IsSet("older-than") {
+								d, err := cli.ParseDuration(c.String("older-than"))
+								if err != nil {
+									return err
+								}
+								olderThan = d
+							}
+							return withStorage(c, func(commands *cli.Commands) error {
+								return commands.EmptyTrash(olderThan)
+							})
+						},
+					},
+				},
+			},
+			{
+				Name:  "restore",
+				Usage: "Take one or more links out of the trash",
+				Action: func(c *urfavecli.Context) error {
+					if c.NArg() == 0 {
+						return fmt.Errorf("usage: rl restore <id> [id...]")
+					}
+					return withStorage(c, func(commands *cli.Commands) error {
+						ids, err := parseIDs(c)
+						if err != nil {
+							return err
+						}
+						return commands.Restore(ids...)
+					})
+				},
+			},
+			{
+				Name:      "history",
+				Usage:     "Show how a link's title, tags, and URL were edited",
+				ArgsUsage: "<id>",
+				Flags: []urfavecli.Flag{
+					&urfavecli.Int64Flag{Name: "revert", Usage: "set the field an edit changed back to its earlier value, by the number history shows"},
+				},
+				Action: func(c *urfavecli.Context) error {
+					if c.NArg() != 1 {
+						return fmt.Errorf("usage: rl history [--revert <number>] <id>")
+					}
+					id, err := cli.ParseID(c.Args().Get(0))
+					if err != nil {
+						return err
+					}
+					return withStorage(c, func(commands *cli.Commands) error {
+						if c.IsSet("revert") {
+							return commands.Revert(id, c.Int64("revert"))
+						}
+						return commands.History(id)
+					})
+				},
+			},
+			{
+				Name:      "mv",
+				Aliases:   []string{"move"},
+				Usage:     "Change a link's URL, keeping everything else",
+				ArgsUsage: "<id> <new-url>",
+				Flags: []urfavecli.Flag{
+					&urfavecli.BoolFlag{Name: "raw", Usage: "save the URL exactly as given (no canonicalization)"},
+				},
+				Action: func(c *urfavecli.Context) error {
+					if c.NArg() != 2 {
+						return fmt.Errorf("usage: rl mv <id> <new-url>")
+					}
+					id, err := cli.ParseID(c.Args().Get(0))
+					if err != nil {
+						return err
+					}
+					return withStorage(c, func(commands *cli.Commands) error {
+						return commands.Move(id, c.Args().Get(1), !boolOr(c, "raw", !cfg.Add.Canonicalize))
+					})
+				},
+			},
+			{
+				Name:      "merge",
+				Usage:     "Merge duplicate links into one, deleting the duplicates",
+				ArgsUsage: "<keep-id> <dup-id> [dup-id...]",
+				Action: func(c *urfavecli.Context) error {
+					if c.NArg() < 2 {
+						return fmt.Errorf("usage: rl merge <keep-id> <dup-id> [dup-id...]")
+					}
+					return withStorage(c, func(commands *cli.Commands) error {
+						ids, err := parseIDs(c)
+						if err != nil {
+							return err
+						}
+						return commands.Merge(ids[0], ids[1:]...)
+					})
+				},
+			},
+			{
+				Name:  "export",
+				Usage: "Export all links to JSON",
+				Flags: []urfavecli.Flag{
+					&urfavecli.StringFlag{Name: "format", Usage: "rl, linkding, or shiori"},
+					&urfavecli.BoolFlag{Name: "encrypt", Usage: "encrypt the export with a passphrase ($RL_PASSPHRASE or prompted)"},
+					&urfavecli.StringFlag{Name: "key-file", Usage: "encrypt with this file of 32+ random bytes instead of a passphrase"},
+				},
+				Action: func(c *urfavecli.Context) error {
+					return withStorage(c, func(commands *cli.Commands) error {
+						enc := cli.Encryption{Encrypt: c.Bool("encrypt"), KeyFile: c.String("key-file")}
+						return commands.Export(os.Stdout, c.String("format"), enc)
+					})
+				},
+			},
+			{
+				Name:  "import",
+				Usage: "Import links from rl's export or another tool's (linkding, Shiori, Omnivore, Readwise Reader)",
+				Flags: []urfavecli.Flag{
+					&urfavecli.StringFlag{Name: "format", Usage: "rl, linkding, shiori, omnivore (zip), or readwise (CSV)"},
+					&urfavecli.BoolFlag{Name: "dry-run", Usage: "show what would be added or merged without changing anything"},
+					&urfavecli.StringFlag{Name: "key-file", Usage: "key file to decrypt an export encrypted with one"},
+				},
+				Action: func(c *urfavecli.Context) error {
+					if c.NArg() == 0 {
+						return fmt.Errorf("usage: rl import [--format rl|linkding|shiori|omnivore|readwise] [--dry-run] [--key-file <file>] <file>")
+					}
+					return withStorage(c, func(commands *cli.Commands) error {
+						return commands.Import(c.Args().Get(0), c.String("format"), c.Bool("dry-run"), cli.Encryption{KeyFile: c.String("key-file")})
+					})
+				},
+			},
+			{
+				Name:    "grep",
+				Aliases: []string{"search"},
+				Usage:   "Search links using full-text search",
+				Flags: []urfavecli.Flag{
+					&urfavecli.BoolFlag{Name: "tsv", Usage: "print tab-separated records for scripts"},
+					&urfavecli.BoolFlag{Name: "content", Aliases: []string{"c"}, Usage: "also search archived article text"},
+					&urfavecli.StringFlag{Name: "since", Usage: "only links saved since a duration ago or date (e.g. 7d, 2024-01-01)"},
+					&urfavecli.StringFlag{Name: "before", Usage: "only links saved before a duration ago or date"},
+					&urfavecli.StringFlag{Name: "read-since", Usage: "only links read since a duration ago or date"},
+					&urfavecli.BoolFlag{Name: "semantic", Aliases: []string{"s"}, Usage: "rank links by meaning with the embedding model instead of matching words"},
+				},
+				Action: func(c *urfavecli.Context) error {
+					if c.NArg() == 0 {
+						return fmt.Errorf("usage: rl grep [--content | --semantic] \"<query>\"")
+					}
+					dates, err := parseDateRange(c)
+					if err != nil {
+						return err
+					}
+					display := cli.DisplayOptions{TSV: c.Bool("tsv")}
+					if c.Bool("semantic") {
+						if c.Bool("content") {
+							return fmt.Errorf("--semantic and --content can't be combined")
+						}
+						if cfg.LLM.EmbeddingModel == "" {
+							return llm.ErrNoEmbeddingModel
+						}
+						client, err := llm.NewClient(llmOptions())
+						if err != nil {
+							return err
+						}
+						return withStorage(c, func(commands *cli.Commands) error {
+							return commands.SemanticSearch(client, c.Args().Get(0), dates, !c.Bool("read-only"), display)
+						})
+					}
+					opts := storage.SearchOptions{Content: c.Bool("content"), DateRange: dates}
+					return withStorage(c, func(commands *cli.Commands) error {
+						return commands.Search(c.Args().Get(0), opts, display)
+					})
+				},
+			},
+			{
+				Name:  "reindex",
+				Usage: "Rebuild the full-text search index",
+				Action: func(c *urfavecli.Context) error {
+					return withStorage(c, func(commands *cli.Commands) error {
+						return commands.Reindex()
+					})
+				},
+			},
+			{
+				Name:  "random",
+				Usage: "Pick a random unread link",
+				Flags: []urfavecli.Flag{
+					&urfavecli.StringFlag{Name: "tag", Usage: "only links with this tag"},
+					&urfavecli.StringFlag{Name: "domain", Usage: "only links from this domain (includes subdomains)"},
+					&urfavecli.StringFlag{Name: "max-time", Usage: "only links readable within a duration (e.g. 10m)"},
+					&urfavecli.BoolFlag{Name: "open", Usage: "open the picked link in the browser"},
+				},
+				Action: func(c *urfavecli.Context) error {
+					var maxTime time.Duration
+					if c.String("max-time") != "" {
+						var err error
+						maxTime, err = cli.ParseDuration(c.String("max-time"))
+						if err != nil {
+							return err
+						}
+					}
+					return withStorage(c, func(commands *cli.Commands) error {
+						return commands.Random(storage.ListOptions{
+							ReadStatus:     storage.ReadStatusUnread,
+							Tag:            c.String("tag"),
+							Domain:         c.String("domain"),
+							MaxReadingTime: maxTime,
+						}, c.Bool("open"))
+					})
+				},
+			},
+			{
+				Name:  "pick",
+				Usage: "Fuzzy-find a link and print its ID (or open / mark it read)",
+				Flags: []urfavecli.Flag{
+					&urfavecli.BoolFlag{Name: "all", Usage: "pick from all links, not just unread"},
+					&urfavecli.StringFlag{Name: "tag", Usage: "only links with this tag"},
+					&urfavecli.BoolFlag{Name: "open", Usage: "open the picked link in the browser"},
+					&urfavecli.BoolFlag{Name: "done", Usage: "mark the picked link as read"},
+				},
+				Action: func(c *urfavecli.Context) error {
+					readStatus := storage.ReadStatusUnread
+					if c.Bool("all") {
+						readStatus = storage.ReadStatusAll
+					}
+					return withStorage(c, func(commands *cli.Commands) error {
+						return commands.Pick(storage.ListOptions{
+							ReadStatus: readStatus,
+							Tag:        c.String("tag"),
+						}, c.Bool("open"), c.Bool("done"))
+					})
+				},
+			},
+			{
+				Name:  "check",
+				Usage: "Check links for 404/410 and unreachable URLs (default: unread)",
+				Flags: []urfavecli.Flag{
+					&urfavecli.BoolFlag{Name: "all", Usage: "check all links"},
+					&urfavecli.BoolFlag{Name: "unread", Usage: "check unread links"},
+				},
+				Action: func(c *urfavecli.Context) error {
+					return withStorage(c, func(commands *cli.Commands) error {
+						readStatus := storage.ReadStatusUnread
+						if c.Bool("all") {
+							readStatus = storage.ReadStatusAll
+						}
+						return commands.Check(readStatus)
+					})
+				},
+			},
+			{
+				Name:  "diffcheck",
+				Usage: "Flag saved pages whose content changed or disappeared since saving",
+				Flags: []urfavecli.Flag{
+					&urfavecli.IntFlag{Name: "threshold", Value: int(cli.DefaultDiffThreshold * 100), Usage: "percent of the text that must differ to flag a page"},
+					&urfavecli.BoolFlag{Name: "update", Usage: "save the current text of changed pages as the new baseline"},
+				},
+				Action: func(c *urfavecli.Context) error {
+					threshold := c.Int("threshold")
+					if threshold < 1 || threshold > 100 {
+						return fmt.Errorf("--threshold must be between 1 and 100")
+					}
+					return withStorage(c, func(commands *cli.Commands) error {
+						var ids []string
+						if c.NArg() > 0 {
+							var err error
+							if ids, err = parseIDs(c); err != nil {
+								return err
+							}
+						}
+						return commands.DiffCheck(float64(threshold)/100, c.Bool("update"), ids...)
+					})
+				},
+			},
+			{
+				Name:  "stats",
+				Usage: "Show link counts and top domains",
+				Action: func(c *urfavecli.Context) error {
+					return withStorage(c, func(commands *cli.Commands) error {
+						return commands.Stats()
+					})
+				},
+			},
+			{
+				Name:  "cleanup",
+				Usage: "Apply the [cleanup] retention policies from the config file",
+				Flags: []urfavecli.Flag{
+					&urfavecli.BoolFlag{Name: "dry-run", Aliases: []string{"n"}, Usage: "list what would change without changing it"},
+				},
+				Action: func(c *urfavecli.Context) error {
+					return withStorage(c, func(commands *cli.Commands) error {
+						return commands.Cleanup(cleanupPolicy(), c.Bool("dry-run"))
+					})
+				},
+			},
+			{
+				Name:  "stale",
+				Usage: "List unread links that have sat the longest, and archive or delete them",
+				Flags: []urfavecli.Flag{
+					&urfavecli.StringFlag{Name: "older-than", Value: "90d", Usage: "only links saved longer ago than this"},
+					&urfavecli.IntFlag{Name: "limit", Usage: "limit number of results"},
+					&urfavecli.BoolFlag{Name: "archive", Usage: "mark them all read without asking"},
+					&urfavecli.BoolFlag{Name: "delete", Usage: "move them all to the trash without asking"},
+				},
+				Action: func(c *urfavecli.Context) error {
+					olderThan, err := cli.ParseDuration(c.String("older-than"))
+					if err != nil {
+						return err
+					}
+					opts := cli.StaleOptions{OlderThan: olderThan, Limit: c.Int("limit")}
+					switch {
+					case c.Bool("archive") && c.Bool("delete"):
+						return fmt.Errorf("--archive and --delete can't be combined")
+					case c.Bool("archive"):
+						opts.Action = cli.StaleArchive
+					case c.Bool("delete"):
+						opts.Action = cli.StaleDelete
+					case isatty.IsTerminal(os.Stdin.Fd()) && !c.Bool("read-only"):
+						opts.Prompt = os.Stdin
+					}
+					return withStorage(c, func(commands *cli.Commands) error {
+						return commands.Stale(opts)
+					})
+				},
+			},
+			{
+				Name:  "streak",
+				Usage: "Show reading streaks and progress toward the weekly goal",
+				Action: func(c *urfavecli.Context) error {
+					return withStorage(c, func(commands *cli.Commands) error {
+						return commands.Streak(cfg.Goals.Weekly)
+					})
+				},
+			},
+			{
+				Name:  "sync",
+				Usage: "Sync links with a remote service",
+				Subcommands: []*urfavecli.Command{
+					{
+						Name:  "wallabag",
+						Usage: "Push new links to Wallabag, pull its entries, and mirror read state both ways",
+						Action: func(c *urfavecli.Context) error {
+							w := cfg.Wallabag
+							if w.URL == "" {
+								return fmt.Errorf("set url and credentials under [wallabag] in the config file")
+							}
+							client, err := wallabag.NewClient(wallabag.Config{
+								URL:          w.URL,
+								ClientID:     w.ClientID,
+								ClientSecret: w.ClientSecret,
+								Username:     w.Username,
+								Password:     w.Password,
+								Token:        w.Token,
+							})
+							if err != nil {
+								return err
+							}
+							return withStorage(c, func(commands *cli.Commands) error {
+								return commands.SyncWallabag(client)
+							})
+						},
+					},
+				},
+			},
+			{
+				Name:  "push",
+				Usage: "Back links up to a remote service",
+				Subcommands: []*urfavecli.Command{
+					{
+						Name:  "pinboard",
+						Usage: "Post links added or changed since the last push to Pinboard (unread links as \"to read\")",
+						Flags: []urfavecli.Flag{
+							&urfavecli.BoolFlag{Name: "dry-run", Aliases: []string{"n"}, Usage: "list the links that would be pushed"},
+						},
+						Action: func(c *urfavecli.Context) error {
+							if cfg.Pinboard.Token == "" {
+								return fmt.Errorf("set token under [pinboard] in the config file")
+							}
+							client, err := pinboard.NewClient(cfg.Pinboard.Token)
+							if err != nil {
+								return err
+							}
+							return withStorage(c, func(commands *cli.Commands) error {
+								return commands.PushPinboard(client, c.Bool("dry-run"))
+							})
+						},
+					},
+				},
+			},
+			{
+				Name:  "db",
+				Usage: "Inspect and manage the database schema",
+				Subcommands: []*urfavecli.Command{
+					{
+						Name:  "migrations",
+						Usage: "List schema migrations and whether each is applied",
+						Action: func(c *urfavecli.Context) error {
+							return withDB(c, func(db *cli.DBCommands) error {
+								return db.Migrations()
+							})
+						},
+					},
+					{
+						Name:  "doctor",
+						Usage: "Check the database for corruption and stale indexes, then vacuum it",
+						Flags: []urfavecli.Flag{
+							&urfavecli.BoolFlag{Name: "fix", Usage: "apply pending migrations, rebuild indexes, and delete orphaned rows"},
+						},
+						Action: func(c *urfavecli.Context) error {
+							return withDB(c, func(db *cli.DBCommands) error {
+								return db.Doctor(c.Bool("fix"))
+							})
+						},
+					},
+					{
+						Name:  "rollback",
+						Usage: "Undo the newest migrations (the next rl command applies them again)",
+						Flags: []urfavecli.Flag{
+							&urfavecli.IntFlag{Name: "steps", Aliases: []string{"n"}, Value: 1, Usage: "number of migrations to undo"},
+							&urfavecli.IntFlag{Name: "to", Usage: "undo every migration newer than this version"},
+						},
+						Action: func(c *urfavecli.Context) error {
+							if c.IsSet("to") && c.IsSet("steps") {
+								return fmt.Errorf("--to and --steps can't be used together")
+							}
+							if c.Int("steps") < 1 {
+								return fmt.Errorf("--steps must be at least 1")
+							}
+							return withDB(c, func(db *cli.DBCommands) error {
+								if c.IsSet("to") {
+									return db.RollbackTo(c.Int("to"))
+								}
+								return db.Rollback(c.Int("steps"))
+							})
+						},
+					},
+				},
+			},
+			{
+				Name:  "backup",
+				Usage: "Snapshot the database and restore snapshots",
+				Subcommands: []*urfavecli.Command{
+					{
+						Name:  "now",
+						Usage: "Snapshot the database",
+						Action: func(c *urfavecli.Context) error {
+							s, err := openStorage(c, printWarning)
+							if err != nil {
+								return err
+							}
+							defer s.Close()
+							return withBackups(c, func(b *cli.BackupCommands) error {
+								return b.Now(s)
+							})
+						},
+					},
+					{
+						Name:    "list",
+						Aliases: []string{"ls"},
+						Usage:   "List snapshots, newest first",
+						Action: func(c *urfavecli.Context) error {
+							return withBackups(c, func(b *cli.BackupCommands) error {
+								return b.List()
+							})
+						},
+					},
+					{
+						Name:      "restore",
+						Usage:     "Replace the database with a snapshot (the current one is backed up first)",
+						ArgsUsage: "<name|latest>",
+						Action: func(c *urfavecli.Context) error {
+							if c.NArg() != 1 {
+								return fmt.Errorf("usage: rl backup restore <name|latest>")
+							}
+							if err := checkReadOnly(c); err != nil {
+								return err
+							}
+							path, err := app.ResolveDBPath(dbPath(c))
+							if err != nil {
+								return err
+							}
+							return withBackups(c, func(b *cli.BackupCommands) error {
+								return b.Restore(c.Args().First(), path)
+							})
+						},
+					},
+				},
+			},
+			{
+				Name:  "mcp",
+				Usage: "Serve the reading list to AI assistants over the Model Context Protocol (stdio)",
+				Action: func(c *urfavecli.Context) error {
+					s, err := openStorage(c, printWarning)
+					if err != nil {
+						return err
+					}
+					defer s.Close()
+					server := mcp.NewServer(s, mcp.Options{
+						Fetch:        cfg.Add.Fetch,
+						Canonicalize: cfg.Add.Canonicalize,
+						Attach:       cfg.Add.Attach,
+						Attachments:  attachmentStore(c),
+						Version:      version,
+					})
+					return server.Serve(c.Context, os.Stdin, os.Stdout)
+				},
+			},
+			{
+				Name:    "tui",
+				Aliases: []string{"interactive", "i"},
+				Usage:   "Launch interactive TUI mode",
+				Action: func(c *urfavecli.Context) error {
+					s, err := openStorage(c, nil)
+					if err != nil {
+						return err
+					}
+					defer s.Close()
+					return tui.Run(s, tuiOptions(c))
+				},
+			},
+		},
+		OnUsageError: func(c *urfavecli.Context, err error, isSubcommand bool) error {
+			if isSubcommand {
+				return err
+			}
+			printError(jsonOutput(c), err)
+			return nil
+		},
+		ExitErrHandler: func(c *urfavecli.Context, err error) {
 			if err != nil {
-				fmt.Fprintf(os.Stderr, "%sError:%s %v\n", colorRed, colorReset, err)
-				os.Exit(1)
+				exit(jsonOutput(c), err)
 			}
 		},
 	}
+	addJSONFlag(cliApp.Commands)
+	return cliApp
+}
+
+// addJSONFlag gives every command its own --json, so it can follow the
+// command (rl ls --json) as well as precede it (rl --json ls).
+func addJSONFlag(commands []*urfavecli.Command) {
+	for _, cmd := range commands {
+		cmd.Flags = append(cmd.Flags, &urfavecli.BoolFlag{Name: "json", Usage: "print records and errors as JSON"})
+		addJSONFlag(cmd.Subcommands)
+	}
+}
+
+// jsonOutput reports whether --json was given before the command or after
+// it.
+func jsonOutput(c *urfavecli.Context) bool {
+	for _, ctx := range c.Lineage() {
+		if ctx.Bool("json") {
+			return true
+		}
+	}
+	return false
+}
+
+// exit prints err and exits with a failure status, or the conventional
+// 130 when the command was interrupted.
+func exit(asJSON bool, err error) {
+	if errors.Is(err, context.Canceled) {
+		printError(asJSON, errors.New("interrupted"))
+		os.Exit(130)
+	}
+	printError(asJSON, err)
+	os.Exit(1)
+}
+
+// cfg holds config file defaults; flags and environment variables override it.
+var cfg = config.Default()
 
-	if err := cliApp.Run(os.Args); err != nil {
+func loadConfig(c *urfavecli.Context) error {
+	path := c.String("config")
+	if path == "" {
+		var err error
+		if path, err = config.DefaultPath(); err != nil {
+			return nil
+		}
+	}
+	loaded, err := config.Load(path)
+	if err != nil {
+		return err
+	}
+	cfg = loaded
+	loc, _ := cfg.Location() // validated by Load
+	cli.SetTimeFormat(loc, cfg.DateFormat)
+	return nil
+}
+
+// tuiOptions builds the TUI settings from the config file and flags.
+func tuiOptions(c *urfavecli.Context) tui.Options {
+	loc, _ := cfg.Location()
+	// Only used to watch for changes, so an unresolvable path just
+	// disables live reload
+	path, _ := app.ResolveDBPath(dbPath(c))
+	// Likewise, without a path searches are only remembered until exit
+	history, _ := app.SearchHistoryPath(dbPath(c))
+	return tui.Options{
+		Location:        loc,
+		DateLayout:      cfg.DateFormat,
+		RelativeTimes:   boolOr(c, "relative", cfg.RelativeTimes),
+		Browser:         cfg.Browser,
+		Keys:            cfg.Keys,
+		Theme:           cfg.Theme,
+		Colors:          cfg.Colors,
+		AddFetch:        cfg.Add.Fetch,
+		AddCanonicalize: cfg.Add.Canonicalize,
+		AddAttach:       cfg.Add.Attach,
+		Attachments:     attachmentStore(c),
+		WeeklyGoal:      cfg.Goals.Weekly,
+		DBPath:          path,
+		SearchHistory:   history,
+	}
+}
+
+// dbPath returns the database path from --db-path, $RL_DB_PATH, or the
+// config file, in that order. "" means the platform default.
+func dbPath(c *urfavecli.Context) string {
+	if c.IsSet("db-path") {
+		return c.String("db-path")
+	}
+	return cfg.DBPath
+}
+
+// boolOr returns the flag's value if it was given, otherwise def.
+func boolOr(c *urfavecli.Context, name string, def bool) bool {
+	if c.IsSet(name) {
+		return c.Bool(name)
+	}
+	return def
+}
+
+// priorityFlag parses --priority, or returns nil if it wasn't given so a
+// link saved again keeps its priority.
+func priorityFlag(c *urfavecli.Context) (*model.Priority, error) {
+	if !c.IsSet("priority") {
+		return nil, nil
+	}
+	priority, err := model.ParsePriority(c.String("priority"))
+	if err != nil {
+		return nil, err
+	}
+	return &priority, nil
+}
+
+// intOr returns the flag's value if it was given, otherwise def.
+func intOr(c *urfavecli.Context, name string, def int) int {
+	if c.IsSet(name) {
+		return c.Int(name)
+	}
+	return def
+}
+
+// parseDateRange reads the --since, --before, and --read-since flags.
+func parseDateRange(c *urfavecli.Context) (storage.DateRange, error) {
+	var r storage.DateRange
+	now := time.Now()
+	for _, f := range []struct {
+		name string
+		t    *time.Time
+	}{
+		{"since", &r.Since},
+		{"before", &r.Before},
+		{"read-since", &r.ReadSince},
+	} {
+		if c.String(f.name) == "" {
+			continue
+		}
+		t, err := cli.ParseSince(c.String(f.name), now)
+		if err != nil {
+			return r, fmt.Errorf("--%s: %w", f.name, err)
+		}
+		*f.t = t
+	}
+	return r, nil
+}
+
+func parseReadStatus(filter string) storage.ReadStatus {
+	switch filter {
+	case "read":
+		return storage.ReadStatusRead
+	case "all":
+		return storage.ReadStatusAll
+	default:
+		return storage.ReadStatusUnread
+	}
+}
+
+// colorEnabled reports whether output should be colored: not disabled by
+// --no-color or NO_COLOR (https://no-color.org), and either forced by the
+// config file or stdout is a terminal.
+func colorEnabled(c *urfavecli.Context) bool {
+	if c.Bool("no-color") || c.Bool("plain") || os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	switch cfg.Color {
+	case "always":
+		return true
+	case "never":
+		return false
+	}
+	return stdoutIsTerminal()
+}
+
+// hyperlinksEnabled reports whether link tables should be clickable: forced
+// by the config file, or colored output going to a terminal known to
+// support OSC 8 hyperlinks. Others would print the escapes verbatim or
+// garble the table.
+func hyperlinksEnabled(c *urfavecli.Context) bool {
+	switch cfg.Hyperlinks {
+	case "always":
+		return true
+	case "never":
+		return false
+	}
+	if !colorEnabled(c) || !stdoutIsTerminal() {
+		return false
+	}
+	switch os.Getenv("TERM_PROGRAM") {
+	case "iTerm.app", "WezTerm", "vscode", "ghostty", "Hyper":
+		return true
+	}
+	if os.Getenv("KITTY_WINDOW_ID") != "" || os.Getenv("WT_SESSION") != "" {
+		return true
+	}
+	// GNOME Terminal and other VTE-based terminals since 0.50
+	vte, _ := strconv.Atoi(os.Getenv("VTE_VERSION"))
+	return vte >= 5000
+}
+
+func stdoutIsTerminal() bool {
+	fd := os.Stdout.Fd()
+	return isatty.IsTerminal(fd) || isatty.IsCygwinTerminal(fd)
+}
+
+var ansiEscape = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+
+// printError reports err on stderr, as {"error": "..."} in JSON mode.
+func printError(asJSON bool, err error) {
+	if !asJSON {
 		fmt.Fprintf(os.Stderr, "%sError:%s %v\n", colorRed, colorReset, err)
-		os.Exit(1)
+		return
+	}
+	data, _ := json.Marshal(struct {
+		Error string `json:"error"`
+	}{ansiEscape.ReplaceAllString(err.Error(), "")})
+	fmt.Fprintln(os.Stderr, string(data))
+}
+
+// printWarning reports a non-fatal error on stderr.
+func printWarning(err error) {
+	fmt.Fprintf(os.Stderr, "%sWarning:%s %v\n", colorYellow, colorReset, err)
+}
+
+// jsonRequested reports whether --json appears among the flags, before or
+// after the command, for errors raised before a context is available.
+func jsonRequested(args []string) bool {
+	for _, arg := range args[1:] {
+		switch arg {
+		case "--json", "-json":
+			return true
+		case "--":
+			return false
+		}
 	}
+	return false
 }
 
 func withStorage(c *urfavecli.Context, fn func(*cli.Commands) error) error {
-	s, err := app.NewStorage(c.String("db-path"))
+	s, err := openStorage(c, printWarning)
 	if err != nil {
-		return fmt.Errorf("failed to initialize storage: %w", err)
+		return err
 	}
 	defer s.Close()
 	commands := cli.NewCommands(s)
+	commands.SetContext(c.Context)
+	commands.SetJSON(jsonOutput(c))
+	commands.SetBrowser(cfg.Browser)
+	commands.SetPlain(c.Bool("plain") || !stdoutIsTerminal())
+	if path, err := app.ListingPath(dbPath(c)); err == nil {
+		commands.SetListingFile(path)
+	}
+	if store := attachmentStore(c); store != nil {
+		commands.SetAttachments(store)
+	}
+	if !c.Bool("read-only") {
+		commands.SetBackups(backupStore(c))
+		if _, err := commands.AutoBackup(backupInterval()); err != nil {
+			printWarning(fmt.Errorf("backup: %w", err))
+		}
+	}
+	if cfg.Cleanup.OnStartup && c.Command.Name != "cleanup" && !c.Bool("read-only") {
+		result, err := commands.AutoCleanup(cleanupPolicy())
+		if err != nil {
+			printWarning(fmt.Errorf("cleanup: %w", err))
+		} else if result != (cli.CleanupResult{}) {
+			fmt.Fprintf(os.Stderr, "Cleanup: %s.\n", result)
+		}
+	}
 	return fn(commands)
 }
+
+// attachmentStore returns the directory saved documents are kept in, or
+// nil for an in-memory database.
+func attachmentStore(c *urfavecli.Context) *attach.Store {
+	dir, err := app.AttachmentsPath(dbPath(c))
+	if err != nil || dir == "" {
+		return nil
+	}
+	return attach.NewStore(dir)
+}
+
+// withDB opens the database for rl db without applying migrations, so a
+// rollback isn't undone before it starts.
+func withDB(c *urfavecli.Context, fn func(*cli.DBCommands) error) error {
+	if err := checkReadOnly(c); err != nil {
+		return err
+	}
+	db, err := app.OpenDB(dbPath(c), dbOptions(c))
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+	commands := cli.NewDBCommands(db)
+	commands.SetContext(c.Context)
+	commands.SetJSON(jsonOutput(c))
+	if store := attachmentStore(c); store != nil {
+		commands.SetAttachments(store)
+	}
+	if store := backupStore(c); store != nil {
+		commands.SetBackups(store)
+	}
+	return fn(commands)
+}
+
+// withBackups runs fn on the database's snapshots.
+func withBackups(c *urfavecli.Context, fn func(*cli.BackupCommands) error) error {
+	store := backupStore(c)
+	if store == nil {
+		return fmt.Errorf("an in-memory database can't be backed up")
+	}
+	commands := cli.NewBackupCommands(store)
+	commands.SetContext(c.Context)
+	commands.SetJSON(jsonOutput(c))
+	return fn(commands)
+}
+
+// backupStore returns where the database is snapshotted, or nil for an
+// in-memory database.
+func backupStore(c *urfavecli.Context) *backup.Store {
+	dir, err := app.BackupsPath(dbPath(c))
+	if err != nil || dir == "" {
+		return nil
+	}
+	return backup.NewStore(dir, cfg.Backup.Keep)
+}
+
+// backupInterval returns how often scheduled backups are taken; zero
+// turns them off.
+func backupInterval() time.Duration {
+	return time.Duration(cfg.Backup.EveryDays) * 24 * time.Hour
+}
+
+// dbOptions returns how to open the database, from flags and the config file.
+func dbOptions(c *urfavecli.Context) app.Options {
+	timeout, _ := cfg.Timeout() // validated by Load
+	opts := app.Options{Timeout: timeout, ReadOnly: c.Bool("read-only"), OnBusy: busyNotice(),
+		IDScheme: cfg.IDScheme, IDLength: cfg.IDLength}
+	if !opts.ReadOnly {
+		opts.Backups = backupStore(c)
+	}
+	return opts
+}
+
+// busyNotice returns an OnBusy handler that explains, once, why a command
+// is waiting.
+func busyNotice() func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			fmt.Fprintf(os.Stderr, "%sDatabase busy:%s another rl process is writing to it. Retrying…\n", colorYellow, colorReset)
+		})
+	}
+}
+
+// readOnlyCommands are the commands --read-only allows, by full name. The
+// TUI and the MCP server are allowed too; their writes fail.
+var readOnlyCommands = map[string]bool{
+	"ls": true, "open": true, "show": true, "qr": true, "note ls": true,
+	"quotes": true, "related": true, "next": true, "export": true, "grep": true,
+	"random": true, "pick": true, "diffcheck": true, "stats": true,
+	"stale": true, "streak": true, "db migrations": true, "backup now": true, "backup list": true, "history": true, "remind": true, "daemon status": true,
+	"trash": true, "rules list": true, "rules test": true, "suggest-tags": true, "mcp": true,
+	"tui": true,
+}
+
+// writingFlags are the flags that make an allowed command write.
+var writingFlags = map[string][]string{
+	"open":         {"done"},
+	"pick":         {"done"},
+	"diffcheck":    {"update"},
+	"stale":        {"archive", "delete"},
+	"history":      {"revert"},
+	"suggest-tags": {"yes"},
+}
+
+// checkReadOnly refuses commands that would change the database when it is
+// opened with --read-only.
+func checkReadOnly(c *urfavecli.Context) error {
+	if !c.Bool("read-only") {
+		return nil
+	}
+	// The full name, "note ls" rather than "ls"
+	var names []string
+	for _, ctx := range c.Lineage() {
+		if ctx.Command != nil && ctx.Command.Name != "" && ctx.Command.Name != c.App.Name {
+			names = append([]string{ctx.Command.Name}, names...)
+		}
+	}
+	if len(names) == 0 {
+		return nil // the TUI started without a command
+	}
+	name := strings.Join(names, " ")
+	if !readOnlyCommands[name] {
+		return fmt.Errorf("rl %s changes the database, which --read-only prevents", name)
+	}
+	for _, flag := range writingFlags[name] {
+		if c.IsSet(flag) && (c.Bool(flag) || c.Int64(flag) != 0) {
+			return fmt.Errorf("rl %s --%s changes the database, which --read-only prevents", name, flag)
+		}
+	}
+	return nil
+}
+
+// reorder runs rl bump and rl demote, moving a link --places along the
+// unread queue in direction, or all the way with the flag named end.
+func reorder(c *urfavecli.Context, direction int, end string) error {
+	if c.NArg() != 1 {
+		return fmt.Errorf("usage: rl %s [--places n | --%s] <id>", c.Command.Name, end)
+	}
+	places := c.Int("places")
+	if places <= 0 {
+		return fmt.Errorf("--places must be a positive number")
+	}
+	if c.Bool(end) {
+		places = math.MaxInt
+	}
+	id, err := cli.ParseID(c.Args().First())
+	if err != nil {
+		return err
+	}
+	return withStorage(c, func(commands *cli.Commands) error {
+		return commands.Reorder(id, direction*places)
+	})
+}
+
+// cleanupPolicy returns the retention policies from the config file.
+func cleanupPolicy() cli.CleanupPolicy {
+	const day = 24 * time.Hour
+	return cli.CleanupPolicy{
+		ArchiveReadAfter:   time.Duration(cfg.Cleanup.ArchiveReadAfterDays) * day,
+		DeleteTrashedAfter: time.Duration(cfg.Cleanup.DeleteTrashedAfterDays) * day,
+	}
+}
+
+// daemonJobs returns the jobs scheduled under [daemon.jobs] in the config
+// file, in the order they run when several are due.
+func daemonJobs() ([]cli.DaemonJob, error) {
+	var jobs []cli.DaemonJob
+	for _, name := range config.DaemonJobs {
+		every, ok := cfg.Daemon.Jobs[name]
+		if !ok {
+			continue
+		}
+		d, err := cli.ParseDuration(every)
+		if err != nil {
+			return nil, fmt.Errorf("daemon.jobs.%s: %w", name, err)
+		}
+		jobs = append(jobs, cli.DaemonJob{Name: name, Every: d})
+	}
+	return jobs, nil
+}
+
+// tagRules returns the [[rules]] from the config file.
+func tagRules() ([]rules.Rule, error) {
+	specs := make([]rules.Rule, len(cfg.Rules))
+	for i, r := range cfg.Rules {
+		specs[i] = rules.Rule{Domain: r.Domain, URL: r.URL, Title: r.Title, Tags: r.Tags}
+	}
+	return rules.New(specs)
+}
+
+// llmOptions returns the language model settings from the config file.
+func llmOptions() llm.Options {
+	return llm.Options{
+		Endpoint:       cfg.LLM.Endpoint,
+		Model:          cfg.LLM.Model,
+		EmbeddingModel: cfg.LLM.EmbeddingModel,
+		APIKey:         cfg.LLM.APIKey,
+		SummaryPrompt:  cfg.LLM.SummaryPrompt,
+	}
+}
+
+// openStorage opens the database with the configured tagging rules and
+// webhooks attached.
+// Webhook failures are passed to onError; the TUI passes nil since it owns
+// the screen.
+func openStorage(c *urfavecli.Context, onError func(error)) (storage.Storage, error) {
+	if err := checkReadOnly(c); err != nil {
+		return nil, err
+	}
+	opts := dbOptions(c)
+	if onError == nil {
+		opts.OnBusy = nil // the TUI owns the screen
+	}
+	tagRules, err := tagRules()
+	if err != nil {
+		return nil, err
+	}
+	s, err := app.NewStorage(dbPath(c), opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize storage: %w", err)
+	}
+	// Tag before notifying, so webhooks see the tags rules added
+	s = rules.Wrap(s, tagRules)
+	hooks := make([]webhook.Hook, 0, len(cfg.Webhooks))
+	for _, h := range cfg.Webhooks {
+		hook := webhook.Hook{URL: h.URL, Headers: h.Headers}
+		for _, event := range h.Events {
+			hook.Events = append(hook.Events, webhook.Event(event))
+		}
+		hooks = append(hooks, hook)
+	}
+	return webhook.Wrap(s, hooks, onError), nil
+}
+
+// addArgs returns the URLs given to `rl add`. An argument of "-" adds the
+// URLs found in stdin.
+func addArgs(c *urfavecli.Context) ([]string, error) {
+	var urls []string
+	for _, arg := range c.Args().Slice() {
+		if arg != "-" {
+			urls = append(urls, arg)
+			continue
+		}
+		input, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return nil, fmt.Errorf("read URLs from stdin: %w", err)
+		}
+		found := cli.ExtractURLs(string(input))
+		if len(found) == 0 {
+			return nil, fmt.Errorf("no URLs found on stdin")
+		}
+		urls = append(urls, found...)
+	}
+	return urls, nil
+}
+
+// parseIDs validates every positional argument as a link ID. An argument
+// of "-" reads whitespace-separated IDs from stdin.
+func parseIDs(c *urfavecli.Context) ([]string, error) {
+	args := make([]string, 0, c.NArg())
+	for _, arg := range c.Args().Slice() {
+		if arg != "-" {
+			args = append(args, arg)
+			continue
+		}
+		input, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return nil, fmt.Errorf("read IDs from stdin: %w", err)
+		}
+		args = append(args, strings.Fields(string(input))...)
+	}
+	if len(args) == 0 {
+		return nil, fmt.Errorf("no IDs given")
+	}
+
+	ids := make([]string, 0, len(args))
+	for _, arg := range args {
+		id, err := cli.ParseID(arg)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
@@ -0,0 +1,250 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bunchhieng/rl/internal/cli"
+	urfavecli "github.com/urfave/cli/v2"
+)
+
+// runApp runs rl with args against a database and config file in dir,
+// returning what it printed to stdout.
+func runApp(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	app := newApp()
+	app.ExitErrHandler = func(*urfavecli.Context, error) {}
+	global := []string{"rl", "--db-path", filepath.Join(dir, "rl.db"), "--config", filepath.Join(dir, "config.toml")}
+	runErr := app.Run(append(global, args...))
+	w.Close()
+	out, _ := io.ReadAll(r)
+	if runErr != nil {
+		t.Fatalf("rl %v: %v", args, runErr)
+	}
+	return string(out)
+}
+
+func TestJSONAfterCommand(t *testing.T) {
+	dir := t.TempDir()
+	runApp(t, dir, "add", "--no-fetch", "https://example.com/a")
+
+	for _, args := range [][]string{{"ls", "--json"}, {"--json", "ls"}} {
+		var links []struct {
+			URL string `json:"url"`
+		}
+		out := runApp(t, dir, args...)
+		if err := json.Unmarshal([]byte(out), &links); err != nil {
+			t.Fatalf("rl %v printed %q, not JSON: %v", args, out, err)
+		}
+		if len(links) != 1 || links[0].URL != "https://example.com/a" {
+			t.Errorf("rl %v = %+v, want the added link", args, links)
+		}
+	}
+}
+
+func TestJSONRequested(t *testing.T) {
+	tests := []struct {
+		args []string
+		want bool
+	}{
+		{[]string{"rl", "--json", "ls"}, true},
+		{[]string{"rl", "ls", "--json"}, true},
+		{[]string{"rl", "ls"}, false},
+		{[]string{"rl", "add", "--", "--json"}, false},
+	}
+	for _, tt := range tests {
+		if got := jsonRequested(tt.args); got != tt.want {
+			t.Errorf("jsonRequested(%v) = %v, want %v", tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestRandomDomain(t *testing.T) {
+	dir := t.TempDir()
+	runApp(t, dir, "add", "--no-fetch", "https://go.dev/blog", "https://lwn.net/Articles/1")
+
+	for range 5 {
+		var link struct {
+			URL string `json:"url"`
+		}
+		out := runApp(t, dir, "random", "--json", "--domain", "lwn.net")
+		if err := json.Unmarshal([]byte(out), &link); err != nil {
+			t.Fatalf("rl random printed %q, not JSON: %v", out, err)
+		}
+		if link.URL != "https://lwn.net/Articles/1" {
+			t.Fatalf("rl random --domain lwn.net = %s, want the lwn.net link", link.URL)
+		}
+	}
+}
+
+// setStdin makes text the input of the commands a test runs.
+func setStdin(t *testing.T, text string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "stdin")
+	if err := os.WriteFile(path, []byte(text), 0644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdin := os.Stdin
+	os.Stdin = f
+	t.Cleanup(func() {
+		os.Stdin = stdin
+		f.Close()
+	})
+}
+
+func TestIDsFromStdin(t *testing.T) {
+	dir := t.TempDir()
+	runApp(t, dir, "add", "--no-fetch", "https://example.com/a", "https://example.com/b", "https://example.com/c")
+	var links []struct {
+		ID  string `json:"id"`
+		URL string `json:"url"`
+	}
+	if err := json.Unmarshal([]byte(runApp(t, dir, "ls", "--json", "--sort", "oldest")), &links); err != nil {
+		t.Fatal(err)
+	}
+	if len(links) != 3 {
+		t.Fatalf("got %d links, want 3", len(links))
+	}
+
+	// IDs on stdin are separated by any whitespace
+	setStdin(t, links[0].ID+"\n  "+links[1].ID+"\n")
+	runApp(t, dir, "done", "-")
+	var read []struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal([]byte(runApp(t, dir, "ls", "--json", "--read")), &read); err != nil {
+		t.Fatal(err)
+	}
+	if len(read) != 2 {
+		t.Errorf("done - marked %d links read, want the 2 on stdin", len(read))
+	}
+
+	setStdin(t, " \n")
+	app := newApp()
+	app.ExitErrHandler = func(*urfavecli.Context, error) {}
+	err := app.Run([]string{"rl", "--db-path", filepath.Join(dir, "rl.db"), "--config", filepath.Join(dir, "config.toml"), "done", "-"})
+	if err == nil || err.Error() != "no IDs given" {
+		t.Errorf("done - with nothing on stdin = %v, want no IDs given", err)
+	}
+}
+
+func TestColorOutput(t *testing.T) {
+	dir := t.TempDir()
+	runApp(t, dir, "add", "--no-fetch", "https://example.com/a")
+	var links []struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal([]byte(runApp(t, dir, "ls", "--json")), &links); err != nil || len(links) != 1 {
+		t.Fatalf("ls --json = %+v, %v", links, err)
+	}
+	id := links[0].ID
+
+	// Off a terminal, tables are plain and nothing is colored
+	if out := runApp(t, dir, "ls"); strings.Contains(out, "\x1b[") || strings.ContainsAny(out, "┌│─") {
+		t.Errorf("ls off a terminal printed colors or borders:\n%s", out)
+	}
+
+	// Earlier runs turned colors off for the whole process
+	cli.SetColor(true)
+	t.Cleanup(func() { cli.SetColor(false) })
+	if err := os.WriteFile(filepath.Join(dir, "config.toml"), []byte("color = \"always\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if out := runApp(t, dir, "done", id); !strings.Contains(out, "\x1b[") {
+		t.Errorf("color = always printed %q without colors", out)
+	}
+	t.Setenv("NO_COLOR", "1")
+	if out := runApp(t, dir, "undo", id); strings.Contains(out, "\x1b[") {
+		t.Errorf("NO_COLOR printed %q with colors", out)
+	}
+}
+
+func TestTimezoneSetting(t *testing.T) {
+	if _, err := time.LoadLocation("Asia/Tokyo"); err != nil {
+		t.Skipf("no time zone database: %v", err)
+	}
+	dir := t.TempDir()
+	export := filepath.Join(dir, "links.json")
+	data := `[{"id": "9m1w2z3x", "url": "https://example.com/a", "created_at": "2024-01-01T20:00:00Z"}]`
+	if err := os.WriteFile(export, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runApp(t, dir, "import", export)
+
+	config := "timezone = \"Asia/Tokyo\"\ndate_format = \"2006-01-02 15:04 MST\"\n"
+	if err := os.WriteFile(filepath.Join(dir, "config.toml"), []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { cli.SetTimeFormat(time.Local, "2006-01-02 15:04:05 MST") })
+	// Saved at 20:00 UTC, which is the next morning in Tokyo
+	if out := runApp(t, dir, "show", "9m1w2z3x"); !strings.Contains(out, "2024-01-02 05:00 JST") {
+		t.Errorf("show printed the save time outside the configured zone:\n%s", out)
+	}
+	if out := runApp(t, dir, "ls", "--all", "--since", "2024-01-02"); !strings.Contains(out, "9m1w2z3x") {
+		t.Errorf("ls --since the Tokyo date it was saved left the link out:\n%s", out)
+	}
+}
+
+func TestBareRLOffTerminal(t *testing.T) {
+	dir := t.TempDir()
+	// Run from a pipe, bare rl prints help instead of taking over the screen
+	out := runApp(t, dir)
+	if !strings.Contains(out, "USAGE:") || !strings.Contains(out, "COMMANDS:") {
+		t.Errorf("bare rl off a terminal printed %q, want the help", out)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "rl.db")); !os.IsNotExist(err) {
+		t.Errorf("bare rl off a terminal opened the database: %v", err)
+	}
+}
+
+func TestAddSeveralURLs(t *testing.T) {
+	dir := t.TempDir()
+	runApp(t, dir, "add", "--no-fetch", "https://example.com/a")
+
+	// URLs on stdin are added alongside the arguments, and ones already
+	// saved are updated
+	setStdin(t, "see https://example.com/c\n")
+	out := runApp(t, dir, "add", "--no-fetch", "--tags", "batch", "https://example.com/a", "https://example.com/b", "-")
+	if !strings.Contains(out, "2 added, 1 updated, 0 skipped") {
+		t.Errorf("rl add printed %q, want a summary of 2 added and 1 updated", out)
+	}
+	var links []struct {
+		URL  string `json:"url"`
+		Tags string `json:"tags"`
+	}
+	if err := json.Unmarshal([]byte(runApp(t, dir, "ls", "--json", "--sort", "oldest")), &links); err != nil {
+		t.Fatal(err)
+	}
+	if len(links) != 3 {
+		t.Fatalf("got %d links, want 3", len(links))
+	}
+	for i, want := range []string{"https://example.com/a", "https://example.com/b", "https://example.com/c"} {
+		if links[i].URL != want || links[i].Tags != "batch" {
+			t.Errorf("link %d = %+v, want %s tagged batch", i, links[i], want)
+		}
+	}
+
+	app := newApp()
+	app.ExitErrHandler = func(*urfavecli.Context, error) {}
+	err := app.Run([]string{"rl", "--db-path", filepath.Join(dir, "rl.db"), "--config", filepath.Join(dir, "config.toml"), "add", "--title", "T", "https://example.com/d", "https://example.com/e"})
+	if err == nil || !strings.Contains(err.Error(), "--title") {
+		t.Errorf("Expected --title to be refused with several URLs, got %v", err)
+	}
+}